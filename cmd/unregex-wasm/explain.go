@@ -0,0 +1,26 @@
+//go:build (js && wasm) || wasip1
+
+// Command unregex-wasm is the WebAssembly build of unregex. Built for js/wasm
+// it registers a JavaScript API; built for wasip1 it's a command that prints
+// the explanation of a pattern as JSON.
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/weslien/unregex/internal/app"
+	"github.com/weslien/unregex/pkg/utils"
+)
+
+// explainJSON explains a pattern in a flavor, go by default, as JSON
+func explainJSON(pattern, flavor string) (string, error) {
+	flavor = strings.ToLower(flavor)
+	if flavor == "" {
+		flavor = "go"
+	}
+	if !utils.IsValidFormat(flavor) {
+		return "", fmt.Errorf("unsupported regex format '%s'", flavor)
+	}
+	return app.RenderJSON(app.Analyze(pattern, flavor))
+}
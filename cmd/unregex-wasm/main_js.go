@@ -0,0 +1,45 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/weslien/unregex/pkg/utils"
+)
+
+// main exposes globalThis.unregex to JavaScript and keeps the Go runtime
+// alive to serve calls. wasm/unregex.js wraps it in a friendlier API.
+func main() {
+	js.Global().Set("unregex", js.ValueOf(map[string]interface{}{
+		"version": utils.Version,
+		"explain": js.FuncOf(explain),
+	}))
+	select {}
+}
+
+// explain implements unregex.explain(pattern, flavor), returning the
+// explanation as a JSON string. Failures are returned as {"error": "..."},
+// since a panic in a Go callback would stop the whole program.
+func explain(this js.Value, args []js.Value) interface{} {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return errorJSON("explain needs a pattern string")
+	}
+	flavor := ""
+	if len(args) > 1 && args[1].Type() == js.TypeString {
+		flavor = args[1].String()
+	}
+
+	out, err := explainJSON(args[0].String(), flavor)
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	return out
+}
+
+// errorJSON encodes an error message for the JavaScript wrapper to throw
+func errorJSON(message string) string {
+	data, _ := json.Marshal(map[string]string{"error": message})
+	return string(data)
+}
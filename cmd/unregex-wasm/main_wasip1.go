@@ -0,0 +1,41 @@
+//go:build wasip1
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// main explains the pattern given as an argument, or read from stdin, and
+// prints the explanation as JSON, for WASI runtimes such as wasmtime:
+//
+//	wasmtime unregex.wasm -format pcre '(?<=a)b'
+func main() {
+	formatFlag := flag.String("format", "go", "Regex format/flavor (go, pcre, posix, js, python)")
+	flag.Parse()
+
+	pattern := strings.Join(flag.Args(), " ")
+	if flag.NArg() == 0 {
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read from stdin: %v\n", err)
+			os.Exit(1)
+		}
+		pattern = strings.TrimSpace(string(input))
+	}
+	if pattern == "" {
+		fmt.Fprintln(os.Stderr, "Error: no regex pattern provided")
+		os.Exit(1)
+	}
+
+	out, err := explainJSON(pattern, *formatFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(out)
+}
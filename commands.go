@@ -0,0 +1,1229 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/weslien/unregex/internal/app"
+	"github.com/weslien/unregex/internal/config"
+	"github.com/weslien/unregex/internal/docgen"
+	"github.com/weslien/unregex/internal/explore"
+	"github.com/weslien/unregex/internal/grpcserver"
+	"github.com/weslien/unregex/internal/history"
+	"github.com/weslien/unregex/internal/library"
+	"github.com/weslien/unregex/internal/lsp"
+	"github.com/weslien/unregex/internal/saved"
+	"github.com/weslien/unregex/internal/selfupdate"
+	"github.com/weslien/unregex/internal/server"
+	"github.com/weslien/unregex/internal/share"
+	"github.com/weslien/unregex/pkg/utils"
+)
+
+// commands maps subcommand names to their implementations. Each subcommand
+// receives the arguments that follow its name and parses its own flags.
+var commands = map[string]func(args []string) error{
+	"batch":       runBatch,
+	"bench":       runBench,
+	"docgen":      runDocgen,
+	"explore":     runExplore,
+	"gen":         runGen,
+	"history":     runHistory,
+	"lib":         runLib,
+	"lsp":         runLSP,
+	"open":        runOpen,
+	"save":        runSave,
+	"self-update": runSelfUpdate,
+	"serve":       runServe,
+	"share":       runShare,
+	"simplify":    runSimplify,
+	"test":        runTest,
+}
+
+// userConfig is the config file main loaded, whose defaults subcommands
+// layer under their flags as main does under the top-level ones
+var userConfig = &config.Config{}
+
+// parseFlags layers the config file and UNREGEX_* environment variables
+// under a subcommand's flags, then parses its arguments
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := userConfig.ApplyDefaults(flags, os.Getenv); err != nil {
+		return err
+	}
+	return flags.Parse(args)
+}
+
+// batchDiagnostic is a lint finding as written by batch -output diagnostics.
+// The fields are always in this order, so problem matchers can parse the
+// records with a regular expression.
+type batchDiagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// runBatch explains or lints every pattern in a file, one per line, and
+// finishes with a summary. Lines may name their flavor as flavor<TAB>pattern.
+func runBatch(args []string) error {
+	flags := flag.NewFlagSet("batch", flag.ExitOnError)
+	formatFlag := flags.String("format", "go", "Regex format/flavor for lines that don't name one")
+	lintFlag := flags.Bool("lint", false, "Only report errors and warnings instead of explaining each pattern")
+	colorFlag := flags.String("color", "auto", "When to color the explanations (always, never, auto)")
+	outputFlag := flags.String("output", "text", "Output format: text, or diagnostics for one JSON record per finding (implies -lint)")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex batch [options] <file>\n\n")
+		fmt.Fprintf(os.Stderr, "Explains or lints each pattern in a file (or - for stdin), one pattern per line.\n")
+		fmt.Fprintf(os.Stderr, "Lines can name their flavor as flavor<TAB>pattern. Blank lines and lines starting with # are skipped.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("batch needs exactly one file of patterns")
+	}
+	defaultFormat := strings.ToLower(*formatFlag)
+	if !utils.IsValidFormat(defaultFormat) {
+		return fmt.Errorf("unsupported regex format '%s'", defaultFormat)
+	}
+	if !utils.IsValidColorMode(*colorFlag) {
+		return fmt.Errorf("unsupported color mode '%s'", *colorFlag)
+	}
+	if *outputFlag != "text" && *outputFlag != "diagnostics" {
+		return fmt.Errorf("unsupported batch output '%s' (supported: text, diagnostics)", *outputFlag)
+	}
+	diagnostics := *outputFlag == "diagnostics"
+	app.SetColor(app.UseColor(*colorFlag) && app.EnableVirtualTerminal())
+
+	path := flags.Arg(0)
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	patterns, errorCount, warningCount := 0, 0, 0
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	report := func(line, column int, severity, message string) error {
+		if severity == app.SeverityError {
+			errorCount++
+		} else {
+			warningCount++
+		}
+		if diagnostics {
+			return encoder.Encode(batchDiagnostic{path, line, column, severity, message})
+		}
+		_, err := fmt.Printf("%s:%d:%d: %s: %s\n", path, line, column, severity, message)
+		return err
+	}
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pattern, format := line, defaultFormat
+		if flavor, rest, found := strings.Cut(line, "\t"); found {
+			format, pattern = strings.ToLower(flavor), rest
+			if !utils.IsValidFormat(format) {
+				patterns++
+				if err := report(lineNumber, 1, app.SeverityError, fmt.Sprintf("unsupported regex format '%s'", format)); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		patterns++
+
+		if !*lintFlag && !diagnostics {
+			rule := app.Glyph("──", "--")
+			fmt.Printf("%s %s:%d (%s) %s\n", rule, path, lineNumber, format, rule)
+			app.ExplainRegex(os.Stdout, pattern, format, false, false)
+			fmt.Println()
+		}
+
+		column := len(line) - len(pattern) + 1
+		for _, finding := range app.Lint(pattern, format) {
+			if err := report(lineNumber, column+finding.Offset, finding.Severity, finding.Message); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// Diagnostics are for tools, which would trip over a summary line
+	if !diagnostics {
+		fmt.Printf("\n%d pattern(s) checked: %d error(s), %d warning(s)\n", patterns, errorCount, warningCount)
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("%d pattern error(s) found", errorCount)
+	}
+	return nil
+}
+
+// runTest matches a pattern against each input and reports where the match
+// and each capture group start and end, in bytes and in runes
+func runTest(args []string) error {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	formatFlag := flags.String("format", "go", "Regex format/flavor the pattern is written in")
+	flagsFlag := flags.String("flags", "", "Flags the pattern is compiled with outside it, such as i or re.IGNORECASE")
+	outputFlag := flags.String("output", "text", "Output format: text, or json for one JSON record per input")
+	overlappingFlag := flags.Bool("overlapping", false, "Report every match, including overlapping ones, by restarting one rune after each match start")
+	fileFlag := flags.String("f", "", "Match the contents of a file, or - for stdin, reporting every match with its line and column")
+	decompressFlag := flags.Bool("decompress", false, "Decompress the -f input as gzip (implied for files ending in .gz)")
+	afterFlag := flags.Int("A", 0, "Print the -f input's matching lines like grep, with N lines of context after each")
+	beforeFlag := flags.Int("B", 0, "Print the -f input's matching lines like grep, with N lines of context before each")
+	contextFlag := flags.Int("C", 0, "Print the -f input's matching lines like grep, with N lines of context around each")
+	colorFlag := flags.String("color", "auto", "When to color the matching lines printed with -A, -B, -C or -e (always, never, auto)")
+	var patternFlag patternList
+	flags.Var(&patternFlag, "e", "Print the lines any of the patterns match like grep, coloring each pattern's matches (repeatable)")
+	allFlag := flags.Bool("all", false, "With -e, print the lines every pattern matches instead of any")
+	importFlag := flags.String("import", "", "Match the regexes in a JSON export from a playground such as regex101 (- for stdin) against their test strings")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex test [options] <pattern> <input> [input...]\n")
+		fmt.Fprintf(os.Stderr, "  unregex test [options] -f <file> <pattern>\n")
+		fmt.Fprintf(os.Stderr, "  unregex test [options] -e <pattern> [-e <pattern>...] [-all] -f <file>\n")
+		fmt.Fprintf(os.Stderr, "  unregex test [options] -import <export.json>\n\n")
+		fmt.Fprintf(os.Stderr, "Matches the pattern against each input and reports the start and end of the match\n")
+		fmt.Fprintf(os.Stderr, "and of each capture group as byte and rune offsets, like JavaScript's d flag.\n")
+		fmt.Fprintf(os.Stderr, "Matches in multi-line inputs also get their 1-based line and column.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	// Patterns given with -e leave every argument to be an input
+	patterns, inputs := []string(patternFlag), flags.Args()
+	if len(patterns) == 0 && len(inputs) > 0 {
+		patterns, inputs = inputs[:1], inputs[1:]
+	}
+	switch {
+	case *importFlag != "":
+		if len(patterns) > 0 || len(inputs) > 0 || *fileFlag != "" {
+			flags.Usage()
+			return fmt.Errorf("test -import takes no patterns, inputs, -e or -f")
+		}
+	case len(patterns) == 0:
+		flags.Usage()
+		return fmt.Errorf("test needs a pattern")
+	case *fileFlag != "" && len(inputs) > 0:
+		flags.Usage()
+		return fmt.Errorf("test -f takes no other inputs")
+	case *fileFlag == "" && len(inputs) == 0:
+		flags.Usage()
+		return fmt.Errorf("test needs at least one input")
+	case len(patternFlag) > 0 && *overlappingFlag:
+		return fmt.Errorf("-overlapping can't be combined with -e")
+	}
+	format := strings.ToLower(*formatFlag)
+	if !utils.IsValidFormat(format) {
+		return fmt.Errorf("unsupported regex format '%s'", format)
+	}
+	if *outputFlag != "text" && *outputFlag != "json" {
+		return fmt.Errorf("unsupported test output '%s' (supported: text, json)", *outputFlag)
+	}
+
+	// Any of -A, -B and -C, even 0, prints matching lines like grep, as
+	// -e does
+	lines := false
+	flags.Visit(func(f *flag.Flag) { lines = lines || f.Name == "A" || f.Name == "B" || f.Name == "C" })
+	before, after := max(*beforeFlag, *contextFlag), max(*afterFlag, *contextFlag)
+	switch {
+	case lines && ((*fileFlag == "" && len(patternFlag) == 0) || *outputFlag != "text"):
+		return fmt.Errorf("-A, -B and -C print lines of a -f input as text")
+	case before < 0 || after < 0:
+		return fmt.Errorf("lines of context can't be negative")
+	case !utils.IsValidColorMode(*colorFlag):
+		return fmt.Errorf("unsupported color mode '%s'", *colorFlag)
+	}
+	app.SetColor(app.UseColor(*colorFlag) && app.EnableVirtualTerminal())
+	if *importFlag != "" {
+		return testSessions(*importFlag, *outputFlag, *overlappingFlag)
+	}
+
+	compileFlags, err := app.ResolveFlags(format, *flagsFlag)
+	if err != nil {
+		return err
+	}
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		r, err := app.CompilePattern(pattern, format, compileFlags)
+		if err != nil {
+			return err
+		}
+		compiled = append(compiled, r)
+	}
+	r := compiled[0]
+
+	// A file is matched as one input, reporting every match in it
+	source := ""
+	if *fileFlag != "" {
+		var data []byte
+		if data, err = readTestInput(*fileFlag, *decompressFlag); err != nil {
+			return err
+		}
+		source = *fileFlag
+		if source == "-" {
+			source = "stdin"
+		}
+		inputs = []string{string(data)}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	unmatched := 0
+	for _, input := range inputs {
+		match := app.MatchInput
+		switch {
+		case *overlappingFlag:
+			match = app.MatchOverlapping
+		case source != "":
+			match = app.MatchAll
+		}
+		var result app.MatchResult
+		if len(patternFlag) > 0 {
+			result = app.MatchPatterns(compiled, input, *allFlag)
+		} else {
+			result = match(r, input)
+		}
+		result.Source = source
+		if !result.Matched {
+			unmatched++
+		}
+		if *outputFlag == "json" {
+			if err := encoder.Encode(result); err != nil {
+				return err
+			}
+			continue
+		}
+		if lines || len(patternFlag) > 0 {
+			fmt.Print(app.RenderMatchLines(result, before, after))
+			continue
+		}
+		fmt.Print(app.RenderMatch(result))
+	}
+	if unmatched > 0 {
+		return fmt.Errorf("%d of %d input(s) didn't match", unmatched, len(inputs))
+	}
+	return nil
+}
+
+// runBench times how long a pattern takes to match inputs and shows which of
+// its tokens the time goes to
+func runBench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	formatFlag := flags.String("format", "go", "Regex format/flavor the pattern is written in")
+	flagsFlag := flags.String("flags", "", "Flags the pattern is compiled with outside it, such as i or re.IGNORECASE")
+	fileFlag := flags.String("f", "", "Match the contents of a file, or - for stdin, instead of inputs")
+	timeFlag := flags.Duration("time", time.Second, "About how long to spend benchmarking")
+	outputFlag := flags.String("output", "text", "Output format: text, or json")
+	colorFlag := flags.String("color", "auto", "When to color the heatmap (always, never, auto)")
+	saveFlag := flags.String("save", "", "Save the results to a baseline file")
+	checkFlag := flags.String("check", "", "Fail if throughput regressed against a baseline file")
+	thresholdFlag := flags.Float64("threshold", 10, "Percentage throughput may drop by before -check fails")
+	cpuProfileFlag := flags.String("cpuprofile", "", "Write a CPU profile of the benchmark to a file")
+	memProfileFlag := flags.String("memprofile", "", "Write a memory allocation profile of the benchmark to a file")
+	enginesFlag := flags.String("engines", "", "Run the pattern under engines instead, such as go,backtrack")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex bench [options] <pattern> <input> [input...]\n")
+		fmt.Fprintf(os.Stderr, "  unregex bench [options] -f <file> <pattern>\n\n")
+		fmt.Fprintf(os.Stderr, "Times how long the pattern takes to find every match in the inputs with Go's regexp\n")
+		fmt.Fprintf(os.Stderr, "package, and attributes the time to its parts by timing each prefix of the pattern.\n")
+		fmt.Fprintf(os.Stderr, "The pattern is shown as a heatmap of where the time goes.\n\n")
+		fmt.Fprintf(os.Stderr, "-save records the results in a baseline file, and -check fails when the throughput\n")
+		fmt.Fprintf(os.Stderr, "dropped by more than -threshold percent against one. -cpuprofile and -memprofile write\n")
+		fmt.Fprintf(os.Stderr, "profiles of the benchmark for 'go tool pprof'.\n\n")
+		fmt.Fprintf(os.Stderr, "-engines runs the pattern under matching engines: go, Go's regexp package, which like\n")
+		fmt.Fprintf(os.Stderr, "RE2 never backtracks, and backtrack, unregex's model of PCRE, regexp2 and other\n")
+		fmt.Fprintf(os.Stderr, "backtracking engines, which counts the steps they take. Those engines aren't built in,\n")
+		fmt.Fprintf(os.Stderr, "so backtrack's time isn't theirs.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	switch {
+	case *fileFlag != "" && flags.NArg() != 1:
+		flags.Usage()
+		return fmt.Errorf("bench -f needs a pattern and no other inputs")
+	case *fileFlag == "" && flags.NArg() < 2:
+		flags.Usage()
+		return fmt.Errorf("bench needs a pattern and at least one input")
+	}
+	format := strings.ToLower(*formatFlag)
+	if !utils.IsValidFormat(format) {
+		return fmt.Errorf("unsupported regex format '%s'", format)
+	}
+	if *outputFlag != "text" && *outputFlag != "json" {
+		return fmt.Errorf("unsupported bench output '%s' (supported: text, json)", *outputFlag)
+	}
+	if !utils.IsValidColorMode(*colorFlag) {
+		return fmt.Errorf("unsupported color mode '%s'", *colorFlag)
+	}
+	if *timeFlag <= 0 {
+		return fmt.Errorf("invalid -time %v, it has to be positive", *timeFlag)
+	}
+	if *thresholdFlag < 0 || *thresholdFlag >= 100 {
+		return fmt.Errorf("invalid -threshold %v, it has to be from 0 up to 100", *thresholdFlag)
+	}
+	var engines []string
+	for _, engine := range strings.Split(*enginesFlag, ",") {
+		if engine = strings.ToLower(strings.TrimSpace(engine)); engine != "" {
+			engines = append(engines, engine)
+		}
+	}
+	if len(engines) > 0 && (*saveFlag != "" || *checkFlag != "") {
+		return fmt.Errorf("bench -engines can't be combined with -save or -check")
+	}
+	var baseline *app.BenchResult
+	if *checkFlag != "" {
+		var err error
+		if baseline, err = app.LoadBaseline(*checkFlag); err != nil {
+			return err
+		}
+	}
+	app.SetColor(app.UseColor(*colorFlag) && app.EnableVirtualTerminal())
+
+	compileFlags, err := app.ResolveFlags(format, *flagsFlag)
+	if err != nil {
+		return err
+	}
+	inputs := flags.Args()[1:]
+	if *fileFlag != "" {
+		data, err := readTestInput(*fileFlag, false)
+		if err != nil {
+			return err
+		}
+		inputs = []string{string(data)}
+	}
+
+	if len(engines) > 0 {
+		var results []app.EngineResult
+		err := profileBench(*cpuProfileFlag, *memProfileFlag, func() (err error) {
+			results, err = app.BenchEngines(flags.Arg(0), format, compileFlags, inputs, engines, *timeFlag)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if *outputFlag == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetEscapeHTML(false)
+			return encoder.Encode(results)
+		}
+		fmt.Print(app.RenderEngineBench(results))
+		return nil
+	}
+
+	var result *app.BenchResult
+	err = profileBench(*cpuProfileFlag, *memProfileFlag, func() (err error) {
+		result, err = app.Bench(flags.Arg(0), format, compileFlags, inputs, *timeFlag)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if *outputFlag == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	} else {
+		fmt.Print(app.RenderBench(result))
+	}
+
+	if *saveFlag != "" {
+		if err := app.SaveBaseline(*saveFlag, result); err != nil {
+			return err
+		}
+	}
+	if baseline != nil {
+		return app.CheckRegression(baseline, result, *thresholdFlag/100)
+	}
+	return nil
+}
+
+// profileBench runs a benchmark, writing a CPU profile of it to cpuPath and
+// a profile of the memory it allocated to memPath, when they're set
+func profileBench(cpuPath, memPath string, bench func() error) error {
+	if cpuPath != "" {
+		file, err := os.Create(cpuPath)
+		if err != nil {
+			return fmt.Errorf("failed to create CPU profile: %v", err)
+		}
+		defer file.Close()
+		if err := pprof.StartCPUProfile(file); err != nil {
+			return fmt.Errorf("failed to start CPU profile: %v", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	if err := bench(); err != nil || memPath == "" {
+		return err
+	}
+	file, err := os.Create(memPath)
+	if err != nil {
+		return fmt.Errorf("failed to create memory profile: %v", err)
+	}
+	defer file.Close()
+	runtime.GC()
+	if err := pprof.Lookup("allocs").WriteTo(file, 0); err != nil {
+		return fmt.Errorf("failed to write memory profile: %v", err)
+	}
+	return nil
+}
+
+// runExplore opens the interactive match explorer on the matches of a
+// pattern in a file
+func runExplore(args []string) error {
+	flags := flag.NewFlagSet("explore", flag.ExitOnError)
+	formatFlag := flags.String("format", "go", "Regex format/flavor the pattern is written in")
+	flagsFlag := flags.String("flags", "", "Flags the pattern is compiled with outside it, such as i or re.IGNORECASE")
+	decompressFlag := flags.Bool("decompress", false, "Decompress the file as gzip (implied for files ending in .gz)")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex explore [options] <pattern> <file>\n\n")
+		fmt.Fprintf(os.Stderr, "Lists the pattern's matches in the file in an interactive view. The arrow keys,\n")
+		fmt.Fprintf(os.Stderr, "Page Up/Down, Home and End move between matches, the capture groups of the\n")
+		fmt.Fprintf(os.Stderr, "selected one are shown beside the list, and q quits.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("explore needs a pattern and a file")
+	}
+	format := strings.ToLower(*formatFlag)
+	if !utils.IsValidFormat(format) {
+		return fmt.Errorf("unsupported regex format '%s'", format)
+	}
+	compileFlags, err := app.ResolveFlags(format, *flagsFlag)
+	if err != nil {
+		return err
+	}
+	r, err := app.CompilePattern(flags.Arg(0), format, compileFlags)
+	if err != nil {
+		return err
+	}
+	if flags.Arg(1) == "-" {
+		return fmt.Errorf("explore reads keys from stdin, so it needs a file")
+	}
+	data, err := readTestInput(flags.Arg(1), *decompressFlag)
+	if err != nil {
+		return err
+	}
+
+	result := app.MatchAll(r, string(data))
+	result.Source = flags.Arg(1)
+	return explore.Run(os.Stdin, os.Stdout, result)
+}
+
+// readTestInput reads the file test -f matches, or stdin for -, decompressing
+// it when it's gzipped, as rotated logs often are. The whole decompressed
+// input is read into memory, since it's matched as one input whose matches
+// can span lines.
+func readTestInput(path string, decompress bool) ([]byte, error) {
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	if decompress || strings.HasSuffix(path, ".gz") {
+		reader, err := gzip.NewReader(input)
+		if err != nil {
+			return nil, fmt.Errorf("can't decompress %s: %v", path, err)
+		}
+		defer reader.Close()
+		input = reader
+	}
+	return io.ReadAll(input)
+}
+
+// runGen prints samples generated from a pattern one per line, with nothing
+// else on stdout, so they can be piped into other tools
+func runGen(args []string) error {
+	flags := flag.NewFlagSet("gen", flag.ExitOnError)
+	formatFlag := flags.String("format", "go", "Regex format/flavor the pattern is written in")
+	flagsFlag := flags.String("flags", "", "Flags the pattern is compiled with outside it, such as i or re.IGNORECASE")
+	countFlag := flags.Int("count", 10, "Number of distinct samples to generate")
+	seedFlag := flags.Int64("seed", 0, "Seed for the random choices, to reproduce samples (0 picks one and prints it to stderr)")
+	maxLengthFlag := flags.Int("max-length", 0, "Longest sample to generate (0 for no limit)")
+	realisticFlag := flags.Bool("realistic", false, "Fill repeated classes with real-looking words, years, names and domains")
+	negativeFlag := flags.Bool("negative", false, "Generate near misses the pattern doesn't match instead of matches")
+	streamFlag := flags.Bool("stream", false, "Keep writing new samples until killed instead of stopping after -count")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex gen [options] <pattern>\n")
+		fmt.Fprintf(os.Stderr, "  unregex gen -stream [options] <pattern> | fuzzer\n\n")
+		fmt.Fprintf(os.Stderr, "Prints distinct strings the pattern matches as a whole, one per line, for use as test data.\n")
+		fmt.Fprintf(os.Stderr, "The pattern has to be compatible with Go's regexp package.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("gen needs exactly one pattern")
+	}
+	if *countFlag < 1 {
+		return fmt.Errorf("invalid -count %d, it has to be at least 1", *countFlag)
+	}
+	countSet := false
+	flags.Visit(func(f *flag.Flag) { countSet = countSet || f.Name == "count" })
+	if *streamFlag && countSet {
+		return fmt.Errorf("-stream writes samples until killed, so it can't be combined with -count")
+	}
+	if *maxLengthFlag < 0 {
+		return fmt.Errorf("invalid -max-length %d, it can't be negative", *maxLengthFlag)
+	}
+	seed := *seedFlag
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+		fmt.Fprintf(os.Stderr, "Seed: %d\n", seed)
+	}
+
+	options := app.SampleOptions{
+		Flags:     *flagsFlag,
+		Count:     *countFlag,
+		Seed:      seed,
+		MaxLength: *maxLengthFlag,
+		Realistic: *realisticFlag,
+		Negative:  *negativeFlag,
+	}
+	if *streamFlag {
+		out := bufio.NewWriter(os.Stdout)
+		err := app.StreamSamples(flags.Arg(0), strings.ToLower(*formatFlag), options, func(sample string) error {
+			_, err := fmt.Fprintln(out, sample)
+			return err
+		})
+		if flushErr := out.Flush(); err == nil {
+			err = flushErr
+		}
+		return err
+	}
+
+	samples, err := app.GenerateSamples(flags.Arg(0), strings.ToLower(*formatFlag), options)
+	if err != nil {
+		return err
+	}
+	for _, sample := range samples {
+		fmt.Println(sample)
+	}
+	return nil
+}
+
+// runSimplify rewrites a pattern into a shorter one that matches the same
+// strings, and proves them equivalent where it can
+func runSimplify(args []string) error {
+	flags := flag.NewFlagSet("simplify", flag.ExitOnError)
+	formatFlag := flags.String("format", "go", "Regex format/flavor the pattern is written in")
+	flagsFlag := flags.String("flags", "", "Flags the pattern is compiled with outside it, such as i or re.IGNORECASE")
+	dropCapturesFlag := flags.Bool("drop-captures", false, "Also remove capturing groups, which renumbers any that are left")
+	outputFlag := flags.String("output", "text", "Output format: text, json, or pattern for the simplified pattern alone")
+	colorFlag := flags.String("color", "auto", "When to color the output (always, never, auto)")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex simplify [options] <pattern>\n\n")
+		fmt.Fprintf(os.Stderr, "Rewrites the pattern into a shorter one that matches the same strings, dropping redundant\n")
+		fmt.Fprintf(os.Stderr, "groups, merging literals, collapsing nested quantifiers, counting repeated elements and\n")
+		fmt.Fprintf(os.Stderr, "removing duplicate alternatives. The two patterns are then proven equivalent by comparing\n")
+		fmt.Fprintf(os.Stderr, "their automata, unless the pattern has multi-line anchors or word boundaries. The pattern\n")
+		fmt.Fprintf(os.Stderr, "has to be compatible with Go's regexp package.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("simplify needs exactly one pattern")
+	}
+	if *outputFlag != "text" && *outputFlag != "json" && *outputFlag != "pattern" {
+		return fmt.Errorf("unsupported simplify output '%s' (supported: text, json, pattern)", *outputFlag)
+	}
+	if !utils.IsValidColorMode(*colorFlag) {
+		return fmt.Errorf("unsupported color mode '%s'", *colorFlag)
+	}
+	app.SetColor(app.UseColor(*colorFlag) && app.EnableVirtualTerminal())
+	format := strings.ToLower(*formatFlag)
+	compileFlags, err := app.ResolveFlags(format, *flagsFlag)
+	if err != nil {
+		return err
+	}
+	result, err := app.Simplify(flags.Arg(0), format, compileFlags, *dropCapturesFlag)
+	if err != nil {
+		return err
+	}
+
+	switch *outputFlag {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(result)
+	case "pattern":
+		fmt.Println(result.Simplified)
+	default:
+		fmt.Print(app.RenderSimplification(result))
+	}
+	return nil
+}
+
+// runDocgen documents the exported regex constants of a Go package, typically
+// invoked through a //go:generate unregex docgen directive
+func runDocgen(args []string) error {
+	flags := flag.NewFlagSet("docgen", flag.ExitOnError)
+	formatFlag := flags.String("format", "go", "Regex format/flavor used to explain the constants")
+	outputFlag := flags.String("o", docgen.DefaultOutput, "Name of the generated file, relative to the package directory")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex docgen [options] [dir]\n")
+		fmt.Fprintf(os.Stderr, "  //go:generate unregex docgen\n\n")
+		fmt.Fprintf(os.Stderr, "Writes the explanations of exported regex constants to a generated Go file.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	format := strings.ToLower(*formatFlag)
+	if !utils.IsValidFormat(format) {
+		return fmt.Errorf("unsupported regex format '%s'", format)
+	}
+
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+
+	constants, err := docgen.Generate(dir, *outputFlag, format)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "unregex docgen: documented %d regex constant(s) in %s\n", len(constants), *outputFlag)
+	return nil
+}
+
+// runSelfUpdate replaces the running binary with the latest release after
+// verifying it against the release checksums
+func runSelfUpdate(args []string) error {
+	flags := flag.NewFlagSet("self-update", flag.ExitOnError)
+	checkFlag := flags.Bool("check", false, "Only report whether a newer release is available")
+	forceFlag := flags.Bool("force", false, "Reinstall the latest release even if it isn't newer")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex self-update [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Replaces this binary with the latest GitHub release, verified against the release checksums.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	updater := selfupdate.New()
+	release, err := updater.Latest()
+	if err != nil {
+		return err
+	}
+
+	if !selfupdate.IsNewer(release.Version(), utils.Version) && !*forceFlag {
+		fmt.Printf("unregex %s is up to date\n", utils.Version)
+		return nil
+	}
+	if *checkFlag {
+		fmt.Printf("unregex %s is available (installed: %s)\n", release.Version(), utils.Version)
+		return nil
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the unregex binary: %v", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+	if strings.Contains(exePath, string(filepath.Separator)+"Cellar"+string(filepath.Separator)) {
+		return fmt.Errorf("unregex was installed with Homebrew; run 'brew upgrade unregex' instead")
+	}
+
+	fmt.Printf("Updating unregex %s to %s...\n", utils.Version, release.Version())
+	if err := updater.Install(release, exePath); err != nil {
+		return err
+	}
+	fmt.Printf("Installed unregex %s to %s\n", release.Version(), exePath)
+	return nil
+}
+
+// runHistory lists previously explained patterns, most recent last
+func runHistory(args []string) error {
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	searchFlag := flags.String("search", "", "Only list patterns containing this text (case-insensitive)")
+	limitFlag := flags.Int("limit", 20, "Number of most recent entries to list (0 for all)")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex history [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Lists previously explained patterns. Explain one again with 'unregex again <id>'.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	path, err := history.Path()
+	if err != nil {
+		return err
+	}
+	entries, err := history.Load(path)
+	if err != nil {
+		return err
+	}
+	if *searchFlag != "" {
+		entries = history.Search(entries, *searchFlag)
+	}
+	if *limitFlag > 0 && len(entries) > *limitFlag {
+		entries = entries[len(entries)-*limitFlag:]
+	}
+
+	for _, entry := range entries {
+		fmt.Printf("%5d  %s  %-6s  %s\n", entry.ID, entry.Time.Local().Format("2006-01-02 15:04"), entry.Format, entry.Pattern)
+	}
+	return nil
+}
+
+// againArgs turns the arguments of 'unregex again [options] <id>' into
+// command-line arguments that explain the history entry with that ID, so
+// it gets the same options, colors and paging as any other pattern. Options
+// come after the entry's -format so they can override it.
+func againArgs(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("usage: unregex again [options] <id>")
+	}
+	id, err := strconv.Atoi(args[len(args)-1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid history ID '%s'", args[len(args)-1])
+	}
+
+	path, err := history.Path()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := history.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := history.Find(entries, id)
+	if !ok {
+		return nil, fmt.Errorf("no pattern with ID %d in the history (see 'unregex history')", id)
+	}
+
+	expanded := append([]string{"-format", entry.Format}, args[:len(args)-1]...)
+	return append(expanded, "--", entry.Pattern), nil
+}
+
+// runSave saves a pattern under a name so it can be explained as @name, or
+// lists or deletes saved patterns
+func runSave(args []string) error {
+	flags := flag.NewFlagSet("save", flag.ExitOnError)
+	formatFlag := flags.String("format", "go", "Regex format/flavor the pattern is written in")
+	descriptionFlag := flags.String("description", "", "What the pattern is for")
+	deleteFlag := flags.Bool("delete", false, "Delete the named pattern instead of saving one")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex save [options] <name> <pattern>\n")
+		fmt.Fprintf(os.Stderr, "  unregex save -delete <name>\n")
+		fmt.Fprintf(os.Stderr, "  unregex save\n\n")
+		fmt.Fprintf(os.Stderr, "Saves a pattern so it can be explained with 'unregex explain @name'. With no\n")
+		fmt.Fprintf(os.Stderr, "arguments, lists the saved patterns.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	path, err := saved.Path()
+	if err != nil {
+		return err
+	}
+	patterns, err := saved.Load(path)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case flags.NArg() == 0 && !*deleteFlag:
+		for _, name := range saved.Names(patterns) {
+			entry := patterns[name]
+			fmt.Printf("@%-20s %-6s  %s\n", name, entry.Format, entry.Pattern)
+			if entry.Description != "" {
+				fmt.Printf("  %s\n", entry.Description)
+			}
+		}
+		return nil
+
+	case *deleteFlag:
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return fmt.Errorf("save -delete needs the name of a saved pattern")
+		}
+		name := strings.TrimPrefix(flags.Arg(0), "@")
+		if _, ok := patterns[name]; !ok {
+			return fmt.Errorf("no saved pattern named '%s'", name)
+		}
+		delete(patterns, name)
+		return saved.Write(path, patterns)
+	}
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return fmt.Errorf("save needs a name and a pattern")
+	}
+	name, pattern := strings.TrimPrefix(flags.Arg(0), "@"), flags.Arg(1)
+	if !saved.ValidName(name) {
+		return fmt.Errorf("invalid name '%s': use letters, digits, '.', '_' and '-'", name)
+	}
+	format := strings.ToLower(*formatFlag)
+	if !utils.IsValidFormat(format) {
+		return fmt.Errorf("unsupported regex format '%s'", format)
+	}
+
+	patterns[name] = saved.Pattern{Pattern: pattern, Format: format, Description: *descriptionFlag}
+	if err := saved.Write(path, patterns); err != nil {
+		return err
+	}
+	fmt.Printf("Saved @%s\n", name)
+	return nil
+}
+
+// runLib lists the curated pattern library or shows one of its patterns,
+// with its caveats, every flavor's variant and an explanation
+func runLib(args []string) error {
+	flags := flag.NewFlagSet("lib", flag.ExitOnError)
+	formatFlag := flags.String("format", "go", "Regex format/flavor of the variant to explain")
+	colorFlag := flags.String("color", "auto", "When to color the explanation (always, never, auto)")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex lib list\n")
+		fmt.Fprintf(os.Stderr, "  unregex lib show [options] <name>\n\n")
+		fmt.Fprintf(os.Stderr, "Shows curated patterns for common formats, with their caveats and a variant for each flavor.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+
+	if len(args) == 0 || args[0] == "list" {
+		for _, entry := range library.All() {
+			fmt.Printf("%-10s %s\n", entry.Name, entry.Title)
+		}
+		return nil
+	}
+	if args[0] != "show" {
+		flags.Usage()
+		return fmt.Errorf("unknown lib command '%s'", args[0])
+	}
+	if err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("lib show needs the name of a pattern")
+	}
+	entry, ok := library.Lookup(flags.Arg(0))
+	if !ok {
+		return fmt.Errorf("no pattern named '%s' in the library (see 'unregex lib list')", flags.Arg(0))
+	}
+	format := strings.ToLower(*formatFlag)
+	if !utils.IsValidFormat(format) {
+		return fmt.Errorf("unsupported regex format '%s'", format)
+	}
+	if !utils.IsValidColorMode(*colorFlag) {
+		return fmt.Errorf("unsupported color mode '%s'", *colorFlag)
+	}
+	app.SetColor(app.UseColor(*colorFlag) && app.EnableVirtualTerminal())
+
+	fmt.Printf("%s: %s\n\n%s\n\n", entry.Name, entry.Title, entry.Description)
+	fmt.Printf("Matches:       %s\n", strings.Join(entry.Matches, "  "))
+	fmt.Printf("Doesn't match: %s\n\n", strings.Join(entry.NonMatches, "  "))
+	fmt.Println("Caveats:")
+	for _, caveat := range entry.Caveats {
+		fmt.Printf("  - %s\n", caveat)
+	}
+	fmt.Println("\nVariants:")
+	for _, flavor := range []string{"go", "pcre", "posix", "js", "python"} {
+		fmt.Printf("  %-6s  %s\n", flavor, entry.Variants[flavor])
+	}
+	fmt.Println()
+
+	return app.ExplainRegex(os.Stdout, entry.Variants[format], format, false, false)
+}
+
+// runLSP serves the Language Server Protocol over stdin and stdout
+func runLSP(args []string) error {
+	flags := flag.NewFlagSet("lsp", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex lsp\n\n")
+		fmt.Fprintf(os.Stderr, "Runs a language server on stdin and stdout that explains regexes in Go, Python,\n")
+		fmt.Fprintf(os.Stderr, "JavaScript and TypeScript files on hover, reports problems with them as\n")
+		fmt.Fprintf(os.Stderr, "diagnostics and offers to simplify Go regexes.\n")
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	return lsp.NewServer(os.Stdin, os.Stdout, utils.Version).Run()
+}
+
+// testSessions matches each regex exported from a playground against its
+// own test strings, in its own flavor and with its own flags. Regexes saved
+// with the g flag report every match in each test string.
+func testSessions(path, output string, overlapping bool) error {
+	sessions, err := readSessions(path)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	unmatched, inputs := 0, 0
+	for i, s := range sessions {
+		flags, err := app.ResolveFlags(s.Format, s.Flags)
+		if err != nil {
+			return fmt.Errorf("regex %d: %w", i+1, err)
+		}
+		r, err := app.CompilePattern(s.Pattern, s.Format, flags)
+		if err != nil {
+			return fmt.Errorf("regex %d: %w", i+1, err)
+		}
+		if output == "text" && len(sessions) > 1 {
+			fmt.Print(patternSeparator("text", i, len(sessions), s.Pattern))
+		}
+
+		match := app.MatchInput
+		switch {
+		case overlapping:
+			match = app.MatchOverlapping
+		case s.Global:
+			match = app.MatchAll
+		}
+		for _, input := range s.Inputs {
+			result := match(r, input)
+			inputs++
+			if !result.Matched {
+				unmatched++
+			}
+			if output == "json" {
+				if err := encoder.Encode(result); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Print(app.RenderMatch(result))
+		}
+	}
+	if unmatched > 0 {
+		return fmt.Errorf("%d of %d input(s) didn't match", unmatched, inputs)
+	}
+	return nil
+}
+
+// runServe serves the HTTP API, and the playground with -ui
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addrFlag := flags.String("addr", "localhost:8080", "Address to listen on, such as :8080 for every interface")
+	uiFlag := flags.Bool("ui", false, "Serve the playground page at / as well as the API")
+	grpcAddrFlag := flags.String("grpc-addr", "", "Also serve the gRPC service in api/unregex/v1 on this address, such as localhost:9090")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex serve [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Serves an HTTP API that explains patterns and matches them against test strings:\n\n")
+		fmt.Fprintf(os.Stderr, "  POST /api/explain  {\"pattern\": \"...\", \"flavor\": \"go\", \"flags\": \"\", \"inputs\": [\"...\"]}\n")
+		fmt.Fprintf(os.Stderr, "  GET  /api/flavors  the names of the supported flavors\n\n")
+		fmt.Fprintf(os.Stderr, "With -ui, / serves a playground page built on the API. With -grpc-addr, the\n")
+		fmt.Fprintf(os.Stderr, "Explain, ExplainBatch, Test, Convert and Lint gRPC methods are served there\n")
+		fmt.Fprintf(os.Stderr, "over HTTP/2 without TLS.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("serve takes no arguments")
+	}
+
+	// Listen before reporting the address, so a port of 0 is reported as
+	// the one picked
+	listener, err := net.Listen("tcp", *addrFlag)
+	if err != nil {
+		return err
+	}
+	url := "http://" + listener.Addr().String()
+	if *uiFlag {
+		fmt.Fprintf(os.Stderr, "Serving the playground on %s/\n", url)
+	} else {
+		fmt.Fprintf(os.Stderr, "Serving the API on %s/api/\n", url)
+	}
+	if *grpcAddrFlag == "" {
+		return http.Serve(listener, server.NewServer(*uiFlag))
+	}
+
+	grpcListener, err := net.Listen("tcp", *grpcAddrFlag)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Serving gRPC on %s\n", grpcListener.Addr())
+	errs := make(chan error, 2)
+	go func() { errs <- http.Serve(listener, server.NewServer(*uiFlag)) }()
+	go func() { errs <- grpcserver.Serve(grpcListener, grpcserver.NewServer()) }()
+	return <-errs
+}
+
+// runShare prints a token encoding a pattern, its flavor and flags and test
+// strings, for 'unregex open' to reproduce
+func runShare(args []string) error {
+	flags := flag.NewFlagSet("share", flag.ExitOnError)
+	formatFlag := flags.String("format", "go", "Regex format/flavor the pattern is written in")
+	flagsFlag := flags.String("flags", "", "Flags the pattern is compiled with outside it, such as i or re.IGNORECASE")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex share [options] <pattern> [test string...]\n\n")
+		fmt.Fprintf(os.Stderr, "Prints a compact URL-safe token for the pattern, its flavor and flags and any\n")
+		fmt.Fprintf(os.Stderr, "test strings, which 'unregex open <token>' explains and matches again.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("share needs a pattern")
+	}
+	format := strings.ToLower(*formatFlag)
+	if !utils.IsValidFormat(format) {
+		return fmt.Errorf("unsupported regex format '%s'", format)
+	}
+	compileFlags, err := app.ResolveFlags(format, *flagsFlag)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(share.Encode(share.Permalink{
+		Pattern: flags.Arg(0),
+		Format:  format,
+		Flags:   compileFlags,
+		Inputs:  flags.Args()[1:],
+	}))
+	return nil
+}
+
+// runOpen explains the pattern a share token encodes, and matches it
+// against the token's test strings
+func runOpen(args []string) error {
+	flags := flag.NewFlagSet("open", flag.ExitOnError)
+	visualizeFlag := flags.Bool("visualize", false, "Output visual annotation of the regex with numbered parts")
+	colorFlag := flags.String("color", "auto", "When to color the output (always, never, auto)")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage:\n")
+		fmt.Fprintf(os.Stderr, "  unregex open [options] <token>\n\n")
+		fmt.Fprintf(os.Stderr, "Explains the pattern in a token made by 'unregex share', in its flavor and with\n")
+		fmt.Fprintf(os.Stderr, "its flags, and matches it against the token's test strings.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("open needs a share token")
+	}
+	if !utils.IsValidColorMode(*colorFlag) {
+		return fmt.Errorf("unsupported color mode '%s'", *colorFlag)
+	}
+	link, err := share.Decode(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	app.SetColor(app.UseColor(*colorFlag) && app.EnableVirtualTerminal())
+
+	if _, err := app.Run(app.RunOptions{
+		Pattern:   link.Pattern,
+		Flavor:    link.Format,
+		Flags:     link.Flags,
+		Visualize: *visualizeFlag,
+	}); err != nil {
+		return err
+	}
+	if len(link.Inputs) == 0 {
+		return nil
+	}
+
+	fmt.Println("\nTest strings:")
+	r, err := app.CompilePattern(link.Pattern, link.Format, link.Flags)
+	if err != nil {
+		return err
+	}
+	for _, input := range link.Inputs {
+		fmt.Print(app.RenderMatch(app.MatchInput(r, input)))
+	}
+	return nil
+}
@@ -1,7 +1,5 @@
 module github.com/weslien/unregex
 
-go 1.21
+go 1.24
 
-require (
-	// Dependencies will be added here
-) 
\ No newline at end of file
+require golang.org/x/text v0.14.0
@@ -0,0 +1,188 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// AccessibleRenderer renders an explanation as plain prose for screen
+// readers, without color, tables or box drawing. Each token is read out
+// symbol by symbol, such as "backslash d", with its position in words.
+type AccessibleRenderer struct{}
+
+// Render writes the spoken explanation of exp to w
+func (r *AccessibleRenderer) Render(w io.Writer, exp *Explanation) error {
+	var result strings.Builder
+
+	length := utf8.RuneCountInString(exp.Pattern)
+	fmt.Fprintf(&result, "Regex pattern: %s\n", exp.Pattern)
+	fmt.Fprintf(&result, "Format: %s.\n", exp.Format)
+	if exp.Flags != "" {
+		fmt.Fprintf(&result, "Flags: %s.\n", exp.FlagsDescription)
+	}
+	fmt.Fprintf(&result, "The pattern is %s long and has %s.\n\n", plural(length, "character"), plural(len(exp.Tokens), "token"))
+
+	var supported, unsupported []string
+	for _, feature := range exp.Features {
+		if feature.Supported {
+			supported = append(supported, feature.Name)
+		} else {
+			unsupported = append(unsupported, feature.Name)
+		}
+	}
+	if len(supported) > 0 {
+		fmt.Fprintf(&result, "Supported features: %s.\n", strings.Join(supported, ", "))
+	}
+	if len(unsupported) > 0 {
+		fmt.Fprintf(&result, "Unsupported features: %s.\n", strings.Join(unsupported, ", "))
+	}
+	result.WriteString("\n")
+
+	pos := 0
+	for i, token := range exp.Tokens {
+		position := ""
+		if tokenPos := strings.Index(exp.Pattern[pos:], token.Token); tokenPos >= 0 {
+			start := utf8.RuneCountInString(exp.Pattern[:pos+tokenPos])
+			end := start + utf8.RuneCountInString(token.Token)
+			position = ", " + spokenPosition(start, end, length)
+			pos += tokenPos + len(token.Token)
+		}
+		fmt.Fprintf(&result, "Token %d, %s%s, means: %s\n", i+1, spokenToken(token.Token), position, token.Explanation)
+	}
+
+	if exp.Sample != "" {
+		fmt.Fprintf(&result, "\nExample match: %q. %s.\n", exp.Sample, exp.SampleStatus)
+	}
+
+	_, err := io.WriteString(w, result.String())
+	return err
+}
+
+// spokenSymbols are the names screen readers can't be relied on to give
+// the symbols in patterns, since many skip punctuation by default
+var spokenSymbols = map[rune]string{
+	'^': "caret", '$': "dollar sign", '.': "period", '*': "asterisk", '+': "plus sign",
+	'?': "question mark", '|': "vertical bar", '\\': "backslash", '/': "slash",
+	'(': "opening parenthesis", ')': "closing parenthesis", '[': "opening bracket", ']': "closing bracket",
+	'{': "opening brace", '}': "closing brace", '<': "less-than sign", '>': "greater-than sign",
+	'-': "hyphen", ',': "comma", ':': "colon", ';': "semicolon", '=': "equals sign", '!': "exclamation mark",
+	'&': "ampersand", '#': "number sign", '@': "at sign", '%': "percent sign", '~': "tilde", '`': "backtick",
+	'\'': "apostrophe", '"': "quotation mark", '_': "underscore",
+	' ': "space", '\t': "tab", '\n': "newline", '\r': "carriage return",
+}
+
+// spokenToken reads a token out, naming a single character, such as "a
+// plus sign" or "the capital letter P", and otherwise each symbol in turn
+// with runs of letters and digits as they are, such as "backslash capital D"
+func spokenToken(token string) string {
+	if utf8.RuneCountInString(token) == 1 {
+		c, _ := utf8.DecodeRuneInString(token)
+		switch {
+		case spokenSymbols[c] != "":
+			return article(spokenSymbols[c]) + " " + spokenSymbols[c]
+		case unicode.IsUpper(c):
+			return "the capital letter " + token
+		case unicode.IsLetter(c):
+			return "the letter " + token
+		case unicode.IsDigit(c):
+			return "the digit " + token
+		}
+		return "the character " + strconv.QuoteRune(c)
+	}
+
+	var words []string
+	for len(token) > 0 {
+		c, size := utf8.DecodeRuneInString(token)
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			name := spokenSymbols[c]
+			if name == "" {
+				name = strconv.QuoteRune(c)
+			}
+			words = append(words, name)
+			token = token[size:]
+			continue
+		}
+
+		// A lone capital is called out, as \D and \d mean opposite things
+		end := strings.IndexFunc(token, func(c rune) bool { return !unicode.IsLetter(c) && !unicode.IsDigit(c) })
+		if end < 0 {
+			end = len(token)
+		}
+		word := token[:end]
+		if end == size && unicode.IsUpper(c) {
+			word = "capital " + word
+		}
+		words = append(words, word)
+		token = token[end:]
+	}
+	return strings.Join(words, " ")
+}
+
+// article returns "a" or "an" for a name
+func article(name string) string {
+	if strings.ContainsRune("aeiou", rune(name[0])) {
+		return "an"
+	}
+	return "a"
+}
+
+// spokenPosition says where the characters from start to end of a pattern
+// of length characters are, such as "at the third character" or "from the
+// second to the last character"
+func spokenPosition(start, end, length int) string {
+	switch {
+	case start == 0 && end == length && length > 1:
+		return "spanning the whole pattern"
+	case end-start <= 1:
+		return "at the " + ordinal(start+1, length) + " character"
+	}
+	return "from the " + ordinal(start+1, length) + " to the " + ordinal(end, length) + " character"
+}
+
+// ordinalWords are the ordinals spelled out up to the nineteenth, and
+// tensWords the multiples of ten, which later ordinals are made of
+var (
+	ordinalWords = []string{"", "first", "second", "third", "fourth", "fifth", "sixth", "seventh", "eighth", "ninth", "tenth",
+		"eleventh", "twelfth", "thirteenth", "fourteenth", "fifteenth", "sixteenth", "seventeenth", "eighteenth", "nineteenth"}
+	tensWords = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+)
+
+// ordinal spells out position n of length, such as "twenty-third", as
+// "last" for the last one, and with a suffix, such as 101st, past the
+// ninety-ninth
+func ordinal(n, length int) string {
+	switch {
+	case n == length && n > 1:
+		return "last"
+	case n < len(ordinalWords):
+		return ordinalWords[n]
+	case n < 100 && n%10 == 0:
+		return strings.TrimSuffix(tensWords[n/10], "y") + "ieth"
+	case n < 100:
+		return tensWords[n/10] + "-" + ordinalWords[n%10]
+	}
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+// plural counts n of something, such as "1 token" or "4 tokens"
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
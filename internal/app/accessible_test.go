@@ -0,0 +1,72 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSpokenToken(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"+", "a plus sign"},
+		{"*", "an asterisk"},
+		{"P", "the capital letter P"},
+		{"x", "the letter x"},
+		{"7", "the digit 7"},
+		{`\d`, "backslash d"},
+		{`\D`, "backslash capital D"},
+		{"(?P<id>", "opening parenthesis question mark capital P less-than sign id greater-than sign"},
+		{"{2,4}", "opening brace 2 comma 4 closing brace"},
+		{"hello world", "hello space world"},
+	}
+	for _, tt := range tests {
+		if got := spokenToken(tt.token); got != tt.want {
+			t.Errorf("spokenToken(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestSpokenPosition(t *testing.T) {
+	tests := []struct {
+		start, end, length int
+		want               string
+	}{
+		{0, 1, 5, "at the first character"},
+		{1, 3, 5, "from the second to the third character"},
+		{3, 5, 5, "from the fourth to the last character"},
+		{0, 3, 3, "spanning the whole pattern"},
+		{0, 1, 1, "at the first character"},
+		{19, 23, 40, "from the twentieth to the twenty-third character"},
+		{99, 101, 200, "from the 100th to the 101st character"},
+		{110, 112, 200, "from the 111th to the 112th character"},
+	}
+	for _, tt := range tests {
+		if got := spokenPosition(tt.start, tt.end, tt.length); got != tt.want {
+			t.Errorf("spokenPosition(%d, %d, %d) = %q, want %q", tt.start, tt.end, tt.length, got, tt.want)
+		}
+	}
+}
+
+func TestAccessibleRenderer(t *testing.T) {
+	var out bytes.Buffer
+	if err := (&AccessibleRenderer{}).Render(&out, AnalyzeWithFlags(`^\d+$`, "go", "")); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{
+		"The pattern is 5 characters long and has 4 tokens.\n",
+		"Token 1, a caret, at the first character, means: ",
+		"Token 2, backslash d, from the second to the third character, means: ",
+		"Token 4, a dollar sign, at the last character, means: ",
+		"Example match: ",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Render() should contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if strings.ContainsAny(out.String(), "\033━│") {
+		t.Errorf("Render() should have no color or box drawing, got:\n%s", out.String())
+	}
+}
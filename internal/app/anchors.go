@@ -0,0 +1,101 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// anchorTokens are the anchors the anchor table describes, in its order
+var anchorTokens = []string{"^", "$", `\A`, `\z`, `\Z`, `\b`, `\B`}
+
+// anchorSemantics describes where an anchor matches in a flavor, with the
+// modifier letters in effect at it and whether its word characters are
+// Unicode-aware. Flavors that don't have the anchor get "" so the table can
+// say so.
+func anchorSemantics(token, formatName, flags string, unicode bool) string {
+	multiline := strings.ContainsRune(flags, 'm')
+	switch token {
+	case "^":
+		switch {
+		case !multiline:
+			return "only at the start of the input"
+		case formatName == "js":
+			return "at the start of the input and after every line terminator (\\n, \\r, U+2028 or U+2029) (m is on)"
+		}
+		return "at the start of the input and after every \\n (m is on)"
+	case "$":
+		switch {
+		case multiline && formatName == "js":
+			return "before every line terminator (\\n, \\r, U+2028 or U+2029) and at the end of the input (m is on)"
+		case multiline:
+			return "before every \\n and at the end of the input (m is on)"
+		case formatName == "pcre" && strings.ContainsRune(flags, 'D'):
+			return "only at the very end of the input (D is on)"
+		case formatName == "pcre" || formatName == "python":
+			return "at the end of the input, and also just before a \\n that ends it"
+		case formatName == "go" || formatName == "js":
+			return "only at the very end of the input, never before a final \\n"
+		}
+		return "only at the end of the input"
+	case `\A`:
+		if formatName == "go" || formatName == "pcre" || formatName == "python" {
+			return "only at the start of the input, even when m is on"
+		}
+	case `\z`:
+		switch formatName {
+		case "go", "pcre":
+			return "only at the very end of the input, even when m is on"
+		case "python":
+			return "only at the very end of the input (Python 3.14 and later, which spell it \\Z before)"
+		}
+	case `\Z`:
+		switch formatName {
+		case "pcre":
+			return "at the end of the input or just before a \\n that ends it, even when m is on"
+		case "python":
+			return "only at the very end of the input, never before a final \\n (unlike \\Z in Perl)"
+		}
+	case `\b`, `\B`:
+		where := "between a word character and a non-word character or the edge of the input"
+		if token == `\B` {
+			where = "anywhere \\b doesn't match"
+		}
+		switch {
+		case formatName == "posix":
+			return ""
+		case unicode:
+			return where + ", with Unicode letters and digits as word characters"
+		}
+		return where + ", with only [A-Za-z0-9_] as word characters"
+	}
+	return ""
+}
+
+// anchorTable describes what each anchor in the pattern means in its
+// flavor with the modifiers in effect, since $ in particular is easily
+// misread. It returns "" when the pattern has no anchors.
+func anchorTable(exp *Explanation) string {
+	seen := make(map[string]bool)
+	var rows []string
+	active := activeModifiers(exp.Tokens, exp.Flags)
+	for _, anchor := range anchorTokens {
+		for i, token := range exp.Tokens {
+			if token.Token != anchor {
+				continue
+			}
+			row := fmt.Sprintf("isn't an anchor in %s", exp.Format)
+			if meaning := anchorSemantics(anchor, exp.FormatName, active[i], unicodeWords(exp, active[i])); meaning != "" {
+				row = "matches " + meaning
+			}
+			if seen[anchor+row] {
+				continue
+			}
+			seen[anchor+row] = true
+			rows = append(rows, fmt.Sprintf("  %s%-3s%s %s\n", colorBold, anchor, colorReset, row))
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%sAnchors:%s\n", colorBold, colorReset) + strings.Join(rows, "")
+}
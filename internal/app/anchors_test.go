@@ -0,0 +1,57 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnchorSemantics(t *testing.T) {
+	tests := []struct {
+		token      string
+		formatName string
+		flags      string
+		unicode    bool
+		want       string
+	}{
+		{"$", "go", "", false, "only at the very end of the input, never before a final \\n"},
+		{"$", "python", "", false, "at the end of the input, and also just before a \\n that ends it"},
+		{"$", "pcre", "D", false, "only at the very end of the input (D is on)"},
+		{"$", "js", "m", false, "before every line terminator (\\n, \\r, U+2028 or U+2029) and at the end of the input (m is on)"},
+		{"^", "posix", "m", false, "at the start of the input and after every \\n (m is on)"},
+		{`\Z`, "python", "", false, "only at the very end of the input, never before a final \\n (unlike \\Z in Perl)"},
+		{`\Z`, "go", "", false, ""},
+		{`\b`, "python", "", true, "between a word character and a non-word character or the edge of the input, with Unicode letters and digits as word characters"},
+		{`\b`, "pcre", "", false, "between a word character and a non-word character or the edge of the input, with only [A-Za-z0-9_] as word characters"},
+		{`\A`, "js", "", false, ""},
+	}
+
+	for _, tt := range tests {
+		if got := anchorSemantics(tt.token, tt.formatName, tt.flags, tt.unicode); got != tt.want {
+			t.Errorf("anchorSemantics(%q, %q, %q, %v) = %q, want %q", tt.token, tt.formatName, tt.flags, tt.unicode, got, tt.want)
+		}
+	}
+}
+
+func TestAnchorTable(t *testing.T) {
+	SetColor(false)
+	defer SetColor(true)
+
+	got := anchorTable(Analyze(`^a$|(?m)^b`, "pcre"))
+	for _, want := range []string{
+		"Anchors:",
+		"^   matches only at the start of the input",
+		"^   matches at the start of the input and after every \\n (m is on)",
+		"$   matches at the end of the input, and also just before a \\n that ends it",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("anchorTable() should contain %q, got:\n%s", want, got)
+		}
+	}
+
+	if got := anchorTable(Analyze(`/\A/`, "js")); !strings.Contains(got, `\A  isn't an anchor in JavaScript RegExp`) {
+		t.Errorf("anchorTable() = %q, want \\A reported as not an anchor", got)
+	}
+	if got := anchorTable(Analyze("abc", "go")); got != "" {
+		t.Errorf("anchorTable(%q) = %q, want no table", "abc", got)
+	}
+}
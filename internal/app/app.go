@@ -1,26 +1,25 @@
 package app
 
 import (
+	"bytes"
 	"fmt"
-	"math/rand"
+	"io"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
+	"unicode/utf8"
 
-	"github.com/weslien/unregex/internal/format"
+	"github.com/weslien/unregex/pkg/format"
 )
 
-// ANSI color codes
-const (
-	colorReset   = "\033[0m"
-	colorRed     = "\033[31m"
-	colorGreen   = "\033[32m"
-	colorYellow  = "\033[33m"
-	colorBlue    = "\033[34m"
-	colorMagenta = "\033[35m"
-	colorCyan    = "\033[36m"
-	colorBold    = "\033[1m"
+// ANSI codes for the text output, set from the active theme by applyColors
+var (
+	colorReset       string
+	colorBold        string
+	colorSupported   string
+	colorUnsupported string
+	tokenColors      []string
 )
 
 // Common character sets for sample generation
@@ -33,115 +32,375 @@ var (
 	specialChars = "!@#$%^&*()-_=+[]{}|;:,.<>?/"
 )
 
-// Initialize random number generator
-var rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+// RunOptions configures a Run
+type RunOptions struct {
+	// Pattern is the regex to explain
+	Pattern string
+
+	// Fragments are the pieces of source the pattern is joined from, such
+	// as string constants, if any. They make up Pattern when it's empty, and
+	// the explanation shows which tokens each contributed.
+	Fragments []string
+
+	// Flavor is the regex format the pattern is written in, go by default
+	Flavor string
+
+	// Flags are flags set outside the pattern, such as x for Python's
+	// re.VERBOSE
+	Flags string
+
+	// Visualize adds the annotated pattern and an example match to the
+	// terminal text
+	Visualize bool
+
+	// Examples configures the example match
+	Examples ExampleOptions
+
+	// Accessible renders the terminal text as prose for screen readers,
+	// without color or box drawing
+	Accessible bool
+
+	// Hyperlinks links the terminal explanations to the flavor's docs. The
+	// caller checks SupportsHyperlinks, since stdout may be piped to a pager.
+	Hyperlinks bool
+
+	// Output is the output format, text by default
+	Output string
+
+	// OutputFile is the file document outputs are written to instead of
+	// Writer, if any
+	OutputFile string
+
+	// Template is a text/template file to render instead of Output, if any
+	Template string
+
+	// Writer receives the rendered explanation, os.Stdout by default
+	Writer io.Writer
+}
+
+// Result is the outcome of a Run
+type Result struct {
+	// Explanation is the analysis the output was rendered from
+	Explanation *Explanation
 
-// Run executes the main application logic
-func Run(args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("no regex pattern provided")
+	// OutputFile is the file the output was written to, if any
+	OutputFile string
+}
+
+// Run executes the main application logic, explaining a pattern as
+// configured by opts
+func Run(opts RunOptions) (*Result, error) {
+	if opts.Pattern == "" {
+		opts.Pattern = strings.Join(opts.Fragments, "")
+	}
+	if opts.Pattern == "" {
+		return nil, fmt.Errorf("no regex pattern provided")
+	}
+	if opts.Flavor == "" {
+		opts.Flavor = "go"
+	}
+	if _, ok := format.Lookup(opts.Flavor); !ok {
+		return nil, &ErrUnknownFormat{Format: opts.Flavor}
+	}
+	if err := opts.Examples.Validate(); err != nil {
+		return nil, err
+	}
+	flags, err := ResolveFlags(opts.Flavor, opts.Flags)
+	if err != nil {
+		return nil, err
+	}
+	opts.Flags = flags
+	if opts.Output == "" {
+		opts.Output = "text"
+	}
+	if opts.Writer == nil {
+		opts.Writer = os.Stdout
 	}
 
-	pattern := args[0]
+	var renderer Renderer
+	if opts.Template != "" {
+		renderer = TemplateFileRenderer(opts.Template)
+	} else if opts.Output == "text" && opts.Accessible {
+		renderer = &AccessibleRenderer{}
+	} else if opts.Output == "text" {
+		renderer = &TextRenderer{Visualize: opts.Visualize, Hyperlinks: opts.Hyperlinks}
+	} else {
+		var err error
+		if renderer, err = NewRenderer(opts.Output); err != nil {
+			return nil, err
+		}
+	}
 
-	// Get the format from args or default to "go"
-	formatName := "go"
-	if len(args) > 1 {
-		formatName = args[1]
+	result := &Result{Explanation: AnalyzeWithOptions(opts.Pattern, opts.Flavor, opts.Flags, opts.Examples)}
+	if len(opts.Fragments) > 0 {
+		if strings.Join(opts.Fragments, "") != opts.Pattern {
+			return nil, fmt.Errorf("the fragments don't join into the pattern")
+		}
+		attachFragments(result.Explanation, opts.Fragments)
 	}
 
-	// Check if visualization is enabled
-	visualize := false
-	if len(args) > 2 && args[2] == "true" {
-		visualize = true
+	// The terminal text always goes to the writer
+	if opts.OutputFile == "" || opts.Output == "text" && opts.Template == "" {
+		if err := renderer.Render(opts.Writer, result.Explanation); err != nil {
+			return nil, err
+		}
+		return result, nil
 	}
 
-	return ExplainRegex(pattern, formatName, visualize)
+	var rendered bytes.Buffer
+	if err := renderer.Render(&rendered, result.Explanation); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(opts.OutputFile, rendered.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %v", opts.OutputFile, err)
+	}
+	result.OutputFile = opts.OutputFile
+	return result, nil
+}
+
+// TokenExplanation pairs a token with its human-readable explanation
+type TokenExplanation struct {
+	Token       string `json:"token"`
+	Explanation string `json:"explanation"`
 }
 
-// ExplainRegex parses and explains a regex pattern
-func ExplainRegex(pattern, formatName string, visualize bool) error {
-	// Get the appropriate regex format implementation
+// FeatureSupport records whether a format supports a regex feature
+type FeatureSupport struct {
+	Name      string `json:"name"`
+	Syntax    string `json:"syntax"`
+	Supported bool   `json:"supported"`
+}
+
+// Explanation is the structured result of analyzing a regex pattern
+type Explanation struct {
+	Pattern    string `json:"pattern"`
+	FormatName string `json:"formatName"`
+	Format     string `json:"format"`
+	Flags      string `json:"flags,omitempty"`
+
+	// FlagsDescription explains Flags letter by letter, as the flavor reads
+	// them
+	FlagsDescription string `json:"flagsDescription,omitempty"`
+
+	Tokens       []TokenExplanation `json:"tokens"`
+	Features     []FeatureSupport   `json:"features"`
+	Sample       string             `json:"sample"`
+	SampleStatus string             `json:"sampleStatus"`
+
+	// Seed is the seed the example match's random choices were made from,
+	// if it was given one
+	Seed int64 `json:"seed,omitempty"`
+
+	// NearMisses are strings that fail to match at one token each, for
+	// negative test cases
+	NearMisses []NearMiss `json:"nearMisses,omitempty"`
+
+	// Fragments are the pieces of source the pattern was joined from, if
+	// it was, with the tokens each contributed
+	Fragments []Fragment `json:"fragments,omitempty"`
+
+	// Expanded explains the regex a grok pattern expands to
+	Expanded *Explanation `json:"expanded,omitempty"`
+
+	// examples are the options the example match was generated with, for
+	// renderers that generate it again
+	examples ExampleOptions
+}
+
+// Analyze tokenizes and explains a pattern without rendering it
+func Analyze(pattern, formatName string) *Explanation {
+	return AnalyzeWithFlags(pattern, formatName, "")
+}
+
+// AnalyzeWithFlags analyzes a pattern compiled with flags given outside it,
+// such as x for Python's re.VERBOSE
+func AnalyzeWithFlags(pattern, formatName, flags string) *Explanation {
+	return AnalyzeWithOptions(pattern, formatName, flags, ExampleOptions{})
+}
+
+// AnalyzeWithOptions analyzes a pattern compiled with flags given outside
+// it, generating its example match with the given options
+func AnalyzeWithOptions(pattern, formatName, flags string, opts ExampleOptions) *Explanation {
 	regexFormat := format.GetFormat(formatName)
+	tokens := format.TokenizeWithFlags(regexFormat, pattern, flags)
+
+	exp := &Explanation{
+		Pattern:    pattern,
+		FormatName: formatName,
+		Format:     regexFormat.Name(),
+		Flags:      flags,
+		Seed:       opts.Seed,
+		examples:   opts,
+	}
+	if flags != "" {
+		exp.FlagsDescription = format.DescribeFlags(formatName, flags)
+	}
 
-	fmt.Printf("%sAnalyzing regex pattern:%s %s\n", colorBold, colorReset, pattern)
-	fmt.Printf("Format: %s\n\n", regexFormat.Name())
+	for _, token := range tokens {
+		exp.Tokens = append(exp.Tokens, TokenExplanation{
+			Token:       token,
+			Explanation: regexFormat.ExplainToken(token),
+		})
+	}
 
-	// Get features supported by this format
-	printSupportedFeatures(regexFormat)
+	applyModifiers(exp.Tokens, flags)
+	applyConditionals(exp.Tokens)
+	applySubroutines(pattern, exp.Tokens)
+	applyBytesMode(pattern, formatName, exp.Tokens)
 
-	// Tokenize and explain the pattern
-	tokens := regexFormat.TokenizeRegex(pattern)
+	for _, feature := range features {
+		exp.Features = append(exp.Features, FeatureSupport{
+			Name:      feature.name,
+			Syntax:    feature.description,
+			Supported: regexFormat.HasFeature(feature.code),
+		})
+	}
 
-	// Create a map to rotate through colors for each token
-	colorMap := []string{colorRed, colorGreen, colorBlue, colorYellow, colorMagenta, colorCyan}
+	// A grok pattern is sampled through the regex it expands to, which is
+	// explained as well when it has library references
+	if formatName == "grok" {
+		expanded, err := format.ExpandGrok(pattern)
+		if err != nil {
+			exp.SampleStatus = "Can't expand the grok pattern: " + err.Error()
+			return exp
+		}
+		expandedExp := AnalyzeWithOptions(expanded, "pcre", flags, opts)
+		exp.Sample = expandedExp.Sample
+		exp.SampleStatus = expandedExp.SampleStatus
+		if expanded != pattern {
+			exp.Expanded = expandedExp
+		}
+		return exp
+	}
+
+	samplePattern, sampleTokens, sampleFlags := compactVerbose(exp)
+	sample, _, status, _ := buildSample(samplePattern, formatName, sampleFlags, sampleTokens, opts)
+	exp.Sample = sample
+	exp.SampleStatus = status
+	exp.NearMisses = nearMisses(samplePattern, formatName, sampleFlags, sampleTokens, opts)
+
+	return exp
+}
+
+// ExplainRegex parses and explains a regex pattern as colored terminal text
+// written to w. With hyperlinks enabled, each explanation links to the
+// flavor's documentation using OSC 8.
+func ExplainRegex(w io.Writer, pattern, formatName string, visualize, hyperlinks bool) error {
+	renderer := &TextRenderer{Visualize: visualize, Hyperlinks: hyperlinks}
+	return renderer.Render(w, Analyze(pattern, formatName))
+}
+
+// TextRenderer renders an explanation as the colored terminal text
+type TextRenderer struct {
+	// Visualize adds the annotated pattern and a colored example match
+	Visualize bool
+
+	// Hyperlinks links each explanation to the flavor's documentation
+	Hyperlinks bool
+}
+
+// Render writes the terminal explanation of exp to w
+func (r *TextRenderer) Render(w io.Writer, exp *Explanation) error {
+	var result strings.Builder
+
+	fmt.Fprintf(&result, "%sAnalyzing regex pattern:%s %s\n", colorBold, colorReset, exp.Pattern)
+	fmt.Fprintf(&result, "Format: %s\n", exp.Format)
+	if exp.Flags != "" {
+		fmt.Fprintf(&result, "Flags: %s\n", exp.FlagsDescription)
+	}
+	result.WriteString("\n")
+
+	// Summarize the features supported by this format
+	writeSupportedFeatures(&result, exp.Features)
+
+	// Rotate through the theme's colors for each token
+	colorMap := tokenPalette(len(exp.Tokens))
 
 	// Print the explanations
-	fmt.Printf("%sToken explanations:%s\n", colorBold, colorReset)
-	explanations := make([]string, len(tokens))
-	for i, token := range tokens {
+	fmt.Fprintf(&result, "%sToken explanations:%s\n", colorBold, colorReset)
+	tokens := make([]string, len(exp.Tokens))
+	for i, token := range exp.Tokens {
+		tokens[i] = token.Token
 		color := colorMap[i%len(colorMap)]
-		explanation := regexFormat.ExplainToken(token)
-		explanations[i] = explanation
-		fmt.Printf("%s%s%d.%s %s%s%s%s: %s\n",
+		explanation := token.Explanation
+		if r.Hyperlinks {
+			explanation = hyperlink(TokenDocURL(exp.FormatName, token.Token), explanation)
+		}
+		fmt.Fprintf(&result, "%s%s%d.%s %s%s%s%s: %s\n",
 			color, colorBold, i+1, colorReset,
-			color, colorBold, token, colorReset,
+			color, colorBold, token.Token, colorReset,
 			explanation)
 	}
 
-	// If visualization is enabled, print the annotated pattern
-	if visualize {
-		fmt.Println()
-		annotatedPattern := visualizePattern(pattern, tokens, colorMap)
-		fmt.Println(annotatedPattern)
-
-		// Generate and display a sample matching string
-		fmt.Println(generateSampleMatch(pattern, formatName, tokens, colorMap))
+	// Spell out what the anchors mean here, as they differ between flavors
+	if anchors := anchorTable(exp); anchors != "" {
+		result.WriteString("\n" + anchors)
 	}
 
-	fmt.Println("\nNOTE: This is a basic regex explainer. Some complex patterns might not be perfectly tokenized.")
-
-	return nil
-}
+	// Spell out what \w and \b count as word characters, ASCII or Unicode
+	if words := wordNote(exp); words != "" {
+		result.WriteString("\n" + words)
+	}
 
-// generateSampleMatch creates an example string that matches the regex pattern
-func generateSampleMatch(pattern, formatName string, tokens []string, colorMap []string) string {
-	// Try to generate a deterministic sample based on the tokens
-	sample, tokenMap := generateDeterministicSample(tokens)
+	// Call out case folding quirks of the letters matched case-insensitively
+	if folding := caseFoldingTable(exp); folding != "" {
+		result.WriteString("\n" + folding)
+	}
 
-	// Verify if the generated sample matches the pattern
-	var r *regexp.Regexp
-	var err error
+	// Spell out how \n and \r\n are treated, as Windows line endings trip
+	// up patterns written for \n
+	if lineEndings := lineEndingTable(exp); lineEndings != "" {
+		result.WriteString("\n" + lineEndings)
+	}
 
-	if formatName == "go" {
-		r, err = regexp.Compile(pattern)
-	} else {
-		// For non-Go formats, just attempt to compile but don't rely on match checking
-		r, err = regexp.Compile(pattern)
+	// Show which tokens each fragment of the pattern contributed
+	if len(exp.Fragments) > 0 {
+		result.WriteString("\n" + fragmentTable(exp.Fragments))
 	}
 
-	// If we couldn't compile the pattern or the sample doesn't match,
-	// use a fallback approach with common examples
-	matchStatus := "Verified match"
-	useAlternate := false
+	// Explain the regex a grok pattern expands to, token by token
+	if exp.Expanded != nil {
+		fmt.Fprintf(&result, "\n%sExpanded regex:%s %s\n", colorBold, colorReset, exp.Expanded.Pattern)
+		expandedColors := tokenPalette(len(exp.Expanded.Tokens))
+		for i, token := range exp.Expanded.Tokens {
+			color := expandedColors[i%len(expandedColors)]
+			fmt.Fprintf(&result, "%s%s%d.%s %s%s%s%s: %s\n",
+				color, colorBold, i+1, colorReset,
+				color, colorBold, token.Token, colorReset,
+				token.Explanation)
+		}
+	}
 
-	if err != nil || (r != nil && !r.MatchString(sample)) {
-		matchStatus = "Approximate match (pattern contains advanced features)"
+	// If visualization is enabled, print the annotated pattern
+	if r.Visualize {
+		result.WriteString("\n")
+		result.WriteString(visualizePattern(exp.Pattern, tokens, colorMap, TerminalWidth(), fragmentBoundaries(exp.Fragments)) + "\n")
 
-		// For patterns with alternation, use a special handler
-		if strings.Contains(pattern, "|") {
-			sample = generateAlternativeSample(pattern, formatName)
-			useAlternate = true
-		} else {
-			sample = generateFallbackSample(pattern, formatName)
+		// Show what each character class admits
+		if grids := classGrids(exp, colorMap); grids != "" {
+			result.WriteString(grids + "\n")
 		}
 
-		// Double-check if our alternative sample matches
-		if r != nil && r.MatchString(sample) {
-			matchStatus = "Verified match (using alternative)"
+		// Generate and display a sample matching string
+		samplePattern, sampleTokens, sampleFlags := compactVerbose(exp)
+		result.WriteString(generateSampleMatch(samplePattern, exp.FormatName, sampleFlags, sampleTokens, colorMap, exp.examples) + "\n")
+
+		// Show strings that fail at one token each, for negative tests
+		if misses := nearMissTable(exp, colorMap); misses != "" {
+			result.WriteString(misses + "\n")
 		}
 	}
 
+	result.WriteString("\nNOTE: This is a basic regex explainer. Some complex patterns might not be perfectly tokenized.\n")
+
+	_, err := io.WriteString(w, result.String())
+	return err
+}
+
+// generateSampleMatch creates an example string that matches the regex pattern
+func generateSampleMatch(pattern, formatName, flags string, tokens []string, colorMap []string, opts ExampleOptions) string {
+	sample, tokenMap, matchStatus, useAlternate := buildSample(pattern, formatName, flags, tokens, opts)
+
 	// Build the display string with colors
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("%sExample matching string:%s\n", colorBold, colorReset))
@@ -161,6 +420,9 @@ func generateSampleMatch(pattern, formatName string, tokens []string, colorMap [
 		var coloredSample strings.Builder
 		for i, c := range sample {
 			char := string(c)
+			if symbol, ok := sampleLineBreaks[c]; ok {
+				char = Glyph(symbol[0], symbol[1])
+			}
 
 			// Find the token index for this character
 			tokenIndex := -1
@@ -172,11 +434,16 @@ func generateSampleMatch(pattern, formatName string, tokens []string, colorMap [
 			}
 
 			// Apply color if we found a token
-			if tokenIndex >= 0 && tokenIndex < len(colorMap) {
+			if tokenIndex >= 0 {
 				coloredSample.WriteString(colorMap[tokenIndex%len(colorMap)] + colorBold + char + colorReset)
 			} else {
 				coloredSample.WriteString(char)
 			}
+
+			// Keep the lines of a multi-line sample on lines of their own
+			if c == '\n' {
+				coloredSample.WriteString("\n")
+			}
 		}
 
 		result.WriteString(coloredSample.String() + "\n")
@@ -187,6 +454,81 @@ func generateSampleMatch(pattern, formatName string, tokens []string, colorMap [
 	return result.String()
 }
 
+// buildSample generates an example string for the pattern, returning the
+// sample, the span each token contributed, a description of how well the
+// sample was verified and whether the alternation fallback was used
+func buildSample(pattern, formatName, flags string, tokens []string, opts ExampleOptions) (string, []Position, string, bool) {
+	// Grok patterns are sampled through the regex they expand to, whose
+	// tokens the sample's spans would be of
+	if formatName == "grok" {
+		expanded, err := format.ExpandGrok(pattern)
+		if err != nil {
+			return "", nil, "", false
+		}
+		sample, _, status, _ := buildSample(expanded, "pcre", flags, format.TokenizeWithFlags(format.GetFormat("pcre"), expanded, flags), opts)
+		return sample, nil, status, false
+	}
+
+	// Try to generate a deterministic sample based on the tokens
+	sample, tokenMap, backrefs := generateDeterministicSample(tokens, formatName, flags, -1, opts)
+
+	// Repeat unbounded quantifiers fewer times until the sample fits the
+	// length cap
+	shortened := false
+	for extra := maxSampleRepetitions; opts.MaxLength > 0 && extra >= 0 &&
+		utf8.RuneCountInString(sample) > opts.MaxLength; extra-- {
+		sample, tokenMap, backrefs = generateDeterministicSample(tokens, formatName, flags, extra, opts)
+		shortened = true
+	}
+
+	// Verify if the generated sample matches the pattern
+	var r *regexp.Regexp
+	var err error
+
+	// Check against the pattern as Go would spell it, with the flags set
+	// outside it
+	r, err = CompilePattern(pattern, formatName, flags)
+
+	// Go has no backreferences, so check the sample against the pattern with
+	// each backreference replaced by the text it repeats
+	if err != nil && len(backrefs) > 0 {
+		r, err = CompilePattern(resolveBackreferences(pattern, tokens, backrefs), formatName, flags)
+	}
+
+	// If we couldn't compile the pattern or the sample doesn't match,
+	// use a fallback approach with common examples
+	matchStatus := "Verified match"
+	useAlternate := false
+
+	if err != nil || (r != nil && !r.MatchString(sample)) {
+		matchStatus = "Approximate match (pattern contains advanced features)"
+
+		// For patterns with alternation, use a special handler
+		if strings.Contains(pattern, "|") {
+			sample = generateAlternativeSample(pattern, formatName)
+			useAlternate = true
+		} else {
+			sample = generateFallbackSample(pattern, formatName)
+		}
+
+		// Double-check if our alternative sample matches
+		if r != nil && r.MatchString(sample) {
+			matchStatus = "Verified match (using alternative)"
+		}
+	}
+
+	// Say when the length cap changed the sample or couldn't be met
+	if matchStatus == "Verified match" && shortened {
+		if utf8.RuneCountInString(sample) > opts.MaxLength {
+			matchStatus += fmt.Sprintf(" (longer than the %d characters allowed, even with the fewest repetitions)", opts.MaxLength)
+		} else {
+			matchStatus += fmt.Sprintf(" (repetitions cut to fit %d characters)", opts.MaxLength)
+		}
+	}
+
+	return sample, tokenMap, matchStatus, useAlternate
+}
+
 // colorizeAlternativeExample creates a colored version of the sample string
 // that properly highlights the alternative choice in the pattern
 func colorizeAlternativeExample(pattern, sample string, tokens []string, colorMap []string) string {
@@ -242,14 +584,14 @@ func colorizeAlternativeExample(pattern, sample string, tokens []string, colorMa
 		// Color the chosen alternative
 		if usingAlt2 {
 			// Use a special color for the alternate choice
-			result.WriteString(colorMap[2] + colorBold + alt2 + colorReset)
+			result.WriteString(colorMap[2%len(colorMap)] + colorBold + alt2 + colorReset)
 		} else {
-			result.WriteString(colorMap[1] + colorBold + alt1 + colorReset)
+			result.WriteString(colorMap[1%len(colorMap)] + colorBold + alt1 + colorReset)
 		}
 
 		// Color the suffix (digits)
 		if digits != "" {
-			result.WriteString(colorMap[3] + colorBold + digits + colorReset)
+			result.WriteString(colorMap[3%len(colorMap)] + colorBold + digits + colorReset)
 		}
 	} else {
 		// Fallback if we can't parse the alternation properly
@@ -264,10 +606,51 @@ type Position struct {
 	start, end int
 }
 
-// generateDeterministicSample tries to create a sample string based on the tokens
-func generateDeterministicSample(tokens []string) (string, []Position) {
+// generateDeterministicSample tries to create a sample string based on the
+// tokens. Character classes contribute one of their members, with the
+// flags in effect, and a backreference repeats what was generated for its
+// group. The text each backreference repeated is returned by token index.
+// Unbounded quantifiers repeat their element at most extra times more than
+// their minimum, unless extra is negative. The sample is the same for the
+// same options, random choices included.
+func generateDeterministicSample(tokens []string, formatName, flags string, extra int, opts ExampleOptions) (string, []Position, map[int]string) {
 	var sample strings.Builder
 	tokenMap := make([]Position, len(tokens))
+	random := opts.random()
+
+	// The modifiers in effect at each token, for case-insensitive classes
+	explained := make([]TokenExplanation, len(tokens))
+	for i, token := range tokens {
+		explained[i].Token = token
+	}
+	active := activeModifiers(explained, flags)
+
+	// The groups open at each token, and the text generated for each closed
+	// capturing group by number and by name
+	type openGroup struct {
+		number int // 0 for groups that don't capture
+		name   string
+		start  int
+	}
+	var open []openGroup
+	captured := make(map[string]string)
+	groupCount := 0
+	backrefs := make(map[int]string)
+
+	// The span of the element a quantifier repeats, and whether the last
+	// token was a quantifier, making a ? or + after it lazy or possessive
+	var atom Position
+	afterQuantifier := false
+
+	// The word lists realistic samples draw from
+	var picker *realisticPicker
+	if opts.Realistic {
+		picker = newRealisticPicker()
+	}
+
+	// Whether a multi-line $ was the last thing matched, so the sample has
+	// to break the line before anything else
+	lineEnded := false
 
 	// Stack to track active groups - for handling alternations properly
 	type Group struct {
@@ -305,9 +688,104 @@ func generateDeterministicSample(tokens []string) (string, []Position) {
 	for i, token := range tokens {
 		startPos := sample.Len()
 
+		// Track groups so backreferences can repeat what they captured
+		if name, capturing := captureGroupName(token); capturing || opensGroup(token) {
+			group := openGroup{name: name, start: startPos}
+			if capturing {
+				groupCount++
+				group.number = groupCount
+			}
+			open = append(open, group)
+			tokenMap[i] = Position{startPos, startPos}
+			// A quantifier right after the opener has nothing to repeat
+			atom, afterQuantifier = tokenMap[i], false
+			continue
+		}
+		closedGroup := -1
+		if token == ")" && len(open) > 0 {
+			group := open[len(open)-1]
+			open = open[:len(open)-1]
+			// A quantifier that repeated nothing may have cut the sample
+			// short of where the group started
+			group.start = min(group.start, sample.Len())
+			closedGroup = group.start
+			if group.number > 0 {
+				captured[strconv.Itoa(group.number)] = sample.String()[group.start:]
+				if group.name != "" {
+					captured[group.name] = sample.String()[group.start:]
+				}
+			}
+		}
+		if target, ok := format.BackreferenceTarget(token); ok {
+			if n, err := strconv.Atoi(target); err == nil && n < 0 {
+				target = strconv.Itoa(groupCount + 1 + n)
+			}
+			backrefs[i] = captured[target]
+			sample.WriteString(captured[target])
+			tokenMap[i] = Position{startPos, sample.Len()}
+			atom, afterQuantifier = tokenMap[i], false
+			continue
+		}
+
+		// Repeat the preceding element as many times as the quantifier
+		// allows, leaning toward the sample bias
+		if isQuantifierToken(token) {
+			if least, most, ok := quantifierBounds(token); ok && !afterQuantifier {
+				n := sampleRepetitions(least, most, opts.Bias, random)
+				if most < 0 && extra >= 0 {
+					most = least + extra
+					n = min(n, most)
+				}
+				// Realistic samples fill a repeated class with a word
+				word, realistic := "", false
+				if picker != nil && n > 0 && i > 0 && atom == tokenMap[i-1] && isRealisticClass(tokens[i-1]) {
+					afterDot := strings.HasSuffix(sample.String()[:atom.start], ".")
+					word, realistic = picker.pick(tokens[i-1], formatName, active[i-1], least, most, afterDot)
+				}
+				if realistic {
+					replaceAtom(&sample, atom, word)
+				} else {
+					repeatAtom(&sample, atom, n)
+				}
+			}
+			tokenMap[i] = Position{startPos, sample.Len()}
+			afterQuantifier = true
+			continue
+		}
+		afterQuantifier = false
+
+		// Flag settings such as (?m) don't contribute to the sample
+		if TokenCategory(token) == CategoryFlags {
+			tokenMap[i] = Position{startPos, startPos}
+			atom = tokenMap[i]
+			continue
+		}
+
+		// After a multi-line $ or before a multi-line ^ the sample breaks
+		// the line, with a newline from the first element that can match
+		// one, skipping optional elements that can't
+		if lineEnded || beforeLineStart(tokens, active, i) {
+			if matchesNewline(token, formatName, active[i]) {
+				sample.WriteString("\n")
+				tokenMap[i] = Position{startPos, sample.Len()}
+				atom, lineEnded = tokenMap[i], false
+				continue
+			}
+			if i+1 < len(tokens) {
+				if least, _, ok := quantifierBounds(tokens[i+1]); ok && least == 0 {
+					tokenMap[i] = Position{startPos, startPos}
+					atom = tokenMap[i]
+					continue
+				}
+			}
+		}
+
 		// Handle different token types
 		switch token {
-		case "^", "$", "\\b", "\\B":
+		case "$":
+			// A multi-line $ ends a line the sample goes on after
+			lineEnded = strings.ContainsRune(active[i], 'm')
+		case "^", "\\b", "\\B":
 			// Zero-width assertions don't contribute to the sample
 		case ".":
 			sample.WriteString("x")
@@ -317,14 +795,8 @@ func generateDeterministicSample(tokens []string) (string, []Position) {
 			sample.WriteString("a")
 		case "\\s":
 			sample.WriteString(" ")
-		case "+":
-			// Repeat the preceding character once more (for +)
-			if sample.Len() > 0 {
-				lastChar := sample.String()[sample.Len()-1:]
-				sample.WriteString(lastChar)
-			}
-		case "*", "?", "{", "}":
-			// Other quantifiers don't contribute directly
+		case "{", "}":
+			// Braces that aren't a quantifier don't contribute
 		case "(":
 			// Opening of a group - no contribution
 		case ")":
@@ -366,21 +838,21 @@ func generateDeterministicSample(tokens []string) (string, []Position) {
 					}
 				}
 			}
-		case "[0-9]":
-			sample.WriteString("7")
-		case "[a-z]":
-			sample.WriteString("m")
-		case "[A-Z]":
-			sample.WriteString("M")
-		case "[a-zA-Z]":
-			sample.WriteString("k")
-		case "[a-zA-Z0-9]":
-			sample.WriteString("k")
 		default:
 			// If token contains character ranges or special sequences
 			if strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]") {
-				// For character classes, pick something in the range
-				sample.WriteString("x")
+				// For character classes, pick one of their members
+				member, ok := sampleClassMember(token, formatName, strings.ContainsRune(active[i], 'i'))
+				if !ok {
+					member = 'x'
+				}
+				sample.WriteRune(member)
+			} else if text, ok := format.QuotedText(token); ok {
+				// \Q...\E spans match their text as is
+				sample.WriteString(text)
+			} else if r, ok := format.DecodeEscape(token); ok {
+				// Hex, octal and Unicode escapes stand for a real character
+				sample.WriteRune(r)
 			} else if strings.HasPrefix(token, "\\") {
 				// Handle escape sequences
 				if len(token) > 1 {
@@ -392,8 +864,13 @@ func generateDeterministicSample(tokens []string) (string, []Position) {
 					case 's':
 						sample.WriteString(" ")
 					default:
-						// For other escape sequences, just add a placeholder
-						sample.WriteString("x")
+						// Other escapes stand for a character or a class,
+						// with a placeholder for those Go can't read
+						member, ok := sampleClassMember(token, formatName, strings.ContainsRune(active[i], 'i'))
+						if !ok {
+							member = 'x'
+						}
+						sample.WriteRune(member)
 					}
 				}
 			} else {
@@ -404,9 +881,38 @@ func generateDeterministicSample(tokens []string) (string, []Position) {
 
 		// Record the position of this token in the sample
 		tokenMap[i] = Position{startPos, sample.Len()}
+		if sample.Len() > startPos {
+			lineEnded = false
+		}
+		atom = tokenMap[i]
+		if closedGroup >= 0 {
+			atom = Position{closedGroup, sample.Len()}
+		}
 	}
 
-	return sample.String(), tokenMap
+	return sample.String(), tokenMap, backrefs
+}
+
+// resolveBackreferences rewrites a pattern with each backreference, given
+// by token index, replaced by the literal text it repeats
+func resolveBackreferences(pattern string, tokens []string, backrefs map[int]string) string {
+	var result strings.Builder
+	pos, written := 0, 0
+	for i, token := range tokens {
+		tokenPos := strings.Index(pattern[pos:], token)
+		if tokenPos == -1 {
+			continue
+		}
+		tokenPos += pos
+		pos = tokenPos + len(token)
+		if text, ok := backrefs[i]; ok {
+			result.WriteString(pattern[written:tokenPos])
+			result.WriteString("(?:" + regexp.QuoteMeta(text) + ")")
+			written = pos
+		}
+	}
+	result.WriteString(pattern[written:])
+	return result.String()
 }
 
 // Simplified version to handle alternation patterns better
@@ -472,11 +978,20 @@ func generateFallbackSample(pattern, formatName string) string {
 	return sample
 }
 
-// visualizePattern creates an annotated representation of the regex with numbers
-func visualizePattern(pattern string, tokens []string, colorMap []string) string {
-	// First, generate a colored version of the pattern with token boundaries
-	var coloredPattern strings.Builder
-	var annotationLine strings.Builder
+// patternSegment is a piece of the visualized pattern with its annotation
+type patternSegment struct {
+	text       string
+	annotation string
+	width      int
+}
+
+// visualizePattern creates an annotated representation of the regex with numbers.
+// When width is positive, the pattern and its annotation line are wrapped
+// together in chunks that fit the terminal. A marker is drawn at each of
+// the boundaries, such as where the fragments the pattern was joined from
+// meet, splitting any token that spans them.
+func visualizePattern(pattern string, tokens []string, colorMap []string, width int, boundaries []int) string {
+	var segments []patternSegment
 	var legendLine strings.Builder
 
 	// Keep track of position in the pattern
@@ -491,27 +1006,25 @@ func visualizePattern(pattern string, tokens []string, colorMap []string) string
 
 			// Add any text before this token (should be empty in most cases)
 			if tokenPos > pos {
-				coloredPattern.WriteString(pattern[pos:tokenPos])
-				for j := pos; j < tokenPos; j++ {
-					annotationLine.WriteString(" ")
-				}
+				segments = append(segments, plainSegments(pattern[pos:tokenPos], pos, boundaries)...)
+			}
+			if isBoundary(tokenPos, boundaries) {
+				segments = append(segments, boundarySegment())
 			}
 
-			// Add the colored token
+			// Center the token number below the colored token, with a
+			// marker at each boundary inside it
 			color := colorMap[i%len(colorMap)]
-			coloredPattern.WriteString(color + colorBold + token + colorReset)
-
-			// Add the token number in the annotation line
+			parts := splitAtBoundaries(token, tokenPos, boundaries)
 			marker := strconv.Itoa(i + 1)
-			padding := strings.Repeat(" ", (len(token)-len(marker))/2)
-			annotationLine.WriteString(color + padding + marker)
-
-			// Add spaces to align with the token length
-			if len(token) > len(marker) {
-				extraPadding := len(token) - len(marker) - len(padding)
-				annotationLine.WriteString(strings.Repeat(" ", extraPadding))
-			}
-			annotationLine.WriteString(colorReset)
+			tokenWidth := displayWidth(token) + len(parts) - 1
+			annotation := strings.Repeat(" ", max(tokenWidth-len(marker), 0)/2) + marker
+			annotation += strings.Repeat(" ", max(tokenWidth-len(annotation), 0))
+			segments = append(segments, patternSegment{
+				text:       color + colorBold + strings.Join(parts, colorReset+fragmentMarker()+color+colorBold) + colorReset,
+				annotation: color + annotation + colorReset,
+				width:      max(tokenWidth, len(annotation)),
+			})
 
 			// Add to the legend
 			if i%3 == 0 && i > 0 {
@@ -528,48 +1041,90 @@ func visualizePattern(pattern string, tokens []string, colorMap []string) string
 
 	// Add any remaining part of the pattern
 	if pos < len(pattern) {
-		coloredPattern.WriteString(pattern[pos:])
+		segments = append(segments, plainSegments(pattern[pos:], pos, boundaries)...)
 	}
 
-	// Build the final result
+	// Build the final result, starting a new chunk whenever the next
+	// segment would overflow the terminal
 	var result strings.Builder
 	result.WriteString("Colored pattern:\n")
-	result.WriteString(coloredPattern.String() + "\n")
+
+	var patternLine, annotationLine strings.Builder
+	lineWidth := 0
+	for _, segment := range segments {
+		if width > 0 && lineWidth > 0 && lineWidth+segment.width > width {
+			result.WriteString(patternLine.String() + "\n")
+			result.WriteString(annotationLine.String() + "\n\n")
+			patternLine.Reset()
+			annotationLine.Reset()
+			lineWidth = 0
+		}
+		patternLine.WriteString(segment.text)
+		annotationLine.WriteString(segment.annotation)
+		lineWidth += segment.width
+	}
+	result.WriteString(patternLine.String() + "\n")
 	result.WriteString(annotationLine.String() + "\n\n")
+
 	result.WriteString("Legend:\n")
 	result.WriteString(legendLine.String() + "\n")
 
 	return result.String()
 }
 
-// printSupportedFeatures prints a summary of features supported by the format
-func printSupportedFeatures(regexFormat format.RegexFormat) {
-	features := []struct {
-		name        string
-		code        string
-		description string
-	}{
-		{name: "Lookahead", code: format.FeatureLookahead, description: "(?=pattern) or (?!pattern)"},
-		{name: "Lookbehind", code: format.FeatureLookbehind, description: "(?<=pattern) or (?<!pattern)"},
-		{name: "Named Groups", code: format.FeatureNamedGroup, description: "(?P<n>pattern)"},
-		{name: "Atomic Groups", code: format.FeatureAtomicGroup, description: "(?>pattern)"},
-		{name: "Conditionals", code: format.FeatureConditional, description: "(?(cond)then|else)"},
-		{name: "Possessive Quantifiers", code: format.FeaturePossessive, description: "a++, a*+, a?+"},
-		{name: "Unicode Properties", code: format.FeatureUnicodeClass, description: "\\p{Property}"},
-		{name: "Recursion", code: format.FeatureRecursion, description: "(?R) or (?0)"},
-		{name: "Backreferences", code: format.FeatureBackreference, description: "\\1, \\2, etc."},
-		{name: "Named Backreferences", code: format.FeatureNamedBackref, description: "\\k<n>"},
-	}
-
-	fmt.Printf("%sSupported Features:%s\n", colorBold, colorReset)
+// plainSegment is uncolored pattern text the tokenizer skipped
+func plainSegment(text string) patternSegment {
+	width := displayWidth(text)
+	return patternSegment{text: text, annotation: strings.Repeat(" ", width), width: width}
+}
 
-	for _, feature := range features {
-		supported := colorRed + "✗" + colorReset
-		if regexFormat.HasFeature(feature.code) {
-			supported = colorGreen + "✓" + colorReset
+// plainSegments is uncolored pattern text the tokenizer skipped, found at
+// start in the pattern, with a marker at each boundary in it
+func plainSegments(text string, start int, boundaries []int) []patternSegment {
+	var segments []patternSegment
+	for i, part := range splitAtBoundaries(text, start, boundaries) {
+		if i > 0 || isBoundary(start, boundaries) {
+			segments = append(segments, boundarySegment())
+		}
+		segments = append(segments, plainSegment(part))
+	}
+	return segments
+}
+
+// boundarySegment marks a boundary between tokens
+func boundarySegment() patternSegment {
+	return patternSegment{text: fragmentMarker(), annotation: fragmentMarker(), width: 1}
+}
+
+// features lists the regex features reported for every format
+var features = []struct {
+	name        string
+	code        string
+	description string
+}{
+	{name: "Lookahead", code: format.FeatureLookahead, description: "(?=pattern) or (?!pattern)"},
+	{name: "Lookbehind", code: format.FeatureLookbehind, description: "(?<=pattern) or (?<!pattern)"},
+	{name: "Named Groups", code: format.FeatureNamedGroup, description: "(?P<n>pattern)"},
+	{name: "Atomic Groups", code: format.FeatureAtomicGroup, description: "(?>pattern)"},
+	{name: "Conditionals", code: format.FeatureConditional, description: "(?(cond)then|else)"},
+	{name: "Possessive Quantifiers", code: format.FeaturePossessive, description: "a++, a*+, a?+"},
+	{name: "Unicode Properties", code: format.FeatureUnicodeClass, description: "\\p{Property}"},
+	{name: "Recursion", code: format.FeatureRecursion, description: "(?R) or (?0)"},
+	{name: "Backreferences", code: format.FeatureBackreference, description: "\\1, \\2, etc."},
+	{name: "Named Backreferences", code: format.FeatureNamedBackref, description: "\\k<n>"},
+}
+
+// writeSupportedFeatures writes a summary of the features supported by a format
+func writeSupportedFeatures(w io.Writer, supported []FeatureSupport) {
+	fmt.Fprintf(w, "%sSupported Features:%s\n", colorBold, colorReset)
+
+	for _, feature := range supported {
+		mark := colorUnsupported + Glyph("✗", "[ ]") + colorReset
+		if feature.Supported {
+			mark = colorSupported + Glyph("✓", "[x]") + colorReset
 		}
-		fmt.Printf("  %s %s (%s)\n", supported, feature.name, feature.description)
+		fmt.Fprintf(w, "  %s %s (%s)\n", mark, feature.Name, feature.Syntax)
 	}
 
-	fmt.Println()
+	fmt.Fprintln(w)
 }
@@ -0,0 +1,274 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// benchRounds is how many times each prefix of a pattern is timed, keeping
+// the fastest run to filter out noise
+const benchRounds = 5
+
+// PartCost is the share of a pattern's matching time attributed to one of
+// its parts: an atom or group with its quantifier, made of the tokens from
+// First to Last
+type PartCost struct {
+	Part     string        `json:"part"`
+	First    int           `json:"first"`
+	Last     int           `json:"last"`
+	Duration time.Duration `json:"duration"`
+	Share    float64       `json:"share"`
+}
+
+// BenchResult is how long a pattern takes to find every match in a set of
+// inputs, and how that time splits between its parts. Throughput is in
+// bytes of input per second.
+type BenchResult struct {
+	Pattern    string        `json:"pattern"`
+	Tokens     []string      `json:"tokens"`
+	Inputs     int           `json:"inputs"`
+	Bytes      int           `json:"bytes"`
+	Duration   time.Duration `json:"duration"`
+	Throughput float64       `json:"throughput"`
+	Parts      []PartCost    `json:"parts"`
+}
+
+// Bench times how long a pattern takes to find every match in the inputs,
+// spending about budget on it, and attributes the time to its top-level
+// parts by timing each prefix of the pattern that ends after a part: the
+// time a part adds to the prefix before it is that part's cost. Costs are
+// estimates, as a longer prefix can also be faster, and such parts are
+// given no time.
+func Bench(pattern, formatName, flags string, inputs []string, budget time.Duration) (*BenchResult, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("benchmarking needs at least one input")
+	}
+	exp := AnalyzeWithFlags(pattern, formatName, flags)
+	compacted, tokens, flags := compactVerbose(exp)
+	if _, err := CompilePattern(compacted, formatName, flags); err != nil {
+		return nil, err
+	}
+
+	// Time the empty pattern as a baseline, then the prefix ending after
+	// each part. A prefix that doesn't compile counts as fast as the one
+	// before it.
+	parts := benchParts(tokens)
+	prefixes := []*regexp.Regexp{regexp.MustCompile(``)}
+	for _, part := range parts {
+		r, _ := CompilePattern(strings.Join(tokens[:part.Last+1], ""), formatName, flags)
+		prefixes = append(prefixes, r)
+	}
+
+	// Interleave the rounds so a slow moment hits every prefix alike
+	times := make([]time.Duration, len(prefixes))
+	slice := budget / time.Duration(benchRounds*len(prefixes))
+	for round := 0; round < benchRounds; round++ {
+		for i, r := range prefixes {
+			if r == nil {
+				continue
+			}
+			if elapsed := timeMatching(r, inputs, slice); round == 0 || elapsed < times[i] {
+				times[i] = elapsed
+			}
+		}
+	}
+
+	result := &BenchResult{Pattern: pattern, Tokens: tokens, Inputs: len(inputs), Duration: times[len(times)-1]}
+	for _, input := range inputs {
+		result.Bytes += len(input)
+	}
+	if result.Duration > 0 {
+		result.Throughput = float64(result.Bytes) / result.Duration.Seconds()
+	}
+	var total time.Duration
+	previous := times[0]
+	for i, part := range parts {
+		if prefixes[i+1] != nil {
+			part.Duration = max(0, times[i+1]-previous)
+			previous = times[i+1]
+		}
+		total += part.Duration
+		result.Parts = append(result.Parts, part)
+	}
+	for i := range result.Parts {
+		if total > 0 {
+			result.Parts[i].Share = float64(result.Parts[i].Duration) / float64(total)
+		}
+	}
+	return result, nil
+}
+
+// benchParts splits tokens into the top-level parts of a pattern: an atom
+// or a whole group, along with the quantifiers after it. A top-level | is
+// part of the alternative after it, so prefixes don't end in an empty
+// alternative that matches everywhere.
+func benchParts(tokens []string) []PartCost {
+	var parts []PartCost
+	depth, first := 0, -1
+	for i, token := range tokens {
+		if first < 0 {
+			first = i
+		}
+		switch {
+		case opensGroup(token):
+			depth++
+		case token == ")" && depth > 0:
+			depth--
+		}
+
+		// A part ends once its groups are closed, unless a quantifier or
+		// a | comes next. Empty tokens, such as stripped delimiters, join
+		// the part before them.
+		if depth > 0 || token == "|" || token == "" && i+1 < len(tokens) {
+			continue
+		}
+		if i+1 < len(tokens) && (isQuantifierToken(tokens[i+1]) || tokens[i+1] == "") {
+			continue
+		}
+		var text strings.Builder
+		for _, t := range tokens[first : i+1] {
+			text.WriteString(t)
+		}
+		parts = append(parts, PartCost{Part: text.String(), First: first, Last: i})
+		first = -1
+	}
+	if first >= 0 {
+		parts = append(parts, PartCost{Part: strings.Join(tokens[first:], ""), First: first, Last: len(tokens) - 1})
+	}
+	return parts
+}
+
+// SaveBaseline writes a benchmark to a file as JSON, for CheckRegression to
+// compare later runs against
+func SaveBaseline(path string, result *BenchResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to save baseline: %v", err)
+	}
+	return nil
+}
+
+// LoadBaseline reads a benchmark saved by SaveBaseline
+func LoadBaseline(path string) (*BenchResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %v", err)
+	}
+	var baseline BenchResult
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("%s isn't a saved benchmark: %v", path, err)
+	}
+	if baseline.Throughput <= 0 {
+		return nil, fmt.Errorf("%s has no throughput to compare against", path)
+	}
+	return &baseline, nil
+}
+
+// CheckRegression compares a benchmark with a baseline of the same pattern,
+// returning an error when its throughput dropped by more than threshold, a
+// fraction of the baseline's
+func CheckRegression(baseline, result *BenchResult, threshold float64) error {
+	if baseline.Pattern != result.Pattern {
+		return fmt.Errorf("the baseline is for pattern %q, not %q", baseline.Pattern, result.Pattern)
+	}
+	change := result.Throughput/baseline.Throughput - 1
+	if change < -threshold {
+		return fmt.Errorf("throughput regressed by %.1f%%, from %s to %s, beyond the %.1f%% threshold",
+			-change*100, formatThroughput(baseline.Throughput), formatThroughput(result.Throughput), threshold*100)
+	}
+	return nil
+}
+
+// formatThroughput renders bytes per second with a binary unit
+func formatThroughput(throughput float64) string {
+	units := []string{"B/s", "KiB/s", "MiB/s", "GiB/s"}
+	unit := 0
+	for throughput >= 1024 && unit < len(units)-1 {
+		throughput /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", throughput, units[unit])
+}
+
+// timeMatching returns how long one pass finding every match of r in the
+// inputs takes, averaged over as many passes as fit in about budget
+func timeMatching(r *regexp.Regexp, inputs []string, budget time.Duration) time.Duration {
+	passes := 0
+	start := time.Now()
+	for passes == 0 || time.Since(start) < budget {
+		for _, input := range inputs {
+			r.FindAllStringIndex(input, -1)
+		}
+		passes++
+	}
+	return time.Since(start) / time.Duration(passes)
+}
+
+// heatColors are the backgrounds of tokens taking at least 10%, 25% and
+// 50% of the matching time
+var heatColors = []struct {
+	share float64
+	color string
+}{
+	{0.5, "\033[41m"},
+	{0.25, "\033[43m"},
+	{0.1, "\033[42m"},
+}
+
+// heatColor returns the background for a token with a share of the time,
+// or "" for cheap tokens and when colors are off
+func heatColor(share float64) string {
+	if !colorEnabled {
+		return ""
+	}
+	for _, heat := range heatColors {
+		if share >= heat.share {
+			return heat.color
+		}
+	}
+	return ""
+}
+
+// RenderBench renders a benchmark as the pattern with each part on a
+// background showing its share of the time, followed by a table of the
+// parts' costs with a bar for each
+func RenderBench(result *BenchResult) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "%sTime per pass over %d input(s):%s %v\n", colorBold, result.Inputs, colorReset, result.Duration)
+	fmt.Fprintf(&out, "%sThroughput:%s %s\n\n", colorBold, colorReset, formatThroughput(result.Throughput))
+
+	fmt.Fprintf(&out, "%sHeatmap:%s ", colorBold, colorReset)
+	for _, part := range result.Parts {
+		if color := heatColor(part.Share); color != "" {
+			out.WriteString(color + part.Part + colorReset)
+		} else {
+			out.WriteString(part.Part)
+		}
+	}
+	out.WriteString("\n\n")
+
+	width := len("Part")
+	for _, part := range result.Parts {
+		width = max(width, utf8.RuneCountInString(part.Part))
+	}
+	fmt.Fprintf(&out, "%s%-*s  %-20s  %6s  %s%s\n", colorBold, width, "Part", "", "Share", "Time", colorReset)
+	for _, part := range result.Parts {
+		filled := int(part.Share*20 + 0.5)
+		bar := strings.Repeat(Glyph("█", "#"), filled) + strings.Repeat(Glyph("░", "."), 20-filled)
+		text := part.Part + strings.Repeat(" ", width-utf8.RuneCountInString(part.Part))
+		if color := heatColor(part.Share); color != "" {
+			text = color + part.Part + colorReset + strings.Repeat(" ", width-utf8.RuneCountInString(part.Part))
+		}
+		fmt.Fprintf(&out, "%s  %s  %5.1f%%  %v\n", text, bar, part.Share*100, part.Duration)
+	}
+	return out.String()
+}
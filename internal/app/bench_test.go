@@ -0,0 +1,118 @@
+package app
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBenchParts(t *testing.T) {
+	tests := []struct {
+		tokens []string
+		want   []string
+	}{
+		{[]string{"a", "b"}, []string{"a", "b"}},
+		{[]string{"(", "a", "|", "b", ")", "+", "c"}, []string{"(a|b)+", "c"}},
+		{[]string{"a", "|", "b", "*", "?"}, []string{"a", "|b*?"}},
+		{[]string{"", "a", "b", "", ""}, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, part := range benchParts(tt.tokens) {
+			got = append(got, part.Part)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("benchParts(%q) = %q, want %q", tt.tokens, got, tt.want)
+		}
+	}
+}
+
+func TestBench(t *testing.T) {
+	input := strings.Repeat("abc 123 ", 100)
+	result, err := Bench(`(a|b)+c\s*\d+`, "go", "", []string{input}, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Bench() error = %v", err)
+	}
+	var parts []string
+	total := 0.0
+	for _, part := range result.Parts {
+		parts = append(parts, part.Part)
+		total += part.Share
+	}
+	if want := []string{"(a|b)+", "c", `\s*`, `\d+`}; !reflect.DeepEqual(parts, want) {
+		t.Errorf("Bench() parts = %q, want %q", parts, want)
+	}
+	if total != 0 && math.Abs(total-1) > 1e-9 {
+		t.Errorf("Bench() shares add up to %v, want 1", total)
+	}
+
+	if _, err := Bench(`a`, "go", "", nil, time.Millisecond); err == nil {
+		t.Error("Bench() with no inputs succeeded, want an error")
+	}
+	if _, err := Bench(`(?<=a)b`, "pcre", "", []string{"ab"}, time.Millisecond); err == nil {
+		t.Error("Bench() with a lookbehind succeeded, want an error")
+	}
+}
+
+func TestRenderBench(t *testing.T) {
+	SetColor(false)
+	defer SetColor(true)
+
+	result := &BenchResult{
+		Pattern:    `a+b`,
+		Inputs:     2,
+		Duration:   3 * time.Microsecond,
+		Throughput: 1.5 * 1024 * 1024,
+		Parts: []PartCost{
+			{Part: "a+", Duration: 3 * time.Microsecond, Share: 0.75},
+			{Part: "b", Duration: time.Microsecond, Share: 0.25},
+		},
+	}
+	got := RenderBench(result)
+	for _, want := range []string{
+		"Time per pass over 2 input(s): 3µs",
+		"Throughput: 1.5 MiB/s",
+		"Heatmap: a+b\n",
+		"a+    " + strings.Repeat("█", 15) + strings.Repeat("░", 5) + "   75.0%  3µs",
+		"b     " + strings.Repeat("█", 5) + strings.Repeat("░", 15) + "   25.0%  1µs",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderBench() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestBaselines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	baseline := &BenchResult{Pattern: `a+`, Bytes: 100, Duration: time.Microsecond, Throughput: 1000}
+	if err := SaveBaseline(path, baseline); err != nil {
+		t.Fatalf("SaveBaseline() error = %v", err)
+	}
+	loaded, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("LoadBaseline() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, baseline) {
+		t.Errorf("LoadBaseline() = %+v, want %+v", loaded, baseline)
+	}
+
+	tests := []struct {
+		pattern    string
+		throughput float64
+		wantErr    bool
+	}{
+		{`a+`, 1200, false},
+		{`a+`, 950, false},
+		{`a+`, 850, true},
+		{`b+`, 1000, true},
+	}
+	for _, tt := range tests {
+		err := CheckRegression(loaded, &BenchResult{Pattern: tt.pattern, Throughput: tt.throughput}, 0.1)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckRegression(%q, %v) error = %v, wantErr %v", tt.pattern, tt.throughput, err, tt.wantErr)
+		}
+	}
+}
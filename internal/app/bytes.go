@@ -0,0 +1,42 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// bytesClassNotes describe what the shorthand classes match in a Python
+// bytes pattern, where they only know about ASCII
+var bytesClassNotes = map[string]string{
+	`\w`: ", only [a-zA-Z0-9_] in a bytes pattern",
+	`\W`: ", i.e. anything but [a-zA-Z0-9_] in a bytes pattern",
+	`\d`: ", only [0-9] in a bytes pattern",
+	`\D`: ", i.e. anything but [0-9] in a bytes pattern",
+	`\s`: `, only [ \t\n\r\f\v] in a bytes pattern`,
+	`\S`: `, i.e. anything but [ \t\n\r\f\v] in a bytes pattern`,
+	`\b`: ", with word characters limited to [a-zA-Z0-9_] in a bytes pattern",
+	`\B`: ", with word characters limited to [a-zA-Z0-9_] in a bytes pattern",
+}
+
+// applyBytesMode adjusts the explanations of a Python bytes pattern such as
+// rb"\w+", which matches bytes rather than str: the shorthand classes only
+// know ASCII, and non-ASCII literals and Unicode escapes are errors
+func applyBytesMode(pattern, formatName string, tokens []TokenExplanation) {
+	if formatName != "python" || !format.IsBytesPattern(pattern) {
+		return
+	}
+
+	for i := range tokens {
+		token := tokens[i].Token
+		switch {
+		case bytesClassNotes[token] != "":
+			tokens[i].Explanation += bytesClassNotes[token]
+		case len(token) > 1 && token[0] == '\\' && strings.IndexByte("uUN", token[1]) >= 0:
+			tokens[i].Explanation = fmt.Sprintf("Error: \\%c escapes aren't allowed in a bytes pattern", token[1])
+		case (TokenCategory(token) == CategoryLiteral || TokenCategory(token) == CategoryClass) && !isASCII(token):
+			tokens[i].Explanation = "Error: a bytes pattern can only contain ASCII literal characters"
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package app
+
+import "testing"
+
+func TestAnalyze_BytesPatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		token   int
+		want    string
+	}{
+		{`rb"\w+"`, 1, "Matches any alphanumeric character (including underscore), only [a-zA-Z0-9_] in a bytes pattern"},
+		{`b'\d'`, 1, "Matches any decimal digit (0-9), only [0-9] in a bytes pattern"},
+		{`rb"café"`, 1, "Error: a bytes pattern can only contain ASCII literal characters"},
+		{`rb"\u00e9"`, 1, `Error: \u escapes aren't allowed in a bytes pattern`},
+		{`r"\w"`, 1, "Matches any alphanumeric character (including underscore)"},
+	}
+
+	for _, tt := range tests {
+		exp := Analyze(tt.pattern, "python")
+		if got := exp.Tokens[tt.token].Explanation; got != tt.want {
+			t.Errorf("Analyze(%q) token %q = %q, want %q", tt.pattern, exp.Tokens[tt.token].Token, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// foldingLetters are the letters with case folding quirks, in the order the
+// case folding table lists them
+var foldingLetters = []string{"k", "s", "i", "ß", "ss"}
+
+// unicodeFolding reports whether case-insensitive matching in the pattern's
+// flavor folds Unicode characters, with the modifier letters in effect.
+// JavaScript only does with the u or v flag and PCRE only in UTF mode, and
+// Python bytes patterns and the a flag limit folding to ASCII.
+func unicodeFolding(exp *Explanation, flags string) bool {
+	switch exp.FormatName {
+	case "go":
+		return true
+	case "python":
+		return !format.IsBytesPattern(exp.Pattern) && !strings.ContainsRune(flags, 'a')
+	case "js":
+		if strings.ContainsAny(exp.Flags, "uv") {
+			return true
+		}
+		for _, token := range exp.Tokens {
+			if strings.HasPrefix(token.Token, "/") && TokenCategory(token.Token) == CategoryFlags &&
+				strings.ContainsAny(token.Token, "uv") {
+				return true
+			}
+		}
+	case "pcre":
+		for _, token := range exp.Tokens {
+			modifiers, ok := format.DelimiterModifiers(token.Token)
+			if token.Token == "(*UTF)" || token.Token == "(*UTF8)" || (ok && strings.ContainsRune(modifiers, 'u')) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// foldingQuirk describes how a letter with a case folding quirk matches
+// case-insensitively in a flavor, with Unicode folding on or off, or ""
+// when the flavor's behavior needs no note
+func foldingQuirk(letter, formatName string, unicode bool) string {
+	asciiOnly := map[string]string{
+		"python": "folding is ASCII-only here",
+		"js":     "the u flag is off",
+		"pcre":   "UTF mode is off",
+	}[formatName]
+
+	switch {
+	case formatName == "posix":
+		if letter == "i" {
+			return "folds by the locale: in a Turkish locale I pairs with the dotless ı, not i"
+		}
+		return ""
+	case letter == "k" && unicode:
+		return "also matches the Kelvin sign K (U+212A)"
+	case letter == "k":
+		return fmt.Sprintf("doesn't match the Kelvin sign K (U+212A), as %s", asciiOnly)
+	case letter == "s" && unicode:
+		return "also matches the long s ſ (U+017F)"
+	case letter == "s":
+		return fmt.Sprintf("doesn't match the long s ſ (U+017F), as %s", asciiOnly)
+	case letter == "i" && unicode && formatName == "python":
+		return "also matches the Turkish dotless ı (U+0131) and dotted İ (U+0130)"
+	case letter == "i":
+		return "doesn't match the Turkish dotless ı (U+0131) or dotted İ (U+0130), so spell them out for Turkish text"
+	case letter == "ß" && unicode:
+		return "also matches the capital ẞ (U+1E9E), but never \"ss\" or \"SS\", as only simple case folding is done"
+	case letter == "ß":
+		return fmt.Sprintf("only matches ß, never ẞ (U+1E9E), \"ss\" or \"SS\", as %s", asciiOnly)
+	case letter == "ss":
+		return "never matches ß, as ß only equals \"ss\" under full case folding, which isn't done"
+	}
+	return ""
+}
+
+// foldingLettersIn returns which of the folding letters a literal or class
+// token can match, case-insensitively
+func foldingLettersIn(token, formatName string) map[string]bool {
+	found := make(map[string]bool)
+	switch TokenCategory(token) {
+	case CategoryLiteral:
+		lower := strings.ToLower(token)
+		for _, letter := range foldingLetters {
+			if strings.Contains(lower, letter) || (letter == "ß" && strings.ContainsRune(token, 'ẞ')) {
+				found[letter] = true
+			}
+		}
+	case CategoryClass:
+		if strings.HasPrefix(token, "[^") {
+			break
+		}
+		members := classMembers(token, formatName)
+		for _, letter := range foldingLetters {
+			r := []rune(letter)[0]
+			if len(letter) == 1 && (classContains(members, r) || classContains(members, []rune(strings.ToUpper(letter))[0])) {
+				found[letter] = true
+			}
+		}
+		if classContains(members, 'ß') || classContains(members, 'ẞ') {
+			found["ß"] = true
+		}
+	}
+	return found
+}
+
+// caseFoldingTable calls out the case folding quirks that matter for the
+// letters the pattern matches case-insensitively, such as the Kelvin sign
+// matching k in some flavors. It returns "" when nothing is matched
+// case-insensitively or no such letter appears.
+func caseFoldingTable(exp *Explanation) string {
+	active := activeModifiers(exp.Tokens, exp.Flags)
+	present := make(map[string]string)
+	for i, token := range exp.Tokens {
+		if !strings.ContainsRune(active[i], 'i') {
+			continue
+		}
+		for letter := range foldingLettersIn(token.Token, exp.FormatName) {
+			if _, ok := present[letter]; !ok {
+				present[letter] = active[i]
+			}
+		}
+	}
+
+	var rows []string
+	for _, letter := range foldingLetters {
+		flags, ok := present[letter]
+		if !ok {
+			continue
+		}
+		if quirk := foldingQuirk(letter, exp.FormatName, unicodeFolding(exp, flags)); quirk != "" {
+			rows = append(rows, fmt.Sprintf("  %s%-3s%s %s\n", colorBold, letter, colorReset, quirk))
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%sCase folding:%s\n", colorBold, colorReset) + strings.Join(rows, "")
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFoldingQuirk(t *testing.T) {
+	tests := []struct {
+		letter     string
+		formatName string
+		unicode    bool
+		want       string
+	}{
+		{"k", "go", true, "also matches the Kelvin sign K (U+212A)"},
+		{"k", "js", false, "doesn't match the Kelvin sign K (U+212A), as the u flag is off"},
+		{"s", "pcre", false, "doesn't match the long s ſ (U+017F), as UTF mode is off"},
+		{"i", "python", true, "also matches the Turkish dotless ı (U+0131) and dotted İ (U+0130)"},
+		{"i", "go", true, "doesn't match the Turkish dotless ı (U+0131) or dotted İ (U+0130), so spell them out for Turkish text"},
+		{"ß", "python", false, "only matches ß, never ẞ (U+1E9E), \"ss\" or \"SS\", as folding is ASCII-only here"},
+		{"k", "posix", false, ""},
+	}
+
+	for _, tt := range tests {
+		if got := foldingQuirk(tt.letter, tt.formatName, tt.unicode); got != tt.want {
+			t.Errorf("foldingQuirk(%q, %q, %v) = %q, want %q", tt.letter, tt.formatName, tt.unicode, got, tt.want)
+		}
+	}
+}
+
+func TestUnicodeFolding(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		formatName string
+		want       bool
+	}{
+		{"(?i)k", "go", true},
+		{"/k/i", "js", false},
+		{"/k/iu", "js", true},
+		{"(?i)k", "pcre", false},
+		{"(*UTF)(?i)k", "pcre", true},
+		{"~k~iu", "pcre", true},
+		{"(?i)k", "python", true},
+		{"(?ai)k", "python", false},
+	}
+
+	for _, tt := range tests {
+		exp := Analyze(tt.pattern, tt.formatName)
+		active := activeModifiers(exp.Tokens, exp.Flags)
+		if got := unicodeFolding(exp, active[len(active)-1]); got != tt.want {
+			t.Errorf("unicodeFolding(%q, %q) = %v, want %v", tt.pattern, tt.formatName, got, tt.want)
+		}
+	}
+}
+
+func TestCaseFoldingTable(t *testing.T) {
+	SetColor(false)
+	defer SetColor(true)
+
+	got := caseFoldingTable(Analyze("(?i)kiss[a-z]", "go"))
+	for _, want := range []string{
+		"Case folding:",
+		"k   also matches the Kelvin sign",
+		"s   also matches the long s",
+		"i   doesn't match the Turkish dotless",
+		"ss  never matches ß",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("caseFoldingTable() should contain %q, got:\n%s", want, got)
+		}
+	}
+
+	for _, pattern := range []string{"kiss", "(?i)abc", "(?i)[^k]", "(?i:x)k"} {
+		if got := caseFoldingTable(Analyze(pattern, "go")); got != "" {
+			t.Errorf("caseFoldingTable(%q) = %q, want no table", pattern, got)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// Token categories used to style tokens by what they do rather than by position
+const (
+	CategoryAnchor        = "anchor"
+	CategoryQuantifier    = "quantifier"
+	CategoryGroup         = "group"
+	CategoryClass         = "class"
+	CategoryEscape        = "escape"
+	CategoryBackreference = "backreference"
+	CategoryAlternation   = "alternation"
+	CategoryAny           = "any"
+	CategoryFlags         = "flags"
+	CategoryLiteral       = "literal"
+)
+
+// TokenCategory classifies a token produced by any of the format tokenizers
+func TokenCategory(token string) string {
+	switch {
+	case token == "":
+		return CategoryLiteral
+	case token == "^" || token == "$":
+		return CategoryAnchor
+	case token == "|":
+		return CategoryAlternation
+	case token == ".":
+		return CategoryAny
+	case isQuantifierToken(token):
+		return CategoryQuantifier
+	case strings.HasPrefix(token, "/") && len(token) > 1:
+		// JavaScript flags extracted from /pattern/flags
+		return CategoryFlags
+	case isDelimiterFlags(token):
+		// The closing delimiter and modifiers of a wrapped PCRE pattern
+		return CategoryFlags
+	case strings.HasPrefix(token, "(?P="):
+		return CategoryBackreference
+	case isSubroutineCall(token):
+		return CategoryBackreference
+	case isInlineFlagsToken(token):
+		return CategoryFlags
+	case format.IsPcreOption(token):
+		// PCRE option settings such as (*UCP)
+		return CategoryFlags
+	case strings.HasPrefix(token, "(") || token == ")":
+		return CategoryGroup
+	case strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]"):
+		return CategoryClass
+	case format.PythonStringPrefix(token) == token:
+		// Python string prefix such as r" or rb"
+		return CategoryFlags
+	case strings.HasPrefix(token, `\Q`) && len(token) > 2:
+		// \Q...\E quoted span
+		return CategoryLiteral
+	case strings.HasPrefix(token, "\\") && len(token) > 1:
+		switch token[1] {
+		case 'b', 'B', 'A', 'z', 'Z', 'G':
+			return CategoryAnchor
+		case '1', '2', '3', '4', '5', '6', '7', '8', '9', 'k', 'g':
+			return CategoryBackreference
+		case 'd', 'D', 'w', 'W', 's', 'S', 'p', 'P', 'n', 't', 'r', 'f', 'v', '0', 'x', 'u', 'U', 'N', 'R', 'a', 'Q', 'E':
+			return CategoryEscape
+		}
+		return CategoryLiteral
+	}
+	return CategoryLiteral
+}
+
+// isQuantifierToken reports whether a token is a quantifier, including lazy
+// and possessive variants
+func isQuantifierToken(token string) bool {
+	switch token {
+	case "*", "+", "?", "*?", "+?", "??", "*+", "++", "?+":
+		return true
+	}
+	if strings.HasPrefix(token, "{") && strings.HasSuffix(token, "}") && len(token) > 2 {
+		for _, c := range token[1 : len(token)-1] {
+			if (c < '0' || c > '9') && c != ',' {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// isInlineFlagsToken reports whether a token is an inline flag group such as (?i)
+func isInlineFlagsToken(token string) bool {
+	if !strings.HasPrefix(token, "(?") || !strings.HasSuffix(token, ")") || len(token) < 4 {
+		return false
+	}
+	for _, c := range token[2 : len(token)-1] {
+		if !strings.ContainsRune("aiLmsuxnJU-", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// opensGroup reports whether a token opens a group that a later ) token
+// closes, including the condition of a conditional group such as (?(1)
+func opensGroup(token string) bool {
+	return strings.HasPrefix(token, "(") && (!strings.HasSuffix(token, ")") || format.IsCondition(token))
+}
+
+// isDelimiterFlags reports whether a token is the closing delimiter and
+// modifiers of a wrapped PCRE pattern, such as /x or }i
+func isDelimiterFlags(token string) bool {
+	_, ok := format.DelimiterModifiers(token)
+	return ok
+}
+
+// isSubroutineCall reports whether a token is a recursion or subroutine call
+// group such as (?R), (?1) or (?&name)
+func isSubroutineCall(token string) bool {
+	_, ok := format.SubroutineTarget(token)
+	return ok && strings.HasPrefix(token, "(")
+}
@@ -0,0 +1,56 @@
+package app
+
+import "testing"
+
+func TestTokenCategory(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"^", CategoryAnchor},
+		{"$", CategoryAnchor},
+		{"\\b", CategoryAnchor},
+		{"\\A", CategoryAnchor},
+		{"*", CategoryQuantifier},
+		{"+?", CategoryQuantifier},
+		{"*+", CategoryQuantifier},
+		{"{2,3}", CategoryQuantifier},
+		{"(", CategoryGroup},
+		{"(?:", CategoryGroup},
+		{"(?P<name>", CategoryGroup},
+		{")", CategoryGroup},
+		{"[a-z]", CategoryClass},
+		{"\\d", CategoryEscape},
+		{"\\p", CategoryEscape},
+		{"\\1", CategoryBackreference},
+		{"(?P=name)", CategoryBackreference},
+		{"|", CategoryAlternation},
+		{".", CategoryAny},
+		{"/gi", CategoryFlags},
+		{"}is", CategoryFlags},
+		{"(?im)", CategoryFlags},
+		{"r'", CategoryFlags},
+		{"\\.", CategoryLiteral},
+		{"abc", CategoryLiteral},
+		{`\Qa.b\E`, CategoryLiteral},
+		{`\Q`, CategoryEscape},
+		{"{abc}", CategoryLiteral},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			if got := TokenCategory(tt.token); got != tt.want {
+				t.Errorf("TokenCategory(%q) = %q, want %q", tt.token, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderHTMLSnippet(t *testing.T) {
+	got := RenderHTMLSnippet(Analyze(`^a<b`, "go"))
+	want := `<code class="unregex unregex-go"><span class="re-anchor" title="Matches only at the start of the input">^</span>` +
+		`<span class="re-literal" title="Matches the string &#39;a&lt;b&#39; literally">a&lt;b</span></code>` + "\n"
+	if got != want {
+		t.Errorf("RenderHTMLSnippet() =\n%s\nwant:\n%s", got, want)
+	}
+}
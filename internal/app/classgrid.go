@@ -0,0 +1,108 @@
+package app
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+// Printable ASCII characters per row of a class grid
+const classGridWidth = 32
+
+// classMembers returns the ranges of characters a class token admits, as
+// pairs of inclusive bounds, or nil when the token isn't a character class
+// Go's regexp/syntax package can read
+func classMembers(token, formatName string) []rune {
+	category := TokenCategory(token)
+	if category != CategoryClass && category != CategoryEscape {
+		return nil
+	}
+
+	parsed, err := syntax.Parse(goCompatiblePattern(token, formatName), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	parsed = parsed.Simplify()
+	if parsed.Op != syntax.OpCharClass {
+		return nil
+	}
+	return parsed.Rune
+}
+
+// classContains reports whether the class ranges contain r
+func classContains(ranges []rune, r rune) bool {
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if r >= ranges[i] && r <= ranges[i+1] {
+			return true
+		}
+	}
+	return false
+}
+
+// renderClassGrid draws the printable ASCII range with the members of a
+// character class shown and the others dotted out, followed by how many
+// characters outside printable ASCII the class admits. With only ASCII the
+// others are left blank, as a dot would read as an admitted ., and an
+// admitted space is shown as _.
+func renderClassGrid(index int, token string, ranges []rune, color string) string {
+	var grid strings.Builder
+	members := 0
+	for r := rune(' '); r <= '~'; r++ {
+		if (r-' ')%classGridWidth == 0 {
+			if r != ' ' {
+				grid.WriteString("\n")
+			}
+			grid.WriteString("  ")
+		}
+		if !classContains(ranges, r) {
+			grid.WriteString(Glyph("·", " "))
+			continue
+		}
+		members++
+		char := string(r)
+		if r == ' ' {
+			char = Glyph("␠", "_")
+		}
+		grid.WriteString(color + colorBold + char + colorReset)
+	}
+
+	others := 0
+	for i := 0; i+1 < len(ranges); i += 2 {
+		others += int(ranges[i+1]-ranges[i]) + 1
+	}
+	others -= members
+
+	return fmt.Sprintf("%s%s%d.%s %s%s%s%s admits %d of the 95 printable ASCII characters, and %s\n%s\n",
+		color, colorBold, index, colorReset,
+		color, colorBold, token, colorReset,
+		members, othersDescription(others), grid.String())
+}
+
+// othersDescription describes how many characters outside printable ASCII
+// a class admits
+func othersDescription(others int) string {
+	switch others {
+	case 0:
+		return "none outside them"
+	case 1:
+		return "1 character outside them"
+	default:
+		return fmt.Sprintf("%d characters outside them", others)
+	}
+}
+
+// classGrids renders a membership grid for each character class token
+func classGrids(exp *Explanation, colorMap []string) string {
+	var result strings.Builder
+	for i, token := range exp.Tokens {
+		ranges := classMembers(token.Token, exp.FormatName)
+		if ranges == nil {
+			continue
+		}
+		if result.Len() == 0 {
+			result.WriteString(fmt.Sprintf("%sCharacter classes:%s\n", colorBold, colorReset))
+		}
+		result.WriteString(renderClassGrid(i+1, token.Token, ranges, colorMap[i%len(colorMap)]))
+	}
+	return result.String()
+}
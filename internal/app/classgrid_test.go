@@ -0,0 +1,64 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassMembers(t *testing.T) {
+	tests := []struct {
+		token  string
+		format string
+		in     string
+		out    string
+	}{
+		{`[\w.-]`, "go", "aZ09_.-", " @/"},
+		{`[^a-z]`, "pcre", "A0 ~", "amz"},
+		{`\d`, "js", "0189", "a "},
+		{`[[:upper:]]`, "posix", "AZ", "az"},
+	}
+
+	for _, tt := range tests {
+		ranges := classMembers(tt.token, tt.format)
+		if ranges == nil {
+			t.Errorf("classMembers(%q) = nil, want a class", tt.token)
+			continue
+		}
+		for _, r := range tt.in {
+			if !classContains(ranges, r) {
+				t.Errorf("classMembers(%q) should contain %q", tt.token, r)
+			}
+		}
+		for _, r := range tt.out {
+			if classContains(ranges, r) {
+				t.Errorf("classMembers(%q) shouldn't contain %q", tt.token, r)
+			}
+		}
+	}
+
+	for _, token := range []string{"abc", `\n`, "[a]", "+"} {
+		if ranges := classMembers(token, "go"); ranges != nil {
+			t.Errorf("classMembers(%q) = %v, want nil", token, ranges)
+		}
+	}
+}
+
+func TestRenderClassGrid(t *testing.T) {
+	SetColor(false)
+	defer SetColor(true)
+
+	got := renderClassGrid(1, `[\d ]`, classMembers(`[\d ]`, "go"), "")
+	for _, want := range []string{
+		"1. [\\d ] admits 11 of the 95 printable ASCII characters, and none outside them\n",
+		"  ␠···············0123456789······\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("renderClassGrid() should contain %q, got:\n%s", want, got)
+		}
+	}
+
+	got = renderClassGrid(2, `[^a]`, classMembers(`[^a]`, "go"), "")
+	if !strings.Contains(got, "admits 94 of the 95 printable ASCII characters, and 1114017 characters outside them") {
+		t.Errorf("renderClassGrid() should count the members outside printable ASCII, got:\n%s", got)
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"os"
+	"strings"
+)
+
+// SetColor enables or disables ANSI colors in the text output
+func SetColor(enabled bool) {
+	colorEnabled = enabled
+	applyColors()
+}
+
+// UseColor decides whether the text output should be colored for a
+// -color mode of always, never or auto
+func UseColor(mode string) bool {
+	return useColor(mode, os.Getenv, StdoutIsTerminal)
+}
+
+// useColor implements UseColor. In auto mode colors are used only when
+// stdout is a terminal, NO_COLOR isn't set (https://no-color.org) and the
+// terminal isn't dumb.
+func useColor(mode string, getenv func(string) string, isTerminal func() bool) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+
+	if getenv("NO_COLOR") != "" || getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal()
+}
+
+// Color depths supported by the text output
+const (
+	ColorDepthBasic     = 8
+	ColorDepth256       = 256
+	ColorDepthTrueColor = 1 << 24
+)
+
+// DetectColorDepth guesses how many colors the terminal can display from
+// COLORTERM and TERM, falling back to the basic eight ANSI colors
+func DetectColorDepth() int {
+	return detectColorDepth(os.Getenv)
+}
+
+// detectColorDepth implements DetectColorDepth
+func detectColorDepth(getenv func(string) string) int {
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorDepthTrueColor
+	}
+
+	// Windows Terminal supports truecolor but doesn't set COLORTERM
+	if getenv("WT_SESSION") != "" {
+		return ColorDepthTrueColor
+	}
+
+	term := getenv("TERM")
+	if strings.Contains(term, "256color") || term == "xterm-kitty" || term == "alacritty" {
+		return ColorDepth256
+	}
+	return ColorDepthBasic
+}
+
+// SetColorDepth sets the number of colors the terminal can display, which
+// selects between a theme's basic and extended palettes
+func SetColorDepth(depth int) {
+	colorDepth = depth
+	applyColors()
+}
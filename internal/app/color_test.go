@@ -0,0 +1,66 @@
+package app
+
+import "testing"
+
+func TestUseColor(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		env      map[string]string
+		terminal bool
+		want     bool
+	}{
+		{"always on a pipe", "always", nil, false, true},
+		{"always with NO_COLOR", "always", map[string]string{"NO_COLOR": "1"}, true, true},
+		{"never on a terminal", "never", nil, true, false},
+		{"auto on a terminal", "auto", nil, true, true},
+		{"auto on a pipe", "auto", nil, false, false},
+		{"auto with NO_COLOR", "auto", map[string]string{"NO_COLOR": "1"}, true, false},
+		{"auto with empty NO_COLOR", "auto", map[string]string{"NO_COLOR": ""}, true, true},
+		{"auto on a dumb terminal", "auto", map[string]string{"TERM": "dumb"}, true, false},
+	}
+
+	for _, tt := range tests {
+		getenv := func(key string) string { return tt.env[key] }
+		isTerminal := func() bool { return tt.terminal }
+		if got := useColor(tt.mode, getenv, isTerminal); got != tt.want {
+			t.Errorf("%s: useColor() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSetColor(t *testing.T) {
+	defer SetColor(true)
+
+	SetColor(false)
+	if colorReset != "" || tokenColors[0] != "" || colorBold != "" {
+		t.Error("SetColor(false) should clear the ANSI codes")
+	}
+
+	SetColor(true)
+	if colorReset != "\033[0m" || tokenColors[5] != "\033[36m" || colorBold != "\033[1m" {
+		t.Error("SetColor(true) should restore the ANSI codes")
+	}
+}
+
+func TestDetectColorDepth(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want int
+	}{
+		{"plain xterm", map[string]string{"TERM": "xterm"}, ColorDepthBasic},
+		{"256 colors", map[string]string{"TERM": "xterm-256color"}, ColorDepth256},
+		{"truecolor", map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, ColorDepthTrueColor},
+		{"24bit", map[string]string{"COLORTERM": "24bit"}, ColorDepthTrueColor},
+		{"Windows Terminal", map[string]string{"WT_SESSION": "abc"}, ColorDepthTrueColor},
+		{"nothing set", nil, ColorDepthBasic},
+	}
+
+	for _, tt := range tests {
+		getenv := func(key string) string { return tt.env[key] }
+		if got := detectColorDepth(getenv); got != tt.want {
+			t.Errorf("%s: detectColorDepth() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
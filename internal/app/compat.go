@@ -0,0 +1,162 @@
+package app
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// goCompatiblePattern strips flavor-specific wrapping from a pattern and
+// rewrites syntax that only differs in spelling, so flavors whose patterns
+// are RE2-compatible can be handled by Go's regexp/syntax package
+func goCompatiblePattern(pattern, formatName string) string {
+	switch formatName {
+	case "js":
+		// Strip /pattern/flags delimiters, carrying over flags Go understands
+		if len(pattern) > 1 && pattern[0] == '/' {
+			if end := strings.LastIndex(pattern, "/"); end > 0 {
+				var goFlags strings.Builder
+				for _, f := range pattern[end+1:] {
+					if f == 'i' || f == 'm' || f == 's' {
+						goFlags.WriteRune(f)
+					}
+				}
+				pattern = pattern[1:end]
+				if goFlags.Len() > 0 {
+					pattern = "(?" + goFlags.String() + ")" + pattern
+				}
+			}
+		}
+	case "pcre":
+		// Strip delimiters such as qr/.../ or #...#, carrying over the
+		// modifiers Go understands
+		if body, modifiers, ok := format.PcreDelimited(pattern); ok {
+			var goFlags strings.Builder
+			for _, f := range modifiers[1:] {
+				if f == 'i' || f == 'm' || f == 's' || f == 'U' {
+					goFlags.WriteRune(f)
+				}
+			}
+			pattern = body
+			if goFlags.Len() > 0 {
+				pattern = "(?" + goFlags.String() + ")" + pattern
+			}
+		}
+	case "grok":
+		// Expand the library references, leaving the regex they make up to
+		// be read as PCRE
+		if expanded, err := format.ExpandGrok(pattern); err == nil {
+			pattern = expanded
+		}
+		formatName = "pcre"
+	case "python":
+		// Strip the string prefix, such as r" or rb", and its quotes
+		if prefix := format.PythonStringPrefix(pattern); prefix != "" {
+			pattern = strings.TrimSuffix(pattern[len(prefix):], prefix[len(prefix)-1:])
+		}
+	}
+
+	// (?<name>...) is spelled (?P<name>...) in Go versions before 1.22
+	var result strings.Builder
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '\\' && i+1 < len(pattern) {
+			if class, ok := shorthandClass(pattern[i+1], formatName, inClass); ok {
+				result.WriteString(class)
+			} else {
+				result.WriteString(pattern[i : i+2])
+			}
+			i++
+			continue
+		}
+		switch {
+		case !inClass && pattern[i] == '[':
+			// JavaScript's [^] matches any character and [] nothing
+			if formatName == "js" && strings.HasPrefix(pattern[i:], "[^]") {
+				result.WriteString(`[\x00-\x{10FFFF}]`)
+				i += 2
+				continue
+			}
+			if formatName == "js" && strings.HasPrefix(pattern[i:], "[]") {
+				result.WriteString(`[^\x00-\x{10FFFF}]`)
+				i++
+				continue
+			}
+			inClass = true
+			result.WriteByte('[')
+			// A ] right after the [ or [^ is a member rather than the end
+			if strings.HasPrefix(pattern[i+1:], "^") {
+				result.WriteByte('^')
+				i++
+			}
+			if strings.HasPrefix(pattern[i+1:], "]") {
+				result.WriteByte(']')
+				i++
+			}
+			continue
+		case inClass && strings.HasPrefix(pattern[i:], "[:"):
+			// POSIX classes such as [:alpha:] are copied whole
+			if end := strings.Index(pattern[i+2:], ":]"); end >= 0 {
+				result.WriteString(pattern[i : i+end+4])
+				i += end + 3
+				continue
+			}
+		case inClass && pattern[i] == ']':
+			inClass = false
+		}
+		if strings.HasPrefix(pattern[i:], "(?<") && i+3 < len(pattern) && pattern[i+3] != '=' && pattern[i+3] != '!' {
+			result.WriteString("(?P<")
+			i += 2
+			continue
+		}
+		result.WriteByte(pattern[i])
+	}
+	return result.String()
+}
+
+// parseSyntax parses a pattern of any flavor with Go's regexp/syntax package,
+// reporting an ErrUnsupportedFeature or ErrSyntax when the flavor's syntax
+// isn't RE2-compatible
+func parseSyntax(pattern, formatName string) (*syntax.Regexp, error) {
+	converted := goCompatiblePattern(pattern, formatName)
+	parsed, err := syntax.Parse(converted, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("pattern can't be converted to an automaton: %w", goSyntaxError(converted, err))
+	}
+	return parsed, nil
+}
+
+// pcreShorthandClasses spells out PCRE's horizontal and vertical whitespace
+// classes, which Go doesn't have, as the members of a bracket expression
+var pcreShorthandClasses = map[byte]string{
+	'h': `\t \x{A0}\x{1680}\x{180E}\x{2000}-\x{200A}\x{202F}\x{205F}\x{3000}`,
+	'v': `\n\x0B\f\r\x{85}\x{2028}\x{2029}`,
+}
+
+// shorthandClass returns the Go spelling of a flavor's shorthand class
+// escape that Go lacks or reads differently, such as PCRE's \h, \V and \R,
+// and reports false for other escapes. A negated shorthand has no spelling
+// inside a bracket expression, so it's left as is there.
+func shorthandClass(letter byte, formatName string, inClass bool) (string, bool) {
+	if formatName != "pcre" {
+		return "", false
+	}
+	switch {
+	case letter == 'N' && !inClass:
+		return `[^\n]`, true
+	case letter == 'R' && !inClass:
+		return `(?:\r\n|[` + pcreShorthandClasses['v'] + `])`, true
+	}
+	if members, ok := pcreShorthandClasses[letter]; ok {
+		if inClass {
+			return members, true
+		}
+		return "[" + members + "]", true
+	}
+	if members, ok := pcreShorthandClasses[letter+'a'-'A']; ok && !inClass && letter >= 'A' && letter <= 'Z' {
+		return "[^" + members + "]", true
+	}
+	return "", false
+}
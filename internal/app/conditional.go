@@ -0,0 +1,67 @@
+package app
+
+import "github.com/weslien/unregex/pkg/format"
+
+// conditional tracks a conditional group while its tokens are explained
+type conditional struct {
+	depth  int  // group depth of the conditional's own branches
+	define bool // whether it's a (?(DEFINE)...) group, which has no branches
+	branch int  // 0 in the then-branch, 1 in the else-branch
+}
+
+// branchNotes name the branch of a conditional a token is in
+var branchNotes = []string{" (in the then-branch)", " (in the else-branch)"}
+
+// applyConditionals explains the | and ) of each conditional group in
+// terms of its then- and else-branches, and notes which branch each token
+// inside it belongs to
+func applyConditionals(tokens []TokenExplanation) {
+	var open []*conditional
+	depth := 0
+
+	for i := range tokens {
+		token := tokens[i].Token
+		var current *conditional
+		if len(open) > 0 {
+			current = open[len(open)-1]
+		}
+		atBranchLevel := current != nil && depth == current.depth
+
+		switch {
+		case token == "|" && atBranchLevel && !current.define:
+			if current.branch == 0 {
+				tokens[i].Explanation = "Separates the then-branch from the else-branch, which is used when the condition fails"
+			} else {
+				tokens[i].Explanation = "Error: a conditional group can't have more than two branches"
+			}
+			current.branch++
+			continue
+		case token == ")" && atBranchLevel:
+			switch {
+			case current.define:
+				tokens[i].Explanation = "End of the DEFINE group"
+			case current.branch == 0:
+				tokens[i].Explanation = "End of the conditional group - with no else-branch, it matches the empty string when the condition fails"
+			default:
+				tokens[i].Explanation = "End of the conditional group"
+			}
+			open = open[:len(open)-1]
+			depth--
+			continue
+		}
+
+		if current != nil && !current.define && current.branch < len(branchNotes) {
+			tokens[i].Explanation += branchNotes[current.branch]
+		}
+
+		switch {
+		case format.IsCondition(token):
+			depth++
+			open = append(open, &conditional{depth: depth, define: token == "(?(DEFINE)"})
+		case opensGroup(token):
+			depth++
+		case token == ")":
+			depth--
+		}
+	}
+}
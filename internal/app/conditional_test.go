@@ -0,0 +1,27 @@
+package app
+
+import "testing"
+
+func TestAnalyze_Conditionals(t *testing.T) {
+	tests := []struct {
+		pattern string
+		token   int
+		want    string
+	}{
+		{"(?(1)a|(b))", 1, "Matches the character 'a' literally (in the then-branch)"},
+		{"(?(1)a|(b))", 2, "Separates the then-branch from the else-branch, which is used when the condition fails"},
+		{"(?(1)a|(b))", 5, "End of a capturing group (in the else-branch)"},
+		{"(?(1)a|(b))", 6, "End of the conditional group"},
+		{"(?(1)a)", 2, "End of the conditional group - with no else-branch, it matches the empty string when the condition fails"},
+		{"(?(1)a|b|c)", 4, "Error: a conditional group can't have more than two branches"},
+		{"(?(DEFINE)(?<d>x))", 1, "Start of a named capturing group called 'd'"},
+		{"(a|b)", 2, "Acts as an OR operator - matches the expression before or after the |"},
+	}
+
+	for _, tt := range tests {
+		exp := Analyze(tt.pattern, "pcre")
+		if got := exp.Tokens[tt.token].Explanation; got != tt.want {
+			t.Errorf("Analyze(%q) token %q = %q, want %q", tt.pattern, exp.Tokens[tt.token].Token, got, tt.want)
+		}
+	}
+}
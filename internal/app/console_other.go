@@ -0,0 +1,9 @@
+//go:build !windows
+
+package app
+
+// EnableVirtualTerminal reports whether the terminal can render ANSI escape
+// codes. Terminals outside Windows always can.
+func EnableVirtualTerminal() bool {
+	return true
+}
@@ -0,0 +1,33 @@
+//go:build windows
+
+package app
+
+import (
+	"os"
+	"syscall"
+)
+
+// enableVirtualTerminalProcessing makes the console interpret ANSI escape codes
+const enableVirtualTerminalProcessing = 0x0004
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// EnableVirtualTerminal turns on ANSI escape code processing for the
+// console stdout is attached to. It reports false when the console can't
+// render ANSI codes, as with cmd.exe before Windows 10, so the caller can
+// fall back to plain text. Output that isn't a console is left alone.
+func EnableVirtualTerminal() bool {
+	handle := syscall.Handle(os.Stdout.Fd())
+
+	var mode uint32
+	if err := syscall.GetConsoleMode(handle, &mode); err != nil {
+		// Not a console, so it's up to whatever reads the output
+		return true
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+
+	ok, _, _ := procSetConsoleMode.Call(uintptr(handle), uintptr(mode|enableVirtualTerminalProcessing))
+	return ok != 0
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// Conversion is a pattern written in another flavor. Converted is the
+// pattern without delimiters or quotes, and Flags the flags it has to be
+// compiled with outside it, as JavaScript and POSIX have no inline
+// modifiers for them.
+type Conversion struct {
+	Pattern   string `json:"pattern"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Converted string `json:"converted"`
+	Flags     string `json:"flags,omitempty"`
+
+	// Proven is set when comparing the automata of both patterns showed
+	// they match the same strings, and Unproven says why they couldn't be
+	// compared otherwise
+	Proven   bool   `json:"proven"`
+	Unproven string `json:"unproven,omitempty"`
+}
+
+// ConvertPattern writes a pattern in another flavor, by parsing it and
+// writing the syntax tree back with the target flavor's spelling of each
+// construct. Class escapes such as \d are kept where the target has them
+// and written as the characters they match otherwise, so they're read the
+// way unregex reads them in every flavor. Both patterns are then compared
+// as automata, as Simplify does. Patterns that need features Go's regexp
+// package lacks, such as lookarounds, can't be converted.
+func ConvertPattern(pattern, from, to, flags string) (*Conversion, error) {
+	for _, name := range []string{from, to} {
+		switch name {
+		case "go", "pcre", "python", "js", "posix":
+		default:
+			return nil, fmt.Errorf("converting doesn't support the %s format", name)
+		}
+	}
+	exp := AnalyzeWithFlags(pattern, from, flags)
+	body, _, _, ambient, err := unwrapPattern(exp)
+	if err != nil {
+		return nil, err
+	}
+
+	var unwritable string
+	source := &regexPrinter{formatName: from, placeholders: map[rune]string{}}
+	source.expand = func(escape string) bool {
+		keep, ok := keepsEscape(escape, to)
+		if !ok && unwritable == "" {
+			unwritable = escape
+		}
+		return !keep
+	}
+	re, err := source.parse(body, ambient)
+	if err != nil {
+		return nil, fmt.Errorf("pattern can't be converted: %w", err)
+	}
+	if unwritable != "" {
+		return nil, fmt.Errorf("pattern can't be converted: %s has no Unicode property classes such as %s", format.GetFormat(to).Name(), unwritable)
+	}
+
+	p := &regexPrinter{formatName: to, placeholders: source.placeholders, slash: to == "js"}
+	result := &Conversion{Pattern: pattern, From: from, To: to}
+	switch to {
+	case "js":
+		result.Flags = p.throughout(re)
+		if from == "js" {
+			// Flags that don't change what's matched, such as g, are kept
+			for _, flag := range ambient {
+				if !strings.ContainsRune("ims"+result.Flags, flag) {
+					result.Flags += string(flag)
+				}
+			}
+		}
+		for _, spelling := range p.placeholders {
+			if strings.HasPrefix(spelling, `\p`) || strings.HasPrefix(spelling, `\P`) {
+				if !strings.ContainsAny(result.Flags, "uv") {
+					result.Flags += "u"
+				}
+				break
+			}
+		}
+	case "posix":
+		for _, flag := range p.throughout(re) {
+			if flag == 'i' || flag == 'm' {
+				result.Flags += string(flag)
+			}
+		}
+	}
+	p.setFlags(result.Flags)
+	result.Converted = p.print(re)
+	if p.err != nil {
+		return nil, fmt.Errorf("pattern can't be converted: %w", p.err)
+	}
+
+	equal, witness, err := equivalentConversion(result, flags)
+	switch {
+	case err != nil:
+		result.Unproven = err.Error()
+	case !equal:
+		return nil, fmt.Errorf("converting %s to %s would change what it matches, such as %q; please report this", pattern, result.Converted, witness)
+	default:
+		result.Proven = true
+	}
+	return result, nil
+}
+
+// equivalentConversion compares the automata of a pattern and its
+// conversion, reporting whether they match the same strings and a string
+// only one of them matches if not
+func equivalentConversion(c *Conversion, flags string) (bool, string, error) {
+	progA, err := automaton(c.Pattern, c.From, flags)
+	if err != nil {
+		return false, "", err
+	}
+	progB, err := automaton(c.Converted, c.To, c.Flags)
+	if err != nil {
+		return false, "", err
+	}
+	equal, witness, _, err := equivalentAutomata(progA, progB)
+	return equal, witness, err
+}
+
+// keepsEscape reports whether a flavor can write a class escape, such as
+// \d, \pL or [:alpha:], as it is, and false for ok when the characters it
+// matches are too many to write out instead
+func keepsEscape(escape, to string) (keep bool, ok bool) {
+	switch {
+	case strings.HasPrefix(escape, "[:"):
+		return to == "go" || to == "pcre" || to == "posix", true
+	case strings.HasPrefix(escape, `\p`) || strings.HasPrefix(escape, `\P`):
+		keep = to == "go" || to == "pcre" || to == "js"
+		return keep, keep
+	case strings.ContainsAny(escape[1:], "hHvVN"):
+		return to == "pcre", true
+	default:
+		return to != "posix", true
+	}
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertPattern(t *testing.T) {
+	tests := []struct {
+		pattern, from, to, flags string
+		want, wantFlags          string
+		proven                   bool
+	}{
+		{`(?P<year>\d{4})-(\d\d)`, "go", "js", "", `(?<year>\d{4})-(\d\d)`, "", true},
+		{`(?i)hello\s+world`, "go", "js", "", `hello\s+world`, "i", true},
+		{`(?i)hello\s+world`, "pcre", "python", "", `(?i)hello\s+world`, "", true},
+		{`hello\s`, "go", "posix", "i", "hello[\t\n\f\r ]", "i", true},
+		{`/a\/b[^/]+/gi`, "js", "go", "", `(?i)a/b[^/]+`, "", true},
+		{`/a\/b/gi`, "js", "js", "", `a\/b`, "ig", true},
+		{`\p{L}+`, "go", "js", "", `\p{L}+`, "u", true},
+		{`[[:alpha:]]+[[:digit:]]`, "posix", "js", "", `[A-Za-z]+[0-9]`, "", true},
+		{`[[:digit:]]x`, "js", "go", "", `[:\[dgit]\]x`, "", true},
+		{`a{,3}`, "python", "js", "", `a{0,3}`, "", true},
+		{`[[:alpha:]]+`, "go", "pcre", "", `[[:alpha:]]+`, "", true},
+		{`\N`, "pcre", "go", "", `.`, "", true},
+		{`r"(?P<w>\w+)"`, "python", "pcre", "", `(?<w>\w+)`, "", true},
+		{`\Qa.b`, "go", "js", "", `a\.b`, "", true},
+		{`^a$`, "go", "js", "m", `^a$`, "m", false},
+	}
+	for _, tt := range tests {
+		got, err := ConvertPattern(tt.pattern, tt.from, tt.to, tt.flags)
+		if err != nil {
+			t.Errorf("ConvertPattern(%q, %s, %s) error = %v", tt.pattern, tt.from, tt.to, err)
+			continue
+		}
+		if got.Converted != tt.want || got.Flags != tt.wantFlags || got.Proven != tt.proven {
+			t.Errorf("ConvertPattern(%q, %s, %s) = %q with flags %q (proven %v), want %q with %q (proven %v)",
+				tt.pattern, tt.from, tt.to, got.Converted, got.Flags, got.Proven, tt.want, tt.wantFlags, tt.proven)
+		}
+	}
+}
+
+func TestConvertPatternErrors(t *testing.T) {
+	tests := []struct {
+		pattern, from, to, want string
+	}{
+		{`(?<=a)b`, "pcre", "go", "lookbehind"},
+		{`\p{L}`, "go", "python", `no Unicode property classes such as \p{L}`},
+		{`(?P<n>a)`, "go", "posix", "named group n"},
+		{`a+?`, "go", "posix", "lazy quantifier"},
+		{`\d+`, "posix", "js", `no \d escape`},
+		{`(?i)a(?-i)b`, "go", "js", "inline modifier"},
+		{`a`, "go", "grok", "doesn't support the grok format"},
+	}
+	for _, tt := range tests {
+		if got, err := ConvertPattern(tt.pattern, tt.from, tt.to, ""); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ConvertPattern(%q, %s, %s) = %v, %v, want an error about %s", tt.pattern, tt.from, tt.to, got, err, tt.want)
+		}
+	}
+}
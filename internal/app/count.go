@@ -0,0 +1,234 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// CountByLength counts the distinct strings of each length from 0 to
+// maxLength that a pattern matches as a whole, to gauge how permissive it
+// is. The counts come from a DFA built from the pattern's automaton, as
+// compiled by Go's regexp/syntax package, over the Unicode characters that
+// can appear in UTF-8 text, so flags set outside the pattern are applied as
+// Go understands them. Multi-line anchors and word boundaries depend on the
+// text around them and aren't supported.
+func CountByLength(pattern, formatName, flags string, maxLength int) ([]*big.Int, error) {
+	r, err := CompilePattern(pattern, formatName, flags)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := syntax.Parse(r.String(), syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, fmt.Errorf("pattern can't be converted to an automaton: %v", err)
+	}
+	for _, inst := range prog.Inst {
+		if inst.Op == syntax.InstEmptyWidth && syntax.EmptyOp(inst.Arg)&^(syntax.EmptyBeginText|syntax.EmptyEndText) != 0 {
+			return nil, errors.New("can't count the strings of patterns with multi-line anchors or word boundaries")
+		}
+	}
+
+	classes := runeClasses(prog)
+	counts := make([]*big.Int, maxLength+1)
+
+	// The number of strings of the current length reaching each DFA state,
+	// keyed by its set of instructions
+	states := map[string][]uint32{}
+	current := map[string]*big.Int{}
+	start := nfaClosure(prog, []uint32{uint32(prog.Start)}, true)
+	key := stateKey(start)
+	states[key] = start
+	current[key] = big.NewInt(1)
+
+	for length := 0; length <= maxLength; length++ {
+		counts[length] = new(big.Int)
+		next := map[string]*big.Int{}
+		for key, count := range current {
+			set := states[key]
+			if nfaAccepts(prog, set) {
+				counts[length].Add(counts[length], count)
+			}
+			if length == maxLength {
+				continue
+			}
+			for _, class := range classes {
+				step := nfaStep(prog, set, class.lo)
+				if len(step) == 0 {
+					continue
+				}
+				stepKey := stateKey(step)
+				states[stepKey] = step
+				if next[stepKey] == nil {
+					next[stepKey] = new(big.Int)
+				}
+				next[stepKey].Add(next[stepKey], new(big.Int).Mul(count, big.NewInt(class.size)))
+			}
+		}
+		current = next
+	}
+	return counts, nil
+}
+
+// runeClass is a range of characters every instruction of an automaton
+// treats the same way, represented by its first character
+type runeClass struct {
+	lo   rune
+	size int64
+}
+
+// runeClasses splits the characters that can appear in UTF-8 text into
+// ranges that no instruction of the automata tells apart
+func runeClasses(progs ...*syntax.Prog) []runeClass {
+	bounds := map[rune]bool{0: true, '\n': true, '\n' + 1: true, 0xD800: true, 0xE000: true, unicode.MaxRune + 1: true}
+	for _, prog := range progs {
+		for _, inst := range prog.Inst {
+			if inst.Op != syntax.InstRune && inst.Op != syntax.InstRune1 {
+				continue
+			}
+			runes := inst.Rune
+			if len(runes) == 1 {
+				runes = []rune{runes[0], runes[0]}
+			}
+			for i := 0; i+1 < len(runes); i += 2 {
+				bounds[runes[i]], bounds[runes[i+1]+1] = true, true
+				// Case-insensitive characters also match their other cases
+				if syntax.Flags(inst.Arg)&syntax.FoldCase != 0 && runes[i] == runes[i+1] {
+					for _, r := range foldOrbit(runes[i]) {
+						bounds[r], bounds[r+1] = true, true
+					}
+				}
+			}
+		}
+	}
+
+	points := make([]rune, 0, len(bounds))
+	for r := range bounds {
+		points = append(points, r)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i] < points[j] })
+
+	var classes []runeClass
+	for i := 0; i+1 < len(points); i++ {
+		// Surrogates can't be encoded in UTF-8
+		if points[i] == 0xD800 {
+			continue
+		}
+		classes = append(classes, runeClass{lo: points[i], size: int64(points[i+1] - points[i])})
+	}
+	return classes
+}
+
+// nfaClosure follows the instructions that don't consume a character from
+// each of pcs, returning the ones that do, the match instruction and end of
+// text assertions, sorted. atStart says whether beginning of text
+// assertions hold.
+func nfaClosure(prog *syntax.Prog, pcs []uint32, atStart bool) []uint32 {
+	seen := map[uint32]bool{}
+	var result []uint32
+	var visit func(pc uint32)
+	visit = func(pc uint32) {
+		if seen[pc] {
+			return
+		}
+		seen[pc] = true
+		inst := prog.Inst[pc]
+		switch inst.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			visit(inst.Out)
+			visit(inst.Arg)
+		case syntax.InstCapture, syntax.InstNop:
+			visit(inst.Out)
+		case syntax.InstEmptyWidth:
+			switch syntax.EmptyOp(inst.Arg) {
+			case syntax.EmptyBeginText:
+				if atStart {
+					visit(inst.Out)
+				}
+			default:
+				result = append(result, pc)
+			}
+		case syntax.InstFail:
+		default:
+			result = append(result, pc)
+		}
+	}
+	for _, pc := range pcs {
+		visit(pc)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// nfaStep returns the closure of the instructions reached from set by
+// consuming r
+func nfaStep(prog *syntax.Prog, set []uint32, r rune) []uint32 {
+	var next []uint32
+	for _, pc := range set {
+		inst := prog.Inst[pc]
+		switch inst.Op {
+		case syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+			if inst.MatchRune(r) {
+				next = append(next, inst.Out)
+			}
+		}
+	}
+	return nfaClosure(prog, next, false)
+}
+
+// nfaAccepts reports whether set reaches the match instruction at the end
+// of the text
+func nfaAccepts(prog *syntax.Prog, set []uint32) bool {
+	seen := map[uint32]bool{}
+	var visit func(pc uint32) bool
+	visit = func(pc uint32) bool {
+		if seen[pc] {
+			return false
+		}
+		seen[pc] = true
+		inst := prog.Inst[pc]
+		switch inst.Op {
+		case syntax.InstMatch:
+			return true
+		case syntax.InstAlt, syntax.InstAltMatch:
+			return visit(inst.Out) || visit(inst.Arg)
+		case syntax.InstCapture, syntax.InstNop:
+			return visit(inst.Out)
+		case syntax.InstEmptyWidth:
+			return syntax.EmptyOp(inst.Arg) == syntax.EmptyEndText && visit(inst.Out)
+		}
+		return false
+	}
+	for _, pc := range set {
+		if visit(pc) {
+			return true
+		}
+	}
+	return false
+}
+
+// stateKey identifies a DFA state by its sorted instructions
+func stateKey(set []uint32) string {
+	var key strings.Builder
+	for _, pc := range set {
+		fmt.Fprintf(&key, "%d,", pc)
+	}
+	return key.String()
+}
+
+// RenderLengthCounts renders the counts of CountByLength as a table
+func RenderLengthCounts(counts []*big.Int) string {
+	var result strings.Builder
+	result.WriteString("Length  Matching strings\n")
+	for length, count := range counts {
+		fmt.Fprintf(&result, "%-7d %s\n", length, count)
+	}
+	return result.String()
+}
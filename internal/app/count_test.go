@@ -0,0 +1,44 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCountByLength(t *testing.T) {
+	tests := []struct {
+		pattern, flags string
+		maxLength      int
+		want           string
+	}{
+		{`^[a-z]{2,3}$`, "", 4, "[0 0 676 17576 0]"},
+		{`(cat|dog)s?|ca.`, "", 4, "[0 0 0 1112064 2]"},
+		{`\d*`, "", 2, "[1 10 100]"},
+		{`ok`, "i", 2, "[0 0 6]"},
+		{`a^b|c$`, "", 2, "[0 1 0]"},
+	}
+
+	for _, tt := range tests {
+		counts, err := CountByLength(tt.pattern, "go", tt.flags, tt.maxLength)
+		if got := fmt.Sprint(counts); err != nil || got != tt.want {
+			t.Errorf("CountByLength(%q, %q, %d) = %s, %v, want %s", tt.pattern, tt.flags, tt.maxLength, got, err, tt.want)
+		}
+	}
+
+	for _, pattern := range []string{`\bx`, `(?m)^x`, `(?<=a)b`} {
+		if _, err := CountByLength(pattern, "pcre", "", 2); err == nil {
+			t.Errorf("CountByLength(%q) = nil error, want an error", pattern)
+		}
+	}
+}
+
+func TestRenderLengthCounts(t *testing.T) {
+	counts, err := CountByLength(`[ab]`, "go", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Length  Matching strings\n0       0\n1       2\n"
+	if got := RenderLengthCounts(counts); got != want {
+		t.Errorf("RenderLengthCounts() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RenderDOT renders the pattern's NFA, as compiled by Go's regexp/syntax
+// package, as a Graphviz DOT digraph
+func RenderDOT(exp *Explanation) (string, error) {
+	parsed, err := parseSyntax(exp.Pattern, exp.FormatName)
+	if err != nil {
+		return "", err
+	}
+
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return "", fmt.Errorf("pattern can't be converted to an automaton: %v", err)
+	}
+
+	// Only emit states reachable from the start instruction
+	reachable := make(map[int]bool)
+	var visit func(pc int)
+	visit = func(pc int) {
+		if reachable[pc] {
+			return
+		}
+		reachable[pc] = true
+		inst := prog.Inst[pc]
+		switch inst.Op {
+		case syntax.InstMatch, syntax.InstFail:
+			return
+		case syntax.InstAlt, syntax.InstAltMatch:
+			visit(int(inst.Out))
+			visit(int(inst.Arg))
+		default:
+			visit(int(inst.Out))
+		}
+	}
+	visit(prog.Start)
+
+	var result strings.Builder
+	result.WriteString("digraph unregex {\n")
+	result.WriteString("\trankdir=LR;\n")
+	result.WriteString(fmt.Sprintf("\tlabel=%s;\n", dotQuote(exp.Pattern)))
+	result.WriteString("\tnode [shape=circle, fontname=\"Helvetica\"];\n")
+	result.WriteString("\tedge [fontname=\"Helvetica\"];\n")
+	result.WriteString("\tstart [shape=point];\n")
+	result.WriteString(fmt.Sprintf("\tstart -> s%d;\n", prog.Start))
+
+	for pc, inst := range prog.Inst {
+		if !reachable[pc] {
+			continue
+		}
+
+		switch inst.Op {
+		case syntax.InstMatch:
+			result.WriteString(fmt.Sprintf("\ts%d [shape=doublecircle, label=\"%d\"];\n", pc, pc))
+			continue
+		case syntax.InstFail:
+			result.WriteString(fmt.Sprintf("\ts%d [shape=octagon, label=\"fail\"];\n", pc))
+			continue
+		}
+
+		result.WriteString(fmt.Sprintf("\ts%d [label=\"%d\"];\n", pc, pc))
+
+		switch inst.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			result.WriteString(fmt.Sprintf("\ts%d -> s%d [label=\"ε\", style=dashed];\n", pc, inst.Out))
+			result.WriteString(fmt.Sprintf("\ts%d -> s%d [label=\"ε\", style=dashed];\n", pc, inst.Arg))
+		case syntax.InstCapture:
+			action := "open"
+			if inst.Arg%2 == 1 {
+				action = "close"
+			}
+			label := fmt.Sprintf("%s group %d", action, inst.Arg/2)
+			result.WriteString(fmt.Sprintf("\ts%d -> s%d [label=%s, style=dashed];\n", pc, inst.Out, dotQuote(label)))
+		case syntax.InstEmptyWidth:
+			label := describeEmptyWidth(syntax.EmptyOp(inst.Arg))
+			result.WriteString(fmt.Sprintf("\ts%d -> s%d [label=%s, style=dashed];\n", pc, inst.Out, dotQuote(label)))
+		case syntax.InstNop:
+			result.WriteString(fmt.Sprintf("\ts%d -> s%d [label=\"ε\", style=dashed];\n", pc, inst.Out))
+		case syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+			result.WriteString(fmt.Sprintf("\ts%d -> s%d [label=%s];\n", pc, inst.Out, dotQuote(describeRuneInst(inst))))
+		}
+	}
+
+	result.WriteString("}\n")
+	return result.String(), nil
+}
+
+// describeRuneInst describes the characters consumed by a rune instruction
+func describeRuneInst(inst syntax.Inst) string {
+	switch inst.Op {
+	case syntax.InstRuneAny:
+		return "any"
+	case syntax.InstRuneAnyNotNL:
+		return "any except \\n"
+	}
+
+	if syntax.Flags(inst.Arg)&syntax.FoldCase != 0 {
+		// Case-folded literals keep a single representative of the fold orbit
+		runes := make([]rune, len(inst.Rune))
+		for i, r := range inst.Rune {
+			runes[i] = unicode.ToLower(r)
+		}
+		return describeRuneRanges(runes) + " (ignore case)"
+	}
+	return describeRuneRanges(inst.Rune)
+}
+
+// describeRuneRanges renders the rune ranges of a compiled instruction in
+// character class notation
+func describeRuneRanges(runes []rune) string {
+	if len(runes) == 1 {
+		return printableRune(runes[0])
+	}
+
+	var result strings.Builder
+	result.WriteString("[")
+	for i := 0; i+1 < len(runes); i += 2 {
+		lo, hi := runes[i], runes[i+1]
+		result.WriteString(printableRune(lo))
+		if hi != lo {
+			result.WriteString("-")
+			result.WriteString(printableRune(hi))
+		}
+	}
+	result.WriteString("]")
+	return result.String()
+}
+
+// printableRune renders a rune so that control and invisible characters stay legible
+func printableRune(r rune) string {
+	if unicode.IsPrint(r) && r != ' ' {
+		return string(r)
+	}
+	quoted := strconv.QuoteRune(r)
+	return quoted[1 : len(quoted)-1]
+}
+
+// describeEmptyWidth names the zero-width assertions of an empty-width instruction
+func describeEmptyWidth(op syntax.EmptyOp) string {
+	var names []string
+	if op&syntax.EmptyBeginLine != 0 {
+		names = append(names, "line start")
+	}
+	if op&syntax.EmptyEndLine != 0 {
+		names = append(names, "line end")
+	}
+	if op&syntax.EmptyBeginText != 0 {
+		names = append(names, "text start")
+	}
+	if op&syntax.EmptyEndText != 0 {
+		names = append(names, "text end")
+	}
+	if op&syntax.EmptyWordBoundary != 0 {
+		names = append(names, "word boundary")
+	}
+	if op&syntax.EmptyNoWordBoundary != 0 {
+		names = append(names, "non-word boundary")
+	}
+	return strings.Join(names, ", ")
+}
+
+// dotQuote quotes a string for use as a DOT attribute value
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
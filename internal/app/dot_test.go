@@ -0,0 +1,53 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderDOT(t *testing.T) {
+	got, err := RenderDOT(Analyze(`^a[0-9]\b`, "go"))
+	if err != nil {
+		t.Fatalf("RenderDOT() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"digraph unregex {",
+		`label="^a[0-9]\\b";`,
+		`[label="text start", style=dashed];`,
+		`[label="a"];`,
+		`[label="[0-9]"];`,
+		`[label="word boundary", style=dashed];`,
+		"[shape=doublecircle",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderDOT() should contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderDOT_Unsupported(t *testing.T) {
+	if _, err := RenderDOT(Analyze(`(?<=a)b`, "pcre")); err == nil {
+		t.Error("RenderDOT() should fail for lookbehind, which regexp/syntax can't compile")
+	}
+}
+
+func TestGoCompatiblePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		format  string
+		want    string
+	}{
+		{`/ab+c/gi`, "js", `(?i)ab+c`},
+		{`/(?<year>\d{4})/`, "js", `(?P<year>\d{4})`},
+		{`r"\d+"`, "python", `\d+`},
+		{`(?<=a)b`, "pcre", `(?<=a)b`},
+		{`\(?<x`, "go", `\(?<x`},
+	}
+
+	for _, tt := range tests {
+		if got := goCompatiblePattern(tt.pattern, tt.format); got != tt.want {
+			t.Errorf("goCompatiblePattern(%q, %q) = %q, want %q", tt.pattern, tt.format, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"time"
+)
+
+// Engines are the matching engines BenchEngines can run: go, Go's regexp
+// package, which like RE2 runs automata and never backtracks, and backtrack,
+// unregex's own model of how backtracking engines such as PCRE, regexp2 and
+// the ones in Java, .NET, Python and JavaScript match. No backtracking
+// engine is built into unregex, so backtrack counts the work such an engine
+// does rather than standing in for its speed.
+var Engines = []string{"go", "backtrack"}
+
+// EngineResult is how long one engine takes to find every match of a
+// pattern in a set of inputs. Steps counts what the backtrack engine did in
+// one pass, each step being a part of the pattern it tried at a position.
+type EngineResult struct {
+	Engine     string        `json:"engine"`
+	Duration   time.Duration `json:"duration"`
+	Throughput float64       `json:"throughput"`
+	Matches    int           `json:"matches"`
+	Steps      int           `json:"steps,omitempty"`
+
+	// GaveUp is set when the backtrack engine took more than
+	// maxBacktrackSteps steps trying to match at one position
+	GaveUp bool `json:"gaveUp,omitempty"`
+}
+
+// BenchEngines times how long each engine takes to find every match of a
+// pattern in the inputs, spending about budget on it in all. The backtrack
+// engine is a straightforward model, so how its steps grow with the inputs
+// says something about PCRE or regexp2, but its time doesn't.
+func BenchEngines(pattern, formatName, flags string, inputs []string, engines []string, budget time.Duration) ([]EngineResult, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("benchmarking needs at least one input")
+	}
+	if len(engines) == 0 {
+		return nil, errors.New("benchmarking engines needs at least one engine")
+	}
+	for _, engine := range engines {
+		switch engine {
+		case "go", "backtrack":
+		case "regexp2", "pcre":
+			return nil, fmt.Errorf("the %s engine isn't built into unregex; backtrack counts the steps a backtracking engine like it takes, but can't time it", engine)
+		default:
+			return nil, fmt.Errorf("unknown engine '%s' (available: %s)", engine, strings.Join(Engines, ", "))
+		}
+	}
+
+	exp := AnalyzeWithFlags(pattern, formatName, flags)
+	passes := make([]func() (int, int, bool), len(engines))
+	for i, engine := range engines {
+		var err error
+		if passes[i], err = enginePass(engine, exp, inputs); err != nil {
+			return nil, fmt.Errorf("the %s engine can't run the pattern: %w", engine, err)
+		}
+	}
+
+	bytes := 0
+	for _, input := range inputs {
+		bytes += len(input)
+	}
+	results := make([]EngineResult, len(engines))
+	for i, engine := range engines {
+		results[i] = EngineResult{Engine: engine}
+		results[i].Matches, results[i].Steps, results[i].GaveUp = passes[i]()
+	}
+
+	// Interleave the rounds so a slow moment hits every engine alike
+	slice := budget / time.Duration(benchRounds*len(engines))
+	for round := 0; round < benchRounds; round++ {
+		for i, pass := range passes {
+			elapsed := timePasses(func() { pass() }, slice)
+			if round == 0 || elapsed < results[i].Duration {
+				results[i].Duration = elapsed
+			}
+		}
+	}
+	for i := range results {
+		if results[i].Duration > 0 {
+			results[i].Throughput = float64(bytes) / results[i].Duration.Seconds()
+		}
+	}
+	return results, nil
+}
+
+// enginePass returns a pass of an engine over the inputs, which finds every
+// match in them and returns how many there were, how many steps it took
+// and whether it gave up
+func enginePass(engine string, exp *Explanation, inputs []string) (func() (int, int, bool), error) {
+	compacted, _, flags := compactVerbose(exp)
+	r, err := CompilePattern(compacted, exp.FormatName, flags)
+	if engine == "go" {
+		if err != nil {
+			return nil, err
+		}
+		return func() (int, int, bool) {
+			matches := 0
+			for _, input := range inputs {
+				matches += len(r.FindAllStringIndex(input, -1))
+			}
+			return matches, 0, false
+		}, nil
+	}
+
+	parsed, _, written, err := parseBacktracking(exp)
+	if err != nil {
+		return nil, err
+	}
+	runes := make([][]rune, len(inputs))
+	for i, input := range inputs {
+		runes[i] = []rune(input)
+	}
+	return func() (int, int, bool) {
+		matches, steps := 0, 0
+		for _, input := range runes {
+			b := &backtracker{input: input, written: written}
+			matches += b.findAll(parsed)
+			steps += b.steps
+			if b.gaveUp {
+				return matches, steps, true
+			}
+		}
+		return matches, steps, false
+	}, nil
+}
+
+// findAll counts the matches of re in the input the way Go's FindAll finds
+// them: leftmost first, each search starting where the last match ended,
+// and an empty match right after a match skipped
+func (b *backtracker) findAll(re *syntax.Regexp) int {
+	matches, previous := 0, -1
+	for start := 0; start <= len(b.input); {
+		end := -1
+		b.limit = b.steps + maxBacktrackSteps
+		b.match(re, start, func(e int) bool {
+			end = e
+			return true
+		})
+		switch {
+		case b.gaveUp:
+			return matches
+		case end < 0 || end == start && start == previous:
+			start++
+			continue
+		}
+		matches++
+		previous = end
+		start = max(end, start+1)
+	}
+	return matches
+}
+
+// timePasses returns how long one call of pass takes, averaged over as many
+// calls as fit in about budget
+func timePasses(pass func(), budget time.Duration) time.Duration {
+	passes := 0
+	start := time.Now()
+	for passes == 0 || time.Since(start) < budget {
+		pass()
+		passes++
+	}
+	return time.Since(start) / time.Duration(passes)
+}
+
+// RenderEngineBench renders the engines' results as a table. It doesn't
+// rank the engines by speed, as the backtrack engine's time is only that of
+// unregex's model.
+func RenderEngineBench(results []EngineResult) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s%-10s  %14s  %14s  %8s  %s%s\n", colorBold, "Engine", "Time per pass", "Throughput", "Matches", "Steps", colorReset)
+	modeled := false
+	for _, result := range results {
+		steps := ""
+		if result.Engine == "backtrack" {
+			steps = fmt.Sprint(result.Steps)
+			if result.GaveUp {
+				steps += " (gave up)"
+			}
+		}
+		fmt.Fprintf(&out, "%-10s  %14v  %14s  %8d  %s\n", result.Engine, result.Duration, formatThroughput(result.Throughput), result.Matches, steps)
+		modeled = modeled || result.Engine == "backtrack"
+	}
+
+	if modeled {
+		out.WriteString("\nbacktrack is unregex's own model of a backtracking engine, not PCRE or regexp2: its steps\n")
+		out.WriteString("show how much the pattern backtracks on these inputs, but its time isn't those engines' speed.\n")
+	}
+	for _, result := range results {
+		switch {
+		case result.GaveUp:
+			fmt.Fprintf(&out, "The %s engine gave up after %d steps at one position: the pattern backtracks catastrophically,\n", result.Engine, maxBacktrackSteps)
+			out.WriteString("which an engine that never backtracks, such as go or RE2, is immune to.\n")
+		case result.Matches != results[0].Matches:
+			fmt.Fprintf(&out, "The %s engine found %d matches and %s %d, as the engines read the pattern differently.\n",
+				result.Engine, result.Matches, results[0].Engine, results[0].Matches)
+		}
+	}
+	return out.String()
+}
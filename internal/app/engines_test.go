@@ -0,0 +1,98 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBenchEngines(t *testing.T) {
+	inputs := []string{"aaab xxaab", "cccc", ""}
+	results, err := BenchEngines(`a+b`, "go", "", inputs, []string{"go", "backtrack"}, 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("BenchEngines() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("BenchEngines() returned %d results, want 2", len(results))
+	}
+	for _, result := range results {
+		if result.Matches != 2 {
+			t.Errorf("%s found %d matches, want 2", result.Engine, result.Matches)
+		}
+		if result.Duration <= 0 || result.GaveUp {
+			t.Errorf("%s = %+v, want a positive duration", result.Engine, result)
+		}
+	}
+	if results[1].Steps == 0 {
+		t.Errorf("backtrack took no steps")
+	}
+}
+
+func TestBenchEnginesMatchCounts(t *testing.T) {
+	tests := []struct {
+		pattern, format, input string
+	}{
+		{`a*`, "go", "baaac"},
+		{`\b\w+\b`, "go", "one two  three"},
+		{`x??`, "pcre", "xyx"},
+		{`(?i)AB|a`, "go", "ab aB a"},
+		{`\d{2,3}`, "python", "1 12 1234 123456"},
+	}
+	for _, tt := range tests {
+		results, err := BenchEngines(tt.pattern, tt.format, "", []string{tt.input}, []string{"go", "backtrack"}, time.Millisecond)
+		if err != nil {
+			t.Errorf("BenchEngines(%q) error = %v", tt.pattern, err)
+			continue
+		}
+		if results[0].Matches != results[1].Matches {
+			t.Errorf("BenchEngines(%q) matches: go %d, backtrack %d", tt.pattern, results[0].Matches, results[1].Matches)
+		}
+	}
+}
+
+func TestBenchEnginesGivesUp(t *testing.T) {
+	input := strings.Repeat("a", 30) + "b"
+	results, err := BenchEngines(`(a+)+$`, "go", "", []string{input}, []string{"backtrack"}, time.Millisecond)
+	if err != nil {
+		t.Fatalf("BenchEngines() error = %v", err)
+	}
+	if !results[0].GaveUp {
+		t.Errorf("backtrack didn't give up on %q", input)
+	}
+	if got := RenderEngineBench(results); !strings.Contains(got, "backtracks catastrophically") {
+		t.Errorf("RenderEngineBench() = %q, want a note on catastrophic backtracking", got)
+	}
+}
+
+func TestBenchEnginesErrors(t *testing.T) {
+	tests := []struct {
+		engines []string
+		want    string
+	}{
+		{[]string{"go", "regexp2"}, "isn't built into unregex"},
+		{[]string{"onig"}, "unknown engine 'onig' (available: go, backtrack)"},
+		{nil, "at least one engine"},
+	}
+	for _, tt := range tests {
+		_, err := BenchEngines(`a`, "go", "", []string{"a"}, tt.engines, time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("BenchEngines(%q) error = %v, want %q", tt.engines, err, tt.want)
+		}
+	}
+}
+
+func TestRenderEngineBench(t *testing.T) {
+	results := []EngineResult{
+		{Engine: "go", Duration: time.Microsecond, Matches: 3},
+		{Engine: "backtrack", Duration: 4 * time.Microsecond, Matches: 3, Steps: 120},
+	}
+	got := RenderEngineBench(results)
+	for _, want := range []string{"Engine", "backtrack", "120", "not PCRE or regexp2"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderEngineBench() = %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "as fast as") {
+		t.Errorf("RenderEngineBench() = %q, want no speed comparison with the backtrack model", got)
+	}
+}
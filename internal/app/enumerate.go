@@ -0,0 +1,254 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp/syntax"
+	"unicode"
+)
+
+// maxEnumerated is how many strings Enumerate lists without a limit before
+// asking for one
+const maxEnumerated = 10000
+
+// Enumerate lists the strings a pattern matches as a whole, in order, when
+// it has no unbounded quantifiers so there are finitely many, along with
+// how many there are. A limit above 0 lists only the first ones, and for
+// languages too large to list the count is an upper bound that may count a
+// string more than once. Flags set outside the pattern are applied as Go
+// understands them.
+func Enumerate(pattern, formatName, flags string, limit int) ([]string, int, error) {
+	r, err := CompilePattern(pattern, formatName, flags)
+	if err != nil {
+		return nil, 0, err
+	}
+	parsed, err := syntax.Parse(r.String(), syntax.Perl)
+	if err != nil {
+		return nil, 0, err
+	}
+	parsed = parsed.Simplify()
+
+	total, finite := languageSize(parsed)
+	switch {
+	case !finite:
+		return nil, 0, errors.New("pattern matches infinitely many strings, as it has a *, + or {n,} quantifier")
+	case limit <= 0 && total > maxEnumerated:
+		return nil, total, fmt.Errorf("pattern matches %d strings; give a limit to list the first ones", total)
+	case limit <= 0:
+		limit = total
+	}
+
+	// Anchors and word boundaries are checked against each whole string,
+	// and strings reached in different ways are listed once
+	whole, err := wholeMatcher(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	budget := min(total, max(limit, maxEnumerated))
+	var matches []string
+	seen := make(map[string]bool)
+	for _, s := range enumerateStrings(parsed, budget) {
+		if !seen[s] && whole.MatchString(s) {
+			seen[s] = true
+			matches = append(matches, s)
+		}
+	}
+	if budget == total {
+		total = len(matches)
+	}
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, total, nil
+}
+
+// languageSize counts the strings a parsed pattern matches, counting the
+// same string reached in different ways more than once and saturating at
+// math.MaxInt. It reports false when there are infinitely many.
+func languageSize(re *syntax.Regexp) (int, bool) {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return 0, true
+	case syntax.OpLiteral:
+		total := 1
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 {
+				total = saturatingMul(total, len(foldOrbit(r)))
+			}
+		}
+		return total, true
+	case syntax.OpCharClass:
+		total := 0
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			total += int(re.Rune[i+1]-re.Rune[i]) + 1
+		}
+		return total, true
+	case syntax.OpAnyChar:
+		return unicode.MaxRune + 1, true
+	case syntax.OpAnyCharNotNL:
+		return unicode.MaxRune, true
+	case syntax.OpStar, syntax.OpPlus:
+		return 0, false
+	case syntax.OpRepeat:
+		if re.Max < 0 {
+			return 0, false
+		}
+		sub, finite := languageSize(re.Sub[0])
+		total, power := 0, 1
+		for k := 0; k <= re.Max; k++ {
+			if k >= re.Min {
+				total = saturatingAdd(total, power)
+			}
+			power = saturatingMul(power, sub)
+		}
+		return total, finite
+	case syntax.OpQuest:
+		sub, finite := languageSize(re.Sub[0])
+		return saturatingAdd(sub, 1), finite
+	case syntax.OpCapture:
+		return languageSize(re.Sub[0])
+	case syntax.OpConcat, syntax.OpAlternate:
+		total := 1
+		if re.Op == syntax.OpAlternate {
+			total = 0
+		}
+		for _, sub := range re.Sub {
+			size, finite := languageSize(sub)
+			if !finite {
+				return 0, false
+			}
+			if re.Op == syntax.OpAlternate {
+				total = saturatingAdd(total, size)
+			} else {
+				total = saturatingMul(total, size)
+			}
+		}
+		return total, true
+	}
+	// Empty matches, anchors and word boundaries match only ""
+	return 1, true
+}
+
+// enumerateStrings lists up to limit strings a parsed pattern with finitely
+// many matches matches, in order
+func enumerateStrings(re *syntax.Regexp, limit int) []string {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return nil
+	case syntax.OpLiteral:
+		result := []string{""}
+		for _, r := range re.Rune {
+			choices := []rune{r}
+			if re.Flags&syntax.FoldCase != 0 {
+				choices = foldOrbit(r)
+			}
+			result = concatStrings(result, runeStrings(choices), limit)
+		}
+		return result
+	case syntax.OpCharClass:
+		var choices []rune
+		for i := 0; i+1 < len(re.Rune) && len(choices) < limit; i += 2 {
+			for r := re.Rune[i]; r <= re.Rune[i+1] && len(choices) < limit; r++ {
+				choices = append(choices, r)
+			}
+		}
+		return runeStrings(choices)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		var choices []rune
+		for r := rune(0); r <= unicode.MaxRune && len(choices) < limit; r++ {
+			if r != '\n' || re.Op == syntax.OpAnyChar {
+				choices = append(choices, r)
+			}
+		}
+		return runeStrings(choices)
+	case syntax.OpQuest:
+		return unionStrings([]string{""}, enumerateStrings(re.Sub[0], limit), limit)
+	case syntax.OpRepeat:
+		sub := enumerateStrings(re.Sub[0], limit)
+		var result []string
+		power := []string{""}
+		for k := 0; k <= re.Max && len(result) < limit; k++ {
+			if k >= re.Min {
+				result = unionStrings(result, power, limit)
+			}
+			power = concatStrings(power, sub, limit)
+		}
+		return result
+	case syntax.OpCapture:
+		return enumerateStrings(re.Sub[0], limit)
+	case syntax.OpConcat:
+		result := []string{""}
+		for _, sub := range re.Sub {
+			result = concatStrings(result, enumerateStrings(sub, limit), limit)
+		}
+		return result
+	case syntax.OpAlternate:
+		var result []string
+		for _, sub := range re.Sub {
+			result = unionStrings(result, enumerateStrings(sub, limit), limit)
+		}
+		return result
+	}
+	return []string{""}
+}
+
+// foldOrbit returns the characters r matches case-insensitively, r first
+func foldOrbit(r rune) []rune {
+	orbit := []rune{r}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		orbit = append(orbit, f)
+	}
+	return orbit
+}
+
+// runeStrings turns each rune into a string of its own
+func runeStrings(runes []rune) []string {
+	result := make([]string, len(runes))
+	for i, r := range runes {
+		result[i] = string(r)
+	}
+	return result
+}
+
+// concatStrings returns up to limit of the strings made of one of prefixes
+// followed by one of suffixes, in order
+func concatStrings(prefixes, suffixes []string, limit int) []string {
+	var result []string
+	for _, prefix := range prefixes {
+		for _, suffix := range suffixes {
+			if len(result) == limit {
+				return result
+			}
+			result = append(result, prefix+suffix)
+		}
+	}
+	return result
+}
+
+// unionStrings appends the strings of b to a, up to limit in all
+func unionStrings(a, b []string, limit int) []string {
+	for _, s := range b {
+		if len(a) == limit {
+			break
+		}
+		a = append(a, s)
+	}
+	return a
+}
+
+// saturatingAdd adds two counts, stopping at math.MaxInt
+func saturatingAdd(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+// saturatingMul multiplies two counts, stopping at math.MaxInt
+func saturatingMul(a, b int) int {
+	if a != 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * b
+}
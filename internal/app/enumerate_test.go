@@ -0,0 +1,38 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnumerate(t *testing.T) {
+	tests := []struct {
+		pattern, flags string
+		limit          int
+		want           []string
+		total          int
+	}{
+		{`(cat|dog)s?`, "", 0, []string{"cat", "cats", "dog", "dogs"}, 4},
+		{`^(yes|no)$`, "", 0, []string{"yes", "no"}, 2},
+		{`\d{3}`, "", 3, []string{"000", "001", "002"}, 1000},
+		{`[ab]{1,2}`, "", 0, []string{"a", "aa", "ab", "b", "ba", "bb"}, 6},
+		{`a|a`, "", 0, []string{"a"}, 1},
+		{`ab`, "i", 0, []string{"AB", "Ab", "aB", "ab"}, 4},
+		{`a\bb|c`, "", 0, []string{"c"}, 1},
+		{`\Qabc`, "", 0, []string{"abc"}, 1},
+		{`\Qa|b`, "", 0, []string{"a|b"}, 1},
+	}
+
+	for _, tt := range tests {
+		got, total, err := Enumerate(tt.pattern, "go", tt.flags, tt.limit)
+		if err != nil || !reflect.DeepEqual(got, tt.want) || total != tt.total {
+			t.Errorf("Enumerate(%q, %q, %d) = %q, %d, %v, want %q, %d", tt.pattern, tt.flags, tt.limit, got, total, err, tt.want, tt.total)
+		}
+	}
+
+	for _, pattern := range []string{`a+`, `\w{5}`, `(?<=a)b`} {
+		if got, _, err := Enumerate(pattern, "pcre", "", 0); err == nil {
+			t.Errorf("Enumerate(%q) = %q, want an error", pattern, got)
+		}
+	}
+}
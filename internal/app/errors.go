@@ -0,0 +1,162 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// Exit statuses the CLI uses for each failure mode
+const (
+	ExitFailure     = 1
+	ExitUsage       = 2
+	ExitSyntax      = 3
+	ExitUnsupported = 4
+)
+
+// ErrSyntax reports a pattern that doesn't parse. Pos is the byte offset in
+// the pattern where the problem starts.
+type ErrSyntax struct {
+	Pos int
+	Err error
+}
+
+func (e *ErrSyntax) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ErrSyntax) Unwrap() error {
+	return e.Err
+}
+
+// ErrUnsupportedFeature reports a pattern using a feature, one of the
+// format.Feature constants, that a flavor doesn't support
+type ErrUnsupportedFeature struct {
+	Feature string
+	Flavor  string
+}
+
+func (e *ErrUnsupportedFeature) Error() string {
+	return fmt.Sprintf("%s doesn't support %s", format.GetFormat(e.Flavor).Name(), featureName(e.Feature))
+}
+
+// ErrUnknownFormat reports a flavor that isn't built in or registered
+type ErrUnknownFormat struct {
+	Format string
+}
+
+func (e *ErrUnknownFormat) Error() string {
+	return fmt.Sprintf("unsupported regex format '%s'", e.Format)
+}
+
+// ErrUnknownFlag reports a flag letter given outside the pattern that the
+// flavor doesn't accept, or a flag constant that isn't known, in Name
+type ErrUnknownFlag struct {
+	Flag   rune
+	Name   string
+	Flavor string
+}
+
+func (e *ErrUnknownFlag) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("unknown flag %q (give flag letters or constants such as re.IGNORECASE)", e.Name)
+	}
+	letters, _ := format.FlagLetters(e.Flavor)
+	return fmt.Sprintf("%s doesn't have a '%c' flag (supported: %s)", format.GetFormat(e.Flavor).Name(), e.Flag, letters)
+}
+
+// ResolveFlags turns flags given outside the pattern, as letters or as the
+// constants of the language the pattern is used from, such as
+// re.IGNORECASE|re.MULTILINE or RegexOptions.IgnoreCase, into the flag
+// letters of the flavor, reporting an ErrUnknownFlag for flags it doesn't
+// know or accept
+func ResolveFlags(flavor, spec string) (string, error) {
+	letters, unknown := format.ParseFlags(spec)
+	if unknown != "" {
+		return "", &ErrUnknownFlag{Name: unknown, Flavor: flavor}
+	}
+	return letters, checkFlags(flavor, letters)
+}
+
+// checkFlags reports the first flag letter the flavor doesn't accept. Flags
+// of flavors without a known set of letters, such as plugins, aren't checked.
+func checkFlags(flavor, flags string) error {
+	letters, ok := format.FlagLetters(flavor)
+	if !ok {
+		return nil
+	}
+	for _, flag := range flags {
+		if !strings.ContainsRune(letters, flag) {
+			return &ErrUnknownFlag{Flag: flag, Flavor: flavor}
+		}
+	}
+	return nil
+}
+
+// ExitCode returns the exit status for err, telling syntax errors, features
+// the flavor can't handle and unknown formats apart from other failures
+func ExitCode(err error) int {
+	var syntaxErr *ErrSyntax
+	var unsupportedErr *ErrUnsupportedFeature
+	var unknownErr *ErrUnknownFormat
+	var flagErr *ErrUnknownFlag
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &unsupportedErr):
+		return ExitUnsupported
+	case errors.As(err, &syntaxErr):
+		return ExitSyntax
+	case errors.As(err, &unknownErr), errors.As(err, &flagErr):
+		return ExitUsage
+	default:
+		return ExitFailure
+	}
+}
+
+// re2Unsupported are the features Go's regexp/syntax package can't parse
+var re2Unsupported = map[string]bool{
+	format.FeatureLookahead:     true,
+	format.FeatureLookbehind:    true,
+	format.FeatureAtomicGroup:   true,
+	format.FeatureConditional:   true,
+	format.FeaturePossessive:    true,
+	format.FeatureRecursion:     true,
+	format.FeatureBackreference: true,
+	format.FeatureNamedBackref:  true,
+}
+
+// goSyntaxError describes why regexp/syntax rejected a pattern, as an
+// ErrUnsupportedFeature when the pattern uses a feature RE2 lacks and as an
+// ErrSyntax otherwise
+func goSyntaxError(pattern string, err error) error {
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\' && i+1 < len(pattern) && pattern[i+1] >= '1' && pattern[i+1] <= '9':
+			return &ErrUnsupportedFeature{Feature: format.FeatureBackreference, Flavor: "go"}
+		case pattern[i] == '[':
+			if end := format.FindClosingBracket(pattern, i); end > i {
+				i = end
+				continue
+			}
+		case strings.IndexByte("+*?", pattern[i]) >= 0 && i+1 < len(pattern) && pattern[i+1] == '+':
+			return &ErrUnsupportedFeature{Feature: format.FeaturePossessive, Flavor: "go"}
+		}
+
+		for _, construct := range featureSyntax {
+			if strings.HasPrefix(pattern[i:], construct.prefix) {
+				if re2Unsupported[construct.feature] {
+					return &ErrUnsupportedFeature{Feature: construct.feature, Flavor: "go"}
+				}
+				break
+			}
+		}
+
+		if pattern[i] == '\\' {
+			i++
+		}
+	}
+	return &ErrSyntax{Pos: syntaxErrorOffset(pattern, err), Err: err}
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+func TestRenderDOT_Errors(t *testing.T) {
+	_, err := RenderDOT(Analyze(`(?<=a)b`, "pcre"))
+	var unsupported *ErrUnsupportedFeature
+	if !errors.As(err, &unsupported) || unsupported.Feature != format.FeatureLookbehind {
+		t.Errorf("RenderDOT() error = %v, want an ErrUnsupportedFeature for lookbehind", err)
+	}
+
+	_, err = RenderDOT(Analyze(`ab[`, "go"))
+	var syntaxErr *ErrSyntax
+	if !errors.As(err, &syntaxErr) || syntaxErr.Pos != 2 {
+		t.Errorf("RenderDOT() error = %v, want an ErrSyntax at offset 2", err)
+	}
+}
+
+func TestRun_UnknownFormat(t *testing.T) {
+	_, err := Run(RunOptions{Pattern: "abc", Flavor: "cobol"})
+	var unknown *ErrUnknownFormat
+	if !errors.As(err, &unknown) || unknown.Format != "cobol" {
+		t.Errorf("Run() error = %v, want an ErrUnknownFormat for cobol", err)
+	}
+}
+
+func TestRun_InvalidExamples(t *testing.T) {
+	_, err := Run(RunOptions{Pattern: "abc", Examples: ExampleOptions{Bias: "most"}, Writer: io.Discard})
+	if err == nil || !strings.Contains(err.Error(), "unsupported sample bias 'most'") {
+		t.Errorf("Run() error = %v, want one for the sample bias", err)
+	}
+}
+
+func TestRun_UnknownFlag(t *testing.T) {
+	_, err := Run(RunOptions{Pattern: "abc", Flavor: "js", Flags: "gq", Writer: io.Discard})
+	var unknown *ErrUnknownFlag
+	if !errors.As(err, &unknown) || unknown.Flag != 'q' {
+		t.Fatalf("Run() error = %v, want an ErrUnknownFlag for q", err)
+	}
+	if want := "JavaScript RegExp doesn't have a 'q' flag (supported: dgimsuvy)"; err.Error() != want {
+		t.Errorf("Run() error = %q, want %q", err.Error(), want)
+	}
+	if ExitCode(err) != ExitUsage {
+		t.Errorf("ExitCode(%v) = %d, want %d", err, ExitCode(err), ExitUsage)
+	}
+}
+
+func TestRun_FlagConstants(t *testing.T) {
+	result, err := Run(RunOptions{Pattern: "^a", Flavor: "python", Flags: "re.IGNORECASE|re.MULTILINE", Writer: io.Discard})
+	if err != nil || result.Explanation.Flags != "im" {
+		t.Fatalf("Run() = %+v, %v, want the flags im", result, err)
+	}
+
+	_, err = Run(RunOptions{Pattern: "abc", Flavor: "python", Flags: "re.FOO", Writer: io.Discard})
+	var unknown *ErrUnknownFlag
+	if !errors.As(err, &unknown) || unknown.Name != "re.FOO" || ExitCode(err) != ExitUsage {
+		t.Errorf("Run() error = %v, want an ErrUnknownFlag for re.FOO", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errors.New("boom"), ExitFailure},
+		{&ErrUnknownFormat{Format: "cobol"}, ExitUsage},
+		{fmt.Errorf("wrapped: %w", &ErrSyntax{Err: errors.New("missing ]")}), ExitSyntax},
+		{fmt.Errorf("wrapped: %w", &ErrUnsupportedFeature{Feature: format.FeatureRecursion, Flavor: "go"}), ExitUnsupported},
+	}
+
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestErrUnsupportedFeature_Error(t *testing.T) {
+	err := &ErrUnsupportedFeature{Feature: format.FeatureLookbehind, Flavor: "go"}
+	if got, want := err.Error(), "Go Regexp doesn't support lookbehind"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
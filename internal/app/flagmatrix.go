@@ -0,0 +1,190 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// matrixFlags are the flags FlagMatrix turns on and off, in the order
+// combinations list them
+const matrixFlags = "imsu"
+
+// FlagCombination is what a pattern matches in an input with one
+// combination of the toggled flags on. Start and End are rune offsets of
+// the match.
+type FlagCombination struct {
+	Flags   string `json:"flags"`
+	Written bool   `json:"written"`
+	Matched bool   `json:"matched"`
+	Match   string `json:"match,omitempty"`
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+}
+
+// FlagMatrix is what a pattern matches in an input under every
+// combination of the i, m, s and u flags its flavor accepts
+type FlagMatrix struct {
+	Input string `json:"input"`
+
+	// Toggled are the flags turned on and off, and Untried the ones the
+	// flavor accepts that matching can't tell apart
+	Toggled string `json:"toggled"`
+	Untried string `json:"untried,omitempty"`
+
+	Combinations []FlagCombination `json:"combinations"`
+
+	// Changing are the toggled flags that change the match in at least one
+	// combination of the others
+	Changing string `json:"changing"`
+}
+
+// BuildFlagMatrix matches an explained pattern against an input with every
+// combination of the i, m and s flags its flavor accepts on, keeping its
+// other flags as written, to show which flags the match depends on. With
+// no input, the example match is used. Inline modifiers in the pattern
+// override the flags, as they do when it's compiled. The u flag is only
+// reported, as Go's regexp package, which does the matching, always reads
+// the pattern and the input as Unicode code points.
+func BuildFlagMatrix(exp *Explanation, input string) (*FlagMatrix, error) {
+	if input == "" {
+		input = exp.Sample
+	}
+	accepted, ok := format.FlagLetters(exp.FormatName)
+	if !ok {
+		accepted = "ims"
+	}
+	matrix := &FlagMatrix{Input: input}
+	for _, flag := range matrixFlags {
+		switch {
+		case !strings.ContainsRune(accepted, flag):
+		case flag == 'u':
+			matrix.Untried += string(flag)
+		default:
+			matrix.Toggled += string(flag)
+		}
+	}
+
+	pattern, _, flags := compactVerbose(exp)
+	if exp.FormatName == "js" && len(pattern) > 1 && pattern[0] == '/' {
+		// The flags of a /pattern/flags literal are toggled like flags
+		// given outside it
+		if end := strings.LastIndex(pattern, "/"); end > 0 {
+			flags += pattern[end+1:]
+			pattern = pattern[:end+1]
+		}
+	}
+	var kept, written strings.Builder
+	for _, flag := range flags {
+		if strings.ContainsRune(matrix.Toggled, flag) {
+			written.WriteRune(flag)
+		} else {
+			kept.WriteRune(flag)
+		}
+	}
+
+	// Combination n has the toggled flags whose bits are set in n on
+	for n := 0; n < 1<<len(matrix.Toggled); n++ {
+		var on string
+		for bit, flag := range matrix.Toggled {
+			if n&(1<<bit) != 0 {
+				on += string(flag)
+			}
+		}
+		r, err := CompilePattern(pattern, exp.FormatName, kept.String()+on)
+		if err != nil {
+			return nil, err
+		}
+		combination := FlagCombination{Flags: on, Written: sameLetters(on, written.String())}
+		if loc := r.FindStringIndex(input); loc != nil {
+			combination.Matched = true
+			combination.Match = input[loc[0]:loc[1]]
+			combination.Start = utf8.RuneCountInString(input[:loc[0]])
+			combination.End = combination.Start + utf8.RuneCountInString(combination.Match)
+		}
+		matrix.Combinations = append(matrix.Combinations, combination)
+	}
+
+	// A flag changes the match when turning it on changes what some
+	// combination without it matches
+	for bit, flag := range matrix.Toggled {
+		for n, without := range matrix.Combinations {
+			if n&(1<<bit) != 0 {
+				continue
+			}
+			with := matrix.Combinations[n|1<<bit]
+			if with.Matched != without.Matched || with.Start != without.Start || with.End != without.End {
+				matrix.Changing += string(flag)
+				break
+			}
+		}
+	}
+	return matrix, nil
+}
+
+// sameLetters reports whether two sets of flag letters hold the same ones
+func sameLetters(a, b string) bool {
+	for _, flag := range a {
+		if !strings.ContainsRune(b, flag) {
+			return false
+		}
+	}
+	for _, flag := range b {
+		if !strings.ContainsRune(a, flag) {
+			return false
+		}
+	}
+	return true
+}
+
+// RenderFlagMatrix renders a flag matrix as a table of what each
+// combination of flags matches, followed by the flags the match depends on
+func RenderFlagMatrix(exp *Explanation, matrix *FlagMatrix) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "%sFlags, tried on %q:%s\n", colorBold, matrix.Input, colorReset)
+	if matrix.Toggled == "" {
+		fmt.Fprintf(&result, "%s doesn't take any of the i, m and s flags.\n", exp.Format)
+	}
+	for _, combination := range matrix.Combinations {
+		flags := combination.Flags
+		if flags == "" {
+			flags = "(none)"
+		}
+		outcome := "no match"
+		if combination.Matched {
+			outcome = fmt.Sprintf("%q at %d-%d", combination.Match, combination.Start, combination.End)
+		}
+		if combination.Written {
+			outcome += " (as written)"
+		}
+		fmt.Fprintf(&result, "  %-*s  %s\n", max(len(matrix.Toggled), len("(none)")), flags, outcome)
+	}
+
+	if matrix.Toggled != "" {
+		result.WriteString("\n")
+		if matrix.Changing == "" {
+			result.WriteString("No flag changes the match here.\n")
+		} else {
+			result.WriteString("The match depends on:\n")
+			for _, flag := range matrix.Changing {
+				fmt.Fprintf(&result, "  %s\n", format.DescribeFlags(exp.FormatName, string(flag)))
+			}
+		}
+		var unchanged []string
+		for _, flag := range matrix.Toggled {
+			if !strings.ContainsRune(matrix.Changing, flag) {
+				unchanged = append(unchanged, string(flag))
+			}
+		}
+		if len(unchanged) > 0 && matrix.Changing != "" {
+			fmt.Fprintf(&result, "It doesn't depend on %s here.\n", strings.Join(unchanged, ", "))
+		}
+	}
+	if matrix.Untried != "" {
+		fmt.Fprintf(&result, "The u flag isn't tried, as matching uses Go's regexp package, which always reads the\n")
+		fmt.Fprintf(&result, "pattern and the input as Unicode code points.\n")
+	}
+	return result.String()
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildFlagMatrix(t *testing.T) {
+	tests := []struct {
+		pattern, format, flags, input string
+		toggled, untried, changing    string
+		written                       string
+	}{
+		{`^hello.world$`, "go", "", "Hello\nworld", "ims", "", "is", ""},
+		{`^b$`, "go", "m", "a\nb", "ims", "", "m", "m"},
+		{`/abc/gi`, "js", "", "ABC", "ims", "u", "i", "i"},
+		{`/abc/i`, "pcre", "", "ABC", "ims", "", "i", "i"},
+		{`A`, "posix", "", "a", "im", "", "i", ""},
+		{`(?i)abc`, "python", "", "ABC", "ims", "u", "", ""},
+	}
+	for _, tt := range tests {
+		matrix, err := BuildFlagMatrix(AnalyzeWithFlags(tt.pattern, tt.format, tt.flags), tt.input)
+		if err != nil {
+			t.Errorf("BuildFlagMatrix(%q) error = %v", tt.pattern, err)
+			continue
+		}
+		if matrix.Toggled != tt.toggled || matrix.Untried != tt.untried || matrix.Changing != tt.changing {
+			t.Errorf("BuildFlagMatrix(%q) toggled %q, untried %q, changing %q, want %q, %q, %q",
+				tt.pattern, matrix.Toggled, matrix.Untried, matrix.Changing, tt.toggled, tt.untried, tt.changing)
+		}
+		if len(matrix.Combinations) != 1<<len(tt.toggled) {
+			t.Errorf("BuildFlagMatrix(%q) has %d combinations, want %d", tt.pattern, len(matrix.Combinations), 1<<len(tt.toggled))
+		}
+		var written []string
+		for _, combination := range matrix.Combinations {
+			if combination.Written {
+				written = append(written, combination.Flags)
+			}
+		}
+		if len(written) != 1 || written[0] != tt.written {
+			t.Errorf("BuildFlagMatrix(%q) marks %q as written, want %q", tt.pattern, written, tt.written)
+		}
+	}
+}
+
+func TestBuildFlagMatrixOffsets(t *testing.T) {
+	matrix, err := BuildFlagMatrix(AnalyzeWithFlags(`é+`, "go", ""), "ÉÉ éé")
+	if err != nil {
+		t.Fatalf("BuildFlagMatrix() error = %v", err)
+	}
+	got := matrix.Combinations[1]
+	if got.Flags != "i" || got.Match != "ÉÉ" || got.Start != 0 || got.End != 2 {
+		t.Errorf("with i = %+v, want \"ÉÉ\" at 0-2", got)
+	}
+	got = matrix.Combinations[0]
+	if got.Match != "éé" || got.Start != 3 || got.End != 5 {
+		t.Errorf("without i = %+v, want \"éé\" at 3-5", got)
+	}
+}
+
+func TestRenderFlagMatrix(t *testing.T) {
+	exp := AnalyzeWithFlags(`/^a.b$/`, "js", "")
+	matrix, err := BuildFlagMatrix(exp, "A\nB")
+	if err != nil {
+		t.Fatalf("BuildFlagMatrix() error = %v", err)
+	}
+	got := RenderFlagMatrix(exp, matrix)
+	for _, want := range []string{
+		`Flags, tried on "A\nB"`,
+		"(none)  no match (as written)",
+		`is      "A\nB" at 0-3`,
+		"The match depends on:",
+		"It doesn't depend on m here.",
+		"The u flag isn't tried",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderFlagMatrix() = %q, want it to contain %q", got, want)
+		}
+	}
+}
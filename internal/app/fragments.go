@@ -0,0 +1,143 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// fragmentMarker separates the fragments a pattern was joined from in the
+// annotated pattern. The ASCII stand-in is !, as | would read as an
+// alternation.
+func fragmentMarker() string {
+	return Glyph("┆", "!")
+}
+
+// Fragment is one of the pieces of source a pattern was joined from, such as
+// a string constant, with the tokens it contributed
+type Fragment struct {
+	Text string `json:"text"`
+
+	// Start and End are the byte offsets of the fragment in the pattern
+	Start int `json:"start"`
+	End   int `json:"end"`
+
+	// Tokens are the numbers of the tokens starting in the fragment, and
+	// Continued the ones among them that end in a later fragment
+	Tokens    []int `json:"tokens"`
+	Continued []int `json:"continued,omitempty"`
+}
+
+// attachFragments records the fragments joined into the pattern of exp and
+// which tokens each contributed
+func attachFragments(exp *Explanation, fragments []string) {
+	exp.Fragments = nil
+	start := 0
+	for _, text := range fragments {
+		exp.Fragments = append(exp.Fragments, Fragment{Text: text, Start: start, End: start + len(text)})
+		start += len(text)
+	}
+
+	spans := format.TokenSpans(format.GetFormat(exp.FormatName), exp.Pattern, exp.Flags)
+	for i, span := range spans {
+		for j := range exp.Fragments {
+			fragment := &exp.Fragments[j]
+			if span.Start < fragment.Start || span.Start >= fragment.End {
+				continue
+			}
+			fragment.Tokens = append(fragment.Tokens, i+1)
+			if span.End > fragment.End {
+				fragment.Continued = append(fragment.Continued, i+1)
+			}
+			break
+		}
+	}
+}
+
+// fragmentBoundaries returns where in the pattern each fragment after the
+// first starts, leaving out the start and end of the pattern
+func fragmentBoundaries(fragments []Fragment) []int {
+	var boundaries []int
+	for _, fragment := range fragments {
+		if fragment.Start > 0 && fragment.Start < fragments[len(fragments)-1].End &&
+			(len(boundaries) == 0 || boundaries[len(boundaries)-1] != fragment.Start) {
+			boundaries = append(boundaries, fragment.Start)
+		}
+	}
+	return boundaries
+}
+
+// splitAtBoundaries splits text, found at start in the pattern, at the
+// fragment boundaries inside it
+func splitAtBoundaries(text string, start int, boundaries []int) []string {
+	var parts []string
+	for _, boundary := range boundaries {
+		if boundary > start && boundary < start+len(text) {
+			parts = append(parts, text[:boundary-start])
+			text, start = text[boundary-start:], boundary
+		}
+	}
+	return append(parts, text)
+}
+
+// fragmentTable lists the fragments a pattern was joined from, with the
+// numbers of the tokens each contributed
+func fragmentTable(fragments []Fragment) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "%sFragments:%s\n", colorBold, colorReset)
+	for i, fragment := range fragments {
+		fmt.Fprintf(&result, "  %d. %s", i+1, quoteFragment(fragment.Text))
+		switch len(fragment.Tokens) {
+		case 0:
+			result.WriteString(": no tokens start here")
+		case 1:
+			fmt.Fprintf(&result, ": token %s", numberRanges(fragment.Tokens))
+		default:
+			fmt.Fprintf(&result, ": tokens %s", numberRanges(fragment.Tokens))
+		}
+		for _, token := range fragment.Continued {
+			fmt.Fprintf(&result, ", token %d continues into the next fragment", token)
+		}
+		result.WriteString("\n")
+	}
+	return result.String()
+}
+
+// quoteFragment quotes a fragment so any spaces at its ends show, with
+// backquotes unless it has characters that need escaping
+func quoteFragment(text string) string {
+	if strconv.CanBackquote(text) {
+		return "`" + text + "`"
+	}
+	return strconv.Quote(text)
+}
+
+// numberRanges renders sorted numbers with runs collapsed, such as 1-3, 5
+func numberRanges(numbers []int) string {
+	var parts []string
+	for i := 0; i < len(numbers); {
+		j := i
+		for j+1 < len(numbers) && numbers[j+1] == numbers[j]+1 {
+			j++
+		}
+		part := strconv.Itoa(numbers[i])
+		if j > i {
+			part += "-" + strconv.Itoa(numbers[j])
+		}
+		parts = append(parts, part)
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+// isBoundary reports whether pos is one of the boundaries
+func isBoundary(pos int, boundaries []int) bool {
+	for _, boundary := range boundaries {
+		if boundary == pos {
+			return true
+		}
+	}
+	return false
+}
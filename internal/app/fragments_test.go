@@ -0,0 +1,65 @@
+package app
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAttachFragments(t *testing.T) {
+	fragments := []string{`^(?P<user>\w+)`, `@(?P<ho`, `st>[\w.]+)$`}
+	exp := AnalyzeWithFlags(strings.Join(fragments, ""), "python", "")
+	attachFragments(exp, fragments)
+
+	want := []Fragment{
+		{Text: `^(?P<user>\w+)`, Start: 0, End: 14, Tokens: []int{1, 2, 3, 4, 5}},
+		{Text: `@(?P<ho`, Start: 14, End: 21, Tokens: []int{6, 7}, Continued: []int{7}},
+		{Text: `st>[\w.]+)$`, Start: 21, End: 32, Tokens: []int{8, 9, 10, 11}},
+	}
+	if !reflect.DeepEqual(exp.Fragments, want) {
+		t.Errorf("attachFragments() = %+v, want %+v", exp.Fragments, want)
+	}
+	if got := fragmentBoundaries(exp.Fragments); !reflect.DeepEqual(got, []int{14, 21}) {
+		t.Errorf("fragmentBoundaries() = %v, want [14 21]", got)
+	}
+}
+
+func TestRun_Fragments(t *testing.T) {
+	SetColor(false)
+	defer SetColor(true)
+
+	var out bytes.Buffer
+	_, err := Run(RunOptions{Fragments: []string{"ab", "c+", " "}, Visualize: true, Writer: &out})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for _, want := range []string{
+		"Fragments:\n  1. `ab`: token 1, token 1 continues into the next fragment\n  2. `c+`: token 2\n  3. ` `: token 3\n",
+		"ab┆c+┆ \n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Run() output should contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	if _, err := Run(RunOptions{Pattern: "abc", Fragments: []string{"ab"}, Writer: &out}); err == nil {
+		t.Error("Run() with fragments that don't join into the pattern should fail")
+	}
+}
+
+func TestNumberRanges(t *testing.T) {
+	tests := []struct {
+		numbers []int
+		want    string
+	}{
+		{nil, ""},
+		{[]int{4}, "4"},
+		{[]int{1, 2, 3, 5, 7, 8}, "1-3, 5, 7-8"},
+	}
+	for _, tt := range tests {
+		if got := numberRanges(tt.numbers); got != tt.want {
+			t.Errorf("numberRanges(%v) = %q, want %q", tt.numbers, got, tt.want)
+		}
+	}
+}
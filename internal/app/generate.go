@@ -0,0 +1,206 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// generateAttempts is how many random strings GenerateSamples tries per
+// sample asked for before giving up on finding more distinct ones
+const generateAttempts = 20
+
+// SampleOptions configures GenerateSamples: the flags set outside the
+// pattern, as letters or constants such as re.IGNORECASE, how many distinct
+// samples to generate, the seed of the random choices and the most
+// characters a sample may have, with 0 for no limit. Realistic fills
+// repeated classes with real-looking words, and Negative generates strings
+// that almost match but don't.
+type SampleOptions struct {
+	Flags     string
+	Count     int
+	Seed      int64
+	MaxLength int
+	Realistic bool
+	Negative  bool
+}
+
+// sampleSource generates random strings from a parsed pattern and checks
+// them against the whole pattern
+type sampleSource struct {
+	parsed *syntax.Regexp
+	whole  *regexp.Regexp
+	rng    *rand.Rand
+	words  *realisticPicker
+	opts   SampleOptions
+}
+
+// newSampleSource compiles a pattern for GenerateSamples and StreamSamples
+func newSampleSource(pattern, formatName string, opts SampleOptions) (*sampleSource, error) {
+	if _, ok := format.Lookup(formatName); !ok {
+		return nil, &ErrUnknownFormat{Format: formatName}
+	}
+	flags, err := ResolveFlags(formatName, opts.Flags)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Count < 0 || opts.MaxLength < 0 {
+		return nil, fmt.Errorf("invalid sample count %d or length %d", opts.Count, opts.MaxLength)
+	}
+	r, err := CompilePattern(pattern, formatName, flags)
+	if err != nil {
+		return nil, err
+	}
+	// Counted repetitions are kept rather than simplified, so realistic
+	// samples can fill them with a word of the right length
+	parsed, err := syntax.Parse(r.String(), syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+
+	// Anchors and word boundaries are ignored while generating, so each
+	// sample is checked against the whole pattern
+	whole, err := wholeMatcher(r)
+	if err != nil {
+		return nil, err
+	}
+	source := &sampleSource{
+		parsed: parsed,
+		whole:  whole,
+		rng:    rand.New(rand.NewSource(opts.Seed)),
+		opts:   opts,
+	}
+	if opts.Realistic {
+		source.words = newRealisticPicker()
+	}
+	return source, nil
+}
+
+// match generates a string repeating unbounded quantifiers up to size times
+// beyond their minimum, and reports whether the whole pattern matches it.
+// Near misses are edits of matches that may be one character longer, so
+// the length limit applies to them instead.
+func (s *sampleSource) match(size int) (string, bool) {
+	var b strings.Builder
+	writeFromSyntax(&b, s.parsed, s.rng, size, s.words)
+	sample := b.String()
+	return sample, s.whole.MatchString(sample) && (s.opts.Negative || s.fits(sample))
+}
+
+// fits reports whether a string is within the length limit
+func (s *sampleSource) fits(text string) bool {
+	return s.opts.MaxLength == 0 || utf8.RuneCountInString(text) <= s.opts.MaxLength
+}
+
+// nearMisses returns the edits of samples the pattern doesn't match that
+// fit the length limit, shuffled so they aren't all variations of the first
+// sample
+func (s *sampleSource) nearMisses(samples []string, limit int) []string {
+	candidates := generateNearMisses(s.whole, samples, limit)
+	s.rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	var misses []string
+	for _, miss := range candidates {
+		if s.fits(miss) {
+			misses = append(misses, miss)
+		}
+	}
+	return misses
+}
+
+// GenerateSamples generates distinct random strings that a pattern matches
+// as a whole, with the generator behind -proptest, or with Negative single
+// edits of such strings that it doesn't match. Patterns with finitely many
+// matches may get fewer samples than asked for. It reports an error for
+// patterns that aren't RE2-compatible or that no sample could be found for.
+func GenerateSamples(pattern, formatName string, opts SampleOptions) ([]string, error) {
+	source, err := newSampleSource(pattern, formatName, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var samples []string
+	for attempt := 0; attempt < opts.Count*generateAttempts && len(samples) < opts.Count; attempt++ {
+		sample, ok := source.match(3)
+		if !ok || seen[sample] {
+			continue
+		}
+		seen[sample] = true
+		samples = append(samples, sample)
+	}
+	if len(samples) == 0 && opts.Count > 0 {
+		return nil, errors.New("couldn't generate a string the pattern matches")
+	}
+	if !opts.Negative {
+		return samples, nil
+	}
+
+	misses := source.nearMisses(samples, opts.Count*generateAttempts)
+	if len(misses) == 0 && opts.Count > 0 {
+		return nil, errors.New("couldn't generate a string the pattern doesn't match")
+	}
+	return misses[:min(len(misses), opts.Count)], nil
+}
+
+// Limits on how hard StreamSamples looks for new strings: it lets unbounded
+// quantifiers repeat twice as often after streamPatience attempts in a row
+// find nothing new, and stops once they may repeat streamMaxSize times
+const (
+	streamPatience = 1000
+	streamMaxSize  = 4096
+)
+
+// StreamSamples passes distinct random strings that a pattern matches as a
+// whole to emit until it returns an error, as GenerateSamples does but
+// without a count, or near misses with Negative. Unbounded quantifiers are
+// repeated more as the shorter strings run out. It returns nil when no new
+// strings can be found, as for patterns with finitely many matches, and
+// keeps every string it emitted in memory to tell new ones apart.
+func StreamSamples(pattern, formatName string, opts SampleOptions, emit func(string) error) error {
+	source, err := newSampleSource(pattern, formatName, opts)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	emitted := false
+	size, failures := 3, 0
+	for size <= streamMaxSize {
+		sample, ok := source.match(size)
+		if !ok || seen[sample] {
+			if failures++; failures == streamPatience {
+				size, failures = size*2, 0
+			}
+			continue
+		}
+		seen[sample] = true
+		failures = 0
+
+		texts := []string{sample}
+		if opts.Negative {
+			texts = source.nearMisses(texts, streamPatience)
+		}
+		for _, text := range texts {
+			if opts.Negative && seen[text] {
+				continue
+			}
+			seen[text] = true
+			emitted = true
+			if err := emit(text); err != nil {
+				return err
+			}
+		}
+	}
+	if !emitted {
+		return errors.New("couldn't generate a string for the pattern")
+	}
+	return nil
+}
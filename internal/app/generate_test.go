@@ -0,0 +1,88 @@
+package app
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"sort"
+	"testing"
+)
+
+func TestGenerateSamples_Negative(t *testing.T) {
+	samples, err := GenerateSamples(`\d{3}-\d{4}`, "pcre", SampleOptions{Count: 10, Seed: 1, Negative: true})
+	if err != nil {
+		t.Fatalf("GenerateSamples returned error: %v", err)
+	}
+	if len(samples) != 10 {
+		t.Errorf("GenerateSamples returned %d near misses, want 10", len(samples))
+	}
+	whole := regexp.MustCompile(`^\d{3}-\d{4}$`)
+	for _, s := range samples {
+		if whole.MatchString(s) {
+			t.Errorf("GenerateSamples with Negative returned %q, which matches", s)
+		}
+	}
+
+	if samples, err := GenerateSamples(`(?s).*`, "go", SampleOptions{Count: 3, Seed: 1, Negative: true}); err == nil {
+		t.Errorf("GenerateSamples(%q) with Negative = %q, want an error", `(?s).*`, samples)
+	}
+}
+
+func TestGenerateSamples_Realistic(t *testing.T) {
+	samples, err := GenerateSamples(`[a-z]+@[a-z]+\.[a-z]{2,4}`, "go", SampleOptions{Count: 3, Seed: 1, Realistic: true})
+	if err != nil {
+		t.Fatalf("GenerateSamples returned error: %v", err)
+	}
+	want := []string{"hello@world.com", "coffee@river.org", "garden@window.net"}
+	for i, s := range samples {
+		if i < len(want) && s != want[i] {
+			t.Errorf("GenerateSamples realistic sample %d = %q, want %q", i, s, want[i])
+		}
+	}
+	if len(samples) != len(want) {
+		t.Errorf("GenerateSamples returned %q, want %q", samples, want)
+	}
+}
+
+func TestGenerateSamples_OpenQuote(t *testing.T) {
+	// \Q runs to the end of the pattern when it isn't closed
+	samples, err := GenerateSamples(`\Qa|b`, "go", SampleOptions{Count: 3, Seed: 1})
+	if err != nil || !reflect.DeepEqual(samples, []string{"a|b"}) {
+		t.Errorf("GenerateSamples(%q) = %q, %v, want %q", `\Qa|b`, samples, err, []string{"a|b"})
+	}
+}
+
+func TestStreamSamples(t *testing.T) {
+	// Patterns with finitely many matches end the stream once they're all
+	// emitted
+	var got []string
+	err := StreamSamples(`yes|no|maybe`, "go", SampleOptions{Seed: 1}, func(s string) error {
+		got = append(got, s)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamSamples returned error: %v", err)
+	}
+	sort.Strings(got)
+	if want := []string{"maybe", "no", "yes"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("StreamSamples(%q) emitted %q, want %q", `yes|no|maybe`, got, want)
+	}
+
+	// Other patterns stream distinct strings until emit fails
+	stop := errors.New("stop")
+	seen := make(map[string]bool)
+	whole := regexp.MustCompile(`^[a-c]+$`)
+	err = StreamSamples(`[a-c]+`, "go", SampleOptions{Seed: 1}, func(s string) error {
+		if seen[s] || !whole.MatchString(s) {
+			t.Errorf("StreamSamples emitted %q, which is repeated or doesn't match", s)
+		}
+		seen[s] = true
+		if len(seen) == 500 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("StreamSamples returned %v, want the error from emit", err)
+	}
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"os"
+	"strings"
+)
+
+// asciiGlyphs is set when the text output should draw only with ASCII
+var asciiGlyphs = false
+
+// SetASCII makes the text output draw its marks, bars and rules with ASCII
+// stand-ins of the same width, such as [x] and [ ] for ✓ and ✗, for
+// terminals and fonts that lack the Unicode glyphs
+func SetASCII(enabled bool) {
+	asciiGlyphs = enabled
+}
+
+// ASCII reports whether the text output draws only with ASCII
+func ASCII() bool {
+	return asciiGlyphs
+}
+
+// Glyph returns a symbol for the text output, or its ASCII stand-in when
+// the output draws only with ASCII
+func Glyph(symbol, ascii string) string {
+	if asciiGlyphs {
+		return ascii
+	}
+	return symbol
+}
+
+// LocaleIsASCII reports whether the locale the environment sets isn't a
+// UTF-8 one, such as C or en_US.ISO-8859-1, so the terminal may not show
+// Unicode glyphs. No locale at all, as is usual on Windows, counts as UTF-8.
+func LocaleIsASCII() bool {
+	return localeIsASCII(os.Getenv)
+}
+
+// localeIsASCII implements LocaleIsASCII, taking the locale from LC_ALL,
+// LC_CTYPE or LANG, in that order, as the C library does
+func localeIsASCII(getenv func(string) string) bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := strings.ToLower(getenv(name)); locale != "" {
+			return !strings.Contains(locale, "utf-8") && !strings.Contains(locale, "utf8")
+		}
+	}
+	return false
+}
+
+// asciiBoxDrawing replaces the box drawing of the railroad diagrams one
+// character for one, so the diagrams stay aligned
+var asciiBoxDrawing = strings.NewReplacer(
+	"─", "-", "│", "|", "┌", "+", "┐", "+", "└", "+", "┘", "+",
+	"├", "+", "┤", "+", "┬", "+", "○", "o", "◎", "@",
+)
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLocaleIsASCII(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want bool
+	}{
+		{map[string]string{}, false},
+		{map[string]string{"LANG": "en_US.UTF-8"}, false},
+		{map[string]string{"LANG": "de_DE.utf8"}, false},
+		{map[string]string{"LANG": "C"}, true},
+		{map[string]string{"LANG": "en_US.ISO-8859-1"}, true},
+		{map[string]string{"LANG": "C", "LC_CTYPE": "C.UTF-8"}, false},
+		{map[string]string{"LANG": "en_US.UTF-8", "LC_ALL": "POSIX"}, true},
+	}
+	for _, tt := range tests {
+		if got := localeIsASCII(func(name string) string { return tt.env[name] }); got != tt.want {
+			t.Errorf("localeIsASCII(%v) = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestASCIIGlyphs(t *testing.T) {
+	SetColor(false)
+	defer SetColor(true)
+	SetASCII(true)
+	defer SetASCII(false)
+
+	var features strings.Builder
+	writeSupportedFeatures(&features, []FeatureSupport{{Name: "Lookahead", Syntax: "(?=a)", Supported: true}, {Name: "Recursion", Syntax: "(?R)"}})
+	if want := "  [x] Lookahead ((?=a))\n  [ ] Recursion ((?R))\n"; !strings.Contains(features.String(), want) {
+		t.Errorf("writeSupportedFeatures() = %q, want it to contain %q", features.String(), want)
+	}
+
+	diagram, err := RenderRailroad(Analyze("ab|cd", "go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diagram, "o--+-+") || strings.ContainsAny(diagram, "─│┌┐└┘├┤┬○◎") {
+		t.Errorf("RenderRailroad() should only draw with ASCII, got:\n%s", diagram)
+	}
+
+	if grid := renderClassGrid(1, "[ .]", []rune{' ', ' ', '.', '.'}, ""); !strings.Contains(grid, "  _             .") {
+		t.Errorf("renderClassGrid() should leave others blank and show a space as _, got:\n%s", grid)
+	}
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeGrok(t *testing.T) {
+	exp := AnalyzeWithFlags(`%{WORD:verb} %{POSINT:status:int}`, "grok", "")
+	if len(exp.Tokens) != 3 || exp.Tokens[0].Token != "%{WORD:verb}" {
+		t.Fatalf("tokens = %+v, want the references as tokens", exp.Tokens)
+	}
+	if exp.Expanded == nil || exp.Expanded.Pattern != `(?<verb>\b\w+\b) (?<status>\b(?:[1-9][0-9]*)\b)` {
+		t.Fatalf("expanded = %+v", exp.Expanded)
+	}
+	if exp.Expanded.FormatName != "pcre" || exp.Sample == "" || exp.SampleStatus != "Verified match" {
+		t.Errorf("sample = %q (%s), want a verified one from the expanded regex", exp.Sample, exp.SampleStatus)
+	}
+
+	if exp := Analyze(`\d+`, "grok"); exp.Expanded != nil {
+		t.Errorf("a pattern without references has an expansion: %q", exp.Expanded.Pattern)
+	}
+	if exp := Analyze(`%{NOSUCHPATTERN}`, "grok"); exp.Sample != "" || !strings.Contains(exp.SampleStatus, "unknown grok pattern NOSUCHPATTERN") {
+		t.Errorf("sample = %q (%s), want none for an unknown pattern", exp.Sample, exp.SampleStatus)
+	}
+}
+
+func TestCompileGrok(t *testing.T) {
+	r, err := CompilePattern(`%{WORD:method} /%{NOTSPACE:path} %{POSINT:status}`, "grok", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := r.FindStringSubmatch("GET /index.html 200")
+	if m == nil || m[r.SubexpIndex("method")] != "GET" || m[r.SubexpIndex("path")] != "index.html" {
+		t.Errorf("FindStringSubmatch() = %q", m)
+	}
+}
+
+func TestRenderGrok(t *testing.T) {
+	SetColor(false)
+	var out strings.Builder
+	if err := (&TextRenderer{}).Render(&out, Analyze(`%{WORD:w}!`, "grok")); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`1. %{WORD:w}: Grok pattern WORD, defined as \b\w+\b, captured as the field w`,
+		`Expanded regex: (?<w>\b\w+\b)!`,
+		`1. (?<w>: Start of a named capturing group called 'w'`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output is missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// NamedGroup describes a named capturing group of a pattern
+type NamedGroup struct {
+	Name        string
+	Number      int
+	Subpattern  string
+	Explanation string
+	Example     string
+}
+
+// FindNamedGroups locates the named capturing groups of a pattern, explaining
+// their contents and, when the pattern can be compiled, an example capture
+// generated at random from the seed
+func FindNamedGroups(pattern, formatName string, seed int64) []NamedGroup {
+	regexFormat := format.GetFormat(formatName)
+	tokens := regexFormat.TokenizeRegex(pattern)
+
+	var groups []NamedGroup
+	number := 0
+	pos := 0
+	for i, token := range tokens {
+		tokenPos := strings.Index(pattern[pos:], token)
+		if tokenPos == -1 {
+			continue
+		}
+		tokenPos += pos
+		pos = tokenPos + len(token)
+
+		name, capturing := captureGroupName(token)
+		if !capturing {
+			continue
+		}
+		number++
+		if name == "" {
+			continue
+		}
+
+		group := NamedGroup{Name: name, Number: number}
+
+		// The subpattern runs up to the parenthesis that closes this group
+		if end := findGroupEnd(pattern, tokenPos); end > 0 {
+			group.Subpattern = pattern[pos:end]
+		}
+
+		// Explain the tokens inside the group
+		var explanations []string
+		depth := 0
+		for _, inner := range tokens[i+1:] {
+			if inner == ")" {
+				if depth == 0 {
+					break
+				}
+				depth--
+			} else if opensGroup(inner) {
+				depth++
+			}
+			explanations = append(explanations, regexFormat.ExplainToken(inner))
+		}
+		group.Explanation = strings.Join(explanations, "; ")
+
+		groups = append(groups, group)
+	}
+
+	// Fill in example captures from a generated sample when Go can compile the pattern
+	if r, err := regexp.Compile(pattern); err == nil && len(groups) > 0 {
+		if parsed, err := syntax.Parse(pattern, syntax.Perl); err == nil {
+			sample := generateFromSyntax(parsed.Simplify(), rand.New(rand.NewSource(seed)), 3)
+			if match := r.FindStringSubmatch(sample); match != nil {
+				for i := range groups {
+					if idx := r.SubexpIndex(groups[i].Name); idx >= 0 && idx < len(match) {
+						groups[i].Example = match[idx]
+					}
+				}
+			}
+		}
+	}
+
+	return groups
+}
+
+// NamedGroupsMarkdown renders the named groups of a pattern as a Markdown
+// table, with example captures generated from the seed
+func NamedGroupsMarkdown(pattern, formatName string, seed int64) (string, error) {
+	groups := FindNamedGroups(pattern, formatName, seed)
+	if len(groups) == 0 {
+		return "", fmt.Errorf("pattern has no named groups")
+	}
+
+	var result strings.Builder
+	result.WriteString("| Name | Group | Subpattern | Explanation | Example |\n")
+	result.WriteString("|------|-------|------------|-------------|---------|\n")
+	for _, group := range groups {
+		example := "—"
+		if group.Example != "" {
+			example = markdownCode(group.Example)
+		}
+		result.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %s |\n",
+			markdownCode(group.Name), group.Number, markdownCode(group.Subpattern),
+			markdownCell(group.Explanation), example))
+	}
+
+	return result.String(), nil
+}
+
+// captureGroupName reports whether a token opens a capturing group and returns
+// the group's name for named groups
+func captureGroupName(token string) (string, bool) {
+	switch {
+	case token == "(":
+		return "", true
+	case strings.HasPrefix(token, "(?P<") && strings.HasSuffix(token, ">"):
+		return token[4 : len(token)-1], true
+	case strings.HasPrefix(token, "(?<") && strings.HasSuffix(token, ">") &&
+		token != "(?<=" && token != "(?<!":
+		return token[3 : len(token)-1], true
+	}
+	return "", false
+}
+
+// findGroupEnd finds the parenthesis closing the group opened at
+// start, skipping parentheses inside character classes
+func findGroupEnd(pattern string, start int) int {
+	depth := 0
+	for i := start; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			if end := format.FindClosingBracket(pattern, i); end > i {
+				i = end
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// markdownCode renders text as an inline code span that is safe inside a table cell.
+// The fence is one backtick longer than the longest run of them in the text,
+// and text that starts or ends with a backtick, or with a space at both ends,
+// is padded with a space each side, as Markdown strips one such pair.
+func markdownCode(text string) string {
+	if text == "" {
+		return ""
+	}
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", longest+1)
+	text = strings.ReplaceAll(text, "|", "\\|")
+	if text[0] == '`' || text[len(text)-1] == '`' || text[0] == ' ' && text[len(text)-1] == ' ' && strings.TrimSpace(text) != "" {
+		text = " " + text + " "
+	}
+	return fence + text + fence
+}
+
+// markdownCell escapes text for use inside a Markdown table cell
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", " ")
+}
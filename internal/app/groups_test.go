@@ -0,0 +1,73 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindNamedGroups(t *testing.T) {
+	groups := FindNamedGroups(`^(?P<year>\d{4})-(\d\d)-(?P<day>[0-9]{2})$`, "go", 1)
+
+	if len(groups) != 2 {
+		t.Fatalf("FindNamedGroups() returned %d groups, want 2", len(groups))
+	}
+
+	tests := []struct {
+		name       string
+		number     int
+		subpattern string
+		exampleLen int
+	}{
+		{"year", 1, `\d{4}`, 4},
+		{"day", 3, `[0-9]{2}`, 2},
+	}
+
+	for i, tt := range tests {
+		got := groups[i]
+		if got.Name != tt.name || got.Number != tt.number || got.Subpattern != tt.subpattern {
+			t.Errorf("group %d = {%q %d %q}, want {%q %d %q}", i, got.Name, got.Number, got.Subpattern, tt.name, tt.number, tt.subpattern)
+		}
+		if len(got.Example) != tt.exampleLen {
+			t.Errorf("group %q example = %q, want %d characters", tt.name, got.Example, tt.exampleLen)
+		}
+		if !strings.Contains(got.Explanation, "occurrences of the preceding element") {
+			t.Errorf("group %q explanation = %q, should explain the quantifier", tt.name, got.Explanation)
+		}
+	}
+}
+
+func TestNamedGroupsMarkdown(t *testing.T) {
+	table, err := NamedGroupsMarkdown(`(?<user>[a-z|]+)@host`, "pcre", 1)
+	if err != nil {
+		t.Fatalf("NamedGroupsMarkdown() error = %v", err)
+	}
+	if !strings.HasPrefix(table, "| Name | Group | Subpattern | Explanation | Example |\n") {
+		t.Errorf("NamedGroupsMarkdown() should start with the table header, got:\n%s", table)
+	}
+	if !strings.Contains(table, "`[a-z\\|]+`") {
+		t.Errorf("NamedGroupsMarkdown() should escape pipes in cells, got:\n%s", table)
+	}
+
+	if _, err := NamedGroupsMarkdown(`(\d+)`, "go", 1); err == nil {
+		t.Error("NamedGroupsMarkdown() should fail for a pattern without named groups")
+	}
+}
+
+func TestMarkdownCode(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"a|b", "`a\\|b`"},
+		{"a`b", "``a`b``"},
+		{"a``b`", "``` a``b` ```"},
+		{"`<x>", "`` `<x> ``"},
+		{"<x>`", "`` <x>` ``"},
+		{" a ", "`  a  `"},
+		{"  ", "`  `"},
+	}
+	for _, tt := range tests {
+		if got := markdownCode(tt.text); got != tt.want {
+			t.Errorf("markdownCode(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
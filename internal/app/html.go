@@ -0,0 +1,184 @@
+package app
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+)
+
+// htmlPalette mirrors the rotating terminal colors for the HTML output
+var htmlPalette = []string{"#d73a49", "#22863a", "#005cc5", "#b08800", "#6f42c1", "#1b7c83"}
+
+// htmlToken is a token prepared for the HTML templates
+type htmlToken struct {
+	Index       int
+	Text        string
+	Explanation string
+	Color       string
+}
+
+// RenderHTML renders an explanation as a self-contained HTML page with a
+// colorized pattern, token tooltips and a live test-string box
+func RenderHTML(exp *Explanation) (string, error) {
+	data := struct {
+		*Explanation
+		Spans    []htmlToken
+		Trailing string
+	}{Explanation: exp}
+
+	data.Spans, data.Trailing = htmlTokens(exp)
+
+	var buf bytes.Buffer
+	if err := htmlPageTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// htmlTokens locates each token in the pattern, keeping any text the
+// tokenizer skipped so the rendered pattern reads exactly like the input
+func htmlTokens(exp *Explanation) ([]htmlToken, string) {
+	var spans []htmlToken
+	pos := 0
+	for i, token := range exp.Tokens {
+		tokenPos := strings.Index(exp.Pattern[pos:], token.Token)
+		if tokenPos == -1 {
+			continue
+		}
+		if tokenPos > 0 {
+			spans = append(spans, htmlToken{Index: -1, Text: exp.Pattern[pos : pos+tokenPos]})
+		}
+		spans = append(spans, htmlToken{
+			Index:       i + 1,
+			Text:        token.Token,
+			Explanation: token.Explanation,
+			Color:       htmlPalette[i%len(htmlPalette)],
+		})
+		pos += tokenPos + len(token.Token)
+	}
+	return spans, exp.Pattern[pos:]
+}
+
+var htmlPageTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Regex explanation: {{.Pattern}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #24292e; }
+code, pre, .pattern, textarea { font-family: SFMono-Regular, Consolas, "Liberation Mono", Menlo, monospace; }
+.pattern { font-size: 1.6em; padding: 0.6em; background: #f6f8fa; border-radius: 6px; word-break: break-all; }
+.tok { position: relative; font-weight: bold; cursor: help; border-bottom: 2px solid transparent; }
+.tok:hover { border-bottom-color: currentColor; }
+.tok .tip { visibility: hidden; opacity: 0; position: absolute; left: 0; top: 1.8em; z-index: 10; width: max-content; max-width: 28em;
+  padding: 0.5em 0.7em; font-size: 0.55em; font-weight: normal; font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif;
+  color: #fff; background: #24292e; border-radius: 4px; transition: opacity 0.15s; }
+.tok:hover .tip { visibility: visible; opacity: 1; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+th, td { text-align: left; padding: 0.35em 0.7em; border-bottom: 1px solid #e1e4e8; vertical-align: top; }
+.yes { color: #22863a; } .no { color: #d73a49; }
+textarea { width: 100%; min-height: 6em; font-size: 1em; padding: 0.5em; box-sizing: border-box; }
+#result { white-space: pre-wrap; padding: 0.6em; background: #f6f8fa; border-radius: 6px; min-height: 1.5em; }
+#result mark { background: #fff5b1; border-bottom: 2px solid #b08800; }
+#status { color: #586069; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>Regex explanation</h1>
+<p>Format: <strong>{{.Format}}</strong></p>
+{{- if .Flags}}
+<p>Flags: {{.FlagsDescription}}</p>
+{{- end}}
+
+<div class="pattern">
+{{- range .Spans -}}
+{{- if lt .Index 0}}{{.Text}}{{else -}}
+<span class="tok" style="color: {{.Color}}">{{.Text}}<span class="tip">{{.Index}}. {{.Explanation}}</span></span>
+{{- end -}}
+{{- end -}}
+{{.Trailing}}</div>
+
+<h2>Tokens</h2>
+<table>
+<tr><th>#</th><th>Token</th><th>Explanation</th></tr>
+{{- range $i, $t := .Tokens}}
+<tr><td>{{inc $i}}</td><td><code>{{$t.Token}}</code></td><td>{{$t.Explanation}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Supported features</h2>
+<table>
+<tr><th>Feature</th><th>Syntax</th><th>Supported</th></tr>
+{{- range .Features}}
+<tr><td>{{.Name}}</td><td><code>{{.Syntax}}</code></td><td>{{if .Supported}}<span class="yes">✓</span>{{else}}<span class="no">✗</span>{{end}}</td></tr>
+{{- end}}
+</table>
+
+{{- if .Sample}}
+<h2>Example match</h2>
+<pre>{{.Sample}}</pre>
+<p><em>{{.SampleStatus}}</em></p>
+{{- end}}
+
+<h2>Try it</h2>
+<textarea id="input" spellcheck="false" placeholder="Type a test string...">{{.Sample}}</textarea>
+<p id="status"></p>
+<div id="result"></div>
+
+<script>
+(function () {
+  var source = {{.Pattern}};
+  var input = document.getElementById("input");
+  var result = document.getElementById("result");
+  var status = document.getElementById("status");
+
+  // Translate the most common non-JavaScript syntax so the browser engine can evaluate it
+  var jsSource = source.replace(/^\/(.*)\/[a-z]*$/, "$1").replace(/\(\?P</g, "(?<").replace(/\(\?P=(\w+)\)/g, "\\k<$1>");
+  var re = null;
+  try {
+    re = new RegExp(jsSource, "gu");
+  } catch (e) {
+    try {
+      re = new RegExp(jsSource, "g");
+    } catch (e2) {
+      status.textContent = "This pattern can't be evaluated by the browser's regex engine: " + e2.message;
+    }
+  }
+
+  function escapeHTML(s) {
+    return s.replace(/[&<>"']/g, function (c) {
+      return { "&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;", "'": "&#39;" }[c];
+    });
+  }
+
+  function update() {
+    if (!re) {
+      result.textContent = input.value;
+      return;
+    }
+    var text = input.value, html = "", last = 0, count = 0, m;
+    re.lastIndex = 0;
+    while ((m = re.exec(text)) !== null) {
+      if (m[0] === "") {
+        re.lastIndex++;
+        continue;
+      }
+      html += escapeHTML(text.slice(last, m.index)) + "<mark>" + escapeHTML(m[0]) + "</mark>";
+      last = m.index + m[0].length;
+      count++;
+    }
+    html += escapeHTML(text.slice(last));
+    result.innerHTML = html;
+    status.textContent = count === 1 ? "1 match" : count + " matches";
+  }
+
+  input.addEventListener("input", update);
+  update();
+})();
+</script>
+</body>
+</html>
+`))
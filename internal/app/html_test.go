@@ -0,0 +1,44 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderHTML(t *testing.T) {
+	got, err := RenderHTML(Analyze(`^<b>\d+</b>$`, "go"))
+	if err != nil {
+		t.Fatalf("RenderHTML() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		`<span class="tok" style="color: #d73a49">^<span class="tip">1. Matches only at the start of the input</span></span>`,
+		"&lt;b&gt;",
+		`var source = "^\u003cb\u003e\\d+\u003c/b\u003e$";`,
+		`<textarea id="input"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderHTML() should contain %q", want)
+		}
+	}
+
+	if strings.Contains(got, "<b>") {
+		t.Error("RenderHTML() should escape the pattern")
+	}
+}
+
+func TestHTMLTokens(t *testing.T) {
+	exp := Analyze(`/ab+/g`, "js")
+	spans, trailing := htmlTokens(exp)
+
+	var text strings.Builder
+	for _, span := range spans {
+		text.WriteString(span.Text)
+	}
+	text.WriteString(trailing)
+
+	if !strings.Contains(text.String(), "ab+") {
+		t.Errorf("htmlTokens() should cover the pattern, got %q", text.String())
+	}
+}
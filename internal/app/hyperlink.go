@@ -0,0 +1,91 @@
+package app
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	mdnRegexGuide = "https://developer.mozilla.org/en-US/docs/Web/JavaScript/Guide/Regular_expressions"
+	mdnRegexRef   = "https://developer.mozilla.org/en-US/docs/Web/JavaScript/Reference/Regular_expressions"
+)
+
+// jsDocURLs maps token categories to the MDN page documenting them
+var jsDocURLs = map[string]string{
+	CategoryAnchor:        mdnRegexGuide + "/Assertions",
+	CategoryQuantifier:    mdnRegexGuide + "/Quantifiers",
+	CategoryGroup:         mdnRegexGuide + "/Groups_and_backreferences",
+	CategoryBackreference: mdnRegexGuide + "/Groups_and_backreferences",
+	CategoryClass:         mdnRegexGuide + "/Character_classes",
+	CategoryEscape:        mdnRegexGuide + "/Character_classes",
+	CategoryAny:           mdnRegexGuide + "/Character_classes",
+	CategoryAlternation:   mdnRegexRef + "/Disjunction",
+	CategoryFlags:         mdnRegexGuide + "#advanced_searching_with_flags",
+	CategoryLiteral:       mdnRegexGuide + "/Cheatsheet",
+}
+
+// TokenDocURL returns the official documentation page for a token in the
+// given flavor, or an empty string when there is none
+func TokenDocURL(formatName, token string) string {
+	switch formatName {
+	case "go":
+		return "https://pkg.go.dev/regexp/syntax"
+	case "js":
+		return jsDocURLs[TokenCategory(token)]
+	case "python":
+		return "https://docs.python.org/3/library/re.html#regular-expression-syntax"
+	case "pcre":
+		return "https://www.pcre.org/current/doc/html/pcre2pattern.html"
+	case "posix":
+		return "https://pubs.opengroup.org/onlinepubs/9699919799/basedefs/V1_chap09.html"
+	case "grok":
+		return "https://www.elastic.co/guide/en/logstash/current/plugins-filters-grok.html"
+	}
+	return ""
+}
+
+// hyperlink wraps text in an OSC 8 escape sequence so supporting terminals
+// render it as a clickable link
+func hyperlink(url, text string) string {
+	if url == "" {
+		return text
+	}
+	return "\033]8;;" + url + "\033\\" + text + "\033]8;;\033\\"
+}
+
+// SupportsHyperlinks reports whether stdout is a terminal known to render
+// OSC 8 hyperlinks. FORCE_HYPERLINK overrides the detection either way.
+func SupportsHyperlinks() bool {
+	if force, ok := os.LookupEnv("FORCE_HYPERLINK"); ok {
+		enabled, err := strconv.ParseBool(force)
+		return err != nil || enabled
+	}
+
+	return StdoutIsTerminal() && terminalSupportsHyperlinks(os.Getenv)
+}
+
+// terminalSupportsHyperlinks recognizes terminals that implement OSC 8 from
+// the environment variables they set
+func terminalSupportsHyperlinks(getenv func(string) string) bool {
+	if getenv("TERM") == "dumb" || getenv("CI") != "" {
+		return false
+	}
+
+	if getenv("WT_SESSION") != "" || getenv("KONSOLE_VERSION") != "" || getenv("DOMTERM") != "" {
+		return true
+	}
+
+	// VTE-based terminals (GNOME Terminal, Tilix, ...) support it since 0.50
+	if vte, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper", "Tabby":
+		return true
+	}
+
+	term := getenv("TERM")
+	return term == "xterm-kitty" || term == "alacritty" || term == "foot" || strings.HasPrefix(term, "foot-")
+}
@@ -0,0 +1,60 @@
+package app
+
+import "testing"
+
+func TestTokenDocURL(t *testing.T) {
+	tests := []struct {
+		format string
+		token  string
+		want   string
+	}{
+		{"go", `\d`, "https://pkg.go.dev/regexp/syntax"},
+		{"js", "+", mdnRegexGuide + "/Quantifiers"},
+		{"js", "^", mdnRegexGuide + "/Assertions"},
+		{"js", "(?<name>", mdnRegexGuide + "/Groups_and_backreferences"},
+		{"js", "/gi", mdnRegexGuide + "#advanced_searching_with_flags"},
+		{"python", "(?P<name>", "https://docs.python.org/3/library/re.html#regular-expression-syntax"},
+		{"unknown", "a", ""},
+	}
+
+	for _, tt := range tests {
+		if got := TokenDocURL(tt.format, tt.token); got != tt.want {
+			t.Errorf("TokenDocURL(%q, %q) = %q, want %q", tt.format, tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestHyperlink(t *testing.T) {
+	got := hyperlink("https://example.com", "text")
+	if want := "\033]8;;https://example.com\033\\text\033]8;;\033\\"; got != want {
+		t.Errorf("hyperlink() = %q, want %q", got, want)
+	}
+
+	if got := hyperlink("", "text"); got != "text" {
+		t.Errorf("hyperlink() without a URL = %q, want plain text", got)
+	}
+}
+
+func TestTerminalSupportsHyperlinks(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"plain xterm", map[string]string{"TERM": "xterm-256color"}, false},
+		{"dumb", map[string]string{"TERM": "dumb", "TERM_PROGRAM": "iTerm.app"}, false},
+		{"iTerm2", map[string]string{"TERM_PROGRAM": "iTerm.app"}, true},
+		{"Windows Terminal", map[string]string{"WT_SESSION": "abc"}, true},
+		{"new VTE", map[string]string{"VTE_VERSION": "6003"}, true},
+		{"old VTE", map[string]string{"VTE_VERSION": "4601"}, false},
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, true},
+		{"CI", map[string]string{"CI": "true", "TERM_PROGRAM": "vscode"}, false},
+	}
+
+	for _, tt := range tests {
+		getenv := func(key string) string { return tt.env[key] }
+		if got := terminalSupportsHyperlinks(getenv); got != tt.want {
+			t.Errorf("%s: terminalSupportsHyperlinks() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
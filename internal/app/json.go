@@ -0,0 +1,20 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// RenderJSON renders an explanation as indented JSON, the structure the
+// WebAssembly build returns to JavaScript. Characters such as < are left
+// unescaped so patterns stay readable.
+func RenderJSON(exp *Explanation) (string, error) {
+	var result strings.Builder
+	encoder := json.NewEncoder(&result)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(exp); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
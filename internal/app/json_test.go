@@ -0,0 +1,54 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRenderJSON(t *testing.T) {
+	out, err := RenderJSON(Analyze(`^a\d+$`, "pcre"))
+	if err != nil {
+		t.Fatalf("RenderJSON() error = %v", err)
+	}
+
+	var decoded struct {
+		Pattern    string `json:"pattern"`
+		FormatName string `json:"formatName"`
+		Tokens     []struct {
+			Token       string `json:"token"`
+			Explanation string `json:"explanation"`
+		} `json:"tokens"`
+		Features []struct {
+			Name      string `json:"name"`
+			Supported bool   `json:"supported"`
+		} `json:"features"`
+		Sample string `json:"sample"`
+	}
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("RenderJSON() produced invalid JSON: %v\n%s", err, out)
+	}
+
+	if decoded.Pattern != `^a\d+$` || decoded.FormatName != "pcre" {
+		t.Errorf("pattern and format = %q, %q", decoded.Pattern, decoded.FormatName)
+	}
+	if len(decoded.Tokens) != 5 || decoded.Tokens[2].Token != `\d` || decoded.Tokens[2].Explanation == "" {
+		t.Errorf("tokens = %+v", decoded.Tokens)
+	}
+	if len(decoded.Features) == 0 {
+		t.Error("features should be included")
+	}
+	if decoded.Sample == "" {
+		t.Error("sample should be included")
+	}
+}
+
+func TestRenderJSON_Unescaped(t *testing.T) {
+	out, err := RenderJSON(Analyze(`(?<=a)b`, "pcre"))
+	if err != nil {
+		t.Fatalf("RenderJSON() error = %v", err)
+	}
+	if !strings.Contains(out, `"pattern": "(?<=a)b"`) {
+		t.Errorf("RenderJSON() should leave < unescaped:\n%s", out)
+	}
+}
@@ -0,0 +1,229 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// Finding severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Finding is a problem found in a pattern. Offset is the byte offset in the
+// pattern where the problem starts.
+type Finding struct {
+	Severity string
+	Offset   int
+	Message  string
+}
+
+// featureSyntax maps the start of a construct to the feature it needs
+var featureSyntax = []struct {
+	prefix  string
+	feature string
+}{
+	{"(?<=", format.FeatureLookbehind},
+	{"(?<!", format.FeatureLookbehind},
+	{"(?=", format.FeatureLookahead},
+	{"(?!", format.FeatureLookahead},
+	{"(?P<", format.FeatureNamedGroup},
+	{"(?<", format.FeatureNamedGroup},
+	{"(?>", format.FeatureAtomicGroup},
+	{"(?(", format.FeatureConditional},
+	{"(?R)", format.FeatureRecursion},
+	{"(?0)", format.FeatureRecursion},
+	{"(?P=", format.FeatureNamedBackref},
+	{`\k<`, format.FeatureNamedBackref},
+	{`\p{`, format.FeatureUnicodeClass},
+	{`\P{`, format.FeatureUnicodeClass},
+}
+
+// nestedQuantifier matches a quantified group that itself ends in a
+// quantifier, such as (a+)+, the classic cause of catastrophic backtracking
+var nestedQuantifier = regexp.MustCompile(`\([^()]*[+*]\)[+*{]`)
+
+// Lint checks a pattern for errors, such as unbalanced groups or features
+// the flavor doesn't support, and for warnings about likely mistakes
+func Lint(pattern, formatName string) []Finding {
+	regexFormat := format.GetFormat(formatName)
+	var findings []Finding
+
+	findings = append(findings, lintStructure(pattern)...)
+
+	// Constructs the flavor doesn't support
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			if i+1 < len(pattern) && pattern[i+1] >= '1' && pattern[i+1] <= '9' &&
+				!regexFormat.HasFeature(format.FeatureBackreference) {
+				findings = append(findings, Finding{SeverityError, i, fmt.Sprintf("%s doesn't support backreferences", regexFormat.Name())})
+			}
+		case '[':
+			if end := format.FindClosingBracket(pattern, i); end > i {
+				i = end
+				continue
+			}
+		case '+', '*', '?':
+			if i+1 < len(pattern) && pattern[i+1] == '+' && !regexFormat.HasFeature(format.FeaturePossessive) {
+				findings = append(findings, Finding{SeverityError, i, fmt.Sprintf("%s doesn't support possessive quantifiers", regexFormat.Name())})
+			}
+		}
+
+		for _, construct := range featureSyntax {
+			if strings.HasPrefix(pattern[i:], construct.prefix) {
+				if !regexFormat.HasFeature(construct.feature) {
+					findings = append(findings, Finding{SeverityError, i,
+						fmt.Sprintf("%s doesn't support %s", regexFormat.Name(), featureName(construct.feature))})
+				}
+				break
+			}
+		}
+
+		if pattern[i] == '\\' {
+			i++
+		}
+	}
+
+	// Go patterns can be checked by the real parser
+	if formatName == "go" && len(findings) == 0 {
+		if _, err := syntax.Parse(pattern, syntax.Perl); err != nil {
+			findings = append(findings, Finding{SeverityError, syntaxErrorOffset(pattern, err), err.Error()})
+		}
+	}
+
+	// Tokens the explainer rejects, such as unknown escapes
+	pos := 0
+	for _, token := range regexFormat.TokenizeRegex(pattern) {
+		tokenPos := strings.Index(pattern[pos:], token)
+		if tokenPos == -1 {
+			continue
+		}
+		tokenPos += pos
+		pos = tokenPos + len(token)
+		if explanation := regexFormat.ExplainToken(token); strings.HasPrefix(explanation, "Invalid") {
+			findings = append(findings, Finding{SeverityError, tokenPos, fmt.Sprintf("%s: %s", explanation, token)})
+		}
+	}
+
+	// Likely mistakes
+	if loc := nestedQuantifier.FindStringIndex(pattern); loc != nil && formatName != "go" {
+		findings = append(findings, Finding{SeverityWarning, loc[0], "nested quantifiers can cause catastrophic backtracking"})
+	}
+	findings = append(findings, lintNormalization(pattern, formatName)...)
+	findings = append(findings, lintLineEndings(pattern, formatName)...)
+	findings = append(findings, lintCollation(pattern, formatName)...)
+	for _, empty := range []string{"(|", "||", "|)"} {
+		if idx := strings.Index(pattern, empty); idx >= 0 && !isEscaped(pattern, idx+1) {
+			findings = append(findings, Finding{SeverityWarning, idx, "empty alternative matches the empty string"})
+			break
+		}
+	}
+
+	return findings
+}
+
+// lintStructure reports unbalanced parentheses and unterminated classes
+func lintStructure(pattern string) []Finding {
+	var findings []Finding
+	var open []int
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+			if i == len(pattern) {
+				findings = append(findings, Finding{SeverityError, i - 1, "trailing backslash"})
+			}
+		case '[':
+			end := format.FindClosingBracket(pattern, i)
+			if end == -1 {
+				findings = append(findings, Finding{SeverityError, i, "unterminated character class"})
+				return findings
+			}
+			i = end
+		case '(':
+			open = append(open, i)
+		case ')':
+			if len(open) == 0 {
+				findings = append(findings, Finding{SeverityError, i, "unmatched closing parenthesis"})
+			} else {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	for _, pos := range open {
+		findings = append(findings, Finding{SeverityError, pos, "unclosed group"})
+	}
+	return findings
+}
+
+// lintCollation warns about the first collating symbol or equivalence
+// class, such as [.ch.] or [=e=] in a bracket expression: POSIX
+// implementations support them unevenly and other flavors match their
+// characters one by one
+func lintCollation(pattern, formatName string) []Finding {
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '\\' {
+			i++
+			continue
+		}
+		if pattern[i] != '[' {
+			continue
+		}
+		end := format.FindClosingBracket(pattern, i)
+		if end < 0 {
+			return nil
+		}
+		for j := i + 1; j+1 < end; j++ {
+			if pattern[j] != '[' || (pattern[j+1] != '.' && pattern[j+1] != '=') {
+				continue
+			}
+			closing := strings.Index(pattern[j+2:end], string(pattern[j+1])+"]")
+			if closing < 0 {
+				continue
+			}
+			item := pattern[j : j+2+closing+2]
+			if formatName == "posix" {
+				return []Finding{{SeverityWarning, j, fmt.Sprintf("%s isn't portable: %s", item, format.CollationSupportNote)}}
+			}
+			return []Finding{{SeverityWarning, j, fmt.Sprintf("%s doesn't have collating symbols or equivalence classes, so %s matches its characters one by one",
+				format.GetFormat(formatName).Name(), item)}}
+		}
+		i = end
+	}
+	return nil
+}
+
+// isEscaped reports whether the byte at pos is preceded by an odd number of backslashes
+func isEscaped(pattern string, pos int) bool {
+	backslashes := 0
+	for i := pos - 1; i >= 0 && pattern[i] == '\\'; i-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+// syntaxErrorOffset locates the expression a regexp/syntax error points at
+func syntaxErrorOffset(pattern string, err error) int {
+	if syntaxErr, ok := err.(*syntax.Error); ok && syntaxErr.Expr != "" {
+		if idx := strings.Index(pattern, syntaxErr.Expr); idx >= 0 {
+			return idx
+		}
+	}
+	return 0
+}
+
+// featureName returns the readable name of a feature code
+func featureName(code string) string {
+	for _, feature := range features {
+		if feature.code == code {
+			return strings.ToLower(feature.name)
+		}
+	}
+	return code
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		format   string
+		severity string
+		offset   int
+		message  string
+	}{
+		{`(?<=a)b`, "go", SeverityError, 0, "Go Regexp doesn't support lookbehind"},
+		{`a++`, "js", SeverityError, 1, "JavaScript RegExp doesn't support possessive quantifiers"},
+		{`a(b`, "go", SeverityError, 1, "unclosed group"},
+		{`a)b`, "pcre", SeverityError, 1, "unmatched closing parenthesis"},
+		{`x[a-z`, "go", SeverityError, 1, "unterminated character class"},
+		{`x**`, "go", SeverityError, 1, "error parsing regexp: invalid nested repetition operator: `**`"},
+		{`^(a+)+$`, "pcre", SeverityWarning, 1, "nested quantifiers can cause catastrophic backtracking"},
+		{`a||b`, "go", SeverityWarning, 1, "empty alternative matches the empty string"},
+		{`x[[=e=]]`, "go", SeverityWarning, 2, "Go Regexp doesn't have collating symbols or equivalence classes, so [=e=] matches its characters one by one"},
+		{`[a[.hyphen.]]`, "posix", SeverityWarning, 2, "[.hyphen.] isn't portable: " + format.CollationSupportNote},
+	}
+
+	for _, tt := range tests {
+		findings := Lint(tt.pattern, tt.format)
+		if len(findings) != 1 {
+			t.Errorf("Lint(%q, %q) = %v, want one finding", tt.pattern, tt.format, findings)
+			continue
+		}
+		want := Finding{tt.severity, tt.offset, tt.message}
+		if findings[0] != want {
+			t.Errorf("Lint(%q, %q) = %+v, want %+v", tt.pattern, tt.format, findings[0], want)
+		}
+	}
+}
+
+func TestLint_Clean(t *testing.T) {
+	for _, tt := range []struct{ pattern, format string }{
+		{`^\d{3}-\d{4}$`, "go"},
+		{`(?<=a)b`, "pcre"},
+		{`\(?=not a lookahead\)`, "go"},
+		{`[(?<=]x`, "go"},
+		{`a\|\|b`, "go"},
+		{`[[:alpha:]]`, "posix"},
+	} {
+		if findings := Lint(tt.pattern, tt.format); len(findings) != 0 {
+			t.Errorf("Lint(%q, %q) = %v, want no findings", tt.pattern, tt.format, findings)
+		}
+	}
+}
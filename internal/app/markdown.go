@@ -0,0 +1,66 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderMarkdown renders an explanation as GitHub-flavored Markdown
+func RenderMarkdown(exp *Explanation) string {
+	var result strings.Builder
+
+	result.WriteString("## Regex explanation\n\n")
+	result.WriteString(markdownFence(exp.Pattern, "regex"))
+	result.WriteString(fmt.Sprintf("\n**Format:** %s\n\n", exp.Format))
+	if exp.Flags != "" {
+		result.WriteString(fmt.Sprintf("**Flags:** %s\n\n", markdownCell(exp.FlagsDescription)))
+	}
+
+	result.WriteString("### Tokens\n\n")
+	result.WriteString("| # | Token | Explanation |\n")
+	result.WriteString("|---|-------|-------------|\n")
+	for i, token := range exp.Tokens {
+		result.WriteString(fmt.Sprintf("| %d | %s | %s |\n", i+1, markdownCode(token.Token), markdownCell(token.Explanation)))
+	}
+
+	if exp.Expanded != nil {
+		result.WriteString("\n### Expanded regex\n\n")
+		result.WriteString(markdownFence(exp.Expanded.Pattern, "regex"))
+		result.WriteString("\n| # | Token | Explanation |\n")
+		result.WriteString("|---|-------|-------------|\n")
+		for i, token := range exp.Expanded.Tokens {
+			result.WriteString(fmt.Sprintf("| %d | %s | %s |\n", i+1, markdownCode(token.Token), markdownCell(token.Explanation)))
+		}
+	}
+
+	result.WriteString("\n### Supported features\n\n")
+	result.WriteString("| Feature | Syntax | Supported |\n")
+	result.WriteString("|---------|--------|:---------:|\n")
+	for _, feature := range exp.Features {
+		supported := "✗"
+		if feature.Supported {
+			supported = "✓"
+		}
+		result.WriteString(fmt.Sprintf("| %s | %s | %s |\n", feature.Name, markdownCode(feature.Syntax), supported))
+	}
+
+	result.WriteString("\n### Example match\n\n")
+	if exp.Sample == "" {
+		result.WriteString("_Couldn't generate a sample for this pattern._\n")
+	} else {
+		result.WriteString(markdownFence(exp.Sample, "text"))
+		result.WriteString(fmt.Sprintf("\n_%s_\n", exp.SampleStatus))
+	}
+
+	return result.String()
+}
+
+// markdownFence wraps text in a fenced code block, lengthening the fence when
+// the text itself contains backtick runs
+func markdownFence(text, language string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fmt.Sprintf("%s%s\n%s\n%s\n", fence, language, text, fence)
+}
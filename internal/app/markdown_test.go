@@ -0,0 +1,44 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	got := RenderMarkdown(Analyze(`^(foo|bar)\d+$`, "go"))
+
+	for _, want := range []string{
+		"```regex\n^(foo|bar)\\d+$\n```",
+		"**Format:** Go Regexp",
+		"| 1 | `^` | Matches only at the start of the input |",
+		"| 4 | `\\|` | Acts as an OR operator - matches the expression before or after the \\| |",
+		"| Lookbehind | `(?<=pattern) or (?<!pattern)` | ✗ |",
+		"### Example match",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderMarkdown() should contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderMarkdown_Flags(t *testing.T) {
+	got := RenderMarkdown(AnalyzeWithFlags(`^a.`, "go", "m"))
+
+	for _, want := range []string{
+		"**Flags:** m: multi-line mode (^ and $ match at line breaks)",
+		"| 1 | `^` | Matches at the start of the input and right after each line break (m is on) |",
+		"| 3 | `.` | Matches any single character except newline |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderMarkdown() should contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestMarkdownFence(t *testing.T) {
+	got := markdownFence("a```b", "text")
+	if !strings.HasPrefix(got, "````text\n") || !strings.HasSuffix(got, "\n````\n") {
+		t.Errorf("markdownFence() should use a longer fence than the content, got %q", got)
+	}
+}
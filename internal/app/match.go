@@ -0,0 +1,407 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Span is where the whole match or one capture group matched in an input,
+// like an entry of the indices array JavaScript's d flag adds. Start and End
+// are byte offsets, RuneStart and RuneEnd the same offsets in code points,
+// and Line and Column the 1-based line and rune column Start is at. Pattern
+// is the 1-based number of the pattern that matched when several are
+// matched with MatchPatterns. A group that didn't take part in the match
+// has Matched unset.
+type Span struct {
+	Group     int    `json:"group"`
+	Name      string `json:"name,omitempty"`
+	Matched   bool   `json:"matched"`
+	Text      string `json:"text"`
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+	RuneStart int    `json:"runeStart"`
+	RuneEnd   int    `json:"runeEnd"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	Pattern   int    `json:"pattern,omitempty"`
+}
+
+// MatchResult is the outcome of matching a pattern against one input.
+// Spans holds the whole match as group 0, followed by every capture group.
+// Matches holds the spans of every match when they were asked for with
+// MatchAll or MatchOverlapping. Source names the file the input was read
+// from, if any, so locations can be reported as file:line:column.
+type MatchResult struct {
+	Source  string   `json:"source,omitempty"`
+	Input   string   `json:"input"`
+	Matched bool     `json:"matched"`
+	Spans   []Span   `json:"spans,omitempty"`
+	Matches [][]Span `json:"matches,omitempty"`
+}
+
+// CompilePattern compiles a pattern of any flavor with Go's regexp package,
+// along with flags set outside it that Go understands, reporting an
+// ErrUnsupportedFeature or ErrSyntax when it isn't RE2-compatible
+func CompilePattern(pattern, formatName, flags string) (*regexp.Regexp, error) {
+	converted := goCompatiblePattern(pattern, formatName)
+	var goFlags strings.Builder
+	for _, f := range flags {
+		if f == 'i' || f == 'm' || f == 's' {
+			goFlags.WriteRune(f)
+		}
+	}
+	if goFlags.Len() > 0 {
+		converted = "(?" + goFlags.String() + ")" + converted
+	}
+
+	r, err := regexp.Compile(converted)
+	if err != nil {
+		return nil, fmt.Errorf("pattern can't be matched: %w", goSyntaxError(converted, err))
+	}
+	return r, nil
+}
+
+// wholeMatcher compiles a compiled pattern anchored to both ends of the
+// text, so it only matches strings it matches as a whole
+func wholeMatcher(r *regexp.Regexp) (*regexp.Regexp, error) {
+	return compileWrapped(r, func(parsed *syntax.Regexp) *syntax.Regexp {
+		return &syntax.Regexp{Op: syntax.OpConcat, Sub: []*syntax.Regexp{{Op: syntax.OpBeginText}, parsed, {Op: syntax.OpEndText}}}
+	})
+}
+
+// compileWrapped compiles a compiled pattern with what wrap puts around its
+// syntax tree. It's built from the tree rather than the pattern's text, as
+// a \Q left open in the pattern would swallow anything appended to it.
+func compileWrapped(r *regexp.Regexp, wrap func(*syntax.Regexp) *syntax.Regexp) (*regexp.Regexp, error) {
+	parsed, err := syntax.Parse(r.String(), syntax.Perl)
+	if err != nil {
+		return nil, goSyntaxError(r.String(), err)
+	}
+	wrapped := wrap(parsed).String()
+	compiled, err := regexp.Compile(wrapped)
+	if err != nil {
+		return nil, goSyntaxError(wrapped, err)
+	}
+	return compiled, nil
+}
+
+// MatchInput matches a compiled pattern against an input, reporting the
+// first match and the offsets of each of its capture groups
+func MatchInput(r *regexp.Regexp, input string) MatchResult {
+	result := MatchResult{Input: input}
+	loc := r.FindStringSubmatchIndex(input)
+	if loc == nil {
+		return result
+	}
+
+	result.Matched = true
+	result.Spans = matchSpans(r, input, loc)
+	return result
+}
+
+// MatchAll matches a compiled pattern against an input like MatchInput,
+// but reports every match the usual scan finds, as for the lines of a file
+func MatchAll(r *regexp.Regexp, input string) MatchResult {
+	result := MatchResult{Input: input}
+	for _, loc := range r.FindAllStringSubmatchIndex(input, -1) {
+		result.Matches = append(result.Matches, matchSpans(r, input, loc))
+	}
+	if len(result.Matches) > 0 {
+		result.Matched = true
+		result.Spans = result.Matches[0]
+	}
+	return result
+}
+
+// MatchPatterns matches several compiled patterns against each line of an
+// input, like grep -e, keeping the lines that any of them matches, or with
+// all the lines that every one of them does. The result holds every match
+// on those lines, in order, with the number of the pattern that made it.
+func MatchPatterns(patterns []*regexp.Regexp, input string, all bool) MatchResult {
+	result := MatchResult{Input: input}
+	start := 0
+	for _, line := range strings.SplitAfter(input, "\n") {
+		text := strings.TrimSuffix(line, "\n")
+		var matches [][]Span
+		matched := 0
+		for i, r := range patterns {
+			locs := r.FindAllStringSubmatchIndex(text, -1)
+			if len(locs) > 0 {
+				matched++
+			}
+			for _, loc := range locs {
+				for j := range loc {
+					if loc[j] >= 0 {
+						loc[j] += start
+					}
+				}
+				spans := matchSpans(r, input, loc)
+				for j := range spans {
+					spans[j].Pattern = i + 1
+				}
+				matches = append(matches, spans)
+			}
+		}
+		start += len(line)
+
+		if matched == 0 || (all && matched < len(patterns)) {
+			continue
+		}
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i][0].Start < matches[j][0].Start })
+		result.Matches = append(result.Matches, matches...)
+	}
+
+	if len(result.Matches) > 0 {
+		result.Matched = true
+		result.Spans = result.Matches[0]
+	}
+	return result
+}
+
+// MatchOverlapping matches a compiled pattern against an input like
+// MatchInput, but reports every match, including ones that overlap: after
+// each match the search restarts one rune after where it started, rather
+// than where it ended. Anchors and word boundaries still see the text
+// before the restart.
+func MatchOverlapping(r *regexp.Regexp, input string) MatchResult {
+	result := MatchResult{Input: input}
+
+	// Past the start, the rune before the restart is matched as context
+	// and the pattern is group 1, whose own groups are numbered after it
+	context, err := compileWrapped(r, func(parsed *syntax.Regexp) *syntax.Regexp {
+		group := &syntax.Regexp{Op: syntax.OpCapture, Cap: 1, Sub: []*syntax.Regexp{parsed}}
+		return &syntax.Regexp{Op: syntax.OpConcat, Sub: []*syntax.Regexp{{Op: syntax.OpAnyChar}, group}}
+	})
+	if err != nil {
+		// A pattern Go compiled always compiles again, but if it ever
+		// doesn't, the matches that don't overlap are still right
+		return MatchAll(r, input)
+	}
+	for pos := 0; pos <= len(input); {
+		var loc []int
+		if pos == 0 {
+			loc = r.FindStringSubmatchIndex(input)
+		} else {
+			_, size := utf8.DecodeLastRuneInString(input[:pos])
+			from := pos - size
+			if loc = context.FindStringSubmatchIndex(input[from:]); loc != nil {
+				loc = loc[2:]
+				for i := range loc {
+					if loc[i] >= 0 {
+						loc[i] += from
+					}
+				}
+			}
+		}
+		if loc == nil {
+			break
+		}
+
+		result.Matches = append(result.Matches, matchSpans(r, input, loc))
+		if loc[0] == len(input) {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(input[loc[0]:])
+		pos = loc[0] + size
+	}
+
+	if len(result.Matches) > 0 {
+		result.Matched = true
+		result.Spans = result.Matches[0]
+	}
+	return result
+}
+
+// matchSpans turns the submatch offsets of a match into spans for the whole
+// match and each capture group
+func matchSpans(r *regexp.Regexp, input string, loc []int) []Span {
+	var spans []Span
+	names := r.SubexpNames()
+	for group := 0; group*2 < len(loc); group++ {
+		span := Span{Group: group, Name: names[group], Start: loc[group*2], End: loc[group*2+1]}
+		if span.Start >= 0 {
+			span.Matched = true
+			span.Text = input[span.Start:span.End]
+			span.RuneStart = utf8.RuneCountInString(input[:span.Start])
+			span.RuneEnd = span.RuneStart + utf8.RuneCountInString(span.Text)
+			lineStart := strings.LastIndexByte(input[:span.Start], '\n') + 1
+			span.Line = strings.Count(input[:lineStart], "\n") + 1
+			span.Column = utf8.RuneCountInString(input[lineStart:span.Start]) + 1
+		}
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+// RenderMatch renders a match result as text, one line per span with its
+// byte and rune offsets, numbering the matches when there are several
+func RenderMatch(result MatchResult) string {
+	var out strings.Builder
+	name := fmt.Sprintf("%q", result.Input)
+	if result.Source != "" {
+		name = result.Source
+	}
+	if !result.Matched {
+		fmt.Fprintf(&out, "%s: no match\n", name)
+		return out.String()
+	}
+
+	if result.Matches == nil {
+		fmt.Fprintf(&out, "%s: match\n", name)
+		renderSpans(&out, result, result.Spans, "Match", "  ")
+		return out.String()
+	}
+	noun := "matches"
+	if len(result.Matches) == 1 {
+		noun = "match"
+	}
+	fmt.Fprintf(&out, "%s: %d %s\n", name, len(result.Matches), noun)
+	for i, spans := range result.Matches {
+		renderSpans(&out, result, spans, fmt.Sprintf("Match %d", i+1), "    ")
+	}
+	return out.String()
+}
+
+// renderSpans writes one line per span of a match, labelling the whole
+// match with label and indenting the capture groups by groupIndent. Spans
+// of inputs read from a file get a file:line:column location editors can
+// open, and those of other multi-line inputs their line and column.
+func renderSpans(out *strings.Builder, result MatchResult, spans []Span, label, groupIndent string) {
+	multiLine := strings.Contains(result.Input, "\n")
+	for _, span := range spans {
+		label, indent := label, "  "
+		if span.Group > 0 {
+			label = fmt.Sprintf("Group %d", span.Group)
+			if span.Name != "" {
+				label += fmt.Sprintf(" (%s)", span.Name)
+			}
+			indent = groupIndent
+		}
+		if !span.Matched {
+			fmt.Fprintf(out, "%s%s: didn't participate\n", indent, label)
+			continue
+		}
+		fmt.Fprintf(out, "%s%s: %q bytes %d-%d, runes %d-%d",
+			indent, label, span.Text, span.Start, span.End, span.RuneStart, span.RuneEnd)
+		switch {
+		case result.Source != "":
+			fmt.Fprintf(out, ", at %s:%d:%d", result.Source, span.Line, span.Column)
+		case multiLine:
+			fmt.Fprintf(out, ", line %d column %d", span.Line, span.Column)
+		}
+		out.WriteString("\n")
+	}
+}
+
+// RenderMatchLines renders the lines of an input that have a match, as grep
+// does, with before and after lines of context around them. Matching lines
+// are prefixed with their file:line:column, context lines with file-line-,
+// leaving out the file for inputs that weren't read from one, and groups of
+// lines are separated with --. Each match is bold on its lines and each
+// capture group in it gets a color of its own, or the whole match the color
+// of its pattern when several patterns were matched.
+func RenderMatchLines(result MatchResult, before, after int) string {
+	lines := strings.SplitAfter(result.Input, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	starts := make([]int, len(lines)+1)
+	for i, line := range lines {
+		starts[i+1] = starts[i] + len(line)
+	}
+
+	// The matches on each line, including the lines a match continues onto,
+	// and the first column matched on it
+	onLine := make(map[int][][]Span)
+	columns := make(map[int]int)
+	for _, spans := range result.Matches {
+		whole := spans[0]
+		if column, ok := columns[whole.Line]; !ok || whole.Column < column {
+			columns[whole.Line] = whole.Column
+		}
+		last := whole.Line + strings.Count(whole.Text, "\n")
+		for line := whole.Line; line <= last; line++ {
+			if _, ok := columns[line]; !ok {
+				columns[line] = 1
+			}
+			onLine[line] = append(onLine[line], spans)
+		}
+	}
+
+	prefix, contextPrefix := "", ""
+	if result.Source != "" {
+		prefix, contextPrefix = result.Source+":", result.Source+"-"
+	}
+
+	var out strings.Builder
+	printed := 0
+	for line := 1; line <= len(lines); line++ {
+		nearby := false
+		for near := max(1, line-after); near <= line+before && !nearby; near++ {
+			_, nearby = columns[near]
+		}
+		if !nearby {
+			continue
+		}
+		if printed > 0 && printed < line-1 {
+			out.WriteString("--\n")
+		}
+		printed = line
+
+		text := strings.TrimSuffix(lines[line-1], "\n")
+		if column, ok := columns[line]; ok {
+			fmt.Fprintf(&out, "%s%d:%d:%s\n", prefix, line, column,
+				highlightLine(onLine[line], starts[line-1], text))
+		} else {
+			fmt.Fprintf(&out, "%s%d-%s\n", contextPrefix, line, text)
+		}
+	}
+	return out.String()
+}
+
+// highlightLine colors the parts of a line starting at byte offset start
+// that matches cover, using the color of the innermost capture group at
+// each character and bold for the rest of a match
+func highlightLine(matches [][]Span, start int, text string) string {
+	var out strings.Builder
+	current := ""
+	for i, r := range text {
+		pos := start + i
+		style := ""
+		for _, spans := range matches {
+			for _, span := range spans {
+				if !span.Matched || pos < span.Start || pos >= span.End {
+					continue
+				}
+				// Groups are numbered by their opening parenthesis, so the
+				// innermost group covering a character comes last. With
+				// several patterns, matches get the color of their pattern
+				// instead of their groups'.
+				if span.Group == 0 {
+					style = colorBold
+					if span.Pattern > 0 && len(tokenColors) > 0 {
+						style += tokenColors[(span.Pattern-1)%len(tokenColors)]
+					}
+				} else if span.Pattern == 0 && len(tokenColors) > 0 {
+					style = colorBold + tokenColors[(span.Group-1)%len(tokenColors)]
+				}
+			}
+		}
+		if style != current {
+			if current != "" {
+				out.WriteString(colorReset)
+			}
+			out.WriteString(style)
+			current = style
+		}
+		out.WriteRune(r)
+	}
+	if current != "" {
+		out.WriteString(colorReset)
+	}
+	return out.String()
+}
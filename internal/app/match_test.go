@@ -0,0 +1,253 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestMatchInput(t *testing.T) {
+	r, err := CompilePattern(`(?P<year>\d{4})-(\d{2})(x)?`, "go", "")
+	if err != nil {
+		t.Fatalf("CompilePattern returned error: %v", err)
+	}
+
+	got := MatchInput(r, "é 2024-01")
+	want := MatchResult{
+		Input:   "é 2024-01",
+		Matched: true,
+		Spans: []Span{
+			{Group: 0, Matched: true, Text: "2024-01", Start: 3, End: 10, RuneStart: 2, RuneEnd: 9, Line: 1, Column: 3},
+			{Group: 1, Name: "year", Matched: true, Text: "2024", Start: 3, End: 7, RuneStart: 2, RuneEnd: 6, Line: 1, Column: 3},
+			{Group: 2, Matched: true, Text: "01", Start: 8, End: 10, RuneStart: 7, RuneEnd: 9, Line: 1, Column: 8},
+			{Group: 3, Start: -1, End: -1},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchInput() = %+v, want %+v", got, want)
+	}
+
+	if got := MatchInput(r, "nope"); got.Matched || got.Spans != nil {
+		t.Errorf("MatchInput(%q) = %+v, want no match", "nope", got)
+	}
+}
+
+func TestCompilePattern(t *testing.T) {
+	tests := []struct {
+		pattern, formatName, flags, input string
+		want                              bool
+	}{
+		{"/ABC/i", "js", "", "abc", true},
+		{"abc", "python", "i", "ABC", true},
+		{`r"\d+"`, "python", "", "42", true},
+		{"abc", "go", "", "ABC", false},
+		{`^\h\H$`, "pcre", "", "\tx", true},
+		{`^[^\h]$`, "pcre", "", " ", false},
+		{`^\N\v$`, "pcre", "", "a\r", true},
+		{`^[^]$`, "js", "", "\n", true},
+		{`^a[]$`, "js", "", "a]", false},
+		{`^[]a]$`, "pcre", "", "]", true},
+	}
+
+	for _, tt := range tests {
+		r, err := CompilePattern(tt.pattern, tt.formatName, tt.flags)
+		if err != nil {
+			t.Errorf("CompilePattern(%q, %q, %q) returned error: %v", tt.pattern, tt.formatName, tt.flags, err)
+			continue
+		}
+		if got := r.MatchString(tt.input); got != tt.want {
+			t.Errorf("CompilePattern(%q, %q, %q).MatchString(%q) = %v, want %v", tt.pattern, tt.formatName, tt.flags, tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := CompilePattern("(?<=a)b", "pcre", ""); ExitCode(err) != ExitUnsupported {
+		t.Errorf("CompilePattern(%q) error = %v, want an ErrUnsupportedFeature", "(?<=a)b", err)
+	}
+}
+
+func TestRenderMatch(t *testing.T) {
+	result := MatchResult{
+		Input:   "ab",
+		Matched: true,
+		Spans: []Span{
+			{Group: 0, Matched: true, Text: "a", Start: 0, End: 1, RuneStart: 0, RuneEnd: 1},
+			{Group: 1, Name: "n", Start: -1, End: -1},
+		},
+	}
+	want := "\"ab\": match\n  Match: \"a\" bytes 0-1, runes 0-1\n  Group 1 (n): didn't participate\n"
+	if got := RenderMatch(result); got != want {
+		t.Errorf("RenderMatch() = %q, want %q", got, want)
+	}
+	if got := RenderMatch(MatchResult{Input: "x"}); got != "\"x\": no match\n" {
+		t.Errorf("RenderMatch(no match) = %q", got)
+	}
+}
+
+func TestMatchOverlapping(t *testing.T) {
+	tests := []struct {
+		pattern, input string
+		want           []string
+	}{
+		{`a\w`, "aaab", []string{"aa@0", "aa@1", "ab@2"}},
+		// Word boundaries and anchors see the text before each restart
+		{`\b\w+`, "ab cd", []string{"ab@0", "cd@3"}},
+		{`^a+`, "aaa", []string{"aaa@0"}},
+		{`(?m)^\w`, "a\nb", []string{"a@0", "b@2"}},
+		{`é.`, "ééé", []string{"éé@0", "éé@2"}},
+		{`x?`, "ab", []string{"@0", "@1", "@2"}},
+		{`z`, "ab", nil},
+		// \Q runs to the end of the pattern when it isn't closed
+		{`\Qa)`, "a)a)", []string{"a)@0", "a)@2"}},
+	}
+
+	for _, tt := range tests {
+		r, err := CompilePattern(tt.pattern, "go", "")
+		if err != nil {
+			t.Fatalf("CompilePattern(%q) returned error: %v", tt.pattern, err)
+		}
+		result := MatchOverlapping(r, tt.input)
+		var got []string
+		for _, spans := range result.Matches {
+			got = append(got, fmt.Sprintf("%s@%d", spans[0].Text, spans[0].Start))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MatchOverlapping(%q, %q) = %q, want %q", tt.pattern, tt.input, got, tt.want)
+		}
+		if result.Matched != (tt.want != nil) {
+			t.Errorf("MatchOverlapping(%q, %q).Matched = %v", tt.pattern, tt.input, result.Matched)
+		}
+	}
+
+	// Capture groups keep their numbers and names
+	r, _ := CompilePattern(`(?P<d>\d)\d`, "go", "")
+	result := MatchOverlapping(r, "123")
+	want := "\"123\": 2 matches\n" +
+		"  Match 1: \"12\" bytes 0-2, runes 0-2\n    Group 1 (d): \"1\" bytes 0-1, runes 0-1\n" +
+		"  Match 2: \"23\" bytes 1-3, runes 1-3\n    Group 1 (d): \"2\" bytes 1-2, runes 1-2\n"
+	if got := RenderMatch(result); got != want {
+		t.Errorf("RenderMatch(overlapping) = %q, want %q", got, want)
+	}
+}
+
+func TestMatchAll_LinesAndColumns(t *testing.T) {
+	r, _ := CompilePattern(`\d+`, "go", "")
+	result := MatchAll(r, "a 1\né 22\n\n333")
+	var got []string
+	for _, spans := range result.Matches {
+		got = append(got, fmt.Sprintf("%s@%d:%d", spans[0].Text, spans[0].Line, spans[0].Column))
+	}
+	if want := []string{"1@1:3", "22@2:3", "333@4:1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchAll() = %q, want %q", got, want)
+	}
+
+	result.Source = "notes.txt"
+	result.Matches = result.Matches[:1]
+	want := "notes.txt: 1 match\n  Match 1: \"1\" bytes 2-3, runes 2-3, at notes.txt:1:3\n"
+	if got := RenderMatch(result); got != want {
+		t.Errorf("RenderMatch(file) = %q, want %q", got, want)
+	}
+
+	// Other multi-line inputs get the line and column without a file name
+	want = "\"a\\nb\": match\n  Match: \"b\" bytes 2-3, runes 2-3, line 2 column 1\n"
+	r, _ = CompilePattern(`b`, "go", "")
+	if got := RenderMatch(MatchInput(r, "a\nb")); got != want {
+		t.Errorf("RenderMatch(multi-line) = %q, want %q", got, want)
+	}
+}
+
+func TestRenderMatchLines(t *testing.T) {
+	SetColor(false)
+	defer SetColor(true)
+
+	input := "one\ntwo 2\nthree\nfour\nfive 5\nsix\nseven\neight 8\n"
+	r, _ := CompilePattern(`\d`, "go", "")
+	result := MatchAll(r, input)
+	result.Source = "n.txt"
+
+	tests := []struct {
+		before, after int
+		want          string
+	}{
+		{0, 0, "n.txt:2:5:two 2\n--\nn.txt:5:6:five 5\n--\nn.txt:8:7:eight 8\n"},
+		{1, 0, "n.txt-1-one\nn.txt:2:5:two 2\n--\nn.txt-4-four\nn.txt:5:6:five 5\n--\nn.txt-7-seven\nn.txt:8:7:eight 8\n"},
+		{0, 1, "n.txt:2:5:two 2\nn.txt-3-three\n--\nn.txt:5:6:five 5\nn.txt-6-six\n--\nn.txt:8:7:eight 8\n"},
+		{1, 1, "n.txt-1-one\nn.txt:2:5:two 2\nn.txt-3-three\nn.txt-4-four\nn.txt:5:6:five 5\nn.txt-6-six\nn.txt-7-seven\nn.txt:8:7:eight 8\n"},
+	}
+	for _, tt := range tests {
+		if got := RenderMatchLines(result, tt.before, tt.after); got != tt.want {
+			t.Errorf("RenderMatchLines(%d, %d) = %q, want %q", tt.before, tt.after, got, tt.want)
+		}
+	}
+
+	// Matches spanning lines mark every line they cover
+	r, _ = CompilePattern(`(?s)o\n.`, "go", "")
+	result = MatchAll(r, "no\nyes\nmaybe")
+	result.Source = "n.txt"
+	if got, want := RenderMatchLines(result, 0, 0), "n.txt:1:2:no\nn.txt:2:1:yes\n"; got != want {
+		t.Errorf("RenderMatchLines(multi-line match) = %q, want %q", got, want)
+	}
+}
+
+func TestRenderMatchLines_ColorsGroups(t *testing.T) {
+	SetColor(true)
+	r, _ := CompilePattern(`a(b)c`, "go", "")
+	result := MatchAll(r, "xabcx")
+	result.Source = "f"
+
+	want := "f:1:2:x" + colorBold + "a" + colorReset + colorBold + tokenColors[0] + "b" + colorReset +
+		colorBold + "c" + colorReset + "x\n"
+	if got := RenderMatchLines(result, 0, 0); got != want {
+		t.Errorf("RenderMatchLines() = %q, want %q", got, want)
+	}
+}
+
+func TestMatchPatterns(t *testing.T) {
+	SetColor(false)
+	defer SetColor(true)
+
+	get, _ := CompilePattern(`GET`, "go", "")
+	status, _ := CompilePattern(`5\d\d`, "go", "")
+	input := "GET /a 200\nPOST /b 500\nGET /c 500\nPUT /d 404\n"
+
+	tests := []struct {
+		all  bool
+		want string
+	}{
+		{false, "1:1:GET /a 200\n2:9:POST /b 500\n3:1:GET /c 500\n"},
+		{true, "3:1:GET /c 500\n"},
+	}
+	for _, tt := range tests {
+		result := MatchPatterns([]*regexp.Regexp{get, status}, input, tt.all)
+		if got := RenderMatchLines(result, 0, 0); got != tt.want {
+			t.Errorf("MatchPatterns(all=%v) renders %q, want %q", tt.all, got, tt.want)
+		}
+	}
+
+	// Matches are in order on each line and know their pattern
+	result := MatchPatterns([]*regexp.Regexp{status, get}, input, true)
+	var got []string
+	for _, spans := range result.Matches {
+		got = append(got, fmt.Sprintf("%s#%d@%d:%d", spans[0].Text, spans[0].Pattern, spans[0].Line, spans[0].Column))
+	}
+	if want := []string{"GET#2@3:1", "500#1@3:8"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchPatterns() matches = %q, want %q", got, want)
+	}
+
+	if result := MatchPatterns([]*regexp.Regexp{get, status}, "PUT /d 404", false); result.Matched {
+		t.Errorf("MatchPatterns() = %+v, want no match", result)
+	}
+}
+
+func TestRenderMatchLines_ColorsPatterns(t *testing.T) {
+	SetColor(true)
+	a, _ := CompilePattern(`a(x)`, "go", "")
+	b, _ := CompilePattern(`b`, "go", "")
+	result := MatchPatterns([]*regexp.Regexp{a, b}, "ax b", false)
+
+	// Groups take the color of their pattern's match
+	want := "1:1:" + colorBold + tokenColors[0] + "ax" + colorReset + " " + colorBold + tokenColors[1] + "b" + colorReset + "\n"
+	if got := RenderMatchLines(result, 0, 0); got != want {
+		t.Errorf("RenderMatchLines() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// applyModifiers notes in each token's explanation how the modifiers in
+// effect at it, such as the i of (?i:foo), change what it matches, and
+// explains the comments of verbose mode. Flags given outside the pattern
+// apply throughout, standalone modifiers until the end of the enclosing
+// group and scoped ones until the end of their own.
+func applyModifiers(tokens []TokenExplanation, flags string) {
+	for i, current := range activeModifiers(tokens, flags) {
+		token := tokens[i].Token
+		if _, ok := format.ParseModifiers(token); ok {
+			continue
+		}
+
+		if strings.ContainsRune(current, 'x') && strings.HasPrefix(token, "#") {
+			tokens[i].Explanation = explainComment(token)
+			continue
+		}
+
+		if mode, ok := modeExplanations[token]; ok && strings.ContainsRune(current, mode.flag) {
+			tokens[i].Explanation = mode.explanation
+			continue
+		}
+		tokens[i].Explanation += modifierNote(token, current)
+	}
+}
+
+// modeExplanations replace the explanations of the tokens whose meaning a
+// modifier changes rather than qualifies: the anchors under m and the dot
+// under s
+var modeExplanations = map[string]struct {
+	flag        rune
+	explanation string
+}{
+	"^": {'m', "Matches at the start of the input and right after each line break (m is on)"},
+	"$": {'m', "Matches at the end of the input and right before each line break (m is on)"},
+	".": {'s', "Matches any single character, including newlines (s is on)"},
+}
+
+// activeModifiers returns the modifier letters in effect at each token,
+// starting from flags given outside the pattern
+func activeModifiers(tokens []TokenExplanation, flags string) []string {
+	// The modifier letters in effect in each open group, outermost first
+	active := []string{flags}
+	result := make([]string, len(tokens))
+
+	for i := range tokens {
+		token := tokens[i].Token
+		current := active[len(active)-1]
+		result[i] = current
+
+		if modifiers, ok := format.ParseModifiers(token); ok {
+			current = withModifiers(current, modifiers.On, modifiers.Off)
+			if modifiers.Scoped {
+				active = append(active, current)
+			} else {
+				active[len(active)-1] = current
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(token, "/") && TokenCategory(token) == CategoryFlags, isDelimiterFlags(token):
+			// JavaScript flags and the modifiers of a wrapped PCRE pattern
+			// come first and apply to the whole pattern
+			for _, c := range token[1:] {
+				if strings.ContainsRune("imsxUD", c) {
+					active[0] = withModifiers(active[0], string(c), "")
+				}
+			}
+		case token == ")":
+			if len(active) > 1 {
+				active = active[:len(active)-1]
+			}
+		case opensGroup(token):
+			active = append(active, current)
+		}
+	}
+	return result
+}
+
+// withModifiers turns the modifier letters in on on and those in off off
+func withModifiers(flags, on, off string) string {
+	var result strings.Builder
+	for _, c := range flags {
+		if !strings.ContainsRune(off, c) {
+			result.WriteRune(c)
+		}
+	}
+	for _, c := range on {
+		if !strings.ContainsRune(result.String(), c) {
+			result.WriteRune(c)
+		}
+	}
+	return result.String()
+}
+
+// modifierNote describes how the active modifier letters change what a
+// token matches, or returns "" when they don't affect it
+func modifierNote(token, flags string) string {
+	category := TokenCategory(token)
+	switch {
+	case strings.ContainsRune(flags, 'i') && (category == CategoryLiteral || category == CategoryClass) &&
+		strings.ToUpper(token) != strings.ToLower(token):
+		return ", case-insensitively (i is on)"
+	case strings.ContainsRune(flags, 'U') && category == CategoryQuantifier &&
+		!(len(token) > 1 && strings.HasSuffix(token, "+")):
+		if len(token) > 1 && strings.HasSuffix(token, "?") {
+			return ", greedily (U is on)"
+		}
+		return ", lazily (U is on)"
+	}
+	return ""
+}
+
+// explainComment explains a # comment of verbose mode
+func explainComment(token string) string {
+	text := strings.TrimSpace(strings.TrimPrefix(token, "#"))
+	if text == "" {
+		return "An empty comment, ignored in verbose mode"
+	}
+	return fmt.Sprintf("Comment, ignored in verbose mode: %s", text)
+}
+
+// compactVerbose returns the pattern and tokens of an explanation without
+// the delimiters of a wrapped PCRE pattern and the comments and x flag of
+// verbose mode, which Go's regexp, used to generate and check samples,
+// doesn't support. Removed tokens become empty so the tokens still line up
+// with the explanation's, and the modifiers after the delimiters are added
+// to the flags returned.
+func compactVerbose(exp *Explanation) (string, []string, string) {
+	pattern := exp.Pattern
+	if body, _, ok := format.PcreDelimited(pattern); ok && exp.FormatName == "pcre" {
+		pattern = body
+	}
+
+	tokens := make([]string, len(exp.Tokens))
+	flags := exp.Flags
+	verbose := strings.ContainsRune(exp.Flags, 'x')
+	for i, token := range exp.Tokens {
+		tokens[i] = token.Token
+		if modifiers, ok := format.ParseModifiers(token.Token); ok && !modifiers.Scoped && strings.ContainsRune(modifiers.On, 'x') {
+			verbose = true
+		}
+		if isDelimiterFlags(token.Token) && exp.FormatName == "pcre" {
+			verbose = verbose || strings.ContainsRune(token.Token, 'x')
+			modifiers, _ := format.DelimiterModifiers(token.Token)
+			flags = withModifiers(flags, modifiers, "")
+			tokens[i] = ""
+		}
+	}
+	if !verbose {
+		return pattern, tokens, flags
+	}
+
+	for i, token := range tokens {
+		if strings.HasPrefix(token, "#") {
+			tokens[i] = ""
+			continue
+		}
+		modifiers, ok := format.ParseModifiers(token)
+		if !ok || !strings.ContainsRune(modifiers.On, 'x') {
+			continue
+		}
+		on := strings.ReplaceAll(modifiers.On, "x", "")
+		switch {
+		case modifiers.Scoped:
+			tokens[i] = "(?" + on + "-" + modifiers.Off + ":"
+		case on == "" && modifiers.Off == "":
+			tokens[i] = ""
+		default:
+			tokens[i] = "(?" + on + "-" + modifiers.Off + ")"
+		}
+		tokens[i] = strings.Replace(tokens[i], "-:", ":", 1)
+		tokens[i] = strings.Replace(tokens[i], "-)", ")", 1)
+	}
+	return strings.Join(tokens, ""), tokens, flags
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyze_InlineModifiers(t *testing.T) {
+	tests := []struct {
+		pattern string
+		format  string
+		token   int
+		want    string
+	}{
+		{"(?i:ab)c", "pcre", 1, "case-insensitively (i is on)"},
+		{"(?i:ab)c", "pcre", 3, ""},
+		{"a(?s).", "go", 2, "including newlines (s is on)"},
+		{"(?s:(?-s:.).)", "pcre", 2, ""},
+		{"(?s:(?-s:.).)", "pcre", 4, "including newlines (s is on)"},
+		{"(?m)^a$", "go", 1, "right after each line break (m is on)"},
+		{"(?m)^a$", "go", 3, "right before each line break (m is on)"},
+		{"^a$", "go", 0, "only at the start of the input"},
+		{"^a$", "go", 2, "only at the end of the input"},
+		{"^a$", "python", 2, "just before a newline that ends it"},
+		{"(?U)a+", "go", 2, "lazily (U is on)"},
+		{"/abc/i", "js", 1, "case-insensitively (i is on)"},
+		{"m{^abc}im", "pcre", 1, "right after each line break (m is on)"},
+		{"m{^abc}im", "pcre", 2, "case-insensitively (i is on)"},
+		{"#a # note\n#x", "pcre", 2, "Comment, ignored in verbose mode: note"},
+	}
+
+	for _, tt := range tests {
+		exp := Analyze(tt.pattern, tt.format)
+		got := exp.Tokens[tt.token].Explanation
+		if (tt.want == "" && strings.Contains(got, " is on)")) || !strings.Contains(got, tt.want) {
+			t.Errorf("Analyze(%q) token %q = %q, want it to note %q", tt.pattern, exp.Tokens[tt.token].Token, got, tt.want)
+		}
+	}
+}
+
+func TestModifierNote(t *testing.T) {
+	tests := []struct {
+		token string
+		flags string
+		want  string
+	}{
+		{"+?", "U", ", greedily (U is on)"},
+		{"++", "U", ""},
+		{"[a-z]", "i", ", case-insensitively (i is on)"},
+		{"123", "i", ""},
+		{".", "m", ""},
+	}
+
+	for _, tt := range tests {
+		if got := modifierNote(tt.token, tt.flags); got != tt.want {
+			t.Errorf("modifierNote(%q, %q) = %q, want %q", tt.token, tt.flags, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeWithFlags_Verbose(t *testing.T) {
+	exp := AnalyzeWithFlags("\\d+  # digits\n-?", "python", "x")
+	want := []TokenExplanation{
+		{`\d`, "Matches any decimal digit (0-9)"},
+		{"+", "Matches 1 or more of the preceding element (greedy)"},
+		{"# digits", "Comment, ignored in verbose mode: digits"},
+		{"-", "Matches the character '-' literally"},
+		{"?", "Matches 0 or 1 of the preceding element (greedy)"},
+	}
+	if len(exp.Tokens) != len(want) {
+		t.Fatalf("AnalyzeWithFlags() tokens = %q, want %q", exp.Tokens, want)
+	}
+	for i := range want {
+		if exp.Tokens[i] != want[i] {
+			t.Errorf("token %d = %q, want %q", i, exp.Tokens[i], want[i])
+		}
+	}
+	if exp.SampleStatus != "Verified match" && exp.SampleStatus != "Verified match (using alternative)" {
+		t.Errorf("AnalyzeWithFlags() sample = %q (%s), want a verified match", exp.Sample, exp.SampleStatus)
+	}
+}
+
+func TestCompactVerbose(t *testing.T) {
+	tests := []struct {
+		pattern string
+		format  string
+		flags   string
+		want    string
+	}{
+		{"(?x) a b # c", "python", "", "ab"},
+		{"(?ix) a", "python", "", "(?i)a"},
+		{"a # b", "python", "x", "a"},
+		{"a # b", "python", "", "a # b"},
+		{"qr/ a b # c\n/x", "pcre", "", "ab"},
+		{"#a b#i", "pcre", "", "a b"},
+	}
+
+	for _, tt := range tests {
+		if got, _, _ := compactVerbose(AnalyzeWithFlags(tt.pattern, tt.format, tt.flags)); got != tt.want {
+			t.Errorf("compactVerbose(%q, %q, %q) = %q, want %q", tt.pattern, tt.format, tt.flags, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxNearMisses caps the near misses generated for a pattern
+const maxNearMisses = 8
+
+// nearMissReplacements are the characters tried, in order, in place of the
+// character a token contributed to the sample
+const nearMissReplacements = "x7!A -_."
+
+// NearMiss is a string that almost matches a pattern, differing from the
+// example match only where one token matched, so it fails because of that
+// token. Index is the token's position in the explanation's tokens.
+type NearMiss struct {
+	Text   string `json:"text"`
+	Index  int    `json:"index"`
+	Token  string `json:"token"`
+	Reason string `json:"reason"`
+}
+
+// nearMisses generates strings that don't match a pattern, each breaking
+// the example match at one token: a character the token can't match, one
+// repetition too few or too many for a quantifier, or text outside an
+// anchor. It returns nil when the example match can't be verified, as for
+// patterns with backreferences.
+func nearMisses(pattern, formatName, flags string, tokens []string, opts ExampleOptions) []NearMiss {
+	sample, tokenMap, _ := generateDeterministicSample(tokens, formatName, flags, -1, opts)
+	r, err := CompilePattern(pattern, formatName, flags)
+	if err != nil || !r.MatchString(sample) {
+		return nil
+	}
+
+	explained := make([]TokenExplanation, len(tokens))
+	for i, token := range tokens {
+		explained[i].Token = token
+	}
+	active := activeModifiers(explained, flags)
+
+	var misses []NearMiss
+	seen := make(map[string]bool)
+	add := func(i int, text, reason string) bool {
+		if len(misses) >= maxNearMisses || seen[text] || r.MatchString(text) {
+			return false
+		}
+		seen[text] = true
+		misses = append(misses, NearMiss{Text: text, Index: i, Token: tokens[i], Reason: reason})
+		return true
+	}
+
+	for i, token := range tokens {
+		span := tokenMap[i]
+		switch {
+		case token == "^" && !strings.ContainsRune(active[i], 'm'):
+			add(i, "x"+sample, "text before the start")
+		case token == "$" && !strings.ContainsRune(active[i], 'm'):
+			add(i, sample+"x", "text after the end")
+		case isQuantifierToken(token):
+			nearMissRepetitions(i, tokens, tokenMap, sample, add)
+		case span.end > span.start && isNearMissElement(token):
+			// Replace the first character the token matched with one it
+			// can't match
+			single, err := CompilePattern("^(?:"+token+")$", formatName, active[i])
+			if err != nil {
+				continue
+			}
+			_, size := utf8.DecodeRuneInString(sample[span.start:])
+			for _, c := range nearMissReplacements {
+				text := sample[:span.start] + string(c) + sample[span.start+size:]
+				if !single.MatchString(string(c)) && add(i, text, fmt.Sprintf("%q doesn't match it", c)) {
+					break
+				}
+			}
+		}
+	}
+	return misses
+}
+
+// isNearMissElement reports whether a token matches a character, so a near
+// miss can replace it with one the token can't match
+func isNearMissElement(token string) bool {
+	switch TokenCategory(token) {
+	case CategoryClass, CategoryAny, CategoryEscape, CategoryLiteral:
+		return true
+	}
+	return false
+}
+
+// nearMissRepetitions adds near misses for the quantifier at i that repeat
+// its element one time fewer than it allows at least, and one time more
+// than it allows at most
+func nearMissRepetitions(i int, tokens []string, tokenMap []Position, sample string, add func(int, string, string) bool) {
+	least, most, ok := quantifierBounds(tokens[i])
+	if !ok || i == 0 {
+		return
+	}
+
+	// The element starts where the token, or the group it closes, does
+	start := i - 1
+	for depth := 0; start >= 0; start-- {
+		if tokens[start] == ")" {
+			depth++
+		} else if opensGroup(tokens[start]) {
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	if start < 0 {
+		return
+	}
+	from, to := tokenMap[start].start, tokenMap[i].end
+	unit := tokenMap[i].start - from
+	if unit <= 0 || to > len(sample) || to-from < unit || (to-from)%unit != 0 {
+		return
+	}
+	element := sample[from : from+unit]
+
+	if least > 0 {
+		text := sample[:from] + strings.Repeat(element, least-1) + sample[to:]
+		add(i, text, fmt.Sprintf("one repetition short of the %d needed", least))
+	}
+	if most >= 0 {
+		text := sample[:from] + strings.Repeat(element, most+1) + sample[to:]
+		add(i, text, fmt.Sprintf("one repetition more than the %d allowed", most))
+	}
+}
+
+// nearMissTable lists the near misses of an explanation with the token
+// each one fails at. It returns "" when there are none.
+func nearMissTable(exp *Explanation, colorMap []string) string {
+	if len(exp.NearMisses) == 0 {
+		return ""
+	}
+	var result strings.Builder
+	fmt.Fprintf(&result, "%sNear misses:%s\n", colorBold, colorReset)
+	for _, miss := range exp.NearMisses {
+		color := colorMap[miss.Index%len(colorMap)]
+		fmt.Fprintf(&result, "  %q fails at %s%s%d. %s%s: %s\n",
+			miss.Text, color, colorBold, miss.Index+1, miss.Token, colorReset, miss.Reason)
+	}
+	return result.String()
+}
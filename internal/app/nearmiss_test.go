@@ -0,0 +1,53 @@
+package app
+
+import "testing"
+
+func TestAnalyze_NearMisses(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []NearMiss
+	}{
+		{`^\d{2}-a$`, []NearMiss{
+			{"x55-a", 0, "^", "text before the start"},
+			{"x5-a", 1, `\d`, `'x' doesn't match it`},
+			{"5-a", 2, "{2}", "one repetition short of the 2 needed"},
+			{"555-a", 2, "{2}", "one repetition more than the 2 allowed"},
+			{"55xa", 3, "-a", `'x' doesn't match it`},
+			{"55-ax", 4, "$", "text after the end"},
+		}},
+		{`(ab)+`, []NearMiss{
+			{"", 3, "+", "one repetition short of the 1 needed"},
+		}},
+		{`(\w+)-\1`, nil},
+	}
+
+	for _, tt := range tests {
+		got := Analyze(tt.pattern, "go").NearMisses
+		if len(got) != len(tt.want) {
+			t.Errorf("Analyze(%q).NearMisses = %v, want %v", tt.pattern, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Analyze(%q).NearMisses[%d] = %v, want %v", tt.pattern, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestNearMissTable(t *testing.T) {
+	SetColor(false)
+	defer SetColor(true)
+
+	got := nearMissTable(Analyze(`^a$`, "go"), []string{""})
+	want := "Near misses:\n" +
+		"  \"xa\" fails at 1. ^: text before the start\n" +
+		"  \"x\" fails at 2. a: 'x' doesn't match it\n" +
+		"  \"ax\" fails at 3. $: text after the end\n"
+	if got != want {
+		t.Errorf("nearMissTable(%q) = %q, want %q", `^a$`, got, want)
+	}
+	if got := nearMissTable(Analyze(`(\w+)-\1`, "go"), []string{""}); got != "" {
+		t.Errorf("nearMissTable(%q) = %q, want none", `(\w+)-\1`, got)
+	}
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lineEndingTokens are the tokens the line ending table describes, in its
+// order
+var lineEndingTokens = []string{".", "^", "$", `\n`, `\R`}
+
+// lineEndingSemantics describes how a token treats \n, \r\n and the other
+// line breaks in a flavor, with the modifier letters in effect at it. It
+// returns "" for tokens whose behavior doesn't depend on line endings there.
+func lineEndingSemantics(token, formatName, flags string) string {
+	multiline := strings.ContainsRune(flags, 'm')
+	switch token {
+	case ".":
+		switch {
+		case formatName == "posix" && !multiline:
+			return "matches any character, \\n and \\r included"
+		case formatName == "posix":
+			return "matches any character but \\n, so it also matches the \\r of a \\r\\n (m is on)"
+		case strings.ContainsRune(flags, 's'):
+			return "matches any character, \\n and \\r included (s is on)"
+		case formatName == "js":
+			return "matches any character but \\n, \\r, U+2028 and U+2029, so it stops before a \\r\\n"
+		}
+		return "matches any character but \\n, so it also matches the \\r of a \\r\\n"
+	case "^":
+		switch {
+		case !multiline:
+			return ""
+		case formatName == "js":
+			return "matches after every \\n and every \\r, so also between the \\r and \\n of a \\r\\n (m is on)"
+		}
+		return "matches after every \\n, so a line starts after the whole \\r\\n (m is on)"
+	case "$":
+		switch {
+		case multiline && formatName == "js":
+			return "matches before every \\n and every \\r, so before the \\r of a \\r\\n (m is on)"
+		case multiline:
+			return "matches before every \\n only, so on \\r\\n input the \\r is left before it (m is on)"
+		case formatName == "pcre" && !strings.ContainsRune(flags, 'D'), formatName == "python":
+			return "skips a final \\n but not a final \\r\\n, so abc$ matches \"abc\\n\" but not \"abc\\r\\n\""
+		}
+		return "matches only at the end, so a trailing \\n or \\r\\n has to be matched or trimmed"
+	case `\n`:
+		return "matches the \\n alone, so the \\r of a \\r\\n needs \\r? before it"
+	case `\R`:
+		if formatName == "pcre" {
+			return "matches any line break, taking \\r\\n as one, as well as a lone \\n or \\r"
+		}
+	}
+	return ""
+}
+
+// lineEndingTable describes how ., the anchors and line break escapes in the
+// pattern treat \n and \r\n with the modifiers in effect, as input with
+// Windows line endings trips up patterns written for \n. It returns "" when
+// the pattern has none of them.
+func lineEndingTable(exp *Explanation) string {
+	seen := make(map[string]bool)
+	var rows []string
+	active := activeModifiers(exp.Tokens, exp.Flags)
+	for _, name := range lineEndingTokens {
+		for i, token := range exp.Tokens {
+			if token.Token != name {
+				continue
+			}
+			row := lineEndingSemantics(name, exp.FormatName, active[i])
+			if row == "" || seen[name+row] {
+				continue
+			}
+			seen[name+row] = true
+			rows = append(rows, fmt.Sprintf("  %s%-3s%s %s\n", colorBold, name, colorReset, row))
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%sLine endings:%s\n", colorBold, colorReset) + strings.Join(rows, "")
+}
+
+// lintLineEndings warns about tokens that behave differently on input with
+// Windows line endings: a \n that doesn't allow for the \r before it, and a
+// multi-line $ that only matches before the \n of a \r\n
+func lintLineEndings(pattern, formatName string) []Finding {
+	exp := Analyze(pattern, formatName)
+	active := activeModifiers(exp.Tokens, "")
+
+	var findings []Finding
+	pos := 0
+	for i, token := range exp.Tokens {
+		category := TokenCategory(token.Token)
+		if category == CategoryFlags || isDelimiterFlags(token.Token) {
+			continue
+		}
+		tokenPos := strings.Index(pattern[pos:], token.Token)
+		if tokenPos == -1 {
+			continue
+		}
+		tokenPos += pos
+		pos = tokenPos + len(token.Token)
+
+		switch {
+		case token.Token == `\n` && !matchesCarriageReturn(exp.Tokens[:i]):
+			fix := "use \\r?\\n"
+			if formatName == "pcre" {
+				fix += " or \\R"
+			}
+			findings = append(findings, Finding{SeverityWarning, tokenPos,
+				fmt.Sprintf("\\n doesn't match the \\r of Windows line endings (\\r\\n); %s", fix)})
+		case token.Token == "$" && strings.ContainsRune(active[i], 'm') && formatName != "js" &&
+			!matchesCarriageReturn(exp.Tokens[:i]):
+			findings = append(findings, Finding{SeverityWarning, tokenPos,
+				"multi-line $ matches only before \\n, so with Windows line endings (\\r\\n) the \\r is left before it; match \\r? first"})
+		}
+	}
+	return findings
+}
+
+// matchesCarriageReturn reports whether the last of the tokens, ignoring a
+// quantifier, can match the \r of a \r\n
+func matchesCarriageReturn(tokens []TokenExplanation) bool {
+	last := len(tokens) - 1
+	if last >= 0 && isQuantifierToken(tokens[last].Token) {
+		last--
+	}
+	if last < 0 {
+		return false
+	}
+	switch token := tokens[last].Token; token {
+	case `\r`, `\s`, `\R`, `\v`, `\x0d`, `\x0D`:
+		return true
+	default:
+		return strings.HasPrefix(token, "[") && !strings.HasPrefix(token, "[^") &&
+			(strings.Contains(token, `\r`) || strings.Contains(token, `\s`))
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineEndingSemantics(t *testing.T) {
+	tests := []struct {
+		token      string
+		formatName string
+		flags      string
+		want       string
+	}{
+		{".", "go", "", "matches any character but \\n, so it also matches the \\r of a \\r\\n"},
+		{".", "js", "", "matches any character but \\n, \\r, U+2028 and U+2029, so it stops before a \\r\\n"},
+		{".", "python", "s", "matches any character, \\n and \\r included (s is on)"},
+		{".", "posix", "", "matches any character, \\n and \\r included"},
+		{"^", "go", "", ""},
+		{"$", "pcre", "", "skips a final \\n but not a final \\r\\n, so abc$ matches \"abc\\n\" but not \"abc\\r\\n\""},
+		{"$", "pcre", "m", "matches before every \\n only, so on \\r\\n input the \\r is left before it (m is on)"},
+		{"$", "js", "m", "matches before every \\n and every \\r, so before the \\r of a \\r\\n (m is on)"},
+		{"$", "go", "", "matches only at the end, so a trailing \\n or \\r\\n has to be matched or trimmed"},
+		{`\R`, "pcre", "", "matches any line break, taking \\r\\n as one, as well as a lone \\n or \\r"},
+		{`\R`, "go", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := lineEndingSemantics(tt.token, tt.formatName, tt.flags); got != tt.want {
+			t.Errorf("lineEndingSemantics(%q, %q, %q) = %q, want %q", tt.token, tt.formatName, tt.flags, got, tt.want)
+		}
+	}
+}
+
+func TestLineEndingTable(t *testing.T) {
+	SetColor(false)
+	defer SetColor(true)
+
+	got := lineEndingTable(Analyze(`(?m)^.+$\R`, "pcre"))
+	for _, want := range []string{
+		"Line endings:",
+		".   matches any character but \\n",
+		"^   matches after every \\n, so a line starts after the whole \\r\\n (m is on)",
+		"$   matches before every \\n only",
+		"\\R  matches any line break",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("lineEndingTable() should contain %q, got:\n%s", want, got)
+		}
+	}
+
+	if got := lineEndingTable(Analyze("abc", "go")); got != "" {
+		t.Errorf("lineEndingTable(%q) = %q, want no table", "abc", got)
+	}
+}
+
+func TestLintLineEndings(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		formatName string
+		offsets    []int
+	}{
+		{`a\nb`, "go", []int{1}},
+		{`a\r\nb`, "go", nil},
+		{`a\r?\nb`, "python", nil},
+		{`a[\r\n]+b`, "pcre", nil},
+		{`(?m)^\w+$`, "pcre", []int{8}},
+		{`(?m)^\w+\r?$`, "go", nil},
+		{`/^\w+$/m`, "js", nil},
+		{`^\w+$`, "python", nil},
+	}
+
+	for _, tt := range tests {
+		findings := lintLineEndings(tt.pattern, tt.formatName)
+		var offsets []int
+		for _, f := range findings {
+			offsets = append(offsets, f.Offset)
+		}
+		if len(offsets) != len(tt.offsets) || (len(offsets) > 0 && offsets[0] != tt.offsets[0]) {
+			t.Errorf("lintLineEndings(%q, %q) = %v, want warnings at %v", tt.pattern, tt.formatName, findings, tt.offsets)
+		}
+	}
+
+	findings := lintLineEndings(`a\nb`, "pcre")
+	if len(findings) != 1 || !strings.Contains(findings[0].Message, "use \\r?\\n or \\R") {
+		t.Errorf("lintLineEndings() = %v, want \\R suggested for PCRE", findings)
+	}
+}
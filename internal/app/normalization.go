@@ -0,0 +1,71 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// lintNormalization warns about characters whose NFC and NFD spellings
+// differ, such as é as one precomposed code point or as e followed by a
+// combining accent. Regex engines compare code points, so a pattern in one
+// form doesn't match input in the other.
+func lintNormalization(pattern, formatName string) []Finding {
+	var findings []Finding
+	seen := make(map[string]bool)
+
+	for i := 0; i < len(pattern); {
+		// A character together with the combining marks that follow it
+		end := i + norm.NFC.NextBoundaryInString(pattern[i:], true)
+		if end <= i {
+			end = i + 1
+		}
+		char := pattern[i:end]
+		offset := i
+		i = end
+
+		if isASCII(char) || seen[char] {
+			continue
+		}
+		nfc, nfd := norm.NFC.String(char), norm.NFD.String(char)
+		if nfc == nfd {
+			continue
+		}
+		seen[char] = true
+
+		form, patternForm, inputForm := "precomposed", "NFC", "NFD"
+		if char == nfd {
+			form, patternForm, inputForm = "decomposed", "NFD", "NFC"
+		}
+		alternative := fmt.Sprintf("match both forms with (?:%s|%s)", nfc, nfd)
+		if formatName == "posix" {
+			base, _ := utf8.DecodeRuneInString(nfd)
+			alternative = fmt.Sprintf("use the equivalence class [[=%c=]], which also matches other accented forms", base)
+		}
+		findings = append(findings, Finding{SeverityWarning, offset,
+			fmt.Sprintf("'%s' is %s (NFC: %s, NFD: %s), so %s input won't match; normalize input to %s or %s",
+				char, form, codePoints(nfc), codePoints(nfd), inputForm, patternForm, alternative)})
+	}
+	return findings
+}
+
+// codePoints lists the code points of s, like U+0065 U+0301
+func codePoints(s string) string {
+	var points []string
+	for _, r := range s {
+		points = append(points, fmt.Sprintf("%U", r))
+	}
+	return strings.Join(points, " ")
+}
+
+// isASCII reports whether s only holds ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
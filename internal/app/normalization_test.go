@@ -0,0 +1,35 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintNormalization(t *testing.T) {
+	precomposed := "caf" + string(rune(0xE9))
+	decomposed := "caf" + "e" + string(rune(0x301))
+
+	findings := Lint(precomposed+"|"+precomposed, "go")
+	if len(findings) != 1 {
+		t.Fatalf("Lint() = %v, want one warning for the repeated character", findings)
+	}
+	if f := findings[0]; f.Severity != SeverityWarning || f.Offset != 3 ||
+		!strings.Contains(f.Message, "is precomposed (NFC: U+00E9, NFD: U+0065 U+0301), so NFD input won't match") {
+		t.Errorf("Lint() = %+v, want a precomposed warning at offset 3", f)
+	}
+
+	findings = Lint(decomposed, "pcre")
+	if len(findings) != 1 || !strings.Contains(findings[0].Message, "is decomposed") ||
+		!strings.Contains(findings[0].Message, "normalize input to NFD") {
+		t.Errorf("Lint() = %v, want a decomposed warning", findings)
+	}
+
+	findings = Lint(precomposed, "posix")
+	if len(findings) != 1 || !strings.Contains(findings[0].Message, "[[=e=]]") {
+		t.Errorf("Lint() = %v, want the POSIX equivalence class suggested", findings)
+	}
+
+	if findings := Lint("naive|"+string(rune(0x4E2D)), "go"); len(findings) != 0 {
+		t.Errorf("Lint() = %v, want no warning for characters with a single form", findings)
+	}
+}
@@ -0,0 +1,328 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	goformat "go/format"
+	"math/rand"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Number of samples checked before a property test is emitted
+const propTestAttempts = 200
+
+// Maximum number of near-miss strings embedded in a property test
+const propTestNearMisses = 8
+
+// GeneratePropertyTest emits a self-contained Go test file that checks structural
+// invariants of the pattern with testing/quick: every generated sample must match,
+// and every embedded near-miss must not. The test only depends on the standard
+// library so it can be dropped into any package's test suite. The samples
+// it's checked with are generated at random from the seed.
+func GeneratePropertyTest(pattern, formatName, packageName string, seed int64) (string, error) {
+	// Property tests run against Go's regexp package, so the pattern has to
+	// be compatible with it regardless of the flavor it was written for
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		if formatName != "go" {
+			return "", fmt.Errorf("pattern is not compatible with Go's regexp package: %w", goSyntaxError(pattern, err))
+		}
+		return "", fmt.Errorf("invalid pattern: %w", goSyntaxError(pattern, err))
+	}
+
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern: %w", goSyntaxError(pattern, err))
+	}
+	parsed = parsed.Simplify()
+
+	// Make sure the generator used by the emitted test can actually satisfy the
+	// pattern; otherwise the test would fail for reasons unrelated to the regex
+	var samples []string
+	random := rand.New(rand.NewSource(seed))
+	for i := 0; i < propTestAttempts; i++ {
+		sample := generateFromSyntax(parsed, random, 10)
+		if !r.MatchString(sample) {
+			return "", fmt.Errorf("pattern contains assertions the sample generator cannot satisfy (generated %q)", sample)
+		}
+		samples = append(samples, sample)
+	}
+
+	if packageName == "" {
+		packageName = "main"
+	}
+
+	data := struct {
+		Pattern    string
+		Package    string
+		NearMisses []string
+	}{
+		Pattern:    strconv.Quote(pattern),
+		Package:    packageName,
+		NearMisses: generateNearMisses(r, samples, propTestNearMisses),
+	}
+
+	var buf bytes.Buffer
+	if err := propTestTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	source, err := goformat.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated test: %v", err)
+	}
+
+	return string(source), nil
+}
+
+// generateNearMisses derives strings that differ from a matching sample by a
+// single edit and are verified not to match the pattern anywhere
+func generateNearMisses(r *regexp.Regexp, samples []string, limit int) []string {
+	seen := make(map[string]bool)
+	var misses []string
+
+	add := func(candidate string) {
+		if len(misses) >= limit || seen[candidate] || r.MatchString(candidate) {
+			return
+		}
+		seen[candidate] = true
+		misses = append(misses, candidate)
+	}
+
+	for _, sample := range samples {
+		runes := []rune(sample)
+		for i := range runes {
+			// Drop one character
+			add(string(runes[:i]) + string(runes[i+1:]))
+
+			// Replace one character with something from a different class
+			replacement := '!'
+			if !strings.ContainsRune(digits, runes[i]) {
+				replacement = '0'
+			}
+			add(string(runes[:i]) + string(replacement) + string(runes[i+1:]))
+		}
+
+		// Truncate and extend the whole sample
+		if len(runes) > 0 {
+			add(string(runes[:len(runes)-1]))
+		}
+		add(sample + "!")
+
+		if len(misses) >= limit {
+			break
+		}
+	}
+
+	return misses
+}
+
+// generateFromSyntax builds a string matching the parsed regular expression.
+// It mirrors the generator embedded in emitted property tests.
+func generateFromSyntax(re *syntax.Regexp, r *rand.Rand, size int) string {
+	var b strings.Builder
+	writeFromSyntax(&b, re, r, size, nil)
+	return b.String()
+}
+
+// writeFromSyntax walks the syntax tree and appends a matching string to b.
+// With a picker, repeated classes get a word from the realistic word lists
+// when one fits.
+func writeFromSyntax(b *strings.Builder, re *syntax.Regexp, r *rand.Rand, size int, words *realisticPicker) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(pickFromRanges(re.Rune, r))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte(byte(' ' + r.Intn('~'-' '+1)))
+	case syntax.OpCapture:
+		writeFromSyntax(b, re.Sub[0], r, size, words)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writeFromSyntax(b, sub, r, size, words)
+		}
+	case syntax.OpAlternate:
+		writeFromSyntax(b, re.Sub[r.Intn(len(re.Sub))], r, size, words)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, -1
+		case syntax.OpPlus:
+			min, max = 1, -1
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if words != nil && isRepeatedClass(re) {
+			afterDot := strings.HasSuffix(b.String(), ".")
+			if word, ok := words.pick(re.Sub[0].String(), "go", "", min, max, afterDot); ok {
+				b.WriteString(word)
+				return
+			}
+		}
+		if max < 0 || max > min+size {
+			max = min + size
+		}
+		count := min + r.Intn(max-min+1)
+		for i := 0; i < count; i++ {
+			writeFromSyntax(b, re.Sub[0], r, size, words)
+		}
+	}
+	// Anchors, boundaries and empty matches don't contribute characters
+}
+
+// pickFromRanges picks a rune from a syntax character class, preferring
+// printable ASCII members so generated samples stay readable
+func pickFromRanges(ranges []rune, r *rand.Rand) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < ' ' {
+			lo = ' '
+		}
+		if hi > '~' {
+			hi = '~'
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	if len(ranges) == 0 {
+		return 'x'
+	}
+
+	pair := r.Intn(len(ranges)/2) * 2
+	lo, hi := ranges[pair], ranges[pair+1]
+	return lo + rune(r.Intn(int(hi-lo)+1))
+}
+
+// propTestTemplate is the source of the emitted property test
+var propTestTemplate = template.Must(template.New("proptest").Parse(`// Code generated by unregex -proptest; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"math/rand"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// unregexPattern is the regular expression under test
+const unregexPattern = {{.Pattern}}
+
+// unregexNearMisses differ from a matching string by a single edit and must not match
+var unregexNearMisses = []string{
+{{- range .NearMisses}}
+	{{printf "%q" .}},
+{{- end}}
+}
+
+// unregexSample is a string generated from the structure of unregexPattern
+type unregexSample string
+
+// Generate implements quick.Generator
+func (unregexSample) Generate(r *rand.Rand, size int) reflect.Value {
+	re, err := syntax.Parse(unregexPattern, syntax.Perl)
+	if err != nil {
+		panic(err)
+	}
+	if size > 10 {
+		size = 10
+	}
+	var b strings.Builder
+	unregexWrite(&b, re.Simplify(), r, size)
+	return reflect.ValueOf(unregexSample(b.String()))
+}
+
+func unregexWrite(b *strings.Builder, re *syntax.Regexp, r *rand.Rand, size int) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(unregexPick(re.Rune, r))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte(byte(' ' + r.Intn('~'-' '+1)))
+	case syntax.OpCapture:
+		unregexWrite(b, re.Sub[0], r, size)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			unregexWrite(b, sub, r, size)
+		}
+	case syntax.OpAlternate:
+		unregexWrite(b, re.Sub[r.Intn(len(re.Sub))], r, size)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, -1
+		case syntax.OpPlus:
+			min, max = 1, -1
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 || max > min+size {
+			max = min + size
+		}
+		count := min + r.Intn(max-min+1)
+		for i := 0; i < count; i++ {
+			unregexWrite(b, re.Sub[0], r, size)
+		}
+	}
+}
+
+func unregexPick(ranges []rune, r *rand.Rand) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < ' ' {
+			lo = ' '
+		}
+		if hi > '~' {
+			hi = '~'
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	if len(ranges) == 0 {
+		return 'x'
+	}
+	pair := r.Intn(len(ranges)/2) * 2
+	lo, hi := ranges[pair], ranges[pair+1]
+	return lo + rune(r.Intn(int(hi-lo)+1))
+}
+
+func TestUnregexPatternProperties(t *testing.T) {
+	re := regexp.MustCompile(unregexPattern)
+
+	// Every generated sample must match the pattern
+	matches := func(s unregexSample) bool {
+		return re.MatchString(string(s))
+	}
+	if err := quick.Check(matches, nil); err != nil {
+		t.Errorf("generated sample does not match %s: %v", unregexPattern, err)
+	}
+
+	// No near-miss may match the pattern
+	for _, s := range unregexNearMisses {
+		if re.MatchString(s) {
+			t.Errorf("near-miss %q unexpectedly matches %s", s, unregexPattern)
+		}
+	}
+}
+`))
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"go/parser"
+	"go/token"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"testing"
+)
+
+func TestGeneratePropertyTest(t *testing.T) {
+	source, err := GeneratePropertyTest(`^[a-z]+@(foo|bar)\.com$`, "go", "patterns", 1)
+	if err != nil {
+		t.Fatalf("GeneratePropertyTest() error = %v", err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "pattern_test.go", source, 0)
+	if err != nil {
+		t.Fatalf("generated source does not parse: %v", err)
+	}
+	if file.Name.Name != "patterns" {
+		t.Errorf("generated package = %q, want %q", file.Name.Name, "patterns")
+	}
+	if !strings.Contains(source, "func TestUnregexPatternProperties(t *testing.T)") {
+		t.Error("generated source should contain the property test function")
+	}
+}
+
+func TestGeneratePropertyTest_Compiles(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go command isn't available")
+	}
+	source, err := GeneratePropertyTest(`^[a-z]+@\d{2}$`, "go", "foo", 1)
+	if err != nil {
+		t.Fatalf("GeneratePropertyTest() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{"go.mod": "module foo\n\ngo 1.21\n", "pattern_test.go": source}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(goTool, "vet", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOTOOLCHAIN=local", "GOFLAGS=")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("go vet on the generated test: %v\n%s", err, output)
+	}
+}
+
+func TestGeneratePropertyTest_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		format  string
+	}{
+		{"Invalid pattern", "(abc", "go"},
+		{"Unsupported by Go regexp", "(?<=a)b", "pcre"},
+		{"Unsatisfiable word boundary", `a\bb`, "go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := GeneratePropertyTest(tt.pattern, tt.format, "main", 1); err == nil {
+				t.Errorf("GeneratePropertyTest(%q) should return an error", tt.pattern)
+			}
+		})
+	}
+}
+
+func TestGenerateNearMisses(t *testing.T) {
+	r := regexp.MustCompile(`^\d{3}$`)
+	misses := generateNearMisses(r, []string{"123"}, 5)
+
+	if len(misses) == 0 {
+		t.Fatal("generateNearMisses() returned no near-misses")
+	}
+	for _, miss := range misses {
+		if r.MatchString(miss) {
+			t.Errorf("near-miss %q matches the pattern", miss)
+		}
+	}
+}
+
+func TestGenerateFromSyntax(t *testing.T) {
+	patterns := []string{`^(cat|dog)s?$`, `[A-Z]{2,4}-\d+`, `[^a-z]x.`}
+
+	for _, pattern := range patterns {
+		parsed, err := syntax.Parse(pattern, syntax.Perl)
+		if err != nil {
+			t.Fatalf("syntax.Parse(%q) error = %v", pattern, err)
+		}
+		r := regexp.MustCompile(pattern)
+		random := rand.New(rand.NewSource(1))
+		for i := 0; i < 50; i++ {
+			sample := generateFromSyntax(parsed.Simplify(), random, 5)
+			if !r.MatchString(sample) {
+				t.Errorf("generateFromSyntax(%q) = %q, which does not match", pattern, sample)
+			}
+		}
+	}
+}
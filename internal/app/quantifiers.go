@@ -0,0 +1,417 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// Quantifier modes
+const (
+	QuantifierGreedy     = "greedy"
+	QuantifierLazy       = "lazy"
+	QuantifierPossessive = "possessive"
+)
+
+// quantifierModes are the modes each quantifier is tried in
+var quantifierModes = []string{QuantifierGreedy, QuantifierLazy, QuantifierPossessive}
+
+// maxBacktrackSteps bounds how long trying a quantifier mode can take, as
+// backtracking can take exponential time on nested quantifiers
+const maxBacktrackSteps = 1000000
+
+// QuantifierTrial is what the pattern matches in an input with one of its
+// quantifiers in one mode. Start and End are rune offsets of the match.
+type QuantifierTrial struct {
+	Mode    string `json:"mode"`
+	Syntax  string `json:"syntax"`
+	Written bool   `json:"written"`
+	Matched bool   `json:"matched"`
+	Match   string `json:"match,omitempty"`
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+
+	// GaveUp is set when trying the mode took too many steps to finish
+	GaveUp bool `json:"gaveUp,omitempty"`
+}
+
+// QuantifierAdvice compares the modes of one quantifier in a pattern
+type QuantifierAdvice struct {
+	// Token is the number of the quantifier's token, and Quantified the
+	// element it repeats with the quantifier, such as .*
+	Token      int               `json:"token"`
+	Quantified string            `json:"quantified"`
+	Trials     []QuantifierTrial `json:"trials"`
+}
+
+// AdviseQuantifiers tries each quantifier of an explained pattern as
+// greedy, lazy and possessive on an input, keeping the others as written,
+// to show what each mode would match. With no input, the example match is
+// used twice over, as a quantifier's mode only matters when it can match
+// more than one way. Patterns are matched the way Go's regexp package
+// reads them, as PCRE would if it supported the pattern.
+func AdviseQuantifiers(exp *Explanation, input string) ([]QuantifierAdvice, string, error) {
+	if input == "" {
+		input = exp.Sample + exp.Sample
+	}
+
+	parsed, quantifiers, written, err := parseBacktracking(exp)
+	if err != nil {
+		return nil, input, err
+	}
+	if len(quantifiers) == 0 {
+		return nil, input, errors.New("pattern has no quantifiers")
+	}
+	var nodes []*syntax.Regexp
+	collectQuantifiers(parsed, &nodes)
+	if written == nil {
+		return nil, input, errors.New("the pattern's quantifiers can't be lined up with its tokens")
+	}
+
+	tokens := make([]string, len(exp.Tokens))
+	for i, token := range exp.Tokens {
+		tokens[i] = token.Token
+	}
+	runes := []rune(input)
+	advice := make([]QuantifierAdvice, len(nodes))
+	for n, node := range nodes {
+		q := quantifiers[n]
+		operand := quantifiedOperand(tokens, q)
+		base := quantifierBase(tokens[q])
+		advice[n] = QuantifierAdvice{Token: q + 1, Quantified: operand + tokens[q]}
+
+		for _, mode := range quantifierModes {
+			trial := QuantifierTrial{Mode: mode, Syntax: operand + base + quantifierSuffix(mode), Written: mode == written[node]}
+			b := &backtracker{input: runes, written: written, target: node, mode: mode, limit: maxBacktrackSteps}
+			for start := 0; start <= len(runes) && !trial.Matched && !b.gaveUp; start++ {
+				b.match(parsed, start, func(end int) bool {
+					trial.Matched, trial.Start, trial.End = true, start, end
+					return true
+				})
+			}
+			trial.GaveUp = b.gaveUp
+			if trial.Matched {
+				trial.Match = string(runes[trial.Start:trial.End])
+			}
+			advice[n].Trials = append(advice[n].Trials, trial)
+		}
+	}
+	return advice, input, nil
+}
+
+// parseBacktracking parses an explained pattern for the backtracker,
+// returning its syntax tree, the indexes of its quantifier tokens and the
+// mode each quantifier in the tree is written in. Go can't parse possessive
+// quantifiers, so they're parsed as greedy ones and marked possessive. The
+// modes are nil when the tokens and the tree's quantifiers don't line up
+// and none of them is possessive, as the tree then marks the lazy ones.
+func parseBacktracking(exp *Explanation) (*syntax.Regexp, []int, map[*syntax.Regexp]string, error) {
+	var quantifiers []int
+	possessive := map[int]bool{}
+	spans := format.TokenSpans(format.GetFormat(exp.FormatName), exp.Pattern, exp.Flags)
+	for i, token := range exp.Tokens {
+		if i < len(spans) && isQuantifierToken(token.Token) {
+			quantifiers = append(quantifiers, i)
+			if quantifierMode(token.Token) == QuantifierPossessive {
+				possessive[spans[i].End-1] = true
+			}
+		}
+	}
+	var pattern strings.Builder
+	for i := 0; i < len(exp.Pattern); i++ {
+		if !possessive[i] {
+			pattern.WriteByte(exp.Pattern[i])
+		}
+	}
+
+	converted := goCompatiblePattern(pattern.String(), exp.FormatName)
+	var goFlags strings.Builder
+	for _, f := range exp.Flags {
+		if f == 'i' || f == 'm' || f == 's' {
+			goFlags.WriteRune(f)
+		}
+	}
+	if goFlags.Len() > 0 {
+		converted = "(?" + goFlags.String() + ")" + converted
+	}
+	parsed, err := syntax.Parse(converted, syntax.Perl)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("pattern can't be matched: %w", goSyntaxError(converted, err))
+	}
+
+	// Nested quantifiers come before the ones around them, both in the
+	// pattern and in a post-order walk of the syntax tree
+	var nodes []*syntax.Regexp
+	collectQuantifiers(parsed, &nodes)
+	if len(nodes) != len(quantifiers) {
+		if len(possessive) > 0 {
+			return nil, nil, nil, errors.New("the pattern's quantifiers can't be lined up with its tokens")
+		}
+		// Without possessive quantifiers the syntax tree knows the rest
+		return parsed, quantifiers, nil, nil
+	}
+	written := make(map[*syntax.Regexp]string, len(nodes))
+	for n, node := range nodes {
+		written[node] = quantifierMode(exp.Tokens[quantifiers[n]].Token)
+	}
+	return parsed, quantifiers, written, nil
+}
+
+// collectQuantifiers appends the quantifier nodes of a syntax tree in post
+// order
+func collectQuantifiers(re *syntax.Regexp, nodes *[]*syntax.Regexp) {
+	for _, sub := range re.Sub {
+		collectQuantifiers(sub, nodes)
+	}
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		*nodes = append(*nodes, re)
+	}
+}
+
+// quantifierBase strips the lazy ? or possessive + from a quantifier token
+func quantifierBase(token string) string {
+	if len(token) > 1 && (strings.HasSuffix(token, "?") || strings.HasSuffix(token, "+")) {
+		return token[:len(token)-1]
+	}
+	return token
+}
+
+// quantifierMode returns the mode a quantifier token is written in
+func quantifierMode(token string) string {
+	switch {
+	case len(token) > 1 && strings.HasSuffix(token, "?"):
+		return QuantifierLazy
+	case len(token) > 1 && strings.HasSuffix(token, "+"):
+		return QuantifierPossessive
+	}
+	return QuantifierGreedy
+}
+
+// quantifierSuffix returns what follows a quantifier to put it in a mode
+func quantifierSuffix(mode string) string {
+	switch mode {
+	case QuantifierLazy:
+		return "?"
+	case QuantifierPossessive:
+		return "+"
+	}
+	return ""
+}
+
+// quantifiedOperand returns the element the quantifier token at q repeats:
+// the token before it, or a whole group when that token closes one
+func quantifiedOperand(tokens []string, q int) string {
+	start := q - 1
+	for start > 0 && tokens[start] == "" {
+		start--
+	}
+	if start < 0 {
+		return ""
+	}
+	if tokens[start] == ")" {
+		for depth := 0; start >= 0; start-- {
+			switch {
+			case tokens[start] == ")":
+				depth++
+			case opensGroup(tokens[start]):
+				depth--
+			}
+			if depth == 0 {
+				break
+			}
+		}
+	}
+	return strings.Join(tokens[max(start, 0):q], "")
+}
+
+// backtracker matches a parsed pattern the way backtracking engines such as
+// PCRE do, preferring the first alternative and each quantifier's mode, so
+// possessive quantifiers can be tried. The target quantifier is matched in
+// mode, and the others as written.
+type backtracker struct {
+	input   []rune
+	written map[*syntax.Regexp]string
+	target  *syntax.Regexp
+	mode    string
+
+	// steps counts the steps taken, and the backtracker gives up once
+	// they pass limit
+	steps  int
+	limit  int
+	gaveUp bool
+}
+
+// match matches re at i, calling k with where each way of matching it ends
+// until k returns true
+func (b *backtracker) match(re *syntax.Regexp, i int, k func(int) bool) bool {
+	if b.steps++; b.steps > b.limit {
+		b.gaveUp = true
+	}
+	if b.gaveUp {
+		return false
+	}
+
+	in := b.input
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		return k(i)
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if i >= len(in) || !(in[i] == r || re.Flags&syntax.FoldCase != 0 && strings.EqualFold(string(in[i]), string(r))) {
+				return false
+			}
+			i++
+		}
+		return k(i)
+	case syntax.OpCharClass:
+		return i < len(in) && classContains(re.Rune, in[i]) && k(i+1)
+	case syntax.OpAnyCharNotNL:
+		return i < len(in) && in[i] != '\n' && k(i+1)
+	case syntax.OpAnyChar:
+		return i < len(in) && k(i+1)
+	case syntax.OpBeginLine:
+		return (i == 0 || in[i-1] == '\n') && k(i)
+	case syntax.OpEndLine:
+		return (i == len(in) || in[i] == '\n') && k(i)
+	case syntax.OpBeginText:
+		return i == 0 && k(i)
+	case syntax.OpEndText:
+		return i == len(in) && k(i)
+	case syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		before := i > 0 && syntax.IsWordChar(in[i-1])
+		after := i < len(in) && syntax.IsWordChar(in[i])
+		return (before != after) == (re.Op == syntax.OpWordBoundary) && k(i)
+	case syntax.OpCapture:
+		return b.match(re.Sub[0], i, k)
+	case syntax.OpConcat:
+		return b.sequence(re.Sub, i, k)
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if b.match(sub, i, k) {
+				return true
+			}
+		}
+		return false
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, -1
+		case syntax.OpPlus:
+			min, max = 1, -1
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		mode, ok := b.written[re]
+		if !ok && re.Flags&syntax.NonGreedy != 0 {
+			mode = QuantifierLazy
+		}
+		if re == b.target {
+			mode = b.mode
+		}
+
+		if mode != QuantifierPossessive {
+			return b.repeat(re.Sub[0], min, max, 0, i, mode == QuantifierLazy, k)
+		}
+		// A possessive quantifier keeps the first, greedy, way it matches
+		// and never gives any of it back
+		end := -1
+		b.repeat(re.Sub[0], min, max, 0, i, false, func(j int) bool {
+			end = j
+			return true
+		})
+		return end >= 0 && k(end)
+	}
+	return false
+}
+
+// sequence matches the elements of a concatenation one after another
+func (b *backtracker) sequence(subs []*syntax.Regexp, i int, k func(int) bool) bool {
+	if len(subs) == 0 {
+		return k(i)
+	}
+	return b.match(subs[0], i, func(j int) bool {
+		return b.sequence(subs[1:], j, k)
+	})
+}
+
+// repeat matches sub between min and max times, or any number of times for
+// a max of -1, having matched it count times so far, trying fewer
+// repetitions first when lazy. A repetition that matches nothing ends the
+// loop, as Perl does.
+func (b *backtracker) repeat(sub *syntax.Regexp, min, max, count, i int, lazy bool, k func(int) bool) bool {
+	more := func() bool {
+		return (max < 0 || count < max) && b.match(sub, i, func(j int) bool {
+			if j == i && count >= min {
+				return false
+			}
+			return b.repeat(sub, min, max, count+1, j, lazy, k)
+		})
+	}
+	stop := func() bool {
+		return count >= min && k(i)
+	}
+	if lazy {
+		return stop() || more()
+	}
+	return more() || stop()
+}
+
+// RenderQuantifierAdvice renders what each quantifier matches in each mode
+func RenderQuantifierAdvice(exp *Explanation, advice []QuantifierAdvice, input string) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "%sQuantifiers, tried on %q:%s\n", colorBold, input, colorReset)
+	result.WriteString("Greedy takes as much as it can and gives back only what the rest of the pattern needs,\n")
+	result.WriteString("lazy takes as little as it can, and possessive takes as much as it can and never gives any back.\n")
+
+	possessive := true
+	for _, feature := range exp.Features {
+		if feature.Name == "Possessive Quantifiers" {
+			possessive = feature.Supported
+		}
+	}
+
+	for n, a := range advice {
+		fmt.Fprintf(&result, "\n%d. %s (token %d)\n", n+1, a.Quantified, a.Token)
+		width := 0
+		for _, trial := range a.Trials {
+			width = max(width, utf8.RuneCountInString(trial.Syntax))
+		}
+
+		same := true
+		for _, trial := range a.Trials {
+			outcome := "no match"
+			switch {
+			case trial.GaveUp:
+				outcome = "gave up after too many steps"
+			case trial.Matched:
+				outcome = fmt.Sprintf("%q at %d-%d", trial.Match, trial.Start, trial.End)
+			}
+			var notes []string
+			if trial.Written {
+				notes = append(notes, "as written")
+			}
+			if trial.Mode == QuantifierPossessive && !possessive || trial.Mode == QuantifierLazy && exp.FormatName == "posix" {
+				notes = append(notes, "not available in "+exp.Format)
+			}
+			note := ""
+			if len(notes) > 0 {
+				note = " (" + strings.Join(notes, ", ") + ")"
+			}
+			fmt.Fprintf(&result, "   %-10s  %s%s  %s%s\n", trial.Mode, trial.Syntax,
+				strings.Repeat(" ", width-utf8.RuneCountInString(trial.Syntax)), outcome, note)
+
+			first := a.Trials[0]
+			same = same && trial.Matched == first.Matched && trial.Start == first.Start && trial.End == first.End
+		}
+		if same {
+			result.WriteString("   All three match the same here, so the choice doesn't change this match.\n")
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAdviseQuantifiers(t *testing.T) {
+	tests := []struct {
+		pattern, formatName, input string
+		quantified                 string
+		want                       []string
+	}{
+		{`<.+>`, "pcre", "<a><b>", ".+", []string{"<a><b>", "<a>", ""}},
+		{`"(.*)"`, "pcre", `x="a" y="b"`, ".*", []string{`"a" y="b"`, `"a"`, ""}},
+		{`\d++\d`, "pcre", "1234", `\d++`, []string{"1234", "12", ""}},
+		{`(ab)+c`, "pcre", "ababc", "(ab)+", []string{"ababc", "ababc", "ababc"}},
+		{`/x.*?y/s`, "js", "x\ny y", ".*?", []string{"x\ny y", "x\ny", ""}},
+	}
+
+	for _, tt := range tests {
+		exp := AnalyzeWithFlags(tt.pattern, tt.formatName, "")
+		advice, _, err := AdviseQuantifiers(exp, tt.input)
+		if err != nil || len(advice) != 1 {
+			t.Fatalf("AdviseQuantifiers(%q) = %v, %v, want one quantifier", tt.pattern, advice, err)
+		}
+		if advice[0].Quantified != tt.quantified {
+			t.Errorf("AdviseQuantifiers(%q) quantified %q, want %q", tt.pattern, advice[0].Quantified, tt.quantified)
+		}
+		for i, trial := range advice[0].Trials {
+			if trial.Match != tt.want[i] || trial.Matched != (tt.want[i] != "") {
+				t.Errorf("AdviseQuantifiers(%q) %s = %q, want %q", tt.pattern, trial.Mode, trial.Match, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestAdviseQuantifiersKeepsOthersAsWritten(t *testing.T) {
+	// The possessive a*+ leaves nothing for a? to give back, whatever mode
+	// a? is tried in
+	exp := AnalyzeWithFlags(`a*+ab`, "pcre", "")
+	advice, _, err := AdviseQuantifiers(exp, "aab")
+	if err != nil || len(advice) != 1 {
+		t.Fatalf("AdviseQuantifiers() = %v, %v", advice, err)
+	}
+	for _, trial := range advice[0].Trials {
+		if trial.Mode == QuantifierPossessive && (!trial.Written || trial.Matched) {
+			t.Errorf("possessive trial = %+v, want written and not matching", trial)
+		}
+		if trial.Mode != QuantifierPossessive && trial.Match != "aab" {
+			t.Errorf("%s trial matched %q, want \"aab\"", trial.Mode, trial.Match)
+		}
+	}
+
+	exp = AnalyzeWithFlags(`a*+a?b`, "pcre", "")
+	advice, _, err = AdviseQuantifiers(exp, "aab")
+	if err != nil || len(advice) != 2 {
+		t.Fatalf("AdviseQuantifiers() = %v, %v", advice, err)
+	}
+	if trial := advice[1].Trials[1]; trial.Match != "aab" {
+		t.Errorf("lazy a?? matched %q, want \"aab\" with a*+ taking the a's", trial.Match)
+	}
+}
+
+func TestAdviseQuantifiersErrors(t *testing.T) {
+	for _, pattern := range []string{`abc`, `(?<=a)b+`} {
+		if _, _, err := AdviseQuantifiers(AnalyzeWithFlags(pattern, "pcre", ""), "ab"); err == nil {
+			t.Errorf("AdviseQuantifiers(%q) succeeded, want an error", pattern)
+		}
+	}
+}
+
+func TestRenderQuantifierAdvice(t *testing.T) {
+	SetColor(false)
+	exp := AnalyzeWithFlags(`<.+>`, "go", "")
+	advice, input, err := AdviseQuantifiers(exp, "<a><b>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := RenderQuantifierAdvice(exp, advice, input)
+	for _, want := range []string{
+		`Quantifiers, tried on "<a><b>":`,
+		`1. .+ (token 3)`,
+		`greedy      .+   "<a><b>" at 0-6 (as written)`,
+		`lazy        .+?  "<a>" at 0-3`,
+		`possessive  .++  no match (not available in Go Regexp)`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output is missing %q:\n%s", want, output)
+		}
+	}
+}
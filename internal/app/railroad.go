@@ -0,0 +1,295 @@
+package app
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+// diagramBlock is a rectangular piece of a railroad diagram. All lines have
+// the same display width and the track enters and leaves on the mid line.
+type diagramBlock struct {
+	lines []string
+	mid   int
+	width int
+}
+
+// RenderRailroad draws a simplified railroad diagram of the pattern with
+// box-drawing characters, for terminals that can't display images
+func RenderRailroad(exp *Explanation) (string, error) {
+	parsed, err := parseSyntax(exp.Pattern, exp.FormatName)
+	if err != nil {
+		return "", err
+	}
+
+	diagram := joinBlocks([]diagramBlock{
+		trackBlock("○─"),
+		railroadNode(parsed),
+		trackBlock("─◎"),
+	}, "")
+
+	var result strings.Builder
+	for _, line := range diagram.lines {
+		result.WriteString(strings.TrimRight(line, " "))
+		result.WriteString("\n")
+	}
+	if asciiGlyphs {
+		return asciiBoxDrawing.Replace(result.String()), nil
+	}
+	return result.String(), nil
+}
+
+// railroadNode converts a syntax tree node into a diagram block
+func railroadNode(re *syntax.Regexp) diagramBlock {
+	switch re.Op {
+	case syntax.OpLiteral:
+		label := fmt.Sprintf("%q", string(re.Rune))
+		if re.Flags&syntax.FoldCase != 0 {
+			label += " (ignore case)"
+		}
+		return boxBlock(label)
+	case syntax.OpCharClass:
+		return boxBlock(re.String())
+	case syntax.OpAnyCharNotNL:
+		return boxBlock("any character except newline")
+	case syntax.OpAnyChar:
+		return boxBlock("any character")
+	case syntax.OpBeginLine:
+		return boxBlock("start of line")
+	case syntax.OpEndLine:
+		return boxBlock("end of line")
+	case syntax.OpBeginText:
+		return boxBlock("start of text")
+	case syntax.OpEndText:
+		return boxBlock("end of text")
+	case syntax.OpWordBoundary:
+		return boxBlock("word boundary")
+	case syntax.OpNoWordBoundary:
+		return boxBlock("non-word boundary")
+	case syntax.OpCapture:
+		label := fmt.Sprintf("group %d", re.Cap)
+		if re.Name != "" {
+			label += " " + re.Name
+		}
+		return frameBlock(railroadNode(re.Sub[0]), label)
+	case syntax.OpConcat:
+		var blocks []diagramBlock
+		for _, sub := range re.Sub {
+			blocks = append(blocks, railroadNode(sub))
+		}
+		return joinBlocks(blocks, "──")
+	case syntax.OpAlternate:
+		var blocks []diagramBlock
+		for _, sub := range re.Sub {
+			blocks = append(blocks, railroadNode(sub))
+		}
+		return stackBlocks(blocks)
+	case syntax.OpQuest:
+		return optionalBlock(railroadNode(re.Sub[0]))
+	case syntax.OpPlus:
+		return loopBlock(railroadNode(re.Sub[0]), repeatLabel(re, "1+"))
+	case syntax.OpStar:
+		return optionalBlock(loopBlock(railroadNode(re.Sub[0]), repeatLabel(re, "")))
+	case syntax.OpRepeat:
+		var label string
+		switch {
+		case re.Max == -1:
+			label = fmt.Sprintf("%d+", re.Min)
+		case re.Min == re.Max:
+			label = fmt.Sprintf("%d×", re.Min)
+		default:
+			label = fmt.Sprintf("%d..%d", re.Min, re.Max)
+		}
+		block := loopBlock(railroadNode(re.Sub[0]), repeatLabel(re, label))
+		if re.Min == 0 {
+			block = optionalBlock(block)
+		}
+		return block
+	}
+
+	// Empty matches and anything else pass straight through
+	return trackBlock("──")
+}
+
+// repeatLabel builds the label drawn on a loop's return track
+func repeatLabel(re *syntax.Regexp, count string) string {
+	label := "<"
+	if count != "" {
+		label += " " + count
+	}
+	if re.Flags&syntax.NonGreedy != 0 {
+		label += " lazy"
+	}
+	return label
+}
+
+// trackBlock is a single line of track
+func trackBlock(track string) diagramBlock {
+	return diagramBlock{lines: []string{track}, mid: 0, width: displayWidth(track)}
+}
+
+// boxBlock draws a terminal element
+func boxBlock(label string) diagramBlock {
+	width := displayWidth(label)
+	return diagramBlock{
+		lines: []string{
+			"┌" + strings.Repeat("─", width+2) + "┐",
+			"┤ " + label + " ├",
+			"└" + strings.Repeat("─", width+2) + "┘",
+		},
+		mid:   1,
+		width: width + 4,
+	}
+}
+
+// padBlock widens a block, extending the track on its mid line
+func padBlock(b diagramBlock, width int) diagramBlock {
+	if b.width >= width {
+		return b
+	}
+	extra := width - b.width
+	lines := make([]string, len(b.lines))
+	for i, line := range b.lines {
+		if i == b.mid {
+			lines[i] = line + strings.Repeat("─", extra)
+		} else {
+			lines[i] = line + strings.Repeat(" ", extra)
+		}
+	}
+	return diagramBlock{lines: lines, mid: b.mid, width: width}
+}
+
+// joinBlocks places blocks side by side with their tracks aligned
+func joinBlocks(blocks []diagramBlock, separator string) diagramBlock {
+	if len(blocks) == 0 {
+		return trackBlock("──")
+	}
+
+	above, below := 0, 0
+	for _, b := range blocks {
+		above = max(above, b.mid)
+		below = max(below, len(b.lines)-b.mid-1)
+	}
+
+	lines := make([]string, above+below+1)
+	width := 0
+	for i, b := range blocks {
+		if i > 0 {
+			for row := range lines {
+				if row == above {
+					lines[row] += separator
+				} else {
+					lines[row] += strings.Repeat(" ", displayWidth(separator))
+				}
+			}
+			width += displayWidth(separator)
+		}
+		offset := above - b.mid
+		for row := range lines {
+			if row >= offset && row-offset < len(b.lines) {
+				lines[row] += b.lines[row-offset]
+			} else {
+				lines[row] += strings.Repeat(" ", b.width)
+			}
+		}
+		width += b.width
+	}
+
+	return diagramBlock{lines: lines, mid: above, width: width}
+}
+
+// stackBlocks draws alternatives on top of each other, joined by rails that
+// branch off the track on the left and merge back on the right
+func stackBlocks(blocks []diagramBlock) diagramBlock {
+	width := 0
+	for _, b := range blocks {
+		width = max(width, b.width)
+	}
+
+	// Find the rows where each branch's track runs
+	var lines []string
+	var mids []int
+	for _, b := range blocks {
+		b = padBlock(b, width)
+		mids = append(mids, len(lines)+b.mid)
+		lines = append(lines, b.lines...)
+	}
+	first, last := mids[0], mids[len(mids)-1]
+
+	result := make([]string, len(lines))
+	branch := 0
+	for row, line := range lines {
+		pre, left, fill, right, post := " ", " ", " ", " ", " "
+		if branch < len(mids) && row == mids[branch] {
+			fill = "─"
+			switch {
+			case branch == 0:
+				pre, left, right, post = "─", "┬", "┬", "─"
+			case branch == len(mids)-1:
+				left, right = "└", "┘"
+			default:
+				left, right = "├", "┤"
+			}
+			branch++
+		} else if row > first && row < last {
+			left, right = "│", "│"
+		}
+		result[row] = pre + left + fill + line + fill + right + post
+	}
+
+	return diagramBlock{lines: result, mid: first, width: width + 6}
+}
+
+// optionalBlock draws a block that can be skipped by a bypass track
+func optionalBlock(b diagramBlock) diagramBlock {
+	return stackBlocks([]diagramBlock{trackBlock(""), b})
+}
+
+// loopBlock draws a block with a return track underneath for repetition
+func loopBlock(b diagramBlock, label string) diagramBlock {
+	b = padBlock(b, displayWidth(label)+2)
+
+	lines := make([]string, 0, len(b.lines)+1)
+	for row, line := range b.lines {
+		switch {
+		case row == b.mid:
+			lines = append(lines, "─┬─"+line+"─┬─")
+		case row < b.mid:
+			lines = append(lines, "   "+line+"   ")
+		default:
+			lines = append(lines, " │ "+line+" │ ")
+		}
+	}
+
+	// Return track, with the label centered on it
+	inner := b.width + 2 - displayWidth(label) - 2
+	leftFill := inner / 2
+	lines = append(lines, " └"+strings.Repeat("─", leftFill)+" "+label+" "+strings.Repeat("─", inner-leftFill)+"┘ ")
+
+	return diagramBlock{lines: lines, mid: b.mid, width: b.width + 6}
+}
+
+// frameBlock draws a labeled frame around a block, used for capturing groups
+func frameBlock(b diagramBlock, label string) diagramBlock {
+	b = padBlock(b, displayWidth(label)+4)
+
+	lines := make([]string, 0, len(b.lines)+2)
+	topFill := b.width + 2 - displayWidth(label) - 3
+	lines = append(lines, " ┌─ "+label+" "+strings.Repeat("─", topFill)+"┐ ")
+	for row, line := range b.lines {
+		if row == b.mid {
+			lines = append(lines, "─┤ "+line+" ├─")
+		} else {
+			lines = append(lines, " │ "+line+" │ ")
+		}
+	}
+	lines = append(lines, " └"+strings.Repeat("─", b.width+2)+"┘ ")
+
+	return diagramBlock{lines: lines, mid: b.mid + 1, width: b.width + 6}
+}
+
+// displayWidth counts the terminal columns used by a diagram string
+func displayWidth(s string) int {
+	return utf8.RuneCountInString(s)
+}
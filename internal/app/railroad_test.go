@@ -0,0 +1,69 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderRailroad(t *testing.T) {
+	got, err := RenderRailroad(Analyze(`^(?P<animal>cat|dog)s?\d{2,4}$`, "go"))
+	if err != nil {
+		t.Fatalf("RenderRailroad() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"○─┤ start of text ├",
+		"┌─ group 1 animal ",
+		`┤ "cat" ├`,
+		`└─┤ "dog" ├─┘`,
+		`└─┤ "s" ├─┘`,
+		"┤ [0-9] ├",
+		"< 2..4",
+		"┤ end of text ├─◎",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderRailroad() should contain %q, got:\n%s", want, got)
+		}
+	}
+
+	// Every line of a block is padded to the same width, so the start and
+	// end markers must share the track's line
+	lines := strings.Split(strings.TrimRight(got, "\n"), "\n")
+	track := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "○") {
+			track = i
+		}
+	}
+	if track == -1 || !strings.HasSuffix(lines[track], "◎") {
+		t.Errorf("RenderRailroad() should draw the start and end markers on one line, got:\n%s", got)
+	}
+}
+
+func TestRenderRailroad_Loops(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`a+`, "< 1+"},
+		{`a*?`, "< lazy"},
+		{`a{3}`, "< 3×"},
+		{`a{2,}`, "< 2+"},
+	}
+
+	for _, tt := range tests {
+		got, err := RenderRailroad(Analyze(tt.pattern, "go"))
+		if err != nil {
+			t.Fatalf("RenderRailroad(%q) error = %v", tt.pattern, err)
+		}
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("RenderRailroad(%q) should contain %q, got:\n%s", tt.pattern, tt.want, got)
+		}
+	}
+}
+
+func TestRenderRailroad_Unsupported(t *testing.T) {
+	if _, err := RenderRailroad(Analyze(`(?<=a)b`, "pcre")); err == nil {
+		t.Error("RenderRailroad() should fail for lookbehind, which regexp/syntax can't parse")
+	}
+}
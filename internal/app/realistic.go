@@ -0,0 +1,115 @@
+package app
+
+import (
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+// Word lists realistic samples draw repeated classes from, each in the
+// order its entries are used
+var (
+	realisticYears   = []string{"1987", "2024", "1969", "2010", "1999", "2031"}
+	realisticNumbers = []string{"42", "7", "365", "12", "3", "80", "443", "8080", "255", "1024", "65535"}
+	realisticDotted  = []string{"john.doe", "mail.example", "jane.smith", "api.example", "docs.example"}
+	realisticTLDs    = []string{"com", "org", "net", "io", "dev"}
+	realisticNames   = []string{"Alice", "Bob", "Carol", "David", "Emma", "Frank", "Grace", "Henry", "Isabel", "Oliver"}
+	realisticWords   = []string{
+		"hello", "world", "coffee", "river", "garden", "window", "yellow", "planet",
+		"music", "paper", "stone", "light", "cloud", "apple", "ocean", "forest",
+		"sun", "cat", "sky", "map", "tea", "box", "go", "a",
+		"mountain", "keyboard", "sandwich", "elephant", "umbrella", "breakfast", "adventure", "photograph",
+	}
+)
+
+// realisticPicker picks entries from the word lists, moving on to the next
+// entry of a list each time it's used so a sample doesn't repeat a word
+type realisticPicker struct {
+	used map[*[]string]int
+}
+
+// newRealisticPicker returns a picker starting at the first entry of each
+// list
+func newRealisticPicker() *realisticPicker {
+	return &realisticPicker{used: make(map[*[]string]int)}
+}
+
+// pick returns a word for a class token repeated between least and most
+// times, with most -1 for no limit, that every character of is a member of
+// the class with the modifier letters in effect. A class right after a dot
+// gets a top-level domain when one fits. It reports false when no word fits.
+func (p *realisticPicker) pick(token, formatName, flags string, least, most int, afterDot bool) (string, bool) {
+	r, err := CompilePattern("^(?:"+token+")+$", formatName, flags)
+	if err != nil {
+		return "", false
+	}
+
+	// Digits get years and numbers, and letters words, with names for
+	// letters that can't be digits, as in [A-Za-z]+, and dotted names for
+	// classes that list a dot, as in [\w.-]+
+	var lists []*[]string
+	switch {
+	case !r.MatchString("a") && !r.MatchString("A"):
+		lists = []*[]string{&realisticYears, &realisticNumbers}
+	case TokenCategory(token) == CategoryClass && strings.Contains(token, ".") && r.MatchString("a.b"):
+		lists = []*[]string{&realisticDotted, &realisticWords}
+	case r.MatchString("Alice") && !r.MatchString("7"):
+		lists = []*[]string{&realisticNames, &realisticWords}
+	default:
+		lists = []*[]string{&realisticWords, &realisticNames, &realisticYears, &realisticNumbers}
+	}
+	if afterDot {
+		lists = append([]*[]string{&realisticTLDs}, lists...)
+	}
+
+	for _, list := range lists {
+		var fitting []string
+		for _, word := range *list {
+			length := utf8.RuneCountInString(word)
+			if length >= least && (most < 0 || length <= most) && r.MatchString(word) {
+				fitting = append(fitting, word)
+			}
+		}
+		if len(fitting) > 0 {
+			word := fitting[p.used[list]%len(fitting)]
+			p.used[list]++
+			return word, true
+		}
+	}
+	return "", false
+}
+
+// isRealisticClass reports whether a token is a class, shorthand or . that
+// realistic samples can fill with a word
+func isRealisticClass(token string) bool {
+	switch TokenCategory(token) {
+	case CategoryClass, CategoryAny:
+		return true
+	case CategoryEscape:
+		return len(token) > 1 && strings.IndexByte("dwsDWSpPhHvVN", token[1]) >= 0
+	}
+	return false
+}
+
+// isRepeatedClass reports whether a parsed pattern repeats a class or .
+// that realistic samples can fill with a word
+func isRepeatedClass(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus, syntax.OpRepeat:
+		switch re.Sub[0].Op {
+		case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+			return true
+		}
+	}
+	return false
+}
+
+// replaceAtom replaces the element at the end of a sample with text
+func replaceAtom(sample *strings.Builder, atom Position, text string) {
+	current := sample.String()
+	if atom.end != len(current) || atom.start > atom.end {
+		return
+	}
+	sample.Reset()
+	sample.WriteString(current[:atom.start] + text)
+}
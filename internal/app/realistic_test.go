@@ -0,0 +1,45 @@
+package app
+
+import "testing"
+
+func TestAnalyze_RealisticSamples(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`^\w+ \d{4}$`, "hello 1987"},
+		{`^\d+-\d+$`, "1987-2024"},
+		{`^[\w.-]+@[a-z0-9.-]+\.[a-z]{2,}$`, "john.doe@mail.example.com"},
+		{`^[A-Za-z]+, \d{1,3}$`, "Alice, 42"},
+		{`^(\w+) \1$`, "hello hello"},
+		{`^[a-z]{2,3}-x$`, "sun-x"},
+		{`^a\d$`, "a5"},
+	}
+
+	for _, tt := range tests {
+		exp := AnalyzeWithOptions(tt.pattern, "go", "", ExampleOptions{Realistic: true})
+		if exp.Sample != tt.want || exp.SampleStatus != "Verified match" {
+			t.Errorf("Analyze(%q) realistic sample = %q (%s), want verified %q", tt.pattern, exp.Sample, exp.SampleStatus, tt.want)
+		}
+	}
+}
+
+func TestIsRealisticClass(t *testing.T) {
+	tests := []struct {
+		token string
+		want  bool
+	}{
+		{`\w`, true},
+		{`[a-z]`, true},
+		{".", true},
+		{`\n`, false},
+		{"a", false},
+		{")", false},
+	}
+
+	for _, tt := range tests {
+		if got := isRealisticClass(tt.token); got != tt.want {
+			t.Errorf("isRealisticClass(%q) = %v, want %v", tt.token, got, tt.want)
+		}
+	}
+}
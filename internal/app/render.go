@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Renderer writes an explanation to w in one output format
+type Renderer interface {
+	Render(w io.Writer, exp *Explanation) error
+}
+
+// RendererFunc adapts a function rendering a whole document to a Renderer
+type RendererFunc func(exp *Explanation) (string, error)
+
+// Render writes the document rendered by f to w
+func (f RendererFunc) Render(w io.Writer, exp *Explanation) error {
+	rendered, err := f(exp)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, rendered)
+	return err
+}
+
+// infallible adapts a render function that can't fail to a RendererFunc
+func infallible(render func(*Explanation) string) RendererFunc {
+	return func(exp *Explanation) (string, error) {
+		return render(exp), nil
+	}
+}
+
+// renderers are the document output formats by their -output name
+var renderers = map[string]Renderer{
+	"markdown":     infallible(RenderMarkdown),
+	"html":         RendererFunc(RenderHTML),
+	"html-snippet": infallible(RenderHTMLSnippet),
+	"dot":          RendererFunc(RenderDOT),
+	"railroad":     RendererFunc(RenderRailroad),
+	"roff":         infallible(RenderRoff),
+	"rst":          infallible(RenderRST),
+}
+
+// NewRenderer returns the renderer for one of the document output formats
+func NewRenderer(output string) (Renderer, error) {
+	renderer, ok := renderers[output]
+	if !ok {
+		return nil, fmt.Errorf("unsupported output format '%s'", output)
+	}
+	return renderer, nil
+}
+
+// TemplateFileRenderer renders explanations with the text/template in path
+func TemplateFileRenderer(path string) Renderer {
+	return RendererFunc(func(exp *Explanation) (string, error) {
+		return RenderTemplateFile(exp, path)
+	})
+}
+
+// Render renders an explanation in one of the document output formats
+func Render(exp *Explanation, output string) (string, error) {
+	renderer, err := NewRenderer(output)
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	if err := renderer.Render(&result, exp); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
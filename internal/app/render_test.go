@@ -0,0 +1,86 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExplainRegex_Writer(t *testing.T) {
+	SetColor(false)
+	defer SetColor(true)
+
+	var buf bytes.Buffer
+	if err := ExplainRegex(&buf, `a\d+`, "go", true, false); err != nil {
+		t.Fatalf("ExplainRegex() error = %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"Analyzing regex pattern: a\\d+\n",
+		"Format: Go Regexp\n",
+		"Supported Features:\n",
+		"Token explanations:\n",
+		"Colored pattern:\n",
+		"Example matching string:\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ExplainRegex() output should contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	var buf bytes.Buffer
+	result, err := Run(RunOptions{Pattern: "abc", Output: "markdown", Writer: &buf})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Explanation.FormatName != "go" {
+		t.Errorf("Run() flavor = %q, want the go default", result.Explanation.FormatName)
+	}
+	if want := RenderMarkdown(Analyze("abc", "go")); buf.String() != want {
+		t.Errorf("Run() wrote:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	if _, err := Run(RunOptions{Pattern: "abc", Output: "pdf", Writer: &buf}); err == nil {
+		t.Error("Run() with an unknown output format should fail")
+	}
+	if _, err := Run(RunOptions{Writer: &buf}); err == nil {
+		t.Error("Run() without a pattern should fail")
+	}
+}
+
+func TestRun_OutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "explanation.rst")
+
+	var buf bytes.Buffer
+	result, err := Run(RunOptions{Pattern: "abc", Flavor: "pcre", Output: "rst", OutputFile: path, Writer: &buf})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.OutputFile != path || buf.Len() != 0 {
+		t.Errorf("Run() should write to %s only, got result file %q and %d bytes written", path, result.OutputFile, buf.Len())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := RenderRST(Analyze("abc", "pcre")); string(data) != want {
+		t.Errorf("Run() wrote:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestNewRenderer(t *testing.T) {
+	for _, output := range []string{"markdown", "html", "html-snippet", "dot", "railroad", "roff", "rst"} {
+		if _, err := NewRenderer(output); err != nil {
+			t.Errorf("NewRenderer(%q) error = %v", output, err)
+		}
+	}
+	if _, err := NewRenderer("text"); err == nil {
+		t.Error("NewRenderer(text) should fail, the terminal text has its own renderer")
+	}
+}
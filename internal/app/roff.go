@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderRoff renders an explanation as a man page, using bold and italic
+// fonts where the terminal output uses colors
+func RenderRoff(exp *Explanation) string {
+	var result strings.Builder
+
+	result.WriteString(".TH UNREGEX 7 \"\" \"unregex\" \"Regex explanation\"\n")
+	result.WriteString(".SH NAME\n")
+	result.WriteString(roffEscape(exp.Pattern) + " \\- regex explanation\n")
+
+	result.WriteString(".SH PATTERN\n")
+	result.WriteString(".nf\n")
+	result.WriteString("\\fB" + roffEscape(exp.Pattern) + "\\fR\n")
+	result.WriteString(".fi\n")
+	result.WriteString(".PP\n")
+	result.WriteString(fmt.Sprintf("Format: \\fI%s\\fR\n", roffEscape(exp.Format)))
+	if exp.Flags != "" {
+		result.WriteString(".br\n")
+		result.WriteString(fmt.Sprintf("Flags: %s\n", roffEscape(exp.FlagsDescription)))
+	}
+
+	result.WriteString(".SH TOKENS\n")
+	for i, token := range exp.Tokens {
+		result.WriteString(".TP\n")
+		result.WriteString(fmt.Sprintf("%d. \\fB%s\\fR\n", i+1, roffEscape(token.Token)))
+		result.WriteString(roffEscape(token.Explanation) + "\n")
+	}
+
+	result.WriteString(".SH SUPPORTED FEATURES\n")
+	for _, feature := range exp.Features {
+		supported := "no"
+		if feature.Supported {
+			supported = "yes"
+		}
+		result.WriteString(".TP\n")
+		result.WriteString(fmt.Sprintf("\\fB%s\\fR (%s)\n", roffEscape(feature.Name), roffEscape(feature.Syntax)))
+		result.WriteString(supported + "\n")
+	}
+
+	result.WriteString(".SH EXAMPLE MATCH\n")
+	if exp.Sample == "" {
+		result.WriteString("Couldn't generate a sample for this pattern.\n")
+	} else {
+		result.WriteString(".nf\n")
+		result.WriteString("\\fI" + roffEscape(exp.Sample) + "\\fR\n")
+		result.WriteString(".fi\n")
+		result.WriteString(".PP\n")
+		result.WriteString(roffEscape(exp.SampleStatus) + "\n")
+	}
+
+	return result.String()
+}
+
+// roffEscape escapes text so troff prints it literally, including lines
+// that would otherwise be read as requests
+func roffEscape(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\e")
+	text = strings.ReplaceAll(text, "-", "\\-")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = "\\&" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderRoff(t *testing.T) {
+	got := RenderRoff(Analyze(`^\d-x$`, "go"))
+
+	for _, want := range []string{
+		".TH UNREGEX 7",
+		".SH NAME\n^\\ed\\-x$ \\- regex explanation\n",
+		"\\fB^\\ed\\-x$\\fR",
+		".SH TOKENS\n.TP\n1. \\fB^\\fR\n",
+		".SH SUPPORTED FEATURES\n",
+		".SH EXAMPLE MATCH\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderRoff() should contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestRoffEscape(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`a\b`, `a\eb`},
+		{"a-b", `a\-b`},
+		{".start", `\&.start`},
+		{"'quoted'", `\&'quoted'`},
+		{"line\n.next", "line\n\\&.next"},
+	}
+
+	for _, tt := range tests {
+		if got := roffEscape(tt.text); got != tt.want {
+			t.Errorf("roffEscape(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderRST renders an explanation as reStructuredText for Sphinx and other
+// docutils-based documentation pipelines
+func RenderRST(exp *Explanation) string {
+	var result strings.Builder
+
+	result.WriteString(rstHeading("Regex explanation", "="))
+	result.WriteString(rstCodeBlock(exp.Pattern))
+	result.WriteString(fmt.Sprintf("**Format:** %s\n\n", rstEscape(exp.Format)))
+	if exp.Flags != "" {
+		result.WriteString(fmt.Sprintf("**Flags:** %s\n\n", rstEscape(exp.FlagsDescription)))
+	}
+
+	result.WriteString(rstHeading("Tokens", "-"))
+	result.WriteString(".. list-table::\n")
+	result.WriteString("   :header-rows: 1\n")
+	result.WriteString("   :widths: 5 20 75\n\n")
+	result.WriteString("   * - #\n     - Token\n     - Explanation\n")
+	for i, token := range exp.Tokens {
+		result.WriteString(fmt.Sprintf("   * - %d\n     - %s\n     - %s\n", i+1, rstLiteral(token.Token), rstEscape(token.Explanation)))
+	}
+	result.WriteString("\n")
+
+	result.WriteString(rstHeading("Supported features", "-"))
+	result.WriteString(".. list-table::\n")
+	result.WriteString("   :header-rows: 1\n\n")
+	result.WriteString("   * - Feature\n     - Syntax\n     - Supported\n")
+	for _, feature := range exp.Features {
+		supported := "✗"
+		if feature.Supported {
+			supported = "✓"
+		}
+		result.WriteString(fmt.Sprintf("   * - %s\n     - %s\n     - %s\n", rstEscape(feature.Name), rstLiteral(feature.Syntax), supported))
+	}
+	result.WriteString("\n")
+
+	result.WriteString(rstHeading("Example match", "-"))
+	if exp.Sample == "" {
+		result.WriteString("*Couldn't generate a sample for this pattern.*\n")
+	} else {
+		result.WriteString(rstCodeBlock(exp.Sample))
+		result.WriteString(fmt.Sprintf("*%s*\n", rstEscape(exp.SampleStatus)))
+	}
+
+	return result.String()
+}
+
+// rstHeading underlines a section title, which reST requires to be at
+// least as long as the title
+func rstHeading(title, underline string) string {
+	return fmt.Sprintf("%s\n%s\n\n", title, strings.Repeat(underline, len([]rune(title))))
+}
+
+// rstCodeBlock renders text as an indented literal code block
+func rstCodeBlock(text string) string {
+	var result strings.Builder
+	result.WriteString(".. code-block:: text\n\n")
+	for _, line := range strings.Split(text, "\n") {
+		result.WriteString("   " + line + "\n")
+	}
+	result.WriteString("\n")
+	return result.String()
+}
+
+// rstLiteral renders text as an inline literal, falling back to escaped
+// text for values an inline literal can't hold
+func rstLiteral(text string) string {
+	if text == "" || strings.Contains(text, "``") || strings.TrimSpace(text) != text || strings.HasSuffix(text, "`") {
+		return `"` + rstEscape(text) + `"`
+	}
+	return "``" + text + "``"
+}
+
+// rstEscape escapes inline markup characters in running text
+func rstEscape(text string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		"*", "\\*",
+		"`", "\\`",
+		"_", "\\_",
+		"|", "\\|",
+		"\n", " ",
+	)
+	return replacer.Replace(text)
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderRST(t *testing.T) {
+	got := RenderRST(Analyze(`^a_b\d$`, "go"))
+
+	for _, want := range []string{
+		"Regex explanation\n=================\n",
+		".. code-block:: text\n\n   ^a_b\\d$\n",
+		"**Format:** Go Regexp",
+		"Tokens\n------\n",
+		".. list-table::\n   :header-rows: 1\n",
+		"   * - 2\n     - ``a_b``\n     - Matches the string 'a\\_b' literally\n",
+		"Example match\n-------------\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderRST() should contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestRSTLiteral(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`\d+`, "``\\d+``"},
+		{" ", `" "`},
+		{"a``b", "\"a\\`\\`b\""},
+		{"*", "``*``"},
+	}
+
+	for _, tt := range tests {
+		if got := rstLiteral(tt.text); got != tt.want {
+			t.Errorf("rstLiteral(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package app
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Sample biases, choosing how many times a quantifier repeats its element
+// in generated samples
+const (
+	SampleBiasMin     = "min"
+	SampleBiasTypical = "typical"
+	SampleBiasMax     = "max"
+)
+
+// maxSampleRepetitions caps the repetitions of an element in a sample,
+// unless the quantifier's minimum needs more
+const maxSampleRepetitions = 16
+
+// ExampleOptions configures the example match generated for a pattern
+type ExampleOptions struct {
+	// Bias is whether quantified elements are repeated the minimum number
+	// of times, a typical number of times, or the maximum: SampleBiasMin,
+	// SampleBiasTypical or SampleBiasMax, typical when empty
+	Bias string
+
+	// MaxLength caps the length of the example in characters, repeating
+	// unbounded quantifiers fewer times to fit, or is 0 for no cap
+	MaxLength int
+
+	// Realistic fills repeated classes with real-looking words, years,
+	// numbers, names and domains, such as "hello" for \w+ and "1987" for
+	// \d{4}, instead of repeating one character
+	Realistic bool
+
+	// Seed makes the example match repeat each quantified element a random
+	// number of times within its bounds, chosen from the seed so the same
+	// seed gives the same example. With 0 the example is the same every
+	// time.
+	Seed int64
+}
+
+// Validate reports an unknown bias or a negative length cap
+func (o ExampleOptions) Validate() error {
+	switch o.Bias {
+	case "", SampleBiasMin, SampleBiasTypical, SampleBiasMax:
+	default:
+		return fmt.Errorf("unsupported sample bias '%s' (supported: min, typical, max)", o.Bias)
+	}
+	if o.MaxLength < 0 {
+		return fmt.Errorf("invalid sample length %d (give 0 for no cap)", o.MaxLength)
+	}
+	return nil
+}
+
+// random returns the source of the example's random choices, or nil when
+// it has no seed and makes none
+func (o ExampleOptions) random() *rand.Rand {
+	if o.Seed == 0 {
+		return nil
+	}
+	return rand.New(rand.NewSource(o.Seed))
+}
+
+// quantifierBounds returns the fewest and most repetitions a quantifier
+// token allows, with -1 as the most for unbounded ones. Lazy and
+// possessive variants have the same bounds.
+func quantifierBounds(token string) (int, int, bool) {
+	if len(token) == 2 && (token[1] == '?' || token[1] == '+') {
+		token = token[:1]
+	}
+	switch token {
+	case "*":
+		return 0, -1, true
+	case "+":
+		return 1, -1, true
+	case "?":
+		return 0, 1, true
+	}
+	if !isQuantifierToken(token) {
+		return 0, 0, false
+	}
+
+	low, high, ranged := strings.Cut(token[1:len(token)-1], ",")
+	least, err := strconv.Atoi(low)
+	if err != nil {
+		return 0, 0, false
+	}
+	switch {
+	case !ranged:
+		return least, least, true
+	case high == "":
+		return least, -1, true
+	}
+	most, err := strconv.Atoi(high)
+	if err != nil {
+		return 0, 0, false
+	}
+	return least, most, true
+}
+
+// sampleRepetitions picks how many times to repeat an element with the
+// given bounds, following the bias: the minimum, the middle of the range,
+// or its maximum, with unbounded ranges treated as a few more than the
+// minimum. With a random source, a typical count is anywhere in the range
+// instead of its middle.
+func sampleRepetitions(least, most int, bias string, r *rand.Rand) int {
+	if most < 0 {
+		most = least + 3
+	}
+	var n int
+	switch {
+	case bias == SampleBiasMin:
+		n = least
+	case bias == SampleBiasMax:
+		n = most
+	case r != nil:
+		n = least + r.Intn(most-least+1)
+	default:
+		n = (least + most + 1) / 2
+	}
+	if n > maxSampleRepetitions && n > least {
+		n = maxSampleRepetitions
+		if least > n {
+			n = least
+		}
+	}
+	return n
+}
+
+// repeatAtom makes the element at the end of the sample, which is there
+// once, appear n times
+func repeatAtom(sample *strings.Builder, atom Position, n int) {
+	text := sample.String()
+	if atom.end != len(text) || atom.start > atom.end {
+		return
+	}
+	if n == 0 {
+		sample.Reset()
+		sample.WriteString(text[:atom.start])
+		return
+	}
+	sample.WriteString(strings.Repeat(text[atom.start:atom.end], n-1))
+}
+
+// sampleCandidates are the characters preferred as a sample member of a
+// class, in order, so samples read naturally
+const sampleCandidates = "mM7xkK5aA0"
+
+// sampleClassMember picks a member of a character class or escape, such as
+// [^a-z] or \W, reading it as Go's regexp/syntax package does, with case
+// folding when the i flag is in effect. It prefers letters and digits, then
+// ASCII punctuation, symbols and space, and reports false when the token can't
+// be read or matches nothing.
+func sampleClassMember(token, formatName string, foldCase bool) (rune, bool) {
+	pattern := goCompatiblePattern(token, formatName)
+	if foldCase {
+		pattern = "(?i)" + pattern
+	}
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return 0, false
+	}
+	parsed = parsed.Simplify()
+	switch {
+	case parsed.Op == syntax.OpLiteral && len(parsed.Rune) == 1:
+		return parsed.Rune[0], true
+	case parsed.Op == syntax.OpAnyCharNotNL || parsed.Op == syntax.OpAnyChar:
+		return 'x', true
+	case parsed.Op != syntax.OpCharClass || len(parsed.Rune) == 0:
+		return 0, false
+	}
+
+	return preferredMember(parsed.Rune), true
+}
+
+// preferredMember picks the member of a class, given as ranges, that reads
+// most naturally in a sample
+func preferredMember(ranges []rune) rune {
+	for _, r := range sampleCandidates {
+		if classContains(ranges, r) {
+			return r
+		}
+	}
+	for _, class := range []func(rune) bool{unicode.IsLetter, unicode.IsDigit, unicode.IsPunct, unicode.IsSymbol, unicode.IsSpace} {
+		for r := rune(' '); r <= '~'; r++ {
+			if class(r) && classContains(ranges, r) {
+				return r
+			}
+		}
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r-ranges[i] < 256; r++ {
+			if unicode.IsGraphic(r) && !unicode.IsSpace(r) {
+				return r
+			}
+		}
+	}
+	return ranges[0]
+}
+
+// sampleLineBreaks are the symbols line breaks in a sample are shown as, so
+// they can be seen and a \r doesn't send the cursor back over the line,
+// with their ASCII stand-ins
+var sampleLineBreaks = map[rune][2]string{'\n': {"↵", `\n`}, '\r': {"␍", `\r`}}
+
+// beforeLineStart reports whether the token at i, with an optional
+// quantifier after it, comes right before a ^ that matches after every
+// newline because the m flag is on
+func beforeLineStart(tokens []string, active []string, i int) bool {
+	next := i + 1
+	if next < len(tokens) && isQuantifierToken(tokens[next]) {
+		next++
+	}
+	return next < len(tokens) && tokens[next] == "^" && strings.ContainsRune(active[next], 'm')
+}
+
+// matchesNewline reports whether a token, such as \s, [\r\n] or . with the
+// s flag, can match a newline with the modifier letters in effect at it
+func matchesNewline(token, formatName, flags string) bool {
+	pattern := goCompatiblePattern(token, formatName)
+	if strings.ContainsRune(flags, 's') {
+		pattern = "(?s)" + pattern
+	}
+	r, err := regexp.Compile("^(?:" + pattern + ")$")
+	return err == nil && r.MatchString("\n")
+}
@@ -0,0 +1,277 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAnalyze_SampleDecodesEscapes(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`^\x41\101$`, "AA"},
+		{`caf\x{e9}`, "café"},
+		{`^\Q1+1=2?\E$`, "1+1=2?"},
+	}
+
+	for _, tt := range tests {
+		exp := Analyze(tt.pattern, "go")
+		if exp.Sample != tt.want || exp.SampleStatus != "Verified match" {
+			t.Errorf("Analyze(%q) sample = %q (%s), want verified %q", tt.pattern, exp.Sample, exp.SampleStatus, tt.want)
+		}
+	}
+}
+
+func TestAnalyze_SampleRepeatsBackreferences(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		formatName string
+		want       string
+	}{
+		{`^(\w+)-\1$`, "pcre", "aaa-aaa"},
+		{`(?P<w>[a-z]+) (?P=w)`, "python", "mmm mmm"},
+		{`(?P<w>[a-z]+) (?P=w)`, "pcre", "mmm mmm"},
+		{`(?P<w>[a-z]+) (?P=w)`, "go", "mmm mmm"},
+		{`/(?<n>ab)\k<n>/`, "js", "abab"},
+		{`(a)(b)\g{-1}`, "pcre", "abb"},
+	}
+
+	for _, tt := range tests {
+		exp := Analyze(tt.pattern, tt.formatName)
+		if exp.Sample != tt.want || exp.SampleStatus != "Verified match" {
+			t.Errorf("Analyze(%q) sample = %q (%s), want verified %q", tt.pattern, exp.Sample, exp.SampleStatus, tt.want)
+		}
+	}
+}
+
+func TestQuantifierBounds(t *testing.T) {
+	tests := []struct {
+		token       string
+		least, most int
+		ok          bool
+	}{
+		{"*", 0, -1, true},
+		{"+?", 1, -1, true},
+		{"?", 0, 1, true},
+		{"*+", 0, -1, true},
+		{"{3}", 3, 3, true},
+		{"{2,}", 2, -1, true},
+		{"{2,4}", 2, 4, true},
+		{"{,4}", 0, 0, false},
+		{"a", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		least, most, ok := quantifierBounds(tt.token)
+		if least != tt.least || most != tt.most || ok != tt.ok {
+			t.Errorf("quantifierBounds(%q) = %d, %d, %v, want %d, %d, %v", tt.token, least, most, ok, tt.least, tt.most, tt.ok)
+		}
+	}
+}
+
+func TestAnalyze_SampleHonorsQuantifierBounds(t *testing.T) {
+	tests := []struct {
+		bias    string
+		pattern string
+		want    string
+	}{
+		{SampleBiasTypical, `^a{2,4}$`, "aaa"},
+		{SampleBiasTypical, `^\d{3}-(ab)+$`, "555-ababab"},
+		{SampleBiasMin, `^a{2,4}b*?c?$`, "aa"},
+		{SampleBiasMax, `^a{2,4}b*?c?$`, "aaaabbbc"},
+		{SampleBiasMax, `^x{1,1000}$`, "xxxxxxxxxxxxxxxx"},
+	}
+
+	for _, tt := range tests {
+		exp := AnalyzeWithOptions(tt.pattern, "go", "", ExampleOptions{Bias: tt.bias})
+		if exp.Sample != tt.want || exp.SampleStatus != "Verified match" {
+			t.Errorf("Analyze(%q) with bias %s sample = %q (%s), want verified %q", tt.pattern, tt.bias, exp.Sample, exp.SampleStatus, tt.want)
+		}
+	}
+
+	if err := (ExampleOptions{Bias: "most"}).Validate(); err == nil {
+		t.Errorf("ExampleOptions{Bias: %q}.Validate() = nil, want an error", "most")
+	}
+}
+
+func TestAnalyze_SampleQuantifierAfterGroupOpener(t *testing.T) {
+	// A quantifier right after an opener repeats nothing, rather than the
+	// element before the group
+	tests := []struct {
+		pattern    string
+		formatName string
+		want       string
+	}{
+		{`a(?)`, "go", "a"},
+		{`a(?)`, "pcre", "a"},
+		{`a(?)`, "js", "a"},
+		{`a(?)`, "python", "a"},
+		{`(?P<n>a)(?P=n)`, "js", "P<n>aP=n"},
+		{`a(?i)?b`, "pcre", "ab"},
+	}
+	for _, tt := range tests {
+		if exp := AnalyzeWithOptions(tt.pattern, tt.formatName, "", ExampleOptions{Bias: SampleBiasMin}); exp.Sample != tt.want {
+			t.Errorf("Analyze(%q, %s) sample = %q, want %q", tt.pattern, tt.formatName, exp.Sample, tt.want)
+		}
+	}
+}
+
+func TestAnalyze_SampleUsesClassMembers(t *testing.T) {
+	tests := []struct {
+		pattern string
+		flags   string
+		want    string
+	}{
+		{`^[0-9]$`, "", "7"},
+		{`^[^a-z]$`, "", "M"},
+		{`^[^a-z]$`, "i", "7"},
+		{`^[xyz]$`, "", "x"},
+		{`^[.-]$`, "", "-"},
+		{`^\W\.$`, "", "!."},
+		{`^\p{Greek}$`, "", "Ͱ"},
+	}
+
+	for _, tt := range tests {
+		exp := AnalyzeWithFlags(tt.pattern, "go", tt.flags)
+		if exp.Sample != tt.want || exp.SampleStatus != "Verified match" {
+			t.Errorf("AnalyzeWithFlags(%q, %q) sample = %q (%s), want verified %q", tt.pattern, tt.flags, exp.Sample, exp.SampleStatus, tt.want)
+		}
+	}
+}
+
+func TestAnalyze_SampleAvoidsNegatedClasses(t *testing.T) {
+	tests := []struct {
+		pattern, formatName, want string
+	}{
+		{`^[^abc]$`, "go", "m"},
+		{`^\D\S\W$`, "go", "mm!"},
+		{`^[^\w\s]$`, "python", "!"},
+		{`^[^[:alnum:]]$`, "posix", "!"},
+		{`^[^\h\d]\H$`, "pcre", "mm"},
+		{`^[^m]$`, "js", "M"},
+	}
+
+	for _, tt := range tests {
+		exp := Analyze(tt.pattern, tt.formatName)
+		if exp.Sample != tt.want || exp.SampleStatus != "Verified match" {
+			t.Errorf("Analyze(%q, %q) sample = %q (%s), want verified %q", tt.pattern, tt.formatName, exp.Sample, exp.SampleStatus, tt.want)
+		}
+	}
+}
+
+func TestAnalyze_SampleBreaksLinesAtAnchors(t *testing.T) {
+	tests := []struct {
+		pattern, formatName, flags, want string
+	}{
+		{`(?m)^\w+$\r?\n^\d+$`, "go", "", "aaa\n555"},
+		{`^a$\s+^b$`, "python", "m", "a\n\n\nb"},
+		{`/^a$\R^b$/m`, "pcre", "", "a\nb"},
+		{`(?m)^\w+$(?s:.)^\d`, "go", "", "aaa\n5"},
+		{`(?m)^a$`, "go", "", "a"},
+	}
+
+	for _, tt := range tests {
+		exp := AnalyzeWithFlags(tt.pattern, tt.formatName, tt.flags)
+		if exp.Sample != tt.want || exp.SampleStatus != "Verified match" {
+			t.Errorf("AnalyzeWithFlags(%q, %q, %q) sample = %q (%s), want verified %q", tt.pattern, tt.formatName, tt.flags, exp.Sample, exp.SampleStatus, tt.want)
+		}
+	}
+}
+
+func TestGenerateSampleMatch_ShowsLineBreaks(t *testing.T) {
+	SetColor(false)
+	defer SetColor(true)
+
+	pattern := `(?m)^a$\n^b$`
+	_, tokens, _ := compactVerbose(Analyze(pattern, "go"))
+	got := generateSampleMatch(pattern, "go", "", tokens, []string{""}, ExampleOptions{})
+	if !strings.Contains(got, "a↵\nb\n") {
+		t.Errorf("generateSampleMatch(%q) = %q, want the line break shown as ↵ before a new line", pattern, got)
+	}
+}
+
+func TestFindNamedGroups_SeedReproduces(t *testing.T) {
+	pattern := `(?P<word>\w+)-(?P<num>\d+)`
+	first := FindNamedGroups(pattern, "go", 42)
+	second := FindNamedGroups(pattern, "go", 42)
+	for i := range first {
+		if first[i].Example != second[i].Example {
+			t.Errorf("FindNamedGroups(%q) group %d example = %q, then %q with the same seed", pattern, i, first[i].Example, second[i].Example)
+		}
+	}
+}
+
+func TestAnalyzeWithOptions_Seed(t *testing.T) {
+	pattern := `^\d{1,9}-[a-z]*$`
+	r := regexp.MustCompile(pattern)
+	samples := make(map[string]bool)
+	for seed := int64(1); seed <= 20; seed++ {
+		exp := AnalyzeWithOptions(pattern, "go", "", ExampleOptions{Seed: seed})
+		if !r.MatchString(exp.Sample) {
+			t.Errorf("AnalyzeWithOptions(%q) with seed %d sample = %q, which doesn't match", pattern, seed, exp.Sample)
+		}
+		if exp.Seed != seed {
+			t.Errorf("AnalyzeWithOptions(%q) Seed = %d, want %d", pattern, exp.Seed, seed)
+		}
+		if again := AnalyzeWithOptions(pattern, "go", "", ExampleOptions{Seed: seed}); again.Sample != exp.Sample {
+			t.Errorf("AnalyzeWithOptions(%q) with seed %d sample = %q, then %q", pattern, seed, exp.Sample, again.Sample)
+		}
+		samples[exp.Sample] = true
+	}
+	if len(samples) < 2 {
+		t.Errorf("AnalyzeWithOptions(%q) gave %v for 20 seeds, want the seed to change the sample", pattern, samples)
+	}
+
+	// Without a seed the example match is the middle of each range
+	if exp := Analyze(pattern, "go"); exp.Sample != "55555-mm" || exp.Seed != 0 {
+		t.Errorf("Analyze(%q) = %q with seed %d, want %q without one", pattern, exp.Sample, exp.Seed, "55555-mm")
+	}
+}
+
+func TestAnalyze_SampleFitsMaxLength(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		maxLength int
+		want      string
+		status    string
+	}{
+		{`^\w+ \d*$`, 0, "aaaa 555", "Verified match"},
+		{`^\w+ \d*$`, 6, "aaa 55", "Verified match (repetitions cut to fit 6 characters)"},
+		{`^\w+ \d{2}$`, 40, "aaaa 55", "Verified match"},
+		{`^\d{5}\w*$`, 3, "55555", "Verified match (longer than the 3 characters allowed, even with the fewest repetitions)"},
+	}
+
+	for _, tt := range tests {
+		exp := AnalyzeWithOptions(tt.pattern, "go", "", ExampleOptions{Bias: SampleBiasMax, MaxLength: tt.maxLength})
+		if exp.Sample != tt.want || exp.SampleStatus != tt.status {
+			t.Errorf("Analyze(%q) with max length %d sample = %q (%s), want %q (%s)", tt.pattern, tt.maxLength, exp.Sample, exp.SampleStatus, tt.want, tt.status)
+		}
+	}
+
+	if err := (ExampleOptions{MaxLength: -1}).Validate(); err == nil {
+		t.Errorf("ExampleOptions{MaxLength: -1}.Validate() = nil, want an error")
+	}
+}
+
+func TestAnalyzeWithOptions_Concurrent(t *testing.T) {
+	// The options belong to each call, so concurrent calls don't see each
+	// other's
+	pattern := `^a{2,4}$`
+	want := map[string]string{SampleBiasMin: "aa", SampleBiasMax: "aaaa"}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		for bias := range want {
+			wg.Add(1)
+			go func(bias string) {
+				defer wg.Done()
+				if exp := AnalyzeWithOptions(pattern, "go", "", ExampleOptions{Bias: bias}); exp.Sample != want[bias] {
+					t.Errorf("AnalyzeWithOptions(%q) with bias %s sample = %q, want %q", pattern, bias, exp.Sample, want[bias])
+				}
+			}(bias)
+		}
+	}
+	wg.Wait()
+}
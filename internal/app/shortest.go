@@ -0,0 +1,88 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+// ShortestMatch derives the shortest string a pattern matches from its
+// structure: optional parts left out, repetitions at their minimum, the
+// shortest alternative and a natural member of each class. Flags set
+// outside the pattern are applied as Go understands them. It reports an
+// error when the pattern isn't RE2-compatible or can't match anything.
+func ShortestMatch(pattern, formatName, flags string) (string, error) {
+	r, err := CompilePattern(pattern, formatName, flags)
+	if err != nil {
+		return "", err
+	}
+	parsed, err := syntax.Parse(r.String(), syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+
+	shortest, ok := shortestString(parsed.Simplify())
+	if !ok {
+		return "", errors.New("pattern can't match any string")
+	}
+	if !r.MatchString(shortest) {
+		return "", fmt.Errorf("the shortest string the pattern's structure allows, %q, is ruled out by its anchors or word boundaries", shortest)
+	}
+	return shortest, nil
+}
+
+// shortestString returns the shortest string a parsed pattern matches,
+// ignoring anchors and word boundaries, and reports false for patterns that
+// match nothing, such as an empty class
+func shortestString(re *syntax.Regexp) (string, bool) {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return "", false
+	case syntax.OpLiteral:
+		// Case-insensitive literals are parsed in upper case
+		if re.Flags&syntax.FoldCase != 0 {
+			return strings.ToLower(string(re.Rune)), true
+		}
+		return string(re.Rune), true
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return "", false
+		}
+		return string(preferredMember(re.Rune)), true
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return "x", true
+	case syntax.OpStar, syntax.OpQuest:
+		return "", true
+	case syntax.OpPlus, syntax.OpCapture:
+		return shortestString(re.Sub[0])
+	case syntax.OpRepeat:
+		sub, ok := shortestString(re.Sub[0])
+		if !ok && re.Min > 0 {
+			return "", false
+		}
+		return strings.Repeat(sub, re.Min), true
+	case syntax.OpConcat:
+		var result strings.Builder
+		for _, sub := range re.Sub {
+			text, ok := shortestString(sub)
+			if !ok {
+				return "", false
+			}
+			result.WriteString(text)
+		}
+		return result.String(), true
+	case syntax.OpAlternate:
+		shortest, found := "", false
+		for _, sub := range re.Sub {
+			text, ok := shortestString(sub)
+			if ok && (!found || utf8.RuneCountInString(text) < utf8.RuneCountInString(shortest)) {
+				shortest, found = text, true
+			}
+		}
+		return shortest, found
+	}
+	// Empty matches, anchors and word boundaries match no characters
+	return "", true
+}
@@ -0,0 +1,33 @@
+package app
+
+import "testing"
+
+func TestShortestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, formatName, flags string
+		want                       string
+	}{
+		{`^\d{3}-\d{4}$`, "go", "", "777-7777"},
+		{`^(foo|ba)+x?$`, "go", "", "ba"},
+		{`\d*`, "go", "", ""},
+		{`colou?r`, "go", "", "color"},
+		{`^hello$`, "go", "i", "hello"},
+		{`(?i)HELLO`, "go", "", "hello"},
+		{`[^a-z]{2,}`, "go", "", "MM"},
+		{`/^a.c$/s`, "js", "", "axc"},
+		{`(?<year>\d{4})`, "pcre", "", "7777"},
+	}
+
+	for _, tt := range tests {
+		got, err := ShortestMatch(tt.pattern, tt.formatName, tt.flags)
+		if err != nil || got != tt.want {
+			t.Errorf("ShortestMatch(%q, %q, %q) = %q, %v, want %q", tt.pattern, tt.formatName, tt.flags, got, err, tt.want)
+		}
+	}
+
+	for _, pattern := range []string{`a\bb`, `[^\x00-\x{10FFFF}]`, `(?<=a)b`} {
+		if got, err := ShortestMatch(pattern, "pcre", ""); err == nil {
+			t.Errorf("ShortestMatch(%q) = %q, want an error", pattern, got)
+		}
+	}
+}
@@ -0,0 +1,1071 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// maxEquivalenceStates bounds how many pairs of DFA states comparing two
+// patterns' automata may visit before giving up
+const maxEquivalenceStates = 20000
+
+// placeholderBase is the first of the private use characters that stand in
+// for class escapes while a pattern is simplified, so they're written back
+// as they were, as \d or \p{L} mean different things in different flavors
+const placeholderBase = 0xF0000
+
+// Rewrite is one language-preserving change Simplify made to a pattern
+type Rewrite struct {
+	Rule   string `json:"rule"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Simplification is a pattern rewritten into a shorter one that matches
+// the same strings
+type Simplification struct {
+	Pattern    string    `json:"pattern"`
+	Simplified string    `json:"simplified"`
+	Rewrites   []Rewrite `json:"rewrites"`
+
+	// Proven is set when comparing the automata of both patterns showed
+	// they match the same strings, and Unproven says why they couldn't be
+	// compared otherwise. States is the number of pairs of DFA states
+	// compared.
+	Proven   bool   `json:"proven"`
+	Unproven string `json:"unproven,omitempty"`
+	States   int    `json:"states,omitempty"`
+}
+
+// Simplify rewrites a pattern into a shorter one that matches the same
+// strings: groups that don't need to be there are dropped, literals and
+// single-character alternatives merged, nested quantifiers collapsed,
+// repeated elements counted and duplicate alternatives removed. Capturing
+// groups are kept unless dropCaptures is set, as removing them renumbers
+// the rest. Both patterns are then compiled to automata and compared, which
+// proves them equivalent unless the pattern uses multi-line anchors or word
+// boundaries, whose meaning depends on the text around them.
+func Simplify(pattern, formatName, flags string, dropCaptures bool) (*Simplification, error) {
+	switch formatName {
+	case "go", "pcre", "python", "js", "posix":
+	default:
+		return nil, fmt.Errorf("simplify doesn't support the %s format", formatName)
+	}
+	exp := AnalyzeWithFlags(pattern, formatName, flags)
+	body, prefix, suffix, ambient, err := unwrapPattern(exp)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &regexPrinter{formatName: formatName, placeholders: map[rune]string{}, slash: prefix == "/"}
+	re, err := p.parse(body, ambient)
+	if err != nil {
+		return nil, fmt.Errorf("pattern can't be simplified: %w", err)
+	}
+
+	result := &Simplification{Pattern: pattern, Simplified: pattern}
+	canonical := p.print(re)
+	if canonical != body && utf8.RuneCountInString(canonical) < utf8.RuneCountInString(body) {
+		result.Rewrites = append(result.Rewrites, Rewrite{"Dropped redundant groups and merged literals and alternatives", body, canonical})
+	}
+	s := &simplifier{printer: p, dropCaptures: dropCaptures}
+	for pass := 0; pass < 10; pass++ {
+		changed := len(s.rewrites)
+		re = s.simplify(re)
+		if len(s.rewrites) == changed {
+			break
+		}
+	}
+	result.Rewrites = append(result.Rewrites, s.rewrites...)
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	simplified := p.print(re)
+	if p.err != nil {
+		return nil, p.err
+	}
+	if utf8.RuneCountInString(simplified) >= utf8.RuneCountInString(body) {
+		result.Rewrites = nil
+		return result, nil
+	}
+	result.Simplified = prefix + simplified + suffix
+
+	equal, witness, states, err := equivalentPatterns(pattern, result.Simplified, formatName, flags)
+	switch {
+	case err != nil:
+		result.Unproven = err.Error()
+	case !equal:
+		return nil, fmt.Errorf("simplifying %s to %s would change what it matches, such as %q; please report this", pattern, result.Simplified, witness)
+	default:
+		result.Proven, result.States = true, states
+	}
+	return result, nil
+}
+
+// unwrapPattern returns the regex inside a pattern's delimiters, string
+// prefix or JavaScript literal, with comments and whitespace removed in
+// verbose mode, the text before and after it and the flags it's compiled
+// with, including those after the delimiters
+func unwrapPattern(exp *Explanation) (string, string, string, string, error) {
+	body, _, flags := compactVerbose(exp)
+	pattern := exp.Pattern
+	switch exp.FormatName {
+	case "pcre":
+		if inner, modifiers, ok := format.PcreDelimited(pattern); ok {
+			return body, pattern[:len(pattern)-len(inner)-len(modifiers)], modifiers, flags, nil
+		}
+	case "js":
+		if end := strings.LastIndex(body, "/"); len(body) > 1 && body[0] == '/' && end > 0 {
+			return body[1:end], "/", body[end:], flags + body[end+1:], nil
+		}
+	case "python":
+		if prefix := format.PythonStringPrefix(body); prefix != "" {
+			if !strings.ContainsAny(prefix, "rR") {
+				return "", "", "", "", errors.New("only Python patterns in raw strings, such as r\"...\", can be rewritten")
+			}
+			quote := prefix[len(prefix)-1:]
+			return strings.TrimSuffix(body[len(prefix):], quote), prefix, quote, flags, nil
+		}
+	}
+	return body, "", "", flags, nil
+}
+
+// simplifier applies language-preserving rewrites to a syntax tree,
+// recording each one
+type simplifier struct {
+	printer      *regexPrinter
+	dropCaptures bool
+	rewrites     []Rewrite
+}
+
+// record notes that a rewrite replaced before with after
+func (s *simplifier) record(rule string, before, after *syntax.Regexp) {
+	s.rewrites = append(s.rewrites, Rewrite{rule, s.printer.print(before), s.printer.print(after)})
+}
+
+// simplify rewrites the subexpressions of re and then re itself
+func (s *simplifier) simplify(re *syntax.Regexp) *syntax.Regexp {
+	for i, sub := range re.Sub {
+		re.Sub[i] = s.simplify(sub)
+	}
+	switch re.Op {
+	case syntax.OpCapture:
+		if s.dropCaptures {
+			s.record("Removed a capturing group", re, re.Sub[0])
+			return re.Sub[0]
+		}
+	case syntax.OpRepeat:
+		return s.simplifyRepeat(re)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest:
+		return s.collapseQuantifiers(re)
+	case syntax.OpAlternate:
+		return s.dedupAlternation(re)
+	case syntax.OpConcat:
+		return s.mergeRepeats(re, re.Sub)
+	case syntax.OpLiteral:
+		if len(re.Rune) > 1 {
+			return s.mergeRepeats(re, []*syntax.Regexp{re})
+		}
+	}
+	return re
+}
+
+// simplifyRepeat writes counted repetitions that have a shorter spelling,
+// such as x{0,1} and x{1,}, with it, and multiplies out nested exact counts
+func (s *simplifier) simplifyRepeat(re *syntax.Regexp) *syntax.Regexp {
+	sub := re.Sub[0]
+	if sub.Op == syntax.OpRepeat && re.Min == re.Max && sub.Min == sub.Max && re.Min*sub.Min <= 1000 {
+		collapsed := &syntax.Regexp{Op: syntax.OpRepeat, Flags: re.Flags, Min: re.Min * sub.Min, Max: re.Min * sub.Min, Sub: sub.Sub}
+		s.record("Collapsed nested quantifiers", re, collapsed)
+		return collapsed
+	}
+	shorter := quantify(sub, re.Min, re.Max, re.Flags)
+	if shorter.Op != syntax.OpRepeat {
+		s.record("Shortened a counted repetition", re, shorter)
+	}
+	return shorter
+}
+
+// nestedQuantifiers gives the quantifier a quantifier of a quantified
+// element collapses to, such as (?:x+)* to x*, by the outer and inner
+// quantifiers
+var nestedQuantifiers = map[[2]syntax.Op]syntax.Op{
+	{syntax.OpStar, syntax.OpStar}: syntax.OpStar, {syntax.OpStar, syntax.OpPlus}: syntax.OpStar, {syntax.OpStar, syntax.OpQuest}: syntax.OpStar,
+	{syntax.OpPlus, syntax.OpStar}: syntax.OpStar, {syntax.OpPlus, syntax.OpPlus}: syntax.OpPlus, {syntax.OpPlus, syntax.OpQuest}: syntax.OpStar,
+	{syntax.OpQuest, syntax.OpStar}: syntax.OpStar, {syntax.OpQuest, syntax.OpPlus}: syntax.OpStar, {syntax.OpQuest, syntax.OpQuest}: syntax.OpQuest,
+}
+
+// collapseQuantifiers collapses a quantifier of a quantified element into
+// one quantifier, when both are greedy or both lazy
+func (s *simplifier) collapseQuantifiers(re *syntax.Regexp) *syntax.Regexp {
+	sub := re.Sub[0]
+	op, ok := nestedQuantifiers[[2]syntax.Op{re.Op, sub.Op}]
+	if !ok || re.Flags&syntax.NonGreedy != sub.Flags&syntax.NonGreedy {
+		return re
+	}
+	collapsed := &syntax.Regexp{Op: op, Flags: re.Flags, Sub: sub.Sub}
+	s.record("Collapsed nested quantifiers", re, collapsed)
+	return collapsed
+}
+
+// dedupAlternation removes alternatives that repeat an earlier one, which
+// could never match anything the earlier one doesn't
+func (s *simplifier) dedupAlternation(re *syntax.Regexp) *syntax.Regexp {
+	var kept []*syntax.Regexp
+	for _, sub := range re.Sub {
+		duplicate := false
+		for _, earlier := range kept {
+			duplicate = duplicate || earlier.Equal(sub)
+		}
+		if !duplicate {
+			kept = append(kept, sub)
+		}
+	}
+	if len(kept) == len(re.Sub) {
+		return re
+	}
+	deduped := kept[0]
+	if len(kept) > 1 {
+		deduped = &syntax.Regexp{Op: syntax.OpAlternate, Flags: re.Flags, Sub: kept}
+	}
+	s.record("Removed duplicate alternatives", re, deduped)
+	return deduped
+}
+
+// element is a part of a concatenation as an atom repeated from min to max
+// times, with max -1 for no limit
+type element struct {
+	atom     *syntax.Regexp
+	min, max int
+	flags    syntax.Flags
+}
+
+// mergeRepeats counts runs of the same element in a concatenation or a
+// literal of its subs, such as \d\d\d as \d{3} and xx* as x+, when that's
+// shorter
+func (s *simplifier) mergeRepeats(re *syntax.Regexp, subs []*syntax.Regexp) *syntax.Regexp {
+	var elements []element
+	for _, sub := range subs {
+		switch sub.Op {
+		case syntax.OpLiteral:
+			for _, r := range sub.Rune {
+				elements = append(elements, element{&syntax.Regexp{Op: syntax.OpLiteral, Flags: sub.Flags, Rune: []rune{r}}, 1, 1, sub.Flags})
+			}
+		case syntax.OpStar:
+			elements = append(elements, element{sub.Sub[0], 0, -1, sub.Flags})
+		case syntax.OpPlus:
+			elements = append(elements, element{sub.Sub[0], 1, -1, sub.Flags})
+		case syntax.OpQuest:
+			elements = append(elements, element{sub.Sub[0], 0, 1, sub.Flags})
+		case syntax.OpRepeat:
+			elements = append(elements, element{sub.Sub[0], sub.Min, sub.Max, sub.Flags})
+		default:
+			elements = append(elements, element{sub, 1, 1, sub.Flags})
+		}
+	}
+
+	subs = nil
+	changed := false
+	for i := 0; i < len(elements); {
+		// A run can mix greedy and lazy elements only through exact counts,
+		// which are neither
+		run := elements[i]
+		lazy := run.min != run.max && run.flags&syntax.NonGreedy != 0
+		j := i + 1
+		for ; j < len(elements) && elements[j].atom.Equal(run.atom); j++ {
+			next := elements[j]
+			nextLazy := next.min != next.max && next.flags&syntax.NonGreedy != 0
+			if next.min != next.max && run.min != run.max && nextLazy != lazy {
+				break
+			}
+			lazy = lazy || nextLazy
+			run.min += next.min
+			if run.max < 0 || next.max < 0 {
+				run.max = -1
+			} else {
+				run.max += next.max
+			}
+		}
+		if run.flags &^= syntax.NonGreedy; lazy {
+			run.flags |= syntax.NonGreedy
+		}
+
+		parts := make([]*syntax.Regexp, 0, j-i)
+		for _, e := range elements[i:j] {
+			parts = append(parts, quantify(e.atom, e.min, e.max, e.flags))
+		}
+		if j-i > 1 && run.max <= 1000 {
+			merged := quantify(run.atom, run.min, run.max, run.flags)
+			before := &syntax.Regexp{Op: syntax.OpConcat, Sub: parts}
+			if utf8.RuneCountInString(s.printer.print(merged)) < utf8.RuneCountInString(s.printer.print(before)) {
+				s.record("Counted repeated elements", before, merged)
+				subs, changed = append(subs, merged), true
+				i = j
+				continue
+			}
+		}
+		subs = append(subs, parts...)
+		i = j
+	}
+	if !changed {
+		return re
+	}
+
+	// Put the literals split up to be counted back together
+	var joined []*syntax.Regexp
+	for _, sub := range subs {
+		if n := len(joined); n > 0 && sub.Op == syntax.OpLiteral && joined[n-1].Op == syntax.OpLiteral && joined[n-1].Flags == sub.Flags {
+			joined[n-1].Rune = append(joined[n-1].Rune, sub.Rune...)
+			continue
+		}
+		if sub.Op == syntax.OpLiteral {
+			sub = &syntax.Regexp{Op: syntax.OpLiteral, Flags: sub.Flags, Rune: append([]rune(nil), sub.Rune...)}
+		}
+		joined = append(joined, sub)
+	}
+	if len(joined) == 1 {
+		return joined[0]
+	}
+	return &syntax.Regexp{Op: syntax.OpConcat, Sub: joined}
+}
+
+// quantify returns atom repeated from min to max times, with max -1 for no
+// limit, in its shortest form
+func quantify(atom *syntax.Regexp, min, max int, flags syntax.Flags) *syntax.Regexp {
+	var op syntax.Op
+	switch {
+	case min == 1 && max == 1:
+		return atom
+	case min == 0 && max == 0:
+		return &syntax.Regexp{Op: syntax.OpEmptyMatch}
+	case min == 0 && max == -1:
+		op = syntax.OpStar
+	case min == 1 && max == -1:
+		op = syntax.OpPlus
+	case min == 0 && max == 1:
+		op = syntax.OpQuest
+	default:
+		return &syntax.Regexp{Op: syntax.OpRepeat, Flags: flags, Min: min, Max: max, Sub: []*syntax.Regexp{atom}}
+	}
+	return &syntax.Regexp{Op: op, Flags: flags, Sub: []*syntax.Regexp{atom}}
+}
+
+// equivalentPatterns compares the automata of two patterns, reporting
+// whether they match the same strings as a whole, a string only one of them
+// matches if not, and how many pairs of states were compared
+func equivalentPatterns(a, b, formatName, flags string) (bool, string, int, error) {
+	progA, err := automaton(a, formatName, flags)
+	if err != nil {
+		return false, "", 0, err
+	}
+	progB, err := automaton(b, formatName, flags)
+	if err != nil {
+		return false, "", 0, err
+	}
+	return equivalentAutomata(progA, progB)
+}
+
+// equivalentAutomata compares two automata as equivalentPatterns does
+func equivalentAutomata(progA, progB *syntax.Prog) (bool, string, int, error) {
+	// Walk the pairs of DFA states both automata reach on the same input,
+	// breadth first so the first difference comes with the shortest input
+	type pair struct {
+		a, b  []uint32
+		input string
+	}
+	classes := runeClasses(progA, progB)
+	start := pair{nfaClosure(progA, []uint32{uint32(progA.Start)}, true), nfaClosure(progB, []uint32{uint32(progB.Start)}, true), ""}
+	seen := map[string]bool{stateKey(start.a) + "|" + stateKey(start.b): true}
+	for queue := []pair{start}; len(queue) > 0; queue = queue[1:] {
+		current := queue[0]
+		if nfaAccepts(progA, current.a) != nfaAccepts(progB, current.b) {
+			return false, current.input, len(seen), nil
+		}
+		for _, class := range classes {
+			next := pair{nfaStep(progA, current.a, class.lo), nfaStep(progB, current.b, class.lo), current.input + string(class.lo)}
+			key := stateKey(next.a) + "|" + stateKey(next.b)
+			if len(next.a) == 0 && len(next.b) == 0 || seen[key] {
+				continue
+			}
+			if len(seen) == maxEquivalenceStates {
+				return false, "", len(seen), fmt.Errorf("the automata have more than %d pairs of states to compare", maxEquivalenceStates)
+			}
+			seen[key] = true
+			queue = append(queue, next)
+		}
+	}
+	return true, "", len(seen), nil
+}
+
+// automaton compiles a pattern to the program of its automaton. It's read
+// as the printer reads it, with each class escape expanded, so a proof
+// can't rest on a construct the flavor doesn't have.
+func automaton(pattern, formatName, flags string) (*syntax.Prog, error) {
+	body, _, _, ambient, err := unwrapPattern(AnalyzeWithFlags(pattern, formatName, flags))
+	if err != nil {
+		return nil, err
+	}
+	p := &regexPrinter{formatName: formatName, placeholders: map[rune]string{}, expand: func(string) bool { return true }}
+	parsed, err := p.parse(body, ambient)
+	if err != nil {
+		return nil, err
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, fmt.Errorf("pattern can't be converted to an automaton: %v", err)
+	}
+	for _, inst := range prog.Inst {
+		if inst.Op == syntax.InstEmptyWidth && syntax.EmptyOp(inst.Arg)&^(syntax.EmptyBeginText|syntax.EmptyEndText) != 0 {
+			return nil, errors.New("patterns with multi-line anchors or word boundaries can't be compared as automata")
+		}
+	}
+	return prog, nil
+}
+
+// Precedences of the parts of a pattern, from an alternation, which binds
+// loosest, to an atom
+const (
+	precAlternate = iota
+	precConcat
+	precRepeat
+	precAtom
+)
+
+// regexPrinter writes a syntax tree back as a pattern in a flavor, as
+// compiled with the flags it was parsed with
+type regexPrinter struct {
+	formatName                           string
+	foldCase, multiLine, dotAll, verbose bool
+
+	// slash is set inside a JavaScript /.../ literal, where / is escaped
+	slash bool
+
+	// placeholders maps the characters standing in for class escapes to
+	// how they were written, and expand, when set, picks the escapes left
+	// for the parser to expand into the characters they match instead, for
+	// a flavor that doesn't have them
+	placeholders map[rune]string
+	expand       func(escape string) bool
+
+	// err is the first construct the flavor has no way of writing
+	err error
+}
+
+// parse parses the regex inside a pattern of the printer's flavor, compiled
+// with the flags it was given outside the pattern, which the printer then
+// writes it as holding throughout
+func (p *regexPrinter) parse(body, flags string) (*syntax.Regexp, error) {
+	p.setFlags(flags)
+	substituted, err := p.substitute(body)
+	if err != nil {
+		return nil, err
+	}
+	parseFlags := syntax.Perl
+	if p.foldCase {
+		parseFlags |= syntax.FoldCase
+	}
+	if p.multiLine {
+		parseFlags &^= syntax.OneLine
+	}
+	if p.dotAll {
+		parseFlags |= syntax.DotNL
+	}
+	converted := goCompatiblePattern(substituted, p.formatName)
+	re, err := syntax.Parse(converted, parseFlags)
+	if err != nil {
+		return nil, goSyntaxError(converted, err)
+	}
+	return re, nil
+}
+
+// substitute replaces the class escapes of a pattern, such as \d, \pL and
+// [:alpha:], with placeholder characters, which the printer writes back. Only
+// the constructs the flavor has are replaced, and the rest of the pattern is
+// rewritten where Go's parser would read it differently from the flavor, or
+// refused where the flavor leaves its meaning undefined.
+func (p *regexPrinter) substitute(pattern string) (string, error) {
+	escapes := "dDwWsS"
+	switch p.formatName {
+	case "pcre":
+		escapes += "hHvVN"
+	case "posix":
+		escapes = ""
+	}
+	posixClasses := p.formatName == "go" || p.formatName == "pcre" || p.formatName == "posix"
+	unreadable := func(construct string) error {
+		return fmt.Errorf("%s has no %s, so the pattern can't be read the way it would be", format.GetFormat(p.formatName).Name(), construct)
+	}
+	spellings := map[string]rune{}
+	placeholder := func(spelling string) string {
+		if p.expand != nil && p.expand(spelling) {
+			return spelling
+		}
+		r, ok := spellings[spelling]
+		if !ok {
+			r = placeholderBase + rune(len(spellings))
+			spellings[spelling] = r
+			p.placeholders[r] = spelling
+		}
+		return string(r)
+	}
+
+	var result strings.Builder
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if r, _ := utf8.DecodeRuneInString(pattern[i:]); r >= placeholderBase && r <= unicode.MaxRune {
+			return "", errors.New("pattern has characters from the supplementary private use area, which simplify can't handle")
+		}
+		switch {
+		case p.formatName == "posix" && inClass && c == '\\':
+			// A backslash in a POSIX bracket expression is a member
+			result.WriteString(`\\`)
+		case p.formatName == "posix" && c == '\\' && i+1 < len(pattern) && strings.IndexByte("ntr", pattern[i+1]) < 0 &&
+			(unicode.IsLetter(rune(pattern[i+1])) || unicode.IsDigit(rune(pattern[i+1]))):
+			return "", unreadable(pattern[i:i+2] + " escape")
+		case p.formatName == "posix" && !inClass && strings.HasPrefix(pattern[i:], "(?"):
+			return "", unreadable("(? groups")
+		case p.formatName == "posix" && !inClass && i > 0 && c == '?' && strings.IndexByte("*+?}", pattern[i-1]) >= 0 && (i < 2 || pattern[i-2] != '\\'):
+			return "", unreadable("lazy quantifiers")
+		case p.formatName == "python" && !inClass && strings.HasPrefix(pattern[i:], "{,") && i > 0:
+			// Python reads {,n} as {0,n}, which Go reads as text
+			if end := strings.IndexByte(pattern[i:], '}'); end > 2 && strings.Trim(pattern[i+2:i+end], "0123456789") == "" {
+				result.WriteString("{0,")
+				i++
+				continue
+			}
+			result.WriteByte(c)
+		case c == '\\' && i+1 < len(pattern) && pattern[i+1] == 'Q' && (p.formatName == "go" || p.formatName == "pcre"):
+			end := strings.Index(pattern[i+2:], `\E`)
+			if end < 0 {
+				end = len(pattern) - i - 2
+			} else {
+				end += 2
+			}
+			result.WriteString(pattern[i : i+2+end])
+			i += 1 + end
+		case c == '\\' && i+1 < len(pattern) && strings.IndexByte(escapes, pattern[i+1]) >= 0:
+			result.WriteString(placeholder(pattern[i : i+2]))
+			i++
+		case c == '\\' && i+2 < len(pattern) && (pattern[i+1] == 'p' || pattern[i+1] == 'P') && p.formatName != "posix":
+			end := i + 3
+			if pattern[i+2] == '{' {
+				if close := strings.IndexByte(pattern[i:], '}'); close > 0 {
+					end = i + close + 1
+				}
+			}
+			result.WriteString(placeholder(pattern[i:end]))
+			i = end - 1
+		case c == '\\' && i+1 < len(pattern):
+			result.WriteString(pattern[i : i+2])
+			i++
+		case !inClass && c == '[':
+			inClass = true
+			result.WriteByte(c)
+			// A ] first in the class, after any ^, is a member
+			if strings.HasPrefix(pattern[i+1:], "^") {
+				result.WriteByte('^')
+				i++
+			}
+			if strings.HasPrefix(pattern[i+1:], "]") {
+				result.WriteByte(']')
+				i++
+			}
+		case inClass && c == '[' && !posixClasses:
+			// Only Go, PCRE and POSIX have classes such as [:alpha:], so
+			// elsewhere a [ in a class is a member
+			result.WriteString(`\[`)
+		case inClass && strings.HasPrefix(pattern[i:], "[:"):
+			if end := strings.Index(pattern[i+2:], ":]"); end >= 0 {
+				result.WriteString(placeholder(pattern[i : i+end+4]))
+				i += end + 3
+				continue
+			}
+			result.WriteByte(c)
+		case inClass && c == ']':
+			inClass = false
+			result.WriteByte(c)
+		default:
+			result.WriteByte(c)
+		}
+	}
+	return result.String(), nil
+}
+
+// print writes a syntax tree as a pattern, starting with modifiers such as
+// (?i) for flags that hold throughout it but weren't given outside it
+func (p *regexPrinter) print(re *syntax.Regexp) string {
+	var b strings.Builder
+	if p.formatName != "js" && p.formatName != "posix" {
+		if modifiers := p.throughout(re); modifiers != "" {
+			saved := *p
+			p.setFlags(modifiers)
+			b.WriteString("(?" + modifiers + ")")
+			defer func() { p.foldCase, p.multiLine, p.dotAll = saved.foldCase, saved.multiLine, saved.dotAll }()
+		}
+	}
+	p.write(&b, re, precAlternate)
+	return b.String()
+}
+
+// throughout returns the modifiers among i, m and s that hold throughout a
+// syntax tree, and that the printer doesn't already write it with
+func (p *regexPrinter) throughout(re *syntax.Regexp) string {
+	var modifiers string
+	uses := map[syntax.Op]bool{}
+	folded, unfolded := false, false
+	var walk func(re *syntax.Regexp)
+	walk = func(re *syntax.Regexp) {
+		uses[re.Op] = true
+		if re.Op == syntax.OpLiteral || re.Op == syntax.OpCharClass {
+			folded = folded || re.Flags&syntax.FoldCase != 0
+			unfolded = unfolded || re.Flags&syntax.FoldCase == 0
+		}
+		for _, sub := range re.Sub {
+			walk(sub)
+		}
+	}
+	walk(re)
+
+	if folded && !unfolded && !p.foldCase {
+		modifiers += "i"
+	}
+	if (uses[syntax.OpBeginLine] || uses[syntax.OpEndLine]) && !uses[syntax.OpBeginText] && !uses[syntax.OpEndText] && !p.multiLine {
+		modifiers += "m"
+	}
+	if uses[syntax.OpAnyChar] && !uses[syntax.OpAnyCharNotNL] && !p.dotAll {
+		modifiers += "s"
+	}
+	return modifiers
+}
+
+// setFlags sets the flags the printer writes a pattern as compiled with
+func (p *regexPrinter) setFlags(flags string) {
+	for _, flag := range flags {
+		switch flag {
+		case 'i':
+			p.foldCase = true
+		case 'm':
+			p.multiLine = true
+		case 's':
+			p.dotAll = true
+		case 'x':
+			p.verbose = true
+		}
+	}
+}
+
+// fail records that the flavor can't write a construct
+func (p *regexPrinter) fail(construct string) {
+	if p.err == nil {
+		p.err = fmt.Errorf("the pattern would need %s, which %s doesn't have", construct, format.GetFormat(p.formatName).Name())
+	}
+}
+
+// group writes a non-capturing group around what write writes
+func (p *regexPrinter) group(b *strings.Builder, write func()) {
+	if p.formatName == "posix" {
+		// POSIX has no non-capturing groups
+		b.WriteByte('(')
+	} else {
+		b.WriteString("(?:")
+	}
+	write()
+	b.WriteByte(')')
+}
+
+// scoped writes a group with an inline modifier around what write writes,
+// such as (?i:...)
+func (p *regexPrinter) scoped(b *strings.Builder, modifier string, write func()) {
+	if p.formatName == "js" || p.formatName == "posix" {
+		p.fail("the inline modifier (?" + modifier + ":...)")
+	}
+	b.WriteString("(?" + modifier + ":")
+	write()
+	b.WriteByte(')')
+}
+
+// write writes re where the surrounding pattern needs a part binding at
+// least as tightly as prec
+func (p *regexPrinter) write(b *strings.Builder, re *syntax.Regexp, prec int) {
+	switch re.Op {
+	case syntax.OpAlternate:
+		if prec > precAlternate {
+			p.group(b, func() { p.write(b, re, precAlternate) })
+			return
+		}
+		for i, sub := range re.Sub {
+			if i > 0 {
+				b.WriteByte('|')
+			}
+			p.write(b, sub, precConcat)
+		}
+	case syntax.OpConcat:
+		if prec > precConcat {
+			p.group(b, func() { p.write(b, re, precConcat) })
+			return
+		}
+		for _, sub := range re.Sub {
+			if sub.Op != syntax.OpEmptyMatch {
+				p.write(b, sub, precConcat)
+			}
+		}
+	case syntax.OpLiteral:
+		fold := re.Flags&syntax.FoldCase != 0
+		switch {
+		case fold != p.foldCase:
+			modifier := "i"
+			if !fold {
+				modifier = "-i"
+			}
+			p.scoped(b, modifier, func() { p.writeLiteral(b, re.Rune, fold) })
+		case len(re.Rune) > 1 && prec > precConcat:
+			p.group(b, func() { p.writeLiteral(b, re.Rune, fold) })
+		default:
+			p.writeLiteral(b, re.Rune, fold)
+		}
+	case syntax.OpCharClass:
+		p.writeClass(b, re.Rune)
+	case syntax.OpAnyCharNotNL:
+		if p.dotAll {
+			b.WriteString(`[^\n]`)
+		} else {
+			b.WriteByte('.')
+		}
+	case syntax.OpAnyChar:
+		if p.dotAll {
+			b.WriteByte('.')
+		} else {
+			b.WriteString(`[\s\S]`)
+		}
+	case syntax.OpBeginLine, syntax.OpEndLine:
+		anchor := "^"
+		if re.Op == syntax.OpEndLine {
+			anchor = "$"
+		}
+		if p.multiLine {
+			b.WriteString(anchor)
+		} else {
+			p.scoped(b, "m", func() { b.WriteString(anchor) })
+		}
+	case syntax.OpBeginText:
+		switch {
+		case !p.multiLine:
+			b.WriteByte('^')
+		case p.formatName == "js" || p.formatName == "posix":
+			p.fail(`\A`)
+		default:
+			b.WriteString(`\A`)
+		}
+	case syntax.OpEndText:
+		switch {
+		case re.Flags&syntax.WasDollar != 0 && !p.multiLine:
+			b.WriteByte('$')
+		case re.Flags&syntax.WasDollar != 0:
+			p.scoped(b, "-m", func() { b.WriteByte('$') })
+		case p.formatName == "python":
+			b.WriteString(`\Z`)
+		case p.formatName == "js" || p.formatName == "posix":
+			p.fail(`\z`)
+		default:
+			b.WriteString(`\z`)
+		}
+	case syntax.OpWordBoundary:
+		b.WriteString(`\b`)
+	case syntax.OpNoWordBoundary:
+		b.WriteString(`\B`)
+	case syntax.OpEmptyMatch:
+		p.group(b, func() {})
+	case syntax.OpNoMatch:
+		p.writeClass(b, nil)
+	case syntax.OpCapture:
+		switch {
+		case re.Name == "":
+			b.WriteByte('(')
+		case p.formatName == "posix":
+			p.fail("the named group " + re.Name)
+		case p.formatName == "go" || p.formatName == "python":
+			b.WriteString("(?P<" + re.Name + ">")
+		default:
+			b.WriteString("(?<" + re.Name + ">")
+		}
+		p.write(b, re.Sub[0], precAlternate)
+		b.WriteByte(')')
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		if prec > precRepeat {
+			p.group(b, func() { p.write(b, re, precRepeat) })
+			return
+		}
+		p.write(b, re.Sub[0], precAtom)
+		switch re.Op {
+		case syntax.OpStar:
+			b.WriteByte('*')
+		case syntax.OpPlus:
+			b.WriteByte('+')
+		case syntax.OpQuest:
+			b.WriteByte('?')
+		default:
+			switch {
+			case re.Min == re.Max:
+				fmt.Fprintf(b, "{%d}", re.Min)
+			case re.Max < 0:
+				fmt.Fprintf(b, "{%d,}", re.Min)
+			default:
+				fmt.Fprintf(b, "{%d,%d}", re.Min, re.Max)
+			}
+		}
+		if re.Flags&syntax.NonGreedy != 0 {
+			if p.formatName == "posix" {
+				p.fail("a lazy quantifier")
+			}
+			b.WriteByte('?')
+		}
+	}
+}
+
+// writeLiteral writes the characters of a literal, as their lowercase
+// forms when case doesn't matter
+func (p *regexPrinter) writeLiteral(b *strings.Builder, runes []rune, fold bool) {
+	for _, r := range runes {
+		if spelling, ok := p.placeholders[r]; ok {
+			if strings.HasPrefix(spelling, "[:") {
+				spelling = "[" + spelling + "]"
+			}
+			b.WriteString(spelling)
+			continue
+		}
+		if fold {
+			r = foldCanonical(r)
+		}
+		switch {
+		case strings.ContainsRune(`\.+*?()|[]{}^$`, r), p.slash && r == '/', p.verbose && (r == ' ' || r == '#'):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			p.writeRune(b, r)
+		}
+	}
+}
+
+// foldCanonical returns the lowercase form of a character among those it
+// matches case-insensitively, or its smallest one if none is lowercase
+func foldCanonical(r rune) rune {
+	orbit := foldOrbit(r)
+	smallest := r
+	for _, f := range orbit {
+		smallest = min(smallest, f)
+	}
+	lower := unicode.ToLower(smallest)
+	for _, f := range orbit {
+		if f == lower {
+			return lower
+		}
+	}
+	return smallest
+}
+
+// writeClass writes a character class from its ranges, negated when that's
+// shorter and without the other cases of letters when case doesn't matter
+func (p *regexPrinter) writeClass(b *strings.Builder, ranges []rune) {
+	negated := len(ranges) > 0 && ranges[0] == 0 && ranges[len(ranges)-1] == unicode.MaxRune
+	if negated {
+		ranges = complementRanges(ranges)
+	}
+	if len(ranges) == 0 {
+		if negated {
+			// The class matches any character
+			p.write(b, &syntax.Regexp{Op: syntax.OpAnyChar}, precAtom)
+			return
+		}
+		switch p.formatName {
+		case "js":
+			b.WriteString("[]")
+		case "python":
+			b.WriteString(`[^\x00-\U0010FFFF]`)
+		default:
+			b.WriteString(`[^\x00-\x{10FFFF}]`)
+		}
+		return
+	}
+	if negated && len(ranges) == 2 && ranges[0] == '\n' && ranges[1] == '\n' && !p.dotAll {
+		b.WriteByte('.')
+		return
+	}
+	if p.foldCase {
+		ranges = unfoldRanges(ranges)
+	}
+
+	b.WriteByte('[')
+	if negated {
+		b.WriteByte('^')
+	}
+	hyphen := false
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		switch {
+		case lo == '-' && hi == '-':
+			// A hyphen needs no escaping last in the class
+			hyphen = true
+			continue
+		case lo == '-':
+			hyphen, lo = true, lo+1
+		case hi == '-':
+			hyphen, hi = true, hi-1
+		}
+		p.writeClassRune(b, lo)
+		switch {
+		case hi == lo:
+		case hi == lo+1:
+			p.writeClassRune(b, hi)
+		default:
+			b.WriteByte('-')
+			p.writeClassRune(b, hi)
+		}
+	}
+	if hyphen {
+		b.WriteByte('-')
+	}
+	b.WriteByte(']')
+}
+
+// writeClassRune writes a character inside a class
+func (p *regexPrinter) writeClassRune(b *strings.Builder, r rune) {
+	if spelling, ok := p.placeholders[r]; ok {
+		b.WriteString(spelling)
+		return
+	}
+	if p.formatName == "posix" && strings.ContainsRune(`\]-[^`, r) {
+		// POSIX bracket expressions have no escapes, so a backslash is a
+		// member as it is, and the other characters would need the members
+		// put in an order the printer doesn't manage
+		if r == '\\' || r == '-' {
+			b.WriteRune(r)
+		} else {
+			p.fail(fmt.Sprintf("%q in a bracket expression", r))
+		}
+		return
+	}
+	if strings.ContainsRune(`\]-[^`, r) || p.slash && r == '/' {
+		b.WriteByte('\\')
+		b.WriteRune(r)
+		return
+	}
+	p.writeRune(b, r)
+}
+
+// writeRune writes a character that isn't special in the pattern,
+// escaping it if it can't be seen
+func (p *regexPrinter) writeRune(b *strings.Builder, r rune) {
+	escapes := map[rune]string{'\n': `\n`, '\t': `\t`, '\r': `\r`, '\f': `\f`, '\v': `\v`}
+	switch {
+	case p.formatName == "posix" || unicode.IsPrint(r) && r != ' ':
+		b.WriteRune(r)
+	case escapes[r] != "":
+		b.WriteString(escapes[r])
+	case r < 0x100:
+		fmt.Fprintf(b, `\x%02X`, r)
+	case p.formatName == "python" && r > 0xFFFF:
+		fmt.Fprintf(b, `\U%08X`, r)
+	case p.formatName == "python" || p.formatName == "js" && r <= 0xFFFF:
+		fmt.Fprintf(b, `\u%04X`, r)
+	case p.formatName == "js":
+		b.WriteRune(r)
+	default:
+		fmt.Fprintf(b, `\x{%X}`, r)
+	}
+}
+
+// complementRanges returns the ranges of the characters not in ranges
+func complementRanges(ranges []rune) []rune {
+	var complement []rune
+	next := rune(0)
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if ranges[i] > next {
+			complement = append(complement, next, ranges[i]-1)
+		}
+		next = ranges[i+1] + 1
+	}
+	if next <= unicode.MaxRune {
+		complement = append(complement, next, unicode.MaxRune)
+	}
+	return complement
+}
+
+// unfoldRanges drops the other cases of letters from the ranges of a class
+// matched case-insensitively, which Go's parser adds, when the remaining
+// characters match the same ones. Large classes are left as they are.
+func unfoldRanges(ranges []rune) []rune {
+	size := 0
+	for i := 0; i+1 < len(ranges); i += 2 {
+		size += int(ranges[i+1]-ranges[i]) + 1
+	}
+	if size > 5000 {
+		return ranges
+	}
+
+	members := map[rune]bool{}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1]; r++ {
+			members[r] = true
+		}
+	}
+	var unfolded []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1]; r++ {
+			if foldCanonical(r) != r {
+				continue
+			}
+			for _, f := range foldOrbit(r) {
+				if !members[f] {
+					return ranges
+				}
+			}
+			if n := len(unfolded); n > 0 && unfolded[n-1] == r-1 {
+				unfolded[n-1] = r
+			} else {
+				unfolded = append(unfolded, r, r)
+			}
+		}
+	}
+
+	// Every member has to be matched through one that's kept
+	covered := 0
+	for i := 0; i+1 < len(unfolded); i += 2 {
+		for r := unfolded[i]; r <= unfolded[i+1]; r++ {
+			covered += len(foldOrbit(r))
+		}
+	}
+	if covered != len(members) {
+		return ranges
+	}
+	return unfolded
+}
+
+// RenderSimplification renders a simplification with the rewrites that
+// made it and whether the patterns were proven to match the same strings
+func RenderSimplification(s *Simplification) string {
+	var result strings.Builder
+	if len(s.Rewrites) == 0 {
+		fmt.Fprintf(&result, "%s\n\nThe pattern is already as simple as unregex can make it.\n", s.Pattern)
+		return result.String()
+	}
+	fmt.Fprintf(&result, "%s%s%s\n\n", colorBold, s.Simplified, colorReset)
+	fmt.Fprintf(&result, "Shortened from %d to %d characters:\n", utf8.RuneCountInString(s.Pattern), utf8.RuneCountInString(s.Simplified))
+	for i, rewrite := range s.Rewrites {
+		fmt.Fprintf(&result, "  %d. %s: %s %s %s\n", i+1, rewrite.Rule, rewrite.Before, Glyph("→", "->"), rewrite.After)
+	}
+	result.WriteString("\n")
+	if s.Proven {
+		fmt.Fprintf(&result, "Equivalence proven: both patterns' automata match the same strings (%d pairs of states compared).\n", s.States)
+	} else {
+		fmt.Fprintf(&result, "Equivalence not proven, as %s; the rewrites preserve what the pattern matches.\n", s.Unproven)
+	}
+	return result.String()
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		pattern, format, flags string
+		dropCaptures           bool
+		want                   string
+		proven                 bool
+	}{
+		{`(?:a+)*b`, "go", "", false, `a*b`, true},
+		{`(?:a?)+`, "go", "", false, `a*`, true},
+		{`(?:a{2}){3}`, "go", "", false, `a{6}`, true},
+		{`\d\d\d-\d\d\d\d`, "go", "", false, `\d{3}-\d{4}`, true},
+		{`aa*`, "go", "", false, `a+`, true},
+		{`[a-z]+[a-z]*`, "go", "i", false, `[a-z]+`, true},
+		{`foo|bar|foo`, "go", "", false, `foo|bar`, true},
+		{`(?:(?:abc))`, "go", "", false, `abc`, true},
+		{`x{1}y{0,1}z{1,}`, "go", "", false, `xy?z+`, true},
+		{`(?:a|b|c)(?:a|b|c)`, "go", "", false, `[a-c]{2}`, true},
+		{`[\d_-][\d_-]`, "go", "", false, `[_\d-]{2}`, true},
+		{`\p{L}\p{L}\p{L}+`, "go", "", false, `\p{L}{3,}`, true},
+		{`(?i)Hello(?:world)`, "go", "", false, `(?i)helloworld`, true},
+		{`(?P<year>\d\d\d\d)-(?:\d\d)`, "go", "", false, `(?P<year>\d{4})-\d\d`, true},
+		{`(a)(a)(a)(a)`, "posix", "", true, `aaaa`, true},
+		{`/(?:\s\s\s\s)/g`, "js", "", false, `/\s{4}/g`, true},
+		{`(?<n>a)(?:b)`, "pcre", "", false, `(?<n>a)b`, true},
+		{`#(?:a)(?:b)\s\s\s#x`, "pcre", "", false, `#ab\s{3}#x`, true},
+		{`r"(?:\d)\d\d\d"`, "python", "", false, `r"\d{4}"`, true},
+		{`\bfoo\b|\bfoo\b`, "go", "", false, `\bfoo\b`, false},
+		{`(?m)^a$|(?m)^a$`, "go", "", false, `(?m)^a$`, false},
+		{`abc`, "go", "", false, `abc`, false},
+		{`(a)(a)(a)(a)`, "posix", "", false, `(a)(a)(a)(a)`, false},
+		{`(?:a*)+?`, "go", "", false, `(?:a*)+?`, false},
+
+		// Each flavor is read with its own syntax, not Go's
+		{`[[:digit:]]|x`, "pcre", "", false, `[x[:digit:]]`, true},
+		{`[[:digit:]]|x`, "js", "", false, `[[:digit:]]|x`, false},
+		{`[[:digit:]]|x`, "python", "", false, `[[:digit:]]|x`, false},
+		{`[\d]|[0-9]`, "posix", "", false, `[0-9\d]`, true},
+		{`a{,3}|a{,3}`, "python", "", false, `a{0,3}`, true},
+	}
+	for _, tt := range tests {
+		got, err := Simplify(tt.pattern, tt.format, tt.flags, tt.dropCaptures)
+		if err != nil {
+			t.Errorf("Simplify(%q) error = %v", tt.pattern, err)
+			continue
+		}
+		if got.Simplified != tt.want || got.Proven != tt.proven {
+			t.Errorf("Simplify(%q) = %q (proven %v), want %q (proven %v)", tt.pattern, got.Simplified, got.Proven, tt.want, tt.proven)
+		}
+		if got.Simplified == tt.pattern && len(got.Rewrites) > 0 {
+			t.Errorf("Simplify(%q) made no change but lists rewrites %v", tt.pattern, got.Rewrites)
+		}
+	}
+}
+
+func TestSimplifyErrors(t *testing.T) {
+	tests := []struct {
+		pattern, format, want string
+	}{
+		{`(?<=a)b`, "pcre", "lookbehind"},
+		{`%{WORD}`, "grok", "doesn't support the grok format"},
+		{`b"\d+"`, "python", "raw strings"},
+		{`\d|[0-9]`, "posix", `no \d escape`},
+		{`(?:a)b`, "posix", "no (? groups"},
+		{`a*?b`, "posix", "no lazy quantifiers"},
+		{`[a^]|b`, "posix", "'^' in a bracket expression"},
+	}
+	for _, tt := range tests {
+		_, err := Simplify(tt.pattern, tt.format, "", false)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Simplify(%q) error = %v, want %q", tt.pattern, err, tt.want)
+		}
+	}
+}
+
+func TestEquivalentPatterns(t *testing.T) {
+	tests := []struct {
+		a, b, flags string
+		equal       bool
+		witness     string
+	}{
+		{`(?:a|b)*`, `[ab]*`, "", true, ""},
+		{`a{2,3}`, `aaa?`, "", true, ""},
+		{`A`, `a`, "i", true, ""},
+		{`a*`, `a+`, "", false, ""},
+		{`ab|ac`, `a[bcd]`, "", false, "ad"},
+	}
+	for _, tt := range tests {
+		equal, witness, _, err := equivalentPatterns(tt.a, tt.b, "go", tt.flags)
+		if err != nil {
+			t.Errorf("equivalentPatterns(%q, %q) error = %v", tt.a, tt.b, err)
+			continue
+		}
+		if equal != tt.equal || witness != tt.witness {
+			t.Errorf("equivalentPatterns(%q, %q) = %v, %q, want %v, %q", tt.a, tt.b, equal, witness, tt.equal, tt.witness)
+		}
+	}
+	if _, _, _, err := equivalentPatterns(`\ba`, `\ba`, "go", ""); err == nil {
+		t.Errorf("equivalentPatterns() with word boundaries didn't fail")
+	}
+}
+
+func TestRenderSimplification(t *testing.T) {
+	result, err := Simplify(`(?:a+)*b`, "go", "", false)
+	if err != nil {
+		t.Fatalf("Simplify() error = %v", err)
+	}
+	got := RenderSimplification(result)
+	for _, want := range []string{"a*b", "Shortened from 8 to 3 characters", "Collapsed nested quantifiers: (?:a+)* ", "Equivalence proven"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderSimplification() = %q, want it to contain %q", got, want)
+		}
+	}
+
+	result, err = Simplify(`abc`, "go", "", false)
+	if err != nil {
+		t.Fatalf("Simplify() error = %v", err)
+	}
+	if got := RenderSimplification(result); !strings.Contains(got, "already as simple") {
+		t.Errorf("RenderSimplification() = %q, want a note that it's already simple", got)
+	}
+}
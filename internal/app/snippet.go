@@ -0,0 +1,29 @@
+package app
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// RenderHTMLSnippet renders just the colorized pattern as inline HTML, with a
+// span per token carrying a re-<category> class and its explanation as title.
+// Styling is left to the embedding page.
+func RenderHTMLSnippet(exp *Explanation) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf(`<code class="unregex unregex-%s">`, html.EscapeString(exp.FormatName)))
+
+	spans, trailing := htmlTokens(exp)
+	for _, span := range spans {
+		if span.Index < 0 {
+			result.WriteString(html.EscapeString(span.Text))
+			continue
+		}
+		result.WriteString(fmt.Sprintf(`<span class="re-%s" title="%s">%s</span>`,
+			TokenCategory(span.Text), html.EscapeString(span.Explanation), html.EscapeString(span.Text)))
+	}
+	result.WriteString(html.EscapeString(trailing))
+
+	result.WriteString("</code>\n")
+	return result.String()
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// captureGroup is a capturing group of a pattern
+type captureGroup struct {
+	name       string
+	index      int // index of the token opening the group
+	subpattern string
+}
+
+// findCaptureGroups lists the capturing groups of a tokenized pattern in
+// order, so group n is at index n-1
+func findCaptureGroups(pattern string, tokens []TokenExplanation) []captureGroup {
+	var groups []captureGroup
+	pos := 0
+	for i, token := range tokens {
+		tokenPos := strings.Index(pattern[pos:], token.Token)
+		if tokenPos == -1 {
+			continue
+		}
+		tokenPos += pos
+		pos = tokenPos + len(token.Token)
+
+		if name, capturing := captureGroupName(token.Token); capturing {
+			group := captureGroup{name: name, index: i}
+			if end := findGroupEnd(pattern, tokenPos); end > 0 {
+				group.subpattern = pattern[pos:end]
+			}
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// applySubroutines adds to the explanation of each subroutine call, such as
+// (?1) or (?&name), the subpattern of the group it re-enters
+func applySubroutines(pattern string, tokens []TokenExplanation) {
+	groups := findCaptureGroups(pattern, tokens)
+	for i := range tokens {
+		target, ok := format.SubroutineTarget(tokens[i].Token)
+		if !ok || target == "0" {
+			continue
+		}
+
+		// Relative calls count from the groups opened before the call
+		opened := 0
+		for _, group := range groups {
+			if group.index < i {
+				opened++
+			}
+		}
+
+		number, err := strconv.Atoi(target)
+		switch {
+		case err != nil:
+			number = 0
+			for n, group := range groups {
+				if group.name == target {
+					number = n + 1
+				}
+			}
+		case target[0] == '-':
+			number += opened + 1
+		case target[0] == '+':
+			number += opened
+		}
+
+		if number < 1 || number > len(groups) {
+			tokens[i].Explanation += ". Error: there is no such group in this pattern"
+			continue
+		}
+		tokens[i].Explanation += fmt.Sprintf(". Group %d is '%s'", number, groups[number-1].subpattern)
+	}
+}
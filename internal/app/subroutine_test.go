@@ -0,0 +1,38 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyze_SubroutineTargets(t *testing.T) {
+	tests := []struct {
+		pattern string
+		token   string
+		want    string
+	}{
+		{`(a(?R)?b)(?1)`, "(?1)", ". Group 1 is 'a(?R)?b'"},
+		{`(?<n>x)(?&n)`, "(?&n)", ". Group 1 is 'x'"},
+		{`(a)(b)(?-1)`, "(?-1)", ". Group 2 is 'b'"},
+		{`(?+1)(c)`, "(?+1)", ". Group 1 is 'c'"},
+		{`(a)(?3)`, "(?3)", ". Error: there is no such group in this pattern"},
+		{`(a)(?R)`, "(?R)", "pattern again rather than repeating text already matched"},
+	}
+
+	for _, tt := range tests {
+		exp := Analyze(tt.pattern, "pcre")
+		found := false
+		for _, token := range exp.Tokens {
+			if token.Token != tt.token {
+				continue
+			}
+			found = true
+			if !strings.HasSuffix(token.Explanation, tt.want) {
+				t.Errorf("Analyze(%q) token %q = %q, want it to end with %q", tt.pattern, tt.token, token.Explanation, tt.want)
+			}
+		}
+		if !found {
+			t.Errorf("Analyze(%q) has no token %q", tt.pattern, tt.token)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are the helper functions available to custom templates
+var templateFuncs = template.FuncMap{
+	"inc":      func(i int) int { return i + 1 },
+	"category": TokenCategory,
+	"docURL":   TokenDocURL,
+	"join":     strings.Join,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"replace":  strings.ReplaceAll,
+	"repeat":   strings.Repeat,
+}
+
+// RenderTemplate renders an explanation with a user-supplied text/template.
+// The template is executed with the Explanation as its data.
+func RenderTemplate(exp *Explanation, text string) (string, error) {
+	tmpl, err := template.New("custom").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, exp); err != nil {
+		return "", fmt.Errorf("failed to render template: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// RenderTemplateFile renders an explanation with the template in path
+func RenderTemplateFile(exp *Explanation, path string) (string, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template: %v", err)
+	}
+	return RenderTemplate(exp, string(text))
+}
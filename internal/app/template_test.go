@@ -0,0 +1,56 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	text := `{{.Pattern}} [{{.Format}}]
+{{range $i, $t := .Tokens}}{{inc $i}} {{$t.Token}} {{category $t.Token}}: {{$t.Explanation}}
+{{end}}{{range .Features}}{{if .Supported}}{{.Name}};{{end}}{{end}}`
+
+	got, err := RenderTemplate(Analyze(`^a\d`, "js"), text)
+	if err != nil {
+		t.Fatalf("RenderTemplate() error = %v", err)
+	}
+
+	want := "^a\\d [JavaScript RegExp]\n" +
+		"1 ^ anchor: Matches only at the start of the input\n" +
+		"2 a literal: Matches the character 'a' literally\n" +
+		"3 \\d escape: Matches any digit (0-9)\n"
+	if len(got) < len(want) || got[:len(want)] != want {
+		t.Errorf("RenderTemplate() =\n%s\nwant prefix\n%s", got, want)
+	}
+}
+
+func TestRenderTemplate_Errors(t *testing.T) {
+	exp := Analyze("a", "go")
+
+	if _, err := RenderTemplate(exp, "{{.Pattern"); err == nil {
+		t.Error("RenderTemplate() should fail for a template that doesn't parse")
+	}
+	if _, err := RenderTemplate(exp, "{{.Missing}}"); err == nil {
+		t.Error("RenderTemplate() should fail for an unknown field")
+	}
+}
+
+func TestRenderTemplateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	if err := os.WriteFile(path, []byte("{{upper .Pattern}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := RenderTemplateFile(Analyze("abc", "go"), path)
+	if err != nil {
+		t.Fatalf("RenderTemplateFile() error = %v", err)
+	}
+	if got != "ABC" {
+		t.Errorf("RenderTemplateFile() = %q, want %q", got, "ABC")
+	}
+
+	if _, err := RenderTemplateFile(Analyze("abc", "go"), filepath.Join(t.TempDir(), "missing.tmpl")); err == nil {
+		t.Error("RenderTemplateFile() should fail for a missing file")
+	}
+}
@@ -0,0 +1,26 @@
+package app
+
+import (
+	"os"
+	"strconv"
+)
+
+// StdoutIsTerminal reports whether stdout is attached to a terminal rather
+// than a pipe or a file
+func StdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// TerminalWidth returns the width of the terminal in columns, from COLUMNS
+// or by asking the terminal. It returns 0 when stdout isn't a terminal and
+// the output shouldn't be wrapped.
+func TerminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	if !StdoutIsTerminal() {
+		return 0
+	}
+	return consoleWidth()
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package app
+
+// consoleWidth can't query the terminal on this platform
+func consoleWidth() int {
+	return 0
+}
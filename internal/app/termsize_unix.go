@@ -0,0 +1,21 @@
+//go:build linux || darwin || freebsd
+
+package app
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// consoleWidth asks the terminal attached to stdout for its width
+func consoleWidth() int {
+	var size struct {
+		rows, cols, xpixel, ypixel uint16
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, os.Stdout.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&size)))
+	if errno != 0 {
+		return 0
+	}
+	return int(size.cols)
+}
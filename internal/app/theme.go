@@ -0,0 +1,196 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Theme is a palette for the colored text output. Colors are ANSI SGR
+// parameters such as "31" (red) or "38;5;208" (256-color orange).
+type Theme struct {
+	Name string
+
+	// Tokens are the token colors used on basic 8-color terminals
+	Tokens []string
+
+	// Extended are the token colors used on 256-color and truecolor
+	// terminals. When empty, Tokens is used everywhere.
+	Extended []string
+
+	// SpreadHues gives every token its own hue on truecolor terminals
+	// instead of recycling the palette for long patterns
+	SpreadHues bool
+
+	Supported   string
+	Unsupported string
+}
+
+// themes holds the built-in themes and any registered from the config file
+var themes = map[string]Theme{
+	"default": {
+		Name:        "default",
+		Tokens:      []string{"31", "32", "34", "33", "35", "36"},
+		Extended:    []string{"38;5;196", "38;5;46", "38;5;33", "38;5;226", "38;5;201", "38;5;51", "38;5;208", "38;5;129", "38;5;118", "38;5;39", "38;5;213", "38;5;172"},
+		SpreadHues:  true,
+		Supported:   "32",
+		Unsupported: "31",
+	},
+	"high-contrast": {
+		Name:        "high-contrast",
+		Tokens:      []string{"30;103", "97;44", "30;102", "97;45", "30;106", "97;41"},
+		Supported:   "30;102",
+		Unsupported: "97;41",
+	},
+	// The Okabe-Ito palette, which stays distinguishable with red-green color blindness
+	"deuteranopia": {
+		Name:        "deuteranopia",
+		Tokens:      []string{"33", "34", "36", "35", "93", "94"},
+		Extended:    []string{"38;5;208", "38;5;75", "38;5;226", "38;5;32", "38;5;166", "38;5;175"},
+		Supported:   "34",
+		Unsupported: "33",
+	},
+}
+
+// activeTheme is the theme applied when colors are enabled
+var activeTheme = themes["default"]
+
+// colorEnabled is false when the text output must not contain ANSI codes
+var colorEnabled = true
+
+// colorDepth is the number of colors the terminal can display
+var colorDepth = ColorDepthBasic
+
+func init() {
+	applyColors()
+}
+
+// ThemeNames returns the names of the available themes in sorted order
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RegisterTheme adds a theme, such as one defined in the config file, or
+// replaces an existing theme with the same name
+func RegisterTheme(theme Theme) error {
+	if theme.Name == "" {
+		return fmt.Errorf("theme has no name")
+	}
+	if len(theme.Tokens) == 0 {
+		return fmt.Errorf("theme '%s' has no token colors", theme.Name)
+	}
+	codes := append([]string{theme.Supported, theme.Unsupported}, theme.Tokens...)
+	for _, code := range append(codes, theme.Extended...) {
+		if !isSGR(code) {
+			return fmt.Errorf("theme '%s' has an invalid color '%s'", theme.Name, code)
+		}
+	}
+
+	themes[theme.Name] = theme
+	return nil
+}
+
+// SetTheme selects the theme used by the text output
+func SetTheme(name string) error {
+	theme, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme '%s' (available: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	activeTheme = theme
+	applyColors()
+	return nil
+}
+
+// applyColors sets the ANSI codes used by the text output from the active
+// theme, or clears them when colors are disabled
+func applyColors() {
+	if !colorEnabled {
+		colorReset, colorBold = "", ""
+		colorSupported, colorUnsupported = "", ""
+		tokenColors = make([]string, len(activeTheme.Tokens))
+		return
+	}
+
+	palette := activeTheme.Tokens
+	if colorDepth >= ColorDepth256 && len(activeTheme.Extended) > 0 {
+		palette = activeTheme.Extended
+	}
+
+	colorReset, colorBold = "\033[0m", "\033[1m"
+	colorSupported = sgr(activeTheme.Supported)
+	colorUnsupported = sgr(activeTheme.Unsupported)
+	tokenColors = make([]string, len(palette))
+	for i, code := range palette {
+		tokenColors[i] = sgr(code)
+	}
+}
+
+// tokenPalette returns the colors for a pattern with count tokens. On
+// truecolor terminals, themes that spread hues get one distinct color per
+// token so long patterns don't recycle colors.
+func tokenPalette(count int) []string {
+	if !colorEnabled || colorDepth < ColorDepthTrueColor || !activeTheme.SpreadHues || count <= len(tokenColors) {
+		return tokenColors
+	}
+
+	palette := make([]string, count)
+	for i := range palette {
+		// Step around the color wheel by the golden angle so neighboring
+		// tokens always get clearly different hues
+		hue := math.Mod(float64(i)*137.508, 360)
+		r, g, b := hslToRGB(hue, 0.75, 0.6)
+		palette[i] = fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b)
+	}
+	return palette
+}
+
+// hslToRGB converts a color from hue (degrees), saturation and lightness
+// (0 to 1) to 8-bit RGB
+func hslToRGB(hue, saturation, lightness float64) (uint8, uint8, uint8) {
+	chroma := (1 - math.Abs(2*lightness-1)) * saturation
+	x := chroma * (1 - math.Abs(math.Mod(hue/60, 2)-1))
+	m := lightness - chroma/2
+
+	var r, g, b float64
+	switch {
+	case hue < 60:
+		r, g, b = chroma, x, 0
+	case hue < 120:
+		r, g, b = x, chroma, 0
+	case hue < 180:
+		r, g, b = 0, chroma, x
+	case hue < 240:
+		r, g, b = 0, x, chroma
+	case hue < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	return uint8(math.Round((r + m) * 255)), uint8(math.Round((g + m) * 255)), uint8(math.Round((b + m) * 255))
+}
+
+// sgr turns SGR parameters into an escape sequence
+func sgr(code string) string {
+	if code == "" {
+		return ""
+	}
+	return "\033[" + code + "m"
+}
+
+// isSGR reports whether code is a list of SGR parameters, allowing empty
+// codes for uncolored elements
+func isSGR(code string) bool {
+	for _, c := range code {
+		if (c < '0' || c > '9') && c != ';' {
+			return false
+		}
+	}
+	return true
+}
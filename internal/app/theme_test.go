@@ -0,0 +1,111 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetTheme(t *testing.T) {
+	defer SetTheme("default")
+
+	for _, name := range []string{"default", "high-contrast", "deuteranopia"} {
+		if err := SetTheme(name); err != nil {
+			t.Errorf("SetTheme(%q) error = %v", name, err)
+		}
+	}
+
+	if err := SetTheme("deuteranopia"); err != nil {
+		t.Fatal(err)
+	}
+	if tokenColors[0] != "\033[33m" {
+		t.Errorf("tokenColors[0] = %q, want the basic deuteranopia yellow", tokenColors[0])
+	}
+
+	SetColorDepth(ColorDepth256)
+	defer SetColorDepth(ColorDepthBasic)
+	if tokenColors[0] != "\033[38;5;208m" {
+		t.Errorf("tokenColors[0] = %q, want the 256-color deuteranopia orange", tokenColors[0])
+	}
+
+	if err := SetTheme("missing"); err == nil {
+		t.Error("SetTheme() should fail for an unknown theme")
+	}
+}
+
+func TestRegisterTheme(t *testing.T) {
+	defer delete(themes, "custom")
+	defer SetTheme("default")
+
+	theme := Theme{Name: "custom", Tokens: []string{"1;35", "38;2;255;128;0"}, Supported: "32"}
+	if err := RegisterTheme(theme); err != nil {
+		t.Fatalf("RegisterTheme() error = %v", err)
+	}
+	if err := SetTheme("custom"); err != nil {
+		t.Fatalf("SetTheme() error = %v", err)
+	}
+	if len(tokenColors) != 2 || tokenColors[1] != "\033[38;2;255;128;0m" || colorUnsupported != "" {
+		t.Errorf("custom theme colors = %q, unsupported %q", tokenColors, colorUnsupported)
+	}
+
+	invalid := []Theme{
+		{Tokens: []string{"31"}},
+		{Name: "empty"},
+		{Name: "bad", Tokens: []string{"red"}},
+	}
+	for _, theme := range invalid {
+		if err := RegisterTheme(theme); err == nil {
+			t.Errorf("RegisterTheme(%+v) should fail", theme)
+		}
+	}
+}
+
+func TestTokenPalette(t *testing.T) {
+	defer SetColorDepth(ColorDepthBasic)
+
+	SetColorDepth(ColorDepthBasic)
+	if got := tokenPalette(20); len(got) != 6 {
+		t.Errorf("tokenPalette(20) on a basic terminal has %d colors, want the theme's 6", len(got))
+	}
+
+	SetColorDepth(ColorDepth256)
+	if got := tokenPalette(20); len(got) != 12 || got[6] != "\033[38;5;208m" {
+		t.Errorf("tokenPalette(20) on a 256-color terminal = %q, want the extended palette", got)
+	}
+
+	SetColorDepth(ColorDepthTrueColor)
+	if got := tokenPalette(4); len(got) != 12 {
+		t.Errorf("tokenPalette(4) on a truecolor terminal has %d colors, want the extended palette", len(got))
+	}
+	got := tokenPalette(20)
+	if len(got) != 20 {
+		t.Fatalf("tokenPalette(20) on a truecolor terminal has %d colors, want one per token", len(got))
+	}
+	seen := map[string]bool{}
+	for _, color := range got {
+		if !strings.HasPrefix(color, "\033[38;2;") || seen[color] {
+			t.Errorf("tokenPalette(20) should contain distinct truecolor codes, got %q", got)
+			break
+		}
+		seen[color] = true
+	}
+}
+
+func TestHSLToRGB(t *testing.T) {
+	tests := []struct {
+		hue, saturation, lightness float64
+		r, g, b                    uint8
+	}{
+		{0, 1, 0.5, 255, 0, 0},
+		{120, 1, 0.5, 0, 255, 0},
+		{240, 1, 0.5, 0, 0, 255},
+		{0, 0, 1, 255, 255, 255},
+	}
+
+	for _, tt := range tests {
+		r, g, b := hslToRGB(tt.hue, tt.saturation, tt.lightness)
+		if r != tt.r || g != tt.g || b != tt.b {
+			t.Errorf("hslToRGB(%v, %v, %v) = %d, %d, %d, want %d, %d, %d",
+				tt.hue, tt.saturation, tt.lightness, r, g, b, tt.r, tt.g, tt.b)
+		}
+	}
+}
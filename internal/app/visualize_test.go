@@ -0,0 +1,29 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVisualizePattern_Wrap(t *testing.T) {
+	SetColor(false)
+	defer SetColor(true)
+
+	pattern := `ab(c|d)\d{2,3}`
+	tokens := []string{"ab", "(", "c", "|", "d", ")", `\d`, "{2,3}"}
+
+	got := visualizePattern(pattern, tokens, tokenColors, 0, nil)
+	if !strings.Contains(got, "ab(c|d)\\d{2,3}\n1 234567   8  \n") {
+		t.Errorf("visualizePattern() without a width should keep one line, got:\n%s", got)
+	}
+
+	got = visualizePattern(pattern, tokens, tokenColors, 8, nil)
+	for _, want := range []string{
+		"ab(c|d)\n1 23456\n\n",
+		"\\d{2,3}\n7   8  \n\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("visualizePattern() with width 8 should contain %q, got:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// wordTokens are the tokens whose meaning depends on what a word character
+// is in the flavor
+var wordTokens = []string{`\w`, `\W`, `\b`, `\B`}
+
+// unicodeWords reports whether \w and \b count Unicode letters and digits as
+// word characters in the pattern's flavor, with the modifier letters in
+// effect. Go and JavaScript only count [A-Za-z0-9_], PCRE does unless
+// (*UCP) or PHP's u modifier is on, and Python 3 str patterns always do
+// unless the a flag is on.
+func unicodeWords(exp *Explanation, flags string) bool {
+	switch exp.FormatName {
+	case "python":
+		return !format.IsBytesPattern(exp.Pattern) && !strings.ContainsRune(flags, 'a')
+	case "pcre":
+		for _, token := range exp.Tokens {
+			modifiers, ok := format.DelimiterModifiers(token.Token)
+			if token.Token == "(*UCP)" || (ok && strings.ContainsRune(modifiers, 'u')) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wordSetting names the flavor and what decides its word characters, to
+// start the word character note
+func wordSetting(exp *Explanation, unicode bool) string {
+	switch {
+	case exp.FormatName == "go":
+		return "In Go"
+	case exp.FormatName == "js":
+		return "In JavaScript, even with the u flag"
+	case exp.FormatName == "pcre" && unicode:
+		return "In PCRE with (*UCP)"
+	case exp.FormatName == "pcre":
+		return "In PCRE without (*UCP)"
+	case format.IsBytesPattern(exp.Pattern):
+		return "In Python bytes patterns"
+	case !unicode:
+		return "In Python with the a (ASCII) flag"
+	}
+	return "In Python 3"
+}
+
+// wordNote explains what \w and \b count as word characters in the flavor
+// and how that differs elsewhere, showing both on "naïve", whose ï only
+// Unicode-aware flavors count. It returns "" when the pattern uses neither
+// or the flavor doesn't have them.
+func wordNote(exp *Explanation) string {
+	if exp.FormatName == "posix" {
+		return ""
+	}
+	active := activeModifiers(exp.Tokens, exp.Flags)
+	for i, token := range exp.Tokens {
+		if !usesWordCharacters(token.Token) {
+			continue
+		}
+
+		setting := "  " + wordSetting(exp, unicodeWords(exp, active[i]))
+		var lines []string
+		if unicodeWords(exp, active[i]) {
+			lines = []string{
+				setting + ", \\w and \\b count Unicode letters and digits as word characters: \\w+ matches all of \"naïve\", and \\b only matches at its ends.",
+				"  Go, JavaScript and PCRE without (*UCP) only count [A-Za-z0-9_], so there \\w+ finds just \"na\" and \"ve\".",
+			}
+		} else {
+			lines = []string{
+				setting + ", \\w and \\b only count [A-Za-z0-9_] as word characters: in \"naïve\", \\w+ finds \"na\" and \"ve\", and \\b matches on both sides of the ï.",
+				"  Python 3 and PCRE with (*UCP) count Unicode letters and digits too, so there \\w+ matches all of \"naïve\".",
+			}
+		}
+		return fmt.Sprintf("%sWord characters:%s\n", colorBold, colorReset) + strings.Join(lines, "\n") + "\n"
+	}
+	return ""
+}
+
+// usesWordCharacters reports whether a token is one of \w, \W, \b and \B or
+// a class containing \w or \W
+func usesWordCharacters(token string) bool {
+	for _, word := range wordTokens {
+		if token == word {
+			return true
+		}
+	}
+	return strings.HasPrefix(token, "[") && (strings.Contains(token, `\w`) || strings.Contains(token, `\W`))
+}
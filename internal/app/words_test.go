@@ -0,0 +1,58 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnicodeWords(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		formatName string
+		want       bool
+	}{
+		{`\w+`, "go", false},
+		{`/\w+/u`, "js", false},
+		{`\w+`, "pcre", false},
+		{`(*UCP)\w+`, "pcre", true},
+		{`#\w+#u`, "pcre", true},
+		{`\w+`, "python", true},
+		{`rb"\w+"`, "python", false},
+		{`(?a)\w+`, "python", false},
+	}
+
+	for _, tt := range tests {
+		exp := Analyze(tt.pattern, tt.formatName)
+		active := activeModifiers(exp.Tokens, exp.Flags)
+		if got := unicodeWords(exp, active[len(active)-1]); got != tt.want {
+			t.Errorf("unicodeWords(%q, %q) = %v, want %v", tt.pattern, tt.formatName, got, tt.want)
+		}
+	}
+}
+
+func TestWordNote(t *testing.T) {
+	SetColor(false)
+	defer SetColor(true)
+
+	got := wordNote(Analyze(`\bna\w+`, "go"))
+	for _, want := range []string{
+		"Word characters:",
+		`In Go, \w and \b only count [A-Za-z0-9_] as word characters: in "naïve", \w+ finds "na" and "ve"`,
+		`Python 3 and PCRE with (*UCP) count Unicode letters and digits too`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("wordNote() should contain %q, got:\n%s", want, got)
+		}
+	}
+
+	got = wordNote(Analyze(`[\w-]+`, "python"))
+	if !strings.Contains(got, `In Python 3, \w and \b count Unicode letters and digits as word characters: \w+ matches all of "naïve"`) {
+		t.Errorf("wordNote() = %q, want Python described as Unicode-aware", got)
+	}
+
+	for _, tt := range []struct{ pattern, formatName string }{{"abc", "go"}, {`\w`, "posix"}} {
+		if got := wordNote(Analyze(tt.pattern, tt.formatName)); got != "" {
+			t.Errorf("wordNote(%q) = %q, want no note", tt.pattern, got)
+		}
+	}
+}
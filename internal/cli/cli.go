@@ -68,7 +68,7 @@ func Run() {
 	}
 
 	// Run the regex explanation with the selected format
-	if err := app.Run([]string{pattern, format}); err != nil {
+	if _, err := app.Run(app.RunOptions{Pattern: pattern, Flavor: format, Hyperlinks: app.SupportsHyperlinks()}); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
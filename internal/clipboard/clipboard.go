@@ -0,0 +1,85 @@
+// Package clipboard reads and writes the system clipboard through the
+// platform's clipboard commands
+package clipboard
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// tool is a pair of commands that paste from and copy to the clipboard
+type tool struct {
+	paste []string
+	copy  []string
+}
+
+// Read returns the text on the clipboard
+func Read() (string, error) {
+	t, err := findTool(runtime.GOOS, os.Getenv, exec.LookPath)
+	if err != nil {
+		return "", err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(t.paste[0], t.paste[1:]...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the clipboard with %s: %v %s", t.paste[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// Write puts text on the clipboard
+func Write(text string) error {
+	t, err := findTool(runtime.GOOS, os.Getenv, exec.LookPath)
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(t.copy[0], t.copy[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to write the clipboard with %s: %v %s", t.copy[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// findTool picks the clipboard commands for a platform. On Linux and the
+// BSDs the Wayland tools are preferred in a Wayland session, then xclip and
+// xsel for X11.
+func findTool(goos string, getenv func(string) string, lookPath func(string) (string, error)) (tool, error) {
+	var candidates []tool
+	switch goos {
+	case "darwin":
+		candidates = []tool{{[]string{"pbpaste"}, []string{"pbcopy"}}}
+	case "windows":
+		candidates = []tool{{
+			[]string{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"},
+			[]string{"powershell", "-NoProfile", "-Command", "$input | Set-Clipboard"},
+		}}
+	default:
+		if getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, tool{[]string{"wl-paste", "--no-newline"}, []string{"wl-copy"}})
+		}
+		candidates = append(candidates,
+			tool{[]string{"xclip", "-selection", "clipboard", "-out"}, []string{"xclip", "-selection", "clipboard", "-in"}},
+			tool{[]string{"xsel", "--clipboard", "--output"}, []string{"xsel", "--clipboard", "--input"}},
+		)
+	}
+
+	names := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if _, err := lookPath(candidate.paste[0]); err == nil {
+			return candidate, nil
+		}
+		names = append(names, candidate.paste[0])
+	}
+	return tool{}, fmt.Errorf("no clipboard tool found (install %s)", strings.Join(names, " or "))
+}
@@ -0,0 +1,58 @@
+package clipboard
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFindTool(t *testing.T) {
+	tests := []struct {
+		name      string
+		goos      string
+		env       map[string]string
+		installed []string
+		want      string
+	}{
+		{"macOS", "darwin", nil, []string{"pbpaste"}, "pbpaste"},
+		{"Windows", "windows", nil, []string{"powershell"}, "powershell"},
+		{"Wayland", "linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, []string{"wl-paste", "xclip"}, "wl-paste"},
+		{"Wayland without wl-clipboard", "linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, []string{"xclip"}, "xclip"},
+		{"X11 ignores wl-paste", "linux", nil, []string{"wl-paste", "xsel"}, "xsel"},
+		{"X11 prefers xclip", "freebsd", nil, []string{"xsel", "xclip"}, "xclip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			lookPath := func(file string) (string, error) {
+				for _, name := range tt.installed {
+					if name == file {
+						return "/usr/bin/" + file, nil
+					}
+				}
+				return "", fmt.Errorf("%s not found", file)
+			}
+
+			got, err := findTool(tt.goos, getenv, lookPath)
+			if err != nil {
+				t.Fatalf("findTool() error = %v", err)
+			}
+			if got.paste[0] != tt.want {
+				t.Errorf("findTool() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindTool_NoneInstalled(t *testing.T) {
+	getenv := func(string) string { return "" }
+	lookPath := func(file string) (string, error) { return "", fmt.Errorf("%s not found", file) }
+
+	_, err := findTool("linux", getenv, lookPath)
+	if err == nil {
+		t.Fatal("findTool() expected an error when no tool is installed")
+	}
+	if want := "no clipboard tool found (install xclip or xsel)"; err.Error() != want {
+		t.Errorf("findTool() error = %q, want %q", err.Error(), want)
+	}
+}
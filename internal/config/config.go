@@ -0,0 +1,126 @@
+// Package config loads the user's unregex configuration file
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Theme is a color theme defined in the config file. Colors are ANSI SGR
+// parameters such as "31" or "38;5;208".
+type Theme struct {
+	Tokens      []string `json:"tokens"`
+	Extended    []string `json:"extended,omitempty"`
+	Supported   string   `json:"supported"`
+	Unsupported string   `json:"unsupported"`
+}
+
+// Plugin declares a regex flavor implemented by an external executable,
+// which speaks the protocol described in the plugin package
+type Plugin struct {
+	// Command is the executable, found in PATH unless it's a path
+	Command string `json:"command"`
+
+	// Args are passed to the executable before any request
+	Args []string `json:"args,omitempty"`
+}
+
+// Config holds the settings read from the config file
+type Config struct {
+	// Theme is the name of the color theme used when -theme isn't given
+	Theme string `json:"theme,omitempty"`
+
+	// Themes defines additional color themes by name
+	Themes map[string]Theme `json:"themes,omitempty"`
+
+	// Defaults sets default values for command-line flags by flag name
+	Defaults map[string]interface{} `json:"defaults,omitempty"`
+
+	// Plugins adds regex flavors implemented by external executables, by
+	// the name given to -format
+	Plugins map[string]Plugin `json:"plugins,omitempty"`
+}
+
+// EnvPrefix starts the environment variables that override flag defaults
+const EnvPrefix = "UNREGEX_"
+
+// EnvName returns the environment variable for a flag, such as
+// UNREGEX_NO_PAGER for -no-pager
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// ApplyDefaults layers defaults onto the flags in fs before they are parsed:
+// first the config file's defaults, then UNREGEX_* environment variables, so
+// flags given on the command line override both. UNREGEX_NO_COLOR is
+// accepted as a shorthand for UNREGEX_COLOR=never.
+func (c *Config) ApplyDefaults(fs *flag.FlagSet, getenv func(string) string) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || f.Name == "help" || f.Name == "version" {
+			return
+		}
+
+		if value, ok := c.Defaults[f.Name]; ok {
+			if setErr := f.Value.Set(fmt.Sprint(value)); setErr != nil {
+				err = fmt.Errorf("invalid default for %s in config file: %v", f.Name, setErr)
+				return
+			}
+		}
+
+		if f.Name == "color" {
+			if noColor, parseErr := strconv.ParseBool(getenv(EnvPrefix + "NO_COLOR")); parseErr == nil && noColor {
+				f.Value.Set("never")
+			}
+		}
+
+		if value := getenv(EnvName(f.Name)); value != "" {
+			if setErr := f.Value.Set(value); setErr != nil {
+				err = fmt.Errorf("invalid %s: %v", EnvName(f.Name), setErr)
+			}
+		}
+	})
+	return err
+}
+
+// Path returns the location of the config file, config.json in the
+// unregex directory of the user's config directory
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "unregex", "config.json"), nil
+}
+
+// Load reads the config file. A missing file yields an empty config.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return &Config{}, nil
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads a config file from path. A missing file yields an empty config.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %v", path, err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	return &cfg, nil
+}
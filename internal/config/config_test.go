@@ -0,0 +1,115 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+  "theme": "solarized",
+  "themes": {
+    "solarized": {"tokens": ["38;5;136", "38;5;37"], "supported": "38;5;64", "unsupported": "38;5;160"}
+  },
+  "plugins": {
+    "cobol": {"command": "unregex-cobol", "args": ["--strict"]}
+  }
+}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if cfg.Theme != "solarized" {
+		t.Errorf("Theme = %q, want %q", cfg.Theme, "solarized")
+	}
+	theme, ok := cfg.Themes["solarized"]
+	if !ok {
+		t.Fatal("Themes should contain the solarized theme")
+	}
+	if len(theme.Tokens) != 2 || theme.Tokens[1] != "38;5;37" || theme.Unsupported != "38;5;160" {
+		t.Errorf("solarized theme = %+v", theme)
+	}
+	if plugin := cfg.Plugins["cobol"]; plugin.Command != "unregex-cobol" || len(plugin.Args) != 1 || plugin.Args[0] != "--strict" {
+		t.Errorf("cobol plugin = %+v", plugin)
+	}
+}
+
+func TestLoadFile_Missing(t *testing.T) {
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v, want an empty config", err)
+	}
+	if cfg.Theme != "" || len(cfg.Themes) != 0 {
+		t.Errorf("LoadFile() = %+v, want an empty config", cfg)
+	}
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{theme"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("LoadFile() should fail for invalid JSON")
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	newFlags := func() (*flag.FlagSet, *string, *string, *bool) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		format := fs.String("format", "go", "")
+		color := fs.String("color", "auto", "")
+		noPager := fs.Bool("no-pager", false, "")
+		return fs, format, color, noPager
+	}
+
+	cfg := &Config{Defaults: map[string]interface{}{"format": "pcre", "no-pager": true}}
+
+	// Config file defaults apply when nothing else is set
+	fs, format, color, noPager := newFlags()
+	if err := cfg.ApplyDefaults(fs, func(string) string { return "" }); err != nil {
+		t.Fatalf("ApplyDefaults() error = %v", err)
+	}
+	if *format != "pcre" || !*noPager || *color != "auto" {
+		t.Errorf("config defaults: format = %q, no-pager = %v, color = %q", *format, *noPager, *color)
+	}
+
+	// Environment variables override the config file, and flags override both
+	env := map[string]string{"UNREGEX_FORMAT": "js", "UNREGEX_NO_PAGER": "false", "UNREGEX_NO_COLOR": "1"}
+	fs, format, color, noPager = newFlags()
+	if err := cfg.ApplyDefaults(fs, func(key string) string { return env[key] }); err != nil {
+		t.Fatalf("ApplyDefaults() error = %v", err)
+	}
+	if *format != "js" || *noPager || *color != "never" {
+		t.Errorf("env overrides: format = %q, no-pager = %v, color = %q", *format, *noPager, *color)
+	}
+	if err := fs.Parse([]string{"-format", "python"}); err != nil {
+		t.Fatal(err)
+	}
+	if *format != "python" {
+		t.Errorf("flag override: format = %q, want %q", *format, "python")
+	}
+}
+
+func TestApplyDefaults_Invalid(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Bool("visualize", false, "")
+
+	env := map[string]string{"UNREGEX_VISUALIZE": "maybe"}
+	if err := (&Config{}).ApplyDefaults(fs, func(key string) string { return env[key] }); err == nil {
+		t.Error("ApplyDefaults() should fail for an invalid boolean")
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("no-pager"); got != "UNREGEX_NO_PAGER" {
+		t.Errorf("EnvName() = %q, want %q", got, "UNREGEX_NO_PAGER")
+	}
+}
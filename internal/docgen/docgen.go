@@ -0,0 +1,302 @@
+// Package docgen generates Go documentation for the regex constants of a package
+package docgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	regexformat "github.com/weslien/unregex/pkg/format"
+)
+
+// DefaultOutput is the file name written when no output file is specified
+const DefaultOutput = "regex_docs_gen.go"
+
+// generatedHeader marks files written by docgen so they are skipped on the next run
+const generatedHeader = "// Code generated by unregex docgen; DO NOT EDIT."
+
+// regexpFuncs are the regexp package functions whose first argument is a pattern
+var regexpFuncs = map[string]bool{
+	"Compile":          true,
+	"CompilePOSIX":     true,
+	"MustCompile":      true,
+	"MustCompilePOSIX": true,
+	"Match":            true,
+	"MatchString":      true,
+	"MatchReader":      true,
+}
+
+// patternSuffixes are name suffixes that mark a constant as a regex without a regexp call
+var patternSuffixes = []string{"Pattern", "Regex", "Regexp", "RE", "Re"}
+
+// Constant is an exported regex constant found in a package
+type Constant struct {
+	Name    string
+	Pattern string
+}
+
+// Generate scans the Go package in dir for exported regex constants and writes
+// their explanations to output (relative to dir). It returns the constants found.
+func Generate(dir, output, formatName string) ([]Constant, error) {
+	if output == "" {
+		output = DefaultOutput
+	}
+
+	pkgName, constants, err := FindConstants(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := Render(pkgName, constants, formatName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, output), source, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %v", output, err)
+	}
+
+	return constants, nil
+}
+
+// FindConstants parses the non-test Go files in dir and returns the package name
+// and its exported string constants that are used as regular expressions
+func FindConstants(dir string) (string, []Constant, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read package directory: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	pkgName := ""
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to parse package: %v", err)
+		}
+		if pkgName == "" {
+			pkgName = file.Name.Name
+		}
+		files = append(files, file)
+	}
+	if pkgName == "" {
+		return "", nil, fmt.Errorf("no Go package found in %s", dir)
+	}
+
+	// Collect every string constant so that concatenations referring to
+	// other constants can be resolved
+	values := make(map[string]ast.Expr)
+	var exported []string
+	compiled := make(map[string]bool)
+
+	for _, file := range files {
+		if isGenerated(file) {
+			continue
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				for i, name := range valueSpec.Names {
+					if i >= len(valueSpec.Values) {
+						continue
+					}
+					values[name.Name] = valueSpec.Values[i]
+					if name.IsExported() {
+						exported = append(exported, name.Name)
+					}
+				}
+			}
+		}
+
+		for name := range regexpArguments(file) {
+			compiled[name] = true
+		}
+	}
+
+	var constants []Constant
+	for _, name := range exported {
+		if !compiled[name] && !hasPatternSuffix(name) {
+			continue
+		}
+		value, ok := evalString(values[name], values, 0)
+		if !ok {
+			continue
+		}
+		constants = append(constants, Constant{Name: name, Pattern: value})
+	}
+
+	sort.Slice(constants, func(i, j int) bool {
+		return constants[i].Name < constants[j].Name
+	})
+
+	return pkgName, constants, nil
+}
+
+// Render produces the formatted source of the generated documentation file
+func Render(pkgName string, constants []Constant, formatName string) ([]byte, error) {
+	regexFormat := regexformat.GetFormat(formatName)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n\n", generatedHeader)
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+
+	for _, c := range constants {
+		tokens := regexFormat.TokenizeRegex(c.Pattern)
+
+		width := 0
+		for _, t := range tokens {
+			if len(t) > width {
+				width = len(t)
+			}
+		}
+
+		fmt.Fprintf(&buf, "// %s matches %s (%s):\n//\n", c.Name, quotePattern(c.Pattern), regexFormat.Name())
+		for _, t := range tokens {
+			fmt.Fprintf(&buf, "//\t%-*s  %s\n", width, commentSafe(t), commentSafe(regexFormat.ExplainToken(t)))
+		}
+		buf.WriteString("\n")
+	}
+
+	// Referencing the constants makes the build fail when one is renamed or
+	// removed, which signals that the documentation needs to be regenerated
+	if len(constants) > 0 {
+		buf.WriteString("var _ = []string{\n")
+		for _, c := range constants {
+			fmt.Fprintf(&buf, "\t%s,\n", c.Name)
+		}
+		buf.WriteString("}\n")
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated documentation: %v", err)
+	}
+	return source, nil
+}
+
+// regexpArguments returns the identifiers passed as the pattern argument to
+// regexp package functions in file
+func regexpArguments(file *ast.File) map[string]bool {
+	// Respect renamed imports of the regexp package
+	regexpName := ""
+	for _, imp := range file.Imports {
+		if imp.Path.Value == `"regexp"` {
+			regexpName = "regexp"
+			if imp.Name != nil {
+				regexpName = imp.Name.Name
+			}
+		}
+	}
+
+	names := make(map[string]bool)
+	if regexpName == "" {
+		return names
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !regexpFuncs[sel.Sel.Name] {
+			return true
+		}
+		if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != regexpName {
+			return true
+		}
+		if arg, ok := call.Args[0].(*ast.Ident); ok {
+			names[arg.Name] = true
+		}
+		return true
+	})
+
+	return names
+}
+
+// evalString resolves a constant expression made of string literals,
+// concatenations and references to other constants
+func evalString(expr ast.Expr, values map[string]ast.Expr, depth int) (string, bool) {
+	if expr == nil || depth > 32 {
+		return "", false
+	}
+
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.ParenExpr:
+		return evalString(e.X, values, depth+1)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		left, ok := evalString(e.X, values, depth+1)
+		if !ok {
+			return "", false
+		}
+		right, ok := evalString(e.Y, values, depth+1)
+		return left + right, ok
+	case *ast.Ident:
+		return evalString(values[e.Name], values, depth+1)
+	}
+
+	return "", false
+}
+
+// quotePattern wraps a pattern for display in a comment, falling back to a
+// quoted string when it contains characters a raw string can't show
+func quotePattern(pattern string) string {
+	if strings.ContainsAny(pattern, "`\r\n") {
+		return strconv.Quote(pattern)
+	}
+	return "`" + pattern + "`"
+}
+
+// commentSafe escapes line breaks so text stays on a single comment line
+func commentSafe(s string) string {
+	return strings.NewReplacer("\n", `\n`, "\r", `\r`).Replace(s)
+}
+
+// hasPatternSuffix reports whether a constant name suggests it holds a regex
+func hasPatternSuffix(name string) bool {
+	for _, suffix := range patternSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isGenerated reports whether file was written by docgen
+func isGenerated(file *ast.File) bool {
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			if c.Text == generatedHeader {
+				return true
+			}
+		}
+	}
+	return false
+}
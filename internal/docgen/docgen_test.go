@@ -0,0 +1,88 @@
+package docgen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSource = `package sample
+
+import "regexp"
+
+const prefix = ` + "`^[a-z]+`" + `
+
+// EmailPattern validates email addresses
+const EmailPattern = prefix + ` + "`@example\\.com$`" + `
+
+// Version is not a regex
+const Version = "1.0"
+
+const Digits = ` + "`\\d+`" + `
+
+var digits = regexp.MustCompile(Digits)
+`
+
+func writePackage(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "sample.go"), []byte(testSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestFindConstants(t *testing.T) {
+	dir := writePackage(t)
+
+	pkgName, constants, err := FindConstants(dir)
+	if err != nil {
+		t.Fatalf("FindConstants() error = %v", err)
+	}
+	if pkgName != "sample" {
+		t.Errorf("FindConstants() package = %q, want %q", pkgName, "sample")
+	}
+
+	want := []Constant{
+		{Name: "Digits", Pattern: `\d+`},
+		{Name: "EmailPattern", Pattern: `^[a-z]+@example\.com$`},
+	}
+	if !reflect.DeepEqual(constants, want) {
+		t.Errorf("FindConstants() = %v, want %v", constants, want)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir := writePackage(t)
+
+	if _, err := Generate(dir, "", "go"); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, DefaultOutput))
+	if err != nil {
+		t.Fatalf("generated file not written: %v", err)
+	}
+
+	for _, want := range []string{
+		generatedHeader,
+		"package sample",
+		"// EmailPattern matches `^[a-z]+@example\\.com$` (Go Regexp):",
+		"Matches any digit (0-9)",
+		"\tDigits,",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("generated file should contain %q, got:\n%s", want, content)
+		}
+	}
+
+	// Running again must skip the generated file and produce the same output
+	if _, err := Generate(dir, "", "go"); err != nil {
+		t.Fatalf("second Generate() error = %v", err)
+	}
+	again, _ := os.ReadFile(filepath.Join(dir, DefaultOutput))
+	if string(again) != string(content) {
+		t.Error("regenerating documentation should be idempotent")
+	}
+}
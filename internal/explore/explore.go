@@ -0,0 +1,301 @@
+// Package explore implements an interactive terminal view of the matches of
+// a pattern in a file: a scrollable list of matches on the left, moved
+// through with the arrow keys, and the capture groups of the selected match
+// on the right.
+package explore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/weslien/unregex/internal/app"
+)
+
+// ANSI codes the explorer draws with
+const (
+	enterScreen = "\033[?1049h\033[?25l"
+	leaveScreen = "\033[?25h\033[?1049l"
+	clearScreen = "\033[H\033[2J"
+	reverse     = "\033[7m"
+	bold        = "\033[1m"
+	reset       = "\033[0m"
+)
+
+// Key is a key the explorer responds to
+type Key int
+
+const (
+	KeyNone Key = iota
+	KeyUp
+	KeyDown
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	KeyQuit
+)
+
+// Explorer is the state of the view: the matches, which one is selected
+// and the first one the list shows
+type Explorer struct {
+	result        app.MatchResult
+	lines         []string
+	selected, top int
+	width, height int
+}
+
+// New returns an explorer over the matches of a file input, as found by
+// app.MatchAll, drawn in a terminal of width by height characters
+func New(result app.MatchResult, width, height int) *Explorer {
+	lines := strings.Split(result.Input, "\n")
+	return &Explorer{result: result, lines: lines, width: width, height: height}
+}
+
+// Resize sets the size of the terminal the explorer is drawn in
+func (e *Explorer) Resize(width, height int) {
+	e.width, e.height = width, height
+	e.scroll()
+}
+
+// Selected returns the index of the selected match
+func (e *Explorer) Selected() int {
+	return e.selected
+}
+
+// HandleKey moves the selection for a key, and reports false when the key
+// quits the explorer
+func (e *Explorer) HandleKey(key Key) bool {
+	last := len(e.result.Matches) - 1
+	switch key {
+	case KeyQuit:
+		return false
+	case KeyUp:
+		e.selected--
+	case KeyDown:
+		e.selected++
+	case KeyPageUp:
+		e.selected -= e.listHeight()
+	case KeyPageDown:
+		e.selected += e.listHeight()
+	case KeyHome:
+		e.selected = 0
+	case KeyEnd:
+		e.selected = last
+	}
+	e.selected = max(0, min(e.selected, last))
+	e.scroll()
+	return true
+}
+
+// listHeight is how many matches the list shows at once, leaving a line
+// for the title and one for the key help
+func (e *Explorer) listHeight() int {
+	return max(1, e.height-2)
+}
+
+// scroll moves the list so the selected match is visible
+func (e *Explorer) scroll() {
+	if e.selected < e.top {
+		e.top = e.selected
+	}
+	if e.selected >= e.top+e.listHeight() {
+		e.top = e.selected - e.listHeight() + 1
+	}
+}
+
+// Render draws the whole screen: a title, the list of matches with the
+// selected one in reverse video, the capture groups of the selected match
+// beside it and the key help at the bottom
+func (e *Explorer) Render() string {
+	listWidth := max(10, e.width/2-2)
+	panelWidth := max(10, e.width-listWidth-3)
+
+	var list []string
+	for i := e.top; i < len(e.result.Matches) && len(list) < e.listHeight(); i++ {
+		whole := e.result.Matches[i][0]
+		row := pad(fit(fmt.Sprintf(" %d:%d %q", whole.Line, whole.Column, whole.Text), listWidth), listWidth)
+		if i == e.selected {
+			row = reverse + row + reset
+		}
+		list = append(list, row)
+	}
+	panel := e.panel(panelWidth)
+
+	var out strings.Builder
+	out.WriteString(clearScreen)
+	title := fmt.Sprintf("%s: %d matches", e.result.Source, len(e.result.Matches))
+	out.WriteString(bold + fit(title, e.width) + reset + "\r\n")
+	for row := 0; row < e.listHeight(); row++ {
+		left, right := strings.Repeat(" ", listWidth), ""
+		if row < len(list) {
+			left = list[row]
+		}
+		if row < len(panel) {
+			right = panel[row]
+		}
+		out.WriteString(left + " │ " + right + "\r\n")
+	}
+	help := fmt.Sprintf("Match %d of %d   ↑/↓ move   PgUp/PgDn page   Home/End first/last   q quit",
+		e.selected+1, len(e.result.Matches))
+	out.WriteString(fit(help, e.width))
+	return out.String()
+}
+
+// panel lists the line the selected match is on, with the match in bold,
+// and the text and offsets of the match and each of its capture groups
+func (e *Explorer) panel(width int) []string {
+	if len(e.result.Matches) == 0 {
+		return []string{fit("No matches", width)}
+	}
+	spans := e.result.Matches[e.selected]
+	whole := spans[0]
+
+	lines := []string{fit(fmt.Sprintf("Line %d, column %d:", whole.Line, whole.Column), width)}
+	if whole.Line-1 < len(e.lines) {
+		text := e.lines[whole.Line-1]
+		start := whole.Start - lineStart(e.result.Input, whole.Start)
+		end := min(len(text), start+len(whole.Text))
+		before := fit("  "+text[:start], width)
+		if utf8.RuneCountInString(before) < width {
+			match := fit(text[start:end], width-utf8.RuneCountInString(before))
+			after := fit(text[end:], width-utf8.RuneCountInString(before)-utf8.RuneCountInString(match))
+			lines = append(lines, before+bold+match+reset+after)
+		} else {
+			lines = append(lines, before)
+		}
+	}
+	lines = append(lines, "")
+
+	for _, span := range spans {
+		label := "Match"
+		if span.Group > 0 {
+			label = fmt.Sprintf("Group %d", span.Group)
+			if span.Name != "" {
+				label += fmt.Sprintf(" (%s)", span.Name)
+			}
+		}
+		if !span.Matched {
+			lines = append(lines, fit(label+": didn't participate", width))
+			continue
+		}
+		lines = append(lines, fit(fmt.Sprintf("%s: %q", label, span.Text), width))
+		lines = append(lines, fit(fmt.Sprintf("  bytes %d-%d, runes %d-%d", span.Start, span.End, span.RuneStart, span.RuneEnd), width))
+	}
+	return lines
+}
+
+// lineStart returns the byte offset of the start of the line offset is on
+func lineStart(input string, offset int) int {
+	return strings.LastIndexByte(input[:offset], '\n') + 1
+}
+
+// fit cuts text to width characters, ending it with … when it's cut
+func fit(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:width-1]) + "…"
+}
+
+// printable replaces the tabs, carriage returns and other control
+// characters of a line with spaces, so they don't break the layout
+func printable(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, text)
+}
+
+// pad fills text with spaces up to width characters
+func pad(text string, width int) string {
+	return text + strings.Repeat(" ", max(0, width-utf8.RuneCountInString(text)))
+}
+
+// ParseKeys turns the bytes a terminal sends for key presses into keys,
+// ignoring the ones the explorer doesn't use
+func ParseKeys(input []byte) []Key {
+	sequences := []struct {
+		bytes string
+		key   Key
+	}{
+		{"\033[A", KeyUp}, {"\033OA", KeyUp}, {"k", KeyUp},
+		{"\033[B", KeyDown}, {"\033OB", KeyDown}, {"j", KeyDown},
+		{"\033[5~", KeyPageUp}, {"\033[6~", KeyPageDown}, {" ", KeyPageDown},
+		{"\033[H", KeyHome}, {"\033[1~", KeyHome}, {"\033OH", KeyHome}, {"g", KeyHome},
+		{"\033[F", KeyEnd}, {"\033[4~", KeyEnd}, {"\033OF", KeyEnd}, {"G", KeyEnd},
+		{"q", KeyQuit}, {"\x03", KeyQuit},
+	}
+
+	var keys []Key
+	for text := string(input); text != ""; {
+		matched := false
+		for _, s := range sequences {
+			if strings.HasPrefix(text, s.bytes) {
+				keys = append(keys, s.key)
+				text = text[len(s.bytes):]
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		// A lone escape quits, and other sequences are skipped whole
+		if text == "\033" {
+			keys = append(keys, KeyQuit)
+		}
+		if strings.HasPrefix(text, "\033[") {
+			end := strings.IndexFunc(text[2:], func(r rune) bool { return r >= '@' && r <= '~' })
+			if end >= 0 {
+				text = text[2+end+1:]
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+	}
+	return keys
+}
+
+// Run shows the explorer in the terminal on in and out until the user
+// quits, restoring the terminal afterwards
+func Run(in *os.File, out io.Writer, result app.MatchResult) error {
+	if len(result.Matches) == 0 {
+		return errors.New("there are no matches to explore")
+	}
+	restore, err := makeRaw(in.Fd())
+	if err != nil {
+		return fmt.Errorf("the explorer needs an interactive terminal: %v", err)
+	}
+	defer restore()
+
+	width, height := termSize(in.Fd())
+	explorer := New(result, width, height)
+	fmt.Fprint(out, enterScreen)
+	defer fmt.Fprint(out, leaveScreen)
+
+	buf := make([]byte, 64)
+	for {
+		fmt.Fprint(out, explorer.Render())
+		n, err := in.Read(buf)
+		if err != nil {
+			return err
+		}
+		explorer.Resize(termSize(in.Fd()))
+		for _, key := range ParseKeys(buf[:n]) {
+			if !explorer.HandleKey(key) {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package explore
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/weslien/unregex/internal/app"
+)
+
+func exploreResult(t *testing.T, pattern, input string) app.MatchResult {
+	t.Helper()
+	r, err := app.CompilePattern(pattern, "go", "")
+	if err != nil {
+		t.Fatalf("CompilePattern(%q) returned error: %v", pattern, err)
+	}
+	result := app.MatchAll(r, input)
+	result.Source = "log.txt"
+	return result
+}
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []Key
+	}{
+		{"\033[A\033[B", []Key{KeyUp, KeyDown}},
+		{"jjk", []Key{KeyDown, KeyDown, KeyUp}},
+		{"\033[5~\033[6~", []Key{KeyPageUp, KeyPageDown}},
+		{"\033[H\033[F", []Key{KeyHome, KeyEnd}},
+		{"\033OA", []Key{KeyUp}},
+		{"x\033[C\033[3~j", []Key{KeyDown}},
+		{"q", []Key{KeyQuit}},
+		{"\x03", []Key{KeyQuit}},
+		{"\033", []Key{KeyQuit}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseKeys([]byte(tt.input)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseKeys(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestExplorer_HandleKey(t *testing.T) {
+	result := exploreResult(t, `\d`, "1\n2\n3\n4\n5\n6\n7\n8\n9\n")
+	explorer := New(result, 60, 5)
+
+	steps := []struct {
+		key  Key
+		want int
+	}{
+		{KeyUp, 0},
+		{KeyDown, 1},
+		{KeyPageDown, 4},
+		{KeyEnd, 8},
+		{KeyDown, 8},
+		{KeyPageUp, 5},
+		{KeyHome, 0},
+	}
+	for _, step := range steps {
+		if !explorer.HandleKey(step.key) {
+			t.Fatalf("HandleKey(%v) quit", step.key)
+		}
+		if got := explorer.Selected(); got != step.want {
+			t.Errorf("after HandleKey(%v) Selected() = %d, want %d", step.key, got, step.want)
+		}
+	}
+	if explorer.HandleKey(KeyQuit) {
+		t.Errorf("HandleKey(KeyQuit) = true, want false")
+	}
+}
+
+func TestExplorer_Render(t *testing.T) {
+	result := exploreResult(t, `(?P<verb>[A-Z]+) (/\w)`, "GET /a 200\nPOST /b 500\nGET /c 500\n")
+	explorer := New(result, 80, 12)
+	explorer.HandleKey(KeyDown)
+	screen := explorer.Render()
+
+	for _, want := range []string{
+		"log.txt: 3 matches",
+		reverse + ` 2:1 "POST /b"`,
+		"Line 2, column 1:",
+		bold + "POST /b" + reset + " 500",
+		`Group 1 (verb): "POST"`,
+		"bytes 11-15, runes 11-15",
+		`Group 2: "/b"`,
+		"Match 2 of 3",
+	} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("Render() = %q, want it to contain %q", screen, want)
+		}
+	}
+
+	// Every row of the list is as wide as the list, so the panel lines up
+	for _, line := range strings.Split(screen, "\r\n")[1:4] {
+		line = strings.NewReplacer(reverse, "", reset, "", bold, "").Replace(line)
+		if strings.Index(line, "│") != strings.Index(strings.Split(screen, "\r\n")[4], "│") {
+			t.Errorf("Render() row %q isn't aligned", line)
+		}
+	}
+}
+
+func TestExplorer_ScrollsToSelection(t *testing.T) {
+	result := exploreResult(t, `\d`, "1\n2\n3\n4\n5\n6\n7\n8\n9\n")
+	explorer := New(result, 60, 5)
+	explorer.HandleKey(KeyEnd)
+	screen := explorer.Render()
+	if !strings.Contains(screen, reverse+` 9:1 "9"`) || strings.Contains(screen, ` 1:1 "1"`) {
+		t.Errorf("Render() after KeyEnd = %q, want the last matches listed", screen)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build darwin || freebsd
+
+package explore
+
+import "syscall"
+
+// Requests that get and set the terminal attributes
+const (
+	getTermios = syscall.TIOCGETA
+	setTermios = syscall.TIOCSETA
+)
@@ -0,0 +1,11 @@
+//go:build linux
+
+package explore
+
+import "syscall"
+
+// Requests that get and set the terminal attributes
+const (
+	getTermios = syscall.TCGETS
+	setTermios = syscall.TCSETS
+)
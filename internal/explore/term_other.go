@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !freebsd
+
+package explore
+
+import "errors"
+
+// makeRaw can't change terminal modes on this platform
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errors.New("raw terminal mode isn't supported on this platform")
+}
+
+// termSize can't query the terminal on this platform
+func termSize(fd uintptr) (int, int) {
+	return 80, 24
+}
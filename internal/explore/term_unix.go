@@ -0,0 +1,41 @@
+//go:build linux || darwin || freebsd
+
+package explore
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// makeRaw puts the terminal on fd in raw mode, so key presses are read as
+// they're typed without being echoed, and returns a function restoring it
+func makeRaw(fd uintptr) (func(), error) {
+	var saved syscall.Termios
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, getTermios, uintptr(unsafe.Pointer(&saved))); errno != 0 {
+		return nil, errno
+	}
+
+	raw := saved
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN], raw.Cc[syscall.VTIME] = 1, 0
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, setTermios, uintptr(unsafe.Pointer(&raw))); errno != 0 {
+		return nil, errno
+	}
+	return func() {
+		syscall.Syscall(syscall.SYS_IOCTL, fd, setTermios, uintptr(unsafe.Pointer(&saved)))
+	}, nil
+}
+
+// termSize asks the terminal on fd for its width and height, falling back
+// to 80 by 24
+func termSize(fd uintptr) (int, int) {
+	var size struct {
+		rows, cols, xpixel, ypixel uint16
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&size)))
+	if errno != 0 || size.cols == 0 || size.rows == 0 {
+		return 80, 24
+	}
+	return int(size.cols), int(size.rows)
+}
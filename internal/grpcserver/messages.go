@@ -0,0 +1,152 @@
+package grpcserver
+
+import (
+	"github.com/weslien/unregex/internal/app"
+)
+
+// explainRequest is an ExplainRequest, which a LintRequest shares the
+// first fields of
+type explainRequest struct {
+	Pattern, Format, Flags string
+}
+
+func (r *explainRequest) unmarshal(b []byte) error {
+	return decodeStrings(b, map[int]*string{1: &r.Pattern, 2: &r.Format, 3: &r.Flags}, nil)
+}
+
+// testRequest is a TestRequest
+type testRequest struct {
+	Pattern, Format, Flags string
+	Inputs                 []string
+}
+
+func (r *testRequest) unmarshal(b []byte) error {
+	return decodeStrings(b, map[int]*string{1: &r.Pattern, 2: &r.Format, 3: &r.Flags}, map[int]*[]string{4: &r.Inputs})
+}
+
+// convertRequest is a ConvertRequest
+type convertRequest struct {
+	Pattern, Format, To, Flags string
+}
+
+func (r *convertRequest) unmarshal(b []byte) error {
+	return decodeStrings(b, map[int]*string{1: &r.Pattern, 2: &r.Format, 3: &r.To, 4: &r.Flags}, nil)
+}
+
+// decodeStrings decodes a message whose fields are all strings into the
+// strings and lists of strings for their numbers, skipping fields it
+// doesn't know, as newer clients may send them
+func decodeStrings(b []byte, strings map[int]*string, lists map[int]*[]string) error {
+	fields, err := parseFields(b)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		var target *string
+		var value string
+		if target = strings[f.Number]; target == nil && lists[f.Number] == nil {
+			continue
+		}
+		if value, err = stringField(f); err != nil {
+			return err
+		}
+		if target != nil {
+			*target = value
+		} else {
+			*lists[f.Number] = append(*lists[f.Number], value)
+		}
+	}
+	return nil
+}
+
+// encodeExplanation encodes an Explanation
+func encodeExplanation(exp *app.Explanation) []byte {
+	var b []byte
+	b = appendString(b, 1, exp.Pattern)
+	b = appendString(b, 2, exp.FormatName)
+	b = appendString(b, 3, exp.Format)
+	for _, token := range exp.Tokens {
+		var t []byte
+		t = appendString(t, 1, token.Token)
+		t = appendString(t, 2, token.Explanation)
+		t = appendString(t, 3, app.TokenCategory(token.Token))
+		b = appendMessage(b, 4, t)
+	}
+	for _, feature := range exp.Features {
+		var f []byte
+		f = appendString(f, 1, feature.Name)
+		f = appendString(f, 2, feature.Syntax)
+		f = appendBool(f, 3, feature.Supported)
+		b = appendMessage(b, 5, f)
+	}
+	b = appendString(b, 6, exp.Sample)
+	return appendString(b, 7, exp.SampleStatus)
+}
+
+// encodeExplainError encodes an Explanation of a pattern that couldn't be
+// explained, for ExplainBatch, which answers every request in the stream
+func encodeExplainError(pattern string, err error) []byte {
+	var b []byte
+	b = appendString(b, 1, pattern)
+	return appendString(b, 8, err.Error())
+}
+
+// encodeTestResponse encodes a TestResponse with the matches in each input
+func encodeTestResponse(results []app.MatchResult) []byte {
+	var b []byte
+	for _, result := range results {
+		var r []byte
+		r = appendString(r, 1, result.Input)
+		r = appendBool(r, 2, result.Matched)
+		for _, spans := range result.Matches {
+			var m []byte
+			for _, span := range spans {
+				var s []byte
+				s = appendInt(s, 1, span.Group)
+				s = appendString(s, 2, span.Name)
+				s = appendBool(s, 3, span.Matched)
+				s = appendString(s, 4, span.Text)
+				s = appendInt(s, 5, span.Start)
+				s = appendInt(s, 6, span.End)
+				s = appendInt(s, 7, span.RuneStart)
+				s = appendInt(s, 8, span.RuneEnd)
+				m = appendMessage(m, 1, s)
+			}
+			r = appendMessage(r, 3, m)
+		}
+		b = appendMessage(b, 1, r)
+	}
+	return b
+}
+
+// encodeConversion encodes a ConvertResponse
+func encodeConversion(c *app.Conversion) []byte {
+	var b []byte
+	b = appendString(b, 1, c.Converted)
+	b = appendString(b, 2, c.Flags)
+	b = appendBool(b, 3, c.Proven)
+	return appendString(b, 4, c.Unproven)
+}
+
+// Severities of a Finding
+const (
+	severityError   = 1
+	severityWarning = 2
+)
+
+// encodeFindings encodes a LintResponse
+func encodeFindings(findings []app.Finding) []byte {
+	var b []byte
+	for _, finding := range findings {
+		severity := severityWarning
+		if finding.Severity == app.SeverityError {
+			severity = severityError
+		}
+		var f []byte
+		f = appendInt(f, 1, severity)
+		f = appendInt(f, 2, finding.Offset)
+		f = appendString(f, 3, finding.Message)
+		b = appendMessage(b, 1, f)
+	}
+	return b
+}
@@ -0,0 +1,15 @@
+package grpcserver
+
+import (
+	"net"
+	"net/http"
+)
+
+// Serve serves the Unregex service on a listener over HTTP/2 without TLS,
+// as gRPC clients connecting to an insecure channel expect
+func Serve(listener net.Listener, s *Server) error {
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	server := &http.Server{Handler: s, Protocols: &protocols}
+	return server.Serve(listener)
+}
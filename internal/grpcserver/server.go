@@ -0,0 +1,303 @@
+// Package grpcserver implements the gRPC service in
+// api/unregex/v1/unregex.proto, which explains, matches, converts and lints
+// patterns. It speaks the gRPC protocol over net/http's HTTP/2 support and
+// encodes the service's messages itself, so unregex needs no gRPC or
+// protocol buffer libraries; clients use stubs generated from the .proto.
+package grpcserver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/weslien/unregex/internal/app"
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// servicePath is the path prefix of the service's methods
+const servicePath = "/unregex.v1.Unregex/"
+
+// maxMessageSize caps the size of a request message, as gRPC's own servers
+// do by default
+const maxMessageSize = 4 << 20
+
+// gRPC status codes
+const (
+	codeOK                = 0
+	codeInvalidArgument   = 3
+	codeResourceExhausted = 8
+	codeUnimplemented     = 12
+	codeInternal          = 13
+)
+
+// statusError is an error answered with a gRPC status code
+type statusError struct {
+	code    int
+	message string
+}
+
+func (e *statusError) Error() string {
+	return e.message
+}
+
+// Server serves the Unregex gRPC service
+type Server struct{}
+
+// NewServer returns a server for the Unregex service
+func NewServer() *Server {
+	return &Server{}
+}
+
+// unaryMethods are the methods that answer one request with one response
+var unaryMethods = map[string]func(*Server, []byte) ([]byte, error){
+	"Explain": (*Server).explain,
+	"Test":    (*Server).test,
+	"Convert": (*Server).convert,
+	"Lint":    (*Server).lint,
+}
+
+// ServeHTTP answers a gRPC call, which has to come over HTTP/2
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ProtoMajor != 2 {
+		http.Error(w, "gRPC calls need a POST over HTTP/2", http.StatusMethodNotAllowed)
+		return
+	}
+	if contentType := r.Header.Get("Content-Type"); contentType != "application/grpc" && !strings.HasPrefix(contentType, "application/grpc+proto") {
+		http.Error(w, "gRPC calls need the application/grpc content type", http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	w.WriteHeader(http.StatusOK)
+	flush(w)
+
+	method := strings.TrimPrefix(r.URL.Path, servicePath)
+	handle, ok := unaryMethods[method]
+	var err error
+	switch {
+	case ok:
+		err = s.unary(w, r.Body, handle)
+	case method == "ExplainBatch":
+		err = s.explainBatch(w, r.Body)
+	default:
+		err = &statusError{codeUnimplemented, fmt.Sprintf("unknown method %s", r.URL.Path)}
+	}
+	writeStatus(w, err)
+}
+
+// unary reads the one request message of a call and writes its response
+func (s *Server) unary(w http.ResponseWriter, body io.Reader, handle func(*Server, []byte) ([]byte, error)) error {
+	message, err := readMessage(body)
+	if err == io.EOF {
+		return &statusError{codeInvalidArgument, "the call has no request message"}
+	} else if err != nil {
+		return err
+	}
+	response, err := handle(s, message)
+	if err != nil {
+		return err
+	}
+	return writeMessage(w, response)
+}
+
+// explainBatch explains each pattern in a stream of requests as it arrives.
+// A pattern that can't be explained is answered with an Explanation that
+// says why, so the rest of the stream still is.
+func (s *Server) explainBatch(w http.ResponseWriter, body io.Reader) error {
+	for {
+		message, err := readMessage(body)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		var req explainRequest
+		if err := req.unmarshal(message); err != nil {
+			return &statusError{codeInvalidArgument, fmt.Sprintf("invalid request: %v", err)}
+		}
+		response, err := s.explainPattern(req)
+		if err != nil {
+			response = encodeExplainError(req.Pattern, err)
+		}
+		if err := writeMessage(w, response); err != nil {
+			return err
+		}
+		flush(w)
+	}
+}
+
+// flush sends what's been written of a response to the client
+func flush(w http.ResponseWriter) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// explain answers an Explain call
+func (s *Server) explain(message []byte) ([]byte, error) {
+	var req explainRequest
+	if err := req.unmarshal(message); err != nil {
+		return nil, &statusError{codeInvalidArgument, fmt.Sprintf("invalid request: %v", err)}
+	}
+	return s.explainPattern(req)
+}
+
+// explainPattern explains the pattern of an ExplainRequest
+func (s *Server) explainPattern(req explainRequest) ([]byte, error) {
+	flavor, flags, err := resolve(req.Pattern, req.Format, req.Flags)
+	if err != nil {
+		return nil, err
+	}
+	return encodeExplanation(app.AnalyzeWithFlags(req.Pattern, flavor, flags)), nil
+}
+
+// test answers a Test call, matching the pattern against each input
+func (s *Server) test(message []byte) ([]byte, error) {
+	var req testRequest
+	if err := req.unmarshal(message); err != nil {
+		return nil, &statusError{codeInvalidArgument, fmt.Sprintf("invalid request: %v", err)}
+	}
+	flavor, flags, err := resolve(req.Pattern, req.Format, req.Flags)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := app.CompilePattern(req.Pattern, flavor, flags)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]app.MatchResult, len(req.Inputs))
+	for i, input := range req.Inputs {
+		results[i] = app.MatchAll(compiled, input)
+	}
+	return encodeTestResponse(results), nil
+}
+
+// convert answers a Convert call
+func (s *Server) convert(message []byte) ([]byte, error) {
+	var req convertRequest
+	if err := req.unmarshal(message); err != nil {
+		return nil, &statusError{codeInvalidArgument, fmt.Sprintf("invalid request: %v", err)}
+	}
+	flavor, flags, err := resolve(req.Pattern, req.Format, req.Flags)
+	if err != nil {
+		return nil, err
+	}
+	to := strings.ToLower(req.To)
+	if to == "" {
+		return nil, errors.New("no flavor to convert to provided")
+	}
+	if _, ok := format.Lookup(to); !ok {
+		return nil, &app.ErrUnknownFormat{Format: to}
+	}
+
+	conversion, err := app.ConvertPattern(req.Pattern, flavor, to, flags)
+	if err != nil {
+		return nil, err
+	}
+	return encodeConversion(conversion), nil
+}
+
+// lint answers a Lint call
+func (s *Server) lint(message []byte) ([]byte, error) {
+	var req explainRequest
+	if err := req.unmarshal(message); err != nil {
+		return nil, &statusError{codeInvalidArgument, fmt.Sprintf("invalid request: %v", err)}
+	}
+	flavor, _, err := resolve(req.Pattern, req.Format, "")
+	if err != nil {
+		return nil, err
+	}
+	return encodeFindings(app.Lint(req.Pattern, flavor)), nil
+}
+
+// resolve checks the pattern, flavor and flags of a request, returning the
+// flavor, go by default, and the flags as the flavor's letters
+func resolve(pattern, flavor, flags string) (string, string, error) {
+	if pattern == "" {
+		return "", "", errors.New("no regex pattern provided")
+	}
+	flavor = strings.ToLower(flavor)
+	if flavor == "" {
+		flavor = "go"
+	}
+	if _, ok := format.Lookup(flavor); !ok {
+		return "", "", &app.ErrUnknownFormat{Format: flavor}
+	}
+	resolved, err := app.ResolveFlags(flavor, flags)
+	if err != nil {
+		return "", "", err
+	}
+	return flavor, resolved, nil
+}
+
+// readMessage reads the next length-prefixed message of a call, returning
+// io.EOF when the client has sent them all
+func readMessage(body io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(body, header[:]); err == io.EOF {
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, &statusError{codeInternal, fmt.Sprintf("reading the request: %v", err)}
+	}
+	if header[0] != 0 {
+		return nil, &statusError{codeUnimplemented, "compressed messages aren't supported"}
+	}
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > maxMessageSize {
+		return nil, &statusError{codeResourceExhausted, fmt.Sprintf("the request message is %d bytes, more than the %d allowed", length, maxMessageSize)}
+	}
+	message := make([]byte, length)
+	if _, err := io.ReadFull(body, message); err != nil {
+		return nil, &statusError{codeInternal, fmt.Sprintf("reading the request: %v", err)}
+	}
+	return message, nil
+}
+
+// writeMessage writes a length-prefixed response message
+func writeMessage(w io.Writer, message []byte) error {
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	if _, err := w.Write(append(frame, message...)); err != nil {
+		return &statusError{codeInternal, fmt.Sprintf("writing the response: %v", err)}
+	}
+	return nil
+}
+
+// writeStatus ends a call with the status of its error in the trailers.
+// Errors in the request, such as syntax errors and unknown flavors, are
+// invalid arguments, and patterns that need features Go's regexp package
+// lacks are unimplemented.
+func writeStatus(w http.ResponseWriter, err error) {
+	code := codeOK
+	var status *statusError
+	switch {
+	case err == nil:
+	case errors.As(err, &status):
+		code = status.code
+	case app.ExitCode(err) == app.ExitUnsupported:
+		code = codeUnimplemented
+	default:
+		code = codeInvalidArgument
+	}
+	w.Header().Set("Grpc-Status", fmt.Sprint(code))
+	if err != nil {
+		w.Header().Set("Grpc-Message", encodeStatusMessage(err.Error()))
+	}
+}
+
+// encodeStatusMessage percent-encodes a status message, as gRPC requires
+// of bytes outside printable ASCII
+func encodeStatusMessage(message string) string {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		if c := message[i]; c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
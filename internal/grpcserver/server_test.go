@@ -0,0 +1,267 @@
+package grpcserver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// newTestServer starts the service over HTTP/2, with TLS as net/http's
+// test servers only negotiate HTTP/2 that way
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewUnstartedServer(NewServer())
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// frame encodes a request message as gRPC sends it
+func frame(message []byte) []byte {
+	framed := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(framed[1:], uint32(len(message)))
+	return append(framed, message...)
+}
+
+// call sends request messages to a method and returns the response
+// messages, the status code and the status message
+func call(t *testing.T, ts *httptest.Server, method string, requests ...[]byte) ([][]byte, int, string) {
+	t.Helper()
+	var body bytes.Buffer
+	for _, request := range requests {
+		body.Write(frame(request))
+	}
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+servicePath+method, &body)
+	req.Header.Set("Content-Type", "application/grpc")
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("calling %s: %v", method, err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("calling %s used %s, want HTTP/2", method, resp.Proto)
+	}
+
+	var responses [][]byte
+	for {
+		message, err := readMessage(resp.Body)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("reading the response of %s: %v", method, err)
+		}
+		responses = append(responses, message)
+	}
+	code, err := strconv.Atoi(resp.Trailer.Get("Grpc-Status"))
+	if err != nil {
+		t.Fatalf("calling %s: invalid grpc-status %q", method, resp.Trailer.Get("Grpc-Status"))
+	}
+	return responses, code, resp.Trailer.Get("Grpc-Message")
+}
+
+// decode splits a message into its fields by number, with the fields
+// repeated under the same number in order
+func decode(t *testing.T, message []byte) map[int][]field {
+	t.Helper()
+	fields, err := parseFields(message)
+	if err != nil {
+		t.Fatalf("invalid response message: %v", err)
+	}
+	byNumber := map[int][]field{}
+	for _, f := range fields {
+		byNumber[f.Number] = append(byNumber[f.Number], f)
+	}
+	return byNumber
+}
+
+func TestExplain(t *testing.T) {
+	ts := newTestServer(t)
+	var request []byte
+	request = appendString(request, 1, `(?P<n>\d+)x`)
+	request = appendString(request, 2, "PCRE")
+	responses, code, message := call(t, ts, "Explain", request)
+	if code != codeOK || len(responses) != 1 {
+		t.Fatalf("Explain = %d responses, status %d %q, want one response", len(responses), code, message)
+	}
+
+	exp := decode(t, responses[0])
+	if got := string(exp[2][0].Bytes); got != "pcre" {
+		t.Errorf("Explain format_name = %q, want pcre", got)
+	}
+	if len(exp[4]) != 5 {
+		t.Fatalf("Explain returned %d tokens, want 5", len(exp[4]))
+	}
+	token := decode(t, exp[4][1].Bytes)
+	if got, category := string(token[1][0].Bytes), string(token[3][0].Bytes); got != `\d` || category != "escape" {
+		t.Errorf("Explain token 2 = %q (%s), want \\d (escape)", got, category)
+	}
+	if len(exp[6]) != 1 {
+		t.Errorf("Explain returned no sample")
+	}
+}
+
+func TestExplain_Errors(t *testing.T) {
+	ts := newTestServer(t)
+	tests := []struct {
+		pattern, format string
+		want            string
+	}{
+		{"", "", "no regex pattern provided"},
+		{"a+", "perl6", "unsupported regex format 'perl6'"},
+	}
+	for _, tt := range tests {
+		var request []byte
+		request = appendString(request, 1, tt.pattern)
+		request = appendString(request, 2, tt.format)
+		responses, code, message := call(t, ts, "Explain", request)
+		if code != codeInvalidArgument || len(responses) != 0 || message != tt.want {
+			t.Errorf("Explain(%q, %q) = status %d %q, want %d %q", tt.pattern, tt.format, code, message, codeInvalidArgument, tt.want)
+		}
+	}
+
+	if _, code, _ := call(t, ts, "Explain"); code != codeInvalidArgument {
+		t.Errorf("Explain without a request = status %d, want %d", code, codeInvalidArgument)
+	}
+	if _, code, _ := call(t, ts, "Frobnicate", nil); code != codeUnimplemented {
+		t.Errorf("unknown method = status %d, want %d", code, codeUnimplemented)
+	}
+}
+
+func TestExplainBatch(t *testing.T) {
+	ts := newTestServer(t)
+
+	// Each request is answered before the next one is sent
+	reader, writer := io.Pipe()
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+servicePath+"ExplainBatch", reader)
+	req.Header.Set("Content-Type", "application/grpc")
+	responses := make(chan *http.Response, 1)
+	go func() {
+		resp, err := ts.Client().Do(req)
+		if err != nil {
+			t.Errorf("calling ExplainBatch: %v", err)
+			close(responses)
+			return
+		}
+		responses <- resp
+	}()
+
+	var resp *http.Response
+	for i, pattern := range []string{"a+", "", "[0-9]"} {
+		writer.Write(frame(appendString(nil, 1, pattern)))
+		if resp == nil {
+			if resp = <-responses; resp == nil {
+				return
+			}
+			defer resp.Body.Close()
+		}
+		message, err := readMessage(resp.Body)
+		if err != nil {
+			t.Fatalf("reading answer %d of ExplainBatch: %v", i+1, err)
+		}
+		exp := decode(t, message)
+		if pattern == "" {
+			if len(exp[8]) != 1 || string(exp[8][0].Bytes) != "no regex pattern provided" {
+				t.Errorf("ExplainBatch answered an empty pattern with %v, want an error", exp)
+			}
+		} else if len(exp[1]) != 1 || string(exp[1][0].Bytes) != pattern || len(exp[4]) == 0 {
+			t.Errorf("ExplainBatch answer %d doesn't explain %q", i+1, pattern)
+		}
+	}
+	writer.Close()
+	if _, err := readMessage(resp.Body); err != io.EOF {
+		t.Errorf("ExplainBatch sent more answers than requests: %v", err)
+	}
+	if code := resp.Trailer.Get("Grpc-Status"); code != "0" {
+		t.Errorf("ExplainBatch status = %s, want 0", code)
+	}
+}
+
+func TestTest(t *testing.T) {
+	ts := newTestServer(t)
+	var request []byte
+	request = appendString(request, 1, `(?P<n>\d+)x`)
+	request = appendString(request, 4, "1x 22x")
+	request = appendString(request, 4, "no")
+	responses, code, message := call(t, ts, "Test", request)
+	if code != codeOK || len(responses) != 1 {
+		t.Fatalf("Test = status %d %q, want one response", code, message)
+	}
+
+	results := decode(t, responses[0])[1]
+	if len(results) != 2 {
+		t.Fatalf("Test returned %d results, want 2", len(results))
+	}
+	first, second := decode(t, results[0].Bytes), decode(t, results[1].Bytes)
+	if len(first[2]) != 1 || len(first[3]) != 2 || len(second[2]) != 0 || len(second[3]) != 0 {
+		t.Fatalf("Test results = %v, %v, want two matches in the first input only", first, second)
+	}
+	group := decode(t, decode(t, first[3][1].Bytes)[1][1].Bytes)
+	if string(group[2][0].Bytes) != "n" || string(group[4][0].Bytes) != "22" || group[5][0].Varint != 3 {
+		t.Errorf("Test second match group = %v, want n: 22 at 3", group)
+	}
+
+	// Patterns Go can't match are unimplemented rather than invalid
+	if _, code, _ := call(t, ts, "Test", appendString(appendString(nil, 1, `(?<=a)b`), 2, "pcre")); code != codeUnimplemented {
+		t.Errorf("Test with a lookbehind = status %d, want %d", code, codeUnimplemented)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	ts := newTestServer(t)
+	var request []byte
+	request = appendString(request, 1, `(?i)(?P<year>\d{4})-\d\d`)
+	request = appendString(request, 3, "js")
+	responses, code, message := call(t, ts, "Convert", request)
+	if code != codeOK || len(responses) != 1 {
+		t.Fatalf("Convert = status %d %q, want one response", code, message)
+	}
+	conversion := decode(t, responses[0])
+	if got, flags := string(conversion[1][0].Bytes), string(conversion[2][0].Bytes); got != `(?<year>\d{4})-\d\d` || flags != "i" {
+		t.Errorf("Convert = %q with flags %q, want (?<year>\\d{4})-\\d\\d with i", got, flags)
+	}
+	if len(conversion[3]) != 1 {
+		t.Errorf("Convert didn't prove the conversion")
+	}
+
+	if _, code, message := call(t, ts, "Convert", appendString(nil, 1, "a")); code != codeInvalidArgument {
+		t.Errorf("Convert without a target flavor = status %d %q, want %d", code, message, codeInvalidArgument)
+	}
+}
+
+func TestLint(t *testing.T) {
+	ts := newTestServer(t)
+	responses, code, message := call(t, ts, "Lint", appendString(appendString(nil, 1, `(a`), 2, "go"))
+	if code != codeOK || len(responses) != 1 {
+		t.Fatalf("Lint = status %d %q, want one response", code, message)
+	}
+	findings := decode(t, responses[0])[1]
+	if len(findings) == 0 {
+		t.Fatal("Lint found nothing wrong with (a")
+	}
+	if finding := decode(t, findings[0].Bytes); finding[1][0].Varint != severityError {
+		t.Errorf("Lint finding = %v, want an error", finding)
+	}
+}
+
+func TestServeHTTP_NotGRPC(t *testing.T) {
+	ts := newTestServer(t)
+	resp, err := ts.Client().Post(ts.URL+servicePath+"Explain", "application/json", bytes.NewReader([]byte("{}")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("POST with a JSON body = %d, want %d", resp.StatusCode, http.StatusUnsupportedMediaType)
+	}
+}
+
+func TestEncodeStatusMessage(t *testing.T) {
+	if got, want := encodeStatusMessage("100% ✓\n"), "100%25 %E2%9C%93%0A"; got != want {
+		t.Errorf("encodeStatusMessage = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+package grpcserver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Protocol buffer wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// field is one field of an encoded protocol buffer message. Varint holds
+// the value of a varint field and Bytes that of a length-delimited one.
+type field struct {
+	Number   int
+	WireType int
+	Varint   uint64
+	Bytes    []byte
+}
+
+// parseFields splits an encoded message into its fields, in the order they
+// were written. Fixed-size fields are skipped, as no message of the
+// service has any, and groups, which proto3 dropped, are an error.
+func parseFields(b []byte) ([]field, error) {
+	var fields []field
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errors.New("truncated field tag")
+		}
+		b = b[n:]
+		f := field{Number: int(tag >> 3), WireType: int(tag & 7)}
+		if f.Number == 0 {
+			return nil, errors.New("field number 0")
+		}
+
+		switch f.WireType {
+		case wireVarint:
+			if f.Varint, n = binary.Uvarint(b); n <= 0 {
+				return nil, fmt.Errorf("truncated varint in field %d", f.Number)
+			}
+			b = b[n:]
+		case wireBytes:
+			length, n := binary.Uvarint(b)
+			if n <= 0 || length > uint64(len(b)-n) {
+				return nil, fmt.Errorf("truncated bytes in field %d", f.Number)
+			}
+			f.Bytes = b[n : n+int(length)]
+			b = b[n+int(length):]
+		case wireFixed64, wireFixed32:
+			size := 8
+			if f.WireType == wireFixed32 {
+				size = 4
+			}
+			if len(b) < size {
+				return nil, fmt.Errorf("truncated fixed-size field %d", f.Number)
+			}
+			b = b[size:]
+			continue
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d in field %d", f.WireType, f.Number)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// stringField returns the value of a string field, or an error if the
+// field was encoded as something else
+func stringField(f field) (string, error) {
+	if f.WireType != wireBytes {
+		return "", fmt.Errorf("field %d should be a string", f.Number)
+	}
+	return string(f.Bytes), nil
+}
+
+// appendTag appends the tag of a field
+func appendTag(b []byte, number, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(number)<<3|uint64(wireType))
+}
+
+// appendString appends a string field, leaving it out when it's empty, as
+// proto3 does with default values
+func appendString(b []byte, number int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = appendTag(b, number, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// appendBool appends a bool field, leaving it out when it's false
+func appendBool(b []byte, number int, v bool) []byte {
+	if !v {
+		return b
+	}
+	b = appendTag(b, number, wireVarint)
+	return append(b, 1)
+}
+
+// appendInt appends an int32 or enum field, leaving it out when it's 0.
+// Negative numbers take ten bytes, as they're sign-extended to 64 bits.
+func appendInt(b []byte, number int, v int) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, number, wireVarint)
+	return binary.AppendUvarint(b, uint64(int64(v)))
+}
+
+// appendMessage appends an embedded message field, which is written even
+// when it's empty, so an element of a repeated field isn't lost
+func appendMessage(b []byte, number int, message []byte) []byte {
+	b = appendTag(b, number, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(message)))
+	return append(b, message...)
+}
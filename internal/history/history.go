@@ -0,0 +1,152 @@
+// Package history records explained patterns so they can be searched and
+// explained again later
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MaxEntries is the number of entries kept; older ones are dropped
+const MaxEntries = 1000
+
+// Entry is an explained pattern
+type Entry struct {
+	ID      int       `json:"id"`
+	Pattern string    `json:"pattern"`
+	Format  string    `json:"format"`
+	Time    time.Time `json:"time"`
+}
+
+// Path returns the location of the history file, history.jsonl in the
+// unregex directory of the user's config directory
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "unregex", "history.jsonl"), nil
+}
+
+// Load reads the history file at path, oldest entry first. A missing file
+// yields an empty history.
+func Load(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid history %s:%d: %v", path, lineNumber, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history %s: %v", path, err)
+	}
+	return entries, nil
+}
+
+// Add records a pattern in the history file at path and returns its entry.
+// Once the history holds more than MaxEntries, the oldest are dropped.
+func Add(path, pattern, formatName string, now time.Time) (Entry, error) {
+	entries, err := Load(path)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{ID: 1, Pattern: pattern, Format: formatName, Time: now}
+	if len(entries) > 0 {
+		entry.ID = entries[len(entries)-1].ID + 1
+	}
+	entries = append(entries, entry)
+
+	if len(entries) > MaxEntries {
+		return entry, write(path, entries[len(entries)-MaxEntries:])
+	}
+	return entry, appendEntry(path, entry)
+}
+
+// Find returns the entry with the given ID
+func Find(entries []Entry, id int) (Entry, bool) {
+	for _, entry := range entries {
+		if entry.ID == id {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Search returns the entries whose pattern contains query, ignoring case
+func Search(entries []Entry, query string) []Entry {
+	query = strings.ToLower(query)
+	var matches []Entry
+	for _, entry := range entries {
+		if strings.Contains(strings.ToLower(entry.Pattern), query) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// appendEntry adds one entry to the end of the history file
+func appendEntry(path string, entry Entry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history %s: %v", path, err)
+	}
+	defer file.Close()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write history %s: %v", path, err)
+	}
+	return nil
+}
+
+// write replaces the history file with entries
+func write(path string, entries []Entry) error {
+	var b strings.Builder
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write history %s: %v", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write history %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAddAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "unregex", "history.jsonl")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	first, err := Add(path, `^\d+$`, "go", now)
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	second, err := Add(path, `(?<=a)b`, "pcre", now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("IDs = %d, %d, want 1, 2", first.ID, second.ID)
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Load() returned %d entries, want 2", len(entries))
+	}
+	if entries[1].Pattern != `(?<=a)b` || entries[1].Format != "pcre" || !entries[1].Time.Equal(now.Add(time.Minute)) {
+		t.Errorf("entries[1] = %+v", entries[1])
+	}
+}
+
+func TestLoad_Missing(t *testing.T) {
+	entries, err := Load(filepath.Join(t.TempDir(), "missing.jsonl"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Load() = %v, want no entries", entries)
+	}
+}
+
+func TestAdd_DropsOldest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	now := time.Now()
+	for i := 0; i < MaxEntries+5; i++ {
+		if _, err := Add(path, "a", "go", now); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(entries) != MaxEntries {
+		t.Fatalf("Load() returned %d entries, want %d", len(entries), MaxEntries)
+	}
+	if entries[0].ID != 6 || entries[len(entries)-1].ID != MaxEntries+5 {
+		t.Errorf("IDs run from %d to %d, want 6 to %d", entries[0].ID, entries[len(entries)-1].ID, MaxEntries+5)
+	}
+}
+
+func TestSearchAndFind(t *testing.T) {
+	entries := []Entry{
+		{ID: 1, Pattern: `^https?://`, Format: "go"},
+		{ID: 2, Pattern: `\d{3}-\d{4}`, Format: "go"},
+		{ID: 3, Pattern: `^HTTP/\d`, Format: "pcre"},
+	}
+
+	matches := Search(entries, "http")
+	if len(matches) != 2 || matches[0].ID != 1 || matches[1].ID != 3 {
+		t.Errorf("Search() = %+v, want entries 1 and 3", matches)
+	}
+
+	if entry, ok := Find(entries, 2); !ok || entry.Pattern != `\d{3}-\d{4}` {
+		t.Errorf("Find(2) = %+v, %v", entry, ok)
+	}
+	if _, ok := Find(entries, 9); ok {
+		t.Error("Find(9) should not find an entry")
+	}
+}
@@ -0,0 +1,178 @@
+// Package library is a curated set of common patterns, each with the
+// variant to use in every supported flavor
+package library
+
+import (
+	"sort"
+	"strings"
+)
+
+// Entry is a curated pattern
+type Entry struct {
+	Name        string
+	Title       string
+	Description string
+
+	// Variants maps each flavor to the pattern written for it
+	Variants map[string]string
+
+	// Matches and NonMatches are examples the pattern accepts and rejects
+	Matches    []string
+	NonMatches []string
+
+	// Caveats are the known limitations of the pattern
+	Caveats []string
+}
+
+// perlVariants uses the same pattern for every flavor with Perl-style
+// syntax and a separate one for POSIX ERE
+func perlVariants(perl, posix string) map[string]string {
+	return map[string]string{
+		"go":     perl,
+		"pcre":   perl,
+		"js":     perl,
+		"python": perl,
+		"posix":  posix,
+	}
+}
+
+// ipv6 accepts the full and every compressed form of an IPv6 address
+const ipv6 = `^(([0-9A-Fa-f]{1,4}:){7}[0-9A-Fa-f]{1,4}|([0-9A-Fa-f]{1,4}:){1,7}:|([0-9A-Fa-f]{1,4}:){1,6}:[0-9A-Fa-f]{1,4}|` +
+	`([0-9A-Fa-f]{1,4}:){1,5}(:[0-9A-Fa-f]{1,4}){1,2}|([0-9A-Fa-f]{1,4}:){1,4}(:[0-9A-Fa-f]{1,4}){1,3}|` +
+	`([0-9A-Fa-f]{1,4}:){1,3}(:[0-9A-Fa-f]{1,4}){1,4}|([0-9A-Fa-f]{1,4}:){1,2}(:[0-9A-Fa-f]{1,4}){1,5}|` +
+	`[0-9A-Fa-f]{1,4}:(:[0-9A-Fa-f]{1,4}){1,6}|:((:[0-9A-Fa-f]{1,4}){1,7}|:))$`
+
+// semverPerl is the pattern recommended by semver.org
+const semverPerl = `^(?P<major>0|[1-9]\d*)\.(?P<minor>0|[1-9]\d*)\.(?P<patch>0|[1-9]\d*)` +
+	`(?:-(?P<prerelease>(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+(?P<buildmetadata>[0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`
+
+var entries = []Entry{
+	{
+		Name:        "email",
+		Title:       "Email address",
+		Description: "A practical email address check: a local part of common characters, an @, and a domain with a top-level domain of two or more letters.",
+		Variants: perlVariants(
+			`^[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}$`,
+			`^[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}$`,
+		),
+		Matches:    []string{"user@example.com", "first.last+tag@mail.example.org"},
+		NonMatches: []string{"user@localhost", "user@@example.com", "@example.com"},
+		Caveats: []string{
+			"Rejects valid but rare addresses: quoted local parts, IP address domains and internationalized addresses.",
+			"Accepts some invalid ones, such as consecutive dots in the local part.",
+			"The only real test of an address is sending mail to it.",
+		},
+	},
+	{
+		Name:        "url",
+		Title:       "HTTP(S) URL",
+		Description: "An http or https URL with a host name, an optional port and an optional path, query and fragment.",
+		Variants: perlVariants(
+			`^https?://[A-Za-z0-9.-]+(?::\d{1,5})?(?:[/?#]\S*)?$`,
+			`^https?://[A-Za-z0-9.-]+(:[0-9]{1,5})?([/?#][^[:space:]]*)?$`,
+		),
+		Matches:    []string{"https://example.com", "http://localhost:8080/path?q=1#top"},
+		NonMatches: []string{"ftp://example.com", "https://", "https://exa mple.com"},
+		Caveats: []string{
+			"Only the http and https schemes are accepted.",
+			"User info (user@host) and IPv6 hosts ([::1]) are rejected.",
+			"Host names and port numbers aren't range-checked; parse the URL to validate it.",
+		},
+	},
+	{
+		Name:        "ipv4",
+		Title:       "IPv4 address",
+		Description: "A dotted-decimal IPv4 address with every octet between 0 and 255.",
+		Variants: perlVariants(
+			`^((25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)$`,
+			`^((25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])\.){3}(25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])$`,
+		),
+		Matches:    []string{"192.168.0.1", "255.255.255.255", "0.0.0.0"},
+		NonMatches: []string{"256.1.1.1", "192.168.01.1", "1.2.3"},
+		Caveats: []string{
+			"Octets with leading zeros are rejected, although some parsers accept them as octal.",
+			"CIDR suffixes such as /24 aren't accepted.",
+		},
+	},
+	{
+		Name:        "ipv6",
+		Title:       "IPv6 address",
+		Description: "An IPv6 address in full or compressed (::) form.",
+		Variants:    perlVariants(ipv6, ipv6),
+		Matches:     []string{"2001:db8::1", "::1", "fe80:0:0:0:204:61ff:fe9d:f156", "::"},
+		NonMatches:  []string{"2001:db8::1::2", "12345::1", "fe80::1%eth0"},
+		Caveats: []string{
+			"Addresses with an embedded IPv4 address (::ffff:192.0.2.1) are rejected.",
+			"Zone IDs (fe80::1%eth0) are rejected.",
+		},
+	},
+	{
+		Name:        "uuid",
+		Title:       "UUID",
+		Description: "An RFC 9562 UUID of versions 1 to 8 in its canonical hyphenated form, in either case.",
+		Variants: perlVariants(
+			`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[1-8][0-9a-fA-F]{3}-[89abAB][0-9a-fA-F]{3}-[0-9a-fA-F]{12}$`,
+			`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[1-8][0-9a-fA-F]{3}-[89abAB][0-9a-fA-F]{3}-[0-9a-fA-F]{12}$`,
+		),
+		Matches:    []string{"123e4567-e89b-42d3-a456-426614174000", "01890A5D-AC96-774B-BCCE-B302099A8057"},
+		NonMatches: []string{"00000000-0000-0000-0000-000000000000", "123e4567e89b42d3a456426614174000", "{123e4567-e89b-42d3-a456-426614174000}"},
+		Caveats: []string{
+			"The nil and max UUIDs and non-RFC variants are rejected.",
+			"Braced ({...}) and urn:uuid: forms aren't accepted.",
+		},
+	},
+	{
+		Name:        "iso-date",
+		Title:       "ISO 8601 date",
+		Description: "A calendar date in the extended ISO 8601 format YYYY-MM-DD.",
+		Variants: perlVariants(
+			`^\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])$`,
+			`^[0-9]{4}-(0[1-9]|1[0-2])-(0[1-9]|[12][0-9]|3[01])$`,
+		),
+		Matches:    []string{"2024-02-29", "1999-12-31"},
+		NonMatches: []string{"2024-13-01", "2024-1-5", "20240229"},
+		Caveats: []string{
+			"Impossible dates such as 2023-02-30 are accepted, since day ranges don't depend on the month; parse the date to validate it.",
+			"Times, week dates and ordinal dates aren't accepted.",
+		},
+	},
+	{
+		Name:        "semver",
+		Title:       "Semantic version",
+		Description: "A semantic version (MAJOR.MINOR.PATCH with optional pre-release and build metadata), using the pattern recommended by semver.org.",
+		Variants: map[string]string{
+			"go":     semverPerl,
+			"python": semverPerl,
+			"pcre":   strings.ReplaceAll(semverPerl, "(?P<", "(?<"),
+			"js":     strings.ReplaceAll(semverPerl, "(?P<", "(?<"),
+			"posix": `^(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)` +
+				`(-((0|[1-9][0-9]*|[0-9]*[a-zA-Z-][0-9a-zA-Z-]*)(\.(0|[1-9][0-9]*|[0-9]*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+				`(\+([0-9a-zA-Z-]+(\.[0-9a-zA-Z-]+)*))?$`,
+		},
+		Matches:    []string{"1.0.0", "2.10.3-rc.1+build.5", "0.1.0-alpha"},
+		NonMatches: []string{"v1.0.0", "1.0", "01.2.3"},
+		Caveats: []string{
+			"A leading v, as in Git tags, isn't accepted.",
+			"The POSIX variant uses numbered groups, since ERE has no named groups.",
+		},
+	},
+}
+
+// All returns the curated patterns sorted by name
+func All() []Entry {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// Lookup returns the curated pattern with the given name
+func Lookup(name string) (Entry, bool) {
+	for _, entry := range entries {
+		if entry.Name == name {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
@@ -0,0 +1,74 @@
+package library
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestEntries(t *testing.T) {
+	flavors := []string{"go", "pcre", "js", "python", "posix"}
+	for _, entry := range All() {
+		t.Run(entry.Name, func(t *testing.T) {
+			if entry.Title == "" || entry.Description == "" || len(entry.Caveats) == 0 {
+				t.Errorf("%s should have a title, description and caveats", entry.Name)
+			}
+			if len(entry.Matches) == 0 || len(entry.NonMatches) == 0 {
+				t.Errorf("%s should have examples", entry.Name)
+			}
+
+			for _, flavor := range flavors {
+				pattern, ok := entry.Variants[flavor]
+				if !ok {
+					t.Errorf("%s has no %s variant", entry.Name, flavor)
+					continue
+				}
+
+				// Go's regexp understands the syntax of every Perl-style
+				// variant once named groups are spelled (?P<, and
+				// CompilePOSIX restricts it to ERE
+				var re *regexp.Regexp
+				var err error
+				if flavor == "posix" {
+					re, err = regexp.CompilePOSIX(pattern)
+				} else {
+					re, err = regexp.Compile(strings.ReplaceAll(pattern, "(?<", "(?P<"))
+				}
+				if err != nil {
+					t.Errorf("%s variant doesn't compile: %v", flavor, err)
+					continue
+				}
+
+				for _, s := range entry.Matches {
+					if !re.MatchString(s) {
+						t.Errorf("%s variant should match %q", flavor, s)
+					}
+				}
+				for _, s := range entry.NonMatches {
+					if re.MatchString(s) {
+						t.Errorf("%s variant should not match %q", flavor, s)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	entry, ok := Lookup("uuid")
+	if !ok || entry.Title != "UUID" {
+		t.Errorf("Lookup(uuid) = %+v, %v", entry, ok)
+	}
+	if _, ok := Lookup("zip-code"); ok {
+		t.Error("Lookup(zip-code) should not find an entry")
+	}
+}
+
+func TestAll_Sorted(t *testing.T) {
+	all := All()
+	for i := 1; i < len(all); i++ {
+		if all[i-1].Name > all[i].Name {
+			t.Errorf("All() isn't sorted: %s before %s", all[i-1].Name, all[i].Name)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package lsp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Literal is a regex written as a string or regex literal in source code
+type Literal struct {
+	// Pattern is the regex after the language's escapes are decoded
+	Pattern string
+
+	// Format is the regex flavor the literal is compiled as
+	Format string
+
+	// Start and End are the byte offsets of the literal's contents (inside
+	// the quotes) in the document
+	Start, End int
+
+	// Raw is true when the contents are the pattern verbatim, as in Go raw
+	// strings, Python r-strings and JavaScript regex literals
+	Raw bool
+
+	// offsets holds the document offset of each byte of Pattern
+	offsets []int
+}
+
+// DocOffset returns the document offset of a byte offset in the pattern. The
+// end of the pattern maps to the end of the literal.
+func (l Literal) DocOffset(patternOffset int) int {
+	if patternOffset < 0 {
+		return l.Start
+	}
+	if patternOffset >= len(l.offsets) {
+		return l.End
+	}
+	return l.offsets[patternOffset]
+}
+
+// PatternOffset returns the byte offset in the pattern written at a document
+// offset, or -1 when the offset is outside the literal
+func (l Literal) PatternOffset(docOffset int) int {
+	if docOffset < l.Start || docOffset >= l.End {
+		return -1
+	}
+	for i := len(l.offsets) - 1; i >= 0; i-- {
+		if l.offsets[i] <= docOffset {
+			return i
+		}
+	}
+	return -1
+}
+
+// Calls that take a regex as their first argument, per language
+var (
+	goRegexCall     = regexp.MustCompile(`\bregexp\.(MustCompile|Compile|MustCompilePOSIX|CompilePOSIX|MatchString|Match)\(\s*`)
+	pythonRegexCall = regexp.MustCompile(`\bre\.(compile|match|search|fullmatch|sub|subn|split|findall|finditer)\(\s*`)
+	jsRegexCall     = regexp.MustCompile(`\bnew\s+RegExp\(\s*|\bRegExp\(\s*`)
+)
+
+// FindLiterals finds the regexes in a document written in the language with
+// the given LSP language identifier
+func FindLiterals(languageID, text string) []Literal {
+	var literals []Literal
+	switch languageID {
+	case "go":
+		for _, loc := range goRegexCall.FindAllStringSubmatchIndex(text, -1) {
+			formatName := "go"
+			if strings.HasSuffix(text[loc[2]:loc[3]], "POSIX") {
+				formatName = "posix"
+			}
+			if lit, ok := decodeGoString(text, loc[1]); ok {
+				lit.Format = formatName
+				literals = append(literals, lit)
+			}
+		}
+	case "python":
+		for _, loc := range pythonRegexCall.FindAllStringIndex(text, -1) {
+			if lit, ok := decodePythonString(text, loc[1]); ok {
+				literals = append(literals, lit)
+			}
+		}
+	case "javascript", "javascriptreact", "typescript", "typescriptreact":
+		for _, loc := range jsRegexCall.FindAllStringIndex(text, -1) {
+			if lit, ok := decodeQuoted(text, loc[1], false, false); ok {
+				lit.Format = "js"
+				literals = append(literals, lit)
+			}
+		}
+		literals = append(literals, findJSRegexLiterals(text)...)
+	}
+
+	// Empty patterns have nothing to explain, and also come from string
+	// forms that aren't decoded, such as Python's triple quotes
+	nonEmpty := literals[:0]
+	for _, lit := range literals {
+		if lit.Pattern != "" {
+			nonEmpty = append(nonEmpty, lit)
+		}
+	}
+	return nonEmpty
+}
+
+// decodeGoString decodes the Go string literal starting at start
+func decodeGoString(text string, start int) (Literal, bool) {
+	if start < len(text) && text[start] == '`' {
+		end := strings.IndexByte(text[start+1:], '`')
+		if end == -1 {
+			return Literal{}, false
+		}
+		return rawLiteral(text, start+1, start+1+end), true
+	}
+	return decodeQuoted(text, start, false, true)
+}
+
+// decodePythonString decodes the Python string literal, with an optional
+// prefix such as r or rb, starting at start
+func decodePythonString(text string, start int) (Literal, bool) {
+	raw := false
+	i := start
+	for i < len(text) && i < start+2 && strings.IndexByte("rRbBuU", text[i]) >= 0 {
+		raw = raw || text[i] == 'r' || text[i] == 'R'
+		i++
+	}
+	lit, ok := decodeQuoted(text, i, raw, true)
+	lit.Format = "python"
+	return lit, ok
+}
+
+// decodeQuoted decodes a string literal in single, double or back quotes
+// starting at start. In raw strings a backslash only stops the quote from
+// ending the string. Otherwise the usual escapes are decoded, and unknown
+// escapes such as \d are kept when keepUnknown is set (Go, Python) or lose
+// their backslash (JavaScript).
+func decodeQuoted(text string, start int, raw, keepUnknown bool) (Literal, bool) {
+	if start >= len(text) || strings.IndexByte("\"'`", text[start]) == -1 {
+		return Literal{}, false
+	}
+	quote := text[start]
+
+	lit := Literal{Start: start + 1, Raw: raw}
+	var b strings.Builder
+	for i := start + 1; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == quote:
+			lit.Pattern, lit.End = b.String(), i
+			return lit, true
+		case c == '\n' && quote != '`':
+			return Literal{}, false
+		case c == '\\' && i+1 < len(text):
+			next := text[i+1]
+			if decoded, ok := simpleEscapes[next]; ok && !raw {
+				b.WriteByte(decoded)
+				lit.offsets = append(lit.offsets, i)
+			} else if raw || keepUnknown {
+				b.WriteByte(c)
+				b.WriteByte(next)
+				lit.offsets = append(lit.offsets, i, i+1)
+			} else {
+				b.WriteByte(next)
+				lit.offsets = append(lit.offsets, i)
+			}
+			i++
+		default:
+			b.WriteByte(c)
+			lit.offsets = append(lit.offsets, i)
+		}
+	}
+	return Literal{}, false
+}
+
+// simpleEscapes are the single-character escapes shared by Go, Python and
+// JavaScript string literals
+var simpleEscapes = map[byte]byte{
+	'\\': '\\',
+	'"':  '"',
+	'\'': '\'',
+	'`':  '`',
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+}
+
+// encodeLiteral writes a pattern as the contents of a literal, escaped as
+// the literal's quotes need, reporting false when a raw literal can't hold
+// the pattern
+func (d *document) encodeLiteral(lit Literal, pattern string) (string, bool) {
+	quote := rune(d.text[lit.Start-1])
+	if lit.Raw {
+		return pattern, !strings.ContainsRune(pattern, quote) && !strings.ContainsRune(pattern, '\n')
+	}
+	var b strings.Builder
+	for _, r := range pattern {
+		switch {
+		case r == '\\' || r == quote:
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < ' ' || r == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String(), true
+}
+
+// rawLiteral returns the literal whose contents are text[start:end] verbatim
+func rawLiteral(text string, start, end int) Literal {
+	lit := Literal{Pattern: text[start:end], Start: start, End: end, Raw: true, offsets: make([]int, end-start)}
+	for i := range lit.offsets {
+		lit.offsets[i] = start + i
+	}
+	return lit
+}
+
+// findJSRegexLiterals finds /pattern/flags literals. A slash starts a regex
+// rather than a division when it follows an operator, an opening bracket or
+// a keyword such as return.
+func findJSRegexLiterals(text string) []Literal {
+	var literals []Literal
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '"', '\'', '`':
+			// Skip strings so slashes inside them aren't mistaken for regexes
+			if lit, ok := decodeQuoted(text, i, false, false); ok {
+				i = lit.End
+			}
+		case '/':
+			if i+1 < len(text) && (text[i+1] == '/' || text[i+1] == '*') {
+				// Skip comments
+				if text[i+1] == '/' {
+					if end := strings.IndexByte(text[i:], '\n'); end >= 0 {
+						i += end
+					} else {
+						i = len(text)
+					}
+				} else if end := strings.Index(text[i+2:], "*/"); end >= 0 {
+					i += end + 3
+				}
+				continue
+			}
+			if !regexCanStart(text[:i]) {
+				continue
+			}
+			if end := jsRegexEnd(text, i+1); end > i+1 {
+				lit := rawLiteral(text, i+1, end)
+				lit.Format = "js"
+				literals = append(literals, lit)
+				i = end
+			}
+		}
+	}
+	return literals
+}
+
+// regexCanStart reports whether a slash after before starts a regex literal
+func regexCanStart(before string) bool {
+	before = strings.TrimRight(before, " \t\r\n")
+	if before == "" {
+		return true
+	}
+	if strings.IndexByte("(,=:[!&|?{};+-*%<>~^", before[len(before)-1]) >= 0 {
+		return true
+	}
+	for _, keyword := range []string{"return", "typeof", "case", "in", "of", "yield", "await"} {
+		if strings.HasSuffix(before, keyword) {
+			rest := before[:len(before)-len(keyword)]
+			if rest == "" || !isIdentByte(rest[len(rest)-1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsRegexEnd returns the offset of the slash that closes a regex literal
+// whose pattern starts at start, or -1
+func jsRegexEnd(text string, start int) int {
+	inClass := false
+	for i := start; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				return i
+			}
+		case '\n':
+			return -1
+		}
+	}
+	return -1
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
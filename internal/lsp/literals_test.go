@@ -0,0 +1,108 @@
+package lsp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindLiterals(t *testing.T) {
+	tests := []struct {
+		name       string
+		languageID string
+		text       string
+		want       []string
+		formats    []string
+	}{
+		{
+			name:       "Go raw and interpreted strings",
+			languageID: "go",
+			text:       "var a = regexp.MustCompile(`^\\d+$`)\nvar b = regexp.MustCompile(\"\\\\w+\\\\.go\")\n",
+			want:       []string{`^\d+$`, `\w+\.go`},
+			formats:    []string{"go", "go"},
+		},
+		{
+			name:       "Go POSIX",
+			languageID: "go",
+			text:       "regexp.MustCompilePOSIX(`[[:alpha:]]+`)",
+			want:       []string{`[[:alpha:]]+`},
+			formats:    []string{"posix"},
+		},
+		{
+			name:       "Go ignores other calls and variables",
+			languageID: "go",
+			text:       "fmt.Println(`a+`)\nregexp.MustCompile(pattern)",
+			want:       nil,
+		},
+		{
+			name:       "Python raw and plain strings",
+			languageID: "python",
+			text:       "re.compile(r'(?P<year>\\d{4})')\nre.search(\"a\\\\sb\", s)\nre.match(r\"\\\"q\\\"\", s)\n",
+			want:       []string{`(?P<year>\d{4})`, `a\sb`, `\"q\"`},
+			formats:    []string{"python", "python", "python"},
+		},
+		{
+			name:       "JavaScript literals and RegExp",
+			languageID: "typescript",
+			text:       "const a = /^[a-z\\/]+$/i;\nconst b = new RegExp(\"\\\\d+\");\nconst c = x / 2 / y;\n// not /a regex/\n",
+			want:       []string{`\d+`, `^[a-z\/]+$`},
+			formats:    []string{"js", "js"},
+		},
+		{
+			name:       "JavaScript drops unknown escapes in strings",
+			languageID: "javascript",
+			text:       `RegExp("\d")`,
+			want:       []string{`d`},
+			formats:    []string{"js"},
+		},
+		{
+			name:       "unsupported language",
+			languageID: "rust",
+			text:       `Regex::new(r"\d+")`,
+			want:       nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			literals := FindLiterals(tt.languageID, tt.text)
+			if len(literals) != len(tt.want) {
+				t.Fatalf("FindLiterals() found %d literals %+v, want %d", len(literals), literals, len(tt.want))
+			}
+			for i, lit := range literals {
+				if lit.Pattern != tt.want[i] {
+					t.Errorf("literal %d = %q, want %q", i, lit.Pattern, tt.want[i])
+				}
+				if lit.Format != tt.formats[i] {
+					t.Errorf("literal %d format = %q, want %q", i, lit.Format, tt.formats[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLiteralOffsets(t *testing.T) {
+	text := `regexp.MustCompile("a\\d+")`
+	literals := FindLiterals("go", text)
+	if len(literals) != 1 {
+		t.Fatalf("FindLiterals() found %d literals, want 1", len(literals))
+	}
+	lit := literals[0]
+	if lit.Pattern != `a\d+` {
+		t.Fatalf("Pattern = %q", lit.Pattern)
+	}
+
+	// The pattern's \d is written as \\d, so the d sits two bytes later
+	dOffset := strings.Index(text, "d+")
+	if got := lit.PatternOffset(dOffset); got != 2 {
+		t.Errorf("PatternOffset(d) = %d, want 2", got)
+	}
+	if got := lit.DocOffset(2); got != dOffset {
+		t.Errorf("DocOffset(2) = %d, want %d", got, dOffset)
+	}
+	if got := lit.DocOffset(len(lit.Pattern)); got != lit.End {
+		t.Errorf("DocOffset(end) = %d, want %d", got, lit.End)
+	}
+	if got := lit.PatternOffset(0); got != -1 {
+		t.Errorf("PatternOffset outside the literal = %d, want -1", got)
+	}
+}
@@ -0,0 +1,41 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestReadWriteMessage(t *testing.T) {
+	var buf bytes.Buffer
+	sent := &message{ID: json.RawMessage("7"), Method: "textDocument/hover", Params: json.RawMessage(`{"x":"é"}`)}
+	if err := writeMessage(&buf, sent); err != nil {
+		t.Fatalf("writeMessage() error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "Content-Length: ") {
+		t.Errorf("message should start with a Content-Length header: %q", buf.String())
+	}
+
+	received, err := readMessage(bufio.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("readMessage() error = %v", err)
+	}
+	if received.JSONRPC != "2.0" || string(received.ID) != "7" || received.Method != sent.Method || string(received.Params) != `{"x":"é"}` {
+		t.Errorf("readMessage() = %+v, want %+v", received, sent)
+	}
+}
+
+func TestReadMessage_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing length": "Content-Type: application/json\r\n\r\n{}",
+		"invalid JSON":   "Content-Length: 3\r\n\r\n{x}",
+		"short body":     "Content-Length: 10\r\n\r\n{}",
+	}
+	for name, input := range tests {
+		if _, err := readMessage(bufio.NewReader(strings.NewReader(input))); err == nil {
+			t.Errorf("%s: readMessage() expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+// Package lsp implements a Language Server Protocol server that explains
+// the regexes in source files: hovers explain the token under the cursor,
+// diagnostics report lint findings and code actions simplify patterns and
+// convert the ones written in another flavor's syntax.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/weslien/unregex/internal/app"
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// Server is a language server speaking JSON-RPC over a pair of streams,
+// usually stdin and stdout
+type Server struct {
+	in      *bufio.Reader
+	out     io.Writer
+	version string
+	docs    map[string]*document
+}
+
+// NewServer returns a server reading requests from in and writing responses
+// and notifications to out
+func NewServer(in io.Reader, out io.Writer, version string) *Server {
+	return &Server{
+		in:      bufio.NewReader(in),
+		out:     out,
+		version: version,
+		docs:    map[string]*document{},
+	}
+}
+
+// Run serves requests until the client sends exit or closes the input
+func (s *Server) Run() error {
+	for {
+		msg, err := readMessage(s.in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var rpcErr *responseError
+		if errors.As(err, &rpcErr) {
+			if err := writeMessage(s.out, &message{ID: json.RawMessage("null"), Error: rpcErr}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or notification. Requests always get a
+// response, even if only an error.
+func (s *Server) handle(msg *message) error {
+	result, err := s.dispatch(msg)
+	if msg.ID == nil {
+		// Notifications have no response
+		return nil
+	}
+
+	response := &message{ID: msg.ID}
+	if err != nil {
+		var rpcErr *responseError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &responseError{codeInvalidParams, err.Error()}
+		}
+		response.Error = rpcErr
+	} else {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		response.Result = data
+	}
+	return writeMessage(s.out, response)
+}
+
+func (s *Server) dispatch(msg *message) (interface{}, error) {
+	switch msg.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   1, // full document on every change
+				"hoverProvider":      true,
+				"codeActionProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "unregex", "version": s.version},
+		}, nil
+
+	case "shutdown":
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		s.docs[params.TextDocument.URI] = newDocument(params.TextDocument.LanguageID, params.TextDocument.Text)
+		return nil, s.publishDiagnostics(params.TextDocument.URI)
+
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		doc, ok := s.docs[params.TextDocument.URI]
+		if !ok || len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		last := params.ContentChanges[len(params.ContentChanges)-1]
+		s.docs[params.TextDocument.URI] = newDocument(doc.languageID, last.Text)
+		return nil, s.publishDiagnostics(params.TextDocument.URI)
+
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []diagnostic{}})
+
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params), nil
+
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.codeActions(params), nil
+	}
+
+	if msg.ID != nil {
+		return nil, &responseError{codeMethodNotFound, fmt.Sprintf("method not supported: %s", msg.Method)}
+	}
+	return nil, nil
+}
+
+// notify sends a notification to the client
+func (s *Server) notify(method string, params interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return writeMessage(s.out, &message{Method: method, Params: data})
+}
+
+// publishDiagnostics lints every regex in a document
+func (s *Server) publishDiagnostics(uri string) error {
+	doc := s.docs[uri]
+	diagnostics := []diagnostic{}
+	for _, lit := range FindLiterals(doc.languageID, doc.text) {
+		for _, finding := range app.Lint(lit.Pattern, lit.Format) {
+			severity := severityError
+			if finding.Severity == app.SeverityWarning {
+				severity = severityWarning
+			}
+			start := lit.DocOffset(finding.Offset)
+			diagnostics = append(diagnostics, diagnostic{
+				Range:    doc.rangeOf(start, lit.DocOffset(finding.Offset+1)),
+				Severity: severity,
+				Source:   "unregex",
+				Message:  finding.Message,
+			})
+		}
+	}
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+// hover explains the regex token under the cursor, or returns nil when the
+// cursor isn't in a regex
+func (s *Server) hover(params textDocumentPositionParams) *hover {
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	offset := doc.offset(params.Position)
+
+	for _, lit := range FindLiterals(doc.languageID, doc.text) {
+		patternOffset := lit.PatternOffset(offset)
+		if patternOffset == -1 {
+			continue
+		}
+
+		regexFormat := format.GetFormat(lit.Format)
+		for _, span := range format.TokenSpans(regexFormat, lit.Pattern, "") {
+			if patternOffset < span.Start || patternOffset >= span.End {
+				continue
+			}
+			token := lit.Pattern[span.Start:span.End]
+
+			value := fmt.Sprintf("`%s` — %s\n\n%s", token, regexFormat.ExplainToken(token), regexFormat.Name())
+			if url := app.TokenDocURL(lit.Format, token); url != "" {
+				value += fmt.Sprintf(" · [documentation](%s)", url)
+			}
+			return &hover{
+				Contents: markupContent{Kind: "markdown", Value: value},
+				Range:    doc.rangeOf(lit.DocOffset(span.Start), lit.DocOffset(span.End)),
+			}
+		}
+	}
+	return nil
+}
+
+// codeActions offers to simplify the Go regexes in the requested range, when
+// the parser's canonical form of the pattern is shorter, and to convert the
+// regexes written in another flavor's syntax to their own
+func (s *Server) codeActions(params codeActionParams) []codeAction {
+	actions := []codeAction{}
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return actions
+	}
+	start, end := doc.offset(params.Range.Start), doc.offset(params.Range.End)
+
+	rewrite := func(title string, lit Literal, pattern string) {
+		newText, ok := doc.encodeLiteral(lit, pattern)
+		if !ok {
+			return
+		}
+		actions = append(actions, codeAction{
+			Title: title,
+			Kind:  "refactor.rewrite",
+			Edit: workspaceEdit{Changes: map[string][]textEdit{
+				params.TextDocument.URI: {{Range: doc.rangeOf(lit.Start, lit.End), NewText: newText}},
+			}},
+		})
+	}
+	for _, lit := range FindLiterals(doc.languageID, doc.text) {
+		if lit.End < start || lit.Start > end {
+			continue
+		}
+		if lit.Format == "go" {
+			if simplified, ok := simplify(lit.Pattern); ok {
+				rewrite(fmt.Sprintf("Simplify regex to %s", simplified), lit, simplified)
+			}
+		}
+		if from, converted, ok := convert(lit.Pattern, lit.Format); ok {
+			rewrite(fmt.Sprintf("Convert regex from %s syntax to %s", format.GetFormat(from).Name(), converted), lit, converted)
+		}
+	}
+	return actions
+}
+
+// convertSources are the flavors a regex may have been written in for
+// another, in the order they're tried
+var convertSources = []string{"pcre", "python", "js", "go"}
+
+// convert rewrites a pattern written in another flavor's syntax in its own
+// flavor's, returning the flavor it was written in. A pattern is taken to be
+// written for the first flavor that reads less of it as literal text, such
+// as a JavaScript (?<name>...) group, which Python reads as the text (?<name>,
+// and is only converted when the conversion is proven to match the same
+// strings and needs no flags set outside the pattern.
+func convert(pattern, formatName string) (string, string, bool) {
+	own := literalLength(pattern, formatName)
+	for _, from := range convertSources {
+		if from == formatName || literalLength(pattern, from) >= own {
+			continue
+		}
+		conversion, err := app.ConvertPattern(pattern, from, formatName, "")
+		if err != nil || !conversion.Proven || conversion.Flags != "" || conversion.Converted == pattern {
+			continue
+		}
+		return from, conversion.Converted, true
+	}
+	return "", "", false
+}
+
+// literalLength returns how many bytes of a pattern a flavor reads as
+// literal text
+func literalLength(pattern, formatName string) int {
+	regexFormat := format.GetFormat(formatName)
+	length := 0
+	for _, token := range format.TokenizeWithFlags(regexFormat, pattern, "") {
+		explanation := regexFormat.ExplainToken(token)
+		if strings.Contains(explanation, "' literally") &&
+			(strings.HasPrefix(explanation, "Matches the character '") || strings.HasPrefix(explanation, "Matches the string '")) {
+			length += len(token)
+		}
+	}
+	return length
+}
+
+// simplify returns the canonical form of a Go pattern from regexp/syntax,
+// such as [a-c] for (?:a|b|c), when it's shorter than the pattern. Forms
+// that spell out flags or text anchors the pattern didn't are skipped, as
+// they read worse even when shorter.
+func simplify(pattern string) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	simplified := re.String()
+	if len(simplified) >= len(pattern) {
+		return "", false
+	}
+	for _, noise := range []string{`(?-m`, `(?m`, `(?s`, `(?i`, `(?U`, `\A`, `\z`} {
+		if strings.Contains(simplified, noise) && !strings.Contains(pattern, noise) {
+			return "", false
+		}
+	}
+	return simplified, true
+}
@@ -0,0 +1,256 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// session runs a server over the given messages and returns what it wrote
+func session(t *testing.T, messages ...string) []*message {
+	t.Helper()
+	var in bytes.Buffer
+	for _, msg := range messages {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+
+	var out bytes.Buffer
+	if err := NewServer(&in, &out, "test").Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var written []*message
+	r := bufio.NewReader(&out)
+	for {
+		msg, err := readMessage(r)
+		if err != nil {
+			break
+		}
+		written = append(written, msg)
+	}
+	return written
+}
+
+// request builds a JSON-RPC message
+func request(t *testing.T, id int, method string, params interface{}) string {
+	t.Helper()
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if id > 0 {
+		msg["id"] = id
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// response finds the response to the request with the given ID
+func response(t *testing.T, messages []*message, id int) *message {
+	t.Helper()
+	for _, msg := range messages {
+		if string(msg.ID) == strconv.Itoa(id) {
+			return msg
+		}
+	}
+	t.Fatalf("no response to request %d", id)
+	return nil
+}
+
+const goSource = "package main\n\nvar re = regexp.MustCompile(`^(?:a|b|c)x(\\d+`)\nvar ok = regexp.MustCompile(\"(?:a|b|c)\\\\.\")\n"
+
+func openGoSource(t *testing.T) string {
+	return request(t, 0, "textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]string{"uri": "file:///main.go", "languageId": "go", "text": goSource},
+	})
+}
+
+func TestServer_Initialize(t *testing.T) {
+	messages := session(t, request(t, 1, "initialize", map[string]interface{}{}), request(t, 2, "shutdown", nil), request(t, 0, "exit", nil))
+
+	var result struct {
+		Capabilities struct {
+			HoverProvider      bool `json:"hoverProvider"`
+			CodeActionProvider bool `json:"codeActionProvider"`
+		} `json:"capabilities"`
+	}
+	if err := json.Unmarshal(response(t, messages, 1).Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.Capabilities.HoverProvider || !result.Capabilities.CodeActionProvider {
+		t.Errorf("initialize capabilities = %+v", result.Capabilities)
+	}
+	if shutdown := response(t, messages, 2); shutdown.Error != nil || string(shutdown.Result) != "null" {
+		t.Errorf("shutdown response = %+v", shutdown)
+	}
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	messages := session(t, openGoSource(t))
+	if len(messages) != 1 || messages[0].Method != "textDocument/publishDiagnostics" {
+		t.Fatalf("expected one diagnostics notification, got %+v", messages)
+	}
+
+	var params publishDiagnosticsParams
+	if err := json.Unmarshal(messages[0].Params, &params); err != nil {
+		t.Fatal(err)
+	}
+	if len(params.Diagnostics) != 1 {
+		t.Fatalf("diagnostics = %+v, want one for the unclosed group", params.Diagnostics)
+	}
+	d := params.Diagnostics[0]
+	if d.Severity != severityError || d.Message != "unclosed group" {
+		t.Errorf("diagnostic = %+v", d)
+	}
+	// The unclosed group is the ( before \d on line 2
+	if d.Range.Start.Line != 2 || d.Range.Start.Character != strings.Index("var re = regexp.MustCompile(`^(?:a|b|c)x(\\d+`)", `(\d`) {
+		t.Errorf("diagnostic range = %+v", d.Range)
+	}
+}
+
+func TestServer_Hover(t *testing.T) {
+	line := "var ok = regexp.MustCompile(\"(?:a|b|c)\\\\.\")"
+	messages := session(t, openGoSource(t),
+		request(t, 1, "textDocument/hover", map[string]interface{}{
+			"textDocument": map[string]string{"uri": "file:///main.go"},
+			"position":     map[string]int{"line": 3, "character": strings.Index(line, `\\.`) + 1},
+		}),
+		request(t, 2, "textDocument/hover", map[string]interface{}{
+			"textDocument": map[string]string{"uri": "file:///main.go"},
+			"position":     map[string]int{"line": 0, "character": 2},
+		}),
+	)
+
+	var h hover
+	if err := json.Unmarshal(response(t, messages, 1).Result, &h); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(h.Contents.Value, "`\\.` — Matches") {
+		t.Errorf("hover = %q, want an explanation of \\.", h.Contents.Value)
+	}
+	if h.Range.Start.Character != strings.Index(line, `\\.`) || h.Range.End.Character != strings.Index(line, `\\.`)+3 {
+		t.Errorf("hover range = %+v, want the escaped \\\\. in the source", h.Range)
+	}
+
+	if result := string(response(t, messages, 2).Result); result != "null" {
+		t.Errorf("hover outside a regex = %s, want null", result)
+	}
+}
+
+func TestServer_CodeAction(t *testing.T) {
+	messages := session(t, openGoSource(t),
+		request(t, 1, "textDocument/codeAction", map[string]interface{}{
+			"textDocument": map[string]string{"uri": "file:///main.go"},
+			"range":        map[string]interface{}{"start": map[string]int{"line": 3, "character": 32}, "end": map[string]int{"line": 3, "character": 32}},
+		}),
+	)
+
+	var actions []codeAction
+	if err := json.Unmarshal(response(t, messages, 1).Result, &actions); err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("code actions = %+v, want one", actions)
+	}
+	edits := actions[0].Edit.Changes["file:///main.go"]
+	if len(edits) != 1 || edits[0].NewText != `[a-c]\\.` {
+		t.Errorf("edits = %+v, want the pattern replaced by [a-c]\\\\.", edits)
+	}
+}
+
+func TestServer_CodeAction_Convert(t *testing.T) {
+	source := "import re\n\nyear = re.compile(r\"(?<year>\\d{4})-\\d\\d\")\nword = re.compile('(?<w>\\\\w+)')\n"
+	messages := session(t,
+		request(t, 0, "textDocument/didOpen", map[string]interface{}{
+			"textDocument": map[string]string{"uri": "file:///main.py", "languageId": "python", "text": source},
+		}),
+		request(t, 1, "textDocument/codeAction", map[string]interface{}{
+			"textDocument": map[string]string{"uri": "file:///main.py"},
+			"range":        map[string]interface{}{"start": map[string]int{"line": 2, "character": 0}, "end": map[string]int{"line": 3, "character": 30}},
+		}),
+	)
+
+	var actions []codeAction
+	if err := json.Unmarshal(response(t, messages, 1).Result, &actions); err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 2 {
+		t.Fatalf("code actions = %+v, want two conversions", actions)
+	}
+	want := []string{`(?P<year>\d{4})-\d\d`, `(?P<w>\\w+)`}
+	for i, action := range actions {
+		if !strings.HasPrefix(action.Title, "Convert regex from Perl Compatible Regular Expressions (PCRE) syntax") {
+			t.Errorf("code action %d title = %q, want a conversion from PCRE", i+1, action.Title)
+		}
+		edits := action.Edit.Changes["file:///main.py"]
+		if len(edits) != 1 || edits[0].NewText != want[i] {
+			t.Errorf("code action %d edits = %+v, want the pattern replaced by %s", i+1, edits, want[i])
+		}
+	}
+}
+
+func TestServer_UnknownMethod(t *testing.T) {
+	messages := session(t, request(t, 1, "workspace/symbol", map[string]string{}), request(t, 0, "$/cancelRequest", map[string]int{"id": 1}))
+	if len(messages) != 1 {
+		t.Fatalf("expected only the error response, got %+v", messages)
+	}
+	if messages[0].Error == nil || messages[0].Error.Code != codeMethodNotFound {
+		t.Errorf("response = %+v, want a method not found error", messages[0])
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+		ok      bool
+	}{
+		{`(?:a|b|c)`, `[a-c]`, true},
+		{`(a|b|c|d)+`, `([a-d])+`, true},
+		{`[0-9]+`, "", false},
+		{`^(?:a|b)$`, "", false},
+		{`(unclosed`, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := simplify(tt.pattern)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("simplify(%q) = %q, %v, want %q, %v", tt.pattern, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		pattern, formatName string
+		from, want          string
+		ok                  bool
+	}{
+		{`(?P<y>\d+)-x`, "js", "pcre", `(?<y>\d+)-x`, true},
+		{`(?<y>a)b`, "python", "pcre", `(?P<y>a)b`, true},
+		{`^\d+(?:a|b)$`, "js", "", "", false},
+		{`(?P<y>a)`, "go", "", "", false},
+		{`(?<y>a)(?<=b)`, "python", "", "", false},
+	}
+	for _, tt := range tests {
+		from, got, ok := convert(tt.pattern, tt.formatName)
+		if from != tt.from || got != tt.want || ok != tt.ok {
+			t.Errorf("convert(%q, %s) = %s, %q, %v, want %s, %q, %v", tt.pattern, tt.formatName, from, got, ok, tt.from, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDocumentPositions(t *testing.T) {
+	// 😀 is one rune, four bytes and two UTF-16 code units
+	doc := newDocument("go", "ab\n😀x\n")
+	xOffset := strings.Index(doc.text, "x")
+	if got := doc.position(xOffset); got != (Position{Line: 1, Character: 2}) {
+		t.Errorf("position(x) = %+v, want line 1, character 2", got)
+	}
+	if got := doc.offset(Position{Line: 1, Character: 2}); got != xOffset {
+		t.Errorf("offset(1:2) = %d, want %d", got, xOffset)
+	}
+}
@@ -0,0 +1,174 @@
+// Package plugin runs regex flavors implemented by external executables.
+//
+// A plugin is started once per request. It reads a single JSON request from
+// stdin and writes a single JSON response to stdout:
+//
+//	{"op":"describe"}                   -> {"name":"COBOL","features":["lookahead"]}
+//	{"op":"tokenize","pattern":"a+b"}   -> {"tokens":[{"token":"a+","explanation":"..."}]}
+//	{"op":"explain","token":"a+"}       -> {"explanation":"..."}
+//
+// Any response may set "error" instead to report a failure.
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Request is sent to a plugin on stdin
+type Request struct {
+	Op      string `json:"op"`
+	Pattern string `json:"pattern,omitempty"`
+	Token   string `json:"token,omitempty"`
+}
+
+// Token is a token of a pattern and its explanation
+type Token struct {
+	Token       string `json:"token"`
+	Explanation string `json:"explanation"`
+}
+
+// Response is read from a plugin's stdout
+type Response struct {
+	Name        string   `json:"name,omitempty"`
+	Features    []string `json:"features,omitempty"`
+	Tokens      []Token  `json:"tokens,omitempty"`
+	Explanation string   `json:"explanation,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// Plugin is a regex format implemented by an external executable. It
+// satisfies format.RegexFormat. Since those methods can't fail, a plugin
+// that can't be run explains the pattern as invalid instead, which the
+// linter reports as an error. A plugin is safe for concurrent use, as the
+// servers explain patterns concurrently.
+type Plugin struct {
+	name    string
+	command string
+	args    []string
+
+	// run sends a request to the plugin, and is replaced in tests
+	run func(req Request) (*Response, error)
+
+	describeOnce sync.Once
+	description  *Response
+
+	// mu guards explanations, which requests add to as they finish
+	mu           sync.Mutex
+	explanations map[string]string
+}
+
+// New returns the plugin registered as name, which runs command with args
+func New(name, command string, args []string) *Plugin {
+	p := &Plugin{name: name, command: command, args: args, explanations: map[string]string{}}
+	p.run = p.exec
+	return p
+}
+
+// exec runs the plugin's executable for a single request
+func (p *Plugin) exec(req Request) (*Response, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(p.command, p.args...)
+	cmd.Stdin = bytes.NewReader(append(input, '\n'))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("plugin %s: %v: %s", p.name, err, msg)
+		}
+		return nil, fmt.Errorf("plugin %s: %v", p.name, err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("plugin %s: invalid response: %v", p.name, err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", p.name, resp.Error)
+	}
+	return &resp, nil
+}
+
+// describe asks the plugin for its name and features once
+func (p *Plugin) describe() *Response {
+	p.describeOnce.Do(func() {
+		p.description, _ = p.run(Request{Op: "describe"})
+	})
+	return p.description
+}
+
+// remember records the explanation of a token, or of a pattern that
+// couldn't be tokenized
+func (p *Plugin) remember(token, explanation string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.explanations[token] = explanation
+}
+
+// Name returns the plugin's descriptive name, or the name it was registered
+// as when the plugin doesn't give one
+func (p *Plugin) Name() string {
+	if desc := p.describe(); desc != nil && desc.Name != "" {
+		return desc.Name
+	}
+	return p.name
+}
+
+// TokenizeRegex asks the plugin to tokenize a pattern, remembering the
+// explanations it returns alongside the tokens
+func (p *Plugin) TokenizeRegex(pattern string) []string {
+	resp, err := p.run(Request{Op: "tokenize", Pattern: pattern})
+	if err != nil {
+		p.remember(pattern, "Invalid: "+err.Error())
+		return []string{pattern}
+	}
+
+	tokens := make([]string, 0, len(resp.Tokens))
+	for _, token := range resp.Tokens {
+		tokens = append(tokens, token.Token)
+		if token.Explanation != "" {
+			p.remember(token.Token, token.Explanation)
+		}
+	}
+	return tokens
+}
+
+// ExplainToken explains a token, asking the plugin unless it was explained
+// when its pattern was tokenized
+func (p *Plugin) ExplainToken(token string) string {
+	p.mu.Lock()
+	explanation, ok := p.explanations[token]
+	p.mu.Unlock()
+	if ok {
+		return explanation
+	}
+	resp, err := p.run(Request{Op: "explain", Token: token})
+	if err != nil {
+		return "Invalid: " + err.Error()
+	}
+	p.remember(token, resp.Explanation)
+	return resp.Explanation
+}
+
+// HasFeature reports whether the plugin lists a feature, using the
+// format package's feature names
+func (p *Plugin) HasFeature(feature string) bool {
+	desc := p.describe()
+	if desc == nil {
+		return false
+	}
+	for _, f := range desc.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
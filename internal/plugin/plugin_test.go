@@ -0,0 +1,148 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestHelperProcess is run as a plugin executable by TestExec
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("UNREGEX_TEST_PLUGIN") != "1" {
+		return
+	}
+	var req Request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Print(`{"error":"bad request"}`)
+		os.Exit(0)
+	}
+	switch req.Op {
+	case "describe":
+		fmt.Print(`{"name":"Helper","features":["lookahead"]}`)
+	case "tokenize":
+		fmt.Printf(`{"tokens":[{"token":%q,"explanation":"Everything"}]}`, req.Pattern)
+	default:
+		fmt.Fprint(os.Stderr, "unknown op")
+		os.Exit(3)
+	}
+	os.Exit(0)
+}
+
+func helperPlugin(t *testing.T) *Plugin {
+	t.Setenv("UNREGEX_TEST_PLUGIN", "1")
+	return New("helper", os.Args[0], []string{"-test.run=TestHelperProcess"})
+}
+
+func TestExec(t *testing.T) {
+	p := helperPlugin(t)
+
+	if got := p.Name(); got != "Helper" {
+		t.Errorf("Name() = %q, want %q", got, "Helper")
+	}
+	if !p.HasFeature("lookahead") || p.HasFeature("lookbehind") {
+		t.Error("HasFeature() should only report the features the plugin lists")
+	}
+	if got := p.TokenizeRegex("a+b"); len(got) != 1 || got[0] != "a+b" {
+		t.Errorf("TokenizeRegex() = %q, want [a+b]", got)
+	}
+	if got := p.ExplainToken("a+b"); got != "Everything" {
+		t.Errorf("ExplainToken() = %q, want the explanation from tokenize", got)
+	}
+
+	// The helper exits with an error for explain requests
+	got := p.ExplainToken("c")
+	if !strings.HasPrefix(got, "Invalid: plugin helper: ") || !strings.Contains(got, "unknown op") {
+		t.Errorf("ExplainToken() = %q, want an invalid explanation quoting stderr", got)
+	}
+}
+
+func TestExec_MissingCommand(t *testing.T) {
+	p := New("missing", "unregex-plugin-that-does-not-exist", nil)
+
+	if got := p.Name(); got != "missing" {
+		t.Errorf("Name() = %q, want the registered name", got)
+	}
+	if p.HasFeature("lookahead") {
+		t.Error("HasFeature() should be false when the plugin can't run")
+	}
+	tokens := p.TokenizeRegex("a+")
+	if len(tokens) != 1 || tokens[0] != "a+" {
+		t.Fatalf("TokenizeRegex() = %q, want the whole pattern", tokens)
+	}
+	if got := p.ExplainToken("a+"); !strings.HasPrefix(got, "Invalid: plugin missing: ") {
+		t.Errorf("ExplainToken() = %q, want an invalid explanation", got)
+	}
+}
+
+func TestExplainTokenCaching(t *testing.T) {
+	var requests []Request
+	p := New("fake", "", nil)
+	p.run = func(req Request) (*Response, error) {
+		requests = append(requests, req)
+		switch req.Op {
+		case "tokenize":
+			return &Response{Tokens: []Token{{"a", "Letter a"}, {"*", ""}}}, nil
+		case "explain":
+			return &Response{Explanation: "Any number of times"}, nil
+		}
+		return nil, errors.New("unsupported")
+	}
+
+	if got := p.TokenizeRegex("a*"); len(got) != 2 || got[0] != "a" || got[1] != "*" {
+		t.Fatalf("TokenizeRegex() = %q", got)
+	}
+	if got := p.ExplainToken("a"); got != "Letter a" {
+		t.Errorf("ExplainToken(a) = %q", got)
+	}
+	if got := p.ExplainToken("*"); got != "Any number of times" {
+		t.Errorf("ExplainToken(*) = %q", got)
+	}
+	p.ExplainToken("*")
+	p.Name()
+	p.HasFeature("lookahead")
+
+	var ops []string
+	for _, req := range requests {
+		ops = append(ops, req.Op)
+	}
+	if want := "tokenize explain describe"; strings.Join(ops, " ") != want {
+		t.Errorf("requests = %q, want %q", strings.Join(ops, " "), want)
+	}
+}
+
+func TestPluginConcurrentUse(t *testing.T) {
+	p := New("fake", "", nil)
+	p.run = func(req Request) (*Response, error) {
+		switch req.Op {
+		case "describe":
+			return &Response{Name: "Fake", Features: []string{"lookahead"}}, nil
+		case "tokenize":
+			return &Response{Tokens: []Token{{req.Pattern, "Everything"}}}, nil
+		}
+		return &Response{Explanation: "Token " + req.Token}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pattern := fmt.Sprint("p", i)
+			p.TokenizeRegex(pattern)
+			if got := p.ExplainToken(pattern); got != "Everything" {
+				t.Errorf("ExplainToken(%s) = %q, want %q", pattern, got, "Everything")
+			}
+			if got := p.ExplainToken("x"); got != "Token x" {
+				t.Errorf("ExplainToken(x) = %q, want %q", got, "Token x")
+			}
+			if p.Name() != "Fake" || !p.HasFeature("lookahead") {
+				t.Errorf("Name() = %q, HasFeature(lookahead) = %v", p.Name(), p.HasFeature("lookahead"))
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,82 @@
+// Package saved stores named patterns, so a vetted pattern set can be
+// explained by name and shared as a single file
+package saved
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// Pattern is a saved pattern and the flavor it's written in
+type Pattern struct {
+	Pattern     string `json:"pattern"`
+	Format      string `json:"format"`
+	Description string `json:"description,omitempty"`
+}
+
+// validName matches the names patterns can be saved under
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidName reports whether name can be used to save a pattern
+func ValidName(name string) bool {
+	return validName.MatchString(name)
+}
+
+// Path returns the location of the saved patterns, patterns.json in the
+// unregex directory of the user's config directory
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "unregex", "patterns.json"), nil
+}
+
+// Load reads the saved patterns at path by name. A missing file yields no
+// patterns.
+func Load(path string) (map[string]Pattern, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]Pattern{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved patterns %s: %v", path, err)
+	}
+
+	patterns := map[string]Pattern{}
+	if err := json.Unmarshal(data, &patterns); err != nil {
+		return nil, fmt.Errorf("invalid saved patterns %s: %v", path, err)
+	}
+	return patterns, nil
+}
+
+// Write stores the patterns at path, indented and sorted by name so the
+// file diffs cleanly when shared
+func Write(path string, patterns map[string]Pattern) error {
+	data, err := json.MarshalIndent(patterns, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for saved patterns: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write saved patterns %s: %v", path, err)
+	}
+	return nil
+}
+
+// Names returns the names of the patterns in sorted order
+func Names(patterns map[string]Pattern) []string {
+	names := make([]string, 0, len(patterns))
+	for name := range patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
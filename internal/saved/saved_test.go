@@ -0,0 +1,79 @@
+package saved
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "unregex", "patterns.json")
+	patterns := map[string]Pattern{
+		"email-strict": {Pattern: `^[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}$`, Format: "go", Description: "Lowercase email address"},
+		"semver":       {Pattern: `^v?\d+\.\d+\.\d+$`, Format: "pcre"},
+	}
+	if err := Write(path, patterns); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Index(string(data), "email-strict") > strings.Index(string(data), "semver") {
+		t.Errorf("Write() should sort patterns by name:\n%s", data)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded) != 2 || loaded["semver"] != patterns["semver"] || loaded["email-strict"] != patterns["email-strict"] {
+		t.Errorf("Load() = %+v, want %+v", loaded, patterns)
+	}
+}
+
+func TestLoad_Missing(t *testing.T) {
+	patterns, err := Load(filepath.Join(t.TempDir(), "patterns.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if patterns == nil || len(patterns) != 0 {
+		t.Errorf("Load() = %v, want an empty map", patterns)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patterns.json")
+	if err := os.WriteFile(path, []byte("[1, 2]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() expected an error for an invalid file")
+	}
+}
+
+func TestValidName(t *testing.T) {
+	tests := map[string]bool{
+		"email-strict": true,
+		"ipv4":         true,
+		"log.line_v2":  true,
+		"":             false,
+		"-flag":        false,
+		"two words":    false,
+		"@email":       false,
+	}
+	for name, want := range tests {
+		if got := ValidName(name); got != want {
+			t.Errorf("ValidName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	names := Names(map[string]Pattern{"b": {}, "c": {}, "a": {}})
+	if strings.Join(names, ",") != "a,b,c" {
+		t.Errorf("Names() = %v, want [a b c]", names)
+	}
+}
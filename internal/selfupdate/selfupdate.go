@@ -0,0 +1,278 @@
+// Package selfupdate replaces the running binary with the latest GitHub release
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the GitHub API endpoint for the latest unregex release
+const DefaultAPIURL = "https://api.github.com/repos/weslien/unregex/releases/latest"
+
+// Release is a GitHub release and its downloadable assets
+type Release struct {
+	TagName string  `json:"tag_name"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Version returns the release's version without the leading "v"
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// Updater downloads releases and installs them over a binary
+type Updater struct {
+	APIURL string
+	Client *http.Client
+	GOOS   string
+	GOARCH string
+}
+
+// New returns an Updater for the official releases and the current platform
+func New() *Updater {
+	return &Updater{
+		APIURL: DefaultAPIURL,
+		Client: &http.Client{Timeout: 60 * time.Second},
+		GOOS:   runtime.GOOS,
+		GOARCH: runtime.GOARCH,
+	}
+}
+
+// ArchiveName returns the name of the release archive for a platform, as
+// produced by goreleaser
+func ArchiveName(version, goos, goarch string) string {
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+	return fmt.Sprintf("unregex_%s_%s_%s.%s", version, goos, goarch, ext)
+}
+
+// ChecksumsName returns the name of the release's SHA-256 checksums file
+func ChecksumsName(version string) string {
+	return fmt.Sprintf("unregex_%s_checksums.txt", version)
+}
+
+// Latest fetches the latest release
+func (u *Updater) Latest() (*Release, error) {
+	body, err := u.get(u.APIURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the latest release: %v", err)
+	}
+
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("invalid release information: %v", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("invalid release information: no tag name")
+	}
+	return &release, nil
+}
+
+// Install downloads the release's archive for the updater's platform,
+// verifies it against the release checksums and replaces the binary at
+// exePath with the one inside
+func (u *Updater) Install(release *Release, exePath string) error {
+	archiveName := ArchiveName(release.Version(), u.GOOS, u.GOARCH)
+	archiveURL := release.assetURL(archiveName)
+	if archiveURL == "" {
+		return fmt.Errorf("release %s has no build for %s/%s", release.TagName, u.GOOS, u.GOARCH)
+	}
+	checksumsURL := release.assetURL(ChecksumsName(release.Version()))
+	if checksumsURL == "" {
+		return fmt.Errorf("release %s has no checksums file", release.TagName)
+	}
+
+	checksums, err := u.get(checksumsURL)
+	if err != nil {
+		return fmt.Errorf("failed to download checksums: %v", err)
+	}
+	want, err := findChecksum(checksums, archiveName)
+	if err != nil {
+		return err
+	}
+
+	archive, err := u.get(archiveURL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %v", archiveName, err)
+	}
+	sum := sha256.Sum256(archive)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", archiveName, got, want)
+	}
+
+	binaryName := "unregex"
+	if u.GOOS == "windows" {
+		binaryName += ".exe"
+	}
+	var binary []byte
+	if u.GOOS == "windows" {
+		binary, err = extractZip(archive, binaryName)
+	} else {
+		binary, err = extractTarGz(archive, binaryName)
+	}
+	if err != nil {
+		return err
+	}
+
+	return replaceBinary(exePath, binary, u.GOOS == "windows")
+}
+
+// assetURL returns the download URL of the named asset
+func (r *Release) assetURL(name string) string {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset.URL
+		}
+	}
+	return ""
+}
+
+// get downloads a URL
+func (u *Updater) get(url string) ([]byte, error) {
+	resp, err := u.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// findChecksum looks up a file's SHA-256 in a checksums file with
+// "<hash>  <name>" lines
+func findChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s", name)
+}
+
+// extractTarGz reads a file from a gzipped tarball
+func extractTarGz(archive []byte, name string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("archive doesn't contain %s", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive: %v", err)
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == name {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// extractZip reads a file from a zip archive
+func extractZip(archive []byte, name string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %v", err)
+	}
+	for _, file := range zr.File {
+		if filepath.Base(file.Name) != name {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive: %v", err)
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("archive doesn't contain %s", name)
+}
+
+// replaceBinary writes the new binary next to the old one and renames it
+// into place. Windows can't overwrite a running executable, so the old one
+// is moved aside first.
+func replaceBinary(exePath string, binary []byte, windows bool) error {
+	mode := os.FileMode(0755)
+	if info, err := os.Stat(exePath); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	newPath := exePath + ".new"
+	if err := os.WriteFile(newPath, binary, mode); err != nil {
+		return fmt.Errorf("failed to write the new binary: %v", err)
+	}
+
+	if windows {
+		oldPath := exePath + ".old"
+		os.Remove(oldPath)
+		if err := os.Rename(exePath, oldPath); err != nil {
+			os.Remove(newPath)
+			return fmt.Errorf("failed to move the old binary aside: %v", err)
+		}
+	}
+
+	if err := os.Rename(newPath, exePath); err != nil {
+		os.Remove(newPath)
+		return fmt.Errorf("failed to replace the binary: %v", err)
+	}
+	return nil
+}
+
+// IsNewer reports whether version a is newer than version b, comparing
+// dot-separated numeric components and ignoring pre-release suffixes
+func IsNewer(a, b string) bool {
+	partsA := versionParts(a)
+	partsB := versionParts(b)
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var numA, numB int
+		if i < len(partsA) {
+			numA, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			numB, _ = strconv.Atoi(partsB[i])
+		}
+		if numA != numB {
+			return numA > numB
+		}
+	}
+	return false
+}
+
+// versionParts splits a version such as v1.2.3-rc1 into its numeric components
+func versionParts(version string) []string {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	return strings.Split(version, ".")
+}
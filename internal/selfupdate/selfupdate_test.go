@@ -0,0 +1,170 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func tarGz(t *testing.T, name string, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, file := range []struct {
+		name    string
+		content []byte
+	}{{"README.md", []byte("readme")}, {name, content}} {
+		if err := tw.WriteHeader(&tar.Header{Name: file.name, Mode: 0755, Size: int64(len(file.content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(file.content)
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+// releaseServer serves a fake GitHub release with a single archive
+func releaseServer(t *testing.T, archiveName string, archive []byte, checksum string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"tag_name": "v1.2.0", "assets": [
+			{"name": %q, "browser_download_url": "%s/archive"},
+			{"name": "unregex_1.2.0_checksums.txt", "browser_download_url": "%s/checksums"}
+		]}`, archiveName, server.URL, server.URL)
+	})
+	mux.HandleFunc("/archive", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+	mux.HandleFunc("/checksums", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "0000  unregex_1.2.0_darwin_arm64.tar.gz\n%s  %s\n", checksum, archiveName)
+	})
+	return server
+}
+
+func TestInstall(t *testing.T) {
+	archive := tarGz(t, "unregex", []byte("new binary"))
+	sum := sha256.Sum256(archive)
+	server := releaseServer(t, "unregex_1.2.0_linux_amd64.tar.gz", archive, hex.EncodeToString(sum[:]))
+
+	exePath := filepath.Join(t.TempDir(), "unregex")
+	if err := os.WriteFile(exePath, []byte("old binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	updater := &Updater{APIURL: server.URL + "/latest", Client: server.Client(), GOOS: "linux", GOARCH: "amd64"}
+	release, err := updater.Latest()
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if release.Version() != "1.2.0" {
+		t.Errorf("Version() = %q, want %q", release.Version(), "1.2.0")
+	}
+
+	if err := updater.Install(release, exePath); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	got, err := os.ReadFile(exePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "new binary" {
+		t.Errorf("binary = %q, want %q", got, "new binary")
+	}
+}
+
+func TestInstall_ChecksumMismatch(t *testing.T) {
+	archive := tarGz(t, "unregex", []byte("tampered binary"))
+	server := releaseServer(t, "unregex_1.2.0_linux_amd64.tar.gz", archive, "deadbeef")
+
+	exePath := filepath.Join(t.TempDir(), "unregex")
+	if err := os.WriteFile(exePath, []byte("old binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	updater := &Updater{APIURL: server.URL + "/latest", Client: server.Client(), GOOS: "linux", GOARCH: "amd64"}
+	release, err := updater.Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := updater.Install(release, exePath); err == nil {
+		t.Error("Install() should fail when the checksum doesn't match")
+	}
+	if got, _ := os.ReadFile(exePath); string(got) != "old binary" {
+		t.Errorf("binary = %q, the old binary should be kept", got)
+	}
+}
+
+func TestInstall_MissingPlatform(t *testing.T) {
+	server := releaseServer(t, "unregex_1.2.0_linux_amd64.tar.gz", nil, "")
+	updater := &Updater{APIURL: server.URL + "/latest", Client: server.Client(), GOOS: "plan9", GOARCH: "386"}
+	release, err := updater.Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := updater.Install(release, filepath.Join(t.TempDir(), "unregex")); err == nil {
+		t.Error("Install() should fail when the release has no build for the platform")
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("unregex.exe")
+	w.Write([]byte("windows binary"))
+	zw.Close()
+
+	got, err := extractZip(buf.Bytes(), "unregex.exe")
+	if err != nil {
+		t.Fatalf("extractZip() error = %v", err)
+	}
+	if string(got) != "windows binary" {
+		t.Errorf("extractZip() = %q, want %q", got, "windows binary")
+	}
+	if _, err := extractZip(buf.Bytes(), "missing"); err == nil {
+		t.Error("extractZip() should fail for a missing file")
+	}
+}
+
+func TestArchiveName(t *testing.T) {
+	if got := ArchiveName("1.2.0", "linux", "amd64"); got != "unregex_1.2.0_linux_amd64.tar.gz" {
+		t.Errorf("ArchiveName() = %q", got)
+	}
+	if got := ArchiveName("1.2.0", "windows", "arm64"); got != "unregex_1.2.0_windows_arm64.zip" {
+		t.Errorf("ArchiveName() = %q", got)
+	}
+}
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1.2.0", "1.1.9", true},
+		{"v0.10.0", "0.9.1", true},
+		{"0.2.2", "0.2.2", false},
+		{"0.2.1", "0.2.2", false},
+		{"1.0", "0.9.9", true},
+		{"1.0.0-rc1", "0.9.0", true},
+	}
+
+	for _, tt := range tests {
+		if got := IsNewer(tt.a, tt.b); got != tt.want {
+			t.Errorf("IsNewer(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
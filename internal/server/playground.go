@@ -0,0 +1,173 @@
+package server
+
+// playgroundPage is the single-page playground. It explains the pattern
+// through /api/explain as it's typed, colorizing each token as the HTML
+// output does, and highlights the matches in each line of the test strings.
+// The pattern, flavor, flags and test strings are kept in the URL fragment,
+// so a playground can be shared as a link.
+const playgroundPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>unregex playground</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #24292e; }
+code, .pattern, input, textarea, #tests-result { font-family: SFMono-Regular, Consolas, "Liberation Mono", Menlo, monospace; }
+.controls { display: flex; gap: 0.5em; margin-bottom: 1em; }
+.controls input, .controls select, textarea { font-size: 1em; padding: 0.5em; box-sizing: border-box; border: 1px solid #d1d5da; border-radius: 6px; }
+#pattern { flex: 1; }
+#flags { width: 6em; }
+textarea { width: 100%; min-height: 6em; }
+.pattern { font-size: 1.6em; padding: 0.6em; background: #f6f8fa; border-radius: 6px; word-break: break-all; min-height: 1.2em; }
+.tok { font-weight: bold; cursor: help; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+th, td { text-align: left; padding: 0.35em 0.7em; border-bottom: 1px solid #e1e4e8; vertical-align: top; }
+#tests-result { white-space: pre-wrap; padding: 0.6em; background: #f6f8fa; border-radius: 6px; min-height: 1.5em; }
+#tests-result mark { background: #fff5b1; border-bottom: 2px solid #b08800; }
+.groups { color: #586069; font-size: 0.85em; margin: 0 0 0.6em 1em; }
+#error { color: #d73a49; }
+#status { color: #586069; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>unregex playground</h1>
+
+<div class="controls">
+<input id="pattern" placeholder="Pattern, such as ^(?P&lt;year&gt;\d{4})-(?P&lt;month&gt;\d{2})$" spellcheck="false" autofocus>
+<select id="flavor"><option>go</option></select>
+<input id="flags" placeholder="Flags" spellcheck="false">
+</div>
+
+<p id="error"></p>
+<div class="pattern" id="colored"></div>
+<p id="status"></p>
+
+<h2>Test strings</h2>
+<textarea id="tests" placeholder="One test string per line" spellcheck="false"></textarea>
+<div id="tests-result"></div>
+
+<h2>Tokens</h2>
+<table id="tokens"><tr><th>#</th><th>Token</th><th>Explanation</th></tr></table>
+
+<script>
+const palette = ["#d73a49", "#22863a", "#005cc5", "#b08800", "#6f42c1", "#1b7c83"];
+const $ = id => document.getElementById(id);
+let pending = 0;
+
+function el(tag, text, attrs) {
+  const node = document.createElement(tag);
+  if (text !== undefined) node.textContent = text;
+  Object.assign(node, attrs || {});
+  return node;
+}
+
+// Color each token where it is in the pattern, keeping any text the
+// tokenizer skipped, as the HTML output does
+function renderPattern(exp) {
+  const colored = $("colored");
+  colored.replaceChildren();
+  let pos = 0;
+  exp.tokens.forEach((token, i) => {
+    const at = exp.pattern.indexOf(token.token, pos);
+    if (at < 0) return;
+    colored.append(exp.pattern.slice(pos, at));
+    const span = el("span", token.token, { className: "tok", title: (i + 1) + ". " + token.explanation });
+    span.style.color = palette[i % palette.length];
+    colored.append(span);
+    pos = at + token.token.length;
+  });
+  colored.append(exp.pattern.slice(pos));
+
+  const table = $("tokens");
+  table.replaceChildren(table.rows[0]);
+  exp.tokens.forEach((token, i) => {
+    const row = table.insertRow();
+    row.insertCell().textContent = i + 1;
+    const code = el("code", token.token);
+    code.style.color = palette[i % palette.length];
+    row.insertCell().append(code);
+    row.insertCell().textContent = token.explanation;
+  });
+  $("status").textContent = exp.sample ? "Example match: " + exp.sample + " (" + exp.sampleStatus + ")" : "";
+}
+
+// Highlight every match in each test string, listing its capture groups.
+// Offsets are taken in code points, as JavaScript strings are UTF-16.
+function renderMatches(lines, resp) {
+  const result = $("tests-result");
+  result.replaceChildren();
+  if (resp.matchError) {
+    result.append(el("span", resp.matchError, { id: "match-error" }));
+    return;
+  }
+  (resp.matches || []).forEach((match, i) => {
+    const chars = Array.from(lines[i]);
+    const line = el("div");
+    let pos = 0;
+    const groups = [];
+    (match.matches || []).forEach(spans => {
+      const whole = spans[0];
+      line.append(chars.slice(pos, whole.runeStart).join(""));
+      line.append(el("mark", chars.slice(whole.runeStart, whole.runeEnd).join("") || "\u200b"));
+      pos = whole.runeEnd;
+      spans.slice(1).filter(span => span.matched).forEach(span => {
+        groups.push((span.name || span.group) + ": " + JSON.stringify(span.text));
+      });
+    });
+    line.append(chars.slice(pos).join(""));
+    result.append(line);
+    if (groups.length) result.append(el("div", groups.join("  "), { className: "groups" }));
+  });
+}
+
+async function update() {
+  const pattern = $("pattern").value;
+  const tests = $("tests").value;
+  const lines = tests ? tests.split("\n") : [];
+  location.replace("#" + new URLSearchParams({ pattern, flavor: $("flavor").value, flags: $("flags").value, tests }));
+  const request = ++pending;
+  if (!pattern) {
+    ["error", "colored", "status", "tests-result"].forEach(id => $(id).replaceChildren());
+    $("tokens").replaceChildren($("tokens").rows[0]);
+    return;
+  }
+
+  const response = await fetch("api/explain", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ pattern, flavor: $("flavor").value, flags: $("flags").value, inputs: lines }),
+  });
+  const resp = await response.json();
+  if (request !== pending) return;
+  if (resp.error) {
+    $("error").textContent = resp.error;
+    return;
+  }
+  $("error").textContent = "";
+  renderPattern(resp.explanation);
+  renderMatches(lines, resp);
+}
+
+async function init() {
+  const flavors = await (await fetch("api/flavors")).json();
+  $("flavor").replaceChildren(...flavors.map(name => el("option", name)));
+
+  const saved = new URLSearchParams(location.hash.slice(1));
+  $("pattern").value = saved.get("pattern") || "";
+  $("flavor").value = saved.get("flavor") || "go";
+  $("flags").value = saved.get("flags") || "";
+  $("tests").value = saved.get("tests") || "";
+
+  let timer;
+  const schedule = () => { clearTimeout(timer); timer = setTimeout(update, 150); };
+  ["pattern", "flags", "tests"].forEach(id => $(id).addEventListener("input", schedule));
+  $("flavor").addEventListener("change", update);
+  update();
+}
+
+init();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,153 @@
+// Package server implements an HTTP API that explains regexes and matches
+// them against test strings, and a single-page playground built on it.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/weslien/unregex/internal/app"
+	"github.com/weslien/unregex/pkg/format"
+)
+
+// maxRequestSize caps the size of a request body, so a client can't make
+// the server read an unbounded pattern
+const maxRequestSize = 1 << 20
+
+// ExplainRequest is the body of a POST to /api/explain
+type ExplainRequest struct {
+	Pattern string `json:"pattern"`
+
+	// Flavor is the regex format the pattern is written in, go by default
+	Flavor string `json:"flavor,omitempty"`
+
+	// Flags are flags set outside the pattern, such as i or re.IGNORECASE
+	Flags string `json:"flags,omitempty"`
+
+	// Inputs are test strings to match the pattern against, if any
+	Inputs []string `json:"inputs,omitempty"`
+}
+
+// ExplainResponse is the explanation of a pattern, with a match result for
+// each input. MatchError says why the inputs couldn't be matched, such as
+// the pattern using features Go's regexp package lacks, in which case the
+// explanation is still given.
+type ExplainResponse struct {
+	Explanation *app.Explanation  `json:"explanation"`
+	Matches     []app.MatchResult `json:"matches,omitempty"`
+	MatchError  string            `json:"matchError,omitempty"`
+}
+
+// Server serves the API, and the playground when enabled
+type Server struct {
+	ui  bool
+	mux *http.ServeMux
+}
+
+// NewServer returns a server for the API under /api/, and with ui set, the
+// playground at /
+func NewServer(ui bool) *Server {
+	s := &Server{ui: ui, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/api/explain", s.handleExplain)
+	s.mux.HandleFunc("/api/flavors", s.handleFlavors)
+	if ui {
+		s.mux.HandleFunc("/", s.handlePlayground)
+	}
+	return s
+}
+
+// ServeHTTP routes a request to the API or the playground
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleExplain explains the pattern in an ExplainRequest
+func (s *Server) handleExplain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "explain needs a POST")
+		return
+	}
+
+	var req ExplainRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+		return
+	}
+	resp, err := s.explain(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// explain explains a pattern and matches it against the inputs
+func (s *Server) explain(req ExplainRequest) (*ExplainResponse, error) {
+	if req.Pattern == "" {
+		return nil, errors.New("no regex pattern provided")
+	}
+	flavor := strings.ToLower(req.Flavor)
+	if flavor == "" {
+		flavor = "go"
+	}
+	if _, ok := format.Lookup(flavor); !ok {
+		return nil, &app.ErrUnknownFormat{Format: flavor}
+	}
+	flags, err := app.ResolveFlags(flavor, req.Flags)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ExplainResponse{Explanation: app.AnalyzeWithFlags(req.Pattern, flavor, flags)}
+	if len(req.Inputs) == 0 {
+		return resp, nil
+	}
+	compiled, err := app.CompilePattern(req.Pattern, flavor, flags)
+	if err != nil {
+		resp.MatchError = err.Error()
+		return resp, nil
+	}
+	for _, input := range req.Inputs {
+		resp.Matches = append(resp.Matches, app.MatchAll(compiled, input))
+	}
+	return resp, nil
+}
+
+// handleFlavors lists the names of the registered formats
+func (s *Server) handleFlavors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, "flavors needs a GET")
+		return
+	}
+	writeJSON(w, http.StatusOK, format.Names())
+}
+
+// handlePlayground serves the playground page
+func (s *Server) handlePlayground(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, playgroundPage)
+}
+
+// writeJSON writes v as the JSON response body, leaving characters such as
+// < unescaped so patterns stay readable
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(v)
+}
+
+// writeError writes an error response as {"error": "..."}
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// post sends a request body to /api/explain and decodes the response
+func post(t *testing.T, s *Server, body string) (int, map[string]json.RawMessage) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/explain", strings.NewReader(body)))
+
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("POST %s: invalid JSON response %q: %v", body, rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+func TestExplain(t *testing.T) {
+	code, resp := post(t, NewServer(false), `{"pattern": "(?P<n>\\d+)x", "inputs": ["1x 22x", "no"]}`)
+	if code != http.StatusOK {
+		t.Fatalf("POST /api/explain status = %d, want 200: %s", code, resp["error"])
+	}
+
+	var exp struct {
+		Tokens []struct{ Token string } `json:"tokens"`
+	}
+	if err := json.Unmarshal(resp["explanation"], &exp); err != nil || len(exp.Tokens) != 5 {
+		t.Errorf("POST /api/explain explanation = %s, want 5 tokens", resp["explanation"])
+	}
+
+	var matches []struct {
+		Matched bool `json:"matched"`
+		Matches [][]struct {
+			Name string `json:"name"`
+			Text string `json:"text"`
+		} `json:"matches"`
+	}
+	if err := json.Unmarshal(resp["matches"], &matches); err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || !matches[0].Matched || len(matches[0].Matches) != 2 || matches[1].Matched {
+		t.Fatalf("POST /api/explain matches = %s, want two matches in the first input only", resp["matches"])
+	}
+	if group := matches[0].Matches[1][1]; group.Name != "n" || group.Text != "22" {
+		t.Errorf("POST /api/explain second match group = %+v, want n: 22", group)
+	}
+}
+
+func TestExplain_Errors(t *testing.T) {
+	s := NewServer(false)
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"pattern": ""}`, "no regex pattern provided"},
+		{`{"pattern": "a", "flavor": "perl"}`, "unsupported regex format 'perl'"},
+		{`{"pattern": `, "invalid request"},
+	}
+	for _, tt := range tests {
+		code, resp := post(t, s, tt.body)
+		if code != http.StatusBadRequest || !strings.Contains(string(resp["error"]), tt.want) {
+			t.Errorf("POST %s = %d %s, want 400 with %q", tt.body, code, resp["error"], tt.want)
+		}
+	}
+
+	// Patterns Go can't match are still explained
+	code, resp := post(t, s, `{"pattern": "(?<=a)b", "flavor": "pcre", "inputs": ["ab"]}`)
+	if code != http.StatusOK || resp["explanation"] == nil || !strings.Contains(string(resp["matchError"]), "lookbehind") {
+		t.Errorf("POST with a lookbehind = %d %v, want an explanation and a match error", code, resp)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/explain", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /api/explain status = %d, want 405", rec.Code)
+	}
+}
+
+func TestPlayground(t *testing.T) {
+	for _, tt := range []struct {
+		ui   bool
+		path string
+		want int
+	}{
+		{true, "/", http.StatusOK},
+		{true, "/missing", http.StatusNotFound},
+		{false, "/", http.StatusNotFound},
+		{false, "/api/flavors", http.StatusOK},
+	} {
+		rec := httptest.NewRecorder()
+		NewServer(tt.ui).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("GET %s with ui %v status = %d, want %d", tt.path, tt.ui, rec.Code, tt.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	NewServer(true).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !strings.Contains(rec.Body.String(), `fetch("api/explain"`) {
+		t.Error("GET / should serve the playground built on the API")
+	}
+}
@@ -0,0 +1,99 @@
+// Package session reads the JSON that regex playgrounds such as regex101
+// export saved regexes as, so work saved on the website can be explained
+// and tested with unregex
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Session is a saved regex with what it was tested against
+type Session struct {
+	Pattern string
+	Format  string
+
+	// Flags are the flags the pattern was compiled with, other than g
+	Flags string
+
+	// Global is set by the g flag, for every match in a test string rather
+	// than the first
+	Global bool
+
+	// Inputs are the lines of the test string, followed by the test strings
+	// of any unit tests
+	Inputs []string
+}
+
+// export is a regex as regex101 exports it
+type export struct {
+	Regex      string `json:"regex"`
+	Flags      string `json:"flags"`
+	Flavor     string `json:"flavor"`
+	TestString string `json:"testString"`
+	UnitTests  []struct {
+		TestString string `json:"testString"`
+	} `json:"unitTests"`
+}
+
+// flavors maps the flavors of the playgrounds to unregex's. Flavors without
+// a counterpart, such as Java and .NET, can't be imported.
+var flavors = map[string]string{
+	"":           "pcre",
+	"pcre":       "pcre",
+	"pcre2":      "pcre",
+	"javascript": "js",
+	"ecmascript": "js",
+	"python":     "python",
+	"golang":     "go",
+	"go":         "go",
+}
+
+// Parse reads one exported regex, or a JSON array of them
+func Parse(data []byte) ([]Session, error) {
+	var exports []export
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &exports); err != nil {
+			return nil, fmt.Errorf("invalid session export: %v", err)
+		}
+	} else {
+		var single export
+		if err := json.Unmarshal(data, &single); err != nil {
+			return nil, fmt.Errorf("invalid session export: %v", err)
+		}
+		exports = []export{single}
+	}
+	if len(exports) == 0 {
+		return nil, errors.New("the session export has no regexes")
+	}
+
+	sessions := make([]Session, len(exports))
+	for i, e := range exports {
+		if e.Regex == "" {
+			return nil, fmt.Errorf("regex %d of the session export has no pattern", i+1)
+		}
+		format, ok := flavors[strings.ToLower(e.Flavor)]
+		if !ok {
+			return nil, fmt.Errorf("regex %d of the session export is in the %s flavor, which unregex doesn't support", i+1, e.Flavor)
+		}
+
+		s := Session{
+			Pattern: e.Regex,
+			Format:  format,
+			Flags:   strings.ReplaceAll(e.Flags, "g", ""),
+			Global:  strings.Contains(e.Flags, "g"),
+		}
+		if e.TestString != "" {
+			s.Inputs = strings.Split(strings.TrimSuffix(strings.ReplaceAll(e.TestString, "\r\n", "\n"), "\n"), "\n")
+		}
+		for _, test := range e.UnitTests {
+			s.Inputs = append(s.Inputs, test.TestString)
+		}
+		sessions[i] = s
+	}
+	return sessions, nil
+}
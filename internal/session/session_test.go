@@ -0,0 +1,59 @@
+package session
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	export := `{
+		"regex": "^(?<year>\\d{4})-(?<month>\\d{2})$",
+		"flags": "gm",
+		"delimiter": "/",
+		"flavor": "pcre2",
+		"testString": "2024-05\r\n24-5\n",
+		"substitution": "",
+		"unitTests": [{"description": "short year", "testString": "99-01", "criteria": "DOES_NOT_MATCH", "target": "REGEX"}]
+	}`
+	got, err := Parse([]byte(export))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []Session{{
+		Pattern: `^(?<year>\d{4})-(?<month>\d{2})$`,
+		Format:  "pcre",
+		Flags:   "m",
+		Global:  true,
+		Inputs:  []string{"2024-05", "24-5", "99-01"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+
+	got, err = Parse([]byte(`[{"regex": "a+", "flavor": "javascript"}, {"regex": "b", "flavor": "golang", "flags": "i"}]`))
+	if err != nil {
+		t.Fatalf("Parse() of an array error = %v", err)
+	}
+	want = []Session{{Pattern: "a+", Format: "js"}, {Pattern: "b", Format: "go", Flags: "i"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() of an array = %+v, want %+v", got, want)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		export string
+		want   string
+	}{
+		{`{"regex": `, "invalid session export"},
+		{`[]`, "has no regexes"},
+		{`{"flavor": "pcre"}`, "regex 1 of the session export has no pattern"},
+		{`[{"regex": "a"}, {"regex": "a", "flavor": "java"}]`, "regex 2 of the session export is in the java flavor"},
+	}
+	for _, tt := range tests {
+		if _, err := Parse([]byte(tt.export)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%s) error = %v, want %q", tt.export, err, tt.want)
+		}
+	}
+}
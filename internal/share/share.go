@@ -0,0 +1,73 @@
+// Package share encodes a pattern, its flavor and flags and test strings
+// into a compact URL-safe token, so an explanation can be passed around in
+// chat and reproduced exactly
+package share
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// version prefixes every token, so the encoding can change without
+// misreading tokens made by older versions
+const version = "1"
+
+// maxDecodedSize caps how large a token can inflate to, so a crafted token
+// can't exhaust memory
+const maxDecodedSize = 1 << 20
+
+// Permalink is what a token reproduces
+type Permalink struct {
+	Pattern string   `json:"p"`
+	Format  string   `json:"f"`
+	Flags   string   `json:"g,omitempty"`
+	Inputs  []string `json:"t,omitempty"`
+}
+
+// Encode returns the token for a permalink: its JSON compressed with
+// DEFLATE and encoded as unpadded URL-safe base64, after a version prefix
+func Encode(p Permalink) string {
+	data, _ := json.Marshal(p)
+
+	var compressed bytes.Buffer
+	w, _ := flate.NewWriter(&compressed, flate.BestCompression)
+	w.Write(data)
+	w.Close()
+
+	return version + base64.RawURLEncoding.EncodeToString(compressed.Bytes())
+}
+
+// Decode returns the permalink a token encodes
+func Decode(token string) (Permalink, error) {
+	token = strings.TrimSpace(token)
+	if !strings.HasPrefix(token, version) {
+		return Permalink{}, errors.New("not an unregex share token, or one from a newer version")
+	}
+
+	compressed, err := base64.RawURLEncoding.DecodeString(token[len(version):])
+	if err != nil {
+		return Permalink{}, fmt.Errorf("invalid share token: %v", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(compressed)), maxDecodedSize+1))
+	if err != nil {
+		return Permalink{}, fmt.Errorf("invalid share token: %v", err)
+	}
+	if len(data) > maxDecodedSize {
+		return Permalink{}, errors.New("invalid share token: too large")
+	}
+
+	var p Permalink
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Permalink{}, fmt.Errorf("invalid share token: %v", err)
+	}
+	if p.Pattern == "" || p.Format == "" {
+		return Permalink{}, errors.New("invalid share token: no pattern or flavor")
+	}
+	return p, nil
+}
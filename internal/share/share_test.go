@@ -0,0 +1,59 @@
+package share
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []Permalink{
+		{Pattern: `^(?P<year>\d{4})-(?P<month>\d{2})$`, Format: "python", Flags: "i", Inputs: []string{"2024-05", "24-5", "ünïcode\n\tlines"}},
+		{Pattern: `a`, Format: "go"},
+	}
+	for _, p := range tests {
+		token := Encode(p)
+		if strings.Trim(token, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_") != "" {
+			t.Errorf("Encode(%+v) = %q, want a URL-safe token", p, token)
+		}
+		got, err := Decode(" " + token + "\n")
+		if err != nil {
+			t.Fatalf("Decode(%q) error = %v", token, err)
+		}
+		if !reflect.DeepEqual(got, p) {
+			t.Errorf("Decode(Encode(%+v)) = %+v", p, got)
+		}
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	deflate := func(data string) string {
+		var compressed bytes.Buffer
+		w, _ := flate.NewWriter(&compressed, flate.BestCompression)
+		w.Write([]byte(data))
+		w.Close()
+		return version + base64.RawURLEncoding.EncodeToString(compressed.Bytes())
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"empty", "", "not an unregex share token"},
+		{"newer version", "2abc", "not an unregex share token"},
+		{"not base64", "1a+b", "invalid share token"},
+		{"not deflate", "1" + base64.RawURLEncoding.EncodeToString([]byte("plain")), "invalid share token"},
+		{"not JSON", deflate("pattern"), "invalid share token"},
+		{"no pattern", deflate(`{"f": "go"}`), "no pattern or flavor"},
+		{"too large", deflate(`{"p": "` + strings.Repeat("a", maxDecodedSize) + `", "f": "go"}`), "too large"},
+	}
+	for _, tt := range tests {
+		if _, err := Decode(tt.token); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Decode() of %s token error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
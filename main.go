@@ -8,36 +8,187 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/weslien/unregex/internal/app"
+	"github.com/weslien/unregex/internal/clipboard"
+	"github.com/weslien/unregex/internal/config"
+	"github.com/weslien/unregex/internal/history"
+	"github.com/weslien/unregex/internal/plugin"
+	"github.com/weslien/unregex/internal/saved"
+	"github.com/weslien/unregex/internal/session"
+	"github.com/weslien/unregex/pkg/format"
 	"github.com/weslien/unregex/pkg/utils"
 )
 
 func main() {
+	// Load the config file first, since its plugins add formats that
+	// subcommands accept too
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := registerPlugins(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	userConfig = cfg
+
+	// Draw only with ASCII in locales that aren't UTF-8, subcommands too
+	app.SetASCII(app.LocaleIsASCII())
+
+	// Dispatch subcommands before the top-level flags are parsed
+	if len(os.Args) > 1 && os.Args[1] == "again" {
+		args, err := againArgs(os.Args[2:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Args = append([]string{os.Args[0]}, args...)
+	} else if len(os.Args) > 1 && os.Args[1] == "explain" {
+		// 'unregex explain ...' is the same as 'unregex ...'
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	} else if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(app.ExitCode(err))
+			}
+			return
+		}
+	}
+
 	// Define command-line flags
-	formatFlag := flag.String("format", "go", "Regex format/flavor (go, pcre, posix, js, python)")
+	formatFlag := flag.String("format", "go", "Regex format/flavor ("+supportedFormats()+")")
+	flagsFlag := flag.String("flags", "", "Flags the pattern is compiled with outside it, as letters such as x or constants such as re.VERBOSE")
+	shellFlag := flag.String("shell", "", "Remove the quoting of a pattern pasted from a command line in the given shell: bash or powershell")
+	var grokPatternsFlag patternList
+	flag.Var(&grokPatternsFlag, "grok-patterns", "Add the grok patterns defined in a file, one NAME pattern per line, to the library the grok format expands (repeatable)")
+	jsStringFlag := flag.Bool("js-string", false, "Unescape a js pattern given as a JavaScript string literal, such as \"\\\\d+\"")
+	outputFlag := flag.String("output", "text", "Output format (text, markdown, html, html-snippet, dot, railroad, roff, rst)")
+	outputFileFlag := flag.String("o", "", "Write non-text outputs to a file instead of stdout")
+	templateFlag := flag.String("template", "", "Render the explanation with a Go text/template file instead of an output format")
 	visualizeFlag := flag.Bool("visualize", false, "Output visual annotation of the regex with numbered parts")
+	sampleBiasFlag := flag.String("sample-bias", "typical", "How often example matches repeat quantified elements (min, typical, max)")
+	realisticFlag := flag.Bool("realistic", false, "Fill example matches with real-looking words, years, names and domains")
+	maxLengthFlag := flag.Int("max-length", 0, "Longest example match to generate, repeating unbounded quantifiers fewer times to fit (0 for no limit)")
+	seedFlag := flag.Int64("seed", 0, "Seed for random example matches, named group examples and property tests, to reproduce them (0 keeps example matches the same and picks one for the others)")
+	colorFlag := flag.String("color", "auto", "When to color the text output (always, never, auto)")
+	asciiFlag := flag.Bool("ascii", false, "Draw marks, bars and rules with ASCII, such as [x] for ✓ (default on when the locale isn't UTF-8; -ascii=false turns it off)")
+	accessibleFlag := flag.Bool("accessible", false, "Explain in plain prose for screen readers, without color, tables or box drawing")
+	themeFlag := flag.String("theme", "", "Color theme (default, high-contrast, deuteranopia, or one defined in the config file)")
+	streamFlag := flag.Bool("stream", false, "Explain each line of stdin as a separate pattern as it arrives")
+	fileFlag := flag.String("f", "", "Read the pattern from a file")
+	watchFlag := flag.Bool("watch", false, "Explain the -f file again whenever it changes")
+	clipboardFlag := flag.Bool("clipboard", false, "Read the pattern from the system clipboard")
+	copySampleFlag := flag.Bool("copy-sample", false, "Copy the pattern's example match to the system clipboard")
+	noHistoryFlag := flag.Bool("no-history", false, "Don't record explained patterns in the history")
+	noPagerFlag := flag.Bool("no-pager", false, "Don't pipe long text output through $PAGER")
+	hyperlinksFlag := flag.Bool("hyperlinks", true, "Link token explanations to the flavor's documentation in terminals that support it")
+	propTestFlag := flag.Bool("proptest", false, "Emit a Go property-based test for the pattern instead of an explanation")
+	packageFlag := flag.String("package", "main", "Package name used for the emitted property test")
+	namedGroupsFlag := flag.Bool("named-groups", false, "Output a Markdown table documenting the pattern's named groups")
+	var enumerateFlag enumerateLimit
+	flag.Var(&enumerateFlag, "enumerate", "List every string a pattern without unbounded quantifiers matches, or the first N with -enumerate=N")
+	countLengthsFlag := flag.Int("count-lengths", 0, "Count the strings of each length up to N the pattern matches instead of explaining it")
+	shortestFlag := flag.Bool("shortest", false, "Output the shortest string the pattern matches, derived from its structure, instead of an explanation")
+	quantifiersFlag := flag.Bool("quantifiers", false, "Show what each quantifier matches as greedy, lazy and possessive instead of an explanation")
+	quantifierInputFlag := flag.String("quantifier-input", "", "Input to try the quantifiers on with -quantifiers, instead of the example match twice over")
+	flagMatrixFlag := flag.Bool("flag-matrix", false, "Show what the pattern matches under every combination of the i, m and s flags instead of an explanation")
+	flagMatrixInputFlag := flag.String("flag-matrix-input", "", "Input to match with -flag-matrix, instead of the example match")
 	helpFlag := flag.Bool("help", false, "Show help message")
 	versionFlag := flag.Bool("version", false, "Show version information")
 
 	// Custom usage function
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Unregex - %s\n\n", utils.Description())
-		fmt.Fprintf(os.Stderr, "Usage:\n")
-		fmt.Fprintf(os.Stderr, "  unregex [options] <pattern>\n")
-		fmt.Fprintf(os.Stderr, "  echo '<pattern>' | unregex [options]\n\n")
-		fmt.Fprintf(os.Stderr, "Options:\n")
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Unregex - %s\n\n", utils.Description())
+		fmt.Fprintf(out, "Usage:\n")
+		fmt.Fprintf(out, "  unregex [options] <pattern> [pattern...]\n")
+		fmt.Fprintf(out, "  unregex [options] -- <pattern> [pattern...]\n")
+		fmt.Fprintf(out, "  echo '<pattern>' | unregex [options]\n")
+		fmt.Fprintf(out, "  unregex batch [options] <file>\n")
+		fmt.Fprintf(out, "  unregex bench [options] <pattern> <input> [input...]\n")
+		fmt.Fprintf(out, "  unregex docgen [options] [dir]\n")
+		fmt.Fprintf(out, "  unregex explain [options] @name\n")
+		fmt.Fprintf(out, "  unregex explore [options] <pattern> <file>\n")
+		fmt.Fprintf(out, "  unregex gen [options] <pattern>\n")
+		fmt.Fprintf(out, "  unregex save [options] <name> <pattern>\n")
+		fmt.Fprintf(out, "  unregex lib show [options] <name>\n")
+		fmt.Fprintf(out, "  unregex test [options] <pattern> <input> [input...]\n")
+		fmt.Fprintf(out, "  unregex lsp\n")
+		fmt.Fprintf(out, "  unregex history [options]\n")
+		fmt.Fprintf(out, "  unregex again [options] <id>\n")
+		fmt.Fprintf(out, "  unregex serve [options]\n")
+		fmt.Fprintf(out, "  unregex share [options] <pattern> [test string...]\n")
+		fmt.Fprintf(out, "  unregex open [options] <token>\n")
+		fmt.Fprintf(out, "  unregex simplify [options] <pattern>\n")
+		fmt.Fprintf(out, "  unregex self-update [options]\n\n")
+		fmt.Fprintf(out, "Options:\n")
 		flag.PrintDefaults()
-		fmt.Fprintf(os.Stderr, "\nExamples:\n")
-		fmt.Fprintf(os.Stderr, "  unregex \"^hello(world|universe)[0-9]+$\"\n")
-		fmt.Fprintf(os.Stderr, "  unregex -format pcre \"(?<=look)behind\"\n")
-		fmt.Fprintf(os.Stderr, "  unregex -visualize \"a{2,4}b[a-z]*\\d+\"\n")
-		fmt.Fprintf(os.Stderr, "  echo \"a{2,4}b[a-z]*\\d+\" | unregex\n")
+		fmt.Fprintf(out, "\nPatterns that start with '-' must come after '--' or be given with -pattern,\n")
+		fmt.Fprintf(out, "otherwise they are read as options.\n")
+		fmt.Fprintf(out, "\nEnvironment:\n")
+		fmt.Fprintf(out, "  UNREGEX_<OPTION>   Default for an option, e.g. UNREGEX_FORMAT=pcre or UNREGEX_NO_PAGER=true\n")
+		fmt.Fprintf(out, "  UNREGEX_NO_COLOR   Same as UNREGEX_COLOR=never\n")
+		fmt.Fprintf(out, "  NO_COLOR           Disable colors when -color is auto\n")
+		fmt.Fprintf(out, "  PAGER              Pager for long text output (default less)\n")
+		fmt.Fprintf(out, "\nExamples:\n")
+		fmt.Fprintf(out, "  unregex \"^hello(world|universe)[0-9]+$\"\n")
+		fmt.Fprintf(out, "  unregex -format pcre \"(?<=look)behind\"\n")
+		fmt.Fprintf(out, "  unregex -visualize \"a{2,4}b[a-z]*\\d+\"\n")
+		fmt.Fprintf(out, "  unregex -- \"-\\d+-\"\n")
+		fmt.Fprintf(out, "  unregex -pattern \"-\\d+-\" -pattern \"--\\w+\"\n")
+		fmt.Fprintf(out, "  unregex -output markdown \"^\\d{3}-\\d{4}$\" > explanation.md\n")
+		fmt.Fprintf(out, "  unregex -template report.tmpl -o report.txt \"^\\d{3}-\\d{4}$\"\n")
+		fmt.Fprintf(out, "  unregex -output html -o report.html \"(?P<year>\\d{4})-(?P<month>\\d{2})\"\n")
+		fmt.Fprintf(out, "  echo \"a{2,4}b[a-z]*\\d+\" | unregex\n")
+		fmt.Fprintf(out, "  tail -f patterns.log | unregex -stream\n")
+		fmt.Fprintf(out, "  unregex -watch -f pattern.txt\n")
+		fmt.Fprintf(out, "  unregex -clipboard -copy-sample\n")
+		fmt.Fprintf(out, "  unregex -visualize -fragment \"^(?P<user>\\w+)\" -fragment \"@(?P<host>[\\w.]+)$\"\n")
+		fmt.Fprintf(out, "  unregex -color=always \"^\\w+$\" | less -R\n")
+		fmt.Fprintf(out, "  unregex -proptest -package mypkg \"^[a-z]+@[a-z]+\\.com$\" > pattern_prop_test.go\n")
 	}
 
-	// Parse command-line flags
-	flag.Parse()
+	// Layer the config file and UNREGEX_* environment variables under the flags
+	if err := cfg.ApplyDefaults(flag.CommandLine, os.Getenv); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Registered after the defaults are layered on, so patterns only ever
+	// come from the command line or stdin
+	var patternFlag patternList
+	flag.Var(&patternFlag, "pattern", "Pattern to explain, even one starting with '-' (repeatable)")
+	var fragmentFlag patternList
+	flag.Var(&fragmentFlag, "fragment", "Fragment of a pattern, joined in order with the others and marked in the explanation (repeatable)")
+	fragmentsFileFlag := flag.String("fragments", "", "Read pattern fragments from a file, one per line")
+	importFlag := flag.String("import", "", "Explain the regexes in a JSON export from a playground such as regex101 (- for stdin)")
+
+	// Parse command-line flags. Errors are reported here rather than by the
+	// flag package so a pattern mistaken for an option gets a hint.
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	flag.CommandLine.SetOutput(io.Discard)
+	err = flag.CommandLine.Parse(os.Args[1:])
+	flag.CommandLine.SetOutput(os.Stderr)
+	if err == flag.ErrHelp {
+		flag.Usage()
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if arg := undefinedFlag(err); arg != "" {
+			fmt.Fprintf(os.Stderr, "If %s is a pattern, pass it after '--' or with -pattern: unregex -- '%s'\n", arg, arg)
+		}
+		fmt.Fprintln(os.Stderr, "Run 'unregex -help' for usage information")
+		os.Exit(app.ExitUsage)
+	}
 
 	// Show help message and exit
 	if *helpFlag {
@@ -51,36 +202,801 @@ func main() {
 		os.Exit(0)
 	}
 
-	fmt.Printf("Unregex - Regex Visualizer v%s\n\n", utils.Version)
+	// Validate the shell whose quoting is removed
+	if _, err := format.UnquoteShell("", *shellFlag); *shellFlag != "" && err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Unsupported shell '%s'\n", *shellFlag)
+		fmt.Fprintf(os.Stderr, "Supported shells: %s\n", strings.Join(format.Shells, ", "))
+		os.Exit(app.ExitUsage)
+	}
 
 	// Validate regex format
 	format := strings.ToLower(*formatFlag)
 	if !utils.IsValidFormat(format) {
 		fmt.Fprintf(os.Stderr, "Error: Unsupported regex format '%s'\n", format)
-		fmt.Fprintf(os.Stderr, "Supported formats: go, pcre, posix, js, python\n")
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Supported formats: %s\n", supportedFormats())
+		os.Exit(app.ExitUsage)
 	}
 
-	// Get regex pattern from arguments or stdin
-	pattern, err := getRegexPattern()
-	if err != nil {
+	// Validate output format
+	output := strings.ToLower(*outputFlag)
+	if !utils.IsValidOutput(output) {
+		fmt.Fprintf(os.Stderr, "Error: Unsupported output format '%s'\n", output)
+		fmt.Fprintf(os.Stderr, "Supported outputs: text, markdown, html, html-snippet, dot, railroad, roff, rst\n")
+		os.Exit(app.ExitUsage)
+	}
+	if *templateFlag != "" && output != "text" {
+		fmt.Fprintf(os.Stderr, "Error: -template can't be combined with -output\n")
+		os.Exit(app.ExitUsage)
+	}
+	textOutput := output == "text" && *templateFlag == ""
+	if *accessibleFlag && !textOutput {
+		fmt.Fprintf(os.Stderr, "Error: -accessible can't be combined with -output or -template\n")
+		os.Exit(app.ExitUsage)
+	}
+	if *outputFileFlag != "" && textOutput {
+		fmt.Fprintf(os.Stderr, "Error: -o requires a document output such as -output html or -template\n")
+		os.Exit(app.ExitUsage)
+	}
+
+	// Validate color mode
+	colorMode := strings.ToLower(*colorFlag)
+	if !utils.IsValidColorMode(colorMode) {
+		fmt.Fprintf(os.Stderr, "Error: Unsupported color mode '%s'\n", colorMode)
+		fmt.Fprintf(os.Stderr, "Supported color modes: always, never, auto\n")
+		os.Exit(app.ExitUsage)
+	}
+	app.SetColor(app.UseColor(colorMode) && !*accessibleFlag && app.EnableVirtualTerminal())
+
+	// Add the user's grok patterns to the standard library
+	for _, path := range grokPatternsFlag {
+		if err := loadGrokPatterns(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// An explicit -ascii=false overrides the locale
+	asciiSet := false
+	flag.Visit(func(f *flag.Flag) { asciiSet = asciiSet || f.Name == "ascii" })
+	if *asciiFlag || asciiSet {
+		app.SetASCII(*asciiFlag)
+	}
+	app.SetColorDepth(app.DetectColorDepth())
+
+	examples := app.ExampleOptions{
+		Bias:      strings.ToLower(*sampleBiasFlag),
+		MaxLength: *maxLengthFlag,
+		Realistic: *realisticFlag,
+		Seed:      *seedFlag,
+	}
+	if err := examples.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(app.ExitUsage)
+	}
+
+	// Named group examples and property tests are always random, seeded
+	// from the clock unless -seed is given
+	seed := *seedFlag
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	// Apply the color theme, from the flag or the config file
+	if err := applyTheme(cfg, *themeFlag); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		fmt.Fprintln(os.Stderr, "Run 'unregex -help' for usage information")
 		os.Exit(1)
 	}
 
-	// Run the regex explanation with the selected format
-	if err := app.Run([]string{pattern, format, fmt.Sprintf("%v", *visualizeFlag)}); err != nil {
+	if *watchFlag && (*fileFlag == "" || *streamFlag || *propTestFlag || *namedGroupsFlag || *copySampleFlag) {
+		fmt.Fprintf(os.Stderr, "Error: -watch needs -f and can't be combined with -stream, -proptest, -named-groups or -copy-sample\n")
+		os.Exit(app.ExitUsage)
+	}
+
+	// Get regex patterns from arguments or stdin, unless they are streamed.
+	// Fragments are joined into a single pattern.
+	var patterns, fragments []string
+	var sessions []session.Session
+	if *importFlag != "" {
+		if flag.NArg() > 0 || len(patternFlag) > 0 || len(fragmentFlag) > 0 || *fragmentsFileFlag != "" || *streamFlag || *fileFlag != "" || *clipboardFlag {
+			fmt.Fprintf(os.Stderr, "Error: -import can't be combined with pattern arguments, -pattern, -fragment, -fragments, -stream, -f or -clipboard\n")
+			os.Exit(app.ExitUsage)
+		}
+		sessions, err = readSessions(*importFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, s := range sessions {
+			patterns = append(patterns, s.Pattern)
+		}
+	} else if len(fragmentFlag) > 0 || *fragmentsFileFlag != "" {
+		if flag.NArg() > 0 || len(patternFlag) > 0 || *streamFlag || *fileFlag != "" || *clipboardFlag {
+			fmt.Fprintf(os.Stderr, "Error: -fragment and -fragments can't be combined with pattern arguments, -pattern, -stream, -f or -clipboard\n")
+			os.Exit(app.ExitUsage)
+		}
+		fragments = fragmentFlag
+		if *fragmentsFileFlag != "" {
+			fromFile, err := readFragmentsFile(*fragmentsFileFlag)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fragments = append(fragments, fromFile...)
+		}
+		patterns = []string{strings.Join(fragments, "")}
+	} else if *streamFlag {
+		if flag.NArg() > 0 || len(patternFlag) > 0 || *clipboardFlag || *copySampleFlag || *propTestFlag || *namedGroupsFlag || *outputFileFlag != "" {
+			fmt.Fprintf(os.Stderr, "Error: -stream reads patterns from stdin and can't be combined with pattern arguments, -pattern, -clipboard, -copy-sample, -proptest, -named-groups or -o\n")
+			os.Exit(app.ExitUsage)
+		}
+	} else if *fileFlag != "" {
+		if flag.NArg() > 0 || len(patternFlag) > 0 || *clipboardFlag {
+			fmt.Fprintf(os.Stderr, "Error: -f can't be combined with pattern arguments, -pattern or -clipboard\n")
+			os.Exit(app.ExitUsage)
+		}
+		pattern, err := readPatternFile(*fileFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		patterns = []string{pattern}
+	} else if *clipboardFlag {
+		if flag.NArg() > 0 || len(patternFlag) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: -clipboard can't be combined with pattern arguments or -pattern\n")
+			os.Exit(app.ExitUsage)
+		}
+		pattern, err := clipboard.Read()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			fmt.Fprintf(os.Stderr, "Error: the clipboard is empty\n")
+			os.Exit(1)
+		}
+		patterns = []string{pattern}
+	} else {
+		patterns, err = getRegexPatterns(patternFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			fmt.Fprintln(os.Stderr, "Run 'unregex -help' for usage information")
+			os.Exit(1)
+		}
+	}
+
+	// Replace @name with saved patterns, in their saved flavor unless
+	// -format was given on the command line
+	formatSet, flagsSet := false, false
+	flag.Visit(func(f *flag.Flag) {
+		formatSet = formatSet || f.Name == "format"
+		flagsSet = flagsSet || f.Name == "flags"
+	})
+	formats := make([]string, len(patterns))
+	patternFlags := make([]string, len(patterns))
+	for i := range formats {
+		formats[i], patternFlags[i] = format, *flagsFlag
+	}
+
+	// Imported regexes keep their flavor and flags unless -format or
+	// -flags was given on the command line
+	for i, s := range sessions {
+		if !formatSet {
+			formats[i] = s.Format
+		}
+		if !flagsSet {
+			patternFlags[i] = s.Flags
+		}
+	}
+	if err := resolveSavedPatterns(patterns, formats, formatSet); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if fragments != nil {
+		// Each fragment is its own string literal
+		fragmentFormats := make([]string, len(fragments))
+		for i := range fragmentFormats {
+			fragmentFormats[i] = formats[0]
+		}
+		if err := unquoteShell(fragments, *shellFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := unescapeJsStrings(fragments, fragmentFormats, *jsStringFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		patterns[0] = strings.Join(fragments, "")
+	} else if err := unquoteShell(patterns, *shellFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	} else if err := unescapeJsStrings(patterns, formats, *jsStringFlag); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	if len(patterns) > 1 && (*propTestFlag || *outputFileFlag != "" || *copySampleFlag) {
+		fmt.Fprintf(os.Stderr, "Error: -proptest, -o and -copy-sample take a single pattern\n")
+		os.Exit(app.ExitUsage)
+	}
+
+	// Emit a property-based test instead of an explanation
+	if *propTestFlag {
+		source, err := app.GeneratePropertyTest(patterns[0], formats[0], *packageFlag, seed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(app.ExitCode(err))
+		}
+		fmt.Fprintf(os.Stderr, "Seed: %d\n", seed)
+		fmt.Print(source)
+		return
+	}
+
+	// Document the named groups instead of explaining the whole pattern
+	if *namedGroupsFlag {
+		failed := false
+		for i, pattern := range patterns {
+			if len(patterns) > 1 {
+				fmt.Print(patternSeparator("markdown", i, len(patterns), pattern))
+			}
+			table, err := app.NamedGroupsMarkdown(pattern, formats[i], seed)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				failed = true
+				continue
+			}
+			fmt.Print(table)
+		}
+		fmt.Fprintf(os.Stderr, "Seed: %d\n", seed)
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// List the strings the pattern matches instead of explaining it
+	if enumerateFlag.on {
+		eachPattern(patterns, formats, patternFlags, func(pattern, flavor, flags string) error {
+			matches, total, err := app.Enumerate(pattern, flavor, flags, enumerateFlag.limit)
+			if err != nil {
+				return err
+			}
+			for _, match := range matches {
+				fmt.Printf("%q\n", match)
+			}
+			if len(matches) < total {
+				fmt.Fprintf(os.Stderr, "Listed the first %d of up to %d strings\n", len(matches), total)
+			}
+			return nil
+		})
+		return
+	}
+
+	// Count the matching strings of each length instead of explaining
+	if *countLengthsFlag > 0 {
+		eachPattern(patterns, formats, patternFlags, func(pattern, flavor, flags string) error {
+			counts, err := app.CountByLength(pattern, flavor, flags, *countLengthsFlag)
+			if err != nil {
+				return err
+			}
+			fmt.Print(app.RenderLengthCounts(counts))
+			return nil
+		})
+		return
+	}
+
+	// Print the shortest match instead of explaining the pattern
+	if *shortestFlag {
+		eachPattern(patterns, formats, patternFlags, func(pattern, flavor, flags string) error {
+			shortest, err := app.ShortestMatch(pattern, flavor, flags)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%q (length %d)\n", shortest, utf8.RuneCountInString(shortest))
+			return nil
+		})
+		return
+	}
+
+	// Compare the modes of each quantifier instead of explaining the pattern
+	if *quantifiersFlag {
+		eachPattern(patterns, formats, patternFlags, func(pattern, flavor, flags string) error {
+			exp := app.AnalyzeWithFlags(pattern, flavor, flags)
+			advice, input, err := app.AdviseQuantifiers(exp, *quantifierInputFlag)
+			if err != nil {
+				return err
+			}
+			fmt.Print(app.RenderQuantifierAdvice(exp, advice, input))
+			return nil
+		})
+		return
+	}
+
+	// Match under every combination of flags instead of explaining the pattern
+	if *flagMatrixFlag {
+		eachPattern(patterns, formats, patternFlags, func(pattern, flavor, flags string) error {
+			exp := app.AnalyzeWithFlags(pattern, flavor, flags)
+			matrix, err := app.BuildFlagMatrix(exp, *flagMatrixInputFlag)
+			if err != nil {
+				return err
+			}
+			fmt.Print(app.RenderFlagMatrix(exp, matrix))
+			return nil
+		})
+		return
+	}
+
+	// Decide on hyperlinks while stdout is still the terminal
+	hyperlinks := *hyperlinksFlag && !*accessibleFlag && app.SupportsHyperlinks()
+
+	// Page long terminal output, like git does. Streamed and watched output
+	// is shown as it arrives, as is accessible output, which screen readers
+	// read as it's written.
+	stopPager := func() {}
+	if textOutput && !*streamFlag && !*watchFlag && !*noPagerFlag && !*accessibleFlag && app.StdoutIsTerminal() {
+		stopPager = startPager()
+	}
+
+	// The banner is only part of the terminal output
+	printBanner := func() {
+		if textOutput {
+			fmt.Printf("Unregex - Regex Visualizer v%s\n\n", utils.Version)
+		}
+	}
+	if !*watchFlag {
+		printBanner()
+	}
+
+	separatorOutput := output
+	if *accessibleFlag {
+		separatorOutput = "accessible"
+	} else if *templateFlag != "" {
+		separatorOutput = "template"
+	}
+
+	// Example matches are only random with -seed, which is reported like
+	// the seeds of the other random examples
+	if *seedFlag != 0 {
+		fmt.Fprintf(os.Stderr, "Seed: %d\n", *seedFlag)
+	}
+
+	// Run the regex explanation with the selected format for each pattern,
+	// carrying on past failures so every pattern gets reported. The exit
+	// status is that of the first failure.
+	exitCode := 0
+	explain := func(i, n int, pattern, format, flags string) {
+		if n != 1 {
+			fmt.Print(patternSeparator(separatorOutput, i, n, pattern))
+		}
+		_, err := app.Run(app.RunOptions{
+			Pattern:    pattern,
+			Fragments:  fragments,
+			Flavor:     format,
+			Flags:      flags,
+			Visualize:  *visualizeFlag,
+			Examples:   examples,
+			Hyperlinks: hyperlinks,
+			Accessible: *accessibleFlag,
+			Output:     output,
+			OutputFile: *outputFileFlag,
+			Template:   *templateFlag,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			if exitCode == 0 {
+				exitCode = app.ExitCode(err)
+			}
+			return
+		}
+		if !*noHistoryFlag && !*streamFlag && !*watchFlag {
+			recordHistory(pattern, format)
+		}
+	}
+
+	if *watchFlag {
+		// Re-explain on every save until interrupted, starting from a clear
+		// screen so the explanation always fits the latest pattern
+		clearScreen := textOutput && app.StdoutIsTerminal()
+		watchFile(*fileFlag, func() {
+			if clearScreen {
+				fmt.Print("\033[H\033[2J")
+			}
+			printBanner()
+			pattern, err := readPatternFile(*fileFlag)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			watched, watchedFormats := []string{pattern}, []string{format}
+			if err := resolveSavedPatterns(watched, watchedFormats, formatSet); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			if err := unquoteShell(watched, *shellFlag); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			if err := unescapeJsStrings(watched, watchedFormats, *jsStringFlag); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			explain(0, 1, watched[0], watchedFormats[0], *flagsFlag)
+			fmt.Fprintf(os.Stderr, "\nWatching %s for changes (Ctrl+C to stop)\n", *fileFlag)
+		})
+	}
+
+	if *streamFlag {
+		// Explain each line as soon as it's read
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		i := 0
+		for scanner.Scan() {
+			pattern := strings.TrimSpace(scanner.Text())
+			if pattern == "" {
+				continue
+			}
+			streamed := []string{pattern}
+			if err := unquoteShell(streamed, *shellFlag); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				continue
+			}
+			if err := unescapeJsStrings(streamed, []string{format}, *jsStringFlag); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				continue
+			}
+			explain(i, 0, streamed[0], format, *flagsFlag)
+			i++
+		}
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read from stdin: %v\n", err)
+			if exitCode == 0 {
+				exitCode = app.ExitFailure
+			}
+		}
+	} else {
+		for i, pattern := range patterns {
+			explain(i, len(patterns), pattern, formats[i], patternFlags[i])
+		}
+	}
+	stopPager()
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+
+	// Copy the example match the explanation showed, generated with the
+	// same flags and example options
+	if *copySampleFlag {
+		flags, err := app.ResolveFlags(formats[0], patternFlags[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(app.ExitCode(err))
+		}
+		sample := app.AnalyzeWithOptions(patterns[0], formats[0], flags, examples).Sample
+		if sample == "" {
+			fmt.Fprintf(os.Stderr, "Error: no example match to copy\n")
+			os.Exit(1)
+		}
+		if err := clipboard.Write(sample); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Copied example match to the clipboard: %s\n", sample)
+	}
+}
+
+// unquoteShell replaces patterns pasted from a command line with the
+// arguments the shell would have passed, when a shell is given
+func unquoteShell(patterns []string, shell string) error {
+	if shell == "" {
+		return nil
+	}
+	for i, pattern := range patterns {
+		unquoted, err := format.UnquoteShell(pattern, shell)
+		if err != nil {
+			return fmt.Errorf("can't unquote %s: %w", pattern, err)
+		}
+		patterns[i] = unquoted
+	}
+	return nil
+}
+
+// unescapeJsStrings replaces js patterns given as JavaScript source, such
+// as new RegExp("\\d+", "g"), with the patterns they build. RegExp
+// constructor calls are always recognized, while bare string literals are
+// only unescaped when force is set, since "a" is also a valid pattern.
+func unescapeJsStrings(patterns, formats []string, force bool) error {
+	for i, pattern := range patterns {
+		if formats[i] != "js" || (!force && !format.IsRegExpConstructor(pattern)) {
+			continue
+		}
+		unescaped, err := format.UnescapeJsString(pattern)
+		if err != nil {
+			return fmt.Errorf("can't unescape %s: %w", pattern, err)
+		}
+		patterns[i] = unescaped
+	}
+	return nil
+}
+
+// resolveSavedPatterns replaces patterns of the form @name with the pattern
+// saved under that name and, unless keepFormat is set, its format with the
+// saved flavor. A pattern like @name that isn't saved is left as it is,
+// since it may be a literal pattern, with a warning in case of a typo.
+func resolveSavedPatterns(patterns, formats []string, keepFormat bool) error {
+	var savedPatterns map[string]saved.Pattern
+	for i, pattern := range patterns {
+		name := strings.TrimPrefix(pattern, "@")
+		if name == pattern || !saved.ValidName(name) {
+			continue
+		}
+
+		if savedPatterns == nil {
+			path, err := saved.Path()
+			if err != nil {
+				return err
+			}
+			if savedPatterns, err = saved.Load(path); err != nil {
+				return err
+			}
+		}
+
+		entry, ok := savedPatterns[name]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Warning: no saved pattern named '%s', explaining %s literally\n", name, pattern)
+			continue
+		}
+		patterns[i] = entry.Pattern
+		if !keepFormat && entry.Format != "" {
+			formats[i] = entry.Format
+		}
+	}
+	return nil
+}
+
+// recordHistory adds an explained pattern to the history. Failing to record
+// it only warns, since the explanation itself succeeded.
+func recordHistory(pattern, format string) {
+	path, err := history.Path()
+	if err == nil {
+		_, err = history.Add(path, pattern, format, time.Now())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// eachPattern reports on each pattern in place of the explanation, with its
+// flavor and resolved flags, separating the reports when there are several.
+// It carries on past failures so every pattern gets reported, and exits with
+// the status of the first failure, as the explanation does.
+func eachPattern(patterns, formats, patternFlags []string, report func(pattern, flavor, flags string) error) {
+	status := 0
+	for i, pattern := range patterns {
+		if len(patterns) > 1 {
+			fmt.Print(patternSeparator("text", i, len(patterns), pattern))
+		}
+		flags, err := app.ResolveFlags(formats[i], patternFlags[i])
+		if err == nil {
+			err = report(pattern, formats[i], flags)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			if status == 0 {
+				status = app.ExitCode(err)
+			}
+		}
+	}
+	if status != 0 {
+		os.Exit(status)
+	}
+}
+
+// patternSeparator introduces pattern i of n when several are explained in
+// one invocation, in a form that suits the output format. n is 0 when the
+// number of patterns isn't known in advance, as with -stream.
+func patternSeparator(output string, i, n int, pattern string) string {
+	switch output {
+	case "text":
+		line := app.Glyph("━", "=")
+		header := fmt.Sprintf("%s Pattern %d of %d: %s ", strings.Repeat(line, 3), i+1, n, pattern)
+		if n <= 0 {
+			header = fmt.Sprintf("%s Pattern %d: %s ", strings.Repeat(line, 3), i+1, pattern)
+		}
+		rule := strings.Repeat(line, max(0, 60-len([]rune(header))))
+		if i > 0 {
+			return "\n" + header + rule + "\n\n"
+		}
+		return header + rule + "\n\n"
+	case "accessible":
+		header := fmt.Sprintf("Pattern %d of %d: %s\n\n", i+1, n, pattern)
+		if n <= 0 {
+			header = fmt.Sprintf("Pattern %d: %s\n\n", i+1, pattern)
+		}
+		if i > 0 {
+			return "\n" + header
+		}
+		return header
+	case "markdown":
+		if i > 0 {
+			return "\n---\n\n"
+		}
+		return ""
+	default:
+		if i > 0 {
+			return "\n"
+		}
+		return ""
+	}
+}
+
+// applyTheme registers the themes defined in the config file and selects
+// the named theme, falling back to the config file's theme and then the default
+func applyTheme(cfg *config.Config, name string) error {
+	for themeName, theme := range cfg.Themes {
+		err := app.RegisterTheme(app.Theme{
+			Name:        themeName,
+			Tokens:      theme.Tokens,
+			Extended:    theme.Extended,
+			Supported:   theme.Supported,
+			Unsupported: theme.Unsupported,
+		})
+		if err != nil {
+			return fmt.Errorf("config file: %v", err)
+		}
+	}
+
+	if name == "" {
+		name = cfg.Theme
+	}
+	if name == "" {
+		name = "default"
+	}
+	return app.SetTheme(name)
+}
+
+// readSessions reads the regexes exported from a playground, from a file or
+// - for stdin
+func readSessions(path string) ([]session.Session, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session.Parse(data)
+}
+
+// readFragmentsFile reads pattern fragments from a file, one per line.
+// Lines are kept as they are, spaces included, apart from blank ones.
+func readFragmentsFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fragments []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.TrimSpace(line) != "" {
+			fragments = append(fragments, line)
+		}
+	}
+	if len(fragments) == 0 {
+		return nil, fmt.Errorf("%s contains no fragments", path)
+	}
+	return fragments, nil
+}
+
+// loadGrokPatterns adds the definitions in a grok patterns file to the
+// library the grok format expands references with
+func loadGrokPatterns(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := format.AddGrokPatterns(string(data)); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// patternList collects the values of the repeatable -pattern, -fragment and
+// -grok-patterns flags
+type patternList []string
+
+func (p *patternList) String() string {
+	return strings.Join(*p, ", ")
+}
+
+func (p *patternList) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+// enumerateLimit is the -enumerate flag, given alone to list every string
+// a pattern matches or as -enumerate=N to list the first N
+type enumerateLimit struct {
+	on    bool
+	limit int
+}
+
+func (e *enumerateLimit) String() string {
+	if e == nil || !e.on {
+		return ""
+	}
+	if e.limit == 0 {
+		return "true"
+	}
+	return strconv.Itoa(e.limit)
+}
+
+func (e *enumerateLimit) Set(value string) error {
+	switch value {
+	case "true":
+		*e = enumerateLimit{on: true}
+	case "false":
+		*e = enumerateLimit{}
+	default:
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("-enumerate takes a number of strings above 0, not %q", value)
+		}
+		*e = enumerateLimit{on: true, limit: limit}
+	}
+	return nil
+}
+
+// IsBoolFlag lets -enumerate be given without a limit
+func (e *enumerateLimit) IsBoolFlag() bool {
+	return true
+}
+
+// undefinedFlag returns the argument behind an unknown-flag parse error,
+// which is often a pattern that starts with '-'
+func undefinedFlag(err error) string {
+	const prefix = "flag provided but not defined: "
+	if msg := err.Error(); strings.HasPrefix(msg, prefix) {
+		return strings.TrimPrefix(msg, prefix)
+	}
+	return ""
+}
+
+// registerPlugins makes the flavors declared in the config file's plugins
+// section available as formats
+func registerPlugins(cfg *config.Config) error {
+	names := make([]string, 0, len(cfg.Plugins))
+	for name := range cfg.Plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p := cfg.Plugins[name]
+		if _, ok := format.Lookup(name); ok {
+			return fmt.Errorf("plugin %s can't replace a built-in format", name)
+		}
+		if p.Command == "" {
+			return fmt.Errorf("plugin %s has no command", name)
+		}
+		format.Register(name, plugin.New(name, p.Command, p.Args))
+	}
+	return nil
+}
+
+// supportedFormats lists the built-in formats followed by any plugins
+func supportedFormats() string {
+	return strings.Join(format.Names(), ", ")
 }
 
-// getRegexPattern retrieves the regex pattern from command line arguments or stdin
-func getRegexPattern() (string, error) {
-	// Check if pattern is provided as a command line argument (after flags)
-	if flag.NArg() > 0 {
-		return flag.Arg(0), nil
+// getRegexPatterns retrieves the regex patterns from -pattern flags, command
+// line arguments or stdin
+func getRegexPatterns(flagPatterns []string) ([]string, error) {
+	// Check if patterns are provided with -pattern or as command line
+	// arguments (after flags)
+	if len(flagPatterns) > 0 || flag.NArg() > 0 {
+		return append(append([]string{}, flagPatterns...), flag.Args()...), nil
 	}
 
 	// Check if data is being piped in through stdin
@@ -90,18 +1006,18 @@ func getRegexPattern() (string, error) {
 		reader := bufio.NewReader(os.Stdin)
 		input, err := io.ReadAll(reader)
 		if err != nil {
-			return "", fmt.Errorf("failed to read from stdin: %v", err)
+			return nil, fmt.Errorf("failed to read from stdin: %v", err)
 		}
 
 		// Trim whitespace and newlines
 		pattern := strings.TrimSpace(string(input))
 		if pattern == "" {
-			return "", fmt.Errorf("empty pattern received from stdin")
+			return nil, fmt.Errorf("empty pattern received from stdin")
 		}
 
-		return pattern, nil
+		return []string{pattern}, nil
 	}
 
 	// No pattern provided
-	return "", fmt.Errorf("no regex pattern provided")
+	return nil, fmt.Errorf("no regex pattern provided")
 }
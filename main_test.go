@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/weslien/unregex/internal/app"
+)
+
+// TestMain runs unregex itself when a test re-executes the test binary
+// with UNREGEX_RUN_MAIN set, so its exit status can be checked
+func TestMain(m *testing.M) {
+	if os.Getenv("UNREGEX_RUN_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// exitStatus runs unregex with arguments and returns its exit status
+func exitStatus(t *testing.T, args ...string) int {
+	t.Helper()
+	return exitStatusWithEnv(t, nil, args...)
+}
+
+// exitStatusWithEnv runs unregex with arguments and extra environment
+// variables, and returns its exit status
+func exitStatusWithEnv(t *testing.T, env []string, args ...string) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "UNREGEX_RUN_MAIN=1", "HOME="+t.TempDir(), "XDG_CONFIG_HOME="+t.TempDir())
+	cmd.Env = append(cmd.Env, env...)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exitErr):
+		return exitErr.ExitCode()
+	default:
+		t.Fatalf("running unregex %q: %v", args, err)
+		return -1
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"-color", "never", "a+"}, 0},
+		{[]string{"-output", "dot", "--", "("}, app.ExitSyntax},
+		{[]string{"-output", "bogus", "a+"}, app.ExitUsage},
+		{[]string{"-color", "sometimes", "a+"}, app.ExitUsage},
+		{[]string{"-template", "t.tmpl", "-output", "html", "a+"}, app.ExitUsage},
+
+		// With several patterns, the first failure sets the status
+		{[]string{"-format", "pcre", "-shortest", "--", "(", "(?<=a)b"}, app.ExitSyntax},
+		{[]string{"-format", "pcre", "-enumerate", "--", "a", "(?<=a)b", "("}, app.ExitUnsupported},
+		{[]string{"-format", "pcre", "-flag-matrix", "--", "(", "(?<=a)b"}, app.ExitSyntax},
+
+		// Subcommands exit with the status of their error
+		{[]string{"test", "(", "x"}, app.ExitSyntax},
+		{[]string{"test", "-format", "pcre", "(?<=a)b", "ab"}, app.ExitUnsupported},
+	}
+	for _, tt := range tests {
+		if got := exitStatus(t, tt.args...); got != tt.want {
+			t.Errorf("unregex %q exit status = %d, want %d", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestSubcommandDefaults(t *testing.T) {
+	// \h is a syntax error in Go and horizontal space in PCRE, so the
+	// status tells which flavor test used
+	args := []string{"test", `a\hb`, "a b"}
+	if got := exitStatusWithEnv(t, nil, args...); got != app.ExitSyntax {
+		t.Errorf("unregex %q exit status = %d, want %d", args, got, app.ExitSyntax)
+	}
+	if got := exitStatusWithEnv(t, []string{"UNREGEX_FORMAT=pcre"}, args...); got != 0 {
+		t.Errorf("UNREGEX_FORMAT=pcre unregex %q exit status = %d, want 0", args, got)
+	}
+
+	// The config file's defaults apply too, under the command line
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "unregex"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "unregex", "config.json"), []byte(`{"defaults":{"format":"pcre"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	env := []string{"XDG_CONFIG_HOME=" + dir}
+	if got := exitStatusWithEnv(t, env, args...); got != 0 {
+		t.Errorf("unregex %q with a pcre default in the config file exit status = %d, want 0", args, got)
+	}
+	if got := exitStatusWithEnv(t, env, "test", "-format", "go", `a\hb`, "a b"); got != app.ExitSyntax {
+		t.Errorf("unregex test -format go with a pcre default exit status = %d, want %d", got, app.ExitSyntax)
+	}
+}
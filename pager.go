@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/weslien/unregex/internal/app"
+)
+
+// startPager pipes stdout through $PAGER, or less, the way git does, and
+// returns a function that waits for the pager to exit. less is started with
+// -F so output that fits on one screen is printed directly. If the pager
+// can't be started, output goes to the terminal as usual.
+func startPager() func() {
+	command := "less"
+	if pager, ok := os.LookupEnv("PAGER"); ok {
+		command = pager
+	}
+	args := strings.Fields(command)
+	if len(args) == 0 || args[0] == "cat" {
+		return func() {}
+	}
+
+	// The pager owns the terminal, so measure it before stdout becomes a pipe
+	if os.Getenv("COLUMNS") == "" {
+		if width := app.TerminalWidth(); width > 0 {
+			os.Setenv("COLUMNS", strconv.Itoa(width))
+		}
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if _, ok := os.LookupEnv("LV"); !ok {
+		cmd.Env = append(cmd.Env, "LV=-c")
+	}
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return func() {}
+	}
+	cmd.Stdin = reader
+	if err := cmd.Start(); err != nil {
+		reader.Close()
+		writer.Close()
+		return func() {}
+	}
+	reader.Close()
+
+	stdout := os.Stdout
+	os.Stdout = writer
+	return func() {
+		writer.Close()
+		os.Stdout = stdout
+		cmd.Wait()
+	}
+}
@@ -0,0 +1,219 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/runenames"
+)
+
+// classShorthands describes the shorthand escapes allowed inside a set
+var classShorthands = map[byte]string{
+	'd': "any digit",
+	'D': "any non-digit",
+	'w': "any word character",
+	'W': "any non-word character",
+	's': "any whitespace character",
+	'S': "any non-whitespace character",
+}
+
+// classControls describes the control character escapes allowed inside a
+// set. \b is a backspace inside a set rather than a word boundary.
+var classControls = map[byte]struct {
+	char        rune
+	description string
+}{
+	'n': {'\n', "a newline"},
+	'r': {'\r', "a carriage return"},
+	't': {'\t', "a tab"},
+	'f': {'\f', "a form feed"},
+	'v': {'\v', "a vertical tab"},
+	'a': {'\a', "a bell"},
+	'e': {0x1b, "an escape"},
+	'b': {'\b', "a backspace"},
+}
+
+// classItem is one piece of a set: a single character, a shorthand escape
+// or a POSIX class
+type classItem struct {
+	text        string // the item as written
+	char        rune   // the character, for single characters
+	single      bool   // whether the item is a single character that can bound a range
+	escaped     bool   // whether the character was escaped
+	description string
+}
+
+// quoteClassChar quotes a character of a set, spelling invisible ones as
+// code points
+func quoteClassChar(r rune) string {
+	if unicode.IsGraphic(r) {
+		return fmt.Sprintf("'%c'", r)
+	}
+	return fmt.Sprintf("U+%04X", r)
+}
+
+// readClassItem reads the item of a set starting at content[i], returning
+// it and where the next one starts
+func readClassItem(content string, i int, flavor string) (classItem, int) {
+	if content[i] == '[' && i+1 < len(content) && strings.IndexByte(":.=", content[i+1]) >= 0 {
+		delimiter := string(content[i+1]) + "]"
+		if end := strings.Index(content[i+2:], delimiter); end >= 0 {
+			next := i + 2 + end + 2
+			item := classItem{text: content[i:next], description: explainBracketClass(content[i:next], flavor)}
+			if content[i+1] == '.' && flavor == "posix" {
+				// A collating symbol of one character can bound a range
+				item.char, item.single = collatingCharacter(content[i+2 : next-2])
+			}
+			return item, next
+		}
+	}
+
+	// Backslashes are ordinary characters inside a POSIX bracket expression
+	if content[i] == '\\' && i+1 < len(content) && flavor != "posix" {
+		if end := propertyEscapeEnd(content, i); end > 0 {
+			return classItem{text: content[i:end], description: explainClassProperty(content[i:end], flavor)}, end
+		}
+		for _, end := range []int{
+			codePointEscapeEnd(content, i, 'x', 2, true),
+			codePointEscapeEnd(content, i, 'u', 4, true),
+			codePointEscapeEnd(content, i, 'U', 8, false),
+			octalEscapeEnd(content, i),
+		} {
+			if end < 0 {
+				continue
+			}
+			if r, ok := DecodeEscape(content[i:end]); ok {
+				item := classItem{text: content[i:end], char: r, single: true, escaped: true}
+				item.description = fmt.Sprintf("%s (%s)", quoteClassChar(r), item.text)
+				return item, end
+			}
+		}
+
+		if description, ok := classShorthands[content[i+1]]; ok {
+			return classItem{text: content[i : i+2], description: fmt.Sprintf("%s (%s)", description, content[i:i+2])}, i + 2
+		}
+		if control, ok := classControls[content[i+1]]; ok {
+			item := classItem{text: content[i : i+2], char: control.char, single: true, escaped: true}
+			item.description = fmt.Sprintf("%s (%s)", control.description, item.text)
+			return item, i + 2
+		}
+
+		r, size := utf8.DecodeRuneInString(content[i+1:])
+		item := classItem{text: content[i : i+1+size], char: r, single: true, escaped: true}
+		item.description = fmt.Sprintf("%s (escaped)", quoteClassChar(r))
+		return item, i + 1 + size
+	}
+
+	r, size := utf8.DecodeRuneInString(content[i:])
+	item := classItem{text: content[i : i+size], char: r, single: true}
+	item.description = quoteClassChar(r)
+	if r >= utf8.RuneSelf {
+		if name := runenames.Name(r); name != "" {
+			item.description += fmt.Sprintf(" (%s)", name)
+		}
+	}
+	return item, i + size
+}
+
+// explainBracketClass explains a POSIX class, collating element or
+// equivalence class inside a set. Only POSIX reads all three, and Go and
+// PCRE read just the classes; elsewhere they are ordinary characters.
+func explainBracketClass(text, flavor string) string {
+	name := text[2 : len(text)-2]
+	switch {
+	case text[1] == ':' && (flavor == "go" || flavor == "pcre" || flavor == "posix"):
+		description := strings.TrimPrefix(explainPosixCharClass(name), "Matches ")
+		if strings.HasPrefix(description, "Unknown") {
+			return fmt.Sprintf("%s, an unknown POSIX class", text)
+		}
+		return fmt.Sprintf("%s, %s", text, description)
+	case text[1] == '.' && flavor == "posix":
+		return explainCollatingSymbol(name)
+	case text[1] == '=' && flavor == "posix":
+		return explainEquivalenceClass(name)
+	default:
+		return fmt.Sprintf("'%s' (not a POSIX class in this flavor, so each of its characters is matched)", text)
+	}
+}
+
+// explainClassProperty explains a \p or \P escape inside a set
+func explainClassProperty(sequence, flavor string) string {
+	explanation := explainUnicodeProperty(sequence, flavor)
+	explanation = strings.Replace(explanation, "Matches a character", "any character", 1)
+	return fmt.Sprintf("%s (%s)", explanation, sequence)
+}
+
+// explainClass explains a set such as [a-z0-9_-] element by element: its
+// ranges, shorthand escapes, POSIX classes and literal characters, and why
+// a -, ] or ^ in it is literal
+func explainClass(token, flavor string) string {
+	content := token[1 : len(token)-1]
+	negated := strings.HasPrefix(content, "^")
+	if negated {
+		content = content[1:]
+	}
+
+	if content == "" {
+		if negated {
+			return "Matches any character, including line breaks"
+		}
+		return "Matches nothing - an empty set never matches"
+	}
+
+	set := fmt.Sprintf("Matches any character in the set: %s", content)
+	if negated {
+		set = fmt.Sprintf("Matches any character NOT in the set: %s", content)
+	}
+	return fmt.Sprintf("%s — %s", set, strings.Join(classElements(content, negated, flavor), "; "))
+}
+
+// classElements describes each element of the contents of a set, after
+// any negating ^
+func classElements(content string, negated bool, flavor string) []string {
+	var items []classItem
+	for i := 0; i < len(content); {
+		item, next := readClassItem(content, i, flavor)
+		items = append(items, item)
+		i = next
+	}
+
+	var elements []string
+	afterRange := false
+	for j := 0; j < len(items); j++ {
+		item := items[j]
+		if j+2 < len(items) && item.single && items[j+1].text == "-" && items[j+2].single {
+			low, high := item, items[j+2]
+			element := fmt.Sprintf("%s to %s", quoteClassChar(low.char), quoteClassChar(high.char))
+			if low.char > high.char {
+				element += " (invalid, as the range runs backwards)"
+			}
+			elements = append(elements, element)
+			j += 2
+			afterRange = true
+			continue
+		}
+
+		element := item.description
+		if !item.escaped {
+			switch {
+			case item.text == "-" && j == 0:
+				element += " (literal because it's first)"
+			case item.text == "-" && j == len(items)-1:
+				element += " (literal because it's last)"
+			case item.text == "-" && afterRange:
+				element += " (literal because it follows a range)"
+			case item.text == "]" && j == 0:
+				element += " (literal because it's first)"
+			case item.text == "^" && j == 0 && negated:
+				element += " (literal because only the first ^ negates)"
+			case item.text == "^" && j > 0:
+				element += " (literal because it isn't first)"
+			}
+		}
+		elements = append(elements, element)
+		afterRange = false
+	}
+	return elements
+}
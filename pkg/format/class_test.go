@@ -0,0 +1,57 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExplainClass(t *testing.T) {
+	tests := []struct {
+		token  string
+		flavor string
+		want   string
+	}{
+		{"[a-z0-9_-]", "go", "Matches any character in the set: a-z0-9_- — 'a' to 'z'; '0' to '9'; '_'; '-' (literal because it's last)"},
+		{"[^-a]", "pcre", "Matches any character NOT in the set: -a — '-' (literal because it's first); 'a'"},
+		{"[a-c-e]", "go", "Matches any character in the set: a-c-e — 'a' to 'c'; '-' (literal because it follows a range); 'e'"},
+		{"[]a]", "go", "Matches any character in the set: ]a — ']' (literal because it's first); 'a'"},
+		{"[^^]", "python", "Matches any character NOT in the set: ^ — '^' (literal because only the first ^ negates)"},
+		{"[a^]", "go", "Matches any character in the set: a^ — 'a'; '^' (literal because it isn't first)"},
+		{`[\d\s.]`, "go", `Matches any character in the set: \d\s. — any digit (\d); any whitespace character (\s); '.'`},
+		{`[\x41-\x5A\-]`, "pcre", `Matches any character in the set: \x41-\x5A\- — 'A' to 'Z'; '-' (escaped)`},
+		{`[\t\n]`, "js", `Matches any character in the set: \t\n — a tab (\t); a newline (\n)`},
+		{"[z-a]", "go", "Matches any character in the set: z-a — 'z' to 'a' (invalid, as the range runs backwards)"},
+		{"[[:alpha:]_]", "go", "Matches any character in the set: [:alpha:]_ — [:alpha:], any alphabetic character (a-z, A-Z); '_'"},
+		{"[[:alpha:]_]", "js", "Matches any character in the set: [:alpha:]_ — '[:alpha:]' (not a POSIX class in this flavor, so each of its characters is matched); '_'"},
+		{"[[=e=]x]", "posix", "Matches any character in the set: [=e=]x — any character that sorts like 'e' in the locale's collation, such as 'e' with or without accents (just 'e' in the C locale); 'x'"},
+		{`[\n]`, "posix", `Matches any character in the set: \n — '\'; 'n'`},
+		{"[é]", "go", "Matches any character in the set: é — 'é' (LATIN SMALL LETTER E WITH ACUTE)"},
+		{"[]", "js", "Matches nothing - an empty set never matches"},
+		{"[^]", "js", "Matches any character, including line breaks"},
+	}
+
+	for _, tt := range tests {
+		if got := explainClass(tt.token, tt.flavor); got != tt.want {
+			t.Errorf("explainClass(%q, %q) = %q, want %q", tt.token, tt.flavor, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeRegex_LiteralClosingBracket(t *testing.T) {
+	tests := []struct {
+		format  RegexFormat
+		pattern string
+		want    []string
+	}{
+		{NewGoFormat(), "[]a]b", []string{"[]a]", "b"}},
+		{NewPcreFormat(), "[^]a]", []string{"[^]a]"}},
+		{NewJsFormat(), "[]a]", []string{"[]", "a]"}},
+		{NewJsFormat(), "[^]+", []string{"[^]", "+"}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.format.TokenizeRegex(tt.pattern); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s TokenizeRegex(%q) = %q, want %q", tt.format.Name(), tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// stringProperties describes the Unicode properties of strings JavaScript
+// accepts with the v flag, which match sequences of code points rather
+// than single characters
+var stringProperties = map[string]string{
+	"Basic_Emoji":                 "emoji that are a single code point, or one followed by VS16",
+	"Emoji_Keycap_Sequence":       "keycap sequences such as a digit, VS16 and COMBINING ENCLOSING KEYCAP",
+	"RGI_Emoji_Modifier_Sequence": "emoji with a skin tone modifier",
+	"RGI_Emoji_Flag_Sequence":     "flag emoji made of two regional indicators",
+	"RGI_Emoji_Tag_Sequence":      "subdivision flags made of tag sequences",
+	"RGI_Emoji_ZWJ_Sequence":      "emoji joined with ZERO WIDTH JOINER, such as family emoji",
+	"RGI_Emoji":                   "any recommended emoji, including multi-code point sequences",
+}
+
+// explainStringProperty explains a \p escape naming a property of strings,
+// or returns "" when it names another property
+func explainStringProperty(sequence string) string {
+	name := strings.Trim(sequence[2:], "{}")
+	description, ok := stringProperties[name]
+	if !ok {
+		return ""
+	}
+	if sequence[1] == 'P' {
+		return fmt.Sprintf("Invalid - the property of strings '%s' can't be negated", name)
+	}
+	return fmt.Sprintf("Matches %s (the property of strings '%s', which needs the v flag)", description, name)
+}
+
+// findClosingSetBracket finds the bracket closing the set starting at
+// pattern[start] in the v flag's syntax, where sets can nest and a ] is
+// only literal when escaped
+func findClosingSetBracket(pattern string, start int) int {
+	depth := 0
+	for i := start; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isClassSetExpression reports whether a set uses the v flag's syntax:
+// nested sets, -- subtraction or && intersection. A [ with no matching ]
+// is a literal one, as it would be without the v flag.
+func isClassSetExpression(token string) bool {
+	if findClosingSetBracket(token, 0) != len(token)-1 {
+		return false
+	}
+	content := token[1 : len(token)-1]
+	for i := 0; i < len(content); i++ {
+		switch {
+		case stringOperandEnd(content, i) > 0:
+			return true
+		case content[i] == '\\':
+			i++
+		case content[i] == '[' && !strings.HasPrefix(content[i:], "[:"):
+			return true
+		case strings.HasPrefix(content[i:], "--"), strings.HasPrefix(content[i:], "&&"):
+			return true
+		}
+	}
+	return false
+}
+
+// stringOperandEnd returns the end of the \q{...} strings or \p{...}
+// property of strings starting at content[i], or -1 if there isn't one
+func stringOperandEnd(content string, i int) int {
+	if !strings.HasPrefix(content[i:], `\q{`) && !strings.HasPrefix(content[i:], `\p{`) {
+		return -1
+	}
+	end := strings.IndexByte(content[i:], '}')
+	if end < 0 {
+		return -1
+	}
+	if content[i+1] == 'p' && stringProperties[content[i+3:i+end]] == "" {
+		return -1
+	}
+	return i + end + 1
+}
+
+// splitClassSet splits the contents of a v flag set into its operands: the
+// nested sets, strings, properties of strings and runs of other elements of
+// a union, or the sides of a -- or && operation, which is returned too
+func splitClassSet(content string) ([]string, string) {
+	var operands []string
+	operator := ""
+	start := 0
+	for i := 0; i < len(content); i++ {
+		switch {
+		case stringOperandEnd(content, i) > 0:
+			end := stringOperandEnd(content, i)
+			if i > start {
+				operands = append(operands, content[start:i])
+			}
+			operands = append(operands, content[i:end])
+			i = end - 1
+			start = end
+		case content[i] == '\\':
+			i++
+		case content[i] == '[':
+			end := findClosingSetBracket(content, i)
+			if end < 0 {
+				continue
+			}
+			if i > start {
+				operands = append(operands, content[start:i])
+			}
+			operands = append(operands, content[i:end+1])
+			i = end
+			start = i + 1
+		case strings.HasPrefix(content[i:], "--"), strings.HasPrefix(content[i:], "&&"):
+			if i > start {
+				operands = append(operands, content[start:i])
+			}
+			operator = content[i : i+2]
+			i++
+			start = i + 1
+		}
+	}
+	if start < len(content) {
+		operands = append(operands, content[start:])
+	}
+	return operands, operator
+}
+
+// describeSetOperand describes one operand of a v flag set
+func describeSetOperand(operand string) string {
+	switch {
+	case strings.HasPrefix(operand, `\q{`):
+		strs := strings.Split(strings.TrimSuffix(operand[3:], "}"), "|")
+		if len(strs) == 1 {
+			return fmt.Sprintf("%s (the string '%s')", operand, strs[0])
+		}
+		return fmt.Sprintf("%s (the strings '%s')", operand, strings.Join(strs, "', '"))
+	case strings.HasPrefix(operand, "[") && strings.HasSuffix(operand, "]"):
+		content := operand[1 : len(operand)-1]
+		negated := strings.HasPrefix(content, "^")
+		if negated {
+			content = content[1:]
+		}
+		description := describeClassSet(content)
+		if negated {
+			description = "anything but " + description
+		}
+		return fmt.Sprintf("%s (%s)", operand, description)
+	case strings.HasPrefix(operand, `\p{`) && stringOperandEnd(operand, 0) == len(operand):
+		return fmt.Sprintf("%s (%s)", operand, stringProperties[operand[3:len(operand)-1]])
+	}
+	return strings.Join(classElements(operand, false, "js"), "; ")
+}
+
+// describeClassSet describes the contents of a v flag set
+func describeClassSet(content string) string {
+	operands, operator := splitClassSet(content)
+	var described []string
+	for _, operand := range operands {
+		described = append(described, describeSetOperand(operand))
+	}
+
+	switch operator {
+	case "--":
+		return fmt.Sprintf("%s except %s", described[0], strings.Join(described[1:], " or "))
+	case "&&":
+		return strings.Join(described, " and also ")
+	}
+	return strings.Join(described, "; ")
+}
+
+// explainClassSet explains a set in the v flag's syntax, with nested sets,
+// strings, and subtraction or intersection of sets
+func explainClassSet(token string) string {
+	content := token[1 : len(token)-1]
+	negated := strings.HasPrefix(content, "^")
+	if negated {
+		content = content[1:]
+	}
+
+	operands, operator := splitClassSet(content)
+	var described []string
+	for _, operand := range operands {
+		described = append(described, describeSetOperand(operand))
+	}
+	explanation := "Matches any character in the set: " + content
+	if negated {
+		explanation = "Matches any character NOT in the set: " + content
+	}
+	switch operator {
+	case "--":
+		return fmt.Sprintf("%s — %s, minus %s (set subtraction, which needs the v flag)",
+			explanation, described[0], strings.Join(described[1:], ", minus "))
+	case "&&":
+		return fmt.Sprintf("%s — in all of %s (set intersection, which needs the v flag)",
+			explanation, strings.Join(described, ", and "))
+	}
+	return fmt.Sprintf("%s — %s (nested sets need the v flag)", explanation, strings.Join(described, "; "))
+}
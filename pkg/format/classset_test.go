@@ -0,0 +1,71 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExplainClassSet(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"[[a-z]--[aeiou]]", "Matches any character in the set: [a-z]--[aeiou] — [a-z] ('a' to 'z'), minus [aeiou] ('a'; 'e'; 'i'; 'o'; 'u') (set subtraction, which needs the v flag)"},
+		{`[\w&&[^\d]]`, `Matches any character in the set: \w&&[^\d] — in all of any word character (\w), and [^\d] (anything but any digit (\d)) (set intersection, which needs the v flag)`},
+		{"[[a-z][0-9]]", "Matches any character in the set: [a-z][0-9] — [a-z] ('a' to 'z'); [0-9] ('0' to '9') (nested sets need the v flag)"},
+		{`[\q{ab|c}x]`, `Matches any character in the set: \q{ab|c}x — \q{ab|c} (the strings 'ab', 'c'); 'x' (nested sets need the v flag)`},
+		{"[^[a-z]--[x]]", "Matches any character NOT in the set: [a-z]--[x] — [a-z] ('a' to 'z'), minus [x] ('x') (set subtraction, which needs the v flag)"},
+		{`[\p{RGI_Emoji}--\q{x}]`, `Matches any character in the set: \p{RGI_Emoji}--\q{x} — \p{RGI_Emoji} (any recommended emoji, including multi-code point sequences), minus \q{x} (the string 'x') (set subtraction, which needs the v flag)`},
+	}
+
+	for _, tt := range tests {
+		if !isClassSetExpression(tt.token) {
+			t.Errorf("isClassSetExpression(%q) = false, want true", tt.token)
+		}
+		if got := explainClassSet(tt.token); got != tt.want {
+			t.Errorf("explainClassSet(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+
+	for _, token := range []string{"[a-z]", "[[a]", "[[:alpha:]]", `[\[a]`} {
+		if isClassSetExpression(token) {
+			t.Errorf("isClassSetExpression(%q) = true, want false", token)
+		}
+	}
+}
+
+func TestExplainStringProperty(t *testing.T) {
+	tests := []struct {
+		sequence string
+		want     string
+	}{
+		{`\p{RGI_Emoji_Flag_Sequence}`, "Matches flag emoji made of two regional indicators (the property of strings 'RGI_Emoji_Flag_Sequence', which needs the v flag)"},
+		{`\P{RGI_Emoji}`, "Invalid - the property of strings 'RGI_Emoji' can't be negated"},
+		{`\p{L}`, ""},
+	}
+
+	for _, tt := range tests {
+		if got := explainStringProperty(tt.sequence); got != tt.want {
+			t.Errorf("explainStringProperty(%q) = %q, want %q", tt.sequence, got, tt.want)
+		}
+	}
+}
+
+func TestJsFormat_TokenizeRegex_UnicodeSets(t *testing.T) {
+	j := &JsFormat{}
+	tests := []struct {
+		pattern string
+		flags   string
+		want    []string
+	}{
+		{"/[[a-z]--[aeiou]]+/v", "", []string{"/v", "[[a-z]--[aeiou]]", "+"}},
+		{`[\]\q{a|b}[x]]`, "v", []string{`[\]\q{a|b}[x]]`}},
+		{"/[[a]]/u", "", []string{"/u", "[[a]", "]"}},
+	}
+
+	for _, tt := range tests {
+		if got := j.TokenizeRegexWithFlags(tt.pattern, tt.flags); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("TokenizeRegexWithFlags(%q, %q) = %q, want %q", tt.pattern, tt.flags, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// posixCollatingNames maps the symbolic names of the POSIX portable
+// character set, which a collating symbol such as [.hyphen.] can use in
+// place of the character itself, to their characters
+var posixCollatingNames = map[string]rune{
+	"NUL": 0, "tab": '\t', "newline": '\n', "vertical-tab": '\v', "form-feed": '\f',
+	"carriage-return": '\r', "space": ' ', "exclamation-mark": '!', "quotation-mark": '"',
+	"number-sign": '#', "dollar-sign": '$', "percent-sign": '%', "ampersand": '&',
+	"apostrophe": '\'', "left-parenthesis": '(', "right-parenthesis": ')', "asterisk": '*',
+	"plus-sign": '+', "comma": ',', "hyphen": '-', "hyphen-minus": '-', "period": '.',
+	"full-stop": '.', "slash": '/', "solidus": '/', "colon": ':', "semicolon": ';',
+	"less-than-sign": '<', "equals-sign": '=', "greater-than-sign": '>', "question-mark": '?',
+	"commercial-at": '@', "left-square-bracket": '[', "backslash": '\\', "reverse-solidus": '\\',
+	"right-square-bracket": ']', "circumflex": '^', "circumflex-accent": '^', "underscore": '_',
+	"low-line": '_', "grave-accent": '`', "left-brace": '{', "left-curly-bracket": '{',
+	"vertical-line": '|', "right-brace": '}', "right-curly-bracket": '}', "tilde": '~',
+}
+
+// CollationSupportNote warns how unevenly collating symbols and equivalence
+// classes are implemented
+const CollationSupportNote = "support varies: glibc follows the locale's collation, musl only accepts single characters and treats [=e=] as just 'e', and most other regex engines don't have them"
+
+// collatingCharacter returns the character a collating symbol names, given
+// as the character itself or by its name in the portable character set,
+// and false for multi-character collating elements such as ch
+func collatingCharacter(name string) (rune, bool) {
+	if r, ok := posixCollatingNames[name]; ok {
+		return r, true
+	}
+	if r, size := utf8.DecodeRuneInString(name); size == len(name) && r != utf8.RuneError {
+		return r, true
+	}
+	return 0, false
+}
+
+// explainCollatingSymbol explains the name inside a [.name.] collating
+// symbol
+func explainCollatingSymbol(name string) string {
+	r, ok := collatingCharacter(name)
+	switch {
+	case !ok:
+		return fmt.Sprintf("the multi-character collating element '%s', which only locales that define it, such as traditional Spanish for ch or ll, recognize", name)
+	case string(r) != name:
+		return fmt.Sprintf("%s (the collating symbol named %s)", quoteClassChar(r), name)
+	}
+	return fmt.Sprintf("%s (as a collating symbol)", quoteClassChar(r))
+}
+
+// explainEquivalenceClass explains the character inside a [=e=]
+// equivalence class
+func explainEquivalenceClass(name string) string {
+	return fmt.Sprintf("any character that sorts like '%s' in the locale's collation, such as '%s' with or without accents (just '%s' in the C locale)", name, name, name)
+}
+
+// explainCollationToken explains a bracket expression holding just one
+// collating symbol or equivalence class, such as [[.hyphen.]] or [[=e=]],
+// along with how unevenly they are supported
+func explainCollationToken(token string) string {
+	name := token[3 : len(token)-3]
+	description := explainEquivalenceClass(name)
+	if token[2] == '.' {
+		description = explainCollatingSymbol(name)
+	}
+	return fmt.Sprintf("Matches %s. Note that %s", description, CollationSupportNote)
+}
+
+// isCollationToken reports whether a token is a bracket expression holding
+// just one collating symbol or equivalence class
+func isCollationToken(token string) bool {
+	if len(token) < 7 || token[0] != '[' || token[1] != '[' || (token[2] != '.' && token[2] != '=') {
+		return false
+	}
+	return strings.Index(token[3:], string(token[2])+"]") == len(token)-6 && strings.HasSuffix(token, "]]")
+}
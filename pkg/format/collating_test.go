@@ -0,0 +1,42 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplainCollatingSymbol(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"a", "'a' (as a collating symbol)"},
+		{"hyphen", "'-' (the collating symbol named hyphen)"},
+		{"ch", "the multi-character collating element 'ch', which only locales that define it, such as traditional Spanish for ch or ll, recognize"},
+	}
+
+	for _, tt := range tests {
+		if got := explainCollatingSymbol(tt.name); got != tt.want {
+			t.Errorf("explainCollatingSymbol(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPosixCollationTokens(t *testing.T) {
+	posix := NewPosixFormat()
+	got := posix.ExplainToken("[[=e=]]")
+	if !strings.HasPrefix(got, "Matches any character that sorts like 'e'") || !strings.HasSuffix(got, CollationSupportNote) {
+		t.Errorf("ExplainToken(%q) = %q, want the equivalence class with the support note", "[[=e=]]", got)
+	}
+
+	want := "Matches any character in the set: [.a.]-[.z.] — 'a' to 'z'"
+	if got := posix.ExplainToken("[[.a.]-[.z.]]"); got != want {
+		t.Errorf("ExplainToken(%q) = %q, want %q", "[[.a.]-[.z.]]", got, want)
+	}
+
+	for _, token := range []string{"[[=e=]x]", "[[:alpha:]]", "[[.a.]-[.z.]]"} {
+		if isCollationToken(token) {
+			t.Errorf("isCollationToken(%q) = true, want false", token)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// conditionEnd returns the end of the condition opening the conditional
+// group starting at pattern[i], such as (?(1) or (?(?=a), or -1 if there
+// isn't one. A lookaround condition runs to the parenthesis closing it.
+func conditionEnd(pattern string, i int) int {
+	if !strings.HasPrefix(pattern[i:], "(?(") {
+		return -1
+	}
+	if strings.HasPrefix(pattern[i+3:], "?") {
+		if end := FindClosingParenthesis(pattern, i+2); end > 0 {
+			return end + 1
+		}
+		return -1
+	}
+	end := strings.IndexByte(pattern[i+3:], ')')
+	if end <= 0 {
+		return -1
+	}
+	return i + 3 + end + 1
+}
+
+// IsCondition reports whether a token opens a conditional group, such as
+// (?(1), (?(<name>) or (?(?=a). Like a group, the conditional it opens
+// ends at the matching ) token.
+func IsCondition(token string) bool {
+	return len(token) > 4 && conditionEnd(token, 0) == len(token)
+}
+
+// lookaroundConditions describes the lookarounds a conditional group can
+// test, by their opening syntax
+var lookaroundConditions = []struct {
+	prefix, description string
+}{
+	{"(?<=", "if %s matches just before this position"},
+	{"(?<!", "if %s doesn't match just before this position"},
+	{"(?=", "if %s matches at this position"},
+	{"(?!", "if %s doesn't match at this position"},
+}
+
+// describeCondition describes when a conditional group's condition holds
+func describeCondition(condition string) string {
+	for _, lookaround := range lookaroundConditions {
+		if strings.HasPrefix(condition, lookaround.prefix) {
+			inner := strings.TrimSuffix(condition[len(lookaround.prefix):], ")")
+			return fmt.Sprintf(lookaround.description, "'"+inner+"'")
+		}
+	}
+
+	name := strings.Trim(condition, "()")
+	switch {
+	case isDigits(name):
+		return fmt.Sprintf("if capturing group %s has matched", name)
+	case len(name) > 1 && (name[0] == '+' || name[0] == '-') && isDigits(name[1:]):
+		return fmt.Sprintf("if the capturing group %s relative to this one has matched", name)
+	case name == "R":
+		return "if the pattern is inside a recursion"
+	case strings.HasPrefix(name, "R&"):
+		return fmt.Sprintf("if the pattern is inside a recursion into the group named '%s'", name[2:])
+	case strings.HasPrefix(name, "R") && isDigits(name[1:]):
+		return fmt.Sprintf("if the pattern is inside a recursion into group %s", name[1:])
+	}
+	name = strings.Trim(name, "<>'")
+	return fmt.Sprintf("if the group named '%s' has matched", name)
+}
+
+// isDigits reports whether s is a non-empty string of decimal digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// explainCondition explains the condition opening a conditional group
+func explainCondition(token string) string {
+	condition := token[2:]
+	if condition == "(DEFINE)" {
+		return "Start of a DEFINE group - defines subpatterns to call elsewhere and never matches by itself"
+	}
+	return fmt.Sprintf("Start of a conditional group - matches the then-branch %s, and the else-branch after | otherwise",
+		describeCondition(condition))
+}
@@ -0,0 +1,45 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeRegex_Conditionals(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"(?(1)a|b)", []string{"(?(1)", "a", "|", "b", ")"}},
+		{"(?(<name>)c)", []string{"(?(<name>)", "c", ")"}},
+		{"(?(?=x(y))y|z)", []string{"(?(?=x(y))", "y", "|", "z", ")"}},
+		{"(?(?<!a)b)", []string{"(?(?<!a)", "b", ")"}},
+	}
+
+	for _, tt := range tests {
+		if got := NewPcreFormat().TokenizeRegex(tt.pattern); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("TokenizeRegex(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestExplainToken_Conditions(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"(?(1)", "Start of a conditional group - matches the then-branch if capturing group 1 has matched, and the else-branch after | otherwise"},
+		{"(?(<name>)", "Start of a conditional group - matches the then-branch if the group named 'name' has matched, and the else-branch after | otherwise"},
+		{"(?('name')", "Start of a conditional group - matches the then-branch if the group named 'name' has matched, and the else-branch after | otherwise"},
+		{"(?(-1)", "Start of a conditional group - matches the then-branch if the capturing group -1 relative to this one has matched, and the else-branch after | otherwise"},
+		{"(?(R2)", "Start of a conditional group - matches the then-branch if the pattern is inside a recursion into group 2, and the else-branch after | otherwise"},
+		{"(?(?!x)", "Start of a conditional group - matches the then-branch if 'x' doesn't match at this position, and the else-branch after | otherwise"},
+		{"(?(DEFINE)", "Start of a DEFINE group - defines subpatterns to call elsewhere and never matches by itself"},
+	}
+
+	for _, tt := range tests {
+		if got := NewPcreFormat().ExplainToken(tt.token); got != tt.want {
+			t.Errorf("ExplainToken(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+}
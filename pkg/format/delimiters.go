@@ -0,0 +1,139 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pcreDelimiters are the characters a bare PCRE pattern can be wrapped in,
+// as in PHP's preg functions, such as #...#u or ~...~i
+const pcreDelimiters = "/#~%!@,;"
+
+// pcreBracketDelimiters pairs the brackets a pattern can be wrapped in after
+// Perl's m or qr operator, such as m{...}, with their closing brackets
+var pcreBracketDelimiters = map[byte]byte{'{': '}', '(': ')', '[': ']', '<': '>'}
+
+// pcreEnvelopeModifiers describes the modifiers that can follow the closing
+// delimiter of a wrapped PCRE pattern, in Perl or PHP, beyond the inline
+// modifier letters
+var pcreEnvelopeModifiers = map[byte]string{
+	'u': "UTF-8 mode (PHP: the pattern and subject are UTF-8)",
+	'A': "anchored mode (the match must start where the search starts)",
+	'D': "dollar end-only mode ($ doesn't match before a final newline)",
+	'S': "extra analysis (PHP: has no effect since PHP 7.3)",
+	'X': "extra mode (PHP: unknown escapes are errors, always on since PHP 7.3)",
+	'g': "global matching (Perl: find every match)",
+	'c': "keep the position after a failed global match (Perl)",
+	'o': "compile once (Perl: variables are interpolated only once)",
+	'a': "ASCII-only \\d, \\s and \\w (Perl)",
+	'l': "locale rules (Perl)",
+	'p': "preserve the matched text in ${^MATCH} (Perl)",
+}
+
+// PcreDelimited splits a PCRE pattern wrapped in delimiters, like Perl's
+// qr/\d+/x and m{...}i or PHP's #...#u, into the pattern inside and the
+// closing delimiter followed by the modifiers. It reports false for a bare
+// pattern.
+func PcreDelimited(pattern string) (string, string, bool) {
+	start := 0
+	switch {
+	case strings.HasPrefix(pattern, "qr") && len(pattern) > 2 && isPerlDelimiter(pattern[2]):
+		start = 2
+	case strings.HasPrefix(pattern, "m") && len(pattern) > 1 && isPerlDelimiter(pattern[1]):
+		start = 1
+	case pattern == "" || strings.IndexByte(pcreDelimiters, pattern[0]) < 0:
+		return "", "", false
+	}
+
+	open := pattern[start]
+	closing := open
+	if bracket, ok := pcreBracketDelimiters[open]; ok {
+		closing = bracket
+	}
+
+	// The modifiers are the letters after the closing delimiter
+	end := len(pattern)
+	for end > start+1 && isASCIILetter(pattern[end-1]) {
+		end--
+	}
+	if end <= start+1 || pattern[end-1] != closing || isEscaped(pattern, end-1) {
+		return "", "", false
+	}
+	if _, ok := DelimiterModifiers(pattern[end-1:]); !ok && end < len(pattern) {
+		return "", "", false
+	}
+
+	// m{2,3}, m(a|b) and m[a-z] are the letter m followed by a quantifier,
+	// group or set unless modifiers follow
+	body := pattern[start+1 : end-1]
+	if start == 1 && ((open == '{' && isQuantifierBody(body)) || ((open == '(' || open == '[') && end == len(pattern))) {
+		return "", "", false
+	}
+	return body, pattern[end-1:], true
+}
+
+// isPerlDelimiter reports whether c can delimit the pattern of Perl's m or
+// qr operator. Perl accepts any punctuation, but only the common delimiters
+// and brackets are recognized, so m+ stays a quantified letter.
+func isPerlDelimiter(c byte) bool {
+	_, bracket := pcreBracketDelimiters[c]
+	return bracket || strings.IndexByte(pcreDelimiters, c) >= 0
+}
+
+// isASCIILetter reports whether c is an ASCII letter
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isEscaped reports whether pattern[i] is preceded by an odd number of
+// backslashes
+func isEscaped(pattern string, i int) bool {
+	backslashes := 0
+	for j := i - 1; j >= 0 && pattern[j] == '\\'; j-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+// isQuantifierBody reports whether s is the inside of a {n,m} quantifier
+func isQuantifierBody(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789,") == ""
+}
+
+// DelimiterModifiers returns the modifiers of the token PcreDelimited
+// splits off a wrapped pattern, such as i for }i, and reports false for
+// tokens that aren't a closing delimiter followed by known modifiers
+func DelimiterModifiers(token string) (string, bool) {
+	if len(token) < 2 || !strings.ContainsRune(pcreDelimiters+"})]>", rune(token[0])) {
+		return "", false
+	}
+	modifiers := token[1:]
+	for i := 0; i < len(modifiers); i++ {
+		if strings.IndexByte(pcreModifiers, modifiers[i]) < 0 && pcreEnvelopeModifiers[modifiers[i]] == "" {
+			return "", false
+		}
+	}
+	return modifiers, true
+}
+
+// isDelimiterModifiers reports whether a token is the closing delimiter and
+// modifiers of a wrapped pattern
+func isDelimiterModifiers(token string) bool {
+	_, ok := DelimiterModifiers(token)
+	return ok
+}
+
+// explainDelimiterModifiers explains the modifiers after the closing
+// delimiter of a wrapped PCRE pattern
+func explainDelimiterModifiers(token string) string {
+	modifiers, _ := DelimiterModifiers(token)
+	var explanations []string
+	for i := 0; i < len(modifiers); i++ {
+		description := pcreEnvelopeModifiers[modifiers[i]]
+		if strings.IndexByte(pcreModifiers, modifiers[i]) >= 0 {
+			description = modifierNames[modifiers[i]]
+		}
+		explanations = append(explanations, fmt.Sprintf("%c: %s", modifiers[i], description))
+	}
+	return "Modifiers: " + strings.Join(explanations, ", ")
+}
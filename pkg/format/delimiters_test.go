@@ -0,0 +1,63 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPcreDelimited(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		body      string
+		modifiers string
+		ok        bool
+	}{
+		{`qr/\d+/x`, `\d+`, "/x", true},
+		{"m{a.b}is", "a.b", "}is", true},
+		{`#^\w+$#u`, `^\w+$`, "#u", true},
+		{"~abc~", "abc", "~", true},
+		{`/a\/b/`, `a\/b`, "/", true},
+		{"m(a|b)i", "a|b", ")i", true},
+		{"m{2,3}", "", "", false},
+		{"m(a|b)", "", "", false},
+		{"m[abc]", "", "", false},
+		{"/usr/bin", "", "", false},
+		{`/a\/`, "", "", false},
+		{"abc", "", "", false},
+		{"#", "", "", false},
+	}
+
+	for _, tt := range tests {
+		body, modifiers, ok := PcreDelimited(tt.pattern)
+		if body != tt.body || modifiers != tt.modifiers || ok != tt.ok {
+			t.Errorf("PcreDelimited(%q) = %q, %q, %v, want %q, %q, %v", tt.pattern, body, modifiers, ok, tt.body, tt.modifiers, tt.ok)
+		}
+	}
+}
+
+func TestExplainDelimiterModifiers(t *testing.T) {
+	want := "Modifiers: i: case-insensitive matching, u: UTF-8 mode (PHP: the pattern and subject are UTF-8)"
+	if got := explainDelimiterModifiers("#iu"); got != want {
+		t.Errorf("explainDelimiterModifiers(%q) = %q, want %q", "#iu", got, want)
+	}
+}
+
+func TestPcreFormat_TokenizeRegex_Delimited(t *testing.T) {
+	p := &PcreFormat{}
+	tests := []struct {
+		pattern string
+		flags   string
+		want    []string
+	}{
+		{"qr/ (\\d+) # digits\n \\.? /x", "", []string{"/x", "(", `\d`, "+", ")", "# digits", `\.`, "?"}},
+		{"#a b#", "x", []string{"a", "b"}},
+		{"m{a.b}i", "", []string{"}i", "a", ".", "b"}},
+		{"a b", "", []string{"a b"}},
+	}
+
+	for _, tt := range tests {
+		if got := p.TokenizeRegexWithFlags(tt.pattern, tt.flags); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("TokenizeRegexWithFlags(%q, %q) = %q, want %q", tt.pattern, tt.flags, got, tt.want)
+		}
+	}
+}
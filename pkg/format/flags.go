@@ -0,0 +1,133 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// compileFlags are the flag letters each flavor accepts outside the
+// pattern, such as re.M in Python or the m of /.../m in JavaScript
+var compileFlags = map[string]string{
+	"go":     goModifiers,
+	"pcre":   pcreModifiers,
+	"python": pythonModifiers,
+	"js":     "dgimsuvy",
+	"posix":  "im",
+}
+
+// posixFlagNames describes the regcomp flags POSIX patterns accept, as
+// they differ from the inline modifiers of other flavors
+var posixFlagNames = map[rune]string{
+	'i': "case-insensitive matching (REG_ICASE)",
+	'm': "newline-sensitive mode (REG_NEWLINE: ^ and $ match at line breaks, and . doesn't match newlines)",
+}
+
+// FlagLetters returns the flag letters a built-in flavor accepts outside
+// the pattern, and false for flavors, such as plugins, it doesn't know
+func FlagLetters(name string) (string, bool) {
+	letters, ok := compileFlags[name]
+	return letters, ok
+}
+
+// DescribeFlags describes flags a pattern is compiled with outside it, one
+// letter at a time as the flavor reads it, such as
+// "m: multi-line mode (^ and $ match at line breaks)"
+func DescribeFlags(name, flags string) string {
+	var descriptions []string
+	for _, flag := range flags {
+		var description string
+		switch name {
+		case "js":
+			description = jsFlagNames[flag]
+		case "posix":
+			description = posixFlagNames[flag]
+		default:
+			if flag < 0x80 {
+				description = modifierNames[byte(flag)]
+			}
+		}
+		if description == "" {
+			description = "unknown flag"
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%c: %s", flag, description))
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// flagConstants maps the flag constants of the languages regexes are
+// written in to the flag letters they set, so flags can be given as they
+// appear in source code, such as re.IGNORECASE|re.MULTILINE. Constants
+// without a letter, like REG_EXTENDED, map to "".
+var flagConstants = map[string]string{
+	// Python's re module
+	"re.I": "i", "re.IGNORECASE": "i",
+	"re.M": "m", "re.MULTILINE": "m",
+	"re.S": "s", "re.DOTALL": "s",
+	"re.X": "x", "re.VERBOSE": "x",
+	"re.A": "a", "re.ASCII": "a",
+	"re.U": "u", "re.UNICODE": "u",
+	"re.L": "L", "re.LOCALE": "L",
+	// .NET's RegexOptions
+	"RegexOptions.None":                    "",
+	"RegexOptions.IgnoreCase":              "i",
+	"RegexOptions.Multiline":               "m",
+	"RegexOptions.Singleline":              "s",
+	"RegexOptions.IgnorePatternWhitespace": "x",
+	"RegexOptions.ExplicitCapture":         "n",
+	// Java's Pattern
+	"Pattern.CASE_INSENSITIVE": "i",
+	"Pattern.MULTILINE":        "m",
+	"Pattern.DOTALL":           "s",
+	"Pattern.COMMENTS":         "x",
+	// PCRE2 compile options
+	"PCRE2_CASELESS":        "i",
+	"PCRE2_MULTILINE":       "m",
+	"PCRE2_DOTALL":          "s",
+	"PCRE2_EXTENDED":        "x",
+	"PCRE2_NO_AUTO_CAPTURE": "n",
+	"PCRE2_DUPNAMES":        "J",
+	"PCRE2_UNGREEDY":        "U",
+	// POSIX regcomp flags
+	"REG_EXTENDED": "",
+	"REG_ICASE":    "i",
+	"REG_NEWLINE":  "m",
+}
+
+// lookupFlagConstant returns the letters a flag constant sets. Constants
+// are found with or without their qualifier, such as IgnoreCase for
+// RegexOptions.IgnoreCase, and with a longer one, such as
+// System.Text.RegularExpressions.RegexOptions.IgnoreCase. One-letter
+// constants like re.I need their qualifier, as I alone is a flag letter.
+func lookupFlagConstant(name string) (string, bool) {
+	for constant, letters := range flagConstants {
+		unqualified := constant[strings.LastIndexByte(constant, '.')+1:]
+		if name == constant || strings.HasSuffix(name, "."+constant) || (name == unqualified && len(name) > 1) {
+			return letters, true
+		}
+	}
+	return "", false
+}
+
+// ParseFlags turns flags given as letters, language-level constants or a
+// mix of both, separated by |, +, commas or spaces as in source code, into
+// flag letters. It returns the first part that is neither a known constant
+// nor letters as unknown.
+func ParseFlags(spec string) (letters, unknown string) {
+	parts := strings.FieldsFunc(spec, func(r rune) bool {
+		return r == '|' || r == '+' || r == ',' || r == ' ' || r == '\t'
+	})
+	var result strings.Builder
+	for _, part := range parts {
+		if constant, ok := lookupFlagConstant(part); ok {
+			part = constant
+		} else if strings.TrimFunc(part, func(r rune) bool { return r < 0x80 && isASCIILetter(byte(r)) }) != "" {
+			return "", part
+		}
+		for _, letter := range part {
+			if !strings.ContainsRune(result.String(), letter) {
+				result.WriteRune(letter)
+			}
+		}
+	}
+	return result.String(), ""
+}
@@ -0,0 +1,59 @@
+package format
+
+import "testing"
+
+func TestDescribeFlags(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags string
+		want  string
+	}{
+		{"go", "ms", "m: multi-line mode (^ and $ match at line breaks); s: dot-all mode (. matches newlines)"},
+		{"python", "a", "a: ASCII-only matching"},
+		{"js", "y", "y: Sticky mode - matches only from the index indicated by the lastIndex property"},
+		{"posix", "i", "i: case-insensitive matching (REG_ICASE)"},
+		{"go", "é", "é: unknown flag"},
+	}
+
+	for _, tt := range tests {
+		if got := DescribeFlags(tt.name, tt.flags); got != tt.want {
+			t.Errorf("DescribeFlags(%q, %q) = %q, want %q", tt.name, tt.flags, got, tt.want)
+		}
+	}
+}
+
+func TestFlagLetters(t *testing.T) {
+	if got, ok := FlagLetters("pcre"); !ok || got != "imsxnJU" {
+		t.Errorf("FlagLetters(%q) = %q, %v, want %q, true", "pcre", got, ok, "imsxnJU")
+	}
+	if _, ok := FlagLetters("cobol"); ok {
+		t.Errorf("FlagLetters(%q) reported true for an unknown flavor", "cobol")
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		spec    string
+		letters string
+		unknown string
+	}{
+		{"ms", "ms", ""},
+		{"re.IGNORECASE|re.MULTILINE", "im", ""},
+		{"re.I | re.X", "ix", ""},
+		{"RegexOptions.IgnoreCase | RegexOptions.Singleline", "is", ""},
+		{"System.Text.RegularExpressions.RegexOptions.Multiline", "m", ""},
+		{"Pattern.CASE_INSENSITIVE + Pattern.DOTALL", "is", ""},
+		{"IGNORECASE", "i", ""},
+		{"REG_EXTENDED|REG_ICASE", "i", ""},
+		{"i,re.I", "i", ""},
+		{"re.FOO", "", "re.FOO"},
+		{"i|Pattern.LITERAL", "", "Pattern.LITERAL"},
+	}
+
+	for _, tt := range tests {
+		letters, unknown := ParseFlags(tt.spec)
+		if letters != tt.letters || unknown != tt.unknown {
+			t.Errorf("ParseFlags(%q) = %q, %q, want %q, %q", tt.spec, letters, unknown, tt.letters, tt.unknown)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package format
+
+import (
+	"strings"
+	"sync"
+)
+
+// RegexFormat defines the interface for different regex format implementations
+type RegexFormat interface {
+	// Name returns the descriptive name of the format
+	Name() string
+	
+	// TokenizeRegex breaks a regex pattern into meaningful tokens
+	TokenizeRegex(pattern string) []string
+	
+	// ExplainToken provides a human-readable explanation for a regex token
+	ExplainToken(token string) string
+	
+	// HasFeature checks if this format supports a specific regex feature
+	HasFeature(feature string) bool
+}
+
+// FlagTokenizer is implemented by formats whose tokens depend on flags
+// given outside the pattern, such as x for Python's re.VERBOSE
+type FlagTokenizer interface {
+	// TokenizeRegexWithFlags tokenizes a pattern compiled with the flags
+	TokenizeRegexWithFlags(pattern, flags string) []string
+}
+
+// TokenizeWithFlags tokenizes a pattern compiled with flags given outside
+// it, which formats that don't implement FlagTokenizer ignore
+func TokenizeWithFlags(f RegexFormat, pattern, flags string) []string {
+	if tokenizer, ok := f.(FlagTokenizer); ok && flags != "" {
+		return tokenizer.TokenizeRegexWithFlags(pattern, flags)
+	}
+	return f.TokenizeRegex(pattern)
+}
+
+// Feature constants for different regex capabilities
+const (
+	FeatureLookahead      = "lookahead"
+	FeatureLookbehind     = "lookbehind"
+	FeatureNamedGroup     = "named_group"
+	FeatureAtomicGroup    = "atomic_group"
+	FeatureConditional    = "conditional"
+	FeaturePossessive     = "possessive"
+	FeatureUnicodeClass   = "unicode_class"
+	FeatureRecursion      = "recursion"
+	FeatureBackreference  = "backreference"
+	FeatureNamedBackref   = "named_backref"
+)
+
+// registry holds the formats by the name given to -format. The built-in
+// formats are listed first, in the order help shows them.
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]RegexFormat{
+		"go":     NewGoFormat(),
+		"pcre":   NewPcreFormat(),
+		"posix":  NewPosixFormat(),
+		"js":     NewJsFormat(),
+		"python": NewPythonFormat(),
+		"grok":   NewGrokFormat(),
+	}
+	registryNames = []string{"go", "pcre", "posix", "js", "python", "grok"}
+)
+
+// builtinFormats is how many of registryNames are built in
+const builtinFormats = 6
+
+// Register makes a format available by name to -format and everything else
+// that accepts a flavor. Formats are shared, so they must be safe to use
+// from several goroutines if the program does so. Register panics if the
+// name is empty or already taken, as built-in formats can't be replaced.
+func Register(name string, f RegexFormat) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	
+	if name == "" || f == nil {
+		panic("format: Register needs a name and a format")
+	}
+	if _, ok := registry[name]; ok {
+		panic("format: Register called twice for " + name)
+	}
+	registry[name] = f
+	registryNames = append(registryNames, name)
+}
+
+// Unregister removes a format added with Register, such as one a test
+// registered for its own use. It panics for the built-in formats, which
+// can't be removed.
+func Unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	
+	for i, registered := range registryNames {
+		if registered != name {
+			continue
+		}
+		if i < builtinFormats {
+			panic("format: Unregister called for the built-in format " + name)
+		}
+		delete(registry, name)
+		registryNames = append(registryNames[:i:i], registryNames[i+1:]...)
+		return
+	}
+}
+
+// Lookup returns the format registered by name
+func Lookup(name string) (RegexFormat, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	
+	f, ok := registry[name]
+	return f, ok
+}
+
+// Names returns the names of the registered formats, the built-in ones
+// first and then the others in the order they were registered
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	
+	return append([]string(nil), registryNames...)
+}
+
+// GetFormat returns the RegexFormat registered by name, or the Go format
+// for unknown names
+func GetFormat(formatName string) RegexFormat {
+	if f, ok := Lookup(formatName); ok {
+		return f
+	}
+	// Default to Go format
+	return NewGoFormat()
+}
+
+// findClosingBracket finds the closing bracket for a character class,
+// skipping POSIX classes such as [:alpha:] inside it
+func FindClosingBracket(pattern string, start int) int {
+	for i := start + 1; i < len(pattern); i++ {
+		if pattern[i] == '[' && i+1 < len(pattern) && strings.IndexByte(":.=", pattern[i+1]) >= 0 {
+			if end := strings.Index(pattern[i+2:], string(pattern[i+1])+"]"); end >= 0 {
+				i += 2 + end + 1
+				continue
+			}
+		}
+		if pattern[i] == ']' && pattern[i-1] != '\\' && !leadsSet(pattern, start, i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// leadsSet reports whether the ] at pattern[i] is the first character of
+// the set starting at pattern[start], after any ^, which makes it literal
+func leadsSet(pattern string, start, i int) bool {
+	return i == start+1 || (i == start+2 && pattern[start+1] == '^')
+}
+
+// findClosingCurlyBrace finds the closing curly brace for a quantifier
+func FindClosingCurlyBrace(pattern string, start int) int {
+	for i := start + 1; i < len(pattern); i++ {
+		if pattern[i] == '}' && pattern[i-1] != '\\' {
+			return i
+		}
+	}
+	return -1
+}
+
+// findClosingParenthesis finds the closing parenthesis for a group
+func FindClosingParenthesis(pattern string, start int) int {
+	depth := 1
+	for i := start + 1; i < len(pattern); i++ {
+		if pattern[i] == '\\' && i+1 < len(pattern) {
+			// Skip escaped characters
+			i++
+			continue
+		}
+		if pattern[i] == '(' {
+			depth++
+		} else if pattern[i] == ')' {
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+} 
\ No newline at end of file
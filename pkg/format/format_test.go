@@ -1,6 +1,7 @@
 package format
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -41,6 +42,59 @@ func TestGetFormat(t *testing.T) {
 	}
 }
 
+// TestRegister tests that registered formats are returned by Lookup and
+// GetFormat and listed after the built-in ones
+func TestRegister(t *testing.T) {
+	toy := &PythonFormat{}
+	Register("toy", toy)
+	t.Cleanup(func() { Unregister("toy") })
+	
+	if got, ok := Lookup("toy"); !ok || got != RegexFormat(toy) {
+		t.Errorf("Lookup(toy) = %v, %v, want the registered format", got, ok)
+	}
+	if _, ok := Lookup("unknown"); ok {
+		t.Error("Lookup(unknown) should fail")
+	}
+	if got := GetFormat("toy"); got != RegexFormat(toy) {
+		t.Errorf("GetFormat(toy) = %v, want the registered format", getFormatType(got))
+	}
+	if got, want := strings.Join(Names(), " "), "go pcre posix js python grok toy"; got != want {
+		t.Errorf("Names() = %q, want %q", got, want)
+	}
+	
+	for _, name := range []string{"toy", "go", ""} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%q) should panic", name)
+				}
+			}()
+			Register(name, toy)
+		}()
+	}
+}
+
+// TestUnregister tests that unregistered formats are gone and built-in
+// ones can't be removed
+func TestUnregister(t *testing.T) {
+	Register("toy", &PythonFormat{})
+	Unregister("toy")
+	
+	if _, ok := Lookup("toy"); ok {
+		t.Error("Lookup(toy) should fail once it's unregistered")
+	}
+	if got, want := strings.Join(Names(), " "), "go pcre posix js python grok"; got != want {
+		t.Errorf("Names() = %q, want %q", got, want)
+	}
+	
+	defer func() {
+		if recover() == nil {
+			t.Error("Unregister(go) should panic")
+		}
+	}()
+	Unregister("go")
+}
+
 // TestHelperFunctions tests the helper functions like FindClosingBracket
 func TestHelperFunctions(t *testing.T) {
 	// Test FindClosingBracket
@@ -54,8 +108,10 @@ func TestHelperFunctions(t *testing.T) {
 			{"[a-z]", 0, 4},
 			{"[^0-9]", 0, 5},
 			{"[\\]]", 0, 3},  // Escaped closing bracket
-			{"[]abc]", 0, 1},  // Closing bracket at beginning is literal
+			{"[]abc]", 0, 5},  // Closing bracket at beginning is literal
+			{"[^]abc]", 0, 6}, // ...and so is one right after the ^
 			{"[invalid", 0, -1}, // No closing bracket
+			{"[[:alpha:]_]", 0, 11}, // POSIX class inside the set
 		}
 
 		for _, tt := range tests {
@@ -39,22 +39,30 @@ func (g *GoFormat) HasFeature(feature string) bool {
 
 // TokenizeRegex breaks a regex pattern into meaningful tokens
 func (g *GoFormat) TokenizeRegex(pattern string) []string {
-	var tokens []string
-	var currentToken strings.Builder
-	
-	for i := 0; i < len(pattern); i++ {
+	return tokenizeSpans(g, pattern, "")
+}
+
+// AppendTokenSpans appends the spans of the tokens of a pattern to dst. Go
+// patterns don't depend on flags given outside them.
+func (g *GoFormat) AppendTokenSpans(dst []Span, pattern, flags string) []Span {
+	t := newSpanBuilder(dst)
+	g.tokenizeFrom(&t, pattern, flags, 0)
+	return t.done()
+}
+
+// tokenizeFrom adds the tokens of a pattern to t, skipping the part of its
+// body before from, which has to be where a token starts
+func (g *GoFormat) tokenizeFrom(t *spanBuilder, pattern, _ string, from int) {
+	for i := t.bodyFrom(from); i < len(pattern); i++ {
 		char := pattern[i]
 		
 		// Handle character classes
 		if char == '[' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
+			t.flush()
 			
 			end := FindClosingBracket(pattern, i)
 			if end > i {
-				tokens = append(tokens, pattern[i:end+1])
+				t.add(i, end+1)
 				i = end
 				continue
 			}
@@ -62,25 +70,43 @@ func (g *GoFormat) TokenizeRegex(pattern string) []string {
 		
 		// Handle special escape sequences
 		if char == '\\' && i+1 < len(pattern) {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
+			t.flush()
+			if end := quotedSpanEnd(pattern, i); end > i+2 {
+				// \Q...\E - text matched literally, special characters and all
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			if end := propertyEscapeEnd(pattern, i); end > 0 {
+				// \p{Name}, \P{Name} or \pL - a Unicode property class
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			if end := octalEscapeEnd(pattern, i); end > i+2 {
+				// \0oo or \ooo - a character by its octal code
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			if end := codePointEscapeEnd(pattern, i, 'x', 2, true); end > 0 {
+				// \xhh or \x{hhhh} - a character by its code point
+				t.add(i, end)
+				i = end - 1
+				continue
 			}
-			tokens = append(tokens, pattern[i:i+2])
+			t.add(i, i+2)
 			i++
 			continue
 		}
 		
 		// Handle curly brace quantifiers
 		if char == '{' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
+			t.flush()
 			
 			end := FindClosingCurlyBrace(pattern, i)
 			if end > i {
-				tokens = append(tokens, pattern[i:end+1])
+				t.add(i, end+1)
 				i = end
 				continue
 			}
@@ -88,111 +114,102 @@ func (g *GoFormat) TokenizeRegex(pattern string) []string {
 		
 		// Handle simple quantifiers
 		if char == '*' || char == '+' || char == '?' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle groups and named groups
 		if char == '(' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
+			t.flush()
+			
+			// (?i) or (?m-s:...) - inline modifiers, alone or scoped to a group
+			if end := inlineModifierEnd(pattern, i, goModifiers); end > 0 {
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			
+			// (?P=name) - a named backreference in Python's syntax
+			if end := pythonBackrefEnd(pattern, i); end > 0 {
+				t.add(i, end)
+				i = end - 1
+				continue
 			}
 			
 			// Check for non-capturing and other special groups
 			if i+2 < len(pattern) && pattern[i+1] == '?' {
 				switch pattern[i+2] {
 				case ':': // (?:pattern) - non-capturing group
-					tokens = append(tokens, "(?:")
+					t.add(i, i+3)
 					i += 2
 				case '=': // (?=pattern) - positive lookahead
-					tokens = append(tokens, "(?=")
+					t.add(i, i+3)
 					i += 2
 				case 'P': // (?P<name>pattern) - named capturing group
 					if i+3 < len(pattern) && pattern[i+3] == '<' {
 						endName := strings.IndexByte(pattern[i+4:], '>')
 						if endName >= 0 {
 							endName += i + 4
-							tokens = append(tokens, pattern[i:endName+1])
+							t.add(i, endName+1)
 							i = endName
 						} else {
-							tokens = append(tokens, string(char))
+							t.add(i, i+1)
 						}
 					} else {
-						tokens = append(tokens, string(char))
+						t.add(i, i+1)
 					}
 				default:
-					tokens = append(tokens, string(char))
+					t.add(i, i+1)
 				}
 				continue
 			} else {
-				tokens = append(tokens, string(char))
+				t.add(i, i+1)
 				continue
 			}
 		}
 		
 		if char == ')' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle alternation
 		if char == '|' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle anchors
 		if char == '^' || char == '$' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle dot
 		if char == '.' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Default case: add to current token
-		currentToken.WriteByte(char)
+		t.literal(i)
 	}
-	
-	// Add the last token if any
-	if currentToken.Len() > 0 {
-		tokens = append(tokens, currentToken.String())
-	}
-	
-	return tokens
 }
 
 // ExplainToken provides a human-readable explanation for a regex token
 func (g *GoFormat) ExplainToken(token string) string {
 	switch {
+	case strings.HasPrefix(token, "(?") && inlineModifierEnd(token, 0, goModifiers) == len(token):
+		return explainModifiers(token)
 	case token == "^":
-		return "Matches the start of a line"
+		return "Matches only at the start of the input"
 	case token == "$":
-		return "Matches the end of a line"
+		return "Matches only at the end of the input"
 	case token == ".":
 		return "Matches any single character except newline"
 	case token == "*":
@@ -214,11 +231,10 @@ func (g *GoFormat) ExplainToken(token string) string {
 	case strings.HasPrefix(token, "(?P<") && strings.HasSuffix(token, ">"):
 		name := token[4 : len(token)-1]
 		return fmt.Sprintf("Start of a named capturing group called '%s'", name)
+	case pythonBackrefEnd(token, 0) == len(token):
+		return fmt.Sprintf("Backreference to the named group '%s'", token[4:len(token)-1])
 	case strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]"):
-		if len(token) > 2 && token[1] == '^' {
-			return fmt.Sprintf("Matches any character NOT in the set: %s", token[2:len(token)-1])
-		}
-		return fmt.Sprintf("Matches any character in the set: %s", token[1:len(token)-1])
+		return explainClass(token, "go")
 	case strings.HasPrefix(token, "\\"):
 		return explainEscapeSequence(token)
 	case strings.HasPrefix(token, "{") && strings.HasSuffix(token, "}"):
@@ -234,10 +250,7 @@ func (g *GoFormat) ExplainToken(token string) string {
 		}
 		return fmt.Sprintf("Matches exactly %s occurrences of the preceding element", content)
 	default:
-		if len(token) == 1 {
-			return fmt.Sprintf("Matches the character '%s' literally", token)
-		}
-		return fmt.Sprintf("Matches the string '%s' literally", token)
+		return explainLiteral(token)
 	}
 }
 
@@ -246,6 +259,9 @@ func explainEscapeSequence(sequence string) string {
 	if len(sequence) < 2 {
 		return "Invalid escape sequence"
 	}
+	if len(sequence) > 2 && octalEscapeEnd(sequence, 0) == len(sequence) {
+		return explainOctalEscape(sequence)
+	}
 	
 	switch sequence[1] {
 	case 'd':
@@ -260,6 +276,13 @@ func explainEscapeSequence(sequence string) string {
 		return "Matches any whitespace character (space, tab, newline, etc.)"
 	case 'S':
 		return "Matches any non-whitespace character"
+	case 'Q':
+		if len(sequence) > 2 {
+			return explainQuotedSpan(sequence)
+		}
+		return "Start of a quoted sequence (everything until \\E is treated as a literal)"
+	case 'E':
+		return "End of a quoted sequence"
 	case 'b':
 		return "Matches a word boundary"
 	case 'B':
@@ -280,6 +303,13 @@ func explainEscapeSequence(sequence string) string {
 		return "Matches a vertical tab character"
 	case '0':
 		return "Matches a null character"
+	case 'p', 'P':
+		return explainUnicodeProperty(sequence, "go")
+	case 'x':
+		if codePointEscapeEnd(sequence, 0, 'x', 2, true) == len(sequence) {
+			return withCodePoint(fmt.Sprintf("Matches the character with hex code %s", escapeHex(sequence)), sequence)
+		}
+		return "Invalid hexadecimal escape sequence"
 	default:
 		return fmt.Sprintf("Matches the character '%c' literally", sequence[1])
 	}
@@ -82,6 +82,11 @@ func TestGoFormat_TokenizeRegex(t *testing.T) {
 			"(?P<name>abc)",
 			[]string{"(?P<name>", "abc", ")"},
 		},
+		{
+			"Named backreference - Python syntax",
+			"(?P<w>a)(?P=w)",
+			[]string{"(?P<w>", "a", ")", "(?P=w)"},
+		},
 		{
 			"Non-capturing group",
 			"(?:abc)",
@@ -125,8 +130,8 @@ func TestGoFormat_ExplainToken(t *testing.T) {
 		token string
 		want  string
 	}{
-		{"^", "Matches the start of a line"},
-		{"$", "Matches the end of a line"},
+		{"^", "Matches only at the start of the input"},
+		{"$", "Matches only at the end of the input"},
 		{".", "Matches any single character except newline"},
 		{"*", "Matches 0 or more of the preceding element"},
 		{"+", "Matches 1 or more of the preceding element"},
@@ -137,6 +142,7 @@ func TestGoFormat_ExplainToken(t *testing.T) {
 		{"(?:", "Start of a non-capturing group - groups the expression but doesn't create a capture group"},
 		{"(?=", "Start of a positive lookahead - matches if the pattern inside matches, but doesn't consume characters"},
 		{"(?P<name>", "Start of a named capturing group called 'name'"},
+		{"(?P=name)", "Backreference to the named group 'name'"},
 		{"[a-z]", "Matches any character in the set: a-z"},
 		{"[^0-9]", "Matches any character NOT in the set: 0-9"},
 		{"\\d", "Matches any digit (0-9)"},
@@ -0,0 +1,245 @@
+package format
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// grokBasePatterns is the standard grok pattern library, Logstash's
+// Apache-2.0 licensed one, as the NOTICE file says
+//
+//go:embed grok-patterns
+var grokBasePatterns string
+
+// grokReference matches a reference to a library pattern, such as %{WORD},
+// %{NUMBER:bytes} or %{NUMBER:bytes:int}, with the pattern's name, the
+// field it's captured as and the type the field is converted to
+var grokReference = regexp.MustCompile(`%\{(\w+)(?::([^:{}]+)(?::(\w+))?)?\}`)
+
+// grokDefinition matches a line of a grok patterns file
+var grokDefinition = regexp.MustCompile(`^(\w+)\s+(.+)$`)
+
+// grokPatterns is the grok pattern library, the standard patterns followed
+// by any added with AddGrokPatterns
+var (
+	grokMu       sync.RWMutex
+	grokOnce     sync.Once
+	grokPatterns map[string]string
+)
+
+// GrokFormat implements the RegexFormat interface for grok patterns, as
+// Logstash and Elasticsearch ingest pipelines use them. References to the
+// pattern library, such as %{WORD:user}, are tokens of their own, and the
+// regex around them is read as PCRE, which is close to the Oniguruma
+// syntax grok patterns are written in.
+type GrokFormat struct {
+	pcre PcreFormat
+}
+
+// NewGrokFormat creates a new grok format implementation
+func NewGrokFormat() RegexFormat {
+	return &GrokFormat{}
+}
+
+// Name returns the descriptive name of the format
+func (g *GrokFormat) Name() string {
+	return "Grok (Logstash)"
+}
+
+// HasFeature checks if this format supports a specific regex feature
+func (g *GrokFormat) HasFeature(feature string) bool {
+	return g.pcre.HasFeature(feature)
+}
+
+// TokenizeRegex breaks a grok pattern into meaningful tokens
+func (g *GrokFormat) TokenizeRegex(pattern string) []string {
+	return g.TokenizeRegexWithFlags(pattern, "")
+}
+
+// TokenizeRegexWithFlags tokenizes a grok pattern compiled with flags,
+// which apply to the regex between the library references
+func (g *GrokFormat) TokenizeRegexWithFlags(pattern, flags string) []string {
+	return tokenizeSpans(g, pattern, flags)
+}
+
+// AppendTokenSpans appends the spans of the tokens of a grok pattern to
+// dst: each library reference and the PCRE tokens of the regex between them
+func (g *GrokFormat) AppendTokenSpans(dst []Span, pattern, flags string) []Span {
+	last := 0
+	for _, loc := range grokReference.FindAllStringIndex(pattern, -1) {
+		dst = g.appendRegexSpans(dst, pattern, last, loc[0], flags)
+		dst = append(dst, Span{loc[0], loc[1]})
+		last = loc[1]
+	}
+	return g.appendRegexSpans(dst, pattern, last, len(pattern), flags)
+}
+
+// appendRegexSpans appends the spans of the PCRE tokens of the regex from
+// start to end of a grok pattern
+func (g *GrokFormat) appendRegexSpans(dst []Span, pattern string, start, end int, flags string) []Span {
+	if start == end {
+		return dst
+	}
+	n := len(dst)
+	dst = g.pcre.AppendTokenSpans(dst, pattern[start:end], flags)
+	for i := n; i < len(dst); i++ {
+		dst[i].Start += start
+		dst[i].End += start
+	}
+	return dst
+}
+
+// ExplainToken provides a human-readable explanation for a grok token
+func (g *GrokFormat) ExplainToken(token string) string {
+	m := grokReference.FindStringSubmatch(token)
+	if m == nil || m[0] != token {
+		return g.pcre.ExplainToken(token)
+	}
+	name, field, fieldType := m[1], m[2], m[3]
+
+	definition, ok := GrokPattern(name)
+	if !ok {
+		return fmt.Sprintf("Grok pattern %s, which isn't in the pattern library", name)
+	}
+	explanation := fmt.Sprintf("Grok pattern %s, defined as %s", name, definition)
+	if field != "" {
+		explanation += ", captured as the field " + field
+	}
+	switch fieldType {
+	case "":
+	case "int":
+		explanation += " and converted to an integer"
+	case "float":
+		explanation += " and converted to a floating-point number"
+	default:
+		explanation += " and kept as a " + fieldType
+	}
+	return explanation
+}
+
+// loadGrokPatterns loads the standard patterns into the library the first
+// time it's used
+func loadGrokPatterns() {
+	grokOnce.Do(func() {
+		patterns, err := ParseGrokPatterns(grokBasePatterns)
+		if err != nil {
+			panic("format: invalid standard grok patterns: " + err.Error())
+		}
+		grokPatterns = patterns
+	})
+}
+
+// GrokPattern returns the definition of a pattern in the grok library
+func GrokPattern(name string) (string, bool) {
+	loadGrokPatterns()
+	grokMu.RLock()
+	defer grokMu.RUnlock()
+
+	definition, ok := grokPatterns[name]
+	return definition, ok
+}
+
+// ParseGrokPatterns reads grok pattern definitions in the format of
+// Logstash's patterns files: a name and the pattern on each line, separated
+// by whitespace, with blank lines and # comments skipped
+func ParseGrokPatterns(data string) (map[string]string, error) {
+	patterns := make(map[string]string)
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m := grokDefinition.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: expected a pattern name followed by its pattern", i+1)
+		}
+		patterns[m[1]] = m[2]
+	}
+	return patterns, nil
+}
+
+// AddGrokPatterns adds the definitions in a grok patterns file to the
+// library, replacing standard patterns of the same name as Logstash does
+func AddGrokPatterns(data string) error {
+	patterns, err := ParseGrokPatterns(data)
+	if err != nil {
+		return err
+	}
+	loadGrokPatterns()
+	grokMu.Lock()
+	defer grokMu.Unlock()
+
+	for name, definition := range patterns {
+		grokPatterns[name] = definition
+	}
+	return nil
+}
+
+// ExpandGrok returns the regex a grok pattern stands for, replacing each
+// library reference with its definition, expanded in turn. A reference
+// captured as a field becomes a named group, and others a non-capturing
+// group.
+func ExpandGrok(pattern string) (string, error) {
+	loadGrokPatterns()
+	grokMu.RLock()
+	defer grokMu.RUnlock()
+
+	return expandGrok(pattern, nil)
+}
+
+// expandGrok expands the references in pattern, which is the definition of
+// the last of the patterns being expanded, if any
+func expandGrok(pattern string, expanding []string) (string, error) {
+	var err error
+	expanded := grokReference.ReplaceAllStringFunc(pattern, func(reference string) string {
+		if err != nil {
+			return ""
+		}
+		m := grokReference.FindStringSubmatch(reference)
+		name, field := m[1], m[2]
+
+		definition, ok := grokPatterns[name]
+		if !ok {
+			err = fmt.Errorf("unknown grok pattern %s", name)
+			return ""
+		}
+		for i, outer := range expanding {
+			if outer == name {
+				err = fmt.Errorf("grok pattern %s refers to itself through %s", name, strings.Join(expanding[i:], " -> ")+" -> "+name)
+				return ""
+			}
+		}
+
+		var inner string
+		if inner, err = expandGrok(definition, append(expanding, name)); err != nil {
+			return ""
+		}
+		if field == "" {
+			return "(?:" + inner + ")"
+		}
+		return "(?<" + grokGroupName(field) + ">" + inner + ")"
+	})
+	return expanded, err
+}
+
+// grokGroupName turns a grok field name into a group name, as fields can
+// be nested, such as [client][ip], or start with @, which names can't
+func grokGroupName(field string) string {
+	var name strings.Builder
+	for _, r := range field {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9':
+			name.WriteRune(r)
+		case name.Len() > 0 && !strings.HasSuffix(name.String(), "_"):
+			name.WriteByte('_')
+		}
+	}
+	group := strings.TrimSuffix(name.String(), "_")
+	if group == "" || group[0] >= '0' && group[0] <= '9' {
+		group = "_" + group
+	}
+	return group
+}
@@ -0,0 +1,90 @@
+package format
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGrokFormat_Tokenize(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`%{IP:client} \[%{HTTPDATE}\]`, []string{"%{IP:client}", " ", `\[`, "%{HTTPDATE}", `\]`}},
+		{`(?:%{INT:n:int}|-)+`, []string{"(?:", "%{INT:n:int}", "|", "-", ")", "+"}},
+		{`%{WORD}%{SPACE}`, []string{"%{WORD}", "%{SPACE}"}},
+		{`\d+`, []string{`\d`, "+"}},
+	}
+
+	for _, tt := range tests {
+		if got := NewGrokFormat().TokenizeRegex(tt.pattern); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("TokenizeRegex(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestGrokFormat_ExplainToken(t *testing.T) {
+	g := NewGrokFormat()
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"%{WORD}", `Grok pattern WORD, defined as \b\w+\b`},
+		{"%{NUMBER:bytes:int}", "Grok pattern NUMBER, defined as (?:%{BASE10NUM}), captured as the field bytes and converted to an integer"},
+		{"%{NOSUCHPATTERN:x}", "Grok pattern NOSUCHPATTERN, which isn't in the pattern library"},
+	}
+	for _, tt := range tests {
+		if got := g.ExplainToken(tt.token); got != tt.want {
+			t.Errorf("ExplainToken(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+	if got := g.ExplainToken(`\d`); got != NewPcreFormat().ExplainToken(`\d`) {
+		t.Errorf("ExplainToken(\\d) = %q, want the PCRE explanation", got)
+	}
+}
+
+func TestExpandGrok(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`%{WORD:verb} /`, `(?<verb>\b\w+\b) /`},
+		{`%{USER}`, `(?:(?:[a-zA-Z0-9._-]+))`},
+		{`%{WORD:[http][method]}`, `(?<http_method>\b\w+\b)`},
+		{`%{WORD:@metadata}`, `(?<metadata>\b\w+\b)`},
+		{`no references`, `no references`},
+	}
+	for _, tt := range tests {
+		got, err := ExpandGrok(tt.pattern)
+		if err != nil || got != tt.want {
+			t.Errorf("ExpandGrok(%q) = %q, %v, want %q", tt.pattern, got, err, tt.want)
+		}
+	}
+
+	// Every standard pattern refers only to patterns in the library
+	for name := range grokPatterns {
+		if expanded, err := ExpandGrok("%{" + name + "}"); err != nil || grokReference.MatchString(expanded) {
+			t.Errorf("ExpandGrok(%s) = %q, %v, want a full expansion", name, expanded, err)
+		}
+	}
+}
+
+func TestAddGrokPatterns(t *testing.T) {
+	err := AddGrokPatterns("# test patterns\n\nTESTID ID-%{TESTDIGITS}\r\nTESTDIGITS [0-9]{4}\nTESTLOOP %{TESTLOOP2}\nTESTLOOP2 a%{TESTLOOP}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ExpandGrok("%{TESTID:id}"); err != nil || got != "(?<id>ID-(?:[0-9]{4}))" {
+		t.Errorf("ExpandGrok(TESTID) = %q, %v", got, err)
+	}
+	if _, err := ExpandGrok("%{TESTLOOP}"); err == nil || !strings.Contains(err.Error(), "TESTLOOP -> TESTLOOP2 -> TESTLOOP") {
+		t.Errorf("ExpandGrok(TESTLOOP) error = %v, want the cycle", err)
+	}
+	if _, err := ExpandGrok("%{TESTMISSING}"); err == nil {
+		t.Error("ExpandGrok(TESTMISSING) should fail")
+	}
+	if err := AddGrokPatterns("TESTNOPATTERN\n"); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("AddGrokPatterns() error = %v, want one for line 1", err)
+	}
+}
@@ -0,0 +1,158 @@
+package format
+
+import "strings"
+
+// retokenizeMargin is how many characters past the end of a token a
+// tokenizer can look at to decide where the token ends, such as the < of
+// (?<=, so tokens ending at least this far before an edit are reused
+const retokenizeMargin = 4
+
+// retokenizeBrackets are the characters that open and close constructs
+// such as classes, groups and names. An edit that adds or removes one, or
+// makes one after it stop being escaped or literal, can complete or break a
+// construct anywhere before it, as sets and conditions can nest, so such
+// edits tokenize the whole pattern again.
+const retokenizeBrackets = "()[]{}<>'"
+
+// resumableTokenizer is implemented by the built-in formats, which can
+// resume tokenizing a pattern where a token of its body starts
+type resumableTokenizer interface {
+	tokenizeFrom(t *spanBuilder, pattern, flags string, from int)
+}
+
+// Retokenizer tokenizes a pattern as it's edited, such as in a live view
+// that explains a pattern on every keystroke. Each update reuses the tokens
+// before the edit and tokenizes only the rest of the pattern, so the work
+// is in proportion to how far from the end the edit is. Formats other than
+// the built-in ones are tokenized whole on every update.
+type Retokenizer struct {
+	format  RegexFormat
+	flags   string
+	pattern string
+	spans   []Span
+	scratch []Span
+	header  int
+	base    int
+	reused  int
+	started bool
+}
+
+// NewRetokenizer returns a Retokenizer for patterns of a format compiled
+// with flags given outside them
+func NewRetokenizer(f RegexFormat, flags string) *Retokenizer {
+	return &Retokenizer{format: f, flags: flags}
+}
+
+// Update tokenizes the edited pattern, returning where each of its tokens
+// is in it. The spans are only valid until the next update.
+func (r *Retokenizer) Update(pattern string) []Span {
+	resumable, ok := r.format.(resumableTokenizer)
+	if !ok {
+		r.pattern, r.spans, r.reused = pattern, TokenSpans(r.format, pattern, r.flags), 0
+		return r.spans
+	}
+	if !r.started || !r.retokenize(resumable, pattern) {
+		t := newSpanBuilder(r.spans[:0])
+		resumable.tokenizeFrom(&t, pattern, r.flags, 0)
+		r.spans, r.header, r.base, r.reused = t.done(), t.header, t.base, 0
+	}
+	r.pattern, r.started = pattern, true
+	return r.spans
+}
+
+// retokenize tokenizes an edited pattern from the end of the last token
+// the edit can't have changed, and reports false when the tokens before
+// the edit can't be reused
+func (r *Retokenizer) retokenize(resumable resumableTokenizer, pattern string) bool {
+	prefix := commonPrefix(r.pattern, pattern)
+	suffix := commonSuffix(r.pattern[prefix:], pattern[prefix:])
+
+	// Look for brackets in the text the edit removed and added, and just
+	// after it, past any backslashes whose escapes the edit may have shifted
+	end := len(pattern) - suffix
+	for end < len(pattern) && pattern[end] == '\\' {
+		end++
+	}
+	end = min(len(pattern), end+retokenizeMargin)
+	removed := r.pattern[prefix : len(r.pattern)-suffix]
+	if strings.ContainsAny(removed, retokenizeBrackets) || strings.ContainsAny(pattern[prefix:end], retokenizeBrackets) {
+		return false
+	}
+	// An edit right after a [ can make or break a POSIX [:class:], [.x.]
+	// or [=x=] inside a class that started before it
+	if prefix > 0 && pattern[prefix-1] == '[' {
+		return false
+	}
+
+	// Keep the body tokens ending far enough before the edit
+	keep := r.header
+	for keep < len(r.spans) && r.spans[keep].End+retokenizeMargin <= prefix {
+		keep++
+	}
+	from := 0
+	if keep > r.header {
+		from = r.spans[keep-1].End
+	}
+
+	t := newSpanBuilder(r.scratch[:0])
+	resumable.tokenizeFrom(&t, pattern, r.flags, from)
+	spans := t.done()
+
+	// The delimiters, prefix or leading flags have to be the same, as they
+	// decide how the body is tokenized
+	if t.header != r.header || t.base != r.base {
+		r.scratch = spans
+		return false
+	}
+	for i := 0; i < t.header; i++ {
+		if pattern[spans[i].Start:spans[i].End] != r.pattern[r.spans[i].Start:r.spans[i].End] {
+			r.scratch = spans
+			return false
+		}
+	}
+
+	// The header tokens come first, where they were, then the kept body
+	// tokens and the new ones
+	copy(r.spans, spans[:t.header])
+	r.spans = append(r.spans[:keep], spans[t.header:]...)
+	r.scratch = spans
+	r.reused = keep - r.header
+	return true
+}
+
+// Tokens returns the tokens of the pattern given to the last update
+func (r *Retokenizer) Tokens() []string {
+	tokens := make([]string, len(r.spans))
+	for i, span := range r.spans {
+		tokens[i] = r.pattern[span.Start:span.End]
+	}
+	return tokens
+}
+
+// Reused returns how many tokens the last update reused from the one
+// before it
+func (r *Retokenizer) Reused() int {
+	return r.reused
+}
+
+// commonPrefix returns the length of the longest prefix a and b share
+func commonPrefix(a, b string) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// commonSuffix returns the length of the longest suffix a and b share
+func commonSuffix(a, b string) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[len(a)-1-i] != b[len(b)-1-i] {
+			return i
+		}
+	}
+	return n
+}
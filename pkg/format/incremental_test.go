@@ -0,0 +1,108 @@
+package format
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRetokenizer(t *testing.T) {
+	r := NewRetokenizer(NewGoFormat(), "")
+	r.Update(`(\d+)-(\w+)`)
+	got := r.Update(`(\d+)-(\w+)x`)
+	if want := TokenSpans(NewGoFormat(), `(\d+)-(\w+)x`, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("Update() = %v, want %v", got, want)
+	}
+	if r.Reused() == 0 {
+		t.Errorf("Update() reused no tokens for an edit at the end")
+	}
+	if got, want := r.Tokens(), []string{"(", `\d`, "+", ")", "-", "(", `\w`, "+", ")", "x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokens() = %q, want %q", got, want)
+	}
+
+	// Closing a class that was literal text changes tokens before the edit
+	r.Update(`a[\d`)
+	r.Update(`a[\d]`)
+	if got, want := r.Tokens(), []string{"a", `[\d]`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokens() = %q, want %q", got, want)
+	}
+}
+
+// TestRetokenizer_RandomEdits checks that tokenizing a pattern as it's
+// edited gives the same tokens as tokenizing each version whole
+func TestRetokenizer_RandomEdits(t *testing.T) {
+	const alphabet = `ab\dwxEPQkgp<>=!:?*+{}[]()|^$.#,0123 '"/` + "\n"
+	starts := map[string][]string{
+		"go":     {`^(?P<user>[a-z0-9._%+-]+)@(?P<domain>[a-z0-9.-]+\.[a-z]{2,})$`, `\Qa.b\E\p{Greek}\x{41}`},
+		"pcre":   {`/(?<year>\d{4})-(?<month>\d{2})(?:T\d{2})?+/x`, `(*UCP)(?(1)a|b)(?R)\k'n'`, `a # note` + "\n" + `b`},
+		"posix":  {`^[[:alpha:]][[:alnum:]_]*=[[.hyphen.]]$`},
+		"js":     {`/^https?:\/\/(?:www\.)?[-a-z]{1,6}\b(?<=x)$/iu`, `/[\p{L}--[a-z]]/v`},
+		"python": {`r"(?x) (?P<key> \w+ ) \s* = (?P=key) # c"`, `(?i)a(?<!b)c`},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for name, patterns := range starts {
+		f := GetFormat(name)
+		for _, flags := range []string{"", "x", "v"} {
+			for _, pattern := range patterns {
+				r := NewRetokenizer(f, flags)
+				r.Update(pattern)
+				for step := 0; step < 5000; step++ {
+					before := pattern
+					pos := rng.Intn(len(pattern) + 1)
+					switch rng.Intn(3) {
+					case 0:
+						pattern = pattern[:pos] + string(alphabet[rng.Intn(len(alphabet))]) + pattern[pos:]
+					case 1:
+						if pos < len(pattern) {
+							pattern = pattern[:pos] + pattern[pos+1:]
+						}
+					default:
+						// Typing at the end is the most common edit
+						pattern += string(alphabet[rng.Intn(len(alphabet))])
+					}
+					if len(pattern) > 80 {
+						pattern = pattern[len(pattern)-80:]
+					}
+					got := append([]Span(nil), r.Update(pattern)...)
+					want := TokenSpans(f, pattern, flags)
+					if !reflect.DeepEqual(got, want) {
+						t.Fatalf("%s Update(%q) after %q with flags %q = %q, want %q", name, pattern, before, flags, spanTexts(pattern, got), spanTexts(pattern, want))
+					}
+				}
+			}
+		}
+	}
+}
+
+func spanTexts(pattern string, spans []Span) string {
+	var texts []string
+	for _, span := range spans {
+		texts = append(texts, pattern[span.Start:span.End])
+	}
+	return strings.Join(texts, " ")
+}
+
+// BenchmarkRetokenizer types a character at the end of a long pattern and
+// deletes it again, tokenizing both versions, incrementally or whole
+func BenchmarkRetokenizer(b *testing.B) {
+	long := strings.Repeat(`(?P<key>\w+)\s*=\s*"[^"]*"|`, 40) + `x`
+	f := NewPcreFormat()
+	b.Run("incremental", func(b *testing.B) {
+		b.ReportAllocs()
+		r := NewRetokenizer(f, "")
+		r.Update(long)
+		for i := 0; i < b.N; i++ {
+			r.Update(long + "y")
+			r.Update(long)
+		}
+	})
+	b.Run("whole", func(b *testing.B) {
+		b.ReportAllocs()
+		var spans []Span
+		for i := 0; i < b.N; i++ {
+			spans = f.(SpanTokenizer).AppendTokenSpans(spans[:0], long+"y", "")
+			spans = f.(SpanTokenizer).AppendTokenSpans(spans[:0], long, "")
+		}
+	})
+}
@@ -38,40 +38,63 @@ func (j *JsFormat) HasFeature(feature string) bool {
 
 // TokenizeRegex breaks a regex pattern into meaningful tokens
 func (j *JsFormat) TokenizeRegex(pattern string) []string {
-	var tokens []string
-	var currentToken strings.Builder
-	
+	return j.TokenizeRegexWithFlags(pattern, "")
+}
+
+// TokenizeRegexWithFlags tokenizes a pattern compiled with flags given
+// outside it as well as after its closing /. With the v flag, sets can
+// nest and combine with -- and &&, so a set runs to its matching ].
+func (j *JsFormat) TokenizeRegexWithFlags(pattern, flags string) []string {
+	return tokenizeSpans(j, pattern, flags)
+}
+
+// AppendTokenSpans appends the spans of the tokens of a pattern compiled
+// with flags to dst, as TokenizeRegexWithFlags tokenizes it
+func (j *JsFormat) AppendTokenSpans(dst []Span, pattern, flags string) []Span {
+	t := newSpanBuilder(dst)
+	j.tokenizeFrom(&t, pattern, flags, 0)
+	return t.done()
+}
+
+// tokenizeFrom adds the tokens of a pattern to t, skipping the part of its
+// body before from, which has to be where a token starts
+func (j *JsFormat) tokenizeFrom(t *spanBuilder, pattern, flags string, from int) {
 	// Check for regex flags at the end
-	flags := ""
 	if len(pattern) > 2 && pattern[0] == '/' {
 		lastSlashPos := strings.LastIndex(pattern, "/")
 		if lastSlashPos > 0 && lastSlashPos < len(pattern)-1 {
-			flags = pattern[lastSlashPos+1:]
-			pattern = pattern[1:lastSlashPos]
-			
 			// Add flags explanation as first token
-			if len(flags) > 0 {
-				tokens = append(tokens, "/"+flags)
-			}
+			t.add(lastSlashPos, len(pattern))
+			flags += pattern[lastSlashPos+1:]
+			pattern = pattern[1:lastSlashPos]
+			t.base = 1
 		} else if pattern[0] == '/' && pattern[len(pattern)-1] == '/' {
 			// No flags, but has delimiters
 			pattern = pattern[1 : len(pattern)-1]
+			t.base = 1
 		}
 	}
 	
-	for i := 0; i < len(pattern); i++ {
+	vMode := strings.ContainsRune(flags, 'v')
+	
+	for i := t.bodyFrom(from); i < len(pattern); i++ {
 		char := pattern[i]
 		
 		// Handle character classes
 		if char == '[' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
+			t.flush()
 			
+			// A ] can't be literal in a JavaScript set, so [] and [^] are whole sets
 			end := FindClosingBracket(pattern, i)
+			if vMode {
+				end = findClosingSetBracket(pattern, i)
+			} else if strings.HasPrefix(pattern[i:], "[]") {
+				end = i + 1
+			} else if strings.HasPrefix(pattern[i:], "[^]") {
+				end = i + 2
+			}
 			if end > i {
-				tokens = append(tokens, pattern[i:end+1])
+				t.add(i, end+1)
 				i = end
 				continue
 			}
@@ -79,25 +102,43 @@ func (j *JsFormat) TokenizeRegex(pattern string) []string {
 		
 		// Handle special escape sequences
 		if char == '\\' && i+1 < len(pattern) {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
+			t.flush()
+			if end := namedBackrefEnd(pattern, i); end > 0 {
+				// \k<name> - a named backreference
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			if end := propertyEscapeEnd(pattern, i); end > 0 {
+				// \p{Name}, \P{Name} or \pL - a Unicode property class
+				t.add(i, end)
+				i = end - 1
+				continue
 			}
-			tokens = append(tokens, pattern[i:i+2])
+			if end := codePointEscapeEnd(pattern, i, 'x', 2, false); end > 0 {
+				// \xhh - a character by its code point
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			if end := codePointEscapeEnd(pattern, i, 'u', 4, true); end > 0 {
+				// \uhhhh or \u{hhhhh} - a character by its code point
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			t.add(i, i+2)
 			i++
 			continue
 		}
 		
 		// Handle curly brace quantifiers
 		if char == '{' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
+			t.flush()
 			
 			end := FindClosingCurlyBrace(pattern, i)
 			if end > i {
-				tokens = append(tokens, pattern[i:end+1])
+				t.add(i, end+1)
 				i = end
 				continue
 			}
@@ -105,131 +146,116 @@ func (j *JsFormat) TokenizeRegex(pattern string) []string {
 		
 		// Handle simple quantifiers
 		if char == '*' || char == '+' || char == '?' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
+			t.flush()
 			
 			// Check for non-greedy quantifier
 			if i+1 < len(pattern) && pattern[i+1] == '?' {
-				tokens = append(tokens, string(char)+"?")
+				t.add(i, i+2)
 				i++
 			} else {
-				tokens = append(tokens, string(char))
+				t.add(i, i+1)
 			}
 			continue
 		}
 		
 		// Handle groups
 		if char == '(' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
+			t.flush()
+			
+			// (?i:...) - modifiers scoped to a group; JavaScript has no
+			// standalone (?i)
+			if end := inlineModifierEnd(pattern, i, jsModifiers); end > 0 && pattern[end-1] == ':' {
+				t.add(i, end)
+				i = end - 1
+				continue
 			}
 			
 			// Check for non-capturing and other special groups
 			if i+2 < len(pattern) && pattern[i+1] == '?' {
 				switch pattern[i+2] {
 				case ':': // (?:pattern) - non-capturing group
-					tokens = append(tokens, "(?:")
+					t.add(i, i+3)
 					i += 2
 				case '=': // (?=pattern) - positive lookahead
-					tokens = append(tokens, "(?=")
+					t.add(i, i+3)
 					i += 2
 				case '!': // (?!pattern) - negative lookahead
-					tokens = append(tokens, "(?!")
+					t.add(i, i+3)
 					i += 2
 				case '<': // Could be lookbehind or named capture
 					if i+3 < len(pattern) {
 						if pattern[i+3] == '=' { // (?<=pattern) - positive lookbehind
-							tokens = append(tokens, "(?<=")
+							t.add(i, i+4)
 							i += 3
 						} else if pattern[i+3] == '!' { // (?<!pattern) - negative lookbehind
-							tokens = append(tokens, "(?<!")
+							t.add(i, i+4)
 							i += 3
 						} else { // (?<name>pattern) - named capturing group
 							endName := strings.IndexByte(pattern[i+3:], '>')
 							if endName >= 0 {
 								endName += i + 3
-								tokens = append(tokens, pattern[i:endName+1])
+								t.add(i, endName+1)
 								i = endName
 							} else {
-								tokens = append(tokens, string(char))
+								t.add(i, i+1)
 							}
 						}
 					} else {
-						tokens = append(tokens, string(char))
+						t.add(i, i+1)
 					}
 				default:
-					tokens = append(tokens, string(char))
+					t.add(i, i+1)
 				}
 				continue
 			} else {
-				tokens = append(tokens, string(char))
+				t.add(i, i+1)
 				continue
 			}
 		}
 		
 		if char == ')' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle alternation
 		if char == '|' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle anchors
 		if char == '^' || char == '$' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle dot
 		if char == '.' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Default case: add to current token
-		currentToken.WriteByte(char)
-	}
-	
-	// Add the last token if any
-	if currentToken.Len() > 0 {
-		tokens = append(tokens, currentToken.String())
+		t.literal(i)
 	}
-	
-	return tokens
 }
 
 // ExplainToken provides a human-readable explanation for a regex token
 func (j *JsFormat) ExplainToken(token string) string {
 	switch {
+	case strings.HasPrefix(token, "(?") && inlineModifierEnd(token, 0, jsModifiers) == len(token):
+		return explainModifiers(token)
 	case strings.HasPrefix(token, "/"):
 		return explainJsFlags(token[1:])
 	case token == "^":
-		return "Matches the start of a line"
+		return "Matches only at the start of the input"
 	case token == "$":
-		return "Matches the end of a line"
+		return "Matches only at the end of the input"
 	case token == ".":
 		return "Matches any single character except newline"
 	case token == "*":
@@ -263,11 +289,10 @@ func (j *JsFormat) ExplainToken(token string) string {
 	case strings.HasPrefix(token, "(?<") && strings.HasSuffix(token, ">") && !strings.Contains(token, "<?") && !strings.Contains(token, "<!"):
 		name := token[3 : len(token)-1]
 		return fmt.Sprintf("Start of a named capturing group called '%s'", name)
+	case strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]") && isClassSetExpression(token):
+		return explainClassSet(token)
 	case strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]"):
-		if len(token) > 2 && token[1] == '^' {
-			return fmt.Sprintf("Matches any character NOT in the set: %s", token[2:len(token)-1])
-		}
-		return fmt.Sprintf("Matches any character in the set: %s", token[1:len(token)-1])
+		return explainClass(token, "js")
 	case strings.HasPrefix(token, "\\"):
 		return explainJsEscapeSequence(token)
 	case strings.HasPrefix(token, "{") && strings.HasSuffix(token, "}"):
@@ -283,13 +308,22 @@ func (j *JsFormat) ExplainToken(token string) string {
 		}
 		return fmt.Sprintf("Matches exactly %s occurrences of the preceding element", content)
 	default:
-		if len(token) == 1 {
-			return fmt.Sprintf("Matches the character '%s' literally", token)
-		}
-		return fmt.Sprintf("Matches the string '%s' literally", token)
+		return explainLiteral(token)
 	}
 }
 
+// jsFlagNames describes what each JavaScript RegExp flag does
+var jsFlagNames = map[rune]string{
+	'g': "Global search - find all matches rather than stopping after the first match",
+	'i': "Case-insensitive search",
+	'm': "Multi-line search - ^ and $ match start/end of each line",
+	's': "Dot-all mode - the dot (.) matches newlines",
+	'u': "Unicode mode - treat pattern as a sequence of Unicode code points",
+	'y': "Sticky mode - matches only from the index indicated by the lastIndex property",
+	'd': "Generate indices for substring matches",
+	'v': "Unicode sets mode - like u, and sets can also nest, subtract with --, intersect with && and match strings",
+}
+
 // explainJsFlags explains JavaScript RegExp flags
 func explainJsFlags(flags string) string {
 	if flags == "" {
@@ -298,22 +332,9 @@ func explainJsFlags(flags string) string {
 	
 	var explanations []string
 	for _, flag := range flags {
-		switch flag {
-		case 'g':
-			explanations = append(explanations, "g: Global search - find all matches rather than stopping after the first match")
-		case 'i':
-			explanations = append(explanations, "i: Case-insensitive search")
-		case 'm':
-			explanations = append(explanations, "m: Multi-line search - ^ and $ match start/end of each line")
-		case 's':
-			explanations = append(explanations, "s: Dot-all mode - the dot (.) matches newlines")
-		case 'u':
-			explanations = append(explanations, "u: Unicode mode - treat pattern as a sequence of Unicode code points")
-		case 'y':
-			explanations = append(explanations, "y: Sticky mode - matches only from the index indicated by the lastIndex property")
-		case 'd':
-			explanations = append(explanations, "d: Generate indices for substring matches")
-		default:
+		if name, ok := jsFlagNames[flag]; ok {
+			explanations = append(explanations, fmt.Sprintf("%c: %s", flag, name))
+		} else {
 			explanations = append(explanations, fmt.Sprintf("%c: Unknown flag", flag))
 		}
 	}
@@ -368,26 +389,29 @@ func explainJsEscapeSequence(sequence string) string {
 	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
 		return fmt.Sprintf("Backreference to capturing group %c", sequence[1])
 	case 'p', 'P':
-		if len(sequence) > 2 && sequence[2] == '{' {
-			end := strings.IndexByte(sequence[3:], '}')
-			if end >= 0 {
-				name := sequence[3 : 3+end]
-				if sequence[1] == 'p' {
-					return fmt.Sprintf("Matches a character with the unicode property '%s' (requires u flag)", name)
-				} else {
-					return fmt.Sprintf("Matches a character without the unicode property '%s' (requires u flag)", name)
-				}
-			}
+		// JavaScript has no one-letter \pL form
+		if len(sequence) < 3 || sequence[2] != '{' {
+			return "Invalid unicode property"
+		}
+		if explanation := explainStringProperty(sequence); explanation != "" {
+			return explanation
 		}
-		return "Invalid unicode property"
+		explanation := explainUnicodeProperty(sequence, "js")
+		if strings.HasPrefix(explanation, "Invalid") {
+			return explanation
+		}
+		return explanation + " when the u flag is set"
 	case 'u':
-		if len(sequence) >= 6 && isHexDigit(sequence[2]) && isHexDigit(sequence[3]) && isHexDigit(sequence[4]) && isHexDigit(sequence[5]) {
-			return fmt.Sprintf("Matches the Unicode character U+%s", sequence[2:6])
+		if codePointEscapeEnd(sequence, 0, 'u', 4, false) == len(sequence) {
+			return withCodePoint(fmt.Sprintf("Matches the Unicode character U+%s", sequence[2:6]), sequence)
+		}
+		if codePointEscapeEnd(sequence, 0, 'u', 4, true) == len(sequence) {
+			return withCodePoint(fmt.Sprintf("Matches the Unicode character U+%s", strings.ToUpper(escapeHex(sequence))), sequence) + " when the u flag is set"
 		}
 		return "Invalid Unicode escape sequence"
 	case 'x':
-		if len(sequence) >= 4 && isHexDigit(sequence[2]) && isHexDigit(sequence[3]) {
-			return fmt.Sprintf("Matches the character with hex code %s", sequence[2:4])
+		if codePointEscapeEnd(sequence, 0, 'x', 2, false) == len(sequence) {
+			return withCodePoint(fmt.Sprintf("Matches the character with hex code %s", sequence[2:4]), sequence)
 		}
 		return "Invalid hexadecimal escape sequence"
 	default:
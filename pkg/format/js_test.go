@@ -157,8 +157,8 @@ func TestJsFormat_ExplainToken(t *testing.T) {
 		{"/u", "Unicode mode"},
 		{"/y", "Sticky mode"},
 		{"/gimuy", "Global search"},
-		{"^", "Matches the start of a line"},
-		{"$", "Matches the end of a line"},
+		{"^", "Matches only at the start of the input"},
+		{"$", "Matches only at the end of the input"},
 		{".", "Matches any single character except newline"},
 		{"*", "Matches 0 or more of the preceding element (greedy)"},
 		{"+", "Matches 1 or more of the preceding element (greedy)"},
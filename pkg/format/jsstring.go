@@ -0,0 +1,120 @@
+package format
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// regExpConstructorPattern matches the start of a RegExp constructor call,
+// such as new RegExp( or RegExp(
+var regExpConstructorPattern = regexp.MustCompile(`^(?:new\s+)?RegExp\s*\(\s*`)
+
+// jsStringEscapes maps the single-character escapes of a JavaScript string
+// literal to the characters they stand for
+var jsStringEscapes = map[byte]string{
+	'n': "\n",
+	't': "\t",
+	'r': "\r",
+	'b': "\b",
+	'f': "\f",
+	'v': "\v",
+	'0': "\x00",
+}
+
+// IsRegExpConstructor reports whether source is a JavaScript RegExp
+// constructor call such as new RegExp("\\d+", "g")
+func IsRegExpConstructor(source string) bool {
+	return regExpConstructorPattern.MatchString(strings.TrimSpace(source))
+}
+
+// UnescapeJsString returns the pattern that JavaScript source building a
+// regex from a string evaluates to, removing the string literal's layer of
+// escaping so "\\d+" becomes \d+. The source is either a string literal or
+// a RegExp constructor call, whose flags are kept as a /pattern/flags
+// literal.
+func UnescapeJsString(source string) (string, error) {
+	source = strings.TrimSuffix(strings.TrimSpace(source), ";")
+	loc := regExpConstructorPattern.FindStringIndex(source)
+	if loc == nil {
+		pattern, rest, err := readJsString(source)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(rest) != "" {
+			return "", fmt.Errorf("unexpected %q after the JavaScript string", strings.TrimSpace(rest))
+		}
+		return pattern, nil
+	}
+
+	pattern, rest, err := readJsString(source[loc[1]:])
+	if err != nil {
+		return "", fmt.Errorf("the RegExp constructor's pattern must be a string literal: %w", err)
+	}
+	flags := ""
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, ",") {
+		if flags, rest, err = readJsString(strings.TrimSpace(rest[1:])); err != nil {
+			return "", fmt.Errorf("the RegExp constructor's flags must be a string literal: %w", err)
+		}
+		rest = strings.TrimSpace(rest)
+	}
+	if rest != ")" {
+		return "", fmt.Errorf("the RegExp constructor call isn't closed with )")
+	}
+
+	if flags != "" || strings.HasPrefix(pattern, "/") {
+		return "/" + pattern + "/" + flags, nil
+	}
+	return pattern, nil
+}
+
+// readJsString reads the JavaScript string literal that source starts with,
+// in single, double or back quotes, returning its value and the source
+// after it
+func readJsString(source string) (string, string, error) {
+	if source == "" || !strings.ContainsRune(`"'`+"`", rune(source[0])) {
+		return "", "", fmt.Errorf("expected a quoted JavaScript string")
+	}
+	quote := source[0]
+
+	var value strings.Builder
+	for i := 1; i < len(source); i++ {
+		switch {
+		case source[i] == quote:
+			return value.String(), source[i+1:], nil
+		case source[i] == '$' && quote == '`' && strings.HasPrefix(source[i:], "${"):
+			return "", "", fmt.Errorf("template literal substitutions such as ${...} can't be unescaped")
+		case source[i] != '\\':
+			value.WriteByte(source[i])
+			continue
+		case i+1 == len(source):
+			return "", "", fmt.Errorf("unterminated JavaScript string")
+		}
+
+		// An escape: the code point escapes and the single-character ones
+		// stand for a character, a line break is a line continuation and any
+		// other character stands for itself, so "\d" is just d
+		if end := codePointEscapeEnd(source, i, 'x', 2, false); end > 0 {
+			r, _ := DecodeEscape(source[i:end])
+			value.WriteRune(r)
+			i = end - 1
+		} else if end := codePointEscapeEnd(source, i, 'u', 4, true); end > 0 {
+			r, ok := DecodeEscape(source[i:end])
+			if !ok {
+				return "", "", fmt.Errorf("invalid Unicode escape %s", source[i:end])
+			}
+			value.WriteRune(r)
+			i = end - 1
+		} else if escaped, ok := jsStringEscapes[source[i+1]]; ok {
+			value.WriteString(escaped)
+			i++
+		} else if source[i+1] == '\n' {
+			i++
+		} else {
+			value.WriteByte(source[i+1])
+			i++
+		}
+	}
+	return "", "", fmt.Errorf("unterminated JavaScript string")
+}
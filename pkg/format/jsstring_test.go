@@ -0,0 +1,62 @@
+package format
+
+import "testing"
+
+func TestUnescapeJsString(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{`new RegExp("\\d+\\.\\d+")`, `\d+\.\d+`},
+		{`new RegExp('\\w+', 'gi');`, `/\w+/gi`},
+		{`RegExp("/path/")`, `//path//`},
+		{`"\\bfoo\d"`, `\bfood`},
+		{"`a\\tb`", "a\tb"},
+		{`"\x41\u00e9\u{1F600}"`, "A\u00e9\U0001F600"},
+		{`'it\'s'`, "it's"},
+	}
+
+	for _, tt := range tests {
+		got, err := UnescapeJsString(tt.source)
+		if err != nil {
+			t.Errorf("UnescapeJsString(%q) returned error: %v", tt.source, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("UnescapeJsString(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestUnescapeJsString_Errors(t *testing.T) {
+	for _, source := range []string{
+		`\d+`,
+		`"\\d+`,
+		`new RegExp(pattern)`,
+		`new RegExp("a", "g"`,
+		"`${x}`",
+		`"a" + "b"`,
+	} {
+		if got, err := UnescapeJsString(source); err == nil {
+			t.Errorf("UnescapeJsString(%q) = %q, want an error", source, got)
+		}
+	}
+}
+
+func TestIsRegExpConstructor(t *testing.T) {
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{`new RegExp("a")`, true},
+		{` RegExp ('a')`, true},
+		{`"a"`, false},
+		{`RegExpish`, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsRegExpConstructor(tt.source); got != tt.want {
+			t.Errorf("IsRegExpConstructor(%q) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The inline modifier letters each flavor accepts in (?flags) and (?flags:
+const (
+	goModifiers     = "imsU"
+	pcreModifiers   = "imsxnJU"
+	pythonModifiers = "aiLmsux"
+	jsModifiers     = "ims"
+)
+
+// modifierNames describes what each inline modifier turns on
+var modifierNames = map[byte]string{
+	'i': "case-insensitive matching",
+	'm': "multi-line mode (^ and $ match at line breaks)",
+	's': "dot-all mode (. matches newlines)",
+	'x': "extended mode (whitespace and # comments are ignored)",
+	'U': "ungreedy mode (quantifiers are lazy unless followed by ?)",
+	'n': "no auto-capture (plain groups don't capture)",
+	'J': "duplicate group names",
+	'a': "ASCII-only matching",
+	'L': "locale-dependent matching",
+	'u': "Unicode matching",
+}
+
+// Modifiers is the flag set of an inline modifier token such as (?i) or
+// (?m-s:
+type Modifiers struct {
+	// On and Off are the modifier letters turned on and off
+	On, Off string
+
+	// Scoped is set for (?flags:...), whose flags only apply inside its own
+	// group, rather than to the rest of the enclosing one
+	Scoped bool
+}
+
+// inlineModifierEnd returns the end of the inline modifier token starting
+// at pattern[i], such as (?i) or (?m-s:, using the modifier letters in
+// flags, or -1 if there isn't one
+func inlineModifierEnd(pattern string, i int, flags string) int {
+	if !strings.HasPrefix(pattern[i:], "(?") {
+		return -1
+	}
+	letters, dash := 0, false
+	for j := i + 2; j < len(pattern); j++ {
+		switch c := pattern[j]; {
+		case c == ')' || c == ':':
+			if letters == 0 {
+				return -1
+			}
+			return j + 1
+		case c == '-' && !dash:
+			dash = true
+		case strings.IndexByte(flags, c) >= 0:
+			letters++
+		default:
+			return -1
+		}
+	}
+	return -1
+}
+
+// ParseModifiers reads the flags of an inline modifier token such as (?i),
+// (?-s) or (?m-s:. It reports false for any other token.
+func ParseModifiers(token string) (Modifiers, bool) {
+	if !strings.HasPrefix(token, "(?") || len(token) < 4 {
+		return Modifiers{}, false
+	}
+	scoped := strings.HasSuffix(token, ":")
+	if !scoped && !strings.HasSuffix(token, ")") {
+		return Modifiers{}, false
+	}
+
+	on, off, _ := strings.Cut(token[2:len(token)-1], "-")
+	for _, c := range []byte(on + off) {
+		if _, ok := modifierNames[c]; !ok {
+			return Modifiers{}, false
+		}
+	}
+	if on == "" && off == "" {
+		return Modifiers{}, false
+	}
+	return Modifiers{On: on, Off: off, Scoped: scoped}, true
+}
+
+// describeModifiers lists what a set of modifier letters stands for
+func describeModifiers(letters string) string {
+	var names []string
+	for _, c := range []byte(letters) {
+		names = append(names, modifierNames[c])
+	}
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	default:
+		return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+	}
+}
+
+// explainModifiers explains an inline modifier token: which flags it turns
+// on and off and whether they last for its own group or the rest of the
+// enclosing one
+func explainModifiers(token string) string {
+	modifiers, ok := ParseModifiers(token)
+	if !ok {
+		return "Invalid inline modifiers"
+	}
+
+	var changes []string
+	if modifiers.On != "" {
+		changes = append(changes, "turns on "+describeModifiers(modifiers.On))
+	}
+	if modifiers.Off != "" {
+		changes = append(changes, "turns off "+describeModifiers(modifiers.Off))
+	}
+	change := strings.Join(changes, " and ")
+
+	if modifiers.Scoped {
+		return fmt.Sprintf("Start of a non-capturing group that %s for the tokens inside it", change)
+	}
+	return fmt.Sprintf("Inline modifiers - %s until the end of the enclosing group", change)
+}
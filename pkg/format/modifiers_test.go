@@ -0,0 +1,68 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeRegex_InlineModifiers(t *testing.T) {
+	tests := []struct {
+		format  RegexFormat
+		pattern string
+		want    []string
+	}{
+		{NewGoFormat(), "a(?i)b", []string{"a", "(?i)", "b"}},
+		{NewPcreFormat(), "(?m-s:^.)", []string{"(?m-s:", "^", ".", ")"}},
+		{NewPythonFormat(), "(?x)a(?-i:b)", []string{"(?x)", "a", "(?-i:", "b", ")"}},
+		{NewJsFormat(), "(?i:a)", []string{"(?i:", "a", ")"}},
+		{NewGoFormat(), "(?:a)", []string{"(?:", "a", ")"}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.format.TokenizeRegex(tt.pattern); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s TokenizeRegex(%q) = %q, want %q", tt.format.Name(), tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestParseModifiers(t *testing.T) {
+	tests := []struct {
+		token string
+		want  Modifiers
+		ok    bool
+	}{
+		{"(?i)", Modifiers{On: "i"}, true},
+		{"(?m-s:", Modifiers{On: "m", Off: "s", Scoped: true}, true},
+		{"(?-i)", Modifiers{Off: "i"}, true},
+		{"(?:", Modifiers{}, false},
+		{"(?=", Modifiers{}, false},
+		{"(?q)", Modifiers{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseModifiers(tt.token)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseModifiers(%q) = %+v, %v, want %+v, %v", tt.token, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestExplainToken_InlineModifiers(t *testing.T) {
+	tests := []struct {
+		format RegexFormat
+		token  string
+		want   string
+	}{
+		{NewGoFormat(), "(?i)", "Inline modifiers - turns on case-insensitive matching until the end of the enclosing group"},
+		{NewPcreFormat(), "(?m-s:", "Start of a non-capturing group that turns on multi-line mode (^ and $ match at line breaks) and turns off dot-all mode (. matches newlines) for the tokens inside it"},
+		{NewPcreFormat(), "(?is)", "Inline modifiers - turns on case-insensitive matching and dot-all mode (. matches newlines) until the end of the enclosing group"},
+		{NewPythonFormat(), "(?i)", "Flags: i: Case-insensitive matching"},
+		{NewPythonFormat(), "(?-i:", "Start of a non-capturing group that turns off case-insensitive matching for the tokens inside it"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.format.ExplainToken(tt.token); got != tt.want {
+			t.Errorf("%s ExplainToken(%q) = %q, want %q", tt.format.Name(), tt.token, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pcreStartOptions describes the option settings a PCRE pattern can start
+// with, such as (*UCP), which override options the caller compiled it with
+var pcreStartOptions = map[string]string{
+	"UTF":               "UTF mode (the pattern and subject are UTF encoded)",
+	"UTF8":              "UTF-8 mode (the pattern and subject are UTF-8)",
+	"UCP":               "Unicode properties for \\d, \\s, \\w and \\b, so word characters include all Unicode letters and digits",
+	"CR":                "\\r alone is a newline",
+	"LF":                "\\n alone is a newline",
+	"CRLF":              "\\r\\n is a newline",
+	"ANYCRLF":           "\\r, \\n and \\r\\n are newlines",
+	"ANY":               "any Unicode line break is a newline",
+	"NUL":               "the NUL character is a newline",
+	"BSR_ANYCRLF":       "\\R only matches \\r, \\n and \\r\\n",
+	"BSR_UNICODE":       "\\R matches any Unicode line break",
+	"NO_AUTO_POSSESS":   "no automatic possessification of quantifiers",
+	"NO_DOTSTAR_ANCHOR": "no automatic anchoring of patterns starting with .*",
+	"NO_JIT":            "no just-in-time compilation",
+	"NO_START_OPT":      "no start-of-match optimizations",
+	"NOTEMPTY":          "an empty match isn't a match",
+	"NOTEMPTY_ATSTART":  "an empty match at the start isn't a match",
+}
+
+// pcreLimitOptions describes the option settings of PCRE that take a number,
+// such as (*LIMIT_MATCH=1000)
+var pcreLimitOptions = map[string]string{
+	"LIMIT_DEPTH":     "backtracking depth",
+	"LIMIT_HEAP":      "heap memory in kibibytes",
+	"LIMIT_MATCH":     "match function calls",
+	"LIMIT_RECURSION": "backtracking depth",
+}
+
+// pcreOptionEnd returns the index just past a PCRE option setting such as
+// (*UCP) or (*LIMIT_MATCH=1000) starting at pattern[i], or -1 if there
+// isn't one
+func pcreOptionEnd(pattern string, i int) int {
+	if !strings.HasPrefix(pattern[i:], "(*") {
+		return -1
+	}
+	end := strings.IndexByte(pattern[i:], ')')
+	if end < 0 {
+		return -1
+	}
+	if _, ok := pcreOptionDescription(pattern[i+2 : i+end]); !ok {
+		return -1
+	}
+	return i + end + 1
+}
+
+// pcreOptionDescription describes the name inside a PCRE option setting
+func pcreOptionDescription(name string) (string, bool) {
+	if description, ok := pcreStartOptions[name]; ok {
+		return description, true
+	}
+	limit, value, found := strings.Cut(name, "=")
+	if description, ok := pcreLimitOptions[limit]; ok && found && isQuantifierBody(value) && !strings.Contains(value, ",") {
+		return fmt.Sprintf("at most %s %s", value, description), true
+	}
+	return "", false
+}
+
+// IsPcreOption reports whether a token is a PCRE option setting such as (*UCP)
+func IsPcreOption(token string) bool {
+	return token != "" && pcreOptionEnd(token, 0) == len(token)
+}
+
+// explainPcreOption explains a PCRE option setting such as (*CRLF)
+func explainPcreOption(token string) string {
+	description, _ := pcreOptionDescription(token[2 : len(token)-1])
+	return "Option setting - " + description
+}
@@ -0,0 +1,33 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPcreOptions(t *testing.T) {
+	got := NewPcreFormat().TokenizeRegex(`(*UCP)(*LIMIT_MATCH=100)\w(*FOO)`)
+	want := []string{"(*UCP)", "(*LIMIT_MATCH=100)", `\w`, "(", "*", "FOO", ")"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeRegex() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"(*CRLF)", "Option setting - \\r\\n is a newline"},
+		{"(*LIMIT_HEAP=64)", "Option setting - at most 64 heap memory in kibibytes"},
+	}
+	for _, tt := range tests {
+		if got := NewPcreFormat().ExplainToken(tt.token); got != tt.want {
+			t.Errorf("ExplainToken(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+
+	for _, token := range []string{"(*LIMIT_MATCH=)", "(*LIMIT_MATCH=1,2)", "(*ucp)", "(*UCP"} {
+		if IsPcreOption(token) {
+			t.Errorf("IsPcreOption(%q) = true, want false", token)
+		}
+	}
+}
@@ -39,22 +39,65 @@ func (p *PcreFormat) HasFeature(feature string) bool {
 
 // TokenizeRegex breaks a regex pattern into meaningful tokens
 func (p *PcreFormat) TokenizeRegex(pattern string) []string {
-	var tokens []string
-	var currentToken strings.Builder
+	return p.TokenizeRegexWithFlags(pattern, "")
+}
+
+// TokenizeRegexWithFlags tokenizes a pattern compiled with flags such as
+// PCRE2_EXTENDED. A pattern wrapped in delimiters, such as qr/.../x or
+// #...#u, is unwrapped and its modifiers are the first token. In extended
+// mode, from the x flag or modifier, whitespace outside classes and escapes
+// is skipped and each # comment is a token of its own.
+func (p *PcreFormat) TokenizeRegexWithFlags(pattern, flags string) []string {
+	return tokenizeSpans(p, pattern, flags)
+}
+
+// AppendTokenSpans appends the spans of the tokens of a pattern compiled
+// with flags to dst, as TokenizeRegexWithFlags tokenizes it
+func (p *PcreFormat) AppendTokenSpans(dst []Span, pattern, flags string) []Span {
+	t := newSpanBuilder(dst)
+	p.tokenizeFrom(&t, pattern, flags, 0)
+	return t.done()
+}
+
+// tokenizeFrom adds the tokens of a pattern to t, skipping the part of its
+// body before from, which has to be where a token starts
+func (p *PcreFormat) tokenizeFrom(t *spanBuilder, pattern, flags string, from int) {
+	// Unwrap a delimited pattern, keeping its modifiers. The closing
+	// delimiter and modifiers end the pattern, right after the body.
+	if body, modifiers, ok := PcreDelimited(pattern); ok {
+		if len(modifiers) > 1 {
+			t.add(len(pattern)-len(modifiers), len(pattern))
+			flags += modifiers[1:]
+		}
+		t.base = len(pattern) - len(modifiers) - len(body)
+		pattern = body
+	}
+	extended := strings.ContainsRune(flags, 'x')
 	
-	for i := 0; i < len(pattern); i++ {
+	for i := t.bodyFrom(from); i < len(pattern); i++ {
 		char := pattern[i]
 		
+		// Skip insignificant whitespace and keep comments in extended mode
+		if extended && (strings.IndexByte(" \t\n\r\f\v", char) >= 0 || char == '#') {
+			t.flush()
+			if char == '#' {
+				end := strings.IndexByte(pattern[i:], '\n')
+				if end < 0 {
+					end = len(pattern) - i
+				}
+				t.add(i, i+len(strings.TrimRight(pattern[i:i+end], " \t\r")))
+				i += end - 1
+			}
+			continue
+		}
+		
 		// Handle character classes
 		if char == '[' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
+			t.flush()
 			
 			end := FindClosingBracket(pattern, i)
 			if end > i {
-				tokens = append(tokens, pattern[i:end+1])
+				t.add(i, end+1)
 				i = end
 				continue
 			}
@@ -62,25 +105,55 @@ func (p *PcreFormat) TokenizeRegex(pattern string) []string {
 		
 		// Handle special escape sequences
 		if char == '\\' && i+1 < len(pattern) {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
+			t.flush()
+			if end := quotedSpanEnd(pattern, i); end > i+2 {
+				// \Q...\E - text matched literally, special characters and all
+				t.add(i, end)
+				i = end - 1
+				continue
 			}
-			tokens = append(tokens, pattern[i:i+2])
+			if end := namedBackrefEnd(pattern, i); end > 0 {
+				// \k<name> - a named backreference
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			if end := gEscapeEnd(pattern, i); end > 0 {
+				// \g<1> or \g'name' - a subroutine call, \g{1} or \g1 - a backreference
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			if end := propertyEscapeEnd(pattern, i); end > 0 {
+				// \p{Name}, \P{Name} or \pL - a Unicode property class
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			if end := octalEscapeEnd(pattern, i); end > i+2 {
+				// \0oo or \ooo - a character by its octal code
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			if end := codePointEscapeEnd(pattern, i, 'x', 2, true); end > 0 {
+				// \xhh or \x{hhhh} - a character by its code point
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			t.add(i, i+2)
 			i++
 			continue
 		}
 		
 		// Handle curly brace quantifiers
 		if char == '{' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
+			t.flush()
 			
 			end := FindClosingCurlyBrace(pattern, i)
 			if end > i {
-				tokens = append(tokens, pattern[i:end+1])
+				t.add(i, end+1)
 				i = end
 				continue
 			}
@@ -88,145 +161,165 @@ func (p *PcreFormat) TokenizeRegex(pattern string) []string {
 		
 		// Handle simple quantifiers and possessive modifiers
 		if char == '*' || char == '+' || char == '?' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
+			t.flush()
 			
 			// Check for possessive quantifier
 			if i+1 < len(pattern) && pattern[i+1] == '+' {
-				tokens = append(tokens, string(char)+"+")
+				t.add(i, i+2)
 				i++
 			} else {
-				tokens = append(tokens, string(char))
+				t.add(i, i+1)
 			}
 			continue
 		}
 		
 		// Handle groups and special assertions
 		if char == '(' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
+			t.flush()
+			
+			// (*UCP) or (*CRLF) - an option setting
+			if end := pcreOptionEnd(pattern, i); end > 0 {
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			
+			// (?R), (?1) or (?&name) - recursion or a subroutine call
+			if end := subroutineCallEnd(pattern, i); end > 0 {
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			
+			// (?P=name) - a named backreference
+			if end := pythonBackrefEnd(pattern, i); end > 0 {
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			
+			// (?(1), (?(<name>) or (?(?=a) - the condition of a conditional group
+			if end := conditionEnd(pattern, i); end > 0 {
+				t.add(i, end)
+				i = end - 1
+				continue
+			}
+			
+			// (?i) or (?m-s:...) - inline modifiers, alone or scoped to a group
+			if end := inlineModifierEnd(pattern, i, pcreModifiers); end > 0 {
+				t.add(i, end)
+				i = end - 1
+				continue
 			}
 			
 			// Check for special groups
 			if i+2 < len(pattern) && pattern[i+1] == '?' {
 				switch pattern[i+2] {
 				case ':': // (?:pattern) - non-capturing group
-					tokens = append(tokens, "(?:")
+					t.add(i, i+3)
 					i += 2
 				case '=': // (?=pattern) - positive lookahead
-					tokens = append(tokens, "(?=")
+					t.add(i, i+3)
 					i += 2
 				case '!': // (?!pattern) - negative lookahead
-					tokens = append(tokens, "(?!")
+					t.add(i, i+3)
 					i += 2
 				case '<': // Could be lookbehind or named group
 					if i+3 < len(pattern) {
 						if pattern[i+3] == '=' { // (?<=pattern) - positive lookbehind
-							tokens = append(tokens, "(?<=")
+							t.add(i, i+4)
 							i += 3
 						} else if pattern[i+3] == '!' { // (?<!pattern) - negative lookbehind
-							tokens = append(tokens, "(?<!")
+							t.add(i, i+4)
 							i += 3
 						} else { // (?<name>pattern) - named capturing group
 							endName := strings.IndexByte(pattern[i+3:], '>')
 							if endName >= 0 {
 								endName += i + 3
-								tokens = append(tokens, pattern[i:endName+1])
+								t.add(i, endName+1)
 								i = endName
 							} else {
-								tokens = append(tokens, string(char))
+								t.add(i, i+1)
 							}
 						}
 					} else {
-						tokens = append(tokens, string(char))
+						t.add(i, i+1)
 					}
 				case '>': // (?>pattern) - atomic group
-					tokens = append(tokens, "(?>")
+					t.add(i, i+3)
 					i += 2
 				case 'P': // (?P<name>pattern) - another named group syntax
 					if i+3 < len(pattern) && pattern[i+3] == '<' {
 						endName := strings.IndexByte(pattern[i+4:], '>')
 						if endName >= 0 {
 							endName += i + 4
-							tokens = append(tokens, pattern[i:endName+1])
+							t.add(i, endName+1)
 							i = endName
 						} else {
-							tokens = append(tokens, string(char))
+							t.add(i, i+1)
 						}
 					} else {
-						tokens = append(tokens, string(char))
+						t.add(i, i+1)
 					}
 				default:
-					tokens = append(tokens, string(char))
+					t.add(i, i+1)
 				}
 				continue
 			} else {
-				tokens = append(tokens, string(char))
+				t.add(i, i+1)
 				continue
 			}
 		}
 		
 		if char == ')' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle alternation
 		if char == '|' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle anchors
 		if char == '^' || char == '$' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle dot
 		if char == '.' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Default case: add to current token
-		currentToken.WriteByte(char)
-	}
-	
-	// Add the last token if any
-	if currentToken.Len() > 0 {
-		tokens = append(tokens, currentToken.String())
+		t.literal(i)
 	}
-	
-	return tokens
 }
 
 // ExplainToken provides a human-readable explanation for a regex token
 func (p *PcreFormat) ExplainToken(token string) string {
 	switch {
+	case IsCondition(token):
+		return explainCondition(token)
+	case isDelimiterModifiers(token):
+		return explainDelimiterModifiers(token)
+	case IsPcreOption(token):
+		return explainPcreOption(token)
+	case subroutineCallEnd(token, 0) == len(token):
+		return explainSubroutineCall(token)
+	case strings.HasPrefix(token, "(?") && inlineModifierEnd(token, 0, pcreModifiers) == len(token):
+		return explainModifiers(token)
 	case token == "^":
-		return "Matches the start of a line"
+		return "Matches only at the start of the input"
 	case token == "$":
-		return "Matches the end of a line"
+		return "Matches at the end of the input, or just before a newline that ends it"
 	case token == ".":
 		return "Matches any single character except newline"
 	case token == "*":
@@ -265,11 +358,10 @@ func (p *PcreFormat) ExplainToken(token string) string {
 	case strings.HasPrefix(token, "(?P<") && strings.HasSuffix(token, ">"):
 		name := token[4 : len(token)-1]
 		return fmt.Sprintf("Start of a named capturing group called '%s'", name)
+	case pythonBackrefEnd(token, 0) == len(token):
+		return fmt.Sprintf("Backreference to the named group '%s'", token[4:len(token)-1])
 	case strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]"):
-		if len(token) > 2 && token[1] == '^' {
-			return fmt.Sprintf("Matches any character NOT in the set: %s", token[2:len(token)-1])
-		}
-		return fmt.Sprintf("Matches any character in the set: %s", token[1:len(token)-1])
+		return explainClass(token, "pcre")
 	case strings.HasPrefix(token, "\\"):
 		return explainPcreEscapeSequence(token)
 	case strings.HasPrefix(token, "{") && strings.HasSuffix(token, "}"):
@@ -285,10 +377,7 @@ func (p *PcreFormat) ExplainToken(token string) string {
 		}
 		return fmt.Sprintf("Matches exactly %s occurrences of the preceding element", content)
 	default:
-		if len(token) == 1 {
-			return fmt.Sprintf("Matches the character '%s' literally", token)
-		}
-		return fmt.Sprintf("Matches the string '%s' literally", token)
+		return explainLiteral(token)
 	}
 }
 
@@ -297,6 +386,9 @@ func explainPcreEscapeSequence(sequence string) string {
 	if len(sequence) < 2 {
 		return "Invalid escape sequence"
 	}
+	if len(sequence) > 2 && octalEscapeEnd(sequence, 0) == len(sequence) {
+		return explainOctalEscape(sequence)
+	}
 	
 	switch sequence[1] {
 	case 'd':
@@ -321,6 +413,8 @@ func explainPcreEscapeSequence(sequence string) string {
 		return "Matches the end of the string or before the final newline"
 	case 'z':
 		return "Matches the absolute end of the string"
+	case 'R':
+		return "Matches any line break, taking \\r\\n as one: \\r\\n, \\n, \\r, \\v, \\f, U+0085, U+2028 or U+2029"
 	case 'G':
 		return "Matches the position where the previous match ended"
 	case 'n':
@@ -336,33 +430,28 @@ func explainPcreEscapeSequence(sequence string) string {
 	case '0':
 		return "Matches a null character"
 	case 'k':
-		if len(sequence) > 2 && sequence[2] == '<' {
-			end := strings.IndexByte(sequence[3:], '>')
-			if end >= 0 {
-				name := sequence[3 : 3+end]
-				return fmt.Sprintf("Backreference to the named group '%s'", name)
-			}
+		if namedBackrefEnd(sequence, 0) == len(sequence) {
+			return fmt.Sprintf("Backreference to the named group '%s'", sequence[3:len(sequence)-1])
 		}
 		return "Invalid named backreference"
 	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
 		return fmt.Sprintf("Backreference to capturing group %c", sequence[1])
+	case 'g':
+		return explainGEscape(sequence)
 	case 'p', 'P':
-		if len(sequence) > 2 && sequence[2] == '{' {
-			end := strings.IndexByte(sequence[3:], '}')
-			if end >= 0 {
-				name := sequence[3 : 3+end]
-				if sequence[1] == 'p' {
-					return fmt.Sprintf("Matches a character with the unicode property '%s'", name)
-				} else {
-					return fmt.Sprintf("Matches a character without the unicode property '%s'", name)
-				}
-			}
-		}
-		return "Invalid unicode property"
+		return explainUnicodeProperty(sequence, "pcre")
 	case 'Q':
+		if len(sequence) > 2 {
+			return explainQuotedSpan(sequence)
+		}
 		return "Start of a quoted sequence (everything until \\E is treated as a literal)"
 	case 'E':
 		return "End of a quoted sequence"
+	case 'x':
+		if codePointEscapeEnd(sequence, 0, 'x', 2, true) == len(sequence) {
+			return withCodePoint(fmt.Sprintf("Matches the character with hex code %s", escapeHex(sequence)), sequence)
+		}
+		return "Invalid hexadecimal escape sequence"
 	default:
 		return fmt.Sprintf("Matches the character '%c' literally", sequence[1])
 	}
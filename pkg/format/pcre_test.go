@@ -87,6 +87,11 @@ func TestPcreFormat_TokenizeRegex(t *testing.T) {
 			"(?P<name>abc)",
 			[]string{"(?P<name>", "abc", ")"},
 		},
+		{
+			"Named backreference - Python syntax",
+			"(?P<w>a)(?P=w)",
+			[]string{"(?P<w>", "a", ")", "(?P=w)"},
+		},
 		{
 			"Non-capturing group",
 			"(?:abc)",
@@ -155,8 +160,8 @@ func TestPcreFormat_ExplainToken(t *testing.T) {
 		token string
 		want  string
 	}{
-		{"^", "Matches the start of a line"},
-		{"$", "Matches the end of a line"},
+		{"^", "Matches only at the start of the input"},
+		{"$", "Matches at the end of the input, or just before a newline that ends it"},
 		{".", "Matches any single character except newline"},
 		{"*", "Matches 0 or more of the preceding element"},
 		{"+", "Matches 1 or more of the preceding element"},
@@ -175,6 +180,7 @@ func TestPcreFormat_ExplainToken(t *testing.T) {
 		{"(?>", "Start of an atomic group"},
 		{"(?<name>", "Start of a named capturing group called 'name'"},
 		{"(?P<name>", "Start of a named capturing group called 'name'"},
+		{"(?P=name)", "Backreference to the named group 'name'"},
 		{"[a-z]", "Matches any character in the set: a-z"},
 		{"[^0-9]", "Matches any character NOT in the set: 0-9"},
 		{"\\d", "Matches any digit (0-9)"},
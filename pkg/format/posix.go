@@ -2,6 +2,7 @@ package format
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -39,18 +40,26 @@ func (p *PosixFormat) HasFeature(feature string) bool {
 
 // TokenizeRegex breaks a regex pattern into meaningful tokens
 func (p *PosixFormat) TokenizeRegex(pattern string) []string {
-	var tokens []string
-	var currentToken strings.Builder
-	
-	for i := 0; i < len(pattern); i++ {
+	return tokenizeSpans(p, pattern, "")
+}
+
+// AppendTokenSpans appends the spans of the tokens of a pattern to dst.
+// POSIX patterns don't depend on flags given outside them.
+func (p *PosixFormat) AppendTokenSpans(dst []Span, pattern, flags string) []Span {
+	t := newSpanBuilder(dst)
+	p.tokenizeFrom(&t, pattern, flags, 0)
+	return t.done()
+}
+
+// tokenizeFrom adds the tokens of a pattern to t, skipping the part of its
+// body before from, which has to be where a token starts
+func (p *PosixFormat) tokenizeFrom(t *spanBuilder, pattern, _ string, from int) {
+	for i := t.bodyFrom(from); i < len(pattern); i++ {
 		char := pattern[i]
 		
 		// Handle character classes
 		if char == '[' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
+			t.flush()
 			
 			// Check for POSIX character classes
 			if i+2 < len(pattern) && pattern[i+1] == '[' && pattern[i+2] == ':' {
@@ -58,7 +67,7 @@ func (p *PosixFormat) TokenizeRegex(pattern string) []string {
 				if end > 3 { // [[:class:]]
 					endBracket := FindClosingBracket(pattern, i)
 					if endBracket > i+end+2 { // Make sure the bracket closes after the POSIX class
-						tokens = append(tokens, pattern[i:endBracket+1])
+						t.add(i, endBracket+1)
 						i = endBracket
 						continue
 					}
@@ -67,7 +76,7 @@ func (p *PosixFormat) TokenizeRegex(pattern string) []string {
 			
 			end := FindClosingBracket(pattern, i)
 			if end > i {
-				tokens = append(tokens, pattern[i:end+1])
+				t.add(i, end+1)
 				i = end
 				continue
 			}
@@ -75,25 +84,19 @@ func (p *PosixFormat) TokenizeRegex(pattern string) []string {
 		
 		// Handle special escape sequences
 		if char == '\\' && i+1 < len(pattern) {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, pattern[i:i+2])
+			t.flush()
+			t.add(i, i+2)
 			i++
 			continue
 		}
 		
 		// Handle curly brace quantifiers
 		if char == '{' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
+			t.flush()
 			
 			end := FindClosingCurlyBrace(pattern, i)
 			if end > i {
-				tokens = append(tokens, pattern[i:end+1])
+				t.add(i, end+1)
 				i = end
 				continue
 			}
@@ -101,82 +104,57 @@ func (p *PosixFormat) TokenizeRegex(pattern string) []string {
 		
 		// Handle simple quantifiers
 		if char == '*' || char == '+' || char == '?' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle groups
 		if char == '(' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		if char == ')' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle alternation
 		if char == '|' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle anchors
 		if char == '^' || char == '$' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle dot
 		if char == '.' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Default case: add to current token
-		currentToken.WriteByte(char)
+		t.literal(i)
 	}
-	
-	// Add the last token if any
-	if currentToken.Len() > 0 {
-		tokens = append(tokens, currentToken.String())
-	}
-	
-	return tokens
 }
 
 // ExplainToken provides a human-readable explanation for a regex token
 func (p *PosixFormat) ExplainToken(token string) string {
 	switch {
 	case token == "^":
-		return "Matches the start of a line"
+		return "Matches only at the start of the input"
 	case token == "$":
-		return "Matches the end of a line"
+		return "Matches only at the end of the input"
 	case token == ".":
 		return "Matches any single character"
 	case token == "*":
@@ -192,20 +170,22 @@ func (p *PosixFormat) ExplainToken(token string) string {
 	case token == ")":
 		return "End of a capturing group"
 	case strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]"):
-		if strings.Contains(token, "[[:") && strings.Contains(token, ":]]") {
-			// Extract POSIX character class name
-			start := strings.Index(token, "[[:")
-			end := strings.Index(token, ":]]")
-			if start >= 0 && end > start+3 {
-				className := token[start+3 : end]
-				return explainPosixCharClass(className)
-			}
+		if strings.HasPrefix(token, "[[:") && strings.HasSuffix(token, ":]]") && strings.Count(token, ":]") == 1 {
+			// A bracket expression holding just one POSIX class
+			className := token[3 : len(token)-3]
+			return withLocaleNote(explainPosixCharClass(className), className)
 		}
+		if isCollationToken(token) {
+			return explainCollationToken(token)
+		}
+		
+		explanation := explainClass(token, "posix")
 		
-		if len(token) > 2 && token[1] == '^' {
-			return fmt.Sprintf("Matches any character NOT in the set: %s", token[2:len(token)-1])
+		// Note which of the POSIX classes in the set depend on the locale
+		for _, match := range posixClassPattern.FindAllStringSubmatch(token, -1) {
+			explanation = withLocaleNote(explanation, match[1])
 		}
-		return fmt.Sprintf("Matches any character in the set: %s", token[1:len(token)-1])
+		return explanation
 	case strings.HasPrefix(token, "\\"):
 		return explainPosixEscapeSequence(token)
 	case strings.HasPrefix(token, "{") && strings.HasSuffix(token, "}"):
@@ -221,10 +201,7 @@ func (p *PosixFormat) ExplainToken(token string) string {
 		}
 		return fmt.Sprintf("Matches exactly %s occurrences of the preceding element", content)
 	default:
-		if len(token) == 1 {
-			return fmt.Sprintf("Matches the character '%s' literally", token)
-		}
-		return fmt.Sprintf("Matches the string '%s' literally", token)
+		return explainLiteral(token)
 	}
 }
 
@@ -264,6 +241,37 @@ func explainPosixCharClass(className string) string {
 	}
 }
 
+// posixClassPattern finds the POSIX classes in a bracket expression
+var posixClassPattern = regexp.MustCompile(`\[:([a-z]+):\]`)
+
+// posixLocaleClasses describes how the classes whose membership depends on
+// the locale differ between the C locale and a UTF-8 one
+var posixLocaleClasses = map[string]struct {
+	c, utf8 string
+}{
+	"alnum": {"a-z, A-Z and 0-9", "letters like é, ß and Ж"},
+	"alpha": {"a-z and A-Z", "letters like é, ß and Ж"},
+	"word":  {"a-z, A-Z, 0-9 and _", "letters like é, ß and Ж"},
+	"lower": {"a-z", "lowercase letters like é, ß and ж"},
+	"upper": {"A-Z", "uppercase letters like É and Ж"},
+	"punct": {"ASCII punctuation", "characters like «, ¿ and €"},
+	"graph": {"visible ASCII characters", "visible characters like é, € and 😀"},
+	"print": {"printable ASCII characters", "printable characters like é, € and 😀"},
+	"space": {"space, \\t, \\n, \\v, \\f and \\r", "Unicode spaces like U+2003 EM SPACE"},
+}
+
+// withLocaleNote adds to an explanation how a POSIX class's membership
+// depends on the locale, the classic surprise when grep behaves
+// differently under LANG=C and a UTF-8 LANG
+func withLocaleNote(explanation, className string) string {
+	locale, ok := posixLocaleClasses[className]
+	if !ok {
+		return explanation
+	}
+	return fmt.Sprintf("%s. [:%s:] depends on the locale: the C locale only includes %s, a UTF-8 locale also %s",
+		explanation, className, locale.c, locale.utf8)
+}
+
 // explainPosixEscapeSequence explains POSIX-specific escape sequences
 func explainPosixEscapeSequence(sequence string) string {
 	if len(sequence) < 2 {
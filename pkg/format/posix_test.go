@@ -0,0 +1,36 @@
+package format
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPosixFormat_TokenizeClasses(t *testing.T) {
+	got := NewPosixFormat().TokenizeRegex(`^[[:alpha:]]+[[:digit:]_]$`)
+	want := []string{"^", "[[:alpha:]]", "+", "[[:digit:]_]", "$"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeRegex() = %q, want %q", got, want)
+	}
+}
+
+func TestPosixFormat_LocaleNotes(t *testing.T) {
+	format := NewPosixFormat()
+
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"[[:alpha:]]", "Matches any alphabetic character (a-z, A-Z). [:alpha:] depends on the locale: the C locale only includes a-z and A-Z, a UTF-8 locale also letters like é, ß and Ж"},
+		{"[[:digit:]]", "Matches decimal digits (0-9)"},
+		{"[^[:lower:]0-9]", "Matches any character NOT in the set: [:lower:]0-9 — [:lower:], lowercase letters (a-z); '0' to '9'. [:lower:] depends on the locale"},
+		{"[[:digit:]_]", "Matches any character in the set: [:digit:]_ — [:digit:], decimal digits (0-9); '_'"},
+	}
+
+	for _, tt := range tests {
+		got := format.ExplainToken(tt.token)
+		if !strings.HasPrefix(got, tt.want) || (!strings.Contains(tt.want, "locale") && strings.Contains(got, "locale")) {
+			t.Errorf("ExplainToken(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+}
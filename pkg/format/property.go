@@ -0,0 +1,183 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Number of example characters listed for a Unicode property
+const propertyExamples = 5
+
+// categoryNames describes the Unicode general categories
+var categoryNames = map[string]string{
+	"C":  "other characters",
+	"Cc": "control characters",
+	"Cf": "format characters",
+	"Co": "private use characters",
+	"Cs": "surrogates",
+	"L":  "letters",
+	"Ll": "lowercase letters",
+	"Lm": "modifier letters",
+	"Lo": "other letters",
+	"Lt": "titlecase letters",
+	"Lu": "uppercase letters",
+	"M":  "marks",
+	"Mc": "spacing marks",
+	"Me": "enclosing marks",
+	"Mn": "nonspacing marks",
+	"N":  "numbers",
+	"Nd": "decimal digits",
+	"Nl": "letter numbers",
+	"No": "other numbers",
+	"P":  "punctuation",
+	"Pc": "connector punctuation",
+	"Pd": "dash punctuation",
+	"Pe": "closing punctuation",
+	"Pf": "final quotation marks",
+	"Pi": "initial quotation marks",
+	"Po": "other punctuation",
+	"Ps": "opening punctuation",
+	"S":  "symbols",
+	"Sc": "currency symbols",
+	"Sk": "modifier symbols",
+	"Sm": "math symbols",
+	"So": "other symbols",
+	"Z":  "separators",
+	"Zl": "line separators",
+	"Zp": "paragraph separators",
+	"Zs": "space separators",
+}
+
+// propertyEscapeEnd returns the end of the Unicode property escape starting
+// at pattern[i], such as \p{Greek}, \P{Lu} or the one-letter \pL, or -1 if
+// there isn't one
+func propertyEscapeEnd(pattern string, i int) int {
+	if i+2 >= len(pattern) || pattern[i] != '\\' || (pattern[i+1] != 'p' && pattern[i+1] != 'P') {
+		return -1
+	}
+	if pattern[i+2] != '{' {
+		if isLetter(pattern[i+2]) {
+			return i + 3
+		}
+		return -1
+	}
+	end := strings.IndexByte(pattern[i+3:], '}')
+	if end <= 0 {
+		return -1
+	}
+	return i + 3 + end + 1
+}
+
+// isLetter reports whether b is an ASCII letter
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// unicodeProperty looks up a property name the way a flavor does, returning
+// the table of code points it covers and a description of it. Flavors
+// other than Go also accept binary properties such as White_Space, and
+// JavaScript spells scripts and categories as Script=Greek or gc=Lu.
+func unicodeProperty(name, flavor string) (*unicode.RangeTable, string) {
+	if flavor == "js" {
+		if eq := strings.IndexByte(name, '='); eq >= 0 {
+			name = name[eq+1:]
+		}
+	}
+
+	if name == "Any" {
+		return &unicode.RangeTable{R32: []unicode.Range32{{Lo: 0, Hi: unicode.MaxRune, Stride: 1}}}, "any character"
+	}
+	if table, ok := unicode.Categories[name]; ok {
+		return table, categoryNames[name]
+	}
+	if table, ok := unicode.Scripts[name]; ok {
+		return table, fmt.Sprintf("the %s script", strings.ReplaceAll(name, "_", " "))
+	}
+	if table, ok := unicode.Properties[name]; ok && flavor != "go" {
+		return table, fmt.Sprintf("the %s property", strings.ReplaceAll(name, "_", " "))
+	}
+	return nil, ""
+}
+
+// describePropertyTable summarizes the code points a property covers: how
+// many there are, the ranges they span and a few examples
+func describePropertyTable(table *unicode.RangeTable) string {
+	count, spans := 0, 0
+	var first, last rune
+	var examples []string
+
+	add := func(r rune) {
+		if count == 0 {
+			first = r
+		}
+		if count == 0 || r != last+1 {
+			spans++
+		}
+		count++
+		last = r
+		if len(examples) < propertyExamples && unicode.IsGraphic(r) && !unicode.IsSpace(r) {
+			examples = append(examples, string(r))
+		}
+	}
+	addRange := func(lo, hi, stride rune) {
+		for r := lo; r <= hi; r += stride {
+			add(r)
+			if stride == 1 && len(examples) == propertyExamples {
+				// Only the bounds matter once there are enough examples
+				count += int(hi - r)
+				last = hi
+				return
+			}
+		}
+	}
+	for _, r := range table.R16 {
+		addRange(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	for _, r := range table.R32 {
+		addRange(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	if count == 0 {
+		return ""
+	}
+
+	ranges := "1 range"
+	if spans != 1 {
+		ranges = fmt.Sprintf("%d ranges", spans)
+	}
+	description := fmt.Sprintf("%d code points in %s from U+%04X to U+%04X", count, ranges, first, last)
+	if len(examples) > 0 {
+		description += ", e.g. " + strings.Join(examples, " ")
+	}
+	return description
+}
+
+// explainUnicodeProperty explains a \p or \P escape as the flavor reads it,
+// with what the property covers when the flavor and Go agree on its name
+func explainUnicodeProperty(sequence, flavor string) string {
+	end := propertyEscapeEnd(sequence, 0)
+	if end != len(sequence) {
+		return "Invalid unicode property"
+	}
+
+	negated := sequence[1] == 'P'
+	name := strings.Trim(sequence[2:], "{}")
+	if strings.HasPrefix(name, "^") && flavor != "js" {
+		negated = !negated
+		name = name[1:]
+	}
+
+	explanation := fmt.Sprintf("Matches a character with the unicode property '%s'", name)
+	if negated {
+		explanation = fmt.Sprintf("Matches a character without the unicode property '%s'", name)
+	}
+
+	table, description := unicodeProperty(name, flavor)
+	if table == nil {
+		return explanation
+	}
+	if coverage := describePropertyTable(table); coverage != "" {
+		description += ": " + coverage
+	}
+	return fmt.Sprintf("%s (%s)", explanation, description)
+}
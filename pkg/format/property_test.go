@@ -0,0 +1,51 @@
+package format
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestTokenizeRegex_UnicodeProperties(t *testing.T) {
+	want := []string{`\p{Greek}`, "+", `\pL`, `\P{Lu}`}
+	for _, f := range []RegexFormat{NewGoFormat(), NewPcreFormat(), NewJsFormat()} {
+		if got := f.TokenizeRegex(`\p{Greek}+\pL\P{Lu}`); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s TokenizeRegex() = %q, want %q", f.Name(), got, want)
+		}
+	}
+}
+
+func TestExplainUnicodeProperty(t *testing.T) {
+	tests := []struct {
+		format RegexFormat
+		token  string
+		want   string
+	}{
+		{NewGoFormat(), `\p{Greek}`, "Matches a character with the unicode property 'Greek' (the Greek script: "},
+		{NewGoFormat(), `\pN`, "Matches a character with the unicode property 'N' (numbers: "},
+		{NewGoFormat(), `\P{Lu}`, "Matches a character without the unicode property 'Lu' (uppercase letters: "},
+		{NewGoFormat(), `\p{^Lu}`, "Matches a character without the unicode property 'Lu' (uppercase letters: "},
+		{NewPcreFormat(), `\p{White_Space}`, "Matches a character with the unicode property 'White_Space' (the White Space property: "},
+		{NewJsFormat(), `\p{Script=Han}`, "Matches a character with the unicode property 'Script=Han' (the Han script: "},
+		{NewJsFormat(), `\pL`, "Invalid unicode property"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.format.ExplainToken(tt.token); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("%s ExplainToken(%q) = %q, want prefix %q", tt.format.Name(), tt.token, got, tt.want)
+		}
+	}
+
+	// Go's regexp package only knows categories and scripts
+	if got, want := NewGoFormat().ExplainToken(`\p{White_Space}`), "Matches a character with the unicode property 'White_Space'"; got != want {
+		t.Errorf("ExplainToken(White_Space) = %q, want %q", got, want)
+	}
+}
+
+func TestDescribePropertyTable(t *testing.T) {
+	ascii := &unicode.RangeTable{R16: []unicode.Range16{{Lo: 0x30, Hi: 0x39, Stride: 1}, {Lo: 0x41, Hi: 0x45, Stride: 2}}}
+	if got, want := describePropertyTable(ascii), "13 code points in 4 ranges from U+0030 to U+0045, e.g. 0 1 2 3 4"; got != want {
+		t.Errorf("describePropertyTable() = %q, want %q", got, want)
+	}
+}
@@ -38,15 +38,35 @@ func (p *PythonFormat) HasFeature(feature string) bool {
 
 // TokenizeRegex breaks a regex pattern into meaningful tokens
 func (p *PythonFormat) TokenizeRegex(pattern string) []string {
-	var tokens []string
-	var currentToken strings.Builder
+	return p.TokenizeRegexWithFlags(pattern, "")
+}
+
+// TokenizeRegexWithFlags tokenizes a pattern compiled with flags such as
+// re.X. In verbose mode, whether from the x flag or a leading (?x),
+// whitespace outside classes and escapes is skipped and each # comment is a
+// token of its own.
+func (p *PythonFormat) TokenizeRegexWithFlags(pattern, flags string) []string {
+	return tokenizeSpans(p, pattern, flags)
+}
+
+// AppendTokenSpans appends the spans of the tokens of a pattern compiled
+// with flags to dst, as TokenizeRegexWithFlags tokenizes it
+func (p *PythonFormat) AppendTokenSpans(dst []Span, pattern, flags string) []Span {
+	t := newSpanBuilder(dst)
+	p.tokenizeFrom(&t, pattern, flags, 0)
+	return t.done()
+}
+
+// tokenizeFrom adds the tokens of a pattern to t, skipping the part of its
+// body before from, which has to be where a token starts
+func (p *PythonFormat) tokenizeFrom(t *spanBuilder, pattern, flags string, from int) {
+	verbose := strings.ContainsRune(flags, 'x')
 	
-	// Check for raw string marker and flags
-	if len(pattern) > 0 && (pattern[0] == 'r' || pattern[0] == 'R') {
-		if len(pattern) > 1 && (pattern[1] == '"' || pattern[1] == '\'') {
-			tokens = append(tokens, pattern[0:2])
-			pattern = pattern[2:]
-		}
+	// Check for a string prefix such as r" or rb' and drop the closing quote
+	if prefix := PythonStringPrefix(pattern); prefix != "" {
+		t.add(0, len(prefix))
+		t.base = len(prefix)
+		pattern = strings.TrimSuffix(pattern[len(prefix):], prefix[len(prefix)-1:])
 	}
 	
 	// Handle inline flags at the beginning
@@ -63,25 +83,38 @@ func (p *PythonFormat) TokenizeRegex(pattern string) []string {
 				}
 			}
 			if isFlag {
-				tokens = append(tokens, pattern[0:flagEnd+1])
+				t.add(0, flagEnd+1)
+				verbose = verbose || strings.ContainsRune(pattern[2:flagEnd], 'x')
+				t.base += flagEnd + 1
 				pattern = pattern[flagEnd+1:]
 			}
 		}
 	}
 	
-	for i := 0; i < len(pattern); i++ {
+	for i := t.bodyFrom(from); i < len(pattern); i++ {
 		char := pattern[i]
 		
+		// Skip insignificant whitespace and keep comments in verbose mode
+		if verbose && (strings.IndexByte(" \t\n\r\f\v", char) >= 0 || char == '#') {
+			t.flush()
+			if char == '#' {
+				end := strings.IndexByte(pattern[i:], '\n')
+				if end < 0 {
+					end = len(pattern) - i
+				}
+				t.add(i, i+len(strings.TrimRight(pattern[i:i+end], " \t\r")))
+				i += end - 1
+			}
+			continue
+		}
+		
 		// Handle character classes
 		if char == '[' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
+			t.flush()
 			
 			end := FindClosingBracket(pattern, i)
 			if end > i {
-				tokens = append(tokens, pattern[i:end+1])
+				t.add(i, end+1)
 				i = end
 				continue
 			}
@@ -89,9 +122,12 @@ func (p *PythonFormat) TokenizeRegex(pattern string) []string {
 		
 		// Handle special escape sequences
 		if char == '\\' && i+1 < len(pattern) {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
+			t.flush()
+			if end := octalEscapeEnd(pattern, i); end > i+2 {
+				// \0oo or \ooo - a character by its octal code
+				t.add(i, end)
+				i = end - 1
+				continue
 			}
 			
 			// Python has some multi-character escape sequences
@@ -107,14 +143,14 @@ func (p *PythonFormat) TokenizeRegex(pattern string) []string {
 						break
 					}
 				}
-				tokens = append(tokens, pattern[i:hexEnd])
+				t.add(i, hexEnd)
 				i = hexEnd - 1
 				continue
 			} else if i+2 < len(pattern) && pattern[i+1] == 'u' {
 				// \uxxxx - exactly 4 hex digits
 				if i+6 <= len(pattern) && isHexDigit(pattern[i+2]) && isHexDigit(pattern[i+3]) && 
 				   isHexDigit(pattern[i+4]) && isHexDigit(pattern[i+5]) {
-					tokens = append(tokens, pattern[i:i+6])
+					t.add(i, i+6)
 					i += 5
 					continue
 				}
@@ -123,7 +159,7 @@ func (p *PythonFormat) TokenizeRegex(pattern string) []string {
 				if i+10 <= len(pattern) && isHexDigit(pattern[i+2]) && isHexDigit(pattern[i+3]) && 
 				   isHexDigit(pattern[i+4]) && isHexDigit(pattern[i+5]) && isHexDigit(pattern[i+6]) && 
 				   isHexDigit(pattern[i+7]) && isHexDigit(pattern[i+8]) && isHexDigit(pattern[i+9]) {
-					tokens = append(tokens, pattern[i:i+10])
+					t.add(i, i+10)
 					i += 9
 					continue
 				}
@@ -131,12 +167,12 @@ func (p *PythonFormat) TokenizeRegex(pattern string) []string {
 				// \N{name} - Unicode character by name
 				end := strings.IndexByte(pattern[i+3:], '}')
 				if end >= 0 {
-					tokens = append(tokens, pattern[i:i+end+4])
+					t.add(i, i+end+4)
 					i += end + 3
 					continue
 				}
 			} else {
-				tokens = append(tokens, pattern[i:i+2])
+				t.add(i, i+2)
 				i++
 				continue
 			}
@@ -144,14 +180,11 @@ func (p *PythonFormat) TokenizeRegex(pattern string) []string {
 		
 		// Handle curly brace quantifiers
 		if char == '{' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
+			t.flush()
 			
 			end := FindClosingCurlyBrace(pattern, i)
 			if end > i {
-				tokens = append(tokens, pattern[i:end+1])
+				t.add(i, end+1)
 				i = end
 				continue
 			}
@@ -159,60 +192,61 @@ func (p *PythonFormat) TokenizeRegex(pattern string) []string {
 		
 		// Handle simple quantifiers
 		if char == '*' || char == '+' || char == '?' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
+			t.flush()
 			
 			// Check for non-greedy quantifier
 			if i+1 < len(pattern) && pattern[i+1] == '?' {
-				tokens = append(tokens, string(char)+"?")
+				t.add(i, i+2)
 				i++
 			} else {
-				tokens = append(tokens, string(char))
+				t.add(i, i+1)
 			}
 			continue
 		}
 		
 		// Handle groups
 		if char == '(' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
+			t.flush()
+			
+			// (?i) or (?m-s:...) - inline modifiers, alone or scoped to a group
+			if end := inlineModifierEnd(pattern, i, pythonModifiers); end > 0 {
+				t.add(i, end)
+				i = end - 1
+				continue
 			}
 			
 			// Check for non-capturing and other special groups
 			if i+2 < len(pattern) && pattern[i+1] == '?' {
 				switch pattern[i+2] {
 				case ':': // (?:pattern) - non-capturing group
-					tokens = append(tokens, "(?:")
+					t.add(i, i+3)
 					i += 2
 				case '=': // (?=pattern) - positive lookahead
-					tokens = append(tokens, "(?=")
+					t.add(i, i+3)
 					i += 2
 				case '!': // (?!pattern) - negative lookahead
-					tokens = append(tokens, "(?!")
+					t.add(i, i+3)
 					i += 2
 				case '<': // Could be lookbehind or named capture
 					if i+3 < len(pattern) {
 						if pattern[i+3] == '=' { // (?<=pattern) - positive lookbehind
-							tokens = append(tokens, "(?<=")
+							t.add(i, i+4)
 							i += 3
 						} else if pattern[i+3] == '!' { // (?<!pattern) - negative lookbehind
-							tokens = append(tokens, "(?<!")
+							t.add(i, i+4)
 							i += 3
 						} else { // (?<name>pattern) - named capturing group
 							endName := strings.IndexByte(pattern[i+3:], '>')
 							if endName >= 0 {
 								endName += i + 3
-								tokens = append(tokens, pattern[i:endName+1])
+								t.add(i, endName+1)
 								i = endName
 							} else {
-								tokens = append(tokens, string(char))
+								t.add(i, i+1)
 							}
 						}
 					} else {
-						tokens = append(tokens, string(char))
+						t.add(i, i+1)
 					}
 				case 'P': // Python specific named group syntaxes
 					if i+3 < len(pattern) {
@@ -220,7 +254,7 @@ func (p *PythonFormat) TokenizeRegex(pattern string) []string {
 							endName := strings.IndexByte(pattern[i+4:], '>')
 							if endName >= 0 {
 								endName += i + 4
-								tokens = append(tokens, pattern[i:endName+1])
+								t.add(i, endName+1)
 								i = endName
 								continue
 							}
@@ -231,80 +265,65 @@ func (p *PythonFormat) TokenizeRegex(pattern string) []string {
 								j++
 							}
 							if j < len(pattern) {
-								tokens = append(tokens, pattern[i:j+1])
+								t.add(i, j+1)
 								i = j
 								continue
 							}
 						}
 					}
-					tokens = append(tokens, string(char))
+					t.add(i, i+1)
 				default:
-					tokens = append(tokens, string(char))
+					t.add(i, i+1)
 				}
 				continue
 			} else {
-				tokens = append(tokens, string(char))
+				t.add(i, i+1)
 				continue
 			}
 		}
 		
 		if char == ')' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle alternation
 		if char == '|' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle anchors
 		if char == '^' || char == '$' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Handle dot
 		if char == '.' {
-			if currentToken.Len() > 0 {
-				tokens = append(tokens, currentToken.String())
-				currentToken.Reset()
-			}
-			tokens = append(tokens, string(char))
+			t.flush()
+			t.add(i, i+1)
 			continue
 		}
 		
 		// Default case: add to current token
-		currentToken.WriteByte(char)
+		t.literal(i)
 	}
-	
-	// Add the last token if any
-	if currentToken.Len() > 0 {
-		tokens = append(tokens, currentToken.String())
-	}
-	
-	return tokens
 }
 
 // ExplainToken provides a human-readable explanation for a regex token
 func (p *PythonFormat) ExplainToken(token string) string {
 	switch {
-	case strings.HasPrefix(token, "r'") || strings.HasPrefix(token, "r\"") || 
-	     strings.HasPrefix(token, "R'") || strings.HasPrefix(token, "R\""):
-		return "Raw string marker - backslashes are treated literally"
+	case strings.HasPrefix(token, "(?") && inlineModifierEnd(token, 0, pythonModifiers) == len(token) && (strings.HasSuffix(token, ":") || strings.Contains(token, "-")):
+		return explainModifiers(token)
+	case token != "" && PythonStringPrefix(token) == token:
+		return explainPythonStringPrefix(token)
+	case strings.HasPrefix(token, "(?P=") && strings.HasSuffix(token, ")"):
+		name := token[4 : len(token)-1]
+		return fmt.Sprintf("Backreference to the named group '%s'", name)
 	case strings.HasPrefix(token, "(?") && strings.HasSuffix(token, ")") && len(token) > 3:
 		// Check for inline flags
 		isFlag := true
@@ -320,9 +339,9 @@ func (p *PythonFormat) ExplainToken(token string) string {
 			return explainPythonFlags(token[2 : len(token)-1])
 		}
 	case token == "^":
-		return "Matches the start of a line"
+		return "Matches only at the start of the input"
 	case token == "$":
-		return "Matches the end of a line"
+		return "Matches at the end of the input, or just before a newline that ends it"
 	case token == ".":
 		return "Matches any single character except newline"
 	case token == "*":
@@ -356,14 +375,8 @@ func (p *PythonFormat) ExplainToken(token string) string {
 	case strings.HasPrefix(token, "(?P<") && strings.HasSuffix(token, ">"):
 		name := token[4 : len(token)-1]
 		return fmt.Sprintf("Start of a named capturing group called '%s'", name)
-	case strings.HasPrefix(token, "(?P=") && strings.HasSuffix(token, ")"):
-		name := token[4 : len(token)-1]
-		return fmt.Sprintf("Backreference to the named group '%s'", name)
 	case strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]"):
-		if len(token) > 2 && token[1] == '^' {
-			return fmt.Sprintf("Matches any character NOT in the set: %s", token[2:len(token)-1])
-		}
-		return fmt.Sprintf("Matches any character in the set: %s", token[1:len(token)-1])
+		return explainClass(token, "python")
 	case strings.HasPrefix(token, "\\"):
 		return explainPythonEscapeSequence(token)
 	case strings.HasPrefix(token, "{") && strings.HasSuffix(token, "}"):
@@ -379,10 +392,7 @@ func (p *PythonFormat) ExplainToken(token string) string {
 		}
 		return fmt.Sprintf("Matches exactly %s occurrences of the preceding element", content)
 	default:
-		if len(token) == 1 {
-			return fmt.Sprintf("Matches the character '%s' literally", token)
-		}
-		return fmt.Sprintf("Matches the string '%s' literally", token)
+		return explainLiteral(token)
 	}
 	
 	return fmt.Sprintf("Unknown token: %s", token)
@@ -424,6 +434,9 @@ func explainPythonEscapeSequence(sequence string) string {
 	if len(sequence) < 2 {
 		return "Invalid escape sequence"
 	}
+	if len(sequence) > 2 && octalEscapeEnd(sequence, 0) == len(sequence) {
+		return explainOctalEscape(sequence)
+	}
 	
 	switch sequence[1] {
 	case 'A':
@@ -471,20 +484,20 @@ func explainPythonEscapeSequence(sequence string) string {
 		return "Invalid named backreference"
 	case 'x':
 		if len(sequence) >= 4 && isHexDigit(sequence[2]) && isHexDigit(sequence[3]) {
-			return fmt.Sprintf("Matches the character with hex code %s", sequence[2:4])
+			return withCodePoint(fmt.Sprintf("Matches the character with hex code %s", sequence[2:4]), sequence)
 		}
 		return "Invalid hexadecimal escape sequence"
 	case 'u':
 		if len(sequence) >= 6 && isHexDigit(sequence[2]) && isHexDigit(sequence[3]) && 
 		   isHexDigit(sequence[4]) && isHexDigit(sequence[5]) {
-			return fmt.Sprintf("Matches the Unicode character U+%s", sequence[2:6])
+			return withCodePoint(fmt.Sprintf("Matches the Unicode character U+%s", sequence[2:6]), sequence)
 		}
 		return "Invalid Unicode escape sequence"
 	case 'U':
 		if len(sequence) >= 10 && isHexDigit(sequence[2]) && isHexDigit(sequence[3]) && 
 		   isHexDigit(sequence[4]) && isHexDigit(sequence[5]) && isHexDigit(sequence[6]) && 
 		   isHexDigit(sequence[7]) && isHexDigit(sequence[8]) && isHexDigit(sequence[9]) {
-			return fmt.Sprintf("Matches the Unicode character U+%s", sequence[2:10])
+			return withCodePoint(fmt.Sprintf("Matches the Unicode character U+%s", sequence[2:10]), sequence)
 		}
 		return "Invalid extended Unicode escape sequence"
 	case 'N':
@@ -499,4 +512,52 @@ func explainPythonEscapeSequence(sequence string) string {
 	default:
 		return fmt.Sprintf("Matches the character '%c' literally", sequence[1])
 	}
-} 
\ No newline at end of file
+} 
+
+// PythonStringPrefix returns the string literal prefix and opening quote a
+// Python pattern starts with, such as r" for a raw string or rb' for raw
+// bytes, or "" if it doesn't start with one
+func PythonStringPrefix(pattern string) string {
+	raw, bytes := false, false
+	for i := 0; i < len(pattern) && i < 3; i++ {
+		switch pattern[i] {
+		case 'r', 'R':
+			if raw {
+				return ""
+			}
+			raw = true
+		case 'b', 'B':
+			if bytes {
+				return ""
+			}
+			bytes = true
+		case '"', '\'':
+			if i == 0 {
+				return ""
+			}
+			return pattern[:i+1]
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// IsBytesPattern reports whether a Python pattern is a bytes literal such
+// as b"..." or rb"...", which matches bytes rather than str
+func IsBytesPattern(pattern string) bool {
+	return strings.ContainsAny(PythonStringPrefix(pattern), "bB")
+}
+
+// explainPythonStringPrefix explains a string prefix such as r" or rb"
+func explainPythonStringPrefix(prefix string) string {
+	raw := strings.ContainsAny(prefix, "rR")
+	if !IsBytesPattern(prefix) {
+		return "Raw string marker - backslashes are treated literally"
+	}
+	bytes := "the pattern matches bytes, not str, so \\w, \\d, \\s and \\b only match ASCII characters"
+	if raw {
+		return "Raw bytes string marker - backslashes are treated literally and " + bytes
+	}
+	return "Bytes string marker - " + bytes
+}
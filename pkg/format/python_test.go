@@ -0,0 +1,79 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPythonFormat_Verbose(t *testing.T) {
+	tests := []struct {
+		pattern string
+		flags   string
+		want    []string
+	}{
+		{"\\d+  # digits\n[ #]? \\  # space", "x", []string{`\d`, "+", "# digits", "[ #]", "?", `\ `, "# space"}},
+		{"(?x) a b # c", "", []string{"(?x)", "a", "b", "# c"}},
+		{"a b#c", "", []string{"a b#c"}},
+		{"a b#c", "i", []string{"a b#c"}},
+	}
+
+	for _, tt := range tests {
+		got := TokenizeWithFlags(NewPythonFormat(), tt.pattern, tt.flags)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("TokenizeWithFlags(%q, %q) = %q, want %q", tt.pattern, tt.flags, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeWithFlags_IgnoredByOtherFormats(t *testing.T) {
+	if got, want := TokenizeWithFlags(NewGoFormat(), "a b", "x"), []string{"a b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeWithFlags() = %q, want %q", got, want)
+	}
+}
+
+func TestPythonStringPrefix(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+		bytes   bool
+	}{
+		{`r"\d"`, `r"`, false},
+		{`rb'\d'`, `rb'`, true},
+		{`Br"\d"`, `Br"`, true},
+		{`b"abc"`, `b"`, true},
+		{`rr"a"`, "", false},
+		{`"a"`, "", false},
+		{`ab"`, "", false},
+		{"rb", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := PythonStringPrefix(tt.pattern); got != tt.want {
+			t.Errorf("PythonStringPrefix(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+		if got := IsBytesPattern(tt.pattern); got != tt.bytes {
+			t.Errorf("IsBytesPattern(%q) = %v, want %v", tt.pattern, got, tt.bytes)
+		}
+	}
+}
+
+func TestPythonFormat_StringPrefixes(t *testing.T) {
+	format := NewPythonFormat()
+	if got, want := format.TokenizeRegex(`rb"\w+"`), []string{`rb"`, `\w`, "+"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeRegex() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{`r"`, "Raw string marker - backslashes are treated literally"},
+		{`b'`, `Bytes string marker - the pattern matches bytes, not str, so \w, \d, \s and \b only match ASCII characters`},
+		{`rb"`, `Raw bytes string marker - backslashes are treated literally and the pattern matches bytes, not str, so \w, \d, \s and \b only match ASCII characters`},
+	}
+	for _, tt := range tests {
+		if got := format.ExplainToken(tt.token); got != tt.want {
+			t.Errorf("ExplainToken(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+}
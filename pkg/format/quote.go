@@ -0,0 +1,43 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// quotedSpanEnd returns the end of the \Q...\E quoted span starting at
+// pattern[i], or -1 if there isn't one. A span without \E runs to the end
+// of the pattern.
+func quotedSpanEnd(pattern string, i int) int {
+	if !strings.HasPrefix(pattern[i:], `\Q`) {
+		return -1
+	}
+	end := strings.Index(pattern[i+2:], `\E`)
+	if end < 0 {
+		return len(pattern)
+	}
+	return i + 2 + end + 2
+}
+
+// QuotedText returns the literal text of a \Q...\E quoted span token, such
+// as a.b for \Qa.b\E. It reports false for any other token.
+func QuotedText(token string) (string, bool) {
+	if len(token) <= 2 || quotedSpanEnd(token, 0) != len(token) {
+		return "", false
+	}
+	return strings.TrimSuffix(token[2:], `\E`), true
+}
+
+// explainQuotedSpan explains a \Q...\E quoted span, whose text is matched
+// literally with no regex syntax in it
+func explainQuotedSpan(token string) string {
+	text, _ := QuotedText(token)
+	if text == "" {
+		return "An empty quoted sequence - matches nothing extra"
+	}
+	explanation := strings.Replace(explainLiteral(text), " literally", " literally, even its special characters", 1)
+	if !strings.HasSuffix(token, `\E`) {
+		return fmt.Sprintf("%s (quoted with \\Q until the end of the pattern)", explanation)
+	}
+	return fmt.Sprintf("%s (quoted with \\Q...\\E)", explanation)
+}
@@ -0,0 +1,66 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeRegex_QuotedSpans(t *testing.T) {
+	tests := []struct {
+		format  RegexFormat
+		pattern string
+		want    []string
+	}{
+		{NewPcreFormat(), `^\Qa.b*(c)\E+$`, []string{"^", `\Qa.b*(c)\E`, "+", "$"}},
+		{NewGoFormat(), `x\Q[y]\E\d`, []string{"x", `\Q[y]\E`, `\d`}},
+		{NewGoFormat(), `\Q1+1`, []string{`\Q1+1`}},
+		{NewPcreFormat(), `a\Q`, []string{"a", `\Q`}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.format.TokenizeRegex(tt.pattern); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s TokenizeRegex(%q) = %q, want %q", tt.format.Name(), tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestQuotedText(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+		ok    bool
+	}{
+		{`\Qa.b\E`, "a.b", true},
+		{`\Q1+1`, "1+1", true},
+		{`\Q\E`, "", true},
+		{`\Q`, "", false},
+		{`\Qa\Eb`, "", false},
+		{`\d`, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := QuotedText(tt.token)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("QuotedText(%q) = %q, %v, want %q, %v", tt.token, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestExplainToken_QuotedSpans(t *testing.T) {
+	tests := []struct {
+		format RegexFormat
+		token  string
+		want   string
+	}{
+		{NewPcreFormat(), `\Qa.b\E`, `Matches the string 'a.b' literally, even its special characters (quoted with \Q...\E)`},
+		{NewGoFormat(), `\Q*`, `Matches the character '*' literally, even its special characters (quoted with \Q until the end of the pattern)`},
+		{NewGoFormat(), `\Q\E`, "An empty quoted sequence - matches nothing extra"},
+		{NewPcreFormat(), `\Q`, `Start of a quoted sequence (everything until \E is treated as a literal)`},
+	}
+
+	for _, tt := range tests {
+		if got := tt.format.ExplainToken(tt.token); got != tt.want {
+			t.Errorf("%s ExplainToken(%q) = %q, want %q", tt.format.Name(), tt.token, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package format
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// subroutineCallPattern matches a recursion or subroutine call group:
+// (?R), (?0), (?1), (?-1), (?+1), (?&name) or (?P>name)
+var subroutineCallPattern = regexp.MustCompile(`^\(\?(?:R|[+-]?[0-9]+|&\w+|P>\w+)\)`)
+
+// gEscapePattern matches a \g escape: a subroutine call such as \g<1> or
+// \g'name', or a backreference such as \g{1}, \g2 or \g-1
+var gEscapePattern = regexp.MustCompile(`^\\g(?:<[+-]?\w+>|'[+-]?\w+'|\{-?\w+\}|-?[0-9]+)`)
+
+// namedBackrefPattern matches a named backreference: \k<name>, or in PCRE
+// also \k'name' and \k{name}
+var namedBackrefPattern = regexp.MustCompile(`^\\k(?:<\w+>|'\w+'|\{\w+\})`)
+
+// pythonBackrefPattern matches a named backreference in Python's syntax,
+// (?P=name), which PCRE accepts as well
+var pythonBackrefPattern = regexp.MustCompile(`^\(\?P=\w+\)`)
+
+// subroutineCallEnd returns the end of the recursion or subroutine call
+// group starting at pattern[i], or -1 if there isn't one
+func subroutineCallEnd(pattern string, i int) int {
+	if loc := subroutineCallPattern.FindStringIndex(pattern[i:]); loc != nil {
+		return i + loc[1]
+	}
+	return -1
+}
+
+// gEscapeEnd returns the end of the \g escape starting at pattern[i], or -1
+// if there isn't one
+func gEscapeEnd(pattern string, i int) int {
+	if loc := gEscapePattern.FindStringIndex(pattern[i:]); loc != nil {
+		return i + loc[1]
+	}
+	return -1
+}
+
+// namedBackrefEnd returns the end of the named backreference starting at
+// pattern[i], or -1 if there isn't one
+func namedBackrefEnd(pattern string, i int) int {
+	if loc := namedBackrefPattern.FindStringIndex(pattern[i:]); loc != nil {
+		return i + loc[1]
+	}
+	return -1
+}
+
+// pythonBackrefEnd returns the end of the (?P=name) backreference
+// starting at pattern[i], or -1 if there isn't one
+func pythonBackrefEnd(pattern string, i int) int {
+	if loc := pythonBackrefPattern.FindStringIndex(pattern[i:]); loc != nil {
+		return i + loc[1]
+	}
+	return -1
+}
+
+// BackreferenceTarget returns the group a backreference matches the text
+// of again: a group number, a relative number such as -1, or a group name.
+// It reports false for any other token.
+func BackreferenceTarget(token string) (string, bool) {
+	switch {
+	case len(token) > 1 && token[0] == '\\' && token[1] != '0' && isDigits(token[1:]) && octalEscapeEnd(token, 0) != len(token):
+		return token[1:], true
+	case namedBackrefEnd(token, 0) == len(token):
+		return token[3 : len(token)-1], true
+	case gEscapeEnd(token, 0) == len(token) && token[2] != '<' && token[2] != '\'':
+		return strings.Trim(token[2:], "{}"), true
+	case pythonBackrefEnd(token, 0) == len(token):
+		return token[4 : len(token)-1], true
+	}
+	return "", false
+}
+
+// SubroutineTarget returns the group a recursion or subroutine call
+// re-enters: "0" for the whole pattern, a group number, a relative number
+// such as -1 or +2, or a group name. It reports false for any other token.
+func SubroutineTarget(token string) (string, bool) {
+	switch {
+	case subroutineCallEnd(token, 0) == len(token):
+		target := strings.TrimSuffix(token[2:], ")")
+		target = strings.TrimPrefix(strings.TrimPrefix(target, "&"), "P>")
+		if target == "R" {
+			target = "0"
+		}
+		return target, true
+	case gEscapeEnd(token, 0) == len(token) && (token[2] == '<' || token[2] == '\''):
+		return token[3 : len(token)-1], true
+	}
+	return "", false
+}
+
+// describeGroupReference names the group a subroutine call or backreference
+// refers to
+func describeGroupReference(target string) string {
+	switch {
+	case target == "0":
+		return "the whole pattern"
+	case target[0] == '-' || target[0] == '+':
+		return fmt.Sprintf("the capturing group %s relative to this one", target)
+	case isDigits(target):
+		return "capturing group " + target
+	}
+	return fmt.Sprintf("the group named '%s'", target)
+}
+
+// explainSubroutineCall explains a recursion or subroutine call, and how it
+// differs from a backreference to the same group
+func explainSubroutineCall(token string) string {
+	target, ok := SubroutineTarget(token)
+	if !ok {
+		return "Invalid subroutine call"
+	}
+	if target == "0" {
+		return "Recursion - matches the whole pattern again at this point, so it can match nested structures. " +
+			"Unlike a backreference, it matches the pattern again rather than repeating text already matched"
+	}
+	return fmt.Sprintf("Subroutine call - matches the pattern of %s again at this point. "+
+		"Unlike a backreference, it can match different text from what the group captured", describeGroupReference(target))
+}
+
+// explainGEscape explains a \g escape, which is either a subroutine call or
+// a backreference depending on its brackets
+func explainGEscape(sequence string) string {
+	if gEscapeEnd(sequence, 0) != len(sequence) {
+		return "Invalid \\g escape"
+	}
+	if _, ok := SubroutineTarget(sequence); ok {
+		return explainSubroutineCall(sequence)
+	}
+	target := strings.Trim(sequence[2:], "{}")
+	return fmt.Sprintf("Backreference to %s - matches the same text it captured", describeGroupReference(target))
+}
@@ -0,0 +1,102 @@
+package format
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenizeRegex_SubroutineCalls(t *testing.T) {
+	got := NewPcreFormat().TokenizeRegex(`(a(?R)?b)(?1)(?&n)(?P>n)(?-1)\g<1>\g'n'\g{1}\g-1`)
+	want := []string{"(", "a", "(?R)", "?", "b", ")", "(?1)", "(?&n)", "(?P>n)", "(?-1)", `\g<1>`, `\g'n'`, `\g{1}`, `\g-1`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeRegex() = %q, want %q", got, want)
+	}
+}
+
+func TestSubroutineTarget(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+		ok    bool
+	}{
+		{"(?R)", "0", true},
+		{"(?0)", "0", true},
+		{"(?12)", "12", true},
+		{"(?+1)", "+1", true},
+		{"(?&name)", "name", true},
+		{"(?P>name)", "name", true},
+		{`\g<-1>`, "-1", true},
+		{`\g'name'`, "name", true},
+		{`\g{1}`, "", false},
+		{"(?:", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := SubroutineTarget(tt.token)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("SubroutineTarget(%q) = %q, %v, want %q, %v", tt.token, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBackreferenceTarget(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+		ok    bool
+	}{
+		{`\1`, "1", true},
+		{`\12`, "12", true},
+		{`\101`, "", false},
+		{`\k<name>`, "name", true},
+		{`\k{name}`, "name", true},
+		{`\g{-1}`, "-1", true},
+		{`\g2`, "2", true},
+		{`\g<1>`, "", false},
+		{"(?P=name)", "name", true},
+		{`\d`, "", false},
+	}
+
+	for _, tt := range tests {
+		if got, ok := BackreferenceTarget(tt.token); got != tt.want || ok != tt.ok {
+			t.Errorf("BackreferenceTarget(%q) = %q, %v, want %q, %v", tt.token, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestTokenizeRegex_NamedBackreferences(t *testing.T) {
+	got := NewPcreFormat().TokenizeRegex(`(?<n>a)\k<n>\k'n'\k{n}`)
+	want := []string{"(?<n>", "a", ")", `\k<n>`, `\k'n'`, `\k{n}`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeRegex() = %q, want %q", got, want)
+	}
+	if got := NewJsFormat().TokenizeRegex(`(?<n>a)\k<n>`); got[len(got)-1] != `\k<n>` {
+		t.Errorf("TokenizeRegex() = %q, want \\k<n> as one token", got)
+	}
+	if got := NewPythonFormat().ExplainToken("(?P=n)"); got != "Backreference to the named group 'n'" {
+		t.Errorf("ExplainToken(%q) = %q, want a named backreference", "(?P=n)", got)
+	}
+}
+
+func TestExplainToken_SubroutineCalls(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"(?R)", "Recursion - matches the whole pattern again"},
+		{"(?2)", "Subroutine call - matches the pattern of capturing group 2 again"},
+		{`\g<name>`, "Subroutine call - matches the pattern of the group named 'name' again"},
+		{`\g{2}`, "Backreference to capturing group 2 - matches the same text it captured"},
+		{`\g-1`, "Backreference to the capturing group -1 relative to this one"},
+	}
+
+	for _, tt := range tests {
+		if got := NewPcreFormat().ExplainToken(tt.token); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("ExplainToken(%q) = %q, want it to start with %q", tt.token, got, tt.want)
+		}
+	}
+	if got := NewPcreFormat().ExplainToken("(?1)"); !strings.Contains(got, "Unlike a backreference") {
+		t.Errorf("ExplainToken(%q) = %q, want it to contrast it with a backreference", "(?1)", got)
+	}
+}
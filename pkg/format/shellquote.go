@@ -0,0 +1,276 @@
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Shells are the shells whose quoting UnquoteShell removes
+var Shells = []string{"bash", "powershell"}
+
+// ansiCEscapes maps the single-character escapes of bash's $'...' quoting
+// to the characters they stand for
+var ansiCEscapes = map[byte]string{
+	'a': "\a", 'b': "\b", 'e': "\x1b", 'E': "\x1b", 'f': "\f", 'n': "\n",
+	'r': "\r", 't': "\t", 'v': "\v", '\\': "\\", '\'': "'", '"': "\"", '?': "?",
+}
+
+// powerShellEscapes maps PowerShell's backtick escapes to the characters
+// they stand for
+var powerShellEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 'e': "\x1b", 'f': "\f", 'n': "\n",
+	'r': "\r", 't': "\t", 'v': "\v",
+}
+
+// UnquoteShell returns the argument a shell passes for source, a pattern
+// as it's written on a command line, removing the shell's layer of quoting
+// so '\d+' or "\\\$" become \d+ and \$. Source must be a single word, and
+// variables and command substitutions, which depend on the shell they ran
+// in, are rejected rather than guessed at.
+func UnquoteShell(source, shell string) (string, error) {
+	source = strings.TrimSpace(source)
+	switch shell {
+	case "bash":
+		return unquoteBash(source)
+	case "powershell":
+		return unquotePowerShell(source)
+	}
+	return "", fmt.Errorf("unsupported shell %q, expected one of %s", shell, strings.Join(Shells, ", "))
+}
+
+// unquoteBash removes bash's single, double and $'...' quotes and
+// backslash escapes
+func unquoteBash(source string) (string, error) {
+	var word strings.Builder
+	for i := 0; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == '\\':
+			// A backslash escapes the next character, and one before a line
+			// break continues the line
+			if i+1 == len(source) {
+				return "", fmt.Errorf("the word ends with an escaping backslash")
+			}
+			if source[i+1] != '\n' {
+				word.WriteByte(source[i+1])
+			}
+			i++
+		case c == '\'':
+			end := strings.IndexByte(source[i+1:], '\'')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(source[i+1 : i+1+end])
+			i += end + 1
+		case c == '$' && strings.HasPrefix(source[i:], "$'"):
+			end, err := readAnsiCString(source, i+2, &word)
+			if err != nil {
+				return "", err
+			}
+			i = end
+		case c == '"':
+			end, err := readBashDoubleQuoted(source, i+1, &word)
+			if err != nil {
+				return "", err
+			}
+			i = end
+		case c == '`' || c == '$' && startsExpansion(source[i+1:]):
+			return "", fmt.Errorf("%s expands in the shell and can't be unquoted", expansionAt(source, i))
+		case c == ' ' || c == '\t' || c == '\n':
+			return "", fmt.Errorf("the text is more than one shell word; quote the pattern as a whole")
+		default:
+			word.WriteByte(c)
+		}
+	}
+	return word.String(), nil
+}
+
+// readBashDoubleQuoted reads a double-quoted string from source[start:],
+// where a backslash only escapes $, `, ", \ and line breaks, and returns
+// the index of the closing quote
+func readBashDoubleQuoted(source string, start int, word *strings.Builder) (int, error) {
+	for i := start; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == '"':
+			return i, nil
+		case c == '\\' && i+1 < len(source) && strings.IndexByte("$`\"\\\n", source[i+1]) >= 0:
+			if source[i+1] != '\n' {
+				word.WriteByte(source[i+1])
+			}
+			i++
+		case c == '`' || c == '$' && startsExpansion(source[i+1:]):
+			return 0, fmt.Errorf("%s expands in the shell and can't be unquoted", expansionAt(source, i))
+		default:
+			word.WriteByte(c)
+		}
+	}
+	return 0, fmt.Errorf("unterminated double quote")
+}
+
+// readAnsiCString reads a $'...' string from source[start:], whose
+// backslash escapes work as in C, and returns the index of the closing quote
+func readAnsiCString(source string, start int, word *strings.Builder) (int, error) {
+	for i := start; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == '\'':
+			return i, nil
+		case c != '\\':
+			word.WriteByte(c)
+			continue
+		case i+1 == len(source):
+			return 0, fmt.Errorf("unterminated $' quote")
+		}
+
+		if escaped, ok := ansiCEscapes[source[i+1]]; ok {
+			word.WriteString(escaped)
+			i++
+			continue
+		}
+		// Numeric escapes: \nnn in octal, \xHH, \uHHHH and \UHHHHHHHH
+		var digits string
+		base, size := 16, 0
+		switch source[i+1] {
+		case 'x':
+			size = 2
+		case 'u':
+			size = 4
+		case 'U':
+			size = 8
+		default:
+			base, size = 8, 3
+		}
+		from := i + 2
+		if base == 8 {
+			from = i + 1
+		}
+		for j := from; j < len(source) && len(digits) < size && isDigitIn(source[j], base); j++ {
+			digits += string(source[j])
+		}
+		if digits == "" {
+			// Unknown escapes keep their backslash
+			word.WriteByte(c)
+			continue
+		}
+		value, _ := strconv.ParseUint(digits, base, 32)
+		if base == 8 || source[i+1] == 'x' {
+			word.WriteByte(byte(value))
+		} else {
+			word.WriteRune(rune(value))
+		}
+		i = from + len(digits) - 1
+	}
+	return 0, fmt.Errorf("unterminated $' quote")
+}
+
+// unquotePowerShell removes PowerShell's single and double quotes and
+// backtick escapes
+func unquotePowerShell(source string) (string, error) {
+	var word strings.Builder
+	for i := 0; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == '`':
+			if i+1 == len(source) {
+				return "", fmt.Errorf("the word ends with an escaping backtick")
+			}
+			if source[i+1] != '\n' {
+				word.WriteByte(source[i+1])
+			}
+			i++
+		case c == '\'':
+			// Two single quotes in a row stand for one
+			i++
+			for ; i < len(source) && (source[i] != '\'' || strings.HasPrefix(source[i:], "''")); i++ {
+				if source[i] == '\'' {
+					i++
+				}
+				word.WriteByte(source[i])
+			}
+			if i == len(source) {
+				return "", fmt.Errorf("unterminated single quote")
+			}
+		case c == '"':
+			end, err := readPowerShellDoubleQuoted(source, i+1, &word)
+			if err != nil {
+				return "", err
+			}
+			i = end
+		case c == '$' && startsExpansion(source[i+1:]):
+			return "", fmt.Errorf("%s expands in the shell and can't be unquoted", expansionAt(source, i))
+		case c == ' ' || c == '\t' || c == '\n':
+			return "", fmt.Errorf("the text is more than one shell word; quote the pattern as a whole")
+		default:
+			word.WriteByte(c)
+		}
+	}
+	return word.String(), nil
+}
+
+// readPowerShellDoubleQuoted reads a double-quoted string from
+// source[start:], where backticks escape and two double quotes in a row
+// stand for one, and returns the index of the closing quote
+func readPowerShellDoubleQuoted(source string, start int, word *strings.Builder) (int, error) {
+	for i := start; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == '"' && strings.HasPrefix(source[i:], `""`):
+			word.WriteByte('"')
+			i++
+		case c == '"':
+			return i, nil
+		case c == '`' && i+1 < len(source):
+			if strings.HasPrefix(source[i+1:], "u{") {
+				end := strings.IndexByte(source[i:], '}')
+				value, err := strconv.ParseUint(source[i+3:i+max(end, 3)], 16, 32)
+				if end < 0 || err != nil {
+					return 0, fmt.Errorf("invalid Unicode escape in %s", source[i:])
+				}
+				word.WriteRune(rune(value))
+				i += end
+				continue
+			}
+			if escaped, ok := powerShellEscapes[source[i+1]]; ok {
+				word.WriteString(escaped)
+			} else if source[i+1] != '\n' {
+				word.WriteByte(source[i+1])
+			}
+			i++
+		case c == '$' && startsExpansion(source[i+1:]):
+			return 0, fmt.Errorf("%s expands in the shell and can't be unquoted", expansionAt(source, i))
+		default:
+			word.WriteByte(c)
+		}
+	}
+	return 0, fmt.Errorf("unterminated double quote")
+}
+
+// startsExpansion reports whether what follows a $ makes it a variable or
+// a substitution, rather than a literal dollar sign as in a$ or $)
+func startsExpansion(rest string) bool {
+	if rest == "" {
+		return false
+	}
+	c := rune(rest[0])
+	return c == '{' || c == '(' || c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c) || strings.ContainsRune("?!#@", c)
+}
+
+// expansionAt names the expansion starting at source[i] for an error
+func expansionAt(source string, i int) string {
+	end := i + 1
+	for end < len(source) && end < i+16 && !strings.ContainsRune(" \t\n'\"", rune(source[end])) {
+		end++
+	}
+	return source[i:end]
+}
+
+// isDigitIn reports whether c is a digit in base 8 or 16
+func isDigitIn(c byte, base int) bool {
+	if base == 8 {
+		return c >= '0' && c <= '7'
+	}
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
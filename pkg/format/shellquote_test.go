@@ -0,0 +1,55 @@
+package format
+
+import "testing"
+
+func TestUnquoteShell(t *testing.T) {
+	tests := []struct {
+		source, shell string
+		want          string
+	}{
+		{`'\d+\.\d+'`, "bash", `\d+\.\d+`},
+		{`"^\\\$[0-9]+ \` + "`" + `"`, "bash", "^\\$[0-9]+ `"},
+		{`"\d\"+$"`, "bash", `\d"+$`},
+		{`\(a\|b\)\*`, "bash", `(a|b)*`},
+		{`'it'\''s'`, "bash", `it's`},
+		{`$'\x41\t\101é\''`, "bash", "A\tAé'"},
+		{`'a'"b"c`, "bash", `abc`},
+		{`a$`, "bash", `a$`},
+		{`'\d+' `, "bash", `\d+`},
+		{`'it''s \d'`, "powershell", `it's \d`},
+		{"\"`$\\d+ `\" `u{e9}\"", "powershell", "$\\d+ \" é"},
+		{`"a""b"`, "powershell", `a"b`},
+		{"a`|b", "powershell", `a|b`},
+		{`"^\d+$"`, "powershell", `^\d+$`},
+	}
+
+	for _, tt := range tests {
+		got, err := UnquoteShell(tt.source, tt.shell)
+		if err != nil {
+			t.Errorf("UnquoteShell(%q, %q) returned error: %v", tt.source, tt.shell, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("UnquoteShell(%q, %q) = %q, want %q", tt.source, tt.shell, got, tt.want)
+		}
+	}
+}
+
+func TestUnquoteShell_Errors(t *testing.T) {
+	for _, tt := range []struct{ source, shell string }{
+		{`'\d+`, "bash"},
+		{`"\d+`, "bash"},
+		{`"$HOME/\d+"`, "bash"},
+		{"`date`", "bash"},
+		{`grep -E '\d+'`, "bash"},
+		{`a\`, "bash"},
+		{`'it's'`, "powershell"},
+		{`"$env:PATH"`, "powershell"},
+		{`"$(Get-Date)"`, "powershell"},
+		{`\d+`, "fish"},
+	} {
+		if got, err := UnquoteShell(tt.source, tt.shell); err == nil {
+			t.Errorf("UnquoteShell(%q, %q) = %q, want an error", tt.source, tt.shell, got)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package format
+
+import (
+	"strings"
+	"sync"
+)
+
+// Span is where a token is in the pattern it came from, as byte offsets
+type Span struct {
+	Start, End int
+}
+
+// SpanTokenizer is implemented by formats that can tokenize a pattern into
+// the offsets of its tokens, without copying them out of the pattern
+type SpanTokenizer interface {
+	// AppendTokenSpans appends the spans of the tokens of a pattern
+	// compiled with the flags to dst, and returns the extended slice
+	AppendTokenSpans(dst []Span, pattern, flags string) []Span
+}
+
+// TokenSpans returns where each token of a pattern compiled with flags
+// given outside it is in the pattern. For formats that don't implement
+// SpanTokenizer, such as plugins, the tokens are looked up in the pattern
+// in order, and ones that can't be found are left out.
+func TokenSpans(f RegexFormat, pattern, flags string) []Span {
+	if tokenizer, ok := f.(SpanTokenizer); ok {
+		return tokenizer.AppendTokenSpans(nil, pattern, flags)
+	}
+	var spans []Span
+	pos := 0
+	for _, token := range TokenizeWithFlags(f, pattern, flags) {
+		start := strings.Index(pattern[pos:], token)
+		if start < 0 {
+			continue
+		}
+		start += pos
+		pos = start + len(token)
+		spans = append(spans, Span{start, pos})
+	}
+	return spans
+}
+
+// spanPool holds scratch slices of spans for tokenizeSpans, so tokenizing
+// many patterns doesn't allocate one for each
+var spanPool = sync.Pool{
+	New: func() any {
+		spans := make([]Span, 0, 64)
+		return &spans
+	},
+}
+
+// tokenizeSpans tokenizes a pattern into spans in a pooled scratch slice
+// and returns the tokens as substrings of the pattern, so the only
+// allocation is the slice of tokens itself
+func tokenizeSpans(f SpanTokenizer, pattern, flags string) []string {
+	scratch := spanPool.Get().(*[]Span)
+	spans := f.AppendTokenSpans((*scratch)[:0], pattern, flags)
+
+	var tokens []string
+	if len(spans) > 0 {
+		tokens = make([]string, len(spans))
+		for i, span := range spans {
+			tokens[i] = pattern[span.Start:span.End]
+		}
+	}
+
+	*scratch = spans
+	spanPool.Put(scratch)
+	return tokens
+}
+
+// spanBuilder collects the spans of tokens as a tokenizer scans a pattern,
+// joining consecutive literal characters into one token. The tokenizer may
+// scan a part of the original pattern, its body, starting at base, after
+// adding header tokens for the rest, such as delimiters and modifiers.
+type spanBuilder struct {
+	spans        []Span
+	base         int
+	header       int
+	literalStart int
+	literalEnd   int
+}
+
+// newSpanBuilder returns a builder appending to spans
+func newSpanBuilder(spans []Span) spanBuilder {
+	return spanBuilder{spans: spans, literalStart: -1}
+}
+
+// bodyFrom marks the end of the header tokens, and returns where in the
+// body to start scanning to resume at from in the original pattern
+func (b *spanBuilder) bodyFrom(from int) int {
+	b.header = len(b.spans)
+	return max(0, from-b.base)
+}
+
+// add flushes any literal run and adds the token from start to end of the
+// part being scanned
+func (b *spanBuilder) add(start, end int) {
+	b.flush()
+	b.spans = append(b.spans, Span{b.base + start, b.base + end})
+}
+
+// literal adds the character at i of the part being scanned to the current
+// run of literal characters
+func (b *spanBuilder) literal(i int) {
+	if b.literalStart >= 0 && b.literalEnd != b.base+i {
+		b.flush()
+	}
+	if b.literalStart < 0 {
+		b.literalStart = b.base + i
+	}
+	b.literalEnd = b.base + i + 1
+}
+
+// flush ends the current run of literal characters as a token
+func (b *spanBuilder) flush() {
+	if b.literalStart >= 0 {
+		b.spans = append(b.spans, Span{b.literalStart, b.literalEnd})
+		b.literalStart = -1
+	}
+}
+
+// done flushes any literal run and returns the spans
+func (b *spanBuilder) done() []Span {
+	b.flush()
+	return b.spans
+}
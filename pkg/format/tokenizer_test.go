@@ -0,0 +1,76 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+// benchmarkPatterns are typical patterns of each flavor, for measuring how
+// fast the tokenizers are
+var benchmarkPatterns = []struct {
+	name, pattern, flags string
+}{
+	{"go", `^(?P<user>[a-z0-9._%+-]+)@(?P<domain>[a-z0-9.-]+\.[a-z]{2,})$`, ""},
+	{"pcre", `/(?<year>\d{4})-(?<month>\d{2})-(?<day>\d{2})(?:T\d{2}:\d{2}(?::\d{2})?)?+/x`, ""},
+	{"posix", `^[[:alpha:]][[:alnum:]_]*[[:space:]]*=[[:space:]]*"[^"]*"$`, ""},
+	{"js", `/^https?:\/\/(?:www\.)?[-a-zA-Z0-9@:%._+~#=]{1,256}\.[a-zA-Z]{1,6}\b(?:[-a-zA-Z0-9()@:%_+.~#?&\/=]*)$/iu`, ""},
+	{"python", `r"(?x) (?P<key> \w+ ) \s* = \s* (?P<value> [^#\n]* )  # a setting"`, ""},
+}
+
+func BenchmarkTokenize(b *testing.B) {
+	for _, bb := range benchmarkPatterns {
+		f := GetFormat(bb.name)
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				TokenizeWithFlags(f, bb.pattern, bb.flags)
+			}
+		})
+	}
+}
+
+func TestTokenSpans(t *testing.T) {
+	tests := []struct {
+		format, pattern, flags string
+		want                   []Span
+	}{
+		{"go", `ab[c-d]+`, "", []Span{{0, 2}, {2, 7}, {7, 8}}},
+		{"pcre", `#a b#x`, "", []Span{{4, 6}, {1, 2}, {3, 4}}},
+		{"pcre", `a # note`, "x", []Span{{0, 1}, {2, 8}}},
+		{"js", `/a+/g`, "", []Span{{3, 5}, {1, 2}, {2, 3}}},
+		{"python", `r"(?i)ab"`, "", []Span{{0, 2}, {2, 6}, {6, 8}}},
+	}
+	for _, tt := range tests {
+		f := GetFormat(tt.format)
+		got := TokenSpans(f, tt.pattern, tt.flags)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s TokenSpans(%q, %q) = %v, want %v", tt.format, tt.pattern, tt.flags, got, tt.want)
+		}
+
+		// The spans are where the tokens are in the pattern
+		tokens := TokenizeWithFlags(f, tt.pattern, tt.flags)
+		for i, span := range got {
+			if i < len(tokens) && tt.pattern[span.Start:span.End] != tokens[i] {
+				t.Errorf("%s TokenSpans(%q)[%d] = %q, want token %q", tt.format, tt.pattern, i, tt.pattern[span.Start:span.End], tokens[i])
+			}
+		}
+	}
+}
+
+// wordFormat is a format that tokenizes into words, without implementing
+// SpanTokenizer, like a plugin
+type wordFormat struct{}
+
+func (w *wordFormat) Name() string                     { return "Words" }
+func (w *wordFormat) ExplainToken(token string) string { return "" }
+func (w *wordFormat) HasFeature(feature string) bool   { return false }
+func (w *wordFormat) TokenizeRegex(pattern string) []string {
+	return []string{"ab", "missing", "cd"}
+}
+
+func TestTokenSpans_Fallback(t *testing.T) {
+	got := TokenSpans(&wordFormat{}, "ab cd", "")
+	if want := []Span{{0, 2}, {3, 5}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenSpans() = %v, want %v", got, want)
+	}
+}
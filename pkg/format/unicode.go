@@ -0,0 +1,160 @@
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/runenames"
+)
+
+// codePointEscapeEnd returns the end of the code point escape starting at
+// pattern[i], such as \x41 or \u00e9, or -1 if there isn't one. The escape
+// letter is followed by exactly digits hex digits or, when braces is set,
+// by any number of them in curly braces, like \x{1F600}.
+func codePointEscapeEnd(pattern string, i int, letter byte, digits int, braces bool) int {
+	if i+2 >= len(pattern) || pattern[i] != '\\' || pattern[i+1] != letter {
+		return -1
+	}
+
+	if braces && pattern[i+2] == '{' {
+		end := strings.IndexByte(pattern[i+3:], '}')
+		if end <= 0 {
+			return -1
+		}
+		for j := i + 3; j < i+3+end; j++ {
+			if !isHexDigit(pattern[j]) {
+				return -1
+			}
+		}
+		return i + 3 + end + 1
+	}
+
+	if i+2+digits > len(pattern) {
+		return -1
+	}
+	for j := i + 2; j < i+2+digits; j++ {
+		if !isHexDigit(pattern[j]) {
+			return -1
+		}
+	}
+	return i + 2 + digits
+}
+
+// octalEscapeEnd returns the end of the octal escape starting at
+// pattern[i], or -1 if there isn't one. Octal escapes are \0 followed by up
+// to two octal digits, or three octal digits like \101, as any shorter
+// escape starting with 1-9 is a backreference.
+func octalEscapeEnd(pattern string, i int) int {
+	if i+1 >= len(pattern) || pattern[i] != '\\' || !isOctalDigit(pattern[i+1]) {
+		return -1
+	}
+
+	end := i + 2
+	for end < len(pattern) && end < i+4 && isOctalDigit(pattern[end]) {
+		end++
+	}
+	if pattern[i+1] != '0' && (end != i+4 || pattern[i+1] > '3') {
+		return -1
+	}
+	return end
+}
+
+// isOctalDigit reports whether b is an octal digit
+func isOctalDigit(b byte) bool {
+	return b >= '0' && b <= '7'
+}
+
+// escapeHex returns the hex digits of a code point escape, without the
+// escape letter and any braces
+func escapeHex(sequence string) string {
+	return strings.Trim(sequence[2:], "{}")
+}
+
+// DecodeEscape returns the character a hex, octal or Unicode escape stands
+// for, such as A for \x41 or \101 and 😀 for \x{1F600} or \U0001F600. It
+// reports false for any other token.
+func DecodeEscape(sequence string) (rune, bool) {
+	var digits string
+	base := 16
+	switch {
+	case codePointEscapeEnd(sequence, 0, 'x', 2, true) == len(sequence),
+		codePointEscapeEnd(sequence, 0, 'u', 4, true) == len(sequence),
+		codePointEscapeEnd(sequence, 0, 'U', 8, false) == len(sequence):
+		digits = escapeHex(sequence)
+	case octalEscapeEnd(sequence, 0) == len(sequence):
+		digits, base = sequence[1:], 8
+	default:
+		return 0, false
+	}
+
+	value, err := strconv.ParseUint(digits, base, 32)
+	if err != nil || value > unicode.MaxRune {
+		return 0, false
+	}
+	return rune(value), true
+}
+
+// describeRune returns a character and its Unicode name. Control
+// characters have no name, so they aren't described.
+func describeRune(r rune) string {
+	name := runenames.Name(r)
+	if name == "" || unicode.IsControl(r) {
+		return ""
+	}
+	if !unicode.IsGraphic(r) || unicode.IsSpace(r) {
+		return name
+	}
+	return fmt.Sprintf("%c — %s", r, name)
+}
+
+// withCodePoint appends the character a code point escape stands for and
+// its Unicode name, like "é — LATIN SMALL LETTER E WITH ACUTE", to the
+// escape's explanation
+func withCodePoint(explanation, sequence string) string {
+	if r, ok := DecodeEscape(sequence); ok {
+		if description := describeRune(r); description != "" {
+			return fmt.Sprintf("%s (%s)", explanation, description)
+		}
+	}
+	return explanation
+}
+
+// explainOctalEscape explains an octal escape such as \101
+func explainOctalEscape(sequence string) string {
+	return withCodePoint(fmt.Sprintf("Matches the character with octal code %s", sequence[1:]), sequence)
+}
+
+// explainLiteral explains a literal token, naming its non-ASCII characters
+func explainLiteral(token string) string {
+	explanation := fmt.Sprintf("Matches the string '%s' literally", token)
+	if utf8.RuneCountInString(token) == 1 {
+		explanation = fmt.Sprintf("Matches the character '%s' literally", token)
+	}
+
+	// A single character is already quoted, so only its name is added
+	if r, size := utf8.DecodeRuneInString(token); size == len(token) && r >= utf8.RuneSelf {
+		if name := runenames.Name(r); name != "" {
+			explanation += fmt.Sprintf(" (%s)", name)
+		}
+		return explanation
+	}
+
+	var names []string
+	seen := make(map[rune]bool)
+	for _, r := range token {
+		if r < utf8.RuneSelf || seen[r] {
+			continue
+		}
+		seen[r] = true
+		if description := describeRune(r); description != "" {
+			names = append(names, description)
+		}
+	}
+	if len(names) > 0 {
+		explanation += fmt.Sprintf(" (%s)", strings.Join(names, "; "))
+	}
+	return explanation
+}
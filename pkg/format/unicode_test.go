@@ -0,0 +1,128 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodePointEscapeEnd(t *testing.T) {
+	tests := []struct {
+		pattern string
+		letter  byte
+		digits  int
+		braces  bool
+		want    int
+	}{
+		{`\x41b`, 'x', 2, false, 4},
+		{`\x4`, 'x', 2, false, -1},
+		{`\x4g`, 'x', 2, false, -1},
+		{`\x{1F600}a`, 'x', 2, true, 9},
+		{`\x{1F600}`, 'x', 2, false, -1},
+		{`\x{}`, 'x', 2, true, -1},
+		{`\x{12`, 'x', 2, true, -1},
+		{`\u00e9`, 'u', 4, false, 6},
+		{`\u00e9`, 'x', 2, false, -1},
+	}
+
+	for _, tt := range tests {
+		if got := codePointEscapeEnd(tt.pattern, 0, tt.letter, tt.digits, tt.braces); got != tt.want {
+			t.Errorf("codePointEscapeEnd(%q, %c, %d, %v) = %d, want %d", tt.pattern, tt.letter, tt.digits, tt.braces, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeRegex_CodePointEscapes(t *testing.T) {
+	tests := []struct {
+		format  RegexFormat
+		pattern string
+		want    []string
+	}{
+		{NewGoFormat(), `a\x41\x{e9}`, []string{"a", `\x41`, `\x{e9}`}},
+		{NewPcreFormat(), `\x{1F600}+`, []string{`\x{1F600}`, "+"}},
+		{NewJsFormat(), `\x41\u00e9\u{1F600}`, []string{`\x41`, `\u00e9`, `\u{1F600}`}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.format.TokenizeRegex(tt.pattern); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s TokenizeRegex(%q) = %q, want %q", tt.format.Name(), tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestExplainToken_CharacterNames(t *testing.T) {
+	tests := []struct {
+		format RegexFormat
+		token  string
+		want   string
+	}{
+		{NewGoFormat(), `\x41`, "Matches the character with hex code 41 (A — LATIN CAPITAL LETTER A)"},
+		{NewPcreFormat(), `\x{e9}`, "Matches the character with hex code e9 (é — LATIN SMALL LETTER E WITH ACUTE)"},
+		{NewJsFormat(), `\u00e9`, "Matches the Unicode character U+00e9 (é — LATIN SMALL LETTER E WITH ACUTE)"},
+		{NewJsFormat(), `\u{1f600}`, "Matches the Unicode character U+1F600 (😀 — GRINNING FACE) when the u flag is set"},
+		{NewPythonFormat(), `\U0001F600`, "Matches the Unicode character U+0001F600 (😀 — GRINNING FACE)"},
+		{NewGoFormat(), `\x0a`, "Matches the character with hex code 0a"},
+		{NewGoFormat(), `\x{zz}`, "Invalid hexadecimal escape sequence"},
+		{NewPosixFormat(), "é", "Matches the character 'é' literally (LATIN SMALL LETTER E WITH ACUTE)"},
+		{NewGoFormat(), "café", "Matches the string 'café' literally (é — LATIN SMALL LETTER E WITH ACUTE)"},
+		{NewGoFormat(), "abc", "Matches the string 'abc' literally"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.format.ExplainToken(tt.token); got != tt.want {
+			t.Errorf("%s ExplainToken(%q) = %q, want %q", tt.format.Name(), tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeEscape(t *testing.T) {
+	tests := []struct {
+		sequence string
+		want     rune
+		ok       bool
+	}{
+		{`\x41`, 'A', true},
+		{`\x{1F600}`, '😀', true},
+		{`\u00e9`, 'é', true},
+		{`\u{e9}`, 'é', true},
+		{`\U0001F600`, '😀', true},
+		{`\101`, 'A', true},
+		{`\012`, '\n', true},
+		{`\0`, 0, true},
+		{`\1`, 0, false},
+		{`\12`, 0, false},
+		{`\400`, 0, false},
+		{`\x{110000}`, 0, false},
+		{`\d`, 0, false},
+		{"a", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := DecodeEscape(tt.sequence)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("DecodeEscape(%q) = %q, %v, want %q, %v", tt.sequence, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestOctalEscapes(t *testing.T) {
+	if got, want := NewGoFormat().TokenizeRegex(`\101\0\1`), []string{`\101`, `\0`, `\1`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenizeRegex() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		format RegexFormat
+		token  string
+		want   string
+	}{
+		{NewGoFormat(), `\101`, "Matches the character with octal code 101 (A — LATIN CAPITAL LETTER A)"},
+		{NewPcreFormat(), `\010`, "Matches the character with octal code 010"},
+		{NewPythonFormat(), `\1`, "Backreference to capturing group 1"},
+		{NewGoFormat(), `\0`, "Matches a null character"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.format.ExplainToken(tt.token); got != tt.want {
+			t.Errorf("%s ExplainToken(%q) = %q, want %q", tt.format.Name(), tt.token, got, tt.want)
+		}
+	}
+}
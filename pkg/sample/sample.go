@@ -0,0 +1,56 @@
+// Package sample generates strings that match a regular expression, so test
+// suites can fabricate structured test data from the same patterns they
+// validate it with. Patterns are written in any flavor unregex explains and
+// have to be compatible with Go's regexp package.
+package sample
+
+import "github.com/weslien/unregex/internal/app"
+
+// Default settings of Generate
+const (
+	DefaultCount = 10
+	DefaultSeed  = 1
+)
+
+// Option changes how Generate generates samples
+type Option func(*options)
+
+// options are the settings Generate passes on to the generator
+type options struct {
+	app.SampleOptions
+}
+
+// WithCount sets how many distinct samples to generate
+func WithCount(n int) Option {
+	return func(o *options) { o.Count = n }
+}
+
+// WithSeed seeds the random choices; the same seed always gives the same
+// samples
+func WithSeed(seed int64) Option {
+	return func(o *options) { o.Seed = seed }
+}
+
+// WithFlags sets the flags the pattern is compiled with outside it, as
+// letters such as "i" or constants such as "re.IGNORECASE"
+func WithFlags(flags string) Option {
+	return func(o *options) { o.Flags = flags }
+}
+
+// WithMaxLength caps how many characters each sample has
+func WithMaxLength(n int) Option {
+	return func(o *options) { o.MaxLength = n }
+}
+
+// Generate returns distinct strings that pattern, written for flavor such
+// as "go", "pcre" or "python", matches as a whole. It generates DefaultCount
+// samples from DefaultSeed unless options say otherwise, so its results are
+// reproducible. Patterns with few matches may get fewer samples than asked
+// for.
+func Generate(pattern, flavor string, opts ...Option) ([]string, error) {
+	settings := options{app.SampleOptions{Count: DefaultCount, Seed: DefaultSeed}}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	return app.GenerateSamples(pattern, flavor, settings.SampleOptions)
+}
@@ -0,0 +1,112 @@
+package sample
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGenerate_Matches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		flavor  string
+		opts    []Option
+		check   string
+	}{
+		{`[a-z]+@[a-z]+\.com`, "go", nil, `^[a-z]+@[a-z]+\.com$`},
+		{`\d{3}-\d{4}`, "pcre", []Option{WithCount(25)}, `^\d{3}-\d{4}$`},
+		{`/hello \w+/i`, "pcre", nil, `^(?i)hello \w+$`},
+		{`ab+c`, "python", []Option{WithFlags("re.IGNORECASE")}, `^(?i)ab+c$`},
+		{`x+`, "go", []Option{WithMaxLength(3), WithCount(3)}, `^x{1,3}$`},
+	}
+
+	for _, tt := range tests {
+		samples, err := Generate(tt.pattern, tt.flavor, tt.opts...)
+		if err != nil {
+			t.Errorf("Generate(%q, %q) returned error: %v", tt.pattern, tt.flavor, err)
+			continue
+		}
+		if len(samples) == 0 {
+			t.Errorf("Generate(%q, %q) returned no samples", tt.pattern, tt.flavor)
+		}
+		check := regexp.MustCompile(tt.check)
+		seen := make(map[string]bool)
+		for _, s := range samples {
+			if !check.MatchString(s) {
+				t.Errorf("Generate(%q, %q) sample %q doesn't match %s", tt.pattern, tt.flavor, s, tt.check)
+			}
+			if seen[s] {
+				t.Errorf("Generate(%q, %q) repeated sample %q", tt.pattern, tt.flavor, s)
+			}
+			seen[s] = true
+		}
+	}
+}
+
+func TestGenerate_Count(t *testing.T) {
+	samples, err := Generate(`[a-z]{8}`, "go", WithCount(50))
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if len(samples) != 50 {
+		t.Errorf("Generate with WithCount(50) returned %d samples, want 50", len(samples))
+	}
+
+	// Patterns with fewer matches than asked for give all they have
+	samples, err = Generate(`yes|no`, "go", WithCount(5))
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if len(samples) != 2 {
+		t.Errorf("Generate(%q) returned %q, want both alternatives", `yes|no`, samples)
+	}
+}
+
+func TestGenerate_MaxLength(t *testing.T) {
+	samples, err := Generate(`\w+`, "go", WithMaxLength(2), WithCount(20))
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	for _, s := range samples {
+		if utf8.RuneCountInString(s) > 2 {
+			t.Errorf("Generate with WithMaxLength(2) returned %q", s)
+		}
+	}
+}
+
+func TestGenerate_Seed(t *testing.T) {
+	first, err := Generate(`[a-z]{5}\d{2}`, "go", WithSeed(42))
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	second, _ := Generate(`[a-z]{5}\d{2}`, "go", WithSeed(42))
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Generate with the same seed returned %q and %q", first, second)
+	}
+	other, _ := Generate(`[a-z]{5}\d{2}`, "go", WithSeed(43))
+	if reflect.DeepEqual(first, other) {
+		t.Errorf("Generate with different seeds returned the same samples %q", first)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		flavor  string
+		opts    []Option
+	}{
+		{`abc`, "cobol", nil},
+		{`(a)\1`, "pcre", nil},
+		{`[a`, "go", nil},
+		{`abc`, "python", []Option{WithFlags("re.NOPE")}},
+		{`a^b`, "go", nil},
+		{`\w{5}`, "go", []Option{WithMaxLength(3)}},
+	}
+
+	for _, tt := range tests {
+		if samples, err := Generate(tt.pattern, tt.flavor, tt.opts...); err == nil {
+			t.Errorf("Generate(%q, %q) = %q, want an error", tt.pattern, tt.flavor, samples)
+		}
+	}
+}
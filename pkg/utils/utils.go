@@ -1,5 +1,7 @@
 package utils
 
+import "github.com/weslien/unregex/pkg/format"
+
 // Version information set during build by the Makefile
 var (
 	// Version is the semantic version of the application
@@ -27,31 +29,57 @@ func FormatPattern(pattern string) string {
 	return pattern
 }
 
-// IsValidFormat checks if the specified regex format is supported
-func IsValidFormat(format string) bool {
-	validFormats := map[string]bool{
-		"go":     true,
-		"pcre":   true,
-		"posix":  true,
-		"js":     true,
-		"python": true,
+// IsValidFormat checks if the specified regex format is supported, either
+// built in or registered with format.Register
+func IsValidFormat(name string) bool {
+	_, ok := format.Lookup(name)
+	return ok
+}
+
+// IsValidOutput checks if the specified output format is supported
+func IsValidOutput(output string) bool {
+	validOutputs := map[string]bool{
+		"text":         true,
+		"markdown":     true,
+		"html":         true,
+		"html-snippet": true,
+		"dot":          true,
+		"railroad":     true,
+		"roff":         true,
+		"rst":          true,
 	}
 	
-	return validFormats[format]
+	return validOutputs[output]
+}
+
+// IsValidColorMode checks if the specified color mode is supported
+func IsValidColorMode(mode string) bool {
+	validModes := map[string]bool{
+		"always": true,
+		"never":  true,
+		"auto":   true,
+	}
+	
+	return validModes[mode]
 }
 
 // GetFormatName returns a readable name for the format
-func GetFormatName(format string) string {
+func GetFormatName(name string) string {
 	formatNames := map[string]string{
 		"go":     "Go Regexp",
 		"pcre":   "Perl Compatible Regular Expressions (PCRE)",
 		"posix":  "POSIX Extended Regular Expressions",
 		"js":     "JavaScript RegExp",
 		"python": "Python re",
+		"grok":   "Grok (Logstash)",
 	}
 	
-	if name, ok := formatNames[format]; ok {
-		return name
+	if readable, ok := formatNames[name]; ok {
+		return readable
+	}
+	if f, ok := format.Lookup(name); ok {
+		return f.Name()
 	}
 	return "Unknown Format"
-} 
\ No newline at end of file
+} 
+
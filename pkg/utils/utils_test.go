@@ -3,6 +3,8 @@ package utils
 import (
 	"strings"
 	"testing"
+
+	"github.com/weslien/unregex/pkg/format"
 )
 
 func TestVersion(t *testing.T) {
@@ -69,6 +71,64 @@ func TestIsValidFormat(t *testing.T) {
 	}
 }
 
+func TestIsValidFormat_Registered(t *testing.T) {
+	format.Register("toy", format.NewJsFormat())
+	t.Cleanup(func() { format.Unregister("toy") })
+	if !IsValidFormat("toy") {
+		t.Error("IsValidFormat(toy) should be true once the format is registered")
+	}
+	if got := GetFormatName("toy"); got != format.NewJsFormat().Name() {
+		t.Errorf("GetFormatName(toy) = %q, want the format's name", got)
+	}
+}
+
+func TestIsValidOutput(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"text", true},
+		{"markdown", true},
+		{"html", true},
+		{"html-snippet", true},
+		{"dot", true},
+		{"railroad", true},
+		{"roff", true},
+		{"rst", true},
+		{"invalid", false},
+		{"", false},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			if got := IsValidOutput(tt.output); got != tt.want {
+				t.Errorf("IsValidOutput(%q) = %v, want %v", tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsValidColorMode(t *testing.T) {
+	tests := []struct {
+		mode string
+		want bool
+	}{
+		{"always", true},
+		{"never", true},
+		{"auto", true},
+		{"sometimes", false},
+		{"", false},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			if got := IsValidColorMode(tt.mode); got != tt.want {
+				t.Errorf("IsValidColorMode(%q) = %v, want %v", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetFormatName(t *testing.T) {
 	tests := []struct {
 		format string
@@ -0,0 +1,73 @@
+// JavaScript API for the WebAssembly build of unregex, for browsers and Node.
+//
+//   import { load } from "./unregex.js";
+//   const unregex = await load();
+//   const explanation = unregex.explain("^\\d{3}-\\d{4}$", "pcre");
+//   console.log(explanation.tokens);
+//
+// unregex.wasm and Go's wasm_exec.js must sit next to this file; `make wasm`
+// puts all three in dist/wasm.
+
+const isNode = typeof process !== "undefined" && process.versions != null && process.versions.node != null;
+
+// load instantiates unregex.wasm, by default the one next to this module,
+// and returns the API. source may also be a URL, a path (in Node), a
+// Response, bytes or a compiled WebAssembly.Module.
+export async function load(source = new URL("./unregex.wasm", import.meta.url)) {
+  if (typeof globalThis.Go !== "function") {
+    if (isNode) {
+      // wasm_exec.js expects the globals Go's own Node runner provides
+      globalThis.fs ??= await import("node:fs");
+      globalThis.path ??= await import("node:path");
+      globalThis.crypto ??= (await import("node:crypto")).webcrypto;
+    }
+    await import(new URL("./wasm_exec.js", import.meta.url).href);
+  }
+
+  const go = new globalThis.Go();
+  const instance = await instantiate(source, go.importObject);
+  // run resolves when the Go program exits, which it doesn't: it keeps
+  // serving calls until the page or process ends
+  go.run(instance);
+
+  const api = globalThis.unregex;
+  return {
+    version: api.version,
+
+    // explain breaks a pattern into tokens and explains each one. flavor is
+    // one of go (the default), pcre, posix, js or python. Returns an object
+    // with pattern, formatName, format, tokens, features, sample and
+    // sampleStatus; throws an Error for an unsupported flavor.
+    explain(pattern, flavor = "go") {
+      const result = JSON.parse(api.explain(String(pattern), flavor));
+      if (result.error !== undefined) {
+        throw new Error(result.error);
+      }
+      return result;
+    },
+  };
+}
+
+async function instantiate(source, importObject) {
+  if (source instanceof WebAssembly.Module) {
+    return WebAssembly.instantiate(source, importObject);
+  }
+  if (source instanceof ArrayBuffer || ArrayBuffer.isView(source)) {
+    return (await WebAssembly.instantiate(source, importObject)).instance;
+  }
+  if (isNode && (typeof source === "string" || (source instanceof URL && source.protocol === "file:"))) {
+    const { readFile } = await import("node:fs/promises");
+    return (await WebAssembly.instantiate(await readFile(source), importObject)).instance;
+  }
+
+  const response = source instanceof Response ? source : fetch(source);
+  if (WebAssembly.instantiateStreaming) {
+    try {
+      return (await WebAssembly.instantiateStreaming(response, importObject)).instance;
+    } catch (err) {
+      // Servers that don't send application/wasm fall back to a full download
+    }
+  }
+  const bytes = await (await (source instanceof Response ? source : fetch(source))).arrayBuffer();
+  return (await WebAssembly.instantiate(bytes, importObject)).instance;
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// watchInterval is how often a watched file is checked for changes
+const watchInterval = 300 * time.Millisecond
+
+// readPatternFile reads a pattern from a file, ignoring surrounding
+// whitespace such as the trailing newline editors add
+func readPatternFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	pattern := strings.TrimSpace(string(data))
+	if pattern == "" {
+		return "", fmt.Errorf("%s contains no pattern", path)
+	}
+	return pattern, nil
+}
+
+// watchFile calls onChange now and again whenever the file's modification
+// time or size changes, until the process is interrupted. The file is
+// polled rather than watched with OS notifications, which also copes with
+// editors that save by replacing the file.
+func watchFile(path string, onChange func()) {
+	var lastMod time.Time
+	lastSize := int64(-1)
+	for {
+		if info, err := os.Stat(path); err == nil && (!info.ModTime().Equal(lastMod) || info.Size() != lastSize) {
+			lastMod, lastSize = info.ModTime(), info.Size()
+			onChange()
+		}
+		time.Sleep(watchInterval)
+	}
+}